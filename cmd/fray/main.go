@@ -9,7 +9,9 @@ import (
 
 func main() {
 	if err := command.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		if !command.IsReported(err) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(command.ExitCode(err))
 	}
 }
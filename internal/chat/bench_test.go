@@ -0,0 +1,62 @@
+package chat
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/devtool"
+)
+
+// generatedProject builds a devtool project for benchmarks. FRAY_BENCH_MESSAGES
+// overrides the default size of 5000 messages.
+func generatedProject(b *testing.B) (core.Project, *sql.DB) {
+	b.Helper()
+	b.Setenv("HOME", b.TempDir())
+	messages := 5000
+	if value, err := strconv.Atoi(os.Getenv("FRAY_BENCH_MESSAGES")); err == nil && value > 0 {
+		messages = value
+	}
+	dir := filepath.Join(b.TempDir(), "bench")
+	if _, err := devtool.Generate(devtool.Options{Dir: dir, Messages: messages, Agents: 12, Threads: 40, Seed: 42}); err != nil {
+		b.Fatalf("generate: %v", err)
+	}
+	project := core.Project{Root: dir, DBPath: filepath.Join(dir, ".fray", "fray.db")}
+	conn, err := db.OpenDatabase(project)
+	if err != nil {
+		b.Fatalf("open: %v", err)
+	}
+	b.Cleanup(func() { _ = conn.Close() })
+	if err := db.InitSchema(conn); err != nil {
+		b.Fatalf("init schema: %v", err)
+	}
+	return project, conn
+}
+
+func BenchmarkNewModelGenerated(b *testing.B) {
+	project, conn := generatedProject(b)
+	opts := Options{DB: conn, ProjectName: "bench", ProjectRoot: project.Root, ProjectDBPath: project.DBPath, Username: "adam", Last: 200}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewModel(opts); err != nil {
+			b.Fatalf("new model: %v", err)
+		}
+	}
+}
+
+func BenchmarkRenderMessagesGenerated(b *testing.B) {
+	project, conn := generatedProject(b)
+	model, err := NewModel(Options{DB: conn, ProjectName: "bench", ProjectRoot: project.Root, ProjectDBPath: project.DBPath, Username: "adam", Last: 200})
+	if err != nil {
+		b.Fatalf("new model: %v", err)
+	}
+	model.width, model.height = 120, 40
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = model.renderMessages()
+	}
+}
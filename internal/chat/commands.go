@@ -331,6 +331,9 @@ func (m *Model) runEditCommand(input string) error {
 		return err
 	}
 
+	if err := db.CheckMessageContent(m.db, body); err != nil {
+		return err
+	}
 	if err := db.EditMessage(m.db, msg.ID, body, m.username); err != nil {
 		return err
 	}
@@ -505,7 +508,8 @@ func (m *Model) appendMessageUpdate(msg types.Message) error {
 
 func (m *Model) appendMessageEditUpdate(msg types.Message, reason string) error {
 	body := msg.Body
-	update := db.MessageUpdateJSONLRecord{ID: msg.ID, Body: &body, Reason: &reason}
+	threadRefs := append([]string{}, msg.ThreadRefs...)
+	update := db.MessageUpdateJSONLRecord{ID: msg.ID, Body: &body, ThreadRefs: &threadRefs, Reason: &reason}
 	if msg.EditedAt != nil {
 		update.EditedAt = msg.EditedAt
 	}
@@ -1335,10 +1339,15 @@ func (m *Model) createThread(name string, parentGUID *string, anchorText string)
 	}
 
 	// Create the thread
+	var createdBy *string
+	if m.username != "" {
+		createdBy = &m.username
+	}
 	thread, err := db.CreateThread(m.db, types.Thread{
 		Name:         name,
 		ParentThread: parentGUID,
 		Status:       types.ThreadStatusOpen,
+		CreatedBy:    createdBy,
 	})
 	if err != nil {
 		return nil, err
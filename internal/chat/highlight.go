@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/adamavenir/fray/internal/core"
 	"github.com/alecthomas/chroma"
 	"github.com/alecthomas/chroma/formatters"
 	"github.com/alecthomas/chroma/lexers"
@@ -18,99 +19,26 @@ func highlightCodeBlocks(body string) string {
 		return body
 	}
 
-	lines := strings.Split(body, "\n")
-	var out strings.Builder
-
-	for i := 0; i < len(lines); i++ {
-		line := lines[i]
-		fence, lang, ok := parseFence(line)
-		if !ok {
-			out.WriteString(line)
-			if i < len(lines)-1 {
-				out.WriteByte('\n')
-			}
-			continue
-		}
-
-		end := findClosingFence(lines, i+1, fence)
-		if end == -1 {
-			out.WriteString(line)
-			if i < len(lines)-1 {
-				out.WriteByte('\n')
-			}
-			continue
-		}
-
-		out.WriteString(line)
-		out.WriteByte('\n')
-
-		code := strings.Join(lines[i+1:end], "\n")
-		out.WriteString(highlightCode(code, lang))
-		out.WriteByte('\n')
-		out.WriteString(lines[end])
-
-		if end < len(lines)-1 {
-			out.WriteByte('\n')
-		}
-		i = end
-	}
-
-	return out.String()
-}
-
-func parseFence(line string) (string, string, bool) {
-	trimmed := strings.TrimLeft(line, " \t")
-	if len(trimmed) < 3 {
-		return "", "", false
-	}
-
-	fenceChar := trimmed[0]
-	if fenceChar != '`' && fenceChar != '~' {
-		return "", "", false
-	}
-
-	count := 0
-	for count < len(trimmed) && trimmed[count] == fenceChar {
-		count++
-	}
-	if count < 3 {
-		return "", "", false
-	}
-
-	fence := trimmed[:count]
-	rest := strings.TrimSpace(trimmed[count:])
-	lang := ""
-	if rest != "" {
-		parts := strings.Fields(rest)
-		if len(parts) > 0 {
-			lang = parts[0]
-		}
-	}
-
-	return fence, lang, true
-}
-
-func findClosingFence(lines []string, start int, fence string) int {
-	for i := start; i < len(lines); i++ {
-		if isClosingFence(lines[i], fence) {
-			return i
-		}
-	}
-	return -1
-}
-
-func isClosingFence(line string, fence string) bool {
-	trimmed := strings.TrimSpace(line)
-	if len(trimmed) < len(fence) {
-		return false
+	blocks := core.ParseCodeBlocks(body)
+	if len(blocks) == 0 {
+		return body
 	}
 
-	for i := 0; i < len(trimmed); i++ {
-		if trimmed[i] != fence[0] {
-			return false
+	lines := strings.Split(body, "\n")
+	out := make([]string, 0, len(lines))
+	next := 0
+	for _, block := range blocks {
+		// Unterminated fences stay as typed.
+		if !block.Closed {
+			continue
 		}
+		out = append(out, lines[next:block.StartLine+1]...)
+		code := strings.Join(lines[block.StartLine+1:block.EndLine], "\n")
+		out = append(out, highlightCode(code, block.Language), lines[block.EndLine])
+		next = block.EndLine + 1
 	}
-	return true
+	out = append(out, lines[next:]...)
+	return strings.Join(out, "\n")
 }
 
 func highlightCode(code, lang string) string {
@@ -166,11 +166,13 @@ func (m *Model) formatQuestionStatus(msgID string) string {
 		return ""
 	}
 
-	var answered, unanswered []string
+	var answered, declined, unanswered []string
 	for i, q := range questions {
 		label := fmt.Sprintf("Q%d", i+1)
 		if q.Status == types.QuestionStatusAnswered {
 			answered = append(answered, label)
+		} else if q.Status == types.QuestionStatusDeclined {
+			declined = append(declined, label)
 		} else {
 			unanswered = append(unanswered, label)
 		}
@@ -181,6 +183,10 @@ func (m *Model) formatQuestionStatus(msgID string) string {
 		answeredStyle := lipgloss.NewStyle().Bold(true)
 		parts = append(parts, answeredStyle.Render("Answered")+": "+strings.Join(answered, ", "))
 	}
+	if len(declined) > 0 {
+		declinedStyle := lipgloss.NewStyle().Bold(true).Foreground(metaColor)
+		parts = append(parts, declinedStyle.Render("Declined")+": "+strings.Join(declined, ", "))
+	}
 	if len(unanswered) > 0 {
 		unansweredStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11")) // yellow
 		parts = append(parts, unansweredStyle.Render("Unanswered")+": "+strings.Join(unanswered, ", "))
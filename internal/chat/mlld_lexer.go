@@ -17,7 +17,7 @@ var mlldLexer = lexers.Register(chroma.MustNewLexer(
 	},
 	chroma.Rules{
 		"root": {
-			{`[\s\S]+`, chroma.Text, nil},
+			{Pattern: `[\s\S]+`, Type: chroma.Text},
 		},
 	},
 ))
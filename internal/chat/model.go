@@ -618,6 +618,18 @@ func (m *Model) handleSubmit(text string) tea.Cmd {
 		return nil
 	}
 
+	// Chat replies may cross homes: the parent is linked into the thread.
+	if replyTo != nil {
+		home := ""
+		if m.currentThread != nil {
+			home = m.currentThread.GUID
+		}
+		if _, err := db.CheckReplyTarget(m.db, *replyTo, home, true); err != nil {
+			m.status = err.Error()
+			return nil
+		}
+	}
+
 	agentBases, err := db.GetAgentBases(m.db)
 	if err != nil {
 		m.status = err.Error()
@@ -1139,7 +1151,7 @@ func isAlphaNum(r rune) bool {
 // Suppressed if: from self, in muted thread, event/surface message, user is not human.
 func (m *Model) maybeNotify(msg types.Message) {
 	// Only notify human users (not agents testing in chat)
-	users, _ := db.GetActiveUsers(m.db)
+	users, _ := db.GetActiveUsers(m.db, m.projectDBPath)
 	isHumanUser := false
 	for _, u := range users {
 		if u == m.username {
@@ -1217,7 +1217,7 @@ func (m *Model) updateThreadMatches() {
 	// Search database for threads matching the filter (if we have a term)
 	m.threadSearchResults = nil
 	if term != "" && m.db != nil {
-		allThreads, err := db.GetThreads(m.db, &types.ThreadQueryOptions{})
+		allThreads, err := db.GetThreads(m.db, &types.ThreadQueryOptions{Viewer: &m.username})
 		if err == nil {
 			// Build set of subscribed thread GUIDs
 			subscribed := make(map[string]struct{})
@@ -1714,7 +1714,7 @@ func loadThreads(dbConn *sql.DB, username string) ([]types.Thread, int) {
 	if dbConn == nil || username == "" {
 		return nil, 0
 	}
-	threads, err := db.GetThreads(dbConn, &types.ThreadQueryOptions{})
+	threads, err := db.GetThreads(dbConn, &types.ThreadQueryOptions{Viewer: &username})
 	if err != nil {
 		return nil, 0
 	}
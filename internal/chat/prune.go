@@ -1,7 +1,6 @@
 package chat
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -33,6 +32,12 @@ func pruneMessages(projectPath string, keep int, pruneAll bool) (pruneResult, er
 	messagesPath := filepath.Join(frayDir, messagesJSONL)
 	historyPath := filepath.Join(frayDir, historyJSONL)
 
+	release, err := db.LockJSONL(projectPath)
+	if err != nil {
+		return pruneResult{}, err
+	}
+	defer release()
+
 	if pruneAll {
 		if err := os.Remove(historyPath); err != nil && !os.IsNotExist(err) {
 			return pruneResult{}, err
@@ -99,7 +104,7 @@ func writeMessages(path string, records []db.MessageJSONLRecord) error {
 	var builder strings.Builder
 	for _, record := range records {
 		record.Type = "message"
-		data, err := json.Marshal(record)
+		data, err := db.MarshalRecord(record)
 		if err != nil {
 			return err
 		}
@@ -5,7 +5,7 @@ import (
 	"testing"
 
 	"github.com/adamavenir/fray/internal/db"
-	_ "modernc.org/sqlite"
+	"github.com/adamavenir/fray/internal/testutil"
 )
 
 func TestResolveReplyReference(t *testing.T) {
@@ -55,16 +55,7 @@ func TestResolveReplyReferenceAmbiguous(t *testing.T) {
 
 func openChatDB(t *testing.T) *sql.DB {
 	t.Helper()
-	path := t.TempDir() + "/test.db"
-	dbConn, err := sql.Open("sqlite", path)
-	if err != nil {
-		t.Fatalf("open sqlite: %v", err)
-	}
-	t.Cleanup(func() { _ = dbConn.Close() })
-	if err := db.InitSchema(dbConn); err != nil {
-		t.Fatalf("init schema: %v", err)
-	}
-	return dbConn
+	return testutil.NewProject(t).OpenDB(t, db.OpenDatabase, db.InitSchema)
 }
 
 func seedMessage(t *testing.T, dbConn *sql.DB, guid string, ts int64, fromAgent, body string) {
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/adamavenir/fray/internal/core"
@@ -29,12 +30,15 @@ func NewAgentCmd() *cobra.Command {
 
 	cmd.AddCommand(
 		NewAgentCreateCmd(),
+		NewAgentSetCmd(),
 		NewAgentStartCmd(),
 		NewAgentRefreshCmd(),
 		NewAgentEndCmd(),
 		NewAgentListCmd(),
+		NewAgentSessionsCmd(),
 		NewAgentCheckCmd(),
 		NewAgentAvatarCmd(),
+		NewAgentShowCmd(),
 	)
 
 	return cmd
@@ -54,8 +58,11 @@ func NewAgentCreateCmd() *cobra.Command {
 			defer ctx.DB.Close()
 
 			agentID := core.NormalizeAgentRef(args[0])
-			if !core.IsValidAgentID(agentID) {
-				return writeCommandError(cmd, fmt.Errorf("invalid agent name: %s", agentID))
+			if normalize, _ := cmd.Flags().GetBool("normalize"); normalize {
+				agentID = core.NormalizeAgentID(agentID)
+			}
+			if err := core.ValidateNewAgentID(agentID); err != nil {
+				return writeCommandError(cmd, validationError("%v", err))
 			}
 
 			driver, _ := cmd.Flags().GetString("driver")
@@ -63,7 +70,7 @@ func NewAgentCreateCmd() *cobra.Command {
 				driver = "claude"
 			}
 			if daemon.GetDriver(driver) == nil {
-				return writeCommandError(cmd, fmt.Errorf("unknown driver: %s (valid: claude, codex, opencode)", driver))
+				return writeCommandError(cmd, validationError("unknown driver: %s (valid: claude, codex, opencode)", driver))
 			}
 
 			promptDelivery, _ := cmd.Flags().GetString("prompt-delivery")
@@ -151,6 +158,7 @@ func NewAgentCreateCmd() *cobra.Command {
 	cmd.Flags().Int64("idle-after", 5000, "time since activity before 'idle' (ms)")
 	cmd.Flags().Int64("min-checkin", 600000, "done-detection: idle + no fray posts = kill (ms, default 10m)")
 	cmd.Flags().Int64("max-runtime", 0, "zombie safety net: forced termination (ms, 0 = unlimited)")
+	cmd.Flags().Bool("normalize", false, "create the normalized form of the name (lowercase, _ and spaces to -)")
 
 	return cmd
 }
@@ -158,9 +166,13 @@ func NewAgentCreateCmd() *cobra.Command {
 // NewAgentStartCmd starts a fresh session for a managed agent.
 func NewAgentStartCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "start <name>",
+		Use:   "start <name|pattern|a,b,c>",
 		Short: "Start a fresh session for a managed agent",
-		Args:  cobra.ExactArgs(1),
+		Long: `Start a fresh session for a managed agent.
+
+Select several agents with a glob ('dev*'), a comma list (dev,pm,arch), or
+--all-managed; each is started in turn and failures are reported per agent.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmdCtx, err := GetContext(cmd)
 			if err != nil {
@@ -168,69 +180,99 @@ func NewAgentStartCmd() *cobra.Command {
 			}
 			defer cmdCtx.DB.Close()
 
-			agent, err := resolveAgentByRef(cmdCtx, args[0])
+			customPrompt, _ := cmd.Flags().GetString("prompt")
+			allManaged, _ := cmd.Flags().GetBool("all-managed")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			selector, err := agentSelectorArg(args, allManaged)
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
 
-			if !agent.Managed {
-				return writeCommandError(cmd, fmt.Errorf("agent @%s is not managed (use 'fray agent create' first)", agent.AgentID))
-			}
-
-			if agent.Invoke == nil || agent.Invoke.Driver == "" {
-				return writeCommandError(cmd, fmt.Errorf("agent @%s has no driver configured", agent.AgentID))
-			}
-
-			driver := daemon.GetDriver(agent.Invoke.Driver)
-			if driver == nil {
-				return writeCommandError(cmd, fmt.Errorf("unknown driver: %s", agent.Invoke.Driver))
-			}
-
-			customPrompt, _ := cmd.Flags().GetString("prompt")
-			prompt := customPrompt
-			if prompt == "" {
-				prompt = buildFlyPrompt(agent.AgentID)
+			if isAgentSelector(selector, allManaged) || dryRun {
+				agents, err := resolveAgentSelector(cmdCtx, selector, allManaged)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				return runAgentBulk(cmd, cmdCtx, "start", agents, dryRun, func(agent types.Agent) (string, error) {
+					sessionID, err := startManagedAgent(cmdCtx, agent, customPrompt)
+					if err != nil {
+						return "", err
+					}
+					return fmt.Sprintf("started (session: %s)", sessionID), nil
+				})
 			}
 
-			ctx := context.Background()
-			proc, err := driver.Spawn(ctx, *agent, prompt)
+			agent, err := resolveAgentByRef(cmdCtx, selector)
 			if err != nil {
-				return writeCommandError(cmd, fmt.Errorf("spawn failed: %w", err))
-			}
-
-			// Drain pipes in background to prevent blocking
-			drainProcessPipes(proc)
-
-			if err := db.UpdateAgentPresence(cmdCtx.DB, agent.AgentID, types.PresenceSpawning); err != nil {
-				driver.Cleanup(proc)
 				return writeCommandError(cmd, err)
 			}
-
-			sessionStart := types.SessionStart{
-				AgentID:   agent.AgentID,
-				SessionID: proc.SessionID,
-				StartedAt: time.Now().Unix(),
+			sessionID, err := startManagedAgent(cmdCtx, *agent, customPrompt)
+			if err != nil {
+				return writeCommandError(cmd, err)
 			}
-			db.AppendSessionStart(cmdCtx.Project.DBPath, sessionStart)
 
 			if cmdCtx.JSONMode {
 				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
 					"agent_id":   agent.AgentID,
-					"session_id": proc.SessionID,
+					"session_id": sessionID,
 					"driver":     agent.Invoke.Driver,
 				})
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Started @%s (session: %s)\n", agent.AgentID, proc.SessionID)
+			fmt.Fprintf(cmd.OutOrStdout(), "Started @%s (session: %s)\n", agent.AgentID, sessionID)
 			return nil
 		},
 	}
 
 	cmd.Flags().String("prompt", "", "custom prompt (default: /fly equivalent)")
+	addAgentSelectorFlags(cmd)
 
 	return cmd
 }
 
+// startManagedAgent spawns a session for a managed agent and records it.
+func startManagedAgent(cmdCtx *CommandContext, agent types.Agent, customPrompt string) (string, error) {
+	if !agent.Managed {
+		return "", validationError("agent @%s is not managed (use 'fray agent create' first)", agent.AgentID)
+	}
+
+	if agent.Invoke == nil || agent.Invoke.Driver == "" {
+		return "", validationError("agent @%s has no driver configured", agent.AgentID)
+	}
+
+	driver := daemon.GetDriver(agent.Invoke.Driver)
+	if driver == nil {
+		return "", validationError("unknown driver: %s", agent.Invoke.Driver)
+	}
+
+	prompt := customPrompt
+	if prompt == "" {
+		prompt = buildFlyPrompt(agent.AgentID)
+	}
+
+	ctx := context.Background()
+	proc, err := driver.Spawn(ctx, agent, prompt)
+	if err != nil {
+		return "", unavailableError(fmt.Errorf("spawn failed: %w", err))
+	}
+
+	// Drain pipes in background to prevent blocking
+	drainProcessPipes(proc)
+
+	if err := db.UpdateAgentPresence(cmdCtx.DB, agent.AgentID, types.PresenceSpawning); err != nil {
+		driver.Cleanup(proc)
+		return "", err
+	}
+
+	sessionStart := types.SessionStart{
+		AgentID:   agent.AgentID,
+		SessionID: proc.SessionID,
+		StartedAt: time.Now().Unix(),
+	}
+	db.AppendSessionStart(cmdCtx.Project.DBPath, sessionStart)
+	return proc.SessionID, nil
+}
+
 // NewAgentRefreshCmd ends the current session and starts a new one.
 func NewAgentRefreshCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -250,11 +292,11 @@ func NewAgentRefreshCmd() *cobra.Command {
 			}
 
 			if !agent.Managed {
-				return writeCommandError(cmd, fmt.Errorf("agent @%s is not managed", agent.AgentID))
+				return writeCommandError(cmd, validationError("agent @%s is not managed", agent.AgentID))
 			}
 
 			if agent.Invoke == nil || agent.Invoke.Driver == "" {
-				return writeCommandError(cmd, fmt.Errorf("agent @%s has no driver configured", agent.AgentID))
+				return writeCommandError(cmd, validationError("agent @%s has no driver configured", agent.AgentID))
 			}
 
 			// Skip session_end recording - we don't track session_id for manual refreshes
@@ -263,14 +305,14 @@ func NewAgentRefreshCmd() *cobra.Command {
 
 			driver := daemon.GetDriver(agent.Invoke.Driver)
 			if driver == nil {
-				return writeCommandError(cmd, fmt.Errorf("unknown driver: %s", agent.Invoke.Driver))
+				return writeCommandError(cmd, validationError("unknown driver: %s", agent.Invoke.Driver))
 			}
 
 			prompt := buildFlyPrompt(agent.AgentID)
 			ctx := context.Background()
 			proc, err := driver.Spawn(ctx, *agent, prompt)
 			if err != nil {
-				return writeCommandError(cmd, fmt.Errorf("spawn failed: %w", err))
+				return writeCommandError(cmd, unavailableError(fmt.Errorf("spawn failed: %w", err)))
 			}
 
 			// Drain pipes in background to prevent blocking
@@ -304,9 +346,13 @@ func NewAgentRefreshCmd() *cobra.Command {
 // NewAgentEndCmd gracefully ends an agent session.
 func NewAgentEndCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "end <name>",
+		Use:   "end <name|pattern|a,b,c>",
 		Short: "Gracefully end an agent session",
-		Args:  cobra.ExactArgs(1),
+		Long: `Gracefully end an agent session.
+
+Select several agents with a glob ('dev*'), a comma list (dev,pm), or
+--all-managed; failures are reported per agent.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmdCtx, err := GetContext(cmd)
 			if err != nil {
@@ -314,18 +360,33 @@ func NewAgentEndCmd() *cobra.Command {
 			}
 			defer cmdCtx.DB.Close()
 
-			agent, err := resolveAgentByRef(cmdCtx, args[0])
+			allManaged, _ := cmd.Flags().GetBool("all-managed")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			selector, err := agentSelectorArg(args, allManaged)
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
 
-			if !agent.Managed {
-				return writeCommandError(cmd, fmt.Errorf("agent @%s is not managed", agent.AgentID))
+			if isAgentSelector(selector, allManaged) || dryRun {
+				agents, err := resolveAgentSelector(cmdCtx, selector, allManaged)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				return runAgentBulk(cmd, cmdCtx, "end", agents, dryRun, func(agent types.Agent) (string, error) {
+					if err := endManagedAgent(cmdCtx, agent); err != nil {
+						return "", err
+					}
+					return "ended", nil
+				})
 			}
 
-			// Skip session_end recording - we don't track session_id for manual ends
-			// The daemon handles session lifecycle properly via monitorProcess
-			db.UpdateAgentPresence(cmdCtx.DB, agent.AgentID, types.PresenceOffline)
+			agent, err := resolveAgentByRef(cmdCtx, selector)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := endManagedAgent(cmdCtx, *agent); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
 			if cmdCtx.JSONMode {
 				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
@@ -339,9 +400,21 @@ func NewAgentEndCmd() *cobra.Command {
 		},
 	}
 
+	addAgentSelectorFlags(cmd)
 	return cmd
 }
 
+// endManagedAgent marks a managed agent's session as ended.
+func endManagedAgent(cmdCtx *CommandContext, agent types.Agent) error {
+	if !agent.Managed {
+		return validationError("agent @%s is not managed", agent.AgentID)
+	}
+
+	// Skip session_end recording - we don't track session_id for manual ends
+	// The daemon handles session lifecycle properly via monitorProcess
+	return db.UpdateAgentPresence(cmdCtx.DB, agent.AgentID, types.PresenceOffline)
+}
+
 // NewAgentListCmd lists all agents with their managed status.
 func NewAgentListCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -409,6 +482,108 @@ func NewAgentListCmd() *cobra.Command {
 	return cmd
 }
 
+// NewAgentSessionsCmd lists recent sessions for an agent with their outcomes.
+func NewAgentSessionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions <name>",
+		Short: "Show recent sessions and how they ended",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmdCtx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer cmdCtx.DB.Close()
+
+			agentID, err := resolveAgentRef(cmdCtx, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			sessions, err := db.ReadAgentSessions(cmdCtx.Project.DBPath, agentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			limit, _ := cmd.Flags().GetInt("limit")
+			if limit > 0 && len(sessions) > limit {
+				sessions = sessions[len(sessions)-limit:]
+			}
+
+			if cmdCtx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(sessions)
+			}
+
+			out := cmd.OutOrStdout()
+			if len(sessions) == 0 {
+				fmt.Fprintf(out, "No sessions recorded for @%s\n", agentID)
+				return nil
+			}
+			if median, count := medianResponseLatency(sessions); count > 0 {
+				fmt.Fprintf(out, "Mention to reply: median %s over %d session(s)\n", median, count)
+			}
+
+			for i := len(sessions) - 1; i >= 0; i-- {
+				session := sessions[i]
+				id := session.SessionID
+				if id == "" {
+					id = "-"
+				}
+				started := "-"
+				if session.StartedAt > 0 {
+					started = time.Unix(session.StartedAt, 0).Format("2006-01-02 15:04")
+				}
+
+				outcome := "running"
+				switch {
+				case session.Outcome != "":
+					outcome = "done: " + string(session.Outcome)
+				case session.ExitCode != nil && *session.ExitCode == 0:
+					outcome = "ended"
+				case session.ExitCode != nil:
+					outcome = fmt.Sprintf("recycled (exit %d)", *session.ExitCode)
+				}
+				if session.DurationMs > 0 {
+					outcome += fmt.Sprintf(", %s", time.Duration(session.DurationMs)*time.Millisecond/time.Second*time.Second)
+				}
+				if session.ResponseLatencyMs != nil {
+					outcome += fmt.Sprintf(", replied in %s", time.Duration(*session.ResponseLatencyMs)*time.Millisecond)
+				}
+
+				fmt.Fprintf(out, "%s  %s  %s\n", started, id, outcome)
+				if session.Summary != nil && *session.Summary != "" {
+					fmt.Fprintf(out, "    %s\n", *session.Summary)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Int("limit", 10, "number of sessions to show (0 for all)")
+
+	return cmd
+}
+
+// medianResponseLatency is the median time from triggering mention to first
+// reply across sessions the daemon linked, and how many there were.
+func medianResponseLatency(sessions []types.AgentSession) (time.Duration, int) {
+	var latencies []int64
+	for _, session := range sessions {
+		if session.ResponseLatencyMs != nil {
+			latencies = append(latencies, *session.ResponseLatencyMs)
+		}
+	}
+	if len(latencies) == 0 {
+		return 0, 0
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	mid := len(latencies) / 2
+	median := latencies[mid]
+	if len(latencies)%2 == 0 {
+		median = (latencies[mid-1] + latencies[mid]) / 2
+	}
+	return time.Duration(median) * time.Millisecond, len(latencies)
+}
+
 // NewAgentCheckCmd performs a daemon-less mention check and spawn.
 func NewAgentCheckCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -431,7 +606,7 @@ Useful for CI/cron-based polling when daemon is not available.`,
 			}
 
 			if !agent.Managed {
-				return writeCommandError(cmd, fmt.Errorf("agent @%s is not managed", agent.AgentID))
+				return writeCommandError(cmd, validationError("agent @%s is not managed", agent.AgentID))
 			}
 
 			frayDir := filepath.Dir(cmdCtx.Project.DBPath)
@@ -489,12 +664,12 @@ Useful for CI/cron-based polling when daemon is not available.`,
 			}
 
 			if agent.Invoke == nil || agent.Invoke.Driver == "" {
-				return writeCommandError(cmd, fmt.Errorf("agent @%s has no driver configured", agent.AgentID))
+				return writeCommandError(cmd, validationError("agent @%s has no driver configured", agent.AgentID))
 			}
 
 			driver := daemon.GetDriver(agent.Invoke.Driver)
 			if driver == nil {
-				return writeCommandError(cmd, fmt.Errorf("unknown driver: %s", agent.Invoke.Driver))
+				return writeCommandError(cmd, validationError("unknown driver: %s", agent.Invoke.Driver))
 			}
 
 			triggerMsg := nonSelf[0]
@@ -503,7 +678,7 @@ Useful for CI/cron-based polling when daemon is not available.`,
 			ctx := context.Background()
 			proc, err := driver.Spawn(ctx, *agent, prompt)
 			if err != nil {
-				return writeCommandError(cmd, fmt.Errorf("spawn failed: %w", err))
+				return writeCommandError(cmd, unavailableError(fmt.Errorf("spawn failed: %w", err)))
 			}
 
 			// Drain pipes in background to prevent blocking
@@ -659,12 +834,12 @@ Examples:
 				return writeCommandError(cmd, err)
 			}
 			if agent == nil {
-				return writeCommandError(cmd, fmt.Errorf("agent not found: @%s", agentID))
+				return writeCommandError(cmd, notFoundError("agent not found: @%s", agentID))
 			}
 
 			// Validate avatar
 			if !core.IsValidAvatar(avatar) {
-				return writeCommandError(cmd, fmt.Errorf("invalid avatar: %s (use a single character or emoji)", avatar))
+				return writeCommandError(cmd, validationError("invalid avatar: %s (use a single character or emoji)", avatar))
 			}
 
 			// Update in database
@@ -0,0 +1,310 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/daemon"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/gobwas/glob"
+	"github.com/spf13/cobra"
+)
+
+// agentOpResult is one agent's outcome in a bulk agent operation.
+type agentOpResult struct {
+	AgentID string `json:"agent_id"`
+	OK      bool   `json:"ok"`
+	Detail  string `json:"detail,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// isAgentSelector reports whether ref names several agents: a glob, a comma
+// list, or --all-managed.
+func isAgentSelector(ref string, allManaged bool) bool {
+	return allManaged || strings.ContainsAny(ref, "*?[,")
+}
+
+// resolveAgentSelector expands a selector into agents. Globs match managed
+// agents only; plain names resolve like any agent ref so unmanaged agents
+// surface as per-agent failures instead of disappearing.
+func resolveAgentSelector(ctx *CommandContext, selector string, allManaged bool) ([]types.Agent, error) {
+	managed, err := db.GetManagedAgents(ctx.DB)
+	if err != nil {
+		return nil, err
+	}
+	if allManaged {
+		if len(managed) == 0 {
+			return nil, notFoundError("no managed agents")
+		}
+		return managed, nil
+	}
+
+	var agents []types.Agent
+	seen := map[string]struct{}{}
+	add := func(agent types.Agent) {
+		if _, ok := seen[agent.AgentID]; ok {
+			return
+		}
+		seen[agent.AgentID] = struct{}{}
+		agents = append(agents, agent)
+	}
+
+	for _, part := range splitCommaList(selector) {
+		ref := core.NormalizeAgentRef(part)
+		if strings.ContainsAny(ref, "*?[") {
+			matcher, err := glob.Compile(ref)
+			if err != nil {
+				return nil, validationError("invalid agent pattern %q: %v", part, err)
+			}
+			matched := false
+			for _, agent := range managed {
+				if matcher.Match(agent.AgentID) {
+					add(agent)
+					matched = true
+				}
+			}
+			if !matched {
+				return nil, notFoundError("no managed agents match %s", part)
+			}
+			continue
+		}
+		agent, err := resolveAgentByRef(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		add(*agent)
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("no agents selected")
+	}
+	return agents, nil
+}
+
+// runAgentBulk applies op to each agent, continuing past failures, and
+// reports per-agent results. With dryRun it only lists the targets.
+func runAgentBulk(cmd *cobra.Command, ctx *CommandContext, verb string, agents []types.Agent, dryRun bool, op func(agent types.Agent) (string, error)) error {
+	results := make([]agentOpResult, 0, len(agents))
+	failed := 0
+	for _, agent := range agents {
+		result := agentOpResult{AgentID: agent.AgentID, OK: true}
+		if dryRun {
+			result.Detail = "would " + verb
+		} else if detail, err := op(agent); err != nil {
+			result.OK = false
+			result.Error = err.Error()
+			failed++
+		} else {
+			result.Detail = detail
+		}
+		results = append(results, result)
+	}
+
+	if ctx.JSONMode {
+		if err := json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+			"action":    verb,
+			"dry_run":   dryRun,
+			"results":   results,
+			"succeeded": len(results) - failed,
+			"failed":    failed,
+		}); err != nil {
+			return err
+		}
+	} else {
+		out := cmd.OutOrStdout()
+		for _, result := range results {
+			if result.OK {
+				fmt.Fprintf(out, "  @%s: %s\n", result.AgentID, result.Detail)
+			} else {
+				fmt.Fprintf(out, "  @%s: error: %s\n", result.AgentID, result.Error)
+			}
+		}
+		if dryRun {
+			fmt.Fprintf(out, "Dry run: would %s %d agent(s)\n", verb, len(results))
+		} else {
+			fmt.Fprintf(out, "%d succeeded, %d failed\n", len(results)-failed, failed)
+		}
+	}
+
+	if failed > 0 {
+		return writeCommandError(cmd, fmt.Errorf("%d of %d agent(s) failed to %s", failed, len(results), verb))
+	}
+	return nil
+}
+
+// NewAgentSetCmd updates invoke settings for one or more managed agents.
+func NewAgentSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <name|pattern|a,b,c>",
+		Short: "Update settings for managed agents",
+		Long: `Update invoke settings for managed agents. Only flags you pass change.
+
+Select agents by name, glob ('dev*'), comma list (dev,pm), or --all-managed.
+Durations accept Go syntax (5m, 90s) or plain milliseconds.
+
+Examples:
+  fray agent set 'dev*' --min-checkin 5m
+  fray agent set --all-managed --max-runtime 2h --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			allManaged, _ := cmd.Flags().GetBool("all-managed")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			selector, err := agentSelectorArg(args, allManaged)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			apply, err := agentSetChanges(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			agents, err := resolveAgentSelector(ctx, selector, allManaged)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			return runAgentBulk(cmd, ctx, "update", agents, dryRun, func(agent types.Agent) (string, error) {
+				if !agent.Managed {
+					return "", fmt.Errorf("not managed")
+				}
+				invoke := types.InvokeConfig{}
+				if agent.Invoke != nil {
+					invoke = *agent.Invoke
+				}
+				apply(&invoke)
+				if err := updateManagedAgentConfig(ctx.DB, agent.AgentID, true, &invoke); err != nil {
+					return "", err
+				}
+				managed := true
+				if err := db.AppendAgentUpdate(ctx.Project.DBPath, db.AgentUpdateJSONLRecord{
+					AgentID: agent.AgentID,
+					Managed: &managed,
+					Invoke:  &invoke,
+				}); err != nil {
+					return "", err
+				}
+				return "updated", nil
+			})
+		},
+	}
+
+	addInvokeFlags(cmd)
+	addAgentSelectorFlags(cmd)
+	return cmd
+}
+
+// addInvokeFlags registers the invoke setting flags read by invokeChanges.
+func addInvokeFlags(cmd *cobra.Command) {
+	cmd.Flags().String("driver", "", "CLI driver (claude, codex, opencode)")
+	cmd.Flags().String("prompt-delivery", "", "how prompts are passed (args, stdin, tempfile)")
+	cmd.Flags().String("spawn-timeout", "", "max time in 'spawning' state (e.g. 30s)")
+	cmd.Flags().String("idle-after", "", "time since activity before 'idle' (e.g. 5s)")
+	cmd.Flags().String("min-checkin", "", "done-detection window (e.g. 10m)")
+	cmd.Flags().String("max-runtime", "", "forced termination after (e.g. 2h, 0 = unlimited)")
+}
+
+// agentSetChanges turns the changed agent set flags into an invoke mutation.
+func agentSetChanges(cmd *cobra.Command) (func(*types.InvokeConfig), error) {
+	apply, changed, err := invokeChanges(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, validationError("nothing to set (use --driver, --prompt-delivery, --spawn-timeout, --idle-after, --min-checkin, or --max-runtime)")
+	}
+	return apply, nil
+}
+
+// invokeChanges turns whichever invoke flags were passed into a mutation and
+// reports whether any were.
+func invokeChanges(cmd *cobra.Command) (func(*types.InvokeConfig), bool, error) {
+	var changes []func(*types.InvokeConfig)
+
+	if cmd.Flags().Changed("driver") {
+		driver, _ := cmd.Flags().GetString("driver")
+		if daemon.GetDriver(driver) == nil {
+			return nil, false, validationError("unknown driver: %s (valid: claude, codex, opencode)", driver)
+		}
+		changes = append(changes, func(invoke *types.InvokeConfig) { invoke.Driver = driver })
+	}
+	if cmd.Flags().Changed("prompt-delivery") {
+		delivery, _ := cmd.Flags().GetString("prompt-delivery")
+		switch types.PromptDelivery(delivery) {
+		case types.PromptDeliveryArgs, types.PromptDeliveryStdin, types.PromptDeliveryTempfile:
+		default:
+			return nil, false, validationError("invalid prompt delivery: %s (valid: args, stdin, tempfile)", delivery)
+		}
+		changes = append(changes, func(invoke *types.InvokeConfig) { invoke.PromptDelivery = types.PromptDelivery(delivery) })
+	}
+	durations := []struct {
+		flag  string
+		field func(*types.InvokeConfig) *int64
+	}{
+		{"spawn-timeout", func(invoke *types.InvokeConfig) *int64 { return &invoke.SpawnTimeoutMs }},
+		{"idle-after", func(invoke *types.InvokeConfig) *int64 { return &invoke.IdleAfterMs }},
+		{"min-checkin", func(invoke *types.InvokeConfig) *int64 { return &invoke.MinCheckinMs }},
+		{"max-runtime", func(invoke *types.InvokeConfig) *int64 { return &invoke.MaxRuntimeMs }},
+	}
+	for _, duration := range durations {
+		if !cmd.Flags().Changed(duration.flag) {
+			continue
+		}
+		raw, _ := cmd.Flags().GetString(duration.flag)
+		ms, err := parseMillis(raw)
+		if err != nil {
+			return nil, false, validationError("--%s: %w", duration.flag, err)
+		}
+		field := duration.field
+		changes = append(changes, func(invoke *types.InvokeConfig) { *field(invoke) = ms })
+	}
+
+	return func(invoke *types.InvokeConfig) {
+		for _, change := range changes {
+			change(invoke)
+		}
+	}, len(changes) > 0, nil
+}
+
+// parseMillis parses a Go duration or a plain millisecond count.
+func parseMillis(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil && ms >= 0 {
+		return ms, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q (use 5m, 90s, or milliseconds)", value)
+	}
+	return duration.Milliseconds(), nil
+}
+
+func addAgentSelectorFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("all-managed", false, "apply to every managed agent")
+	cmd.Flags().Bool("dry-run", false, "list target agents without changing anything")
+}
+
+// agentSelectorArg returns the selector argument, requiring exactly one of a
+// selector or --all-managed.
+func agentSelectorArg(args []string, allManaged bool) (string, error) {
+	if allManaged && len(args) > 0 {
+		return "", validationError("use either an agent selector or --all-managed, not both")
+	}
+	if !allManaged && len(args) == 0 {
+		return "", validationError("agent name, pattern, or --all-managed required")
+	}
+	if allManaged {
+		return "", nil
+	}
+	return args[0], nil
+}
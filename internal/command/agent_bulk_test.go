@@ -0,0 +1,135 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+type bulkPayload struct {
+	DryRun    bool            `json:"dry_run"`
+	Results   []agentOpResult `json:"results"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+}
+
+func decodeBulk(t *testing.T, output string) bulkPayload {
+	t.Helper()
+	var payload bulkPayload
+	if err := json.NewDecoder(strings.NewReader(output)).Decode(&payload); err != nil {
+		t.Fatalf("decode bulk output: %v (%q)", err, output)
+	}
+	return payload
+}
+
+func bulkAgentIDs(payload bulkPayload) string {
+	ids := make([]string, 0, len(payload.Results))
+	for _, result := range payload.Results {
+		ids = append(ids, result.AgentID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func newManagedAgents(t *testing.T, agents ...string) string {
+	t.Helper()
+	projectDir := newFlowProject(t, "bob")
+	for _, agent := range agents {
+		runFray(t, "agent", "create", agent)
+	}
+	return projectDir
+}
+
+func TestAgentSelectorResolution(t *testing.T) {
+	newManagedAgents(t, "dev1", "dev2", "pm")
+
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"dev*"}, "dev1,dev2"},
+		{[]string{"pm,dev*,dev1"}, "pm,dev1,dev2"},
+		{[]string{"--all-managed"}, "dev1,dev2,pm"},
+	}
+	for _, tc := range cases {
+		args := append([]string{"--json", "agent", "end", "--dry-run"}, tc.args...)
+		payload := decodeBulk(t, runFray(t, args...))
+		if got := bulkAgentIDs(payload); got != tc.want {
+			t.Fatalf("%v: expected %s, got %s", tc.args, tc.want, got)
+		}
+	}
+
+	if output, err := executeCommand(NewRootCmd("test"), "agent", "end", "qa*"); err == nil {
+		t.Fatalf("expected unmatched pattern to fail, got %q", output)
+	}
+	if output, err := executeCommand(NewRootCmd("test"), "agent", "end", "dev1", "--all-managed"); err == nil {
+		t.Fatalf("expected selector plus --all-managed to fail, got %q", output)
+	}
+}
+
+func TestAgentBulkReportsPartialFailure(t *testing.T) {
+	projectDir := newManagedAgents(t, "dev1", "dev2")
+
+	output, err := executeCommand(NewRootCmd("test"), "--json", "agent", "end", "dev*,bob")
+	if err == nil {
+		t.Fatalf("expected error when one agent fails, got %q", output)
+	}
+	payload := decodeBulk(t, output)
+	if payload.Succeeded != 2 || payload.Failed != 1 {
+		t.Fatalf("expected 2 succeeded and 1 failed, got %+v", payload)
+	}
+	for _, result := range payload.Results {
+		if result.AgentID == "bob" {
+			if result.OK || !strings.Contains(result.Error, "not managed") {
+				t.Fatalf("expected bob to fail as unmanaged, got %+v", result)
+			}
+		} else if !result.OK {
+			t.Fatalf("expected @%s to succeed, got %+v", result.AgentID, result)
+		}
+	}
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	for _, id := range []string{"dev1", "dev2"} {
+		agent, err := db.GetAgent(dbConn, id)
+		if err != nil || agent == nil || agent.Presence != types.PresenceOffline {
+			t.Fatalf("expected @%s offline, got %+v (%v)", id, agent, err)
+		}
+	}
+}
+
+func TestAgentSetDryRunDoesNotMutate(t *testing.T) {
+	projectDir := newManagedAgents(t, "dev1", "dev2", "pm")
+
+	minCheckin := func(id string) int64 {
+		t.Helper()
+		dbConn := openProjectDB(t, projectDir)
+		defer dbConn.Close()
+		agent, err := db.GetAgent(dbConn, id)
+		if err != nil || agent == nil || agent.Invoke == nil {
+			t.Fatalf("get @%s: %+v (%v)", id, agent, err)
+		}
+		return agent.Invoke.MinCheckinMs
+	}
+
+	output := runFray(t, "agent", "set", "dev*", "--min-checkin", "5m", "--dry-run")
+	if !strings.Contains(output, "Dry run: would update 2 agent(s)") {
+		t.Fatalf("expected dry-run summary, got %q", output)
+	}
+	if got := minCheckin("dev1"); got != 600000 {
+		t.Fatalf("dry run changed min_checkin to %d", got)
+	}
+
+	runFray(t, "agent", "set", "dev*", "--min-checkin", "5m")
+	for id, want := range map[string]int64{"dev1": 300000, "dev2": 300000, "pm": 600000} {
+		if got := minCheckin(id); got != want {
+			t.Fatalf("@%s min_checkin = %d, want %d", id, got, want)
+		}
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "agent", "set", "dev1"); err == nil {
+		t.Fatal("expected agent set without changes to fail")
+	}
+}
@@ -2,7 +2,6 @@ package command
 
 import (
 	"database/sql"
-	"fmt"
 	"sort"
 	"strings"
 
@@ -18,7 +17,7 @@ func resolveAgentRef(ctx *CommandContext, ref string) (string, error) {
 		return "", err
 	}
 	if suggestion != "" && !ctx.Force {
-		return "", fmt.Errorf("did you mean @%s? Re-run with --force to use @%s", suggestion, resolved)
+		return "", validationError("did you mean @%s? Re-run with --force to use @%s", suggestion, resolved)
 	}
 	return resolved, nil
 }
@@ -42,14 +41,14 @@ func resolveAgentByRef(ctx *CommandContext, ref string) (*types.Agent, error) {
 		return nil, err
 	}
 	if len(matches) == 0 {
-		return nil, fmt.Errorf("agent not found: %s", ref)
+		return nil, notFoundError("agent not found: %s", ref)
 	}
 	if len(matches) > 1 {
 		ids := make([]string, 0, len(matches))
 		for _, match := range matches {
 			ids = append(ids, match.AgentID)
 		}
-		return nil, fmt.Errorf("ambiguous prefix '%s' matches: %s", ref, joinList(ids))
+		return nil, validationError("ambiguous prefix '%s' matches: %s", ref, joinList(ids))
 	}
 	return &matches[0], nil
 }
@@ -0,0 +1,272 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// agentDetail is everything fray knows about one agent, assembled for
+// fray agent show.
+type agentDetail struct {
+	Agent           types.Agent         `json:"agent"`
+	PresenceSince   *int64              `json:"presence_since,omitempty"` // session start while a session is live
+	Roles           *types.AgentRoles   `json:"roles"`
+	Claims          []types.Claim       `json:"claims"`
+	Subscriptions   []agentSubscription `json:"subscriptions"`
+	PendingMentions int                 `json:"pending_mentions"`
+	Working         *types.AgentWorking `json:"working,omitempty"`
+	LastSession     *types.AgentSession `json:"last_session,omitempty"`
+}
+
+// agentSubscription is a thread subscription with its thread path.
+type agentSubscription struct {
+	types.ThreadSubscription
+	Path string `json:"path"`
+}
+
+// NewAgentShowCmd shows one agent in full.
+func NewAgentShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show everything about one agent",
+		Long: `Show one agent's full record: identity, avatar, driver settings, presence,
+last seen and heartbeat, status, roles, active claims, thread subscriptions,
+queued mentions, working ping, and the last session.
+
+Examples:
+  fray agent show @dev
+  fray agent show dev --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmdCtx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer cmdCtx.DB.Close()
+
+			agent, err := resolveAgentByRef(cmdCtx, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			detail, err := buildAgentDetail(cmdCtx, *agent)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if cmdCtx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(detail)
+			}
+			writeAgentDetail(cmd.OutOrStdout(), detail)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// buildAgentDetail gathers an agent's record from the cache and its session
+// history from JSONL.
+func buildAgentDetail(ctx *CommandContext, agent types.Agent) (*agentDetail, error) {
+	detail := &agentDetail{Agent: agent}
+
+	roles, err := db.GetAgentRoles(ctx.DB, agent.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	detail.Roles = roles
+
+	claims, err := db.GetClaimsByAgent(ctx.DB, agent.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	detail.Claims = claims
+	if detail.Claims == nil {
+		detail.Claims = []types.Claim{}
+	}
+
+	subscriptions, err := db.GetAgentSubscriptions(ctx.DB, agent.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	detail.Subscriptions = make([]agentSubscription, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		path := sub.ThreadGUID
+		thread, err := db.GetThread(ctx.DB, sub.ThreadGUID)
+		if err != nil {
+			return nil, err
+		}
+		if thread != nil {
+			if path, err = buildThreadPath(ctx.DB, thread); err != nil {
+				return nil, err
+			}
+		}
+		detail.Subscriptions = append(detail.Subscriptions, agentSubscription{ThreadSubscription: sub, Path: path})
+	}
+
+	pending, err := db.GetPendingMentions(ctx.DB)
+	if err != nil {
+		return nil, err
+	}
+	detail.PendingMentions = len(pending[agent.AgentID])
+
+	detail.Working, err = db.GetAgentWorking(ctx.DB, agent.AgentID, db.WorkingTTL(ctx.DB), time.Now().UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := db.ReadAgentSessions(ctx.Project.DBPath, agent.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) > 0 {
+		last := sessions[len(sessions)-1]
+		detail.LastSession = &last
+		if last.EndedAt == nil && last.StartedAt > 0 && agent.Presence != "" && agent.Presence != types.PresenceOffline {
+			started := last.StartedAt
+			detail.PresenceSince = &started
+		}
+	}
+	return detail, nil
+}
+
+func writeAgentDetail(out io.Writer, detail *agentDetail) {
+	agent := detail.Agent
+	field := func(label, value string) {
+		fmt.Fprintf(out, "  %-17s %s\n", label+":", value)
+	}
+
+	fmt.Fprintf(out, "@%s\n", agent.AgentID)
+	field("guid", agent.GUID)
+	if agent.ParentAgent != nil {
+		field("parent", "@"+*agent.ParentAgent)
+	}
+	field("avatar", formatOptionalValue(agent.Avatar))
+	field("status", formatOptionalValue(agent.Status))
+	field("purpose", formatOptionalValue(agent.Purpose))
+	if agent.LeftAt != nil {
+		field("left", formatRelative(*agent.LeftAt))
+	}
+
+	presence := string(agent.Presence)
+	if presence == "" {
+		presence = string(types.PresenceOffline)
+	}
+	if detail.PresenceSince != nil {
+		presence += " (since " + formatRelative(*detail.PresenceSince) + ")"
+	}
+	field("presence", presence)
+	field("last seen", formatRelative(agent.LastSeen))
+	if agent.LastHeartbeat != nil {
+		field("last heartbeat", formatRelative(*agent.LastHeartbeat/1000))
+	}
+
+	if agent.Managed && agent.Invoke != nil {
+		field("driver", formatInvokeSummary(agent.Invoke))
+		if len(agent.Invoke.Config) > 0 {
+			keys := make([]string, 0, len(agent.Invoke.Config))
+			for key := range agent.Invoke.Config {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			pairs := make([]string, 0, len(keys))
+			for _, key := range keys {
+				pairs = append(pairs, fmt.Sprintf("%s=%v", key, agent.Invoke.Config[key]))
+			}
+			field("driver config", strings.Join(pairs, ", "))
+		}
+	} else {
+		field("driver", "-- (not managed)")
+	}
+
+	roles := "--"
+	if detail.Roles != nil && (len(detail.Roles.Held) > 0 || len(detail.Roles.Playing) > 0) {
+		roles = strings.Join(detail.Roles.Held, ", ")
+		if len(detail.Roles.Playing) > 0 {
+			if roles != "" {
+				roles += "; "
+			}
+			roles += "playing " + strings.Join(detail.Roles.Playing, ", ")
+		}
+	}
+	field("roles", roles)
+
+	claims := make([]string, 0, len(detail.Claims))
+	for _, claim := range detail.Claims {
+		claims = append(claims, fmt.Sprintf("%s:%s", claim.ClaimType, claim.Pattern))
+	}
+	field("claims", formatListOrNone(claims))
+
+	subscriptions := make([]string, 0, len(detail.Subscriptions))
+	for _, sub := range detail.Subscriptions {
+		label := sub.Path
+		if sub.Level != "" && sub.Level != types.SubscriptionAll {
+			label += " (" + string(sub.Level) + ")"
+		}
+		subscriptions = append(subscriptions, label)
+	}
+	field("subscriptions", formatListOrNone(subscriptions))
+	field("pending mentions", fmt.Sprintf("%d", detail.PendingMentions))
+	if detail.Working != nil {
+		field("working", formatWorkingLine(*detail.Working))
+	}
+
+	if session := detail.LastSession; session != nil {
+		line := session.SessionID
+		if line == "" {
+			line = "-"
+		}
+		if session.StartedAt > 0 {
+			line += ", started " + formatRelative(session.StartedAt)
+		}
+		switch {
+		case session.Outcome != "":
+			line += ", done: " + string(session.Outcome)
+		case session.EndedAt != nil:
+			line += ", ended " + formatRelative(*session.EndedAt)
+		default:
+			line += ", running"
+		}
+		field("last session", line)
+		if session.Summary != nil && *session.Summary != "" {
+			fmt.Fprintf(out, "  %-17s %s\n", "", *session.Summary)
+		}
+	}
+}
+
+// formatInvokeSummary renders a driver and its timeouts on one line.
+func formatInvokeSummary(invoke *types.InvokeConfig) string {
+	parts := []string{}
+	if invoke.PromptDelivery != "" {
+		parts = append(parts, "prompt "+string(invoke.PromptDelivery))
+	}
+	timeouts := []struct {
+		label string
+		ms    int64
+	}{
+		{"spawn", invoke.SpawnTimeoutMs},
+		{"idle", invoke.IdleAfterMs},
+		{"checkin", invoke.MinCheckinMs},
+		{"max", invoke.MaxRuntimeMs},
+	}
+	for _, timeout := range timeouts {
+		if timeout.ms > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", timeout.label, time.Duration(timeout.ms)*time.Millisecond))
+		}
+	}
+	driver := invoke.Driver
+	if driver == "" {
+		driver = "claude"
+	}
+	if len(parts) == 0 {
+		return driver
+	}
+	return fmt.Sprintf("%s (%s)", driver, strings.Join(parts, ", "))
+}
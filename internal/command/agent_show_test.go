@@ -0,0 +1,90 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestAgentShowAssemblesFullRecord(t *testing.T) {
+	projectDir := newFlowProject(t, "pm")
+	runFray(t, "agent", "create", "dev", "--driver", "claude", "--max-runtime", "7200000")
+	runFray(t, "thread", "design")
+	runFray(t, "follow", "design", "--as", "dev", "--level", "mentions")
+	runFray(t, "claim", "dev", "--file", "src/auth.go")
+	runFray(t, "role", "add", "dev", "reviewer")
+	msg := postJSON(t, "post", "--as", "pm", "@dev can you take auth?")
+	msgID := msg["id"].(string)
+
+	conn := openProjectDB(t, projectDir)
+	project, err := core.DiscoverProject(projectDir)
+	if err != nil {
+		t.Fatalf("discover project: %v", err)
+	}
+	now := time.Now()
+	status := "fixing auth"
+	avatar := "🦊"
+	if err := db.UpdateAgent(conn, "dev", db.AgentUpdates{
+		Status: types.OptionalString{Set: true, Value: &status},
+		Avatar: types.OptionalString{Set: true, Value: &avatar},
+	}); err != nil {
+		t.Fatalf("update agent: %v", err)
+	}
+	if err := db.UpdateAgentPresence(conn, "dev", types.PresenceActive); err != nil {
+		t.Fatalf("presence: %v", err)
+	}
+	if err := db.UpdateAgentHeartbeat(conn, "dev", now.UnixMilli()); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if err := db.AddPendingMention(conn, "dev", msgID); err != nil {
+		t.Fatalf("pending mention: %v", err)
+	}
+	if err := db.SetAgentWorking(conn, "dev", &msgID, db.WorkingSourceDaemon, now.UnixMilli()); err != nil {
+		t.Fatalf("working: %v", err)
+	}
+	if err := db.AppendSessionStart(project.DBPath, types.SessionStart{AgentID: "dev", SessionID: "sess-1", TriggeredBy: &msgID, StartedAt: now.Add(-5 * time.Minute).Unix()}); err != nil {
+		t.Fatalf("session start: %v", err)
+	}
+	_ = conn.Close()
+
+	var detail agentDetail
+	output := runFray(t, "agent", "show", "@dev", "--json")
+	if err := json.Unmarshal([]byte(output), &detail); err != nil {
+		t.Fatalf("decode %q: %v", output, err)
+	}
+	switch {
+	case detail.Agent.GUID == "" || detail.Agent.Avatar == nil || detail.Agent.Status == nil:
+		t.Fatalf("missing identity fields: %+v", detail.Agent)
+	case detail.Agent.Invoke == nil || detail.Agent.Invoke.Driver != "claude" || detail.Agent.Invoke.MaxRuntimeMs != 7200000:
+		t.Fatalf("missing driver settings: %+v", detail.Agent.Invoke)
+	case detail.Agent.Presence != types.PresenceActive || detail.PresenceSince == nil || detail.Agent.LastHeartbeat == nil:
+		t.Fatalf("missing presence: %+v", detail)
+	case detail.Roles == nil || len(detail.Roles.Held) != 1:
+		t.Fatalf("missing roles: %+v", detail.Roles)
+	case len(detail.Claims) != 1 || detail.Claims[0].Pattern != "src/auth.go":
+		t.Fatalf("missing claims: %+v", detail.Claims)
+	case len(detail.Subscriptions) != 1 || detail.Subscriptions[0].Path != "design" || detail.Subscriptions[0].Level != types.SubscriptionMentions:
+		t.Fatalf("missing subscriptions: %+v", detail.Subscriptions)
+	case detail.PendingMentions != 1:
+		t.Fatalf("expected 1 pending mention, got %d", detail.PendingMentions)
+	case detail.Working == nil || detail.Working.MessageGUID == nil || *detail.Working.MessageGUID != msgID:
+		t.Fatalf("missing working ping: %+v", detail.Working)
+	case detail.LastSession == nil || detail.LastSession.SessionID != "sess-1":
+		t.Fatalf("missing last session: %+v", detail.LastSession)
+	}
+
+	text := runFray(t, "agent", "show", "dev")
+	for _, want := range []string{
+		"@dev\n", "🦊", "fixing auth", "active (since 5m ago)", "claude (prompt stdin", "max 2h0m0s",
+		"reviewer", "file:src/auth.go", "design (mentions)", "pending mentions: 1", "@dev is working on #" + msgID, "sess-1",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// NewAnnounceCmd creates the announce command.
+func NewAnnounceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "announce <message>",
+		Short: "Post a room-wide announcement",
+		Long: `Post an important room message that mentions @all.
+
+With --ack-required, every active agent is expected to acknowledge it with
+'fray ack <msg>' or a ✅ reaction, and 'fray announce status <msg>' shows who
+hasn't. Set announce_reminder_minutes to have the daemon re-mention the
+stragglers once after that long.
+
+Examples:
+  fray announce "Everyone must rebase onto main" --as pm --ack-required
+  fray announce status msg-abc123
+  fray config announce_reminder_minutes 30`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			agentRef, _ := cmd.Flags().GetString("as")
+			if agentRef == "" {
+				return writeCommandError(cmd, validationError("--as is required"))
+			}
+			agentID, err := resolveAgentRef(ctx, agentRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			agent, err := db.GetAgent(ctx.DB, agentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if agent == nil {
+				return writeCommandError(cmd, notFoundError("agent not found: @%s. Use 'fray new' first", agentID))
+			}
+			if agent.LeftAt != nil {
+				return writeCommandError(cmd, validationError("agent @%s has left. Use 'fray back @%s' to resume", agentID, agentID))
+			}
+
+			body := strings.TrimSpace(args[0])
+			if body == "" {
+				return writeCommandError(cmd, validationError("announcement is empty"))
+			}
+			if err := checkMessageContent(cmd, ctx, body); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			bases, err := db.GetAgentBases(ctx.DB)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			mentions := core.ExtractMentions(body, bases)
+			if !containsString(mentions, "all") {
+				body = "@all " + body
+				mentions = append([]string{"all"}, mentions...)
+			}
+			mentions = core.ExpandAllMention(mentions, bases)
+
+			audience, err := announcementAudience(ctx, agentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			now := time.Now().Unix()
+			created, err := createMessage(cmd, ctx, types.Message{
+				TS:        now,
+				FromAgent: agentID,
+				Body:      body,
+				Mentions:  mentions,
+				Home:      "room",
+				Important: true,
+			})
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.AppendMessage(ctx.Project.DBPath, created); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			ackRequired, _ := cmd.Flags().GetBool("ack-required")
+			announcement := types.Announcement{
+				MessageGUID: created.ID,
+				FromAgent:   agentID,
+				AckRequired: ackRequired,
+				Audience:    audience,
+				CreatedAt:   now,
+			}
+			if err := db.CreateAnnouncement(ctx.DB, announcement); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.AppendAnnouncement(ctx.Project.DBPath, announcement); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			updates := db.AgentUpdates{LastSeen: types.OptionalInt64{Set: true, Value: &now}}
+			if err := db.UpdateAgent(ctx.DB, agentID, updates); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			out := cmd.OutOrStdout()
+			if ctx.JSONMode {
+				return json.NewEncoder(out).Encode(map[string]any{"message": created, "announcement": announcement})
+			}
+			if !ackRequired {
+				fmt.Fprintf(out, "[%s] Announced to @all\n", created.ID)
+				return nil
+			}
+			fmt.Fprintf(out, "[%s] Announced to @all; waiting on %d ack(s). Check with: fray announce status %s\n", created.ID, len(audience), created.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent making the announcement")
+	cmd.Flags().Bool("ack-required", false, "track who has acknowledged the announcement")
+	cmd.Flags().Bool("allow-secrets", false, "post even if the content policy flags a secret")
+	cmd.AddCommand(NewAnnounceStatusCmd())
+	return cmd
+}
+
+// NewAnnounceStatusCmd creates the announce status command.
+func NewAnnounceStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <msg>",
+		Short: "Show who has acknowledged an announcement",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			announcement, err := resolveAnnouncementRef(ctx, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			acks, err := db.GetAnnouncementAcks(ctx.DB, *announcement)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			pending := db.PendingAnnouncementAcks(*announcement, acks)
+
+			out := cmd.OutOrStdout()
+			if ctx.JSONMode {
+				if pending == nil {
+					pending = []string{}
+				}
+				return json.NewEncoder(out).Encode(map[string]any{
+					"announcement": announcement,
+					"acks":         acks,
+					"pending":      pending,
+				})
+			}
+
+			fmt.Fprintf(out, "Announcement %s by @%s (%s)\n", announcement.MessageGUID, announcement.FromAgent, formatRelative(announcement.CreatedAt))
+			if !announcement.AckRequired {
+				fmt.Fprintln(out, "  acks not required")
+				return nil
+			}
+			acked := make([]string, 0, len(acks))
+			for _, ack := range acks {
+				label := "@" + ack.AgentID
+				if ack.Source == db.AckSourceReaction {
+					label += " (" + db.AnnouncementAckReaction + ")"
+				}
+				acked = append(acked, label)
+			}
+			fmt.Fprintf(out, "  acked (%d): %s\n", len(acked), formatListOrNone(acked))
+			waiting := make([]string, 0, len(pending))
+			for _, agentID := range pending {
+				waiting = append(waiting, "@"+agentID)
+			}
+			fmt.Fprintf(out, "  waiting (%d): %s\n", len(waiting), formatListOrNone(waiting))
+			if announcement.RemindedAt != nil {
+				fmt.Fprintf(out, "  reminded %s\n", formatRelative(*announcement.RemindedAt))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// NewAckCmd creates the ack command.
+func NewAckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ack <msg>",
+		Short: "Acknowledge an announcement",
+		Long: `Acknowledge an ack-required announcement. Reacting ✅ to it counts too.
+
+Examples:
+  fray ack msg-abc123 --as dev`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			agentRef, _ := cmd.Flags().GetString("as")
+			if agentRef == "" {
+				return writeCommandError(cmd, validationError("--as is required"))
+			}
+			agentID, err := resolveAgentRef(ctx, agentRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			announcement, err := resolveAnnouncementRef(ctx, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if !announcement.AckRequired {
+				return writeCommandError(cmd, validationError("announcement %s does not require acks", announcement.MessageGUID))
+			}
+
+			acks, err := db.GetAnnouncementAcks(ctx.DB, *announcement)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			ack := types.AnnouncementAck{
+				MessageGUID: announcement.MessageGUID,
+				AgentID:     agentID,
+				Source:      db.AckSourceCommand,
+				AckedAt:     time.Now().Unix(),
+			}
+			already := false
+			for _, existing := range acks {
+				if existing.AgentID == agentID {
+					ack, already = existing, true
+					break
+				}
+			}
+			if !already {
+				if _, err := db.AckAnnouncement(ctx.DB, ack); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if err := db.AppendAnnouncementAck(ctx.Project.DBPath, ack); err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			if ctx.JSONMode {
+				return json.NewEncoder(out).Encode(map[string]any{"ack": ack, "already": already})
+			}
+			if already {
+				fmt.Fprintf(out, "@%s already acked %s\n", agentID, announcement.MessageGUID)
+				return nil
+			}
+			fmt.Fprintf(out, "@%s acked %s\n", agentID, announcement.MessageGUID)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent acknowledging the announcement")
+	return cmd
+}
+
+// resolveAnnouncementRef resolves a message reference to its announcement.
+func resolveAnnouncementRef(ctx *CommandContext, ref string) (*types.Announcement, error) {
+	msg, err := resolveMessageRef(ctx.DB, ref)
+	if err != nil {
+		return nil, err
+	}
+	announcement, err := db.GetAnnouncement(ctx.DB, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	if announcement == nil {
+		return nil, notFoundError("%s is not an announcement", msg.ID)
+	}
+	return announcement, nil
+}
+
+// announcementAudience lists the agents expected to ack an announcement:
+// everyone still around except the announcer and sub-agents.
+func announcementAudience(ctx *CommandContext, announcer string) ([]string, error) {
+	agents, err := db.GetAgents(ctx.DB)
+	if err != nil {
+		return nil, err
+	}
+	var audience []string
+	for _, agent := range agents {
+		if agent.LeftAt != nil || agent.ParentAgent != nil || agent.AgentID == announcer {
+			continue
+		}
+		audience = append(audience, agent.AgentID)
+	}
+	return audience, nil
+}
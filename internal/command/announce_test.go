@@ -0,0 +1,103 @@
+package command
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+type announceStatus struct {
+	Announcement types.Announcement      `json:"announcement"`
+	Acks         []types.AnnouncementAck `json:"acks"`
+	Pending      []string                `json:"pending"`
+}
+
+func getAnnounceStatus(t *testing.T, msgID string) announceStatus {
+	t.Helper()
+	output := runFray(t, "announce", "status", msgID, "--json")
+	var status announceStatus
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		t.Fatalf("decode %q: %v", output, err)
+	}
+	return status
+}
+
+func TestAnnounceAckViaCommandAndReaction(t *testing.T) {
+	projectDir := newFlowProject(t, "pm", "dev", "qa", "ops")
+
+	output := runFray(t, "announce", "Everyone must rebase onto main", "--as", "pm", "--ack-required", "--json")
+	var payload struct {
+		Message types.Message `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode %q: %v", output, err)
+	}
+	msg := payload.Message
+	if !msg.Important || !strings.HasPrefix(msg.Body, "@all ") || len(msg.Mentions) < 3 {
+		t.Fatalf("expected an important @all message, got %+v", msg)
+	}
+
+	status := getAnnounceStatus(t, msg.ID)
+	if !reflect.DeepEqual(status.Pending, []string{"dev", "ops", "qa"}) || len(status.Acks) != 0 {
+		t.Fatalf("expected everyone but pm pending, got %+v", status)
+	}
+
+	if output := runFray(t, "ack", msg.ID, "--as", "dev"); !strings.Contains(output, "@dev acked") {
+		t.Fatalf("unexpected ack output %q", output)
+	}
+	if output := runFray(t, "ack", msg.ID, "--as", "dev"); !strings.Contains(output, "already acked") {
+		t.Fatalf("expected a repeat ack to be a no-op, got %q", output)
+	}
+	runFray(t, "react", "✅", msg.ID, "--as", "qa")
+	runFray(t, "react", "🎉", msg.ID, "--as", "ops")
+
+	status = getAnnounceStatus(t, msg.ID)
+	sources := map[string]string{}
+	for _, ack := range status.Acks {
+		sources[ack.AgentID] = ack.Source
+	}
+	if !reflect.DeepEqual(sources, map[string]string{"dev": db.AckSourceCommand, "qa": db.AckSourceReaction}) {
+		t.Fatalf("expected dev acked by command and qa by reaction, got %+v", status.Acks)
+	}
+	if !reflect.DeepEqual(status.Pending, []string{"ops"}) {
+		t.Fatalf("expected only ops pending, got %v", status.Pending)
+	}
+
+	text := runFray(t, "announce", "status", msg.ID)
+	if !strings.Contains(text, "acked (2): @dev, @qa (✅)") || !strings.Contains(text, "waiting (1): @ops") {
+		t.Fatalf("unexpected status output %q", text)
+	}
+
+	conn := openProjectDB(t, projectDir)
+	if err := db.RebuildDatabaseFromJSONL(conn, projectDir); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	_ = conn.Close()
+	if status := getAnnounceStatus(t, msg.ID); !reflect.DeepEqual(status.Pending, []string{"ops"}) || len(status.Acks) != 2 {
+		t.Fatalf("expected acks to survive a rebuild, got %+v", status)
+	}
+}
+
+func TestAckRejectsPlainAnnouncements(t *testing.T) {
+	newFlowProject(t, "pm", "dev")
+	output := runFray(t, "announce", "@all lunch is here", "--as", "pm", "--json")
+	var payload struct {
+		Message types.Message `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode %q: %v", output, err)
+	}
+	if strings.Count(payload.Message.Body, "@all") != 1 {
+		t.Fatalf("expected @all not to be doubled, got %q", payload.Message.Body)
+	}
+	if _, err := executeCommand(NewRootCmd("test"), "ack", payload.Message.ID, "--as", "dev"); err == nil {
+		t.Fatal("expected acking an announcement without --ack-required to fail")
+	}
+	if _, err := executeCommand(NewRootCmd("test"), "announce", "status", "hello"); err == nil {
+		t.Fatal("expected status on a non-announcement to fail")
+	}
+}
@@ -46,6 +46,8 @@ Interactive mode (for humans):
 Direct mode (for agents):
   fray answer <qstn-id> "answer text" --as agent
                            Answer a specific question directly
+  fray answer <qstn-id> - --as agent          Read the answer from stdin
+  fray answer <qstn-id> --file a.md --as agent  Read the answer from a file
 
 In interactive mode:
   - Type a letter (a, b, c) to select a proposed option
@@ -62,16 +64,25 @@ Skipped questions are offered for review at the end of the session.`,
 			defer ctx.DB.Close()
 
 			agentRef, _ := cmd.Flags().GetString("as")
+			answerFile, _ := cmd.Flags().GetString("file")
 
 			// Direct mode: answer <qstn-id> "answer" --as agent
-			if len(args) >= 2 {
+			if len(args) >= 2 || (len(args) == 1 && answerFile != "") {
 				if agentRef == "" {
 					return writeCommandError(cmd, fmt.Errorf("--as is required for direct answer mode"))
 				}
-				return runDirectAnswer(ctx, args[0], args[1], agentRef)
+				var answerArg string
+				if len(args) >= 2 {
+					answerArg = args[1]
+				}
+				answerText, err := readBodyInput(cmd.InOrStdin(), answerArg, len(args) >= 2, answerFile)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				return runDirectAnswer(cmd, ctx, args[0], answerText, agentRef)
 			}
 
-			// Interactive mode: answer (uses username from config)
+			// Interactive mode: answer (as --as or the logged-in human)
 			if len(args) == 0 {
 				return runInteractiveAnswer(ctx, agentRef)
 			}
@@ -82,11 +93,12 @@ Skipped questions are offered for review at the end of the session.`,
 	}
 
 	cmd.Flags().StringP("as", "", "", "agent identity (required for direct mode)")
+	cmd.Flags().String("file", "", "read the answer from a file (direct mode)")
 	return cmd
 }
 
 // runDirectAnswer handles: fray answer <qstn-id> "answer" --as agent
-func runDirectAnswer(ctx *CommandContext, questionRef, answerText, agentRef string) error {
+func runDirectAnswer(cmd *cobra.Command, ctx *CommandContext, questionRef, answerText, agentRef string) error {
 	agentID, err := resolveAgentRef(ctx, agentRef)
 	if err != nil {
 		return err
@@ -115,6 +127,10 @@ func runDirectAnswer(ctx *CommandContext, questionRef, answerText, agentRef stri
 		return fmt.Errorf("question %s is already answered", question.GUID)
 	}
 
+	if err := checkMentionTypos(cmd.ErrOrStderr(), ctx, answerText); err != nil {
+		return err
+	}
+
 	// For direct mode, post single Q&A formatted message
 	pairs := []qaPair{{question: *question, answer: answerText}}
 	if err := postAnswerSummary(ctx.DB, ctx.Project.DBPath, agentID, pairs); err != nil {
@@ -134,26 +150,30 @@ func runDirectAnswer(ctx *CommandContext, questionRef, answerText, agentRef stri
 	return nil
 }
 
+// answerIdentity picks who interactive answers are for: --as, then
+// FRAY_AGENT_ID, then the logged-in human (migrating a legacy chat username on first use).
+func answerIdentity(ctx *CommandContext, agentRef string) (string, error) {
+	if agentRef != "" {
+		return resolveAgentRef(ctx, agentRef)
+	}
+	if agentID := strings.TrimSpace(os.Getenv("FRAY_AGENT_ID")); agentID != "" {
+		return resolveAgentRef(ctx, agentID)
+	}
+	identity, err := humanIdentity(ctx)
+	if err != nil {
+		return "", err
+	}
+	if identity == "" {
+		return "", fmt.Errorf("no identity configured. Run 'fray login <name>' or specify --as")
+	}
+	return identity, nil
+}
+
 // runInteractiveAnswer handles: fray answer (interactive mode for humans)
 func runInteractiveAnswer(ctx *CommandContext, agentRef string) error {
-	// Determine identity: --as flag or username from config
-	var identity string
-	if agentRef != "" {
-		resolved, err := resolveAgentRef(ctx, agentRef)
-		if err != nil {
-			return err
-		}
-		identity = resolved
-	} else {
-		// Use username from config (human user)
-		username, err := db.GetConfig(ctx.DB, "username")
-		if err != nil {
-			return err
-		}
-		if username == "" {
-			return fmt.Errorf("no username configured. Use 'fray chat' first or specify --as")
-		}
-		identity = username
+	identity, err := answerIdentity(ctx, agentRef)
+	if err != nil {
+		return err
 	}
 
 	// Get open questions addressed to this identity
@@ -364,7 +364,10 @@ func (m answerModel) renderQuestion() string {
 			for _, con := range opt.Cons {
 				b.WriteString(fmt.Sprintf("     %s %s\n", answerConStyle.Render("- Con:"), con))
 			}
-			if len(opt.Pros) > 0 || len(opt.Cons) > 0 {
+			for _, ref := range opt.Refs {
+				b.WriteString(fmt.Sprintf("     %s\n", answerMetaStyle.Render("see #"+ref)))
+			}
+			if len(opt.Pros) > 0 || len(opt.Cons) > 0 || len(opt.Refs) > 0 {
 				b.WriteString("\n")
 			}
 		}
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -74,18 +75,39 @@ func NewAskCmd() *cobra.Command {
 				}
 			}
 
+			optionSpecs, _ := cmd.Flags().GetStringArray("option")
+			if question != nil && len(optionSpecs) > 0 {
+				return writeCommandError(cmd, validationError("--option only applies when asking a new question"))
+			}
+			options := make([]types.QuestionOption, 0, len(optionSpecs))
+			for _, spec := range optionSpecs {
+				option, err := parseQuestionOption(spec)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if err := resolveOptionRefs(ctx.DB, &option); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				options = append(options, option)
+			}
+
+			if err := checkMessageContent(cmd, ctx, questionInput); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
 			now := time.Now().Unix()
 			if question == nil {
 				threadGUID := (*string)(nil)
 				if thread != nil {
 					threadGUID = &thread.GUID
 				}
-				createdQuestion, err := db.CreateQuestion(ctx.DB, types.Question{
+				createdQuestion, err := createQuestion(cmd, ctx, types.Question{
 					Re:         questionInput,
 					FromAgent:  agentID,
 					ToAgent:    toAgent,
 					Status:     types.QuestionStatusOpen,
 					ThreadGUID: threadGUID,
+					Options:    options,
 					CreatedAt:  now,
 				})
 				if err != nil {
@@ -116,7 +138,7 @@ func NewAskCmd() *cobra.Command {
 				home = *question.ThreadGUID
 			}
 
-			created, err := db.CreateMessage(ctx.DB, types.Message{
+			created, err := createMessage(cmd, ctx, types.Message{
 				TS:        now,
 				FromAgent: agentID,
 				Body:      body,
@@ -183,7 +205,52 @@ func NewAskCmd() *cobra.Command {
 	cmd.Flags().String("as", "", "agent ID to ask as")
 	cmd.Flags().String("to", "", "agent to ask")
 	cmd.Flags().String("thread", "", "thread guid or path")
+	cmd.Flags().Bool("allow-secrets", false, "ask even if the content policy flags a secret")
+	cmd.Flags().StringArray("option", nil, `proposed answer as "label:+pro:-con:ref=msg-abc" (repeatable)`)
 	_ = cmd.MarkFlagRequired("as")
 
 	return cmd
 }
+
+// parseQuestionOption parses an --option spec: a label followed by
+// colon-separated parts, each "+pro", "-con", or "ref=<message|thread>".
+func parseQuestionOption(spec string) (types.QuestionOption, error) {
+	parts := strings.Split(spec, ":")
+	option := types.QuestionOption{Label: strings.TrimSpace(parts[0])}
+	if option.Label == "" {
+		return option, validationError("--option %q needs a label", spec)
+	}
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			continue
+		case strings.HasPrefix(part, "+"):
+			option.Pros = append(option.Pros, strings.TrimSpace(part[1:]))
+		case strings.HasPrefix(part, "-"):
+			option.Cons = append(option.Cons, strings.TrimSpace(part[1:]))
+		case strings.HasPrefix(part, "ref="):
+			for _, ref := range strings.Split(strings.TrimPrefix(part, "ref="), ",") {
+				if ref = strings.TrimSpace(ref); ref != "" {
+					option.Refs = append(option.Refs, ref)
+				}
+			}
+		default:
+			return option, validationError("--option %q: %q is not +pro, -con, or ref=<id>", spec, part)
+		}
+	}
+	return option, nil
+}
+
+// resolveOptionRefs checks that each ref names an existing message or
+// thread, replacing it with the canonical GUID.
+func resolveOptionRefs(dbConn *sql.DB, option *types.QuestionOption) error {
+	for i, ref := range option.Refs {
+		_, guid, err := resolveItemRef(dbConn, ref)
+		if err != nil {
+			return validationError("option %q: %v", option.Label, err)
+		}
+		option.Refs[i] = guid
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestParseQuestionOption(t *testing.T) {
+	cases := []struct {
+		spec string
+		want types.QuestionOption
+	}{
+		{"LRU", types.QuestionOption{Label: "LRU"}},
+		{"LRU:+simple:-misses bursts:ref=msg-abc", types.QuestionOption{
+			Label: "LRU", Pros: []string{"simple"}, Cons: []string{"misses bursts"}, Refs: []string{"msg-abc"},
+		}},
+		{"ARC: +adaptive : ref=msg-a,design", types.QuestionOption{
+			Label: "ARC", Pros: []string{"adaptive"}, Refs: []string{"msg-a", "design"},
+		}},
+	}
+	for _, tc := range cases {
+		got, err := parseQuestionOption(tc.spec)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.spec, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("parse %q: got %+v, want %+v", tc.spec, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"", ":+pro", "LRU:maybe"} {
+		if _, err := parseQuestionOption(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func askedQuestion(t *testing.T, projectDir string) types.Question {
+	t.Helper()
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	questions, err := db.GetQuestions(dbConn, nil)
+	if err != nil || len(questions) != 1 {
+		t.Fatalf("expected one question, got %d (%v)", len(questions), err)
+	}
+	return questions[0]
+}
+
+func TestAskOptionRefs(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "cache-design")
+	evidence := postJSON(t, "post", "--as", "bob", "LRU benchmarks: 92% hit rate")["id"].(string)
+
+	// Refs resolve short message IDs and thread names to GUIDs.
+	runFray(t, "ask", "which cache?", "--as", "alice", "--to", "bob",
+		"--option", "LRU:+simple:ref="+strings.TrimPrefix(evidence, "msg-"),
+		"--option", "ARC:-complex:ref=cache-design")
+
+	question := askedQuestion(t, projectDir)
+	if len(question.Options) != 2 {
+		t.Fatalf("expected two options, got %+v", question.Options)
+	}
+	if refs := question.Options[0].Refs; len(refs) != 1 || refs[0] != evidence {
+		t.Fatalf("expected message ref %s, got %v", evidence, refs)
+	}
+	if refs := question.Options[1].Refs; len(refs) != 1 || !strings.HasPrefix(refs[0], "thrd-") {
+		t.Fatalf("expected a thread GUID ref, got %v", refs)
+	}
+
+	// Refs are carried through JSONL, so a rebuild keeps them.
+	records, err := db.ReadQuestions(filepath.Join(projectDir, ".fray"))
+	if err != nil || len(records) != 1 || !reflect.DeepEqual(records[0].Options, question.Options) {
+		t.Fatalf("expected options with refs in questions.jsonl, got %+v (%v)", records, err)
+	}
+
+	// The answer prompt shows the evidence under each option.
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	view := newAnswerModel(dbConn, projectDir, "bob", []types.Question{question}).renderQuestion()
+	if !strings.Contains(view, "see #"+evidence) || !strings.Contains(view, "see #"+question.Options[1].Refs[0]) {
+		t.Fatalf("expected see-lines for refs, got:\n%s", view)
+	}
+}
+
+func TestAskOptionRejectsUnknownRef(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+
+	output, err := executeCommand(NewRootCmd("test"), "ask", "which cache?", "--as", "alice",
+		"--option", "LRU:ref=msg-doesnotexist")
+	if err == nil || !strings.Contains(output, "message not found: msg-doesnotexist") {
+		t.Fatalf("expected bad ref to be rejected, got %v %q", err, output)
+	}
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	if questions, _ := db.GetQuestions(dbConn, nil); len(questions) != 0 {
+		t.Fatalf("expected no question to be created, got %+v", questions)
+	}
+}
+
+func TestPruneKeepsOpenQuestionEvidence(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	evidence := postJSON(t, "post", "--as", "bob", "old benchmark run")["id"].(string)
+	runFray(t, "post", "--as", "bob", "unrelated chatter")
+	runFray(t, "ask", "which cache?", "--as", "alice", "--option", "LRU:ref="+evidence)
+	runFray(t, "post", "--as", "alice", "latest note")
+
+	runFray(t, "prune", "--keep", "1", "--yes")
+
+	data, err := os.ReadFile(filepath.Join(projectDir, ".fray", "messages.jsonl"))
+	if err != nil {
+		t.Fatalf("read messages: %v", err)
+	}
+	if !strings.Contains(string(data), "old benchmark run") {
+		t.Fatalf("expected evidence of an open question to survive prune")
+	}
+	if strings.Contains(string(data), "unrelated chatter") {
+		t.Fatalf("expected unreferenced messages to be pruned")
+	}
+}
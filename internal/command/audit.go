@@ -0,0 +1,209 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// auditedCommands lists the administrative commands recorded in the audit
+// log, by path below the root. The bool marks commands that are only
+// audited when they change something (config with a value).
+var auditedCommands = map[string]bool{
+	"prune":           false,
+	"privacy migrate": false,
+	"archive":         false,
+	"restore":         false,
+	"anchor":          false,
+	"rm":              false,
+	"thread archive":  false,
+	"thread restore":  false,
+	"thread rename":   false,
+	"new":             false,
+	"agent create":    false,
+	"rename":          false,
+	"merge":           false,
+	"destroy":         false,
+	"config":          true,
+	"config import":   false,
+}
+
+// auditSecretFlagPattern matches flag names whose values must never reach
+// the log. Config values go by the key's Secret spec instead.
+var auditSecretFlagPattern = regexp.MustCompile(`(?i)pass(word|phrase)?|secret|token|api[_-]?key|credential`)
+
+const auditRedacted = "[redacted]"
+
+// installAuditHooks wraps the RunE of every audited command under root so it
+// records an audit entry once it finishes. Cobra skips PersistentPostRun when
+// RunE fails, and failures belong in the log too, so the hook wraps RunE
+// rather than hanging off the root.
+func installAuditHooks(root *cobra.Command) {
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+		path := auditCommandPath(cmd)
+		if _, ok := auditedCommands[path]; !ok || cmd.RunE == nil {
+			return
+		}
+		run := cmd.RunE
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			err := run(cmd, args)
+			if auditedCommands[path] && len(args) < 2 {
+				return err
+			}
+			recordAudit(cmd, path, args, err)
+			return err
+		}
+	}
+	walk(root)
+}
+
+// auditCommandPath is a command's path without the root name, e.g.
+// "thread rename".
+func auditCommandPath(cmd *cobra.Command) string {
+	path := cmd.CommandPath()
+	if root := cmd.Root(); root != cmd {
+		path = strings.TrimPrefix(path, root.Name()+" ")
+	}
+	return path
+}
+
+// recordAudit appends an audit entry for a finished command. Auditing is
+// best effort: a command outside a project, or one that destroyed it, is
+// simply not recorded.
+func recordAudit(cmd *cobra.Command, path string, args []string, runErr error) {
+	ctx, err := GetContext(cmd)
+	if err != nil {
+		return
+	}
+	defer ctx.DB.Close()
+
+	entry := db.AuditRecord{
+		Actor:      auditActor(ctx, cmd),
+		Command:    path,
+		Args:       auditArgs(cmd, path, args),
+		RecordedAt: time.Now().Unix(),
+	}
+	if runErr != nil {
+		entry.Error = string(ErrorCode(runErr))
+	}
+	_ = db.AppendAuditEntry(ctx.Project.DBPath, entry)
+}
+
+// auditActor names who ran a command: --as when the command has it, then
+// FRAY_AGENT_ID, then the configured username, then the OS user.
+func auditActor(ctx *CommandContext, cmd *cobra.Command) string {
+	var asRef string
+	if flag := cmd.Flags().Lookup("as"); flag != nil {
+		asRef = flag.Value.String()
+	}
+	if actor, err := curationActor(ctx, asRef); err == nil && actor != "" {
+		return actor
+	} else if asRef != "" {
+		return strings.TrimPrefix(asRef, "@")
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "unknown"
+}
+
+// auditArgs renders the positional arguments and the flags that were set,
+// with secret-looking flag values and secret config values redacted.
+func auditArgs(cmd *cobra.Command, path string, args []string) []string {
+	rendered := append([]string(nil), args...)
+	if path == "config" && len(rendered) == 2 {
+		if spec, _ := lookupConfigKey(rendered[0]); spec.Secret {
+			rendered[1] = auditRedacted
+		}
+	}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		value := flag.Value.String()
+		if auditSecretFlagPattern.MatchString(flag.Name) {
+			value = auditRedacted
+		}
+		rendered = append(rendered, fmt.Sprintf("--%s=%s", flag.Name, value))
+	})
+	return rendered
+}
+
+// NewAuditCmd creates the audit command.
+func NewAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show who ran administrative commands",
+		Long: `Show the audit log: administrative commands (prune, privacy migrate,
+archive, restore, anchor, rm, thread archive/restore/rename, agent
+create/rename/merge, destroy, config changes and imports) with who ran them,
+their arguments, and whether they failed. Secret config values and
+secret-looking flag values are redacted. The log is
+.fray/audit.jsonl and syncs with the rest of the project.
+
+Examples:
+  fray audit
+  fray audit --last 10 --actor adam`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			last, _ := cmd.Flags().GetInt("last")
+			if last < 1 {
+				return writeCommandError(cmd, validationError("--last must be at least 1"))
+			}
+			actor, _ := cmd.Flags().GetString("actor")
+			actor = strings.TrimPrefix(actor, "@")
+
+			all, err := db.ReadAuditEntries(ctx.Project.DBPath)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			entries := make([]db.AuditRecord, 0, len(all))
+			for _, entry := range all {
+				if actor == "" || entry.Actor == actor {
+					entries = append(entries, entry)
+				}
+			}
+			if len(entries) > last {
+				entries = entries[len(entries)-last:]
+			}
+
+			out := cmd.OutOrStdout()
+			if ctx.JSONMode {
+				return json.NewEncoder(out).Encode(map[string]any{"entries": entries})
+			}
+			if len(entries) == 0 {
+				fmt.Fprintln(out, "No audit entries")
+				return nil
+			}
+			for _, entry := range entries {
+				line := fmt.Sprintf("%s  @%s  fray %s", time.Unix(entry.RecordedAt, 0).Format("2006-01-02 15:04:05"), entry.Actor, entry.Command)
+				if len(entry.Args) > 0 {
+					line += " " + strings.Join(entry.Args, " ")
+				}
+				if entry.Error != "" {
+					line += fmt.Sprintf("  (failed: %s)", entry.Error)
+				}
+				fmt.Fprintln(out, line)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Int("last", 50, "number of most recent entries to show")
+	cmd.Flags().String("actor", "", "only entries by this actor")
+	return cmd
+}
@@ -0,0 +1,121 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func TestAuditRecordsAllowlistedCommands(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	t.Setenv("USER", "adam")
+
+	runFray(t, "thread", "design")
+	runFray(t, "post", "--as", "alice", "not an admin action")
+	runFray(t, "config", "stale_hours")
+	runFray(t, "config", "stale_hours", "8")
+	runFray(t, "thread", "rename", "design", "design-v2", "--as", "alice")
+	if _, err := executeCommand(NewRootCmd("test"), "thread", "rename", "nowhere", "somewhere"); err == nil {
+		t.Fatal("expected renaming an unknown thread to fail")
+	}
+
+	entries, err := db.ReadAuditEntries(projectDir)
+	if err != nil {
+		t.Fatalf("read audit: %v", err)
+	}
+	var commands []string
+	for _, entry := range entries {
+		commands = append(commands, entry.Command)
+	}
+	// newFlowProject's "new alice" runs before USER is set, so only check
+	// what follows it.
+	got := strings.Join(commands[1:], ",")
+	if commands[0] != "new" || got != "config,thread rename,thread rename" {
+		t.Fatalf("unexpected audited commands %v", commands)
+	}
+	config, rename, failed := entries[1], entries[2], entries[3]
+	if config.Actor != "adam" || strings.Join(config.Args, " ") != "stale_hours 8" || config.Error != "" {
+		t.Fatalf("unexpected config entry %+v", config)
+	}
+	if rename.Actor != "alice" || strings.Join(rename.Args, " ") != "design design-v2 --as=alice" {
+		t.Fatalf("unexpected thread rename entry %+v", rename)
+	}
+	if failed.Error != string(ErrorKindNotFound) {
+		t.Fatalf("expected the failed rename to record not_found, got %+v", failed)
+	}
+
+	output := runFray(t, "audit", "--actor", "alice", "--json")
+	var payload struct {
+		Entries []db.AuditRecord `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode %q: %v", output, err)
+	}
+	if len(payload.Entries) != 1 || payload.Entries[0].Command != "thread rename" {
+		t.Fatalf("expected only alice's rename, got %+v", payload.Entries)
+	}
+
+	output = runFray(t, "audit", "--last", "1")
+	if strings.Count(output, "\n") != 1 || !strings.Contains(output, "@adam  fray thread rename nowhere somewhere  (failed: not_found)") {
+		t.Fatalf("unexpected audit output %q", output)
+	}
+}
+
+func TestAuditArgsRedactSecrets(t *testing.T) {
+	cmd := &cobra.Command{Use: "prune"}
+	cmd.Flags().String("passphrase", "", "")
+	cmd.Flags().String("api-key", "", "")
+	cmd.Flags().Int("keep", 0, "")
+	if err := cmd.Flags().Parse([]string{"--passphrase", "hunter2", "--api-key=abc", "--keep", "5"}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	got := strings.Join(auditArgs(cmd, "prune", nil), " ")
+	if got != "--api-key=[redacted] --keep=5 --passphrase=[redacted]" {
+		t.Fatalf("unexpected args %q", got)
+	}
+
+	config := &cobra.Command{Use: "config"}
+	if got := auditArgs(config, "config", []string{"slack_token", "xoxb-1"}); got[1] != auditRedacted {
+		t.Fatalf("expected the secret config value redacted, got %v", got)
+	}
+	if got := auditArgs(config, "config", []string{"stale_hours", "8"}); got[1] != "8" {
+		t.Fatalf("expected an ordinary config value kept, got %v", got)
+	}
+	// Redaction follows the key's spec, not how the name looks.
+	if got := auditArgs(config, "config", []string{"webhook_password", "hunter2"}); got[1] != auditRedacted {
+		t.Fatalf("expected a secret-suffix key redacted, got %v", got)
+	}
+	if got := auditArgs(config, "config", []string{"token_budget", "500"}); got[1] != "500" {
+		t.Fatalf("expected a non-secret key kept, got %v", got)
+	}
+}
+
+func TestAuditRecordsTopLevelAdminCommands(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "design")
+	anchor := postJSON(t, "post", "--as", "alice", "--thread", "design", "design summary")["id"].(string)
+	doomed := postJSON(t, "post", "--as", "alice", "posted by mistake")["id"].(string)
+
+	runFray(t, "anchor", "design", anchor, "--as", "alice")
+	runFray(t, "archive", "design")
+	runFray(t, "restore", "design")
+	runFray(t, "rm", doomed, "--as", "alice")
+
+	entries, err := db.ReadAuditEntries(projectDir)
+	if err != nil {
+		t.Fatalf("read audit: %v", err)
+	}
+	var commands []string
+	for _, entry := range entries {
+		commands = append(commands, entry.Command)
+	}
+	if got := strings.Join(commands, ","); got != "new,anchor,archive,restore,rm" {
+		t.Fatalf("unexpected audited commands %q", got)
+	}
+	if rm := entries[len(entries)-1]; len(rm.Args) == 0 || rm.Args[0] != doomed || rm.Actor != "alice" {
+		t.Fatalf("unexpected rm entry %+v", rm)
+	}
+}
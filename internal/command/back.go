@@ -32,6 +32,13 @@ func NewBackCmd() *cobra.Command {
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
+			if len(args) > 1 {
+				// Check the body before any state changes, so a blocked
+				// message does not leave the agent half-updated.
+				if err := checkMessageContent(cmd, ctx, args[1]); err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
 
 			agent, err := db.GetAgent(ctx.DB, agentID)
 			if err != nil {
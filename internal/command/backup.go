@@ -0,0 +1,406 @@
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// backupManifestName is the first entry of every backup archive.
+const backupManifestName = "manifest.json"
+
+// backupLocalFiles are the .fray/local files worth keeping: the local config
+// layer (secrets stripped), private records, and client prefs. The rest of
+// local/ is per-machine state such as the undo log and rebuild stats.
+var backupLocalFiles = map[string]bool{
+	"local/config.json":       true,
+	"local/private.jsonl":     true,
+	"local/client_prefs.json": true,
+}
+
+// backupManifest describes a backup archive's contents.
+type backupManifest struct {
+	CreatedAt      string       `json:"created_at"`
+	ChannelID      string       `json:"channel_id"`
+	ChannelName    string       `json:"channel_name,omitempty"`
+	StorageVersion int          `json:"storage_version"`
+	FrayVersion    string       `json:"fray_version"`
+	Files          []backupFile `json:"files"`
+}
+
+// backupFile is one archived file, relative to .fray/. Records counts the
+// non-blank lines of JSONL files.
+type backupFile struct {
+	Path    string `json:"path"`
+	Records int    `json:"records"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// NewBackupCmd creates the backup command.
+func NewBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Archive the .fray directory",
+		Long: `Write a timestamped tar.gz of the .fray directory before risky operations.
+
+The archive holds the JSONL files, fray-config.json, private records and the
+local config layer, plus a manifest.json with record counts per file, the
+channel id, and the storage and fray versions. The SQLite cache is left out
+(it is rebuilt on restore), as are secret config keys (*_token, *_secret,
+...) and per-machine state such as the undo log.
+
+Archives go to .fray/local/backups by default, which git ignores and later
+backups leave out; pass --out to keep them elsewhere.
+
+Examples:
+  fray backup
+  fray backup --out ~/fray-backups
+  fray backup restore .fray/local/backups/fray-backup-myproj-20260101-120000.tar.gz --into /tmp/copy`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			outDir, _ := cmd.Flags().GetString("out")
+			if outDir == "" {
+				outDir = backupDefaultDir(ctx.Project)
+			}
+			if err := os.MkdirAll(outDir, 0o755); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			name := ctx.ChannelName
+			if name == "" {
+				name = GetProjectName(ctx.Project.Root)
+			}
+			now := time.Now().UTC()
+			archivePath := filepath.Join(outDir, fmt.Sprintf("fray-backup-%s-%s.tar.gz", name, now.Format("20060102-150405")))
+
+			manifest := backupManifest{
+				CreatedAt:   now.Format(time.RFC3339),
+				ChannelID:   ctx.ChannelID,
+				ChannelName: ctx.ChannelName,
+				FrayVersion: cmd.Root().Version,
+			}
+			if ctx.ProjectConfig != nil {
+				manifest.StorageVersion = ctx.ProjectConfig.Version
+			}
+			if err := writeBackup(filepath.Dir(ctx.Project.DBPath), archivePath, &manifest); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"archive":  archivePath,
+					"manifest": manifest,
+				})
+			}
+			records := 0
+			for _, file := range manifest.Files {
+				records += file.Records
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Backed up %d file(s), %d record(s) to %s\n", len(manifest.Files), records, archivePath)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("out", "", "directory for the archive (default: .fray/local/backups)")
+	cmd.AddCommand(NewBackupRestoreCmd())
+	return cmd
+}
+
+// NewBackupRestoreCmd creates the backup restore command.
+func NewBackupRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <archive>",
+		Short: "Unpack a backup and rebuild its database",
+		Long: `Unpack a fray backup into a directory without a .fray, check every file
+against the manifest's record counts, and rebuild the SQLite cache.
+
+Nothing is written to the target unless the whole archive verifies, so a
+truncated or edited archive leaves the directory untouched.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			into, _ := cmd.Flags().GetString("into")
+			root, err := filepath.Abs(into)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			frayDir := filepath.Join(root, ".fray")
+			if _, err := os.Stat(frayDir); err == nil {
+				return writeCommandError(cmd, conflictError("%s already exists; restore into another directory with --into", frayDir))
+			}
+			if err := os.MkdirAll(root, 0o755); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			staging, err := os.MkdirTemp(root, ".fray-restore-")
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer os.RemoveAll(staging)
+			// MkdirTemp makes the directory 0700; give the restored .fray
+			// the permissions fray init would.
+			if err := os.Chmod(staging, 0o755); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			manifest, err := unpackBackup(args[0], staging)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := verifyBackup(staging, manifest); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := os.Rename(staging, frayDir); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			project := core.Project{Root: root, DBPath: filepath.Join(frayDir, "fray.db")}
+			var opts db.RebuildOptions
+			if quiet, _ := cmd.Flags().GetBool("quiet"); !quiet {
+				opts.Progress = cmd.ErrOrStderr()
+			}
+			conn, err := rebuildProjectDatabase(project, opts)
+			if err != nil {
+				return writeCommandError(cmd, fmt.Errorf("rebuild: %w", err))
+			}
+			defer conn.Close()
+
+			if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"into":     root,
+					"manifest": manifest,
+					"rebuilt":  true,
+				})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored %d file(s) from %s into %s and rebuilt the database\n", len(manifest.Files), manifest.CreatedAt, root)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("into", ".", "directory to restore into (must not contain .fray)")
+	cmd.Flags().Bool("quiet", false, "don't print rebuild progress to stderr")
+	return cmd
+}
+
+// backupDefaultDir is where archives go without --out: local/backups under
+// .fray, out of the working tree and out of git.
+func backupDefaultDir(project core.Project) string {
+	return filepath.Join(filepath.Dir(project.DBPath), "local", "backups")
+}
+
+// writeBackup archives frayDir to archivePath, filling in manifest.Files.
+func writeBackup(frayDir, archivePath string, manifest *backupManifest) error {
+	contents := map[string][]byte{}
+	err := filepath.WalkDir(frayDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(frayDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !backupIncludes(rel) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if rel == "local/config.json" {
+			if data, err = stripSecretConfig(data); err != nil {
+				return fmt.Errorf("%s: %w", rel, err)
+			}
+		}
+		contents[rel] = data
+		manifest.Files = append(manifest.Files, backupFile{Path: rel, Records: countRecords(rel, data), Bytes: int64(len(data))})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Path < manifest.Files[j].Path })
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: time.Now()}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	err = write(backupManifestName, append(manifestData, '\n'))
+	for _, file := range manifest.Files {
+		if err != nil {
+			break
+		}
+		err = write(".fray/"+file.Path, contents[file.Path])
+	}
+	for _, closer := range []io.Closer{tw, gz, out} {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		_ = os.Remove(archivePath)
+	}
+	return err
+}
+
+// backupIncludes reports whether a path relative to .fray belongs in a backup.
+func backupIncludes(rel string) bool {
+	if strings.HasPrefix(rel, "local/") {
+		return backupLocalFiles[rel]
+	}
+	for _, suffix := range []string{".db", ".db-wal", ".db-shm"} {
+		if strings.HasSuffix(rel, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// stripSecretConfig drops secret-tagged keys from a config layer file.
+func stripSecretConfig(data []byte) ([]byte, error) {
+	values := map[string]string{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	for key := range values {
+		if spec, ok := lookupConfigKey(key); ok && spec.Secret {
+			delete(values, key)
+		}
+	}
+	out, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// countRecords counts the non-blank lines of a JSONL file; other files have
+// no records.
+func countRecords(rel string, data []byte) int {
+	if !strings.HasSuffix(rel, ".jsonl") {
+		return 0
+	}
+	count := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// unpackBackup extracts an archive's .fray files into dir and returns its
+// manifest.
+func unpackBackup(archivePath, dir string) (*backupManifest, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a fray backup: %w", archivePath, err)
+	}
+	defer gz.Close()
+
+	var manifest *backupManifest
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s is truncated or corrupt: %w", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%s is truncated or corrupt: %w", archivePath, err)
+		}
+
+		if header.Name == backupManifestName {
+			manifest = &backupManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, fmt.Errorf("parse manifest: %w", err)
+			}
+			continue
+		}
+		rel, ok := strings.CutPrefix(header.Name, ".fray/")
+		clean := path.Clean(rel)
+		if !ok || clean != rel || clean == "." || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+			return nil, fmt.Errorf("unexpected archive entry %q", header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(clean))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%s has no %s; not a fray backup", archivePath, backupManifestName)
+	}
+	return manifest, nil
+}
+
+// verifyBackup checks the unpacked files against the manifest.
+func verifyBackup(dir string, manifest *backupManifest) error {
+	var problems []string
+	for _, file := range manifest.Files {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: missing", file.Path))
+			continue
+		}
+		if records := countRecords(file.Path, data); records != file.Records {
+			problems = append(problems, fmt.Sprintf("%s: %d records, manifest says %d", file.Path, records, file.Records))
+		} else if int64(len(data)) != file.Bytes {
+			problems = append(problems, fmt.Sprintf("%s: %d bytes, manifest says %d", file.Path, len(data), file.Bytes))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("backup does not match its manifest:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
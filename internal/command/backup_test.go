@@ -0,0 +1,185 @@
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func backupProject(t *testing.T, outDir string) (string, backupManifest) {
+	t.Helper()
+	output := runFray(t, "backup", "--out", outDir, "--json")
+	var payload struct {
+		Archive  string         `json:"archive"`
+		Manifest backupManifest `json:"manifest"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode backup: %v\n%s", err, output)
+	}
+	return payload.Archive, payload.Manifest
+}
+
+func countProjectMessages(t *testing.T, projectDir string) int {
+	t.Helper()
+	project, err := core.OpenProjectAt(projectDir)
+	if err != nil {
+		t.Fatalf("open project: %v", err)
+	}
+	conn, err := db.OpenDatabase(project)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	messages, err := db.GetMessages(conn, &types.MessageQueryOptions{IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("get messages: %v", err)
+	}
+	return len(messages)
+}
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "design")
+	runFray(t, "post", "--as", "alice", "design", "first design note for the backup")
+	runFray(t, "post", "--as", "bob", "a room message that should survive")
+	runFray(t, "config", "notify_quiet", "20:00-06:00", "--scope", "local")
+	runFray(t, "config", "webhook_token", "hunter2", "--scope", "local")
+	before := countProjectMessages(t, projectDir)
+
+	archive, manifest := backupProject(t, filepath.Join(t.TempDir(), "backups"))
+	if manifest.ChannelID == "" || manifest.StorageVersion == 0 || manifest.FrayVersion != "test" {
+		t.Fatalf("expected channel and versions in manifest, got %+v", manifest)
+	}
+	for _, file := range manifest.Files {
+		if strings.HasSuffix(file.Path, ".db") || file.Path == "local/undo.jsonl" {
+			t.Fatalf("expected %s left out of the backup", file.Path)
+		}
+	}
+
+	into := filepath.Join(t.TempDir(), "restored")
+	runFray(t, "backup", "restore", archive, "--into", into, "--quiet")
+	if after := countProjectMessages(t, into); after != before {
+		t.Fatalf("expected %d messages after restore, got %d", before, after)
+	}
+
+	local, err := os.ReadFile(filepath.Join(into, ".fray", "local", "config.json"))
+	if err != nil {
+		t.Fatalf("read restored local config: %v", err)
+	}
+	if !strings.Contains(string(local), "notify_quiet") || strings.Contains(string(local), "hunter2") {
+		t.Fatalf("expected local config without secrets, got %s", local)
+	}
+
+	info, err := os.Stat(filepath.Join(into, ".fray"))
+	if err != nil || info.Mode().Perm() != 0o755 {
+		t.Fatalf("expected restored .fray to be 0755, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "backup", "restore", archive, "--into", into); err == nil {
+		t.Fatal("expected restore over an existing .fray to fail")
+	}
+}
+
+func TestBackupDefaultsToLocalDir(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "post", "--as", "alice", "something worth keeping")
+
+	archive, _ := backupProject(t, "")
+	if filepath.Dir(archive) != filepath.Join(projectDir, ".fray", "local", "backups") {
+		t.Fatalf("expected the archive under .fray/local/backups, got %s", archive)
+	}
+
+	// A later backup leaves earlier archives out.
+	_, manifest := backupProject(t, "")
+	for _, file := range manifest.Files {
+		if strings.HasPrefix(file.Path, "local/backups/") {
+			t.Fatalf("expected earlier archives left out, got %s", file.Path)
+		}
+	}
+}
+
+// rewriteArchive copies a backup, passing each entry's data through edit.
+func rewriteArchive(t *testing.T, src, dst string, edit func(name string, data []byte) []byte) {
+	t.Helper()
+	in, err := os.Open(src)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer in.Close()
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	var buf bytes.Buffer
+	outGz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(outGz)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read archive: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		data = edit(header.Name, data)
+		header.Size = int64(len(data))
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("write header: %v", err)
+		}
+		_, _ = tw.Write(data)
+	}
+	_ = tw.Close()
+	_ = outGz.Close()
+	if err := os.WriteFile(dst, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write archive: %v", err)
+	}
+}
+
+func TestBackupRestoreRejectsDamagedArchives(t *testing.T) {
+	newFlowProject(t, "alice")
+	runFray(t, "post", "--as", "alice", "a message worth keeping around")
+	archive, _ := backupProject(t, t.TempDir())
+
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+	truncated := filepath.Join(t.TempDir(), "truncated.tar.gz")
+	if err := os.WriteFile(truncated, data[:len(data)/2], 0o644); err != nil {
+		t.Fatalf("write truncated: %v", err)
+	}
+	into := filepath.Join(t.TempDir(), "restored")
+	output, err := executeCommand(NewRootCmd("test"), "backup", "restore", truncated, "--into", into)
+	if err == nil || !strings.Contains(output, "truncated") {
+		t.Fatalf("expected truncated archive to fail, got %v %q", err, output)
+	}
+
+	// A well-formed archive missing a message line fails the manifest check.
+	dropped := filepath.Join(t.TempDir(), "dropped.tar.gz")
+	rewriteArchive(t, archive, dropped, func(name string, data []byte) []byte {
+		if name != ".fray/messages.jsonl" {
+			return data
+		}
+		lines := strings.SplitAfter(string(data), "\n")
+		return []byte(strings.Join(lines[1:], ""))
+	})
+	output, err = executeCommand(NewRootCmd("test"), "backup", "restore", dropped, "--into", into)
+	if err == nil || !strings.Contains(output, "messages.jsonl") {
+		t.Fatalf("expected manifest mismatch on messages.jsonl, got %v %q", err, output)
+	}
+	if _, err := os.Stat(filepath.Join(into, ".fray")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing restored from a damaged archive, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/gobwas/glob"
+	"github.com/spf13/cobra"
+)
+
+// BlameEntry is one claim in a `fray blame` timeline.
+type BlameEntry struct {
+	types.ClaimHistoryEntry
+	State string `json:"state"` // "active" | "cleared" | "expired"
+}
+
+// NewBlameCmd creates the blame command.
+func NewBlameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "blame <path>",
+		Short: "Show which agents have claimed a file",
+		Long: `Show the claim history for a file: every file claim whose pattern
+matches the path, including claims that were cleared or expired, with the
+agent's status at the time it claimed.
+
+Examples:
+  fray blame internal/db/schema.go
+  fray blame src/app.ts --since 14d`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			path := normalizeBlamePath(ctx.Project.Root, args[0])
+
+			var cutoff int64
+			if since, _ := cmd.Flags().GetString("since"); since != "" {
+				seconds, err := parseDuration(since)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				cutoff = time.Now().Unix() - seconds
+			}
+
+			history, err := db.ReadClaimHistory(ctx.Project.DBPath)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			entries := blameTimeline(history, path, cutoff, time.Now().Unix())
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"path":   path,
+					"claims": entries,
+				})
+			}
+
+			out := cmd.OutOrStdout()
+			if len(entries) == 0 {
+				fmt.Fprintf(out, "No claims on %s\n", path)
+				return nil
+			}
+			fmt.Fprintf(out, "%s (%d claims):\n", path, len(entries))
+			for _, entry := range entries {
+				fmt.Fprintf(out, "  %s  @%s  %s  %s\n", formatBlameTime(entry.CreatedAt), entry.AgentID, entry.Pattern, blameStateLabel(entry))
+				if entry.Status != nil && *entry.Status != "" {
+					fmt.Fprintf(out, "      status: %s\n", *entry.Status)
+				}
+				if entry.Reason != nil && *entry.Reason != "" && (entry.Status == nil || *entry.Reason != *entry.Status) {
+					fmt.Fprintf(out, "      reason: %s\n", *entry.Reason)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("since", "", "only claims held within this window (e.g. 14d, 2h)")
+	return cmd
+}
+
+// blameTimeline returns the file claims in history matching path, oldest
+// first. With a non-zero cutoff, claims that ended before it are dropped.
+func blameTimeline(history []types.ClaimHistoryEntry, path string, cutoff, now int64) []BlameEntry {
+	entries := []BlameEntry{}
+	for _, claim := range history {
+		if claim.ClaimType != types.ClaimTypeFile || !claimPatternMatches(claim.Pattern, path) {
+			continue
+		}
+		entry := BlameEntry{ClaimHistoryEntry: claim, State: "active"}
+		end := now
+		switch {
+		case claim.ExpiresAt != nil && *claim.ExpiresAt <= now && (claim.ClearedAt == nil || *claim.ExpiresAt < *claim.ClearedAt):
+			entry.State = "expired"
+			end = *claim.ExpiresAt
+		case claim.ClearedAt != nil:
+			entry.State = "cleared"
+			end = *claim.ClearedAt
+		}
+		if cutoff > 0 && end < cutoff {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt < entries[j].CreatedAt
+	})
+	return entries
+}
+
+// claimPatternMatches reports whether a file claim pattern covers path,
+// either literally or as a glob.
+func claimPatternMatches(pattern, path string) bool {
+	if pattern == path {
+		return true
+	}
+	matcher, err := glob.Compile(pattern)
+	if err != nil {
+		return false
+	}
+	return matcher.Match(path)
+}
+
+// normalizeBlamePath turns path into the project-relative, slash-separated
+// form claims are written in. Paths outside the project are kept as given.
+func normalizeBlamePath(root, path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		if rel, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func blameStateLabel(entry BlameEntry) string {
+	switch entry.State {
+	case "cleared":
+		return "cleared " + formatBlameTime(*entry.ClearedAt)
+	case "expired":
+		return "expired " + formatBlameTime(*entry.ExpiresAt)
+	default:
+		return "active"
+	}
+}
+
+func formatBlameTime(ts int64) string {
+	return time.Unix(ts, 0).Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestBlameTimelineMatchesGlobsInOrder(t *testing.T) {
+	expired := int64(150)
+	cleared := int64(400)
+	history := []types.ClaimHistoryEntry{
+		{AgentID: "carol", ClaimType: types.ClaimTypeFile, Pattern: "src/**/*.go", CreatedAt: 300},
+		{AgentID: "alice", ClaimType: types.ClaimTypeFile, Pattern: "src/db/schema.go", CreatedAt: 100, ClearedAt: &cleared},
+		{AgentID: "bob", ClaimType: types.ClaimTypeFile, Pattern: "src/db/*", CreatedAt: 50, ExpiresAt: &expired},
+		{AgentID: "dave", ClaimType: types.ClaimTypeFile, Pattern: "docs/*.md", CreatedAt: 200},
+		{AgentID: "erin", ClaimType: types.ClaimTypeBD, Pattern: "src/db/schema.go", CreatedAt: 250},
+	}
+
+	entries := blameTimeline(history, "src/db/schema.go", 0, 1000)
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.AgentID+":"+entry.State)
+	}
+	want := []string{"bob:expired", "alice:cleared", "carol:active"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	recent := blameTimeline(history, "src/db/schema.go", 200, 1000)
+	if len(recent) != 2 || recent[0].AgentID != "alice" || recent[1].AgentID != "carol" {
+		t.Fatalf("expected claims ending after the cutoff, got %+v", recent)
+	}
+}
+
+func TestBlameIncludesClearedClaims(t *testing.T) {
+	newFlowProject(t, "dev", "pm")
+	runFray(t, "status", "@dev", "fixing the parser", "--file", "src/parser/*.go")
+	runFray(t, "clear", "@dev")
+	runFray(t, "claim", "@pm", "--file", "src/parser/lexer.go")
+
+	output := runFray(t, "--json", "blame", "src/parser/lexer.go")
+	var payload struct {
+		Path   string       `json:"path"`
+		Claims []BlameEntry `json:"claims"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode blame: %v (%q)", err, output)
+	}
+	if payload.Path != "src/parser/lexer.go" {
+		t.Fatalf("unexpected path %q", payload.Path)
+	}
+	if len(payload.Claims) != 2 {
+		t.Fatalf("expected two claims, got %+v", payload.Claims)
+	}
+	first, second := payload.Claims[0], payload.Claims[1]
+	if first.AgentID != "dev" || first.State != "cleared" || first.ClearedAt == nil {
+		t.Fatalf("expected cleared claim by dev first, got %+v", first)
+	}
+	if first.Status == nil || *first.Status != "fixing the parser" {
+		t.Fatalf("expected status at claim time, got %+v", first.Status)
+	}
+	if second.AgentID != "pm" || second.State != "active" {
+		t.Fatalf("expected active claim by pm, got %+v", second)
+	}
+}
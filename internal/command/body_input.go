@@ -0,0 +1,57 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinBodyArg is the positional body that means "read it from stdin".
+const stdinBodyArg = "-"
+
+// maxBodyInputBytes caps bodies read from stdin or --file.
+const maxBodyInputBytes = 256 * 1024
+
+// readBodyInput returns the message body for commands that take it as an
+// argument, from stdin ("-"), or from --file. hasArg reports whether a
+// positional body was given at all. Bodies read from stdin or a file lose
+// their trailing newlines so heredocs and editors don't leave a blank line.
+func readBodyInput(stdin io.Reader, arg string, hasArg bool, filePath string) (string, error) {
+	if filePath != "" {
+		if hasArg {
+			if arg == stdinBodyArg {
+				return "", validationError("read the body from stdin (-) or --file, not both")
+			}
+			return "", validationError("got a message argument and --file; pass the body one way")
+		}
+		f, err := os.Open(filePath)
+		if err != nil {
+			return "", validationError("cannot read --file: %v", err)
+		}
+		defer f.Close()
+		return readBodyFrom(f, filePath)
+	}
+	if !hasArg {
+		return "", validationError("message body required (as an argument, - for stdin, or --file)")
+	}
+	if arg != stdinBodyArg {
+		return arg, nil
+	}
+	return readBodyFrom(stdin, "stdin")
+}
+
+func readBodyFrom(r io.Reader, source string) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBodyInputBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", source, err)
+	}
+	if len(data) > maxBodyInputBytes {
+		return "", validationError("message from %s is larger than %d KB", source, maxBodyInputBytes/1024)
+	}
+	body := strings.TrimRight(string(data), "\r\n")
+	if strings.TrimSpace(body) == "" {
+		return "", validationError("message from %s is empty", source)
+	}
+	return body, nil
+}
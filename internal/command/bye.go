@@ -32,6 +32,11 @@ func NewByeCmd() *cobra.Command {
 			if len(args) > 1 {
 				message = args[1]
 			}
+			// Check the body before any state changes, so a blocked message
+			// does not leave the agent half-updated.
+			if err := checkMessageContent(cmd, ctx, message); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
 			agent, err := db.GetAgent(ctx.DB, agentID)
 			if err != nil {
@@ -43,10 +48,17 @@ func NewByeCmd() *cobra.Command {
 
 			now := time.Now().Unix()
 			nowMs := time.Now().UnixMilli()
+			releasedClaims, err := db.GetClaimsByAgent(ctx.DB, agentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
 			clearedClaims, err := db.DeleteClaimsByAgent(ctx.DB, agentID)
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
+			if err := appendClaimClears(ctx, releasedClaims); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
 			// Clear session roles
 			sessionRoles, err := db.GetSessionRoles(ctx.DB, agentID)
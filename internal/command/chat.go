@@ -15,9 +15,10 @@ import (
 // NewChatCmd creates the chat command.
 func NewChatCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "chat [channel]",
-		Short: "Interactive chat mode",
-		Args:  cobra.MaximumNArgs(1),
+		Use:         "chat [channel]",
+		Short:       "Interactive chat mode",
+		Annotations: map[string]string{noJSONAnnotation: "true"},
+		Args:        cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode {
 				return writeCommandError(cmd, fmt.Errorf("--json not supported for interactive chat"))
@@ -75,7 +76,7 @@ func NewChatCmd() *cobra.Command {
 
 			defer ctx.DB.Close()
 
-			username, err := db.GetConfig(ctx.DB, "username")
+			username, err := humanIdentity(ctx)
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
@@ -84,7 +85,7 @@ func NewChatCmd() *cobra.Command {
 				if username == "" {
 					return writeCommandError(cmd, fmt.Errorf("username is required"))
 				}
-				if err := db.SetConfig(ctx.DB, "username", username); err != nil {
+				if err := db.SetConfigLayer(ctx.DB, ctx.Project.DBPath, db.ConfigScopeLocal, db.HumanIdentityConfigKey, username); err != nil {
 					return writeCommandError(cmd, err)
 				}
 			}
@@ -3,6 +3,7 @@ package command
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/adamavenir/fray/internal/db"
@@ -15,7 +16,19 @@ func NewClaimCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "claim <agent>",
 		Short: "Claim resources to prevent collision",
-		Args:  cobra.ExactArgs(1),
+		Long: `Claim files, issues, or branches so other agents know you are working on them.
+
+Say why with --note, and link the message that explains the work with --msg.
+Both show up in "fray claims" and in the pre-commit conflict warning.
+
+Examples:
+  fray claim alice --file src/auth.ts --note "refactoring token refresh"
+  fray claim alice --files "src/auth/*" --msg msg-abc123 --ttl 2h
+
+Handing a claim over:
+  fray claim request @alice --file src/auth.ts --as bob --note "need 10 min"
+  fray claim release --file src/auth.ts --as alice --to @bob`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, err := GetContext(cmd)
 			if err != nil {
@@ -33,7 +46,7 @@ func NewClaimCmd() *cobra.Command {
 				return writeCommandError(cmd, err)
 			}
 			if agent == nil {
-				return writeCommandError(cmd, fmt.Errorf("agent not found: @%s", agentID))
+				return writeCommandError(cmd, notFoundError("agent not found: @%s", agentID))
 			}
 
 			if _, err := db.PruneExpiredClaims(ctx.DB); err != nil {
@@ -41,7 +54,21 @@ func NewClaimCmd() *cobra.Command {
 			}
 
 			ttl, _ := cmd.Flags().GetString("ttl")
-			reason, _ := cmd.Flags().GetString("reason")
+			note, _ := cmd.Flags().GetString("note")
+			if reason, _ := cmd.Flags().GetString("reason"); reason != "" {
+				if note != "" {
+					return writeCommandError(cmd, validationError("use --note or --reason, not both"))
+				}
+				note = reason
+			}
+			var messageGUID *string
+			if msgRef, _ := cmd.Flags().GetString("msg"); msgRef != "" {
+				msg, err := resolveMessageRef(ctx.DB, msgRef)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				messageGUID = &msg.ID
+			}
 			var expiresAt *int64
 			if ttl != "" {
 				seconds, err := parseDuration(ttl)
@@ -57,22 +84,46 @@ func NewClaimCmd() *cobra.Command {
 				return writeCommandError(cmd, err)
 			}
 			if len(claims) == 0 {
-				return writeCommandError(cmd, fmt.Errorf("no claims specified. Use --file, --files, --bd, or --issue"))
+				return writeCommandError(cmd, validationError("no claims specified. Use --file, --files, --bd, --issue, or --branch"))
 			}
 
 			created := make([]types.Claim, 0, len(claims))
+			claimed := make([]types.ClaimInput, 0, len(claims))
 			for _, claim := range claims {
+				// Another agent's branch claim is a warning, not an error: the
+				// rest of the claims still go through.
+				if claim.ClaimType == types.ClaimTypeBranch {
+					existing, err := db.GetClaim(ctx.DB, types.ClaimTypeBranch, claim.Pattern)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
+					if existing != nil && existing.AgentID != agentID {
+						fmt.Fprintf(cmd.ErrOrStderr(), "Warning: branch %s is already claimed by @%s; not claimed\n", claim.Pattern, existing.AgentID)
+						continue
+					}
+					if existing != nil {
+						continue
+					}
+				}
 				createdClaim, err := db.CreateClaim(ctx.DB, types.ClaimInput{
-					AgentID:   agentID,
-					ClaimType: claim.ClaimType,
-					Pattern:   claim.Pattern,
-					Reason:    optionalString(reason),
-					ExpiresAt: expiresAt,
+					AgentID:     agentID,
+					ClaimType:   claim.ClaimType,
+					Pattern:     claim.Pattern,
+					Reason:      optionalString(note),
+					MessageGUID: messageGUID,
+					ExpiresAt:   expiresAt,
 				})
 				if err != nil {
 					return writeCommandError(cmd, err)
 				}
 				created = append(created, *createdClaim)
+				claimed = append(claimed, claim)
+			}
+			if len(created) == 0 {
+				return writeCommandError(cmd, conflictError("nothing claimed"))
+			}
+			if err := appendClaimHistory(ctx, created, agent.Status); err != nil {
+				return writeCommandError(cmd, err)
 			}
 
 			claimList := buildClaimList(created)
@@ -101,7 +152,7 @@ func NewClaimCmd() *cobra.Command {
 
 			out := cmd.OutOrStdout()
 			fmt.Fprintf(out, "@%s claimed:\n", agentID)
-			for _, claim := range claims {
+			for _, claim := range claimed {
 				typePrefix := ""
 				if claim.ClaimType != types.ClaimTypeFile {
 					typePrefix = fmt.Sprintf("%s:", claim.ClaimType)
@@ -121,8 +172,14 @@ func NewClaimCmd() *cobra.Command {
 	cmd.Flags().String("files", "", "claim multiple files (comma-separated globs)")
 	cmd.Flags().String("bd", "", "claim a beads issue")
 	cmd.Flags().String("issue", "", "claim a GitHub issue")
+	cmd.Flags().String("branch", "", "claim a git branch")
 	cmd.Flags().String("ttl", "", "expiration time (e.g., 2h, 30m, 1d)")
-	cmd.Flags().String("reason", "", "reason for claim")
+	cmd.Flags().String("note", "", "what you are doing (shown to other agents)")
+	cmd.Flags().String("reason", "", "alias for --note")
+	cmd.Flags().String("msg", "", "message that explains the claim")
+	_ = cmd.Flags().MarkHidden("reason")
+
+	cmd.AddCommand(NewClaimRequestCmd(), NewClaimReleaseCmd())
 
 	return cmd
 }
@@ -132,6 +189,7 @@ func collectClaims(cmd *cobra.Command) ([]types.ClaimInput, error) {
 	files, _ := cmd.Flags().GetString("files")
 	bd, _ := cmd.Flags().GetString("bd")
 	issue, _ := cmd.Flags().GetString("issue")
+	branch, _ := cmd.Flags().GetString("branch")
 
 	claims := []types.ClaimInput{}
 	if file != "" {
@@ -148,10 +206,49 @@ func collectClaims(cmd *cobra.Command) ([]types.ClaimInput, error) {
 	if issue != "" {
 		claims = append(claims, types.ClaimInput{ClaimType: types.ClaimTypeIssue, Pattern: stripHash(issue)})
 	}
+	if branch = strings.TrimSpace(branch); branch != "" {
+		claims = append(claims, types.ClaimInput{ClaimType: types.ClaimTypeBranch, Pattern: branch})
+	}
 
 	return claims, nil
 }
 
+// appendClaimHistory records new claims in JSONL so they stay visible to
+// `fray blame` after they are cleared or expire.
+func appendClaimHistory(ctx *CommandContext, claims []types.Claim, status *string) error {
+	for _, claim := range claims {
+		if err := db.AppendClaim(ctx.Project.DBPath, claim, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendClaimClears records released claims in JSONL.
+func appendClaimClears(ctx *CommandContext, claims []types.Claim) error {
+	now := time.Now().Unix()
+	for _, claim := range claims {
+		if err := db.AppendClaimClear(ctx.Project.DBPath, claim, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claimFlag returns the claim command flag that names a claim.
+func claimFlag(claimType types.ClaimType, pattern string) []string {
+	switch claimType {
+	case types.ClaimTypeBD:
+		return []string{"--bd", pattern}
+	case types.ClaimTypeIssue:
+		return []string{"--issue", pattern}
+	case types.ClaimTypeBranch:
+		return []string{"--branch", pattern}
+	default:
+		return []string{"--file", pattern}
+	}
+}
+
 func buildClaimList(claims []types.Claim) string {
 	parts := make([]string, 0, len(claims))
 	for _, claim := range claims {
@@ -167,10 +264,29 @@ func buildClaimList(claims []types.Claim) string {
 func claimsToPayload(claims []types.Claim) []map[string]any {
 	payload := make([]map[string]any, 0, len(claims))
 	for _, claim := range claims {
-		payload = append(payload, map[string]any{
+		entry := map[string]any{
 			"type":    claim.ClaimType,
 			"pattern": claim.Pattern,
-		})
+		}
+		if claim.Reason != nil {
+			entry["note"] = *claim.Reason
+		}
+		if claim.MessageGUID != nil {
+			entry["message_guid"] = *claim.MessageGUID
+		}
+		payload = append(payload, entry)
 	}
 	return payload
 }
+
+// claimContext renders a claim's note and linked message as " - note (msg-x)".
+func claimContext(claim types.Claim) string {
+	context := ""
+	if claim.Reason != nil && *claim.Reason != "" {
+		context = " - " + *claim.Reason
+	}
+	if claim.MessageGUID != nil && *claim.MessageGUID != "" {
+		context += fmt.Sprintf(" (%s)", *claim.MessageGUID)
+	}
+	return context
+}
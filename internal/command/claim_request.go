@@ -0,0 +1,346 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// NewClaimRequestCmd creates the claim request command.
+func NewClaimRequestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "request <holder>",
+		Short: "Ask a claim's holder to hand it over",
+		Long: `Ask the agent holding a claim to release it to you. The holder is
+mentioned with the exact release command, and the request stays listed in
+"fray claims" until the claim is released.
+
+Example:
+  fray claim request @dev --file src/auth.ts --as pm --note "need 10 min"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			asRef, _ := cmd.Flags().GetString("as")
+			if asRef == "" {
+				return writeCommandError(cmd, validationError("--as is required"))
+			}
+			requester, err := resolveAgentByRef(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			holder, err := resolveAgentRef(ctx, strings.TrimPrefix(args[0], "@"))
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if holder == requester.AgentID {
+				return writeCommandError(cmd, validationError("cannot request your own claims"))
+			}
+			note, _ := cmd.Flags().GetString("note")
+
+			held, err := heldClaims(cmd, ctx, holder)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			release := []string{"fray claim release"}
+			for _, claim := range held {
+				release = append(release, strings.Join(claimFlag(claim.ClaimType, claim.Pattern), " "))
+			}
+			release = append(release, "--as", holder, "--to", requester.AgentID)
+			body := fmt.Sprintf("@%s claim request: %s", holder, buildClaimList(held))
+			if note != "" {
+				body += " - " + note
+			}
+			body += "\nTo hand it over: " + strings.Join(release, " ")
+
+			now := time.Now().Unix()
+			msg, err := db.CreateMessage(ctx.DB, types.Message{
+				TS:        now,
+				FromAgent: requester.AgentID,
+				Body:      body,
+				Mentions:  []string{holder},
+			})
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.AppendMessage(ctx.Project.DBPath, msg); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			requests := make([]types.ClaimRequest, 0, len(held))
+			for _, claim := range held {
+				guid, err := core.GenerateGUID("creq")
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				request := types.ClaimRequest{
+					GUID:        guid,
+					RequestedBy: requester.AgentID,
+					Holder:      holder,
+					ClaimType:   claim.ClaimType,
+					Pattern:     claim.Pattern,
+					Note:        optionalString(note),
+					MessageGUID: msg.ID,
+					RequestedAt: now,
+				}
+				if err := db.AppendClaimRequest(ctx.Project.DBPath, request); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				requests = append(requests, request)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"requests":   requests,
+					"message_id": msg.ID,
+				})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Asked @%s for %s (%s)\n", holder, buildClaimList(held), msg.ID)
+			return nil
+		},
+	}
+
+	addClaimTargetFlags(cmd)
+	cmd.Flags().String("as", "", "agent making the request")
+	cmd.Flags().String("note", "", "why you need it (shown to the holder)")
+	return cmd
+}
+
+// NewClaimReleaseCmd creates the claim release command.
+func NewClaimReleaseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Release claims, optionally straight to another agent",
+		Long: `Release claims you hold. With --to the claims pass directly to that agent,
+so nobody else can grab them in between; pending requests for the claims are
+resolved either way.
+
+Example:
+  fray claim release --file src/auth.ts --as dev --to @pm`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			asRef, _ := cmd.Flags().GetString("as")
+			if asRef == "" {
+				return writeCommandError(cmd, validationError("--as is required"))
+			}
+			holder, err := resolveAgentRef(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			var recipient *types.Agent
+			if toRef, _ := cmd.Flags().GetString("to"); toRef != "" {
+				recipient, err = resolveAgentByRef(ctx, strings.TrimPrefix(toRef, "@"))
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if recipient.AgentID == holder {
+					return writeCommandError(cmd, validationError("@%s already holds these claims", holder))
+				}
+			}
+
+			held, err := heldClaims(cmd, ctx, holder)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			requesters, requestFor, err := pendingRequesters(ctx, held)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			now := time.Now().Unix()
+			for _, claim := range held {
+				if _, err := db.DeleteClaim(ctx.DB, claim.ClaimType, claim.Pattern); err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
+
+			claimList := buildClaimList(held)
+			var body string
+			var mentions []string
+			if recipient != nil {
+				granted := make([]types.Claim, 0, len(held))
+				for _, claim := range held {
+					if err := db.AppendClaimTransfer(ctx.Project.DBPath, claim, recipient.AgentID, now); err != nil {
+						return writeCommandError(cmd, err)
+					}
+					input := types.ClaimInput{AgentID: recipient.AgentID, ClaimType: claim.ClaimType, Pattern: claim.Pattern}
+					if request, ok := requestFor[claimKey(claim)]; ok && request.RequestedBy == recipient.AgentID {
+						input.Reason = request.Note
+						input.MessageGUID = &request.MessageGUID
+					}
+					created, err := db.CreateClaim(ctx.DB, input)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
+					granted = append(granted, *created)
+				}
+				if err := appendClaimHistory(ctx, granted, recipient.Status); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if err := recordUndoBarrier(cmd, ctx, "claim release"); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				body = fmt.Sprintf("@%s released to you: %s", recipient.AgentID, claimList)
+				mentions = []string{recipient.AgentID}
+			} else {
+				if err := appendClaimClears(ctx, held); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				description := fmt.Sprintf("restore %d claim(s) for @%s: %s", len(held), holder, claimList)
+				if err := recordUndo(cmd, ctx, "claim release", description, claimInverse(held)); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				body = "released claims: " + claimList
+				if len(requesters) > 0 {
+					body += fmt.Sprintf(" (requested by @%s)", strings.Join(requesters, ", @"))
+					mentions = requesters
+				}
+			}
+			if mentions == nil {
+				mentions = []string{}
+			}
+			msg, err := db.CreateMessage(ctx.DB, types.Message{
+				TS:        now,
+				FromAgent: holder,
+				Body:      body,
+				Mentions:  mentions,
+			})
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.AppendMessage(ctx.Project.DBPath, msg); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				payload := map[string]any{
+					"agent_id": holder,
+					"released": claimsToPayload(held),
+				}
+				if recipient != nil {
+					payload["to"] = recipient.AgentID
+				}
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
+			}
+			out := cmd.OutOrStdout()
+			if recipient != nil {
+				fmt.Fprintf(out, "@%s released %s to @%s\n", holder, claimList, recipient.AgentID)
+				return nil
+			}
+			fmt.Fprintf(out, "@%s released %s\n", holder, claimList)
+			return nil
+		},
+	}
+
+	addClaimTargetFlags(cmd)
+	cmd.Flags().String("as", "", "agent releasing the claims")
+	cmd.Flags().String("to", "", "hand the claims straight to this agent")
+	return cmd
+}
+
+// addClaimTargetFlags registers the flags collectClaims reads.
+func addClaimTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().String("file", "", "a claimed file")
+	cmd.Flags().String("files", "", "claimed files (comma-separated globs)")
+	cmd.Flags().String("bd", "", "a claimed beads issue")
+	cmd.Flags().String("issue", "", "a claimed GitHub issue")
+	cmd.Flags().String("branch", "", "a claimed git branch")
+}
+
+// heldClaims resolves the claim flags to the active claims, each of which
+// must be held by holder.
+func heldClaims(cmd *cobra.Command, ctx *CommandContext, holder string) ([]types.Claim, error) {
+	if _, err := db.PruneExpiredClaims(ctx.DB); err != nil {
+		return nil, err
+	}
+	inputs, err := collectClaims(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, validationError("no claims specified. Use --file, --files, --bd, --issue, or --branch")
+	}
+	held := make([]types.Claim, 0, len(inputs))
+	for _, input := range inputs {
+		label := buildClaimList([]types.Claim{{ClaimType: input.ClaimType, Pattern: input.Pattern}})
+		claim, err := db.GetClaim(ctx.DB, input.ClaimType, input.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		if claim == nil {
+			return nil, notFoundError("no active claim on %s", label)
+		}
+		if claim.AgentID != holder {
+			return nil, conflictError("%s is claimed by @%s, not @%s", label, claim.AgentID, holder)
+		}
+		held = append(held, *claim)
+	}
+	return held, nil
+}
+
+func claimKey(claim types.Claim) string {
+	return string(claim.ClaimType) + ":" + claim.Pattern
+}
+
+// pendingRequesters returns who is waiting on claims, in request order, and
+// the earliest pending request per claim.
+func pendingRequesters(ctx *CommandContext, claims []types.Claim) ([]string, map[string]types.ClaimRequest, error) {
+	requests, err := pendingClaimRequests(ctx, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	var requesters []string
+	seen := map[string]bool{}
+	byClaim := map[string]types.ClaimRequest{}
+	for _, request := range requests {
+		key := string(request.ClaimType) + ":" + request.Pattern
+		if _, ok := byClaim[key]; !ok {
+			byClaim[key] = request
+		}
+		if !seen[request.RequestedBy] {
+			seen[request.RequestedBy] = true
+			requesters = append(requesters, request.RequestedBy)
+		}
+	}
+	return requesters, byClaim, nil
+}
+
+// pendingClaimRequests returns unresolved requests against claims, made
+// while the claim's current holder held it.
+func pendingClaimRequests(ctx *CommandContext, claims []types.Claim) ([]types.ClaimRequest, error) {
+	requests, err := db.ReadClaimRequests(ctx.Project.DBPath)
+	if err != nil {
+		return nil, err
+	}
+	holders := make(map[string]string, len(claims))
+	for _, claim := range claims {
+		holders[claimKey(claim)] = claim.AgentID
+	}
+	var pending []types.ClaimRequest
+	for _, request := range requests {
+		if request.ResolvedAt != nil {
+			continue
+		}
+		if holder, ok := holders[string(request.ClaimType)+":"+request.Pattern]; ok && holder == request.Holder {
+			pending = append(pending, request)
+		}
+	}
+	return pending, nil
+}
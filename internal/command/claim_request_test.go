@@ -0,0 +1,111 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestClaimRequestAndTransfer(t *testing.T) {
+	projectDir := newFlowProject(t, "dev", "pm")
+	runFray(t, "claim", "@dev", "--file", "src/auth.ts", "--note", "token refresh")
+
+	output := runFray(t, "claim", "request", "@dev", "--file", "src/auth.ts", "--as", "pm", "--note", "need 10 min", "--json")
+	var requested struct {
+		Requests  []types.ClaimRequest `json:"requests"`
+		MessageID string               `json:"message_id"`
+	}
+	if err := json.Unmarshal([]byte(output), &requested); err != nil {
+		t.Fatalf("decode %q: %v", output, err)
+	}
+	if len(requested.Requests) != 1 || requested.Requests[0].Holder != "dev" || requested.Requests[0].RequestedBy != "pm" {
+		t.Fatalf("unexpected requests %+v", requested.Requests)
+	}
+
+	dbConn := openProjectDB(t, projectDir)
+	msg, err := db.GetMessage(dbConn, requested.MessageID)
+	dbConn.Close()
+	if err != nil || msg == nil {
+		t.Fatalf("get request message: %v", err)
+	}
+	want := "@dev claim request: src/auth.ts - need 10 min\nTo hand it over: fray claim release --file src/auth.ts --as dev --to pm"
+	if msg.FromAgent != "pm" || msg.Body != want || len(msg.Mentions) != 1 || msg.Mentions[0] != "dev" {
+		t.Fatalf("unexpected request message %+v", msg)
+	}
+
+	if claims := runFray(t, "claims", "dev"); !strings.Contains(claims, "REQUESTS (1):") || !strings.Contains(claims, "@pm wants src/auth.ts from @dev") || !strings.Contains(claims, "need 10 min") {
+		t.Fatalf("expected the pending request in claims output, got %q", claims)
+	}
+
+	if output, err := executeCommand(NewRootCmd("test"), "claim", "release", "--file", "src/auth.ts", "--as", "pm"); err == nil {
+		t.Fatalf("expected a non-holder release to fail, got %q", output)
+	}
+
+	if output := runFray(t, "claim", "release", "--file", "src/auth.ts", "--as", "dev", "--to", "@pm"); !strings.Contains(output, "@dev released src/auth.ts to @pm") {
+		t.Fatalf("unexpected release output %q", output)
+	}
+
+	dbConn = openProjectDB(t, projectDir)
+	claim, err := db.GetClaim(dbConn, types.ClaimTypeFile, "src/auth.ts")
+	dbConn.Close()
+	if err != nil || claim == nil || claim.AgentID != "pm" {
+		t.Fatalf("expected pm to hold the claim, got %+v (%v)", claim, err)
+	}
+	if claim.Reason == nil || *claim.Reason != "need 10 min" || claim.MessageGUID == nil || *claim.MessageGUID != requested.MessageID {
+		t.Fatalf("expected the transferred claim to carry the request context, got %+v", claim)
+	}
+	if claims := runFray(t, "claims"); strings.Contains(claims, "REQUESTS") {
+		t.Fatalf("expected no pending requests after the transfer, got %q", claims)
+	}
+
+	requests, err := db.ReadClaimRequests(projectDir)
+	if err != nil || len(requests) != 1 || requests[0].ResolvedAt == nil || requests[0].GrantedTo == nil || *requests[0].GrantedTo != "pm" {
+		t.Fatalf("expected the request granted to pm, got %+v (%v)", requests, err)
+	}
+	history, err := db.ReadClaimHistory(projectDir)
+	if err != nil || len(history) != 2 {
+		t.Fatalf("expected two claim lifetimes, got %+v (%v)", history, err)
+	}
+	if history[0].AgentID != "dev" || history[0].TransferredTo == nil || *history[0].TransferredTo != "pm" || history[1].AgentID != "pm" || history[1].ClearedAt != nil {
+		t.Fatalf("expected dev's claim handed to pm in the history, got %+v", history)
+	}
+}
+
+func TestClaimReleaseMentionsRequesters(t *testing.T) {
+	projectDir := newFlowProject(t, "dev", "pm", "qa")
+	runFray(t, "claim", "@dev", "--file", "go.mod")
+	runFray(t, "claim", "request", "dev", "--file", "go.mod", "--as", "pm")
+	runFray(t, "claim", "request", "dev", "--file", "go.mod", "--as", "qa")
+
+	output := runFray(t, "claim", "release", "--file", "go.mod", "--as", "dev")
+	if !strings.Contains(output, "@dev released go.mod") {
+		t.Fatalf("unexpected release output %q", output)
+	}
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	if claim, err := db.GetClaim(dbConn, types.ClaimTypeFile, "go.mod"); err != nil || claim != nil {
+		t.Fatalf("expected the claim released, got %+v (%v)", claim, err)
+	}
+	messages, err := db.GetMessages(dbConn, &types.MessageQueryOptions{})
+	if err != nil {
+		t.Fatalf("get messages: %v", err)
+	}
+	var release *types.Message
+	for i := range messages {
+		if strings.HasPrefix(messages[i].Body, "released claims:") {
+			release = &messages[i]
+		}
+	}
+	if release == nil || release.Body != "released claims: go.mod (requested by @pm, @qa)" || strings.Join(release.Mentions, ",") != "pm,qa" {
+		t.Fatalf("unexpected release message %+v", release)
+	}
+
+	requests, err := db.ReadClaimRequests(projectDir)
+	if err != nil || len(requests) != 2 || requests[0].ResolvedAt == nil || requests[1].ResolvedAt == nil || requests[0].GrantedTo != nil {
+		t.Fatalf("expected both requests resolved without a grant, got %+v (%v)", requests, err)
+	}
+}
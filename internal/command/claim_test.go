@@ -0,0 +1,87 @@
+package command
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestClaimBranch(t *testing.T) {
+	projectDir := newFlowProject(t, "dev", "pm")
+	runFray(t, "claim", "@dev", "--branch", "feature/auth", "--reason", "auth rewrite")
+
+	dbConn := openProjectDB(t, projectDir)
+	claim, err := db.GetClaim(dbConn, types.ClaimTypeBranch, "feature/auth")
+	dbConn.Close()
+	if err != nil {
+		t.Fatalf("get claim: %v", err)
+	}
+	if claim == nil || claim.AgentID != "dev" {
+		t.Fatalf("expected branch claim held by dev, got %+v", claim)
+	}
+
+	output, err := executeCommand(NewRootCmd("test"), "claim", "@pm", "--branch", "feature/auth")
+	if err == nil {
+		t.Fatalf("expected second claim to fail, got %q", output)
+	}
+	if !strings.Contains(output, "already claimed by @dev") {
+		t.Fatalf("expected conflict warning, got %q", output)
+	}
+
+	claims := runFray(t, "claims")
+	if !strings.Contains(claims, "BRANCHES (1):") || !strings.Contains(claims, "feature/auth @dev") {
+		t.Fatalf("expected branch section in claims output, got %q", claims)
+	}
+}
+
+func TestClaimNoteAndMessageLink(t *testing.T) {
+	projectDir := newFlowProject(t, "dev")
+	msg := postJSON(t, "post", "--as", "dev", "token refresh races on expiry, rewriting it")
+	msgID := msg["id"].(string)
+	runFray(t, "claim", "@dev", "--file", "src/auth.ts", "--note", "refactoring token refresh", "--msg", msgID)
+
+	dbConn := openProjectDB(t, projectDir)
+	claim, err := db.GetClaim(dbConn, types.ClaimTypeFile, "src/auth.ts")
+	dbConn.Close()
+	if err != nil || claim == nil {
+		t.Fatalf("get claim: %+v (%v)", claim, err)
+	}
+	if claim.Reason == nil || *claim.Reason != "refactoring token refresh" || claim.MessageGUID == nil || *claim.MessageGUID != msgID {
+		t.Fatalf("expected note and message link on claim, got %+v", claim)
+	}
+
+	history, err := db.ReadClaimHistory(filepath.Join(projectDir, ".fray"))
+	if err != nil || len(history) != 1 {
+		t.Fatalf("read claim history: %+v (%v)", history, err)
+	}
+	if history[0].MessageGUID == nil || *history[0].MessageGUID != msgID {
+		t.Fatalf("expected message link in JSONL, got %+v", history[0])
+	}
+
+	claims := runFray(t, "claims")
+	if !strings.Contains(claims, "src/auth.ts") || !strings.Contains(claims, "- refactoring token refresh ("+msgID+")") {
+		t.Fatalf("expected note and link in claims output, got %q", claims)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "claim", "@dev", "--file", "b.go", "--msg", "msg-nope"); err == nil {
+		t.Fatal("expected unknown --msg to fail")
+	}
+}
+
+func TestStatusClaimInheritsNote(t *testing.T) {
+	projectDir := newFlowProject(t, "dev")
+	runFray(t, "status", "@dev", "fixing flaky login test", "--file", "tests/login_test.go")
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	claim, err := db.GetClaim(dbConn, types.ClaimTypeFile, "tests/login_test.go")
+	if err != nil || claim == nil {
+		t.Fatalf("get claim: %+v (%v)", claim, err)
+	}
+	if claim.Reason == nil || *claim.Reason != "fixing flaky login test" {
+		t.Fatalf("expected status text as claim note, got %+v", claim.Reason)
+	}
+}
@@ -74,7 +74,12 @@ func NewClaimsCmd() *cobra.Command {
 			fmt.Fprintf(out, "CLAIMS (%d):\n", len(claims))
 
 			byAgent := map[string][]types.Claim{}
+			var branches []types.Claim
 			for _, claim := range claims {
+				if claim.ClaimType == types.ClaimTypeBranch {
+					branches = append(branches, claim)
+					continue
+				}
 				byAgent[claim.AgentID] = append(byAgent[claim.AgentID], claim)
 			}
 
@@ -95,11 +100,30 @@ func NewClaimsCmd() *cobra.Command {
 							expiry = " (expired)"
 						}
 					}
-					reason := ""
-					if claim.Reason != nil && *claim.Reason != "" {
-						reason = " - " + *claim.Reason
+					fmt.Fprintf(out, "    %s%s (%s)%s%s\n", typePrefix, claim.Pattern, age, expiry, claimContext(claim))
+				}
+			}
+
+			if len(branches) > 0 {
+				fmt.Fprintf(out, "\nBRANCHES (%d):\n", len(branches))
+				for _, claim := range branches {
+					fmt.Fprintf(out, "  %s @%s (%s)%s\n", claim.Pattern, claim.AgentID, formatRelative(claim.CreatedAt), claimContext(claim))
+				}
+			}
+
+			requests, err := pendingClaimRequests(ctx, claims)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if len(requests) > 0 {
+				fmt.Fprintf(out, "\nREQUESTS (%d):\n", len(requests))
+				for _, request := range requests {
+					label := buildClaimList([]types.Claim{{ClaimType: request.ClaimType, Pattern: request.Pattern}})
+					note := ""
+					if request.Note != nil {
+						note = " - " + *request.Note
 					}
-					fmt.Fprintf(out, "    %s%s (%s)%s%s\n", typePrefix, claim.Pattern, age, expiry, reason)
+					fmt.Fprintf(out, "  @%s wants %s from @%s (%s)%s (%s)\n", request.RequestedBy, label, request.Holder, formatRelative(request.RequestedAt), note, request.MessageGUID)
 				}
 			}
 
@@ -107,6 +131,6 @@ func NewClaimsCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().String("type", "", "filter by claim type (file, bd, issue)")
+	cmd.Flags().String("type", "", "filter by claim type (file, bd, issue, branch)")
 	return cmd
 }
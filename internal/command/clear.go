@@ -38,11 +38,16 @@ func NewClearCmd() *cobra.Command {
 			file, _ := cmd.Flags().GetString("file")
 			bd, _ := cmd.Flags().GetString("bd")
 			issue, _ := cmd.Flags().GetString("issue")
+			branch, _ := cmd.Flags().GetString("branch")
 
 			cleared := int64(0)
 			clearedItems := []string{}
+			clearedClaims := []types.Claim{}
 
 			if file != "" {
+				if claim, err := db.GetClaim(ctx.DB, types.ClaimTypeFile, file); err == nil && claim != nil {
+					clearedClaims = append(clearedClaims, *claim)
+				}
 				deleted, err := db.DeleteClaim(ctx.DB, types.ClaimTypeFile, file)
 				if err != nil {
 					return writeCommandError(cmd, err)
@@ -54,6 +59,9 @@ func NewClearCmd() *cobra.Command {
 			}
 			if bd != "" {
 				pattern := stripHash(bd)
+				if claim, err := db.GetClaim(ctx.DB, types.ClaimTypeBD, pattern); err == nil && claim != nil {
+					clearedClaims = append(clearedClaims, *claim)
+				}
 				deleted, err := db.DeleteClaim(ctx.DB, types.ClaimTypeBD, pattern)
 				if err != nil {
 					return writeCommandError(cmd, err)
@@ -65,6 +73,9 @@ func NewClearCmd() *cobra.Command {
 			}
 			if issue != "" {
 				pattern := stripHash(issue)
+				if claim, err := db.GetClaim(ctx.DB, types.ClaimTypeIssue, pattern); err == nil && claim != nil {
+					clearedClaims = append(clearedClaims, *claim)
+				}
 				deleted, err := db.DeleteClaim(ctx.DB, types.ClaimTypeIssue, pattern)
 				if err != nil {
 					return writeCommandError(cmd, err)
@@ -75,11 +86,26 @@ func NewClearCmd() *cobra.Command {
 				}
 			}
 
-			if file == "" && bd == "" && issue == "" {
+			if branch != "" {
+				if claim, err := db.GetClaim(ctx.DB, types.ClaimTypeBranch, branch); err == nil && claim != nil {
+					clearedClaims = append(clearedClaims, *claim)
+				}
+				deleted, err := db.DeleteClaim(ctx.DB, types.ClaimTypeBranch, branch)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if deleted {
+					cleared++
+					clearedItems = append(clearedItems, fmt.Sprintf("branch:%s", branch))
+				}
+			}
+
+			if file == "" && bd == "" && issue == "" && branch == "" {
 				existing, err := db.GetClaimsByAgent(ctx.DB, agentID)
 				if err != nil {
 					return writeCommandError(cmd, err)
 				}
+				clearedClaims = append(clearedClaims, existing...)
 				for _, claim := range existing {
 					if claim.ClaimType == types.ClaimTypeFile {
 						clearedItems = append(clearedItems, claim.Pattern)
@@ -94,6 +120,9 @@ func NewClearCmd() *cobra.Command {
 			}
 
 			if cleared > 0 {
+				if err := appendClaimClears(ctx, clearedClaims); err != nil {
+					return writeCommandError(cmd, err)
+				}
 				body := fmt.Sprintf("cleared claims: %s", joinList(clearedItems))
 				msg, err := db.CreateMessage(ctx.DB, types.Message{
 					TS:        time.Now().Unix(),
@@ -107,6 +136,10 @@ func NewClearCmd() *cobra.Command {
 				if err := db.AppendMessage(ctx.Project.DBPath, msg); err != nil {
 					return writeCommandError(cmd, err)
 				}
+				description := fmt.Sprintf("restore %d claim(s) for @%s: %s", len(clearedClaims), agentID, joinList(clearedItems))
+				if err := recordUndo(cmd, ctx, "clear", description, claimInverse(clearedClaims)); err != nil {
+					return writeCommandError(cmd, err)
+				}
 			}
 
 			if ctx.JSONMode {
@@ -138,5 +171,6 @@ func NewClearCmd() *cobra.Command {
 	cmd.Flags().String("file", "", "clear a specific file claim")
 	cmd.Flags().String("bd", "", "clear a specific beads issue claim")
 	cmd.Flags().String("issue", "", "clear a specific GitHub issue claim")
+	cmd.Flags().String("branch", "", "clear a specific branch claim")
 	return cmd
 }
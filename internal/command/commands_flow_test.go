@@ -2,30 +2,19 @@ package command
 
 import (
 	"database/sql"
-	"os"
 	"strings"
 	"testing"
 
 	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
 	"github.com/adamavenir/fray/internal/types"
 )
 
 func TestInitNewPostFlow(t *testing.T) {
-	tmpHome := t.TempDir()
-	t.Setenv("HOME", tmpHome)
-
+	testutil.IsolateHome(t)
 	projectDir := t.TempDir()
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("getwd: %v", err)
-	}
-	if err := os.Chdir(projectDir); err != nil {
-		t.Fatalf("chdir: %v", err)
-	}
-	t.Cleanup(func() {
-		_ = os.Chdir(cwd)
-	})
+	testutil.Chdir(t, projectDir)
 
 	cmd := NewRootCmd("test")
 	if _, err := executeCommand(cmd, "init", "--defaults"); err != nil {
@@ -42,21 +31,9 @@ func TestInitNewPostFlow(t *testing.T) {
 		t.Fatalf("post command: %v", err)
 	}
 
-	project, err := core.DiscoverProject(projectDir)
-	if err != nil {
-		t.Fatalf("discover project: %v", err)
-	}
-
-	dbConn, err := db.OpenDatabase(project)
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
+	dbConn := openProjectDB(t, projectDir)
 	defer dbConn.Close()
 
-	if err := db.InitSchema(dbConn); err != nil {
-		t.Fatalf("init schema: %v", err)
-	}
-
 	agent, err := db.GetAgent(dbConn, "alice")
 	if err != nil {
 		t.Fatalf("get agent: %v", err)
@@ -75,20 +52,9 @@ func TestInitNewPostFlow(t *testing.T) {
 }
 
 func TestEditRequiresReasonAndCreatesEvent(t *testing.T) {
-	tmpHome := t.TempDir()
-	t.Setenv("HOME", tmpHome)
-
+	testutil.IsolateHome(t)
 	projectDir := t.TempDir()
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("getwd: %v", err)
-	}
-	if err := os.Chdir(projectDir); err != nil {
-		t.Fatalf("chdir: %v", err)
-	}
-	t.Cleanup(func() {
-		_ = os.Chdir(cwd)
-	})
+	testutil.Chdir(t, projectDir)
 
 	cmd := NewRootCmd("test")
 	if _, err := executeCommand(cmd, "init", "--defaults"); err != nil {
@@ -161,20 +127,9 @@ func TestEditRequiresReasonAndCreatesEvent(t *testing.T) {
 }
 
 func TestQuestionLifecycleFlow(t *testing.T) {
-	tmpHome := t.TempDir()
-	t.Setenv("HOME", tmpHome)
-
+	testutil.IsolateHome(t)
 	projectDir := t.TempDir()
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("getwd: %v", err)
-	}
-	if err := os.Chdir(projectDir); err != nil {
-		t.Fatalf("chdir: %v", err)
-	}
-	t.Cleanup(func() {
-		_ = os.Chdir(cwd)
-	})
+	testutil.Chdir(t, projectDir)
 
 	cmd := NewRootCmd("test")
 	if _, err := executeCommand(cmd, "init", "--defaults"); err != nil {
@@ -229,20 +184,9 @@ func TestQuestionLifecycleFlow(t *testing.T) {
 }
 
 func TestThreadCommandFlow(t *testing.T) {
-	tmpHome := t.TempDir()
-	t.Setenv("HOME", tmpHome)
-
+	testutil.IsolateHome(t)
 	projectDir := t.TempDir()
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("getwd: %v", err)
-	}
-	if err := os.Chdir(projectDir); err != nil {
-		t.Fatalf("chdir: %v", err)
-	}
-	t.Cleanup(func() {
-		_ = os.Chdir(cwd)
-	})
+	testutil.Chdir(t, projectDir)
 
 	cmd := NewRootCmd("test")
 	if _, err := executeCommand(cmd, "init", "--defaults"); err != nil {
@@ -314,20 +258,9 @@ func TestThreadCommandFlow(t *testing.T) {
 }
 
 func TestCrossThreadReplyAutoAdd(t *testing.T) {
-	tmpHome := t.TempDir()
-	t.Setenv("HOME", tmpHome)
-
+	testutil.IsolateHome(t)
 	projectDir := t.TempDir()
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("getwd: %v", err)
-	}
-	if err := os.Chdir(projectDir); err != nil {
-		t.Fatalf("chdir: %v", err)
-	}
-	t.Cleanup(func() {
-		_ = os.Chdir(cwd)
-	})
+	testutil.Chdir(t, projectDir)
 
 	cmd := NewRootCmd("test")
 	if _, err := executeCommand(cmd, "init", "--defaults"); err != nil {
@@ -354,7 +287,7 @@ func TestCrossThreadReplyAutoAdd(t *testing.T) {
 	_ = dbConn.Close()
 
 	cmd = NewRootCmd("test")
-	if _, err := executeCommand(cmd, "post", "--as", "alice", "--thread", "analysis", "--reply-to", roomMsgID, "This is a longer reply in the thread"); err != nil {
+	if _, err := executeCommand(cmd, "post", "--as", "alice", "--thread", "analysis", "--cross-home", "--reply-to", roomMsgID, "This is a longer reply in the thread"); err != nil {
 		t.Fatalf("thread reply: %v", err)
 	}
 
@@ -0,0 +1,122 @@
+package command
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// maxCommitPatchBytes caps the patch fray post --diff stores on a message.
+const maxCommitPatchBytes = 64 * 1024
+
+// resolveCommits resolves refs to commit metadata in the git repository at
+// root, capturing each patch when withDiff is set.
+func resolveCommits(root string, refs []string, withDiff bool) ([]types.CommitRef, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, unavailableError(fmt.Errorf("--commit needs git, which was not found"))
+	}
+	if _, err := runGitCommand(root, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, validationError("--commit needs a git repository; %s is not one", root)
+	}
+
+	commits := make([]types.CommitRef, 0, len(refs))
+	for _, ref := range refs {
+		commit, err := resolveCommit(root, ref, withDiff)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+func resolveCommit(root, ref string, withDiff bool) (types.CommitRef, error) {
+	sha, err := runGitCommand(root, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil || strings.TrimSpace(sha) == "" {
+		return types.CommitRef{}, validationError("unknown commit: %s", ref)
+	}
+	sha = strings.TrimSpace(sha)
+
+	header, err := runGitCommand(root, "show", "-s", "--format=%an%x00%s", sha)
+	if err != nil {
+		return types.CommitRef{}, err
+	}
+	author, subject, _ := strings.Cut(strings.TrimRight(header, "\n"), "\x00")
+	commit := types.CommitRef{SHA: sha, Author: author, Subject: subject}
+
+	numstat, err := runGitCommand(root, "show", "--numstat", "--format=", sha)
+	if err != nil {
+		return types.CommitRef{}, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(numstat), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		// Binary files report "-" for both counts.
+		insertions, _ := strconv.Atoi(fields[0])
+		deletions, _ := strconv.Atoi(fields[1])
+		commit.Insertions += insertions
+		commit.Deletions += deletions
+		commit.Files = append(commit.Files, fields[2])
+	}
+
+	if withDiff {
+		patch, err := runGitCommand(root, "show", "--format=", "--patch", sha)
+		if err != nil {
+			return types.CommitRef{}, err
+		}
+		if len(patch) > maxCommitPatchBytes {
+			patch = truncateUTF8(patch, maxCommitPatchBytes)
+			commit.PatchTruncated = true
+		}
+		commit.Patch = patch
+	}
+	return commit, nil
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune.
+func truncateUTF8(s string, n int) string {
+	for n > 0 && n < len(s) && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
+
+// formatCommitSummary renders the compact one-line block shown under a
+// message with an attached commit.
+func formatCommitSummary(commit types.CommitRef) string {
+	return fmt.Sprintf("  %s⎇ %s %s (%s · %d file(s) +%d -%d)%s",
+		gray, shortSHA(commit.SHA), commit.Subject, commit.Author, len(commit.Files), commit.Insertions, commit.Deletions, reset)
+}
+
+// formatCommitDetails renders the expanded view of fray get --with-commits:
+// the full SHA, changed files, and the patch when one was captured.
+func formatCommitDetails(commit types.CommitRef) string {
+	lines := []string{fmt.Sprintf("    %scommit %s%s", dim, commit.SHA, reset)}
+	for _, file := range commit.Files {
+		lines = append(lines, "    "+gray+file+reset)
+	}
+	if commit.Patch != "" {
+		for _, line := range strings.Split(strings.TrimRight(commit.Patch, "\n"), "\n") {
+			lines = append(lines, "    "+line)
+		}
+		if commit.PatchTruncated {
+			lines = append(lines, fmt.Sprintf("    %s... patch truncated at %d KB%s", dim, maxCommitPatchBytes/1024, reset))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+)
+
+// commitFile commits content to name in the repo at dir and returns the
+// new commit's SHA.
+func commitFile(t *testing.T, dir, name, content, subject string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	for _, args := range [][]string{
+		{"add", name},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", subject},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	sha, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git rev-parse: %v", err)
+	}
+	return strings.TrimSpace(string(sha))
+}
+
+func TestPostCommitCapturesMetadata(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	projectDir := newFlowProject(t, "dev")
+	gitInit(t, projectDir)
+	sha := commitFile(t, projectDir, "main.go", "package main\n\nfunc main() {}\n", "Add entry point")
+	commitFile(t, projectDir, "README.md", "# demo\n", "Add readme")
+
+	runFray(t, "post", "--as", "dev", "--commit", "HEAD~1", "please review")
+	output := runFray(t, "get", "--last", "5")
+	if !strings.Contains(output, "⎇ "+sha[:7]+" Add entry point (test · 1 file(s) +3 -0)") {
+		t.Fatalf("expected commit summary under the message, got:\n%s", output)
+	}
+
+	database := openProjectDB(t, projectDir)
+	defer database.Close()
+	msg, err := db.GetMessage(database, findRoomMessageByBody(t, database, "please review"))
+	if err != nil || msg == nil {
+		t.Fatalf("get message: %v", err)
+	}
+	if len(msg.Commits) != 1 {
+		t.Fatalf("expected one commit on the message, got %+v", msg.Commits)
+	}
+	commit := msg.Commits[0]
+	if commit.SHA != sha || commit.Author != "test" || commit.Subject != "Add entry point" {
+		t.Fatalf("unexpected commit metadata: %+v", commit)
+	}
+	if len(commit.Files) != 1 || commit.Files[0] != "main.go" || commit.Insertions != 3 || commit.Deletions != 0 {
+		t.Fatalf("unexpected commit stats: %+v", commit)
+	}
+	if commit.Patch != "" {
+		t.Fatalf("expected no patch without --diff, got %q", commit.Patch)
+	}
+
+	// The metadata survives a rebuild from JSONL.
+	project, err := core.DiscoverProject(projectDir)
+	if err != nil {
+		t.Fatalf("discover project: %v", err)
+	}
+	if err := db.RebuildDatabaseFromJSONL(database, project.DBPath); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	rebuilt, err := db.GetMessage(database, msg.ID)
+	if err != nil || rebuilt == nil || len(rebuilt.Commits) != 1 || rebuilt.Commits[0].SHA != sha {
+		t.Fatalf("expected commit metadata after rebuild, got %+v (%v)", rebuilt, err)
+	}
+}
+
+func TestPostDiffAndGetWithCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	projectDir := newFlowProject(t, "dev")
+	gitInit(t, projectDir)
+	commitFile(t, projectDir, "notes.txt", "first line\n", "Add notes")
+
+	runFray(t, "post", "--as", "dev", "--commit", "HEAD", "--diff", "patch attached")
+
+	output := runFray(t, "get", "--last", "5")
+	if strings.Contains(output, "+first line") {
+		t.Fatalf("expected the patch to stay collapsed without --with-commits, got:\n%s", output)
+	}
+	output = runFray(t, "get", "--last", "5", "--with-commits")
+	for _, want := range []string{"Add notes", "notes.txt", "+first line"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in expanded output, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestPostCommitErrors(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	projectDir := newFlowProject(t, "dev")
+
+	output, err := executeCommand(NewRootCmd("test"), "post", "--as", "dev", "--commit", "HEAD", "outside git")
+	if err == nil || !strings.Contains(output, "git repository") {
+		t.Fatalf("expected a not-a-repo error, got %v %q", err, output)
+	}
+
+	output, err = executeCommand(NewRootCmd("test"), "post", "--as", "dev", "--diff", "no commit")
+	if err == nil || !strings.Contains(output, "--diff requires --commit") {
+		t.Fatalf("expected --diff without --commit to fail, got %v %q", err, output)
+	}
+
+	gitInit(t, projectDir)
+	output, err = executeCommand(NewRootCmd("test"), "post", "--as", "dev", "--commit", "no-such-ref", "bad ref")
+	if err == nil || !strings.Contains(output, "unknown commit: no-such-ref") {
+		t.Fatalf("expected an unknown-commit error, got %v %q", err, output)
+	}
+}
@@ -0,0 +1,87 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// compactColumns is the column order of fray get --compact. Prompt
+// templates parse this format, so only ever append columns.
+var compactColumns = []string{"id", "ts", "from", "home", "body"}
+
+// defaultCompactMaxBody is the --max-body default, in characters.
+const defaultCompactMaxBody = 280
+
+// compactListing holds the terse output modes of fray get.
+type compactListing struct {
+	idsOnly    bool
+	maxBody    int
+	expandCode bool
+}
+
+// compactListingFlags reads --compact, --ids-only, --max-body, and
+// --expand-code. It returns nil when neither terse format was requested.
+func compactListingFlags(cmd *cobra.Command, jsonMode bool) (*compactListing, error) {
+	compact, _ := cmd.Flags().GetBool("compact")
+	idsOnly, _ := cmd.Flags().GetBool("ids-only")
+	if !compact && !idsOnly {
+		return nil, nil
+	}
+	if compact && idsOnly {
+		return nil, validationError("use --compact or --ids-only, not both")
+	}
+	if jsonMode {
+		return nil, validationError("--compact and --ids-only cannot be combined with --json")
+	}
+	maxBody, _ := cmd.Flags().GetInt("max-body")
+	if maxBody < 0 {
+		return nil, validationError("--max-body must be 0 (no limit) or positive")
+	}
+	expandCode, _ := cmd.Flags().GetBool("expand-code")
+	return &compactListing{idsOnly: idsOnly, maxBody: maxBody, expandCode: expandCode}, nil
+}
+
+// write prints one line per message: the GUID alone for --ids-only, or the
+// compactColumns separated by "|" for --compact. Fenced code blocks collapse
+// to "[code: go, 42 lines]" unless --expand-code is set.
+func (l *compactListing) write(out io.Writer, messages []types.Message) {
+	for _, msg := range messages {
+		if l.idsOnly {
+			fmt.Fprintln(out, msg.ID)
+			continue
+		}
+		if !l.expandCode {
+			msg.Body = core.CollapseCodeBlocks(msg.Body)
+		}
+		fmt.Fprintln(out, formatCompactMessage(msg, l.maxBody))
+	}
+}
+
+// formatCompactMessage renders "id|ts|from|home|body". The body is the last
+// column, so pipes inside it need no escaping; backslashes, newlines, and
+// carriage returns are escaped to keep one message per line. Bodies longer
+// than maxBody characters are cut with an ellipsis and their full length.
+func formatCompactMessage(msg types.Message, maxBody int) string {
+	home := msg.Home
+	if home == "" {
+		home = "room"
+	}
+	body := msg.Body
+	if length := utf8.RuneCountInString(body); maxBody > 0 && length > maxBody {
+		body = string([]rune(body)[:maxBody]) + "…[" + strconv.Itoa(length) + " chars]"
+	}
+	return strings.Join([]string{msg.ID, strconv.FormatInt(msg.TS, 10), msg.FromAgent, home, escapeCompactBody(body)}, "|")
+}
+
+var compactBodyEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+func escapeCompactBody(body string) string {
+	return compactBodyEscaper.Replace(body)
+}
@@ -0,0 +1,126 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func compactFixture() []types.Message {
+	return []types.Message{
+		{ID: "msg-aaaa1111", TS: 1767225600, FromAgent: "pm", Home: "room", Body: "Which schema should we ship?"},
+		{ID: "msg-bbbb2222", TS: 1767225660, FromAgent: "dev.2", Home: "thrd-design1", Body: "Two options:\n1. flat | simple\n2. nested (see C:\\schemas)\r\n"},
+		{ID: "msg-cccc3333", TS: 1767225720, FromAgent: "qa", Body: "Härtetest: " + strings.Repeat("ä", 40)},
+		{ID: "msg-dddd4444", TS: 1767225780, FromAgent: "pm", Home: "thrd-design1", Body: ""},
+	}
+}
+
+func TestCompactFormatsGolden(t *testing.T) {
+	cases := []struct {
+		golden  string
+		listing compactListing
+	}{
+		{"get_compact.txt", compactListing{maxBody: 30}},
+		{"get_compact_full.txt", compactListing{}},
+		{"get_ids_only.txt", compactListing{idsOnly: true}},
+	}
+	for _, tc := range cases {
+		var out strings.Builder
+		tc.listing.write(&out, compactFixture())
+
+		golden := filepath.Join("testdata", tc.golden)
+		if *updateGolden {
+			if err := os.WriteFile(golden, []byte(out.String()), 0o644); err != nil {
+				t.Fatalf("update golden: %v", err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("read golden: %v", err)
+		}
+		if out.String() != string(want) {
+			t.Fatalf("output differs from %s (rerun with -update to accept):\n%s", golden, out.String())
+		}
+	}
+}
+
+func TestCompactColumnOrderIsStable(t *testing.T) {
+	if got := strings.Join(compactColumns, "|"); got != "id|ts|from|home|body" {
+		t.Fatalf("compact column order changed to %s; prompt templates depend on it", got)
+	}
+	line := formatCompactMessage(compactFixture()[1], 0)
+	if fields := strings.SplitN(line, "|", len(compactColumns)); len(fields) != len(compactColumns) || fields[3] != "thrd-design1" {
+		t.Fatalf("unexpected compact line %q", line)
+	}
+}
+
+func TestGetCompactAndIDsOnly(t *testing.T) {
+	newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "design")
+	first := postJSON(t, "post", "--as", "alice", "design", "first line\nsecond line")
+	second := postJSON(t, "post", "--as", "bob", "design", strings.Repeat("x", 50))
+
+	output := runFray(t, "get", "design", "--compact", "--max-body", "10")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two compact lines without header, got %q", output)
+	}
+	for _, line := range lines {
+		fields := strings.SplitN(line, "|", len(compactColumns))
+		if len(fields) != len(compactColumns) {
+			t.Fatalf("expected %d columns, got %q", len(compactColumns), line)
+		}
+		switch fields[0] {
+		case first["id"]:
+			if fields[2] != "alice" || fields[4] != "first line…[22 chars]" {
+				t.Fatalf("unexpected first line %q", line)
+			}
+		case second["id"]:
+			if fields[4] != "xxxxxxxxxx…[50 chars]" {
+				t.Fatalf("expected truncated body, got %q", line)
+			}
+		default:
+			t.Fatalf("unexpected message in %q", line)
+		}
+	}
+
+	output = runFray(t, "get", "design", "--ids-only", "--last", "1")
+	if got := strings.TrimSpace(output); got != first["id"] && got != second["id"] {
+		t.Fatalf("expected a single GUID, got %q", output)
+	}
+
+	if output, err := executeCommand(NewRootCmd("test"), "get", "design", "--compact", "--json"); err == nil {
+		t.Fatalf("expected --compact --json to fail, got %q", output)
+	}
+}
+
+func TestGetCodeBlocks(t *testing.T) {
+	newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "design")
+	code := postJSON(t, "post", "--as", "alice", "design", "try this:\n```go\nfmt.Println(1)\nfmt.Println(2)\n```\nthoughts?")
+	prose := postJSON(t, "post", "--as", "bob", "design", "looks fine to me, shipping it")
+
+	output := runFray(t, "get", "design", "--compact")
+	if !strings.Contains(output, code["id"].(string)+"|") || !strings.Contains(output, `try this:\n[code: go, 2 lines]\nthoughts?`) {
+		t.Fatalf("expected collapsed code block, got %q", output)
+	}
+	output = runFray(t, "get", "design", "--compact", "--expand-code")
+	if !strings.Contains(output, "```go\\nfmt.Println(1)") {
+		t.Fatalf("expected --expand-code to keep the fence, got %q", output)
+	}
+
+	output = strings.TrimSpace(runFray(t, "get", "design", "--ids-only", "--code-only"))
+	if output != code["id"] {
+		t.Fatalf("expected only the code message, got %q", output)
+	}
+	output = strings.TrimSpace(runFray(t, "get", "design", "--ids-only", "--no-code"))
+	if output != prose["id"] {
+		t.Fatalf("expected only the prose message, got %q", output)
+	}
+	if output, err := executeCommand(NewRootCmd("test"), "get", "design", "--code-only", "--no-code"); err == nil {
+		t.Fatalf("expected --code-only --no-code to fail, got %q", output)
+	}
+}
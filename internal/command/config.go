@@ -3,9 +3,11 @@ package command
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
 	"github.com/spf13/cobra"
 )
@@ -15,7 +17,18 @@ func NewConfigCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "config [key] [value]",
 		Short: "Get or set configuration",
-		Args:  cobra.MaximumNArgs(2),
+		Long: `Get or set configuration.
+
+Config is layered. Values in a higher layer override lower ones:
+  local    .fray/local/config.json   this machine only (not shared)
+  project  the project database      shared with the team (default)
+  global   ~/.config/fray/config.json  all your projects
+
+Reads return the effective value unless --scope picks a layer. Writes go to
+the project layer unless --scope says otherwise. Personal preferences such as
+identity, stale_hours, and notify_quiet may be set in any layer; team settings
+are project-only. username is deprecated in favour of identity (see fray login).`,
+		Args: cobra.MaximumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, err := GetContext(cmd)
 			if err != nil {
@@ -23,31 +36,29 @@ func NewConfigCmd() *cobra.Command {
 			}
 			defer ctx.DB.Close()
 
+			scope, scoped, err := configScopeFlag(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
 			if len(args) == 0 {
-				entries, err := db.GetAllConfig(ctx.DB)
-				if err != nil {
-					return writeCommandError(cmd, err)
-				}
-				if ctx.JSONMode {
-					return json.NewEncoder(cmd.OutOrStdout()).Encode(entries)
-				}
-				out := cmd.OutOrStdout()
-				if len(entries) == 0 {
-					fmt.Fprintln(out, "No configuration set")
-					return nil
-				}
-				fmt.Fprintln(out, "Configuration:")
-				for _, entry := range entries {
-					fmt.Fprintf(out, "  %s: %s\n", entry.Key, entry.Value)
-				}
-				return nil
+				return listConfig(cmd, ctx, scope, scoped)
 			}
 
 			key := normalizeConfigKey(args[0])
 			if len(args) == 1 {
-				value, err := db.GetConfig(ctx.DB, key)
-				if err != nil {
-					return writeCommandError(cmd, err)
+				var value string
+				if scoped {
+					values, err := db.GetConfigLayer(ctx.DB, ctx.Project.DBPath, scope)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
+					value = values[key]
+				} else {
+					value, err = getConfigValue(ctx, key)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
 				}
 				if value == "" {
 					return writeCommandError(cmd, fmt.Errorf("config key '%s' not found", args[0]))
@@ -60,41 +71,289 @@ func NewConfigCmd() *cobra.Command {
 				return nil
 			}
 
-			if err := validateConfigValue(key, args[1]); err != nil {
+			if !scoped {
+				scope = db.ConfigScopeProject
+			}
+			if err := validateConfigScope(key, scope); err != nil {
 				return writeCommandError(cmd, err)
 			}
-			if err := db.SetConfig(ctx.DB, key, args[1]); err != nil {
+			if err := validateConfigValue(key, args[1]); err != nil {
+				return writeCommandError(cmd, validationError("%s", err))
+			}
+			if err := db.SetConfigLayer(ctx.DB, ctx.Project.DBPath, scope, key, args[1]); err != nil {
 				return writeCommandError(cmd, err)
 			}
+			if key == db.UsernameConfigKey {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Warning: username is deprecated and ignored once identity is set. Use 'fray login <name>'")
+			}
 			if ctx.JSONMode {
 				payload := map[string]string{args[0]: args[1]}
 				return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "Set %s = %s\n", args[0], args[1])
+			if scope == db.ConfigScopeProject {
+				fmt.Fprintf(cmd.OutOrStdout(), "Set %s = %s\n", args[0], args[1])
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Set %s = %s (%s)\n", args[0], args[1], scope)
+			}
 			return nil
 		},
 	}
 
+	cmd.Flags().String("scope", "", "config layer to read or write: global, project, or local")
+
+	cmd.AddCommand(NewConfigListCmd())
+	cmd.AddCommand(NewConfigExportCmd())
+	cmd.AddCommand(NewConfigImportCmd())
+
 	return cmd
 }
 
+// NewConfigListCmd creates the config list command.
+func NewConfigListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List effective config and the layer each value comes from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			scope, scoped, err := configScopeFlag(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			return listConfig(cmd, ctx, scope, scoped)
+		},
+	}
+
+	cmd.Flags().String("scope", "", "list only one layer: global, project, or local")
+	return cmd
+}
+
+func listConfig(cmd *cobra.Command, ctx *CommandContext, scope db.ConfigScope, scoped bool) error {
+	var entries []db.ResolvedConfigEntry
+	if scoped {
+		values, err := db.GetConfigLayer(ctx.DB, ctx.Project.DBPath, scope)
+		if err != nil {
+			return writeCommandError(cmd, err)
+		}
+		for key, value := range values {
+			entries = append(entries, db.ResolvedConfigEntry{Key: key, Value: value, Scope: scope})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	} else {
+		var err error
+		entries, err = db.ResolveConfig(ctx.DB, ctx.Project.DBPath)
+		if err != nil {
+			return writeCommandError(cmd, err)
+		}
+	}
+
+	if ctx.JSONMode {
+		if entries == nil {
+			entries = []db.ResolvedConfigEntry{}
+		}
+		return json.NewEncoder(cmd.OutOrStdout()).Encode(entries)
+	}
+	out := cmd.OutOrStdout()
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No configuration set")
+		return nil
+	}
+	fmt.Fprintln(out, "Configuration:")
+	for _, entry := range entries {
+		fmt.Fprintf(out, "  %s: %s (%s)\n", entry.Key, entry.Value, entry.Scope)
+	}
+	return nil
+}
+
+// configScopeFlag reads --scope. scoped is false when no layer was picked.
+func configScopeFlag(cmd *cobra.Command) (db.ConfigScope, bool, error) {
+	value, _ := cmd.Flags().GetString("scope")
+	if value == "" {
+		return "", false, nil
+	}
+	scope, err := db.ParseConfigScope(value)
+	if err != nil {
+		return "", false, err
+	}
+	return scope, true, nil
+}
+
+// validateConfigScope rejects writing key to a layer it does not belong in.
+// Unknown keys may only be set in the project layer.
+func validateConfigScope(key string, scope db.ConfigScope) error {
+	spec, _ := lookupConfigKey(key)
+	if spec.allowsScope(scope) {
+		return nil
+	}
+	allowed := spec.Scopes
+	if len(allowed) == 0 {
+		allowed = []db.ConfigScope{db.ConfigScopeProject}
+	}
+	names := make([]string, 0, len(allowed))
+	for _, s := range allowed {
+		names = append(names, string(s))
+	}
+	return validationError("%s cannot be set at %s scope (allowed: %s)", key, scope, strings.Join(names, ", "))
+}
+
 func normalizeConfigKey(value string) string {
 	return strings.ReplaceAll(value, "-", "_")
 }
 
 func validateConfigValue(key, value string) error {
+	if strings.HasPrefix(key, modelRatePrefix) {
+		_, err := core.ParseModelRate(value)
+		return err
+	}
+	if strings.HasPrefix(key, retentionPrefix) {
+		_, err := core.ParseRetentionPolicy(value)
+		return err
+	}
 	switch key {
 	case "stale_hours":
 		parsed, err := strconv.Atoi(strings.TrimSpace(value))
 		if err != nil || parsed <= 0 {
 			return fmt.Errorf("stale_hours must be a positive integer")
 		}
-	case "precommit_strict":
-		normalized := strings.ToLower(strings.TrimSpace(value))
-		if normalized == "true" || normalized == "false" || normalized == "1" || normalized == "0" {
-			return nil
+	case "notify_quiet":
+		_, err := parseQuietHours(value)
+		return err
+	case "precommit_strict", "auto_thread_issues", "auto_questions", "sub_mention_fanout",
+		threadCurationOpenKey, strictMentionsKey:
+		if _, err := db.ParseConfigBool(value); err != nil {
+			return fmt.Errorf("%s %v", key, err)
+		}
+	case "digest_every_n_messages":
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || parsed < 0 {
+			return fmt.Errorf("digest_every_n_messages must be a non-negative integer")
+		}
+	case db.PostBlockPatternsConfigKey:
+		_, err := db.ParseBlockPatterns(value)
+		return err
+	case db.AnnounceReminderConfigKey:
+		_, err := db.ParseAnnounceReminder(value)
+		return err
+	case db.WorkingTTLConfigKey:
+		_, err := db.ParseWorkingTTL(value)
+		return err
+	case db.MaxMoveRepliesConfigKey:
+		_, err := db.ParseMaxMoveReplies(value)
+		return err
+	case db.GUIDEntropyConfigKey:
+		_, err := db.ParseGUIDEntropy(value)
+		return err
+	case "question_resolve_reactions", "question_decline_reactions":
+		if strings.Trim(value, ", ") == "" {
+			return fmt.Errorf("%s must list at least one reaction", key)
+		}
+		return nil
+	case db.PrivateRecordsConfigKey:
+		_, err := db.ParsePrivateRecords(value)
+		return err
+	case "standup_marker":
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("standup_marker must not be empty")
+		}
+	case "warm_agents":
+		for _, part := range strings.Split(value, ",") {
+			id := strings.TrimPrefix(strings.TrimSpace(part), "@")
+			if id != "" && !core.IsValidAgentID(id) {
+				return fmt.Errorf("warm_agents: invalid agent id %q", id)
+			}
 		}
-		return fmt.Errorf("precommit_strict must be true or false")
 	}
 	return nil
 }
+
+// configKeySpec describes a known config key.
+type configKeySpec struct {
+	// Portable keys are settings that make sense in another project.
+	// Channel identity and daemon watermarks are not.
+	Portable bool
+	// Secret keys are masked on export.
+	Secret bool
+	// Scopes lists the layers the key may be set in. Nil means project only.
+	Scopes []db.ConfigScope
+}
+
+// anyScope marks personal preferences that may live in any config layer.
+var anyScope = db.ConfigScopes
+
+// allowsScope reports whether the key may be set in scope.
+func (spec configKeySpec) allowsScope(scope db.ConfigScope) bool {
+	if len(spec.Scopes) == 0 {
+		return scope == db.ConfigScopeProject
+	}
+	for _, allowed := range spec.Scopes {
+		if allowed == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// getConfigValue returns the effective value of key across the global,
+// project, and local config layers.
+func getConfigValue(ctx *CommandContext, key string) (string, error) {
+	value, _, err := db.GetResolvedConfig(ctx.DB, ctx.Project.DBPath, key)
+	return value, err
+}
+
+// configRegistry lists the config keys fray knows about.
+var configRegistry = map[string]configKeySpec{
+	"stale_hours":                 {Portable: true, Scopes: anyScope},
+	"precommit_strict":            {Portable: true, Scopes: []db.ConfigScope{db.ConfigScopeProject, db.ConfigScopeLocal}},
+	"notify_quiet":                {Portable: true, Scopes: anyScope},
+	"auto_thread_issues":          {Portable: true},
+	"auto_questions":              {Portable: true},
+	"warm_agents":                 {Portable: true},
+	"digest_every_n_messages":     {Portable: true},
+	"digest_threads":              {},
+	"post_block_patterns":         {Portable: true},
+	"guid_entropy_bytes":          {Portable: true},
+	"working_ttl_seconds":         {Portable: true},
+	"announce_reminder_minutes":   {Portable: true},
+	"standup_marker":              {Portable: true},
+	"thread_curation_open":        {Portable: true},
+	"sub_mention_fanout":          {Portable: true},
+	"strict_mentions":             {Portable: true, Scopes: []db.ConfigScope{db.ConfigScopeProject, db.ConfigScopeLocal}},
+	"question_resolve_reactions":  {Portable: true},
+	"question_decline_reactions":  {Portable: true},
+	"identity":                    {Portable: true, Scopes: anyScope},
+	"username":                    {Portable: true, Scopes: anyScope},
+	"private_records":             {Portable: true, Scopes: []db.ConfigScope{db.ConfigScopeGlobal, db.ConfigScopeLocal}},
+	"render_markdown":             {Portable: true, Scopes: anyScope},
+	"mv_max_replies":              {Portable: true},
+	"channel_id":                  {},
+	"channel_name":                {},
+	"auto_thread_watermark":       {},
+	"question_reaction_watermark": {},
+	"auto_questions_watermark":    {},
+}
+
+// secretConfigSuffixes tag credential-style keys (e.g. webhook_token) as secret.
+var secretConfigSuffixes = []string{"_token", "_secret", "_password", "_api_key"}
+
+// lookupConfigKey returns the spec for key. model_rate.<model> and
+// retention.<home> keys are portable; unlisted keys with a secret suffix are accepted as portable
+// secrets.
+func lookupConfigKey(key string) (configKeySpec, bool) {
+	if spec, ok := configRegistry[key]; ok {
+		return spec, true
+	}
+	if strings.HasPrefix(key, modelRatePrefix) || strings.HasPrefix(key, retentionPrefix) {
+		return configKeySpec{Portable: true}, true
+	}
+	for _, suffix := range secretConfigSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return configKeySpec{Portable: true, Secret: true, Scopes: anyScope}, true
+		}
+	}
+	return configKeySpec{}, false
+}
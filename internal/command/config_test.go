@@ -0,0 +1,94 @@
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+)
+
+func TestConfigScopePrecedence(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+
+	runFray(t, "config", "notify_quiet", "20:00-06:00", "--scope", "global")
+	if got := strings.TrimSpace(runFray(t, "config", "notify_quiet")); got != "notify_quiet: 20:00-06:00" {
+		t.Fatalf("expected global value, got %q", got)
+	}
+
+	runFray(t, "config", "notify_quiet", "21:00-07:00")
+	if got := strings.TrimSpace(runFray(t, "config", "notify_quiet")); got != "notify_quiet: 21:00-07:00" {
+		t.Fatalf("expected project to override global, got %q", got)
+	}
+
+	runFray(t, "config", "notify_quiet", "22:00-08:00", "--scope", "local")
+	if got := strings.TrimSpace(runFray(t, "config", "notify_quiet")); got != "notify_quiet: 22:00-08:00" {
+		t.Fatalf("expected local to override project, got %q", got)
+	}
+
+	// Each layer still holds its own value.
+	if got := strings.TrimSpace(runFray(t, "config", "notify_quiet", "--scope", "global")); got != "notify_quiet: 20:00-06:00" {
+		t.Fatalf("expected global layer unchanged, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".fray", "local", "config.json")); err != nil {
+		t.Fatalf("expected local config file: %v", err)
+	}
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	if got, _ := db.GetConfig(dbConn, "notify_quiet"); got != "21:00-07:00" {
+		t.Fatalf("expected project layer unchanged, got %q", got)
+	}
+}
+
+func TestConfigScopeRestrictedKeys(t *testing.T) {
+	newFlowProject(t, "alice")
+
+	for _, args := range [][]string{
+		{"config", "auto_thread_issues", "true", "--scope", "global"},
+		{"config", "precommit_strict", "true", "--scope", "global"},
+		{"config", "some_unknown_key", "x", "--scope", "local"},
+	} {
+		output, err := executeCommand(NewRootCmd("test"), args...)
+		if err == nil || !strings.Contains(output, "cannot be set at") {
+			t.Fatalf("expected scope rejection for %v, got %q (%v)", args, output, err)
+		}
+	}
+
+	runFray(t, "config", "precommit_strict", "true", "--scope", "local")
+	if _, err := executeCommand(NewRootCmd("test"), "config", "stale_hours", "3", "--scope", "bogus"); err == nil {
+		t.Fatal("expected invalid scope to fail")
+	}
+}
+
+func TestConfigListAttributesLayers(t *testing.T) {
+	newFlowProject(t, "alice")
+	runFray(t, "config", "username", "adam", "--scope", "global")
+	runFray(t, "config", "auto_thread_issues", "true")
+	runFray(t, "config", "stale_hours", "9", "--scope", "local")
+
+	var entries []db.ResolvedConfigEntry
+	output := runFray(t, "--json", "config", "list")
+	if err := json.Unmarshal([]byte(output), &entries); err != nil {
+		t.Fatalf("decode list: %v (%q)", err, output)
+	}
+	got := map[string]db.ResolvedConfigEntry{}
+	for _, entry := range entries {
+		got[entry.Key] = entry
+	}
+	for key, want := range map[string]db.ResolvedConfigEntry{
+		"username":           {Key: "username", Value: "adam", Scope: db.ConfigScopeGlobal},
+		"auto_thread_issues": {Key: "auto_thread_issues", Value: "true", Scope: db.ConfigScopeProject},
+		"stale_hours":        {Key: "stale_hours", Value: "9", Scope: db.ConfigScopeLocal},
+	} {
+		if got[key] != want {
+			t.Fatalf("%s = %+v, want %+v", key, got[key], want)
+		}
+	}
+
+	text := runFray(t, "config", "list")
+	if !strings.Contains(text, "username: adam (global)") || !strings.Contains(text, "stale_hours: 9 (local)") {
+		t.Fatalf("expected layer attribution in list, got %q", text)
+	}
+}
@@ -0,0 +1,245 @@
+package command
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+const (
+	configExportVersion = 1
+	maskedConfigValue   = "********"
+)
+
+// configExport is the file format for fray config export/import.
+type configExport struct {
+	Version int               `json:"version"`
+	Config  map[string]string `json:"config"`
+}
+
+// NewConfigExportCmd creates the config export command.
+func NewConfigExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export portable project settings as JSON",
+		Long: `Write the project's portable config keys to stdout as JSON.
+
+Only keys set with fray config are exported. The project file
+(.fray/fray-config.json: channel id and name, created_at, and known agents
+with their invoke settings) identifies this project and is not exported, so
+an import never re-homes another project onto this channel or its agents.
+Daemon state keys are left out too. Secret keys (*_token, *_secret,
+*_password, *_api_key) are masked unless --include-secrets.
+
+Examples:
+  fray config export > fray-settings.json
+  fray config import fray-settings.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+
+			entries, err := db.GetAllConfig(ctx.DB)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			export := configExport{Version: configExportVersion, Config: map[string]string{}}
+			for _, entry := range entries {
+				spec, known := lookupConfigKey(entry.Key)
+				if !known {
+					fmt.Fprintf(cmd.ErrOrStderr(), "skipping unknown key %s\n", entry.Key)
+					continue
+				}
+				if !spec.Portable {
+					continue
+				}
+				value := entry.Value
+				if spec.Secret && !includeSecrets {
+					value = maskedConfigValue
+				}
+				export.Config[entry.Key] = value
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(export)
+		},
+	}
+
+	cmd.Flags().Bool("include-secrets", false, "export secret values unmasked")
+	return cmd
+}
+
+// NewConfigImportCmd creates the config import command.
+func NewConfigImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import project settings exported by fray config export",
+		Long: `Apply settings from a fray config export file.
+
+Only config keys are imported; the target project keeps its own channel and
+known agents (see fray config export --help).
+
+Every key is validated before anything is written, and the import is applied
+in a single transaction. Keys that already hold a different value are
+reported as conflicts and nothing is applied unless --overwrite is given.
+Secret keys are skipped unless --include-secrets (masked values always are).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+			includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			var export configExport
+			if err := json.Unmarshal(data, &export); err != nil {
+				return writeCommandError(cmd, fmt.Errorf("invalid settings file: %w", err))
+			}
+			if export.Version != configExportVersion {
+				return writeCommandError(cmd, fmt.Errorf("unsupported settings version %d", export.Version))
+			}
+
+			plan, err := planConfigImport(ctx.DB, export.Config, includeSecrets)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if len(plan.Conflicts) > 0 && !overwrite {
+				if ctx.JSONMode {
+					_ = json.NewEncoder(cmd.OutOrStdout()).Encode(plan.masked())
+				} else {
+					out := cmd.OutOrStdout()
+					fmt.Fprintln(out, "Conflicts (use --overwrite to replace):")
+					for _, conflict := range plan.Conflicts {
+						fmt.Fprintf(out, "  %s: %s -> %s\n", conflict.Key, conflict.Current, conflict.Incoming)
+					}
+				}
+				return writeCommandError(cmd, fmt.Errorf("%d conflicting key(s); nothing imported", len(plan.Conflicts)))
+			}
+
+			if err := db.SetConfigEntries(ctx.DB, plan.Apply); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			shown := plan.masked()
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(shown)
+			}
+			out := cmd.OutOrStdout()
+			for _, entry := range shown.Apply {
+				fmt.Fprintf(out, "Set %s = %s\n", entry.Key, entry.Value)
+			}
+			for _, key := range plan.Skipped {
+				fmt.Fprintf(out, "Skipped secret %s\n", key)
+			}
+			fmt.Fprintf(out, "Imported %d key(s), %d unchanged\n", len(plan.Apply), len(plan.Unchanged))
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("overwrite", false, "replace keys that already have a different value")
+	cmd.Flags().Bool("include-secrets", false, "import secret keys")
+	return cmd
+}
+
+type configConflict struct {
+	Key      string `json:"key"`
+	Current  string `json:"current"`
+	Incoming string `json:"incoming"`
+}
+
+type configImportPlan struct {
+	Apply     []types.ConfigEntry `json:"applied"`
+	Unchanged []string            `json:"unchanged"`
+	Skipped   []string            `json:"skipped"`
+	Conflicts []configConflict    `json:"conflicts"`
+}
+
+// masked returns a copy of the plan for output, with secret values masked.
+// Conflicts are already masked when planned.
+func (p *configImportPlan) masked() *configImportPlan {
+	shown := *p
+	shown.Apply = make([]types.ConfigEntry, len(p.Apply))
+	for i, entry := range p.Apply {
+		if spec, _ := lookupConfigKey(entry.Key); spec.Secret {
+			entry.Value = maskedConfigValue
+		}
+		shown.Apply[i] = entry
+	}
+	return &shown
+}
+
+// planConfigImport validates incoming settings against the registry and works
+// out what an import would change. Any invalid key fails the whole import.
+func planConfigImport(dbConn *sql.DB, incoming map[string]string, includeSecrets bool) (*configImportPlan, error) {
+	keys := make([]string, 0, len(incoming))
+	for key := range incoming {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	plan := &configImportPlan{}
+	var problems []string
+	for _, raw := range keys {
+		key := normalizeConfigKey(raw)
+		value := incoming[raw]
+		spec, known := lookupConfigKey(key)
+		switch {
+		case !known:
+			problems = append(problems, fmt.Sprintf("%s: unknown config key", raw))
+			continue
+		case !spec.Portable:
+			problems = append(problems, fmt.Sprintf("%s: project-specific, not importable", raw))
+			continue
+		}
+		if spec.Secret && (!includeSecrets || value == maskedConfigValue) {
+			plan.Skipped = append(plan.Skipped, key)
+			continue
+		}
+		if err := validateConfigValue(key, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", raw, err))
+			continue
+		}
+
+		current, err := db.GetConfig(dbConn, key)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case current == value:
+			plan.Unchanged = append(plan.Unchanged, key)
+			continue
+		case current != "":
+			conflict := configConflict{Key: key, Current: current, Incoming: value}
+			if spec.Secret {
+				conflict.Current, conflict.Incoming = maskedConfigValue, maskedConfigValue
+			}
+			plan.Conflicts = append(plan.Conflicts, conflict)
+		}
+		plan.Apply = append(plan.Apply, types.ConfigEntry{Key: key, Value: value})
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid settings:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return plan, nil
+}
@@ -0,0 +1,142 @@
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+)
+
+func exportSettings(t *testing.T, args ...string) (configExport, string) {
+	t.Helper()
+	output := runFray(t, append([]string{"config", "export"}, args...)...)
+	var export configExport
+	if err := json.Unmarshal([]byte(output), &export); err != nil {
+		t.Fatalf("decode export: %v\n%s", err, output)
+	}
+	path := filepath.Join(t.TempDir(), "fray-settings.json")
+	if err := os.WriteFile(path, []byte(output), 0o644); err != nil {
+		t.Fatalf("write export: %v", err)
+	}
+	return export, path
+}
+
+func TestConfigExportImportRoundTrip(t *testing.T) {
+	newFlowProject(t, "alice")
+	runFray(t, "config", "stale_hours", "6")
+	runFray(t, "config", "notify_quiet", "22:00-08:00")
+	runFray(t, "config", "auto_thread_issues", "true")
+
+	export, path := exportSettings(t)
+	if _, ok := export.Config["channel_id"]; ok {
+		t.Fatalf("channel identity should not be exported: %v", export.Config)
+	}
+
+	// init seeds stale_hours, so the fresh project conflicts on it.
+	target := newFlowProject(t, "bob")
+	runFray(t, "config", "import", "--overwrite", path)
+
+	dbConn := openProjectDB(t, target)
+	defer dbConn.Close()
+	for key, want := range map[string]string{"stale_hours": "6", "notify_quiet": "22:00-08:00", "auto_thread_issues": "true"} {
+		if got, _ := db.GetConfig(dbConn, key); got != want {
+			t.Fatalf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	// Re-importing is a no-op.
+	output := runFray(t, "config", "import", path)
+	if !strings.Contains(output, "Imported 0 key(s), 3 unchanged") {
+		t.Fatalf("expected unchanged re-import, got %q", output)
+	}
+}
+
+func TestConfigImportReportsConflicts(t *testing.T) {
+	newFlowProject(t, "alice")
+	runFray(t, "config", "stale_hours", "6")
+	runFray(t, "config", "precommit_strict", "true")
+	_, path := exportSettings(t)
+
+	target := newFlowProject(t, "bob")
+	runFray(t, "config", "stale_hours", "12")
+
+	output, err := executeCommand(NewRootCmd("test"), "config", "import", path)
+	if err == nil {
+		t.Fatalf("expected conflict to fail import")
+	}
+	if !strings.Contains(output, "stale_hours: 12 -> 6") {
+		t.Fatalf("expected conflict report, got %q", output)
+	}
+	dbConn := openProjectDB(t, target)
+	strict, _ := db.GetConfig(dbConn, "precommit_strict")
+	_ = dbConn.Close()
+	if strict != "" {
+		t.Fatalf("expected nothing applied on conflict, got precommit_strict=%q", strict)
+	}
+
+	runFray(t, "config", "import", "--overwrite", path)
+	dbConn = openProjectDB(t, target)
+	defer dbConn.Close()
+	if got, _ := db.GetConfig(dbConn, "stale_hours"); got != "6" {
+		t.Fatalf("expected overwrite, got %q", got)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	_ = os.WriteFile(bad, []byte(`{"version":1,"config":{"stale_hours":"-1","mystery":"x"}}`), 0o644)
+	output, err = executeCommand(NewRootCmd("test"), "config", "import", bad)
+	if err == nil || !strings.Contains(output, "mystery: unknown config key") {
+		t.Fatalf("expected validation failure, got %v %q", err, output)
+	}
+}
+
+func TestConfigExportMasksSecrets(t *testing.T) {
+	newFlowProject(t, "alice")
+	runFray(t, "config", "webhook_token", "s3cret")
+
+	masked, maskedPath := exportSettings(t)
+	if masked.Config["webhook_token"] != maskedConfigValue {
+		t.Fatalf("expected masked secret, got %q", masked.Config["webhook_token"])
+	}
+	_, plainPath := exportSettings(t, "--include-secrets")
+
+	target := newFlowProject(t, "bob")
+	output := runFray(t, "config", "import", "--include-secrets", maskedPath)
+	if !strings.Contains(output, "Skipped secret webhook_token") {
+		t.Fatalf("expected masked secret skipped, got %q", output)
+	}
+	runFray(t, "config", "import", plainPath)
+	dbConn := openProjectDB(t, target)
+	got, _ := db.GetConfig(dbConn, "webhook_token")
+	_ = dbConn.Close()
+	if got != "" {
+		t.Fatalf("expected secret skipped without --include-secrets, got %q", got)
+	}
+
+	output = runFray(t, "config", "import", "--include-secrets", plainPath)
+	if strings.Contains(output, "s3cret") || !strings.Contains(output, "Set webhook_token = "+maskedConfigValue) {
+		t.Fatalf("expected the imported secret masked, got %q", output)
+	}
+	dbConn = openProjectDB(t, target)
+	defer dbConn.Close()
+	if got, _ := db.GetConfig(dbConn, "webhook_token"); got != "s3cret" {
+		t.Fatalf("expected secret imported, got %q", got)
+	}
+
+	// A conflicting secret is reported without either value.
+	runFray(t, "config", "webhook_token", "l0cal")
+	for _, args := range [][]string{
+		{"config", "import", "--include-secrets", plainPath},
+		{"config", "import", "--include-secrets", "--json", plainPath},
+	} {
+		output, err := executeCommand(NewRootCmd("test"), args...)
+		if err == nil || !strings.Contains(output, "webhook_token") {
+			t.Fatalf("expected a secret conflict, got %v %q", err, output)
+		}
+		if strings.Contains(output, "s3cret") || strings.Contains(output, "l0cal") {
+			t.Fatalf("secret conflict leaked a value: %q", output)
+		}
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
 	"github.com/spf13/cobra"
 )
 
@@ -30,71 +32,45 @@ func GetContext(cmd *cobra.Command) (*CommandContext, error) {
 	channelRef, _ := cmd.Flags().GetString("in")
 	force, _ := cmd.Flags().GetBool("force")
 
-	if projectAlias != "" {
-		mainProject, err := core.DiscoverProject("")
-		if err != nil {
-			return nil, err
-		}
-		mainDB, err := db.OpenDatabase(mainProject)
-		if err != nil {
-			return nil, err
-		}
-		if err := db.InitSchema(mainDB); err != nil {
-			_ = mainDB.Close()
-			return nil, err
-		}
-
-		linked, err := db.GetLinkedProject(mainDB, projectAlias)
-		_ = mainDB.Close()
-		if err != nil {
-			return nil, err
-		}
-		if linked == nil {
-			return nil, fmt.Errorf("linked project '%s' not found. Use 'fray link' first", projectAlias)
-		}
-		if _, err := os.Stat(linked.Path); err != nil {
-			return nil, fmt.Errorf("linked project '%s' database not found at %s", projectAlias, linked.Path)
-		}
-
-		project, err := projectFromDBPath(linked.Path)
-		if err != nil {
-			return nil, err
-		}
-		linkedDB, err := db.OpenDatabase(project)
+	var ctx *ChannelContext
+	if root, ok := projectRootFromFlag(projectAlias); ok {
+		var err error
+		ctx, err = resolveProjectRootContext(root)
 		if err != nil {
-			return nil, err
+			return nil, unavailableError(err)
 		}
-		if err := db.InitSchema(linkedDB); err != nil {
-			_ = linkedDB.Close()
-			return nil, err
+	} else if projectAlias != "" {
+		linked, err := lookupLinkedProject(projectAlias)
+		switch {
+		case linked != nil:
+			return linkedProjectContext(projectAlias, linked, jsonMode, force)
+		case isProjectDir(projectAlias):
+			// Not a linked alias, but a project directory by that name.
+			ctx, err = resolveProjectRootContext(projectAlias)
+			if err != nil {
+				return nil, unavailableError(err)
+			}
+		case err != nil:
+			return nil, unavailableError(err)
+		default:
+			return nil, notFoundError("linked project '%s' not found. Use 'fray link' first", projectAlias)
 		}
+	}
 
-		config, err := db.ReadProjectConfig(project.DBPath)
+	if ctx == nil {
+		var err error
+		ctx, err = ResolveChannelContext(channelRef, "")
 		if err != nil {
-			_ = linkedDB.Close()
-			return nil, err
+			return nil, unavailableError(err)
 		}
-
-		return &CommandContext{
-			DB:            linkedDB,
-			Project:       project,
-			JSONMode:      jsonMode,
-			ProjectConfig: config,
-			Force:         force,
-		}, nil
-	}
-
-	ctx, err := ResolveChannelContext(channelRef, "")
-	if err != nil {
-		return nil, err
 	}
 	conn, err := db.OpenDatabase(ctx.Project)
 	if err != nil {
-		return nil, err
+		return nil, unavailableError(err)
 	}
 	if err := db.InitSchema(conn); err != nil {
 		_ = conn.Close()
-		return nil, err
+		return nil, unavailableError(err)
 	}
 
 	return &CommandContext{
@@ -117,14 +93,8 @@ type ChannelContext struct {
 }
 
 // ResolveChannelContext resolves channel context using global config and local project.
+// An empty cwd defers to core.DiscoverProject, which honors FRAY_PROJECT_ROOT.
 func ResolveChannelContext(channelRef string, cwd string) (*ChannelContext, error) {
-	if cwd == "" {
-		var err error
-		cwd, err = os.Getwd()
-		if err != nil {
-			return nil, err
-		}
-	}
 	globalConfig, err := core.ReadGlobalConfig()
 	if err != nil {
 		return nil, err
@@ -165,22 +135,135 @@ func ResolveChannelContext(channelRef string, cwd string) (*ChannelContext, erro
 	if err != nil {
 		return nil, err
 	}
-	localConfig, err := db.ReadProjectConfig(localProject.DBPath)
+	return channelContextForProject(localProject)
+}
+
+// resolveProjectRootContext resolves channel context for an explicit project root.
+func resolveProjectRootContext(root string) (*ChannelContext, error) {
+	project, err := core.OpenProjectAt(root)
 	if err != nil {
 		return nil, err
 	}
-	if localConfig == nil || localConfig.ChannelID == "" {
+	return channelContextForProject(project)
+}
+
+func channelContextForProject(project core.Project) (*ChannelContext, error) {
+	config, err := db.ReadProjectConfig(project.DBPath)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil || config.ChannelID == "" {
 		return nil, errors.New("no channel context: local .fray/ directory has no channel_id")
 	}
 
 	return &ChannelContext{
-		Project:       localProject,
-		ChannelID:     localConfig.ChannelID,
-		ChannelName:   localConfig.ChannelName,
-		ProjectConfig: localConfig,
+		Project:       project,
+		ChannelID:     config.ChannelID,
+		ChannelName:   config.ChannelName,
+		ProjectConfig: config,
 	}, nil
 }
 
+// projectForFlag resolves a --project value the way GetContext does, without
+// opening the database: an explicit path, then a linked alias, then a
+// project directory by that name. An empty value falls back to
+// core.DiscoverProject, which honors FRAY_PROJECT_ROOT.
+func projectForFlag(value string) (core.Project, error) {
+	if root, ok := projectRootFromFlag(value); ok {
+		return core.OpenProjectAt(root)
+	}
+	if value == "" {
+		return core.DiscoverProject("")
+	}
+	linked, err := lookupLinkedProject(value)
+	switch {
+	case linked != nil:
+		return projectFromDBPath(linked.Path)
+	case isProjectDir(value):
+		return core.OpenProjectAt(value)
+	case err != nil:
+		return core.Project{}, err
+	}
+	return core.Project{}, fmt.Errorf("linked project '%s' not found", value)
+}
+
+// lookupLinkedProject finds alias among the current project's linked
+// projects. It returns nil without an error when there is no such link.
+func lookupLinkedProject(alias string) (*types.LinkedProject, error) {
+	mainProject, err := core.DiscoverProject("")
+	if err != nil {
+		return nil, err
+	}
+	mainDB, err := db.OpenDatabase(mainProject)
+	if err != nil {
+		return nil, err
+	}
+	defer mainDB.Close()
+	if err := db.InitSchema(mainDB); err != nil {
+		return nil, err
+	}
+	return db.GetLinkedProject(mainDB, alias)
+}
+
+// linkedProjectContext opens the project behind a linked alias.
+func linkedProjectContext(alias string, linked *types.LinkedProject, jsonMode, force bool) (*CommandContext, error) {
+	if _, err := os.Stat(linked.Path); err != nil {
+		return nil, unavailableError(fmt.Errorf("linked project '%s' database not found at %s", alias, linked.Path))
+	}
+
+	project, err := projectFromDBPath(linked.Path)
+	if err != nil {
+		return nil, unavailableError(err)
+	}
+	linkedDB, err := db.OpenDatabase(project)
+	if err != nil {
+		return nil, unavailableError(err)
+	}
+	if err := db.InitSchema(linkedDB); err != nil {
+		_ = linkedDB.Close()
+		return nil, unavailableError(err)
+	}
+
+	config, err := db.ReadProjectConfig(project.DBPath)
+	if err != nil {
+		_ = linkedDB.Close()
+		return nil, unavailableError(err)
+	}
+
+	return &CommandContext{
+		DB:            linkedDB,
+		Project:       project,
+		JSONMode:      jsonMode,
+		ProjectConfig: config,
+		Force:         force,
+	}, nil
+}
+
+// projectRootFromFlag reports whether a --project value is an explicit
+// path: one starting with "." or "~/" (or just "~"), or containing a path
+// separator. "~user" is not expanded. Bare names are linked-project aliases
+// first and directories only when no link matches; see GetContext.
+func projectRootFromFlag(value string) (string, bool) {
+	switch {
+	case value == "":
+		return "", false
+	case value == "~" || strings.HasPrefix(value, "~/"):
+		if home, err := os.UserHomeDir(); err == nil {
+			value = filepath.Join(home, strings.TrimPrefix(value, "~"))
+		}
+		return value, true
+	case strings.HasPrefix(value, "."), strings.ContainsRune(value, filepath.Separator), strings.Contains(value, "/"):
+		return value, true
+	}
+	return "", false
+}
+
+// isProjectDir reports whether dir contains a .fray directory.
+func isProjectDir(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, ".fray"))
+	return err == nil && info.IsDir()
+}
+
 // ResolveAgentRef resolves agent IDs using known agent aliases.
 func ResolveAgentRef(ref string, config *db.ProjectConfig) string {
 	normalized := core.NormalizeAgentRef(ref)
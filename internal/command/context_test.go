@@ -1,31 +1,19 @@
 package command
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
 )
 
 func TestResolveChannelContextByName(t *testing.T) {
-	tmpHome := t.TempDir()
-	t.Setenv("HOME", tmpHome)
-
-	projectDir := t.TempDir()
-	project, err := core.InitProject(projectDir, false)
-	if err != nil {
-		t.Fatalf("init project: %v", err)
-	}
-
-	dbConn, err := db.OpenDatabase(project)
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-	if err := db.InitSchema(dbConn); err != nil {
-		_ = dbConn.Close()
-		t.Fatalf("init schema: %v", err)
-	}
-	_ = dbConn.Close()
+	p := testutil.NewProject(t)
+	p.OpenDB(t, db.OpenDatabase, db.InitSchema)
+	projectDir, project := p.Root, p.Project
 
 	channelID := "ch-12345678"
 	channelName := "alpha"
@@ -53,21 +41,9 @@ func TestResolveChannelContextByName(t *testing.T) {
 }
 
 func TestResolveChannelContextLocal(t *testing.T) {
-	projectDir := t.TempDir()
-	project, err := core.InitProject(projectDir, false)
-	if err != nil {
-		t.Fatalf("init project: %v", err)
-	}
-
-	dbConn, err := db.OpenDatabase(project)
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-	if err := db.InitSchema(dbConn); err != nil {
-		_ = dbConn.Close()
-		t.Fatalf("init schema: %v", err)
-	}
-	_ = dbConn.Close()
+	p := testutil.NewProject(t)
+	p.OpenDB(t, db.OpenDatabase, db.InitSchema)
+	projectDir, project := p.Root, p.Project
 
 	channelID := "ch-22222222"
 	if _, err := db.UpdateProjectConfig(project.DBPath, db.ProjectConfig{
@@ -84,3 +60,109 @@ func TestResolveChannelContextLocal(t *testing.T) {
 		t.Fatalf("expected channel id %s, got %s", channelID, ctx.ChannelID)
 	}
 }
+
+func TestGetContextProjectFlagAndEnvPrecedence(t *testing.T) {
+	makeProject := func(channelID string) string {
+		p := testutil.NewProject(t)
+		p.OpenDB(t, db.OpenDatabase, db.InitSchema)
+		if _, err := db.UpdateProjectConfig(p.Project.DBPath, db.ProjectConfig{ChannelID: channelID}); err != nil {
+			t.Fatalf("update config: %v", err)
+		}
+		return p.Root
+	}
+	cwdProject := makeProject("ch-cwd00000")
+	envProject := makeProject("ch-env00000")
+	flagProject := makeProject("ch-flag0000")
+
+	testutil.Chdir(t, cwdProject)
+
+	channelFor := func(args ...string) string {
+		t.Helper()
+		cmd := NewRootCmd("test")
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatalf("parse flags: %v", err)
+		}
+		ctx, err := GetContext(cmd)
+		if err != nil {
+			t.Fatalf("get context: %v", err)
+		}
+		defer ctx.DB.Close()
+		return ctx.ChannelID
+	}
+
+	if got := channelFor(); got != "ch-cwd00000" {
+		t.Fatalf("expected cwd project, got %s", got)
+	}
+
+	t.Setenv(core.ProjectRootEnv, envProject)
+	if got := channelFor(); got != "ch-env00000" {
+		t.Fatalf("expected env project, got %s", got)
+	}
+	if got := channelFor("--project", flagProject); got != "ch-flag0000" {
+		t.Fatalf("expected flag project, got %s", got)
+	}
+}
+
+func TestGetContextProjectFlagPrefersLinkedAlias(t *testing.T) {
+	makeProject := func(root, channelID string) core.Project {
+		project, err := core.InitProject(root, false)
+		if err != nil {
+			t.Fatalf("init project: %v", err)
+		}
+		conn, err := db.OpenDatabase(project)
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		defer conn.Close()
+		if err := db.InitSchema(conn); err != nil {
+			t.Fatalf("init schema: %v", err)
+		}
+		if _, err := db.UpdateProjectConfig(project.DBPath, db.ProjectConfig{ChannelID: channelID}); err != nil {
+			t.Fatalf("update config: %v", err)
+		}
+		return project
+	}
+	cwd := testutil.NewProject(t)
+	cwdDB := cwd.OpenDB(t, db.OpenDatabase, db.InitSchema)
+	linked := makeProject(t.TempDir(), "ch-link0000")
+	makeProject(filepath.Join(cwd.Root, "alpha"), "ch-dir00000")
+	makeProject(filepath.Join(cwd.Root, "beta"), "ch-beta0000")
+	if err := db.LinkProject(cwdDB, "alpha", linked.DBPath); err != nil {
+		t.Fatalf("link project: %v", err)
+	}
+	testutil.Chdir(t, cwd.Root)
+
+	channelFor := func(project string) string {
+		t.Helper()
+		cmd := NewRootCmd("test")
+		if err := cmd.ParseFlags([]string{"--project", project}); err != nil {
+			t.Fatalf("parse flags: %v", err)
+		}
+		ctx, err := GetContext(cmd)
+		if err != nil {
+			t.Fatalf("get context for %s: %v", project, err)
+		}
+		defer ctx.DB.Close()
+		return ctx.ProjectConfig.ChannelID
+	}
+
+	// A link wins over a same-named directory; ./ forces the directory, and
+	// a bare name with no link still finds a project directory.
+	if got := channelFor("alpha"); got != "ch-link0000" {
+		t.Fatalf("expected the linked alias, got %s", got)
+	}
+	if got := channelFor("./alpha"); got != "ch-dir00000" {
+		t.Fatalf("expected the directory, got %s", got)
+	}
+	if got := channelFor("beta"); got != "ch-beta0000" {
+		t.Fatalf("expected the unlinked directory, got %s", got)
+	}
+
+	home, _ := os.UserHomeDir()
+	if root, ok := projectRootFromFlag("~/work"); !ok || root != filepath.Join(home, "work") {
+		t.Fatalf("expected ~/ expanded, got %q %v", root, ok)
+	}
+	if _, ok := projectRootFromFlag("~bob"); ok {
+		t.Fatal("expected ~user to be left alone")
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/daemon"
 	"github.com/spf13/cobra"
 )
@@ -26,9 +29,21 @@ The daemon:
 - Tracks agent presence (spawning, active, idle, error, offline)
 - Records session lifecycle events to agents.jsonl
 
-Only one daemon can run per project (enforced via lock file).
-Use Ctrl+C or SIGTERM to gracefully shut down.`,
+Only one daemon can run per project. The running daemon holds
+.fray/local/daemon.lease (pid, hostname, heartbeat) and refreshes it every
+10s; a second daemon refuses to start while the lease is fresh. If the old
+daemon died without cleaning up, --takeover replaces its lease once the pid
+is confirmed dead, or wait 30s for the lease to go stale.
+Use Ctrl+C or SIGTERM to gracefully shut down.
+
+With --all-channels, one process serves every channel in the global registry
+(see 'fray ls'). Each project keeps its own lock and state; log lines are
+prefixed with the channel name. Toggle channels with 'fray daemon channels'.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if allChannels, _ := cmd.Flags().GetBool("all-channels"); allChannels {
+				return runMultiProjectDaemon(cmd)
+			}
+
 			cmdCtx, err := GetContext(cmd)
 			if err != nil {
 				return writeCommandError(cmd, err)
@@ -40,10 +55,13 @@ Use Ctrl+C or SIGTERM to gracefully shut down.`,
 				pollInterval = 1 * time.Second
 			}
 			debug, _ := cmd.Flags().GetBool("debug")
+			takeover, _ := cmd.Flags().GetBool("takeover")
 
 			cfg := daemon.Config{
 				PollInterval: pollInterval,
 				Debug:        debug,
+				Retention:    runScheduledRetention,
+				Takeover:     takeover,
 			}
 
 			d := daemon.New(cmdCtx.Project, cmdCtx.DB, cfg)
@@ -100,8 +118,12 @@ Use Ctrl+C or SIGTERM to gracefully shut down.`,
 
 	cmd.Flags().Duration("poll-interval", 1*time.Second, "how often to poll for mentions")
 	cmd.Flags().Bool("debug", false, "enable debug logging")
+	cmd.Flags().Bool("takeover", false, "replace a fresh lease whose daemon pid is dead")
+	cmd.Flags().Bool("all-channels", false, "serve every registered channel from one process")
+	cmd.Flags().Duration("refresh-interval", daemon.DefaultRefreshInterval, "how often --all-channels re-reads the channel registry")
 
 	cmd.AddCommand(NewDaemonStatusCmd())
+	cmd.AddCommand(NewDaemonChannelsCmd())
 
 	return cmd
 }
@@ -118,19 +140,33 @@ func NewDaemonStatusCmd() *cobra.Command {
 			}
 			defer cmdCtx.DB.Close()
 
-			frayDir := cmdCtx.Project.Root + "/.fray"
-			isLocked := daemon.IsLocked(frayDir)
+			frayDir := filepath.Dir(cmdCtx.Project.DBPath)
+			lease, err := daemon.ReadLease(frayDir)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			now := time.Now()
+			running := lease != nil && lease.Held(now)
 
 			if cmdCtx.JSONMode {
 				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
-					"running": isLocked,
+					"running": running,
+					"lease":   lease,
 				})
 			}
 
-			if isLocked {
-				fmt.Fprintln(cmd.OutOrStdout(), "Daemon is running")
-			} else {
-				fmt.Fprintln(cmd.OutOrStdout(), "Daemon is not running")
+			out := cmd.OutOrStdout()
+			switch {
+			case lease == nil:
+				fmt.Fprintln(out, "Daemon is not running")
+			case running:
+				fmt.Fprintf(out, "Daemon is running (pid %d on %s, heartbeat %s ago)\n",
+					lease.PID, lease.Hostname, lease.Age(now).Round(time.Second))
+			case lease.Stale(now):
+				fmt.Fprintf(out, "Daemon is not running (stale lease from pid %d on %s, last heartbeat %s ago; fray doctor --fix clears it)\n",
+					lease.PID, lease.Hostname, lease.Age(now).Round(time.Second))
+			default:
+				fmt.Fprintf(out, "Daemon is not running (pid %d exited without releasing its lease; start with --takeover)\n", lease.PID)
 			}
 			return nil
 		},
@@ -138,3 +174,126 @@ func NewDaemonStatusCmd() *cobra.Command {
 
 	return cmd
 }
+
+// runMultiProjectDaemon serves every enabled registered channel until
+// interrupted.
+func runMultiProjectDaemon(cmd *cobra.Command) error {
+	jsonMode, _ := cmd.Flags().GetBool("json")
+	pollInterval, _ := cmd.Flags().GetDuration("poll-interval")
+	refreshInterval, _ := cmd.Flags().GetDuration("refresh-interval")
+	debug, _ := cmd.Flags().GetBool("debug")
+	takeover, _ := cmd.Flags().GetBool("takeover")
+
+	supervisor := daemon.NewSupervisor(daemon.SupervisorConfig{
+		Daemon:          daemon.Config{PollInterval: pollInterval, Debug: debug, Retention: runScheduledRetention, Takeover: takeover},
+		RefreshInterval: refreshInterval,
+		Warn:            cmd.ErrOrStderr(),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	if err := supervisor.Start(ctx); err != nil {
+		return writeCommandError(cmd, err)
+	}
+
+	running := supervisor.Running()
+	if jsonMode {
+		json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+			"status":   "started",
+			"channels": running,
+		})
+	} else {
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Daemon started for %d channel(s) (poll interval: %s)\n", len(running), pollInterval)
+		for _, entry := range running {
+			fmt.Fprintf(out, "  %s  %s\n", entry.Name, entry.Root)
+		}
+		fmt.Fprintln(out, "Press Ctrl+C to stop")
+	}
+
+	<-sigCh
+
+	if !jsonMode {
+		fmt.Fprintln(cmd.OutOrStdout(), "\nShutting down...")
+	}
+	if err := supervisor.Stop(); err != nil {
+		return writeCommandError(cmd, err)
+	}
+	if jsonMode {
+		return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{"status": "stopped"})
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "Daemon stopped")
+	return nil
+}
+
+// NewDaemonChannelsCmd lists and toggles channels for --all-channels.
+func NewDaemonChannelsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "channels",
+		Short: "List channels served by 'fray daemon --all-channels'",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonMode, _ := cmd.Flags().GetBool("json")
+			entries, err := daemon.RegistryProjects()
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if entries == nil {
+				entries = []daemon.ProjectEntry{}
+			}
+			if jsonMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{"channels": entries})
+			}
+
+			out := cmd.OutOrStdout()
+			if len(entries) == 0 {
+				fmt.Fprintln(out, "No channels registered")
+				return nil
+			}
+			for _, entry := range entries {
+				state := "enabled"
+				if !entry.Enabled {
+					state = "disabled"
+				}
+				running := ""
+				if daemon.IsLocked(filepath.Join(entry.Root, ".fray")) {
+					running = ", running"
+				}
+				fmt.Fprintf(out, "  %s  %s  %s (%s%s)\n", entry.ChannelID, entry.Name, entry.Root, state, running)
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(newDaemonChannelToggleCmd("enable", true))
+	cmd.AddCommand(newDaemonChannelToggleCmd("disable", false))
+	return cmd
+}
+
+func newDaemonChannelToggleCmd(verb string, enabled bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   verb + " <channel>",
+		Short: fmt.Sprintf("%s a channel for 'fray daemon --all-channels'", strings.ToUpper(verb[:1])+verb[1:]),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonMode, _ := cmd.Flags().GetBool("json")
+			id, channel, err := core.SetChannelDaemonEnabled(args[0], enabled)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if jsonMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(daemon.ProjectEntry{
+					ChannelID: id,
+					Name:      channel.Name,
+					Root:      channel.Path,
+					Enabled:   enabled,
+				})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%sd %s for the multi-project daemon\n", strings.ToUpper(verb[:1])+verb[1:], channel.Name)
+			return nil
+		},
+	}
+}
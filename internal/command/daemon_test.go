@@ -0,0 +1,49 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/daemon"
+)
+
+func TestDaemonChannelsToggle(t *testing.T) {
+	newFlowProject(t, "alice")
+
+	listChannels := func() []daemon.ProjectEntry {
+		t.Helper()
+		var payload struct {
+			Channels []daemon.ProjectEntry `json:"channels"`
+		}
+		output := runFray(t, "--json", "daemon", "channels")
+		if err := json.Unmarshal([]byte(output), &payload); err != nil {
+			t.Fatalf("decode %q: %v", output, err)
+		}
+		return payload.Channels
+	}
+
+	channels := listChannels()
+	if len(channels) != 1 || !channels[0].Enabled {
+		t.Fatalf("expected one enabled channel after init, got %+v", channels)
+	}
+
+	output := runFray(t, "daemon", "channels", "disable", channels[0].ChannelID)
+	if !strings.Contains(output, "Disabled") {
+		t.Fatalf("expected disable confirmation, got %q", output)
+	}
+	if channels = listChannels(); channels[0].Enabled {
+		t.Fatalf("expected channel disabled, got %+v", channels[0])
+	}
+
+	// Re-running init re-registers the channel without re-enabling it.
+	runFray(t, "init", "--defaults")
+	if channels = listChannels(); channels[0].Enabled {
+		t.Fatalf("expected re-registration to keep the channel disabled, got %+v", channels[0])
+	}
+
+	runFray(t, "daemon", "channels", "enable", channels[0].Name)
+	if channels = listChannels(); !channels[0].Enabled {
+		t.Fatalf("expected channel re-enabled, got %+v", channels[0])
+	}
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/adamavenir/fray/internal/devtool"
+	"github.com/spf13/cobra"
+)
+
+// NewDevtoolCmd creates the hidden devtool command.
+func NewDevtoolCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "devtool",
+		Short:  "Tools for working on fray itself",
+		Hidden: true,
+	}
+	cmd.AddCommand(newDevtoolGenerateCmd())
+	return cmd
+}
+
+func newDevtoolGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a large deterministic project for performance testing",
+		Long: `Synthesize a fray project: agents with varied drivers, nested threads, and
+messages with reply chains, mentions, reactions, pins, and edits, all written
+through the same JSONL code paths fray uses. The same --seed always produces
+the same files. The SQLite cache is built on first use (or fray rebuild).
+
+Examples:
+  fray devtool generate --messages 100000 --agents 12 --threads 40 --seed 42 --out ./bigproj
+  cd bigproj && time fray rebuild`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonMode, _ := cmd.Flags().GetBool("json")
+			out, _ := cmd.Flags().GetString("out")
+			if out == "" {
+				return writeCommandError(cmd, validationError("--out is required"))
+			}
+			opts := devtool.Options{Dir: out}
+			opts.Messages, _ = cmd.Flags().GetInt("messages")
+			opts.Agents, _ = cmd.Flags().GetInt("agents")
+			opts.Threads, _ = cmd.Flags().GetInt("threads")
+			opts.Seed, _ = cmd.Flags().GetInt64("seed")
+
+			summary, err := devtool.Generate(opts)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			w := cmd.OutOrStdout()
+			if jsonMode {
+				return json.NewEncoder(w).Encode(summary)
+			}
+			fmt.Fprintf(w, "Generated %s (seed %d) in %s\n", summary.Dir, summary.Seed, summary.Elapsed.Round(1e6))
+			fmt.Fprintf(w, "  agents:    %d\n", summary.Agents)
+			fmt.Fprintf(w, "  threads:   %d\n", summary.Threads)
+			fmt.Fprintf(w, "  messages:  %d (%d replies, %d mentions)\n", summary.Messages, summary.Replies, summary.Mentions)
+			fmt.Fprintf(w, "  reactions: %d\n", summary.Reactions)
+			fmt.Fprintf(w, "  pins:      %d\n", summary.Pins)
+			fmt.Fprintf(w, "  edits:     %d\n", summary.Edits)
+			return nil
+		},
+	}
+
+	cmd.Flags().Int("messages", 10000, "number of messages")
+	cmd.Flags().Int("agents", 12, "number of agents")
+	cmd.Flags().Int("threads", 40, "number of threads")
+	cmd.Flags().Int64("seed", 1, "random seed")
+	cmd.Flags().String("out", "", "directory for the new project (must not already be a fray project)")
+	return cmd
+}
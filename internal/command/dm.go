@@ -0,0 +1,180 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// NewDMCmd creates the dm command.
+func NewDMCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dm <@agent> <message>",
+		Short: "Send a direct message to one agent",
+		Long: `Send a direct message without posting in the room.
+
+Each pair of identities shares one dm thread (dm/<a>+<b>, names sorted),
+created on first use. dm threads are only listed for their two participants
+(fray threads --all shows everything), and the daemon treats every dm as a
+direct address, so a dm from another agent wakes the recipient.
+
+Examples:
+  fray dm @dev "please rebase before continuing" --as pm
+  fray get dm/dev+pm --as dev`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			agentRef, _ := cmd.Flags().GetString("as")
+			if agentRef == "" {
+				return writeCommandError(cmd, fmt.Errorf("--as is required"))
+			}
+			fromID, isHuman, err := resolveDMIdentity(ctx, agentRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			toID, _, err := resolveDMIdentity(ctx, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if fromID == toID {
+				return writeCommandError(cmd, fmt.Errorf("cannot dm yourself"))
+			}
+
+			thread, err := ensureDMThread(ctx, fromID, toID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			body := args[1]
+			if err := checkMessageContent(cmd, ctx, body); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			bases, err := db.GetAgentBases(ctx.DB)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			mentions := core.ExtractMentions(body, bases)
+			if !containsString(mentions, toID) {
+				mentions = append(mentions, toID)
+			}
+
+			msgType := types.MessageTypeAgent
+			if isHuman {
+				msgType = types.MessageTypeUser
+			}
+			now := time.Now().Unix()
+			created, err := createMessage(cmd, ctx, types.Message{
+				TS:        now,
+				FromAgent: fromID,
+				Body:      body,
+				Mentions:  mentions,
+				Home:      thread.GUID,
+				Type:      msgType,
+			})
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.AppendMessage(ctx.Project.DBPath, created); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if !isHuman {
+				updates := db.AgentUpdates{LastSeen: types.OptionalInt64{Set: true, Value: &now}}
+				if err := db.UpdateAgent(ctx.DB, fromID, updates); err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
+
+			path := core.DMThreadRoot + "/" + thread.Name
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"id":     created.ID,
+					"from":   fromID,
+					"to":     toID,
+					"thread": thread.GUID,
+					"path":   path,
+				})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "[%s] @%s → @%s (%s)\n", created.ID, fromID, toID, path)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent or user sending the dm")
+	cmd.Flags().Bool("allow-secrets", false, "send even if the content policy flags a secret")
+	return cmd
+}
+
+// resolveDMIdentity resolves an agent or the stored human username.
+func resolveDMIdentity(ctx *CommandContext, ref string) (string, bool, error) {
+	agentID, err := resolveAgentRef(ctx, ref)
+	if err != nil {
+		return "", false, err
+	}
+	agent, err := db.GetAgent(ctx.DB, agentID)
+	if err != nil {
+		return "", false, err
+	}
+	if agent != nil {
+		if agent.LeftAt != nil {
+			return "", false, fmt.Errorf("agent @%s has left. Use 'fray back @%s' to resume", agentID, agentID)
+		}
+		return agentID, false, nil
+	}
+	if username, _ := humanIdentity(ctx); username != "" && username == agentID {
+		return agentID, true, nil
+	}
+	return "", false, fmt.Errorf("agent not found: @%s", agentID)
+}
+
+// ensureDMThread returns the dm thread for two identities, creating the dm/
+// root and the pair thread on first use. Both sides are subscribed.
+func ensureDMThread(ctx *CommandContext, a, b string) (*types.Thread, error) {
+	root, err := ensureDMTypedThread(ctx, core.DMThreadRoot, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ensureDMTypedThread(ctx, core.DMThreadName(a, b), &root.GUID, []string{a, b})
+}
+
+func ensureDMTypedThread(ctx *CommandContext, name string, parentGUID *string, subscribers []string) (*types.Thread, error) {
+	thread, err := db.GetThreadByName(ctx.DB, name, parentGUID)
+	if err != nil {
+		return nil, err
+	}
+	if thread != nil {
+		return thread, nil
+	}
+
+	// Either participant may curate a dm, so it records no single creator.
+	now := time.Now().Unix()
+	created, err := db.CreateThread(ctx.DB, types.Thread{
+		Name:         name,
+		ParentThread: parentGUID,
+		Status:       types.ThreadStatusOpen,
+		Type:         types.ThreadTypeDM,
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AppendThread(ctx.Project.DBPath, created, subscribers); err != nil {
+		return nil, err
+	}
+	for _, agentID := range subscribers {
+		if err := db.SubscribeThread(ctx.DB, created.GUID, agentID, now); err != nil {
+			return nil, err
+		}
+	}
+	return &created, nil
+}
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestDMReusesPairThread(t *testing.T) {
+	projectDir := newFlowProject(t, "pm", "dev")
+
+	first := runFray(t, "dm", "@dev", "please rebase before continuing", "--as", "pm")
+	if !strings.Contains(first, "(dm/dev+pm)") {
+		t.Fatalf("expected dm/dev+pm thread, got %q", first)
+	}
+	runFray(t, "dm", "@pm", "rebased", "--as", "dev")
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	thread, err := resolveThreadRef(dbConn, "dm/dev+pm")
+	if err != nil {
+		t.Fatalf("resolve dm thread: %v", err)
+	}
+	if thread.Type != types.ThreadTypeDM {
+		t.Fatalf("expected dm thread type, got %q", thread.Type)
+	}
+	home := thread.GUID
+	messages, err := db.GetMessages(dbConn, &types.MessageQueryOptions{Home: &home})
+	if err != nil {
+		t.Fatalf("get dm messages: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected both dms in one thread, got %d", len(messages))
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "dm", "@pm", "hi me", "--as", "pm"); err == nil {
+		t.Fatalf("expected dm to self to fail")
+	}
+}
+
+func TestDMThreadsListedOnlyForParticipants(t *testing.T) {
+	projectDir := newFlowProject(t, "pm", "dev", "alice")
+	runFray(t, "dm", "@dev", "please rebase", "--as", "pm")
+	runFray(t, "thread", "design")
+
+	dbConn := openProjectDB(t, projectDir)
+	thread, err := resolveThreadRef(dbConn, "dm/dev+pm")
+	if err != nil {
+		t.Fatalf("resolve dm thread: %v", err)
+	}
+	// Even an explicit subscription doesn't list someone else's dm.
+	if err := db.SubscribeThread(dbConn, thread.GUID, "alice", 0); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	_ = dbConn.Close()
+
+	if output := runFray(t, "threads", "--as", "dev"); !strings.Contains(output, "dm/dev+pm") {
+		t.Fatalf("expected participant to see dm, got %q", output)
+	}
+	if output := runFray(t, "threads", "--as", "alice"); strings.Contains(output, "dev+pm") {
+		t.Fatalf("expected dm hidden from non-participant, got %q", output)
+	}
+	if output := runFray(t, "threads", "--all"); !strings.Contains(output, "dm/dev+pm") {
+		t.Fatalf("expected --all to list dm, got %q", output)
+	}
+}
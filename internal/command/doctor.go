@@ -0,0 +1,217 @@
+package command
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/daemon"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// threadNameIssue is a thread whose name does not meet the current rules.
+type threadNameIssue struct {
+	GUID    string `json:"guid"`
+	Path    string `json:"path"`
+	Problem string `json:"problem"`
+	Suggest string `json:"suggest,omitempty"`
+}
+
+// NewDoctorCmd creates the doctor command.
+func NewDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Report project data that needs attention",
+		Long: `Check the project for data that predates current validation rules.
+
+Nothing is changed unless --fix is given; use the suggested commands to fix
+what is reported. With no flags every check runs.
+
+Checks:
+  rebuild lock     a .fray/local/rebuild.lock left by an interrupted rebuild
+                   (blocks every other command); --fix clears it and rebuilds
+  daemon lease     a .fray/local/daemon.lease whose daemon is gone (stale
+                   heartbeat or dead pid); --fix removes it
+  --thread-names   threads whose names are not lowercase [a-z0-9:-/.],
+                   use a reserved name (room, main, all), or collide with a
+                   sibling when case is ignored`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// The rebuild lock is checked before opening the database, which
+			// refuses to open while an interrupted rebuild's lock remains.
+			done, err := checkRebuildLock(cmd)
+			if err != nil || done {
+				return err
+			}
+
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			fix, _ := cmd.Flags().GetBool("fix")
+			lease, err := checkDaemonLease(filepath.Dir(ctx.Project.DBPath), fix)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			// Thread names always runs; --thread-names is accepted for scripts.
+			issues, err := findThreadNameIssues(ctx.DB)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"thread_names":  issues,
+					"daemon_lease":  lease,
+					"lease_cleared": lease != nil && fix,
+				})
+			}
+
+			out := cmd.OutOrStdout()
+			if lease != nil {
+				if fix {
+					fmt.Fprintf(out, "Daemon lease: cleared stale lease from pid %d on %s\n", lease.PID, lease.Hostname)
+				} else {
+					fmt.Fprintf(out, "Daemon lease: stale (pid %d on %s, last heartbeat %s ago)\n",
+						lease.PID, lease.Hostname, lease.Age(time.Now()).Round(time.Second))
+					fmt.Fprintln(out, "    fix: fray doctor --fix")
+				}
+			}
+			if len(issues) == 0 {
+				fmt.Fprintln(out, "Thread names: ok")
+				return nil
+			}
+			fmt.Fprintf(out, "Thread names: %d issue(s)\n", len(issues))
+			for _, issue := range issues {
+				fmt.Fprintf(out, "  %s (%s): %s\n", issue.Path, issue.GUID, issue.Problem)
+				if issue.Suggest != "" {
+					fmt.Fprintf(out, "    fix: fray thread rename %s %s\n", issue.GUID, issue.Suggest)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("thread-names", false, "check thread names")
+	cmd.Flags().Bool("fix", false, "clear a stale rebuild lock (and rebuild the cache) or daemon lease")
+	return cmd
+}
+
+// checkRebuildLock reports a rebuild lock left by a dead process, clearing
+// it and rebuilding with --fix. done is true when the remaining checks
+// cannot run because the lock is still in place.
+func checkRebuildLock(cmd *cobra.Command) (done bool, err error) {
+	project, err := core.DiscoverProject("")
+	if err != nil {
+		return true, writeCommandError(cmd, err)
+	}
+	lock, err := db.ReadRebuildLock(project.DBPath)
+	if err != nil {
+		return true, writeCommandError(cmd, err)
+	}
+	if lock == nil || lock.Active() {
+		// A live rebuild is not a problem; opening the database waits for it.
+		return false, nil
+	}
+
+	jsonMode, _ := cmd.Flags().GetBool("json")
+	out := cmd.OutOrStdout()
+	if fix, _ := cmd.Flags().GetBool("fix"); !fix {
+		if jsonMode {
+			return true, json.NewEncoder(out).Encode(map[string]any{"rebuild_lock": lock})
+		}
+		fmt.Fprintf(out, "Rebuild lock: stale (pid %d, started %s); the cache may be half-built\n",
+			lock.PID, time.Unix(lock.StartedAt, 0).Format(time.RFC3339))
+		fmt.Fprintln(out, "    fix: fray doctor --fix")
+		return true, nil
+	}
+
+	if err := db.ClearRebuildLock(project.DBPath); err != nil {
+		return true, writeCommandError(cmd, err)
+	}
+	rebuilt, err := rebuildProjectDatabase(project, db.RebuildOptions{})
+	if err != nil {
+		return true, writeCommandError(cmd, fmt.Errorf("rebuild: %w", err))
+	}
+	rebuilt.Close()
+	if !jsonMode {
+		fmt.Fprintf(out, "Rebuild lock: cleared stale lock from pid %d and rebuilt the cache\n", lock.PID)
+	}
+	return false, nil
+}
+
+// checkDaemonLease returns the project's daemon lease when no daemon holds
+// it any more, removing it when fix is set. A held lease, or none, is nil.
+func checkDaemonLease(frayDir string, fix bool) (*daemon.Lease, error) {
+	lease, err := daemon.ReadLease(frayDir)
+	if err != nil || lease == nil || lease.Held(time.Now()) {
+		return nil, err
+	}
+	if fix {
+		if err := daemon.ClearLease(frayDir); err != nil {
+			return nil, err
+		}
+	}
+	return lease, nil
+}
+
+// findThreadNameIssues lists threads that CreateThread would reject today.
+func findThreadNameIssues(dbConn *sql.DB) ([]threadNameIssue, error) {
+	threads, err := db.GetThreads(dbConn, &types.ThreadQueryOptions{IncludeArchived: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// Group siblings that differ only by case. The conforming one (or the
+	// oldest) is kept; the rest are reported as duplicates of it.
+	key := func(thread types.Thread) string {
+		parent := ""
+		if thread.ParentThread != nil {
+			parent = *thread.ParentThread
+		}
+		return parent + "\x00" + core.NormalizeThreadName(thread.Name)
+	}
+	canonical := make(map[string]types.Thread)
+	for _, thread := range threads {
+		k := key(thread)
+		current, ok := canonical[k]
+		if !ok || (current.Name != core.NormalizeThreadName(current.Name) && thread.Name == core.NormalizeThreadName(thread.Name)) {
+			canonical[k] = thread
+		}
+	}
+
+	issues := []threadNameIssue{}
+	for _, thread := range threads {
+		path, _ := buildThreadPath(dbConn, &thread)
+		if path == "" {
+			path = thread.Name
+		}
+		issue := threadNameIssue{GUID: thread.GUID, Path: path}
+		normalized := core.NormalizeThreadName(thread.Name)
+
+		if err := core.ValidateThreadName(normalized); err != nil {
+			issue.Problem = err.Error()
+			if sanitized, _ := SanitizeThreadName(thread.Name); sanitized != "" && core.ValidateThreadName(sanitized) == nil {
+				issue.Suggest = sanitized
+			}
+		} else if keep := canonical[key(thread)]; keep.GUID != thread.GUID {
+			keepPath, _ := buildThreadPath(dbConn, &keep)
+			issue.Problem = fmt.Sprintf("duplicates %s (%s) when case is ignored", keepPath, keep.GUID)
+		} else if normalized != thread.Name {
+			issue.Problem = "name is not lowercase"
+			issue.Suggest = normalized
+		}
+		if issue.Problem != "" {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
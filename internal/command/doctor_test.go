@@ -0,0 +1,131 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/daemon"
+)
+
+func TestThreadNameRulesAndDoctorReport(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "design")
+	if output := runFray(t, "thread", "Design"); strings.Contains(output, "Created") {
+		t.Fatalf("expected Design to resolve to the existing thread, got %q", output)
+	}
+
+	if output, err := executeCommand(NewRootCmd("test"), "thread", "room"); err == nil {
+		t.Fatalf("expected reserved name to be rejected, got %q", output)
+	}
+
+	runFray(t, "thread", "other")
+	output, err := executeCommand(NewRootCmd("test"), "thread", "rename", "other", "design")
+	if err == nil || !strings.Contains(output, "Use 'fray thread design' instead") {
+		t.Fatalf("expected duplicate suggestion, got %v: %q", err, output)
+	}
+
+	// The command and core agree: punctuation core allows is kept, and '/'
+	// is rejected with the exact charset rather than silently dropped.
+	if output := runFray(t, "thread", "rename", "other", "v1.2"); !strings.Contains(output, "v1.2") {
+		t.Fatalf("expected rename to v1.2, got %q", output)
+	}
+	output, err = executeCommand(NewRootCmd("test"), "thread", "rename", "v1.2", "notes/2024")
+	if ErrorCode(err) != ErrorKindValidation || !strings.Contains(output, "use only lowercase letters, digits, and : . + -") {
+		t.Fatalf("expected '/' to be rejected with the charset, got %v: %q", err, output)
+	}
+
+	// Names from before validation only show up in the doctor report.
+	dbConn := openProjectDB(t, projectDir)
+	for _, stmt := range []string{
+		`INSERT INTO fray_threads (guid, name, status, type, created_at) VALUES ('thrd-old1', 'Design', 'open', 'standard', 2)`,
+		`INSERT INTO fray_threads (guid, name, status, type, created_at) VALUES ('thrd-old2', 'Old Notes', 'open', 'standard', 3)`,
+	} {
+		if _, err := dbConn.Exec(stmt); err != nil {
+			t.Fatalf("insert legacy thread: %v", err)
+		}
+	}
+	_ = dbConn.Close()
+
+	output = runFray(t, "doctor", "--thread-names")
+	if !strings.Contains(output, "Thread names: 2 issue(s)") {
+		t.Fatalf("expected two issues, got %q", output)
+	}
+	if !strings.Contains(output, "Design (thrd-old1): duplicates design (") {
+		t.Fatalf("expected case-insensitive duplicate, got %q", output)
+	}
+	if !strings.Contains(output, "fray thread rename thrd-old2 old-notes") {
+		t.Fatalf("expected rename suggestion for invalid name, got %q", output)
+	}
+}
+
+func TestDoctorClearsInterruptedRebuildLock(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "post", "--as", "alice", "survives the rebuild")
+
+	// Simulate a rebuild killed halfway: tables dropped, lock left behind.
+	dbConn := openProjectDB(t, projectDir)
+	if _, err := dbConn.Exec("DELETE FROM fray_messages"); err != nil {
+		t.Fatalf("clear messages: %v", err)
+	}
+	_ = dbConn.Close()
+	lockPath := filepath.Join(projectDir, ".fray", "local", "rebuild.lock")
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(lockPath, []byte(`{"pid":1073741824,"started_at":1}`), 0o644); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+
+	if output, err := executeCommand(NewRootCmd("test"), "get", "--last", "5"); err == nil || !strings.Contains(output, "fray doctor --fix") {
+		t.Fatalf("expected commands to refuse the half-built cache, got %v: %q", err, output)
+	}
+	if output := runFray(t, "doctor"); !strings.Contains(output, "Rebuild lock: stale") {
+		t.Fatalf("expected doctor to report the stale lock, got %q", output)
+	}
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Fatalf("expected doctor without --fix to leave the lock: %v", err)
+	}
+
+	if output := runFray(t, "doctor", "--fix"); !strings.Contains(output, "cleared stale lock") {
+		t.Fatalf("expected doctor --fix to clear the lock, got %q", output)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the lock to be gone, got %v", err)
+	}
+	if output := runFray(t, "get", "--last", "5"); !strings.Contains(output, "survives the rebuild") {
+		t.Fatalf("expected the rebuilt cache to have the message, got %q", output)
+	}
+}
+
+func TestDoctorClearsStaleDaemonLease(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	frayDir := filepath.Join(projectDir, ".fray")
+	leasePath := daemon.LeasePath(frayDir)
+	if err := os.MkdirAll(filepath.Dir(leasePath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(leasePath, []byte(`{"pid":1073741824,"hostname":"elsewhere","heartbeat":1}`), 0o600); err != nil {
+		t.Fatalf("write lease: %v", err)
+	}
+
+	if output := runFray(t, "daemon", "status"); !strings.Contains(output, "stale lease from pid 1073741824") {
+		t.Fatalf("expected status to read the stale lease, got %q", output)
+	}
+	if output := runFray(t, "doctor"); !strings.Contains(output, "Daemon lease: stale (pid 1073741824 on elsewhere") {
+		t.Fatalf("expected doctor to report the stale lease, got %q", output)
+	}
+	if _, err := os.Stat(leasePath); err != nil {
+		t.Fatalf("expected doctor without --fix to leave the lease: %v", err)
+	}
+	if output := runFray(t, "doctor", "--fix"); !strings.Contains(output, "cleared stale lease") {
+		t.Fatalf("expected doctor --fix to clear the lease, got %q", output)
+	}
+	if _, err := os.Stat(leasePath); !os.IsNotExist(err) {
+		t.Fatalf("expected the lease to be gone, got %v", err)
+	}
+	if output := runFray(t, "daemon", "status"); !strings.Contains(output, "Daemon is not running") {
+		t.Fatalf("expected no daemon after cleanup, got %q", output)
+	}
+}
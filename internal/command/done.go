@@ -0,0 +1,127 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// NewDoneCmd creates the done command.
+func NewDoneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "done",
+		Short: "Report that your session's work is finished",
+		Long: `Post a structured done report and end the session cleanly.
+
+The daemon ends a session as soon as its agent has reported done and gone
+quiet, instead of waiting out the min_checkin timeout. A blocked report also
+mentions the owner of the thread that woke you (or the pm).
+
+Examples:
+  fray done --as dev --summary "auth refactor merged"
+  fray done --as dev --status blocked --summary "need API keys for staging"
+  fray done --as dev --status needs-review --summary "PR #42 ready"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			asRef, _ := cmd.Flags().GetString("as")
+			if asRef == "" {
+				return writeCommandError(cmd, fmt.Errorf("--as is required"))
+			}
+			agentID, err := resolveAgentRef(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			agent, err := db.GetAgent(ctx.DB, agentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if agent == nil {
+				return writeCommandError(cmd, fmt.Errorf("agent not found: @%s", agentID))
+			}
+
+			statusValue, _ := cmd.Flags().GetString("status")
+			status, err := core.ParseDoneStatus(statusValue)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			summary, _ := cmd.Flags().GetString("summary")
+
+			bases, err := db.GetAgentBases(ctx.DB)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			now := time.Now().Unix()
+			created, err := db.CreateMessage(ctx.DB, types.Message{
+				TS:        now,
+				FromAgent: agentID,
+				Body:      core.FormatDoneBody(status, summary),
+				Mentions:  core.ExtractMentions(summary, bases),
+				Type:      types.MessageTypeDone,
+			})
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.AppendMessage(ctx.Project.DBPath, created); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			sessionID := ""
+			if agent.LastSessionID != nil {
+				sessionID = *agent.LastSessionID
+			}
+			report := types.SessionDone{
+				AgentID:   agentID,
+				SessionID: sessionID,
+				Status:    status,
+				MessageID: created.ID,
+				At:        now,
+			}
+			if summary != "" {
+				report.Summary = &summary
+			}
+			if err := db.AppendSessionDone(ctx.Project.DBPath, report); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if err := db.UpdateAgent(ctx.DB, agentID, db.AgentUpdates{
+				LastSeen: types.OptionalInt64{Set: true, Value: &now},
+			}); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"agent_id":   agentID,
+					"status":     status,
+					"summary":    summary,
+					"message_id": created.ID,
+					"session_id": sessionID,
+				})
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "[%s] @%s done: %s\n", created.ID, agentID, status)
+			if summary != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", summary)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent reporting done")
+	cmd.Flags().String("summary", "", "what was accomplished (or what is blocking)")
+	cmd.Flags().String("status", "success", "success, blocked, or needs-review")
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestDonePostsReportAndShowsInSessions(t *testing.T) {
+	projectDir := newFlowProject(t, "dev")
+	runFray(t, "done", "--as", "dev", "--status", "blocked", "--summary", "need staging API keys")
+
+	dbConn := openProjectDB(t, projectDir)
+	done, err := db.GetLatestDoneMessage(dbConn, "dev", 0)
+	_ = dbConn.Close()
+	if err != nil || done == nil {
+		t.Fatalf("expected done message: %v", err)
+	}
+	if done.Type != types.MessageTypeDone || done.Body != "blocked: need staging API keys" {
+		t.Fatalf("unexpected done message: %+v", done)
+	}
+
+	output := runFray(t, "agent", "sessions", "dev")
+	if !strings.Contains(output, "done: blocked") || !strings.Contains(output, "need staging API keys") {
+		t.Fatalf("expected outcome in sessions output, got %q", output)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "done", "--as", "dev", "--status", "finished"); err == nil {
+		t.Fatalf("expected invalid status to fail")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
 	"time"
 
@@ -13,12 +14,89 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// messageEditor edits text interactively and returns the result.
+type messageEditor interface {
+	Edit(initial string) (string, error)
+}
+
+// externalEditor opens $VISUAL or $EDITOR (vi by default) on a temp file,
+// attached to in and out (os.Stdin and os.Stdout when nil).
+type externalEditor struct {
+	in, out *os.File
+}
+
+func (e externalEditor) Edit(initial string) (string, error) {
+	in, out := e.in, e.out
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+	// Without a terminal (agents, pipes) the editor would wait forever.
+	if !isTTY(in) || !isTTY(out) {
+		return "", validationError("no terminal for $EDITOR; pass the new content as an argument, or - to read it from stdin")
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	file, err := os.CreateTemp("", "fray-edit-*.md")
+	if err != nil {
+		return "", err
+	}
+	path := file.Name()
+	defer os.Remove(path)
+	if _, err := file.WriteString(initial); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	// Run through the shell so EDITOR may carry arguments ("code --wait").
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin = in
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(edited), nil
+}
+
+// interactiveEditor is swapped out in tests.
+var interactiveEditor messageEditor = externalEditor{}
+
 // NewEditCmd creates the edit command.
 func NewEditCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "edit <msgid> <new-content> [flags]",
+		Use:   "edit <msgid> [new-content] [flags]",
 		Short: "Edit a message you posted",
-		Args:  cobra.MinimumNArgs(2),
+		Long: `Edit a message you posted. Without new content, opens $VISUAL or $EDITOR
+pre-filled with the current body; saving it unchanged aborts the edit.
+The editor needs a terminal; elsewhere pass the content as an argument, or
+"-" to read it from stdin.
+Mentions are re-extracted from the new body.
+
+Humans may edit another agent's message with --force.
+
+Examples:
+  fray edit msg-abc "fixed typo" --as dev
+  fray edit msg-abc --as dev --reason "clarify scope"
+  echo "fixed typo" | fray edit msg-abc - --as dev`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, err := GetContext(cmd)
 			if err != nil {
@@ -41,6 +119,9 @@ func NewEditCmd() *cobra.Command {
 			}
 
 			reason, _ := cmd.Flags().GetString("message")
+			if alias, _ := cmd.Flags().GetString("reason"); alias != "" {
+				reason = alias
+			}
 			if isAgentEnv && strings.TrimSpace(reason) == "" {
 				return writeCommandError(cmd, fmt.Errorf("-m (reason) is required for agents"))
 			}
@@ -50,10 +131,45 @@ func NewEditCmd() *cobra.Command {
 				return writeCommandError(cmd, err)
 			}
 
+			if err := authorizeMessageEdit(ctx, msg, agentID); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
 			msgID := msg.ID
-			newBody := strings.Join(args[1:], " ")
+			var newBody string
+			if len(args) == 2 && args[1] == stdinBodyArg {
+				newBody, err = readBodyInput(cmd.InOrStdin(), args[1], true, "")
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+			} else if len(args) > 1 {
+				newBody = strings.Join(args[1:], " ")
+			} else {
+				edited, err := interactiveEditor.Edit(msg.Body)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				newBody = strings.TrimRight(edited, "\n")
+			}
+			if newBody == msg.Body {
+				if ctx.JSONMode {
+					return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{"id": msgID, "edited": false})
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "No changes; edit aborted")
+				return nil
+			}
+			if strings.TrimSpace(newBody) == "" {
+				return writeCommandError(cmd, validationError("empty message; edit aborted"))
+			}
+			if err := checkMessageContent(cmd, ctx, newBody); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
-			if err := db.EditMessage(ctx.DB, msgID, newBody, agentID); err != nil {
+			mentions, err := extractEditMentions(ctx, msg, newBody)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.ReplaceMessageBody(ctx.DB, msgID, newBody, mentions); err != nil {
 				return writeCommandError(cmd, err)
 			}
 
@@ -71,6 +187,9 @@ func NewEditCmd() *cobra.Command {
 			}
 			body := updated.Body
 			update.Body = &body
+			update.Mentions = &updated.Mentions
+			threadRefs := append([]string{}, updated.ThreadRefs...)
+			update.ThreadRefs = &threadRefs
 			if err := db.AppendMessageUpdate(ctx.Project.DBPath, update); err != nil {
 				return writeCommandError(cmd, err)
 			}
@@ -118,6 +237,48 @@ func NewEditCmd() *cobra.Command {
 
 	cmd.Flags().String("as", "", "agent identity (uses FRAY_AGENT_ID if not set)")
 	cmd.Flags().StringP("message", "m", "", "optional reason for the edit")
+	cmd.Flags().String("reason", "", "alias for --message")
+	cmd.Flags().Bool("allow-secrets", false, "save even if the content policy flags a secret")
 
 	return cmd
 }
+
+// authorizeMessageEdit allows the author to edit their message, and a human
+// to edit anyone's message with --force.
+func authorizeMessageEdit(ctx *CommandContext, msg *types.Message, agentID string) error {
+	if msg.FromAgent == agentID {
+		return nil
+	}
+	if !isHumanActor(ctx, agentID) {
+		return validationError("cannot edit message from another agent (message from @%s)", msg.FromAgent)
+	}
+	if !ctx.Force {
+		return validationError("message is from @%s; use --force to edit it as @%s", msg.FromAgent, agentID)
+	}
+	return nil
+}
+
+// extractEditMentions re-extracts mentions from an edited body the way post
+// does, including users and expanding @all outside meta and notes threads.
+func extractEditMentions(ctx *CommandContext, msg *types.Message, body string) ([]string, error) {
+	bases, err := db.GetAgentBases(ctx.DB)
+	if err != nil {
+		return nil, err
+	}
+	users, _ := db.GetActiveUsers(ctx.DB, ctx.Project.DBPath)
+	for _, u := range users {
+		bases[u] = struct{}{}
+	}
+	mentions := core.ExtractMentions(body, bases)
+
+	var thread *types.Thread
+	if msg.Home != "" && msg.Home != "room" {
+		if thread, err = db.GetThread(ctx.DB, msg.Home); err != nil {
+			return nil, err
+		}
+	}
+	if !threadExcludesBroadcast(thread) {
+		mentions = core.ExpandAllMention(mentions, bases)
+	}
+	return mentions, nil
+}
@@ -0,0 +1,156 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+)
+
+// fakeEditor returns a canned body and records what it was given.
+type fakeEditor struct {
+	result  string
+	initial []string
+}
+
+func (f *fakeEditor) Edit(initial string) (string, error) {
+	f.initial = append(f.initial, initial)
+	return f.result, nil
+}
+
+func useEditor(t *testing.T, editor messageEditor) {
+	t.Helper()
+	previous := interactiveEditor
+	interactiveEditor = editor
+	t.Cleanup(func() { interactiveEditor = previous })
+}
+
+func TestEditInteractiveUnchangedAborts(t *testing.T) {
+	projectDir := newFlowProject(t, "dev")
+	posted := postJSON(t, "post", "--as", "dev", "ship it")
+	msgID := posted["id"].(string)
+
+	editor := &fakeEditor{result: "ship it\n"}
+	useEditor(t, editor)
+
+	output := runFray(t, "edit", msgID, "--as", "dev")
+	if !strings.Contains(output, "edit aborted") {
+		t.Fatalf("expected abort, got %q", output)
+	}
+	if len(editor.initial) != 1 || editor.initial[0] != "ship it" {
+		t.Fatalf("expected editor pre-filled with the body, got %q", editor.initial)
+	}
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	msg, err := db.GetMessage(dbConn, msgID)
+	if err != nil || msg == nil {
+		t.Fatalf("get message: %v", err)
+	}
+	if msg.EditedAt != nil {
+		t.Fatalf("expected no edit recorded, got edited_at %d", *msg.EditedAt)
+	}
+}
+
+func TestEditReadsBodyFromStdin(t *testing.T) {
+	projectDir := newFlowProject(t, "dev")
+	posted := postJSON(t, "post", "--as", "dev", "ship it")
+	msgID := posted["id"].(string)
+
+	editor := &fakeEditor{result: "unused"}
+	useEditor(t, editor)
+	if _, err := runFrayWithInput(t, "ship it tomorrow\n", "edit", msgID, "-", "--as", "dev"); err != nil {
+		t.Fatalf("edit from stdin: %v", err)
+	}
+	if len(editor.initial) != 0 {
+		t.Fatalf("expected no editor for stdin edit")
+	}
+
+	dbConn := openProjectDB(t, projectDir)
+	msg, err := db.GetMessage(dbConn, msgID)
+	dbConn.Close()
+	if err != nil || msg == nil {
+		t.Fatalf("get message: %v", err)
+	}
+	if msg.Body != "ship it tomorrow" {
+		t.Fatalf("expected body from stdin, got %q", msg.Body)
+	}
+}
+
+func TestExternalEditorRequiresTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	// An editor that would succeed must not be launched without a terminal.
+	t.Setenv("VISUAL", "true")
+
+	_, err = externalEditor{in: r, out: w}.Edit("ship it")
+	if err == nil || !strings.Contains(err.Error(), "no terminal") {
+		t.Fatalf("expected terminal error, got %v", err)
+	}
+}
+
+func TestEditReextractsMentions(t *testing.T) {
+	projectDir := newFlowProject(t, "dev", "pm", "qa")
+	posted := postJSON(t, "post", "--as", "dev", "@pm please review")
+	msgID := posted["id"].(string)
+
+	useEditor(t, &fakeEditor{result: "@qa please review\n"})
+	runFray(t, "edit", msgID, "--as", "dev", "--reason", "wrong reviewer")
+
+	dbConn := openProjectDB(t, projectDir)
+	msg, err := db.GetMessage(dbConn, msgID)
+	dbConn.Close()
+	if err != nil || msg == nil {
+		t.Fatalf("get message: %v", err)
+	}
+	if msg.Body != "@qa please review" || strings.Join(msg.Mentions, ",") != "qa" {
+		t.Fatalf("expected body and mentions updated, got %q %v", msg.Body, msg.Mentions)
+	}
+
+	// The new mentions survive a rebuild from JSONL.
+	records, err := db.ReadMessages(filepath.Join(projectDir, ".fray"))
+	if err != nil {
+		t.Fatalf("read messages: %v", err)
+	}
+	found := false
+	for _, record := range records {
+		if record.ID != msgID {
+			continue
+		}
+		found = true
+		if strings.Join(record.Mentions, ",") != "qa" {
+			t.Fatalf("expected JSONL mentions updated, got %v", record.Mentions)
+		}
+	}
+	if !found {
+		t.Fatalf("message %s not in JSONL", msgID)
+	}
+}
+
+func TestEditOtherAgentsMessage(t *testing.T) {
+	newFlowProject(t, "dev", "pm")
+	runFray(t, "config", "username", "adam")
+	posted := postJSON(t, "post", "--as", "dev", "teh plan")
+	msgID := posted["id"].(string)
+
+	editor := &fakeEditor{result: "the plan"}
+	useEditor(t, editor)
+
+	if _, err := executeCommand(NewRootCmd("test"), "edit", msgID, "--as", "pm", "--force"); err == nil {
+		t.Fatal("expected an agent to be refused editing another agent's message")
+	}
+	output, err := executeCommand(NewRootCmd("test"), "edit", msgID, "--as", "adam")
+	if err == nil || !strings.Contains(output, "--force") {
+		t.Fatalf("expected a human to need --force, got %v %q", err, output)
+	}
+	if len(editor.initial) != 0 {
+		t.Fatalf("expected refused edits not to open the editor, got %d", len(editor.initial))
+	}
+	runFray(t, "edit", msgID, "--as", "adam", "--force")
+}
@@ -1,13 +1,129 @@
 package command
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
 	"github.com/spf13/cobra"
 )
 
+// ErrorKind classifies a command failure for scripts: it picks the exit code
+// and is the "code" field of JSON error output.
+type ErrorKind string
+
+const (
+	ErrorKindUnknown     ErrorKind = "error"
+	ErrorKindNotFound    ErrorKind = "not_found"   // a thread, message, agent, ... does not exist
+	ErrorKindValidation  ErrorKind = "validation"  // bad arguments or flags
+	ErrorKindConflict    ErrorKind = "conflict"    // the target is taken or already exists
+	ErrorKindUnavailable ErrorKind = "unavailable" // the project or database cannot be used right now
+)
+
+// Exit codes per ErrorKind. Unclassified errors exit 1.
+const (
+	ExitError       = 1
+	ExitNotFound    = 2
+	ExitValidation  = 3
+	ExitConflict    = 4
+	ExitUnavailable = 5
+)
+
+// CommandError attaches an ErrorKind to an error.
+type CommandError struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *CommandError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+func notFoundError(format string, args ...any) error {
+	return &CommandError{Kind: ErrorKindNotFound, Err: fmt.Errorf(format, args...)}
+}
+
+func validationError(format string, args ...any) error {
+	return &CommandError{Kind: ErrorKindValidation, Err: fmt.Errorf(format, args...)}
+}
+
+func conflictError(format string, args ...any) error {
+	return &CommandError{Kind: ErrorKindConflict, Err: fmt.Errorf(format, args...)}
+}
+
+// unavailableError marks err as an unavailable project or database. A nil err
+// stays nil.
+func unavailableError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CommandError{Kind: ErrorKindUnavailable, Err: err}
+}
+
+// ErrorCode classifies err. Typed command errors win; known db errors are
+// mapped; anything else is ErrorKindUnknown.
+func ErrorCode(err error) ErrorKind {
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Kind
+	}
+	var threadTaken *db.ThreadNameTakenError
+	var claimTaken *db.ClaimTakenError
+	if errors.As(err, &threadTaken) || errors.As(err, &claimTaken) {
+		return ErrorKindConflict
+	}
+	var blocked *core.BlockedContentError
+	if errors.As(err, &blocked) {
+		return ErrorKindValidation
+	}
+	if err != nil && strings.Contains(err.Error(), "database is locked") {
+		return ErrorKindUnavailable
+	}
+	return ErrorKindUnknown
+}
+
+// ExitCode returns the process exit code for err: 0 for nil, otherwise the
+// code documented for its ErrorKind.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	switch ErrorCode(err) {
+	case ErrorKindNotFound:
+		return ExitNotFound
+	case ErrorKindValidation:
+		return ExitValidation
+	case ErrorKindConflict:
+		return ExitConflict
+	case ErrorKindUnavailable:
+		return ExitUnavailable
+	default:
+		return ExitError
+	}
+}
+
+// writeCommandError prints err to stderr, as JSON under --json, and returns it
+// marked as reported.
 func writeCommandError(cmd *cobra.Command, err error) error {
+	if err == nil || IsReported(err) {
+		return err
+	}
+	if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode {
+		_ = json.NewEncoder(cmd.ErrOrStderr()).Encode(map[string]any{
+			"error":     err.Error(),
+			"code":      ErrorCode(err),
+			"exit_code": ExitCode(err),
+		})
+		return &reportedError{err: err}
+	}
+
 	fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s\n", err.Error())
 
 	// Check for schema errors and suggest rebuild
@@ -15,7 +131,27 @@ func writeCommandError(cmd *cobra.Command, err error) error {
 		fmt.Fprintln(cmd.ErrOrStderr(), "Hint: This looks like a schema mismatch. Try: fray rebuild")
 	}
 
-	return err
+	return &reportedError{err: err}
+}
+
+// reportedError marks an error writeCommandError has already printed.
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
+
+// IsReported reports whether err was already written to stderr, so main
+// should only set the exit code.
+func IsReported(err error) bool {
+	var reported *reportedError
+	return errors.As(err, &reported)
 }
 
 // isSchemaError checks if an error is a SQLite schema mismatch.
@@ -0,0 +1,85 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/testutil"
+)
+
+func TestCommandExitCodes(t *testing.T) {
+	newFlowProject(t, "alice", "bob")
+	runFray(t, "claim", "@alice", "--file", "src/main.go")
+
+	cases := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"not found thread", []string{"thread", "rename", "nosuch", "other"}, ExitNotFound},
+		{"not found message", []string{"claim", "@alice", "--file", "a.go", "--msg", "msg-zzzzzzzz"}, ExitNotFound},
+		{"validation", []string{"claim", "@alice"}, ExitValidation},
+		{"validation flag", []string{"post", "--as", "alice", "hi", "--no-such-flag"}, ExitValidation},
+		{"conflict", []string{"claim", "@bob", "--file", "src/main.go"}, ExitConflict},
+	}
+	for _, tc := range cases {
+		output, err := executeCommand(NewRootCmd("test"), tc.args...)
+		if got := ExitCode(err); got != tc.want {
+			t.Fatalf("%s: exit code %d, want %d (%v, %q)", tc.name, got, tc.want, err, output)
+		}
+	}
+}
+
+func TestCommandExitCodeUnavailable(t *testing.T) {
+	testutil.IsolateHome(t)
+	t.Setenv("FRAY_PROJECT_ROOT", "")
+	t.Chdir(t.TempDir())
+
+	_, err := executeCommand(NewRootCmd("test"), "get", "--last", "5")
+	if got := ExitCode(err); got != ExitUnavailable {
+		t.Fatalf("exit code %d, want %d (%v)", got, ExitUnavailable, err)
+	}
+}
+
+func TestCommandJSONErrorCode(t *testing.T) {
+	newFlowProject(t, "alice")
+
+	output, err := executeCommand(NewRootCmd("test"), "--json", "thread", "rename", "nosuch", "other")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	var payload struct {
+		Error    string    `json:"error"`
+		Code     ErrorKind `json:"code"`
+		ExitCode int       `json:"exit_code"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode error output: %v (%q)", err, output)
+	}
+	if payload.Code != ErrorKindNotFound || payload.ExitCode != ExitNotFound || !strings.Contains(payload.Error, "nosuch") {
+		t.Fatalf("unexpected error payload: %+v", payload)
+	}
+	// main must not print the error again after the JSON payload.
+	if !IsReported(err) || ExitCode(err) != ExitNotFound {
+		t.Fatalf("expected a reported not-found error, got %v", err)
+	}
+	if IsReported(errors.New("boom")) {
+		t.Fatal("expected a plain error not to count as reported")
+	}
+}
+
+func TestExitCodeUnknownAndWrapped(t *testing.T) {
+	if got := ExitCode(nil); got != 0 {
+		t.Fatalf("nil error exit code %d", got)
+	}
+	if got := ExitCode(errors.New("boom")); got != ExitError {
+		t.Fatalf("unclassified error exit code %d, want %d", got, ExitError)
+	}
+	wrapped := fmt.Errorf("context: %w", notFoundError("thread not found: x"))
+	if got := ExitCode(wrapped); got != ExitNotFound {
+		t.Fatalf("wrapped not-found exit code %d, want %d", got, ExitNotFound)
+	}
+}
@@ -0,0 +1,298 @@
+package command
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// exportCSVHeader is the column order of fray export --format csv.
+var exportCSVHeader = []string{"id", "ts", "from", "type", "home", "reply_to", "mentions", "reactions", "body"}
+
+// exportFormats lists the formats fray export writes.
+var exportFormats = []string{"csv", "json", "markdown"}
+
+// exportRow is one exported message, the same fields in every format.
+type exportRow struct {
+	ID        string   `json:"id"`
+	TS        string   `json:"ts"`
+	From      string   `json:"from"`
+	Type      string   `json:"type"`
+	Home      string   `json:"home"`
+	ReplyTo   string   `json:"reply_to,omitempty"`
+	Mentions  []string `json:"mentions"`
+	Reactions int      `json:"reactions"`
+	Body      string   `json:"body"`
+}
+
+// exportWriter streams rows in one export format.
+type exportWriter interface {
+	begin() error
+	row(exportRow) error
+	end() error
+}
+
+// NewExportCmd creates the export command.
+func NewExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export messages for analysis elsewhere",
+		Long: `Export messages, oldest first, for spreadsheets and other tools.
+
+Every format carries the same fields: id, ts (ISO 8601, UTC), from, type,
+home (room or the thread path), reply_to, mentions, reactions (count), and
+body. Archived messages are left out.
+
+  csv       one row per message; mentions are semicolon-joined and fields
+            are quoted per RFC 4180, so commas, quotes, and newlines in
+            bodies survive the round trip
+  json      an array of message objects
+  markdown  a list for docs, with each body quoted under its message
+
+Examples:
+  fray export --format csv --out messages.csv
+  fray export --format csv --thread design --since 30d > design.csv
+  fray export --format json --since 30d --until 7d --by alice --not-by alice.1
+  fray export --format markdown --thread design > design.md`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			format, _ := cmd.Flags().GetString("format")
+			if !containsString(exportFormats, format) {
+				return writeCommandError(cmd, validationError("unsupported export format %q (supported: %s)", format, strings.Join(exportFormats, ", ")))
+			}
+
+			options := types.MessageQueryOptions{}
+			since, _ := cmd.Flags().GetString("since")
+			until, _ := cmd.Flags().GetString("until")
+			if err := applyTimeBounds(ctx.DB, since, until, &options); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			options.FromAgents, options.ExcludeFromAgents = authorFilterFlags(cmd, ctx)
+			if threadRef, _ := cmd.Flags().GetString("thread"); threadRef != "" {
+				thread, err := resolveThreadRef(ctx.DB, threadRef)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				options.Home = &thread.GUID
+			}
+
+			outPath, _ := cmd.Flags().GetString("out")
+			var w io.Writer = cmd.OutOrStdout()
+			if outPath != "" && outPath != "-" {
+				file, err := os.Create(outPath)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				defer file.Close()
+				w = file
+			}
+
+			count, err := exportMessages(ctx.DB, &options, newExportWriter(format, w))
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if outPath == "" || outPath == "-" {
+				return nil
+			}
+
+			out := cmd.OutOrStdout()
+			if ctx.JSONMode {
+				return json.NewEncoder(out).Encode(map[string]any{"format": format, "out": outPath, "count": count})
+			}
+			fmt.Fprintf(out, "Exported %d message(s) to %s\n", count, outPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("format", "csv", "export format (csv, json, markdown)")
+	cmd.Flags().String("out", "", "file to write (default: stdout)")
+	cmd.Flags().String("thread", "", "only messages in this thread")
+	cmd.Flags().String("since", "", "only messages after this time (24h, 30d, RFC3339, or a message GUID)")
+	cmd.Flags().String("until", "", "only messages at or before this time (24h, 30d, RFC3339, or a message GUID)")
+	cmd.Flags().StringArray("by", nil, "only messages by this agent or its subagents (repeatable)")
+	cmd.Flags().StringArray("not-by", nil, "drop messages by this agent or its subagents (repeatable, wins over --by)")
+	return cmd
+}
+
+// exportMessages streams matching messages to w and returns how many were
+// written.
+func exportMessages(dbConn *sql.DB, options *types.MessageQueryOptions, w exportWriter) (int, error) {
+	if err := w.begin(); err != nil {
+		return 0, err
+	}
+
+	homes := map[string]string{}
+	count := 0
+	err := db.StreamMessages(dbConn, options, func(msg types.Message, reactionCount int) error {
+		home, ok := homes[msg.Home]
+		if !ok {
+			var err error
+			home, err = resolveHomeName(dbConn, msg.Home)
+			if err != nil {
+				return err
+			}
+			homes[msg.Home] = home
+		}
+		count++
+		return w.row(exportRow{
+			ID:        msg.ID,
+			TS:        time.Unix(msg.TS, 0).UTC().Format(time.RFC3339),
+			From:      msg.FromAgent,
+			Type:      string(msg.Type),
+			Home:      home,
+			ReplyTo:   normalizeOptionalValue(msg.ReplyTo),
+			Mentions:  append([]string{}, msg.Mentions...),
+			Reactions: reactionCount,
+			Body:      msg.Body,
+		})
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, w.end()
+}
+
+// newExportWriter returns the writer for a validated format.
+func newExportWriter(format string, w io.Writer) exportWriter {
+	switch format {
+	case "json":
+		return &jsonExport{w: w}
+	case "markdown":
+		return &markdownExport{w: w}
+	default:
+		return &csvExport{w: csv.NewWriter(w)}
+	}
+}
+
+type csvExport struct {
+	w *csv.Writer
+}
+
+func (e *csvExport) begin() error {
+	return e.w.Write(exportCSVHeader)
+}
+
+func (e *csvExport) row(r exportRow) error {
+	return e.w.Write([]string{
+		r.ID,
+		r.TS,
+		r.From,
+		r.Type,
+		r.Home,
+		r.ReplyTo,
+		strings.Join(r.Mentions, ";"),
+		strconv.Itoa(r.Reactions),
+		r.Body,
+	})
+}
+
+func (e *csvExport) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonExport writes an array one element at a time, so large exports are
+// never held in memory.
+type jsonExport struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonExport) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonExport) row(r exportRow) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if e.count == 0 {
+		sep = "\n  "
+	}
+	e.count++
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExport) end() error {
+	closing := "]\n"
+	if e.count > 0 {
+		closing = "\n]\n"
+	}
+	_, err := io.WriteString(e.w, closing)
+	return err
+}
+
+// markdownExport writes a list like fray pinboard --markdown, with each
+// body quoted under its message.
+type markdownExport struct {
+	w     io.Writer
+	count int
+}
+
+func (e *markdownExport) begin() error {
+	_, err := io.WriteString(e.w, "# Messages\n")
+	return err
+}
+
+func (e *markdownExport) row(r exportRow) error {
+	e.count++
+	header := fmt.Sprintf("\n- **@%s** · %s · %s · `%s`", r.From, r.Home, r.TS, r.ID)
+	if r.ReplyTo != "" {
+		header += fmt.Sprintf(" · reply to `%s`", r.ReplyTo)
+	}
+	if r.Reactions > 0 {
+		header += fmt.Sprintf(" · %d reaction(s)", r.Reactions)
+	}
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteByte('\n')
+	for _, line := range strings.Split(strings.TrimSpace(r.Body), "\n") {
+		fmt.Fprintf(&b, "  > %s\n", line)
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *markdownExport) end() error {
+	if e.count > 0 {
+		return nil
+	}
+	_, err := io.WriteString(e.w, "\n_No messages._\n")
+	return err
+}
+
+// resolveHomeName resolves a message home to the name shown to people:
+// thread homes become their path, anything else is kept as-is.
+func resolveHomeName(dbConn *sql.DB, home string) (string, error) {
+	if !strings.HasPrefix(home, "thrd-") {
+		return home, nil
+	}
+	thread, err := db.GetThread(dbConn, home)
+	if err != nil || thread == nil {
+		return home, err
+	}
+	return buildThreadPath(dbConn, thread)
+}
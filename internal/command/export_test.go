@@ -0,0 +1,175 @@
+package command
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportCSVEscapesBodies(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "design")
+
+	tricky := "she said \"ship it\", then left\nsecond line, with comma"
+	root := postJSON(t, "post", "--as", "alice", "--thread", "design", "@bob "+tricky)
+	rootID := root["id"].(string)
+	reply := postJSON(t, "post", "--as", "bob", "--thread", "design", "--reply-to", rootID, "agreed, shipping it after the review lands")
+	runFray(t, "react", "🚀", rootID, "--as", "bob")
+	runFray(t, "post", "--as", "bob", "room chatter")
+
+	outPath := filepath.Join(projectDir, "messages.csv")
+	output := runFray(t, "export", "--format", "csv", "--out", outPath, "--thread", "design")
+	if !strings.Contains(output, "Exported 2 message(s)") {
+		t.Fatalf("unexpected export output %q", output)
+	}
+
+	file, err := os.Open(outPath)
+	if err != nil {
+		t.Fatalf("open export: %v", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("parse export: %v", err)
+	}
+	if len(rows) != 3 || !reflect.DeepEqual(rows[0], exportCSVHeader) {
+		t.Fatalf("expected header and two rows, got %q", rows)
+	}
+
+	byID := map[string][]string{}
+	for _, row := range rows[1:] {
+		byID[row[0]] = row
+	}
+	first := byID[rootID]
+	if first == nil || first[2] != "alice" || first[4] != "design" || first[6] != "bob" || first[7] != "1" {
+		t.Fatalf("unexpected first row %q", first)
+	}
+	if first[8] != "@bob "+tricky {
+		t.Fatalf("body did not survive the round trip: %q", first[8])
+	}
+	if _, err := time.Parse(time.RFC3339, first[1]); err != nil {
+		t.Fatalf("expected an ISO timestamp, got %q", first[1])
+	}
+	second := byID[reply["id"].(string)]
+	if second == nil || second[5] != rootID || second[7] != "0" {
+		t.Fatalf("unexpected reply row %q", second)
+	}
+}
+
+func TestExportCSVSinceFilter(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	old := postJSON(t, "post", "--as", "alice", "from last quarter")
+	recent := postJSON(t, "post", "--as", "alice", "from this week")
+
+	conn := openProjectDB(t, projectDir)
+	if _, err := conn.Exec("UPDATE fray_messages SET ts = ? WHERE guid = ?", time.Now().AddDate(0, 0, -90).Unix(), old["id"]); err != nil {
+		t.Fatalf("backdate message: %v", err)
+	}
+	_ = conn.Close()
+
+	output := runFray(t, "export", "--since", "30d")
+	rows, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("parse export %q: %v", output, err)
+	}
+	ids := map[string]bool{}
+	for _, row := range rows[1:] {
+		ids[row[0]] = true
+	}
+	if ids[old["id"].(string)] || !ids[recent["id"].(string)] {
+		t.Fatalf("expected only messages from the last 30 days, got %q", rows)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "export", "--format", "xlsx"); err == nil {
+		t.Fatal("expected an unsupported format to fail")
+	}
+}
+
+func TestExportUntilAndAuthorFilters(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	old := postJSON(t, "post", "--as", "alice", "from last quarter")["id"].(string)
+	recent := postJSON(t, "post", "--as", "alice", "from this week")["id"].(string)
+	fromBob := postJSON(t, "post", "--as", "bob", "bob this week")["id"].(string)
+
+	conn := openProjectDB(t, projectDir)
+	if _, err := conn.Exec("UPDATE fray_messages SET ts = ? WHERE guid = ?", time.Now().AddDate(0, 0, -90).Unix(), old); err != nil {
+		t.Fatalf("backdate message: %v", err)
+	}
+	_ = conn.Close()
+
+	exported := func(args ...string) map[string]bool {
+		t.Helper()
+		output := runFray(t, append([]string{"export", "--format", "json"}, args...)...)
+		var rows []exportRow
+		if err := json.Unmarshal([]byte(output), &rows); err != nil {
+			t.Fatalf("parse json export %q: %v", output, err)
+		}
+		ids := map[string]bool{}
+		for _, row := range rows {
+			ids[row.ID] = true
+		}
+		return ids
+	}
+
+	if ids := exported("--until", "30d"); !ids[old] || ids[recent] || ids[fromBob] {
+		t.Fatalf("expected only the backdated message before 30d ago, got %v", ids)
+	}
+	if ids := exported("--by", "alice"); !ids[old] || !ids[recent] || ids[fromBob] {
+		t.Fatalf("expected only alice's messages, got %v", ids)
+	}
+	if ids := exported("--since", "30d", "--not-by", "alice"); ids[old] || ids[recent] || !ids[fromBob] {
+		t.Fatalf("expected only bob's recent message, got %v", ids)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "export", "--since", "1d", "--until", "30d"); err == nil {
+		t.Fatal("expected --since after --until to fail")
+	}
+}
+
+func TestExportJSONAndMarkdown(t *testing.T) {
+	newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "design")
+	root := postJSON(t, "post", "--as", "alice", "--thread", "design", "@bob \"quoted\"\nsecond line")
+	rootID := root["id"].(string)
+	reply := postJSON(t, "post", "--as", "bob", "--thread", "design", "--reply-to", rootID, "taking this one after the review lands")
+
+	output := runFray(t, "export", "--format", "json", "--thread", "design")
+	var rows []exportRow
+	if err := json.Unmarshal([]byte(output), &rows); err != nil {
+		t.Fatalf("parse json export %q: %v", output, err)
+	}
+	byID := map[string]exportRow{}
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+	first, second := byID[rootID], byID[reply["id"].(string)]
+	if len(rows) != 2 || first.Home != "design" || first.Body != "@bob \"quoted\"\nsecond line" {
+		t.Fatalf("unexpected json rows %+v", rows)
+	}
+	if !reflect.DeepEqual(first.Mentions, []string{"bob"}) || second.ReplyTo != rootID {
+		t.Fatalf("unexpected json fields %+v", rows)
+	}
+
+	output = runFray(t, "export", "--format", "markdown", "--thread", "design")
+	for _, want := range []string{
+		"# Messages",
+		"- **@alice** · design · ",
+		"  > second line",
+		"`" + reply["id"].(string) + "` · reply to `" + rootID + "`",
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in markdown export:\n%s", want, output)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	output = runFray(t, "export", "--format", "json", "--thread", "design", "--since", future)
+	if strings.TrimSpace(output) != "[]" {
+		t.Fatalf("expected an empty array, got %q", output)
+	}
+}
@@ -12,8 +12,9 @@ import (
 // NewFilterCmd creates the filter command.
 func NewFilterCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "filter",
-		Short: "Manage message filter preferences",
+		Use:         "filter",
+		Short:       "Manage message filter preferences",
+		Annotations: map[string]string{noJSONAnnotation: "true"},
 	}
 
 	cmd.AddCommand(newFilterSetCmd())
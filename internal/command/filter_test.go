@@ -1,31 +1,18 @@
 package command
 
 import (
-	"os"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
 )
 
 func TestFilterCommandLifecycle(t *testing.T) {
-	projectDir := t.TempDir()
-	project, err := core.InitProject(projectDir, false)
-	if err != nil {
-		t.Fatalf("init project: %v", err)
-	}
-
-	dbConn, err := db.OpenDatabase(project)
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-	if err := db.InitSchema(dbConn); err != nil {
-		_ = dbConn.Close()
-		t.Fatalf("init schema: %v", err)
-	}
-	_ = dbConn.Close()
+	p := testutil.NewProject(t)
+	p.OpenDB(t, db.OpenDatabase, db.InitSchema)
+	project := p.Project
 
 	if _, err := db.UpdateProjectConfig(project.DBPath, db.ProjectConfig{
 		Version:     1,
@@ -36,16 +23,7 @@ func TestFilterCommandLifecycle(t *testing.T) {
 		t.Fatalf("update config: %v", err)
 	}
 
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("getwd: %v", err)
-	}
-	if err := os.Chdir(projectDir); err != nil {
-		t.Fatalf("chdir: %v", err)
-	}
-	t.Cleanup(func() {
-		_ = os.Chdir(cwd)
-	})
+	testutil.Chdir(t, p.Root)
 
 	t.Setenv("FRAY_AGENT_ID", "alice")
 
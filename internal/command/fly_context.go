@@ -0,0 +1,115 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// NewFlyContextCmd creates the fly-context command.
+func NewFlyContextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fly-context",
+		Short: "Session readiness context for an agent in one document",
+		Long: `Bundle what an agent needs at session start into one document:
+
+  - handoff: the latest message in meta/<agent>/notes
+  - meta: the project meta thread's anchor and last 5 messages
+  - unread_mentions: mentions past the agent's mentions watermark
+  - open_questions: open questions addressed to the agent
+  - nearby_claims: other agents' file claims overlapping paths the agent
+    has claimed before
+  - focus_thread: the thread of the agent's most recent post
+
+The daemon embeds the same document in fresh-session wake prompts.
+
+Examples:
+  fray fly-context --as dev
+  fray fly-context --as dev --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			asRef, _ := cmd.Flags().GetString("as")
+			if asRef == "" {
+				return writeCommandError(cmd, fmt.Errorf("--as is required"))
+			}
+			agentID, err := resolveAgentRef(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			fly, err := db.GetFlyContext(ctx.DB, ctx.Project.DBPath, agentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(fly)
+			}
+			printFlyContext(cmd.OutOrStdout(), fly)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent to build the context for (required)")
+
+	return cmd
+}
+
+func printFlyContext(out io.Writer, fly *db.FlyContext) {
+	fmt.Fprintf(out, "Fly context for @%s\n", fly.AgentID)
+
+	fmt.Fprintln(out, "\nHandoff:")
+	if fly.Handoff == nil {
+		fmt.Fprintln(out, "  (none)")
+	} else {
+		printFlyMessage(out, *fly.Handoff)
+	}
+
+	fmt.Fprintln(out, "\nMeta:")
+	if fly.Meta == nil {
+		fmt.Fprintln(out, "  (no meta thread)")
+	} else {
+		if fly.Meta.Anchor != nil {
+			fmt.Fprintf(out, "  anchor: %s\n", truncateBody(fly.Meta.Anchor.Body, 100))
+		}
+		for _, msg := range fly.Meta.Recent {
+			printFlyMessage(out, msg)
+		}
+	}
+
+	fmt.Fprintf(out, "\nUnread mentions (%d):\n", len(fly.UnreadMentions))
+	for _, msg := range fly.UnreadMentions {
+		printFlyMessage(out, msg)
+	}
+
+	fmt.Fprintf(out, "\nOpen questions (%d):\n", len(fly.OpenQuestions))
+	for _, question := range fly.OpenQuestions {
+		fmt.Fprintf(out, "  [%s] @%s: %s\n", question.GUID, question.FromAgent, truncateBody(question.Re, 80))
+	}
+
+	fmt.Fprintf(out, "\nNearby claims (%d):\n", len(fly.NearbyClaims))
+	for _, claim := range fly.NearbyClaims {
+		fmt.Fprintf(out, "  @%s: %s%s\n", claim.AgentID, claim.Pattern, claimContext(claim))
+	}
+
+	fmt.Fprintln(out, "\nFocus:")
+	if fly.FocusThread == nil {
+		fmt.Fprintln(out, "  (room)")
+	} else {
+		fmt.Fprintf(out, "  %s (%s)\n", fly.FocusThread.Name, fly.FocusThread.GUID)
+	}
+}
+
+func printFlyMessage(out io.Writer, msg types.Message) {
+	fmt.Fprintf(out, "  [%s] @%s: %s\n", msg.ID, msg.FromAgent, truncateBody(msg.Body, 80))
+}
@@ -0,0 +1,39 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+)
+
+func TestFlyContextCommand(t *testing.T) {
+	projectDir := newFlowProject(t, "dev", "pm")
+	runFray(t, "thread", "design")
+	sketch := postJSON(t, "post", "--as", "dev", "design", "sketching the parser")
+	runFray(t, "post", "--as", "pm", "@dev can you review?")
+	// Order dev's thread post after its join message.
+	spreadTimestamps(t, projectDir, sketch["id"])
+
+	var fly db.FlyContext
+	output := runFray(t, "--json", "fly-context", "--as", "dev")
+	if err := json.Unmarshal([]byte(output), &fly); err != nil {
+		t.Fatalf("decode fly context: %v (%q)", err, output)
+	}
+	if fly.AgentID != "dev" || fly.FocusThread == nil || fly.FocusThread.Name != "design" {
+		t.Fatalf("expected dev focused on design, got %+v", fly)
+	}
+	if len(fly.UnreadMentions) != 1 || !strings.Contains(fly.UnreadMentions[0].Body, "review") {
+		t.Fatalf("expected pm's mention, got %+v", fly.UnreadMentions)
+	}
+
+	text := runFray(t, "fly-context", "--as", "dev")
+	if !strings.Contains(text, "Unread mentions (1)") || !strings.Contains(text, "design (") {
+		t.Fatalf("expected text sections, got %q", text)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "fly-context"); err == nil {
+		t.Fatal("expected fly-context without --as to fail")
+	}
+}
@@ -3,6 +3,7 @@ package command
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/adamavenir/fray/internal/db"
@@ -19,9 +20,17 @@ func NewFollowCmd() *cobra.Command {
 
 Accepts thread GUID, name, or path.
 
+Levels (--level):
+  all       every new message shows as thread activity (default)
+  mentions  only messages that @mention you show as activity
+  digest    never wakes the agent; activity still shows in fray get
+
+Following again with a different --level changes the level.
+
 Examples:
   fray follow design-thread
   fray follow opus/notes
+  fray follow design --level mentions --as dev
   fray follow thrd-xyz --as alice`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -42,14 +51,24 @@ Examples:
 				return writeCommandError(cmd, err)
 			}
 
+			levelFlag, _ := cmd.Flags().GetString("level")
+			level := types.SubscriptionLevel(strings.ToLower(strings.TrimSpace(levelFlag)))
+			if level == "" {
+				level = types.SubscriptionAll
+			}
+			if !db.IsValidSubscriptionLevel(level) {
+				return writeCommandError(cmd, fmt.Errorf("invalid --level %q (use all, mentions, or digest)", levelFlag))
+			}
+
 			now := time.Now().Unix()
-			if err := db.SubscribeThread(ctx.DB, thread.GUID, agentID, now); err != nil {
+			if err := db.SubscribeThreadLevel(ctx.DB, thread.GUID, agentID, level, now); err != nil {
 				return writeCommandError(cmd, err)
 			}
 			if err := db.AppendThreadSubscribe(ctx.Project.DBPath, db.ThreadSubscribeJSONLRecord{
 				ThreadGUID:   thread.GUID,
 				AgentID:      agentID,
 				SubscribedAt: now,
+				Level:        level,
 			}); err != nil {
 				return writeCommandError(cmd, err)
 			}
@@ -58,6 +77,7 @@ Examples:
 				payload := map[string]any{
 					"thread": thread.GUID,
 					"agent":  agentID,
+					"level":  level,
 				}
 				return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
 			}
@@ -66,12 +86,83 @@ Examples:
 			if path == "" {
 				path = thread.GUID
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "Following %s\n", path)
+			if level == types.SubscriptionAll {
+				fmt.Fprintf(cmd.OutOrStdout(), "Following %s\n", path)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Following %s (%s)\n", path, level)
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().String("as", "", "agent to follow as")
+	cmd.Flags().String("level", "", "subscription level: all, mentions, or digest")
+
+	return cmd
+}
+
+// NewFollowsCmd creates the follows command (list subscriptions with levels).
+func NewFollowsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "follows",
+		Short: "List followed threads and their levels",
+		Long: `List the threads an agent follows, with each subscription level.
+
+Examples:
+  fray follows --as dev
+  fray follows --as dev --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			agentRef, _ := cmd.Flags().GetString("as")
+			agentID, err := resolveSubscriptionAgent(ctx, agentRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			subs, err := db.GetAgentSubscriptions(ctx.DB, agentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			type followEntry struct {
+				types.ThreadSubscription
+				Path string `json:"path"`
+			}
+			entries := make([]followEntry, 0, len(subs))
+			for _, sub := range subs {
+				path := sub.ThreadGUID
+				if thread, err := db.GetThread(ctx.DB, sub.ThreadGUID); err == nil && thread != nil {
+					if built, err := buildThreadPath(ctx.DB, thread); err == nil && built != "" {
+						path = built
+					}
+				}
+				entries = append(entries, followEntry{ThreadSubscription: sub, Path: path})
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(entries)
+			}
+
+			out := cmd.OutOrStdout()
+			if len(entries) == 0 {
+				fmt.Fprintf(out, "@%s follows no threads\n", agentID)
+				return nil
+			}
+			fmt.Fprintf(out, "@%s follows %d thread(s):\n", agentID, len(entries))
+			for _, entry := range entries {
+				fmt.Fprintf(out, "  %-9s %s\n", entry.Level, entry.Path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent whose follows to list")
 
 	return cmd
 }
@@ -529,6 +620,14 @@ func updateThreadStatusTopLevel(cmd *cobra.Command, ref string, status string) e
 	if err != nil {
 		return writeCommandError(cmd, err)
 	}
+	asRef, _ := cmd.Flags().GetString("as")
+	action := "archive"
+	if status == string(types.ThreadStatusOpen) {
+		action = "restore"
+	}
+	if err := checkThreadCuration(ctx, thread, asRef, action); err != nil {
+		return writeCommandError(cmd, err)
+	}
 
 	updated, err := db.UpdateThread(ctx.DB, thread.GUID, db.ThreadUpdates{
 		Status: types.OptionalString{Set: true, Value: &status},
@@ -543,6 +642,9 @@ func updateThreadStatusTopLevel(cmd *cobra.Command, ref string, status string) e
 	}); err != nil {
 		return writeCommandError(cmd, err)
 	}
+	if err := recordThreadStatusUndo(cmd, ctx, thread, updated.Status); err != nil {
+		return writeCommandError(cmd, err)
+	}
 
 	if ctx.JSONMode {
 		return json.NewEncoder(cmd.OutOrStdout()).Encode(updated)
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestFollowLevelsAndFollows(t *testing.T) {
+	newFlowProject(t, "dev", "pm")
+	runFray(t, "thread", "design")
+	runFray(t, "thread", "ops")
+
+	output := runFray(t, "follow", "design", "--level", "mentions", "--as", "dev")
+	if !strings.Contains(output, "Following design (mentions)") {
+		t.Fatalf("unexpected follow output %q", output)
+	}
+	runFray(t, "follow", "ops", "--as", "dev")
+
+	if _, err := executeCommand(NewRootCmd("test"), "follow", "ops", "--level", "loud", "--as", "dev"); err == nil {
+		t.Fatalf("expected invalid level to fail")
+	}
+
+	var entries []struct {
+		types.ThreadSubscription
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal([]byte(runFray(t, "follows", "--as", "dev", "--json")), &entries); err != nil {
+		t.Fatalf("decode follows: %v", err)
+	}
+	levels := map[string]types.SubscriptionLevel{}
+	for _, entry := range entries {
+		levels[entry.Path] = entry.Level
+	}
+	if levels["design"] != types.SubscriptionMentions || levels["ops"] != types.SubscriptionAll {
+		t.Fatalf("unexpected levels %v", levels)
+	}
+
+	text := runFray(t, "follows", "--as", "dev")
+	if !strings.Contains(text, "mentions  design") || !strings.Contains(text, "all       ops") {
+		t.Fatalf("unexpected follows output %q", text)
+	}
+}
+
+func TestThreadActivityHintsRespectMentionsLevel(t *testing.T) {
+	projectDir := newFlowProject(t, "dev", "pm")
+	runFray(t, "thread", "design")
+	runFray(t, "follow", "design", "--level", "mentions", "--as", "dev")
+	runFray(t, "post", "--as", "pm", "design", "general progress update on the schema")
+
+	ctx := &CommandContext{DB: openProjectDB(t, projectDir)}
+	defer ctx.DB.Close()
+
+	hints, err := getThreadActivityHints(ctx, "dev")
+	if err != nil {
+		t.Fatalf("hints: %v", err)
+	}
+	if len(hints) != 0 {
+		t.Fatalf("expected no activity without a mention, got %+v", hints)
+	}
+
+	runFray(t, "post", "--as", "pm", "design", "@dev can you check the migration order?")
+	hints, err = getThreadActivityHints(ctx, "dev")
+	if err != nil {
+		t.Fatalf("hints: %v", err)
+	}
+	if len(hints) != 1 || hints[0].NewCount != 1 {
+		t.Fatalf("expected one mentioning message as activity, got %+v", hints)
+	}
+}
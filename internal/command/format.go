@@ -16,6 +16,9 @@ import (
 
 const maxDisplayLines = 60
 
+// importantMarker flags messages posted with --important.
+const importantMarker = "❗"
+
 // Accordion settings
 const (
 	DefaultAccordionThreshold = 10 // Show accordion if more than this many messages
@@ -59,25 +62,45 @@ func GetProjectName(projectRoot string) string {
 
 // FormatMessage formats a message for display.
 func FormatMessage(msg types.Message, projectName string, agentBases map[string]struct{}) string {
-	return formatMessageWithOptions(msg, projectName, agentBases, true, nil)
+	return formatMessageWithOptions(msg, projectName, agentBases, messageFormat{truncate: true})
 }
 
 // FormatMessageFull formats a message without truncation (for anchors).
 func FormatMessageFull(msg types.Message, projectName string, agentBases map[string]struct{}) string {
-	return formatMessageWithOptions(msg, projectName, agentBases, false, nil)
+	return formatMessageWithOptions(msg, projectName, agentBases, messageFormat{})
 }
 
 // FormatMessageWithQuote formats a message with an optional quoted message for inline display.
 func FormatMessageWithQuote(msg types.Message, projectName string, agentBases map[string]struct{}, quotedMsg *types.Message) string {
-	return formatMessageWithOptions(msg, projectName, agentBases, true, quotedMsg)
+	return formatMessageWithOptions(msg, projectName, agentBases, messageFormat{truncate: true, quoted: quotedMsg})
+}
+
+// messageFormat holds per-call choices for formatMessageWithOptions.
+type messageFormat struct {
+	truncate bool
+	quoted   *types.Message
+	// markdownWidth renders the body as markdown for a terminal this wide;
+	// zero leaves it as typed.
+	markdownWidth int
+}
+
+func formatMessageWithOptions(msg types.Message, projectName string, agentBases map[string]struct{}, opts messageFormat) string {
+	formatted := formatMessageLine(msg, projectName, agentBases, opts)
+	for _, commit := range msg.Commits {
+		formatted += "\n" + formatCommitSummary(commit)
+	}
+	return formatted
 }
 
-func formatMessageWithOptions(msg types.Message, projectName string, agentBases map[string]struct{}, truncate bool, quotedMsg *types.Message) string {
+func formatMessageLine(msg types.Message, projectName string, agentBases map[string]struct{}, opts messageFormat) string {
 	editedSuffix := ""
 	if msg.Edited || msg.EditCount > 0 || msg.EditedAt != nil {
 		editedSuffix = " (edited)"
 	}
 	idBlock := fmt.Sprintf("%s[%s#%s%s %s]%s", dim, bold, projectName, reset, dim+msg.ID+editedSuffix, reset)
+	if msg.Important {
+		idBlock += " " + importantMarker
+	}
 
 	// Check for answer message format
 	if strings.HasPrefix(msg.Body, "answered @") {
@@ -86,14 +109,17 @@ func formatMessageWithOptions(msg types.Message, projectName string, agentBases
 
 	color := getAgentColor(msg.FromAgent, msg.Type, nil)
 	displayBody := msg.Body
-	if truncate {
+	if opts.truncate {
 		displayBody = truncateForDisplay(msg.Body, msg.ID)
 	}
+	rendered := renderMarkdownBody(displayBody, opts.markdownWidth, defaultMarkdownStyles)
+	markdown := rendered != displayBody
+	displayBody = rendered
 
 	// Format quote block if present
 	quoteBlock := ""
-	if quotedMsg != nil {
-		quoteBlock = formatQuoteBlock(quotedMsg, agentBases)
+	if opts.quoted != nil {
+		quoteBlock = formatQuoteBlock(opts.quoted, agentBases)
 	}
 
 	// Format reactions if present
@@ -105,13 +131,18 @@ func formatMessageWithOptions(msg types.Message, projectName string, agentBases
 	if color != "" {
 		coloredBody := colorizeBody(displayBody, color, agentBases)
 		coloredBody = highlightIssueIDs(coloredBody, color)
+		coloredBody = highlightThreadRefs(coloredBody, color, msg.ThreadRefs)
+		if markdown {
+			// Markdown styles end in a full reset; restore the sender color.
+			coloredBody = strings.ReplaceAll(coloredBody, "\x1b[0m", "\x1b[0m"+color)
+		}
 		if quoteBlock != "" {
 			return fmt.Sprintf("%s %s@%s:%s\n%s\n\"%s\"%s%s", idBlock, color, msg.FromAgent, reset, quoteBlock, color+coloredBody, reset, reactionSuffix)
 		}
 		return fmt.Sprintf("%s %s@%s: \"%s\"%s%s", idBlock, color, msg.FromAgent, coloredBody, reset, reactionSuffix)
 	}
 
-	highlightedBody := highlightIssueIDs(highlightMentions(displayBody), "")
+	highlightedBody := highlightThreadRefs(highlightIssueIDs(highlightMentions(displayBody), ""), "", msg.ThreadRefs)
 	if quoteBlock != "" {
 		return fmt.Sprintf("%s @%s:\n%s\n\"%s\"%s", idBlock, msg.FromAgent, quoteBlock, highlightedBody, reactionSuffix)
 	}
@@ -271,6 +302,27 @@ func highlightIssueIDs(body, senderColor string) string {
 	})
 }
 
+// highlightThreadRefs colors #thread and thrd- references. It only runs for
+// messages that cite a real thread, so a stray #word stays plain.
+func highlightThreadRefs(body, senderColor string, threadRefs []string) string {
+	if noColor || len(threadRefs) == 0 {
+		return body
+	}
+	spans := core.FindThreadRefs(body)
+	if len(spans) == 0 {
+		return body
+	}
+	var out strings.Builder
+	last := 0
+	for _, span := range spans {
+		out.WriteString(body[last:span.Start])
+		out.WriteString(cyan + body[span.Start:span.End] + reset + senderColor)
+		last = span.End
+	}
+	out.WriteString(body[last:])
+	return out.String()
+}
+
 func highlightMentions(body string) string {
 	if noColor {
 		return body
@@ -400,6 +452,58 @@ func FormatMessagePreview(msg types.Message, projectName string) string {
 	return fmt.Sprintf("  %s[%s]%s @%s: %s", dim, shortID, reset, msg.FromAgent, firstLine)
 }
 
+// formatReplyParentContext renders a context-only reply parent as a dimmed line.
+func formatReplyParentContext(parent types.Message) string {
+	body := strings.Join(strings.Fields(parent.Body), " ")
+	return fmt.Sprintf("%s↳ in reply to @%s [%s]: %s%s", dim, parent.FromAgent, parent.ID, truncateBody(body, 80), reset)
+}
+
+// replyPreviewLen caps the parent body shown in a reply preview.
+const replyPreviewLen = 60
+
+// ReplyPreviews maps reply parent IDs to their parents for inline previews.
+// A nil map disables previews; a reply whose parent is absent from a non-nil
+// map (e.g. pruned) gets a "not available" line instead.
+type ReplyPreviews map[string]*types.Message
+
+// line returns the dimmed preview line for msg, or "" when msg is not a reply
+// or previews are disabled.
+func (p ReplyPreviews) line(msg types.Message) string {
+	if p == nil || msg.ReplyTo == nil || *msg.ReplyTo == "" {
+		return ""
+	}
+	return formatReplyPreview(*msg.ReplyTo, p[*msg.ReplyTo])
+}
+
+// prefix puts msg's preview line above its formatted line.
+func (p ReplyPreviews) prefix(msg types.Message, formatted string) string {
+	if preview := p.line(msg); preview != "" {
+		return preview + "\n" + formatted
+	}
+	return formatted
+}
+
+// formatReplyPreview renders "↳ @alice: What do you think about..." for a
+// reply's parent. A nil parent means it is no longer available.
+func formatReplyPreview(parentID string, parent *types.Message) string {
+	if parent == nil {
+		return fmt.Sprintf("%s↳ reply to %s (not available)%s", dim, parentID, reset)
+	}
+	body := strings.Join(strings.Fields(parent.Body), " ")
+	return fmt.Sprintf("%s↳ @%s: %s%s", dim, parent.FromAgent, truncateBody(body, replyPreviewLen), reset)
+}
+
+// FormatMessageWithPreview formats a message with its reply preview, if any.
+func FormatMessageWithPreview(msg types.Message, projectName string, agentBases map[string]struct{}, previews ReplyPreviews) string {
+	return formatMessageWithPreview(msg, projectName, agentBases, previews, 0)
+}
+
+// formatMessageWithPreview is FormatMessageWithPreview with markdown rendered
+// to markdownWidth (zero for raw bodies).
+func formatMessageWithPreview(msg types.Message, projectName string, agentBases map[string]struct{}, previews ReplyPreviews, markdownWidth int) string {
+	return previews.prefix(msg, formatMessageWithOptions(msg, projectName, agentBases, messageFormat{truncate: true, markdownWidth: markdownWidth}))
+}
+
 // AccordionOptions configures accordion behavior.
 type AccordionOptions struct {
 	Threshold    int  // Show accordion if more than this many messages (0 = use default)
@@ -409,6 +513,16 @@ type AccordionOptions struct {
 	ProjectName  string
 	AgentBases   map[string]struct{}
 	QuotedMsgs   map[string]*types.Message // Map of message ID -> quoted message for inline display
+	Parents      map[string]*types.Message // Context-only reply parents outside the page (get --with-parents)
+	Previews     ReplyPreviews             // Inline reply previews (nil = off)
+	// ExpandCommits shows attached commits' files and patches (get --with-commits)
+	ExpandCommits bool
+	// Interleave holds extra lines to print before messages[i]; the key
+	// len(messages) prints after the last message (get --with-threads).
+	Interleave map[int][]string
+	// MarkdownWidth renders bodies as markdown for a terminal this wide
+	// (get --render-md); zero leaves them as typed.
+	MarkdownWidth int
 }
 
 // FormatMessageListAccordion formats a list of messages with accordion collapsing.
@@ -431,22 +545,52 @@ func FormatMessageListAccordion(messages []types.Message, opts AccordionOptions)
 		tailCount = AccordionTailCount
 	}
 
+	// Each context-only parent is rendered once, above the first reply to it;
+	// other full-format replies get the shorter inline preview when enabled.
+	shownParents := make(map[string]struct{})
+	withParent := func(msg types.Message, line string, preview bool) string {
+		if msg.ReplyTo == nil {
+			return line
+		}
+		if parent, ok := opts.Parents[*msg.ReplyTo]; ok {
+			if _, shown := shownParents[parent.ID]; !shown {
+				shownParents[parent.ID] = struct{}{}
+				return formatReplyParentContext(*parent) + "\n" + line
+			}
+		}
+		if preview {
+			return opts.Previews.prefix(msg, line)
+		}
+		return line
+	}
+
 	// Helper to format a message with its quote if available
 	formatMsg := func(msg types.Message) string {
 		var quotedMsg *types.Message
 		if msg.QuoteMessageGUID != nil && opts.QuotedMsgs != nil {
 			quotedMsg = opts.QuotedMsgs[*msg.QuoteMessageGUID]
 		}
-		return formatMessageWithOptions(msg, opts.ProjectName, opts.AgentBases, true, quotedMsg)
+		line := withParent(msg, formatMessageWithOptions(msg, opts.ProjectName, opts.AgentBases, messageFormat{
+			truncate:      true,
+			quoted:        quotedMsg,
+			markdownWidth: opts.MarkdownWidth,
+		}), true)
+		if opts.ExpandCommits {
+			for _, commit := range msg.Commits {
+				line += "\n" + formatCommitDetails(commit)
+			}
+		}
+		return line
 	}
 
 	// If ShowAll or under threshold, format all messages normally
 	if opts.ShowAll || len(messages) <= threshold {
-		lines := make([]string, len(messages))
+		lines := make([]string, 0, len(messages))
 		for i, msg := range messages {
-			lines[i] = formatMsg(msg)
+			lines = append(lines, opts.Interleave[i]...)
+			lines = append(lines, formatMsg(msg))
 		}
-		return lines
+		return append(lines, opts.Interleave[len(messages)]...)
 	}
 
 	// Accordion: head + collapsed middle + tail
@@ -454,6 +598,7 @@ func FormatMessageListAccordion(messages []types.Message, opts AccordionOptions)
 
 	// Head messages (full format)
 	for i := 0; i < headCount && i < len(messages); i++ {
+		lines = append(lines, opts.Interleave[i]...)
 		lines = append(lines, formatMsg(messages[i]))
 	}
 
@@ -464,7 +609,8 @@ func FormatMessageListAccordion(messages []types.Message, opts AccordionOptions)
 		collapsedCount := middleEnd - middleStart
 		lines = append(lines, fmt.Sprintf("%s  ... %d messages collapsed ...%s", dim, collapsedCount, reset))
 		for i := middleStart; i < middleEnd; i++ {
-			lines = append(lines, FormatMessagePreview(messages[i], opts.ProjectName))
+			lines = append(lines, opts.Interleave[i]...)
+			lines = append(lines, withParent(messages[i], FormatMessagePreview(messages[i], opts.ProjectName), false))
 		}
 		lines = append(lines, fmt.Sprintf("%s  ... end collapsed ...%s", dim, reset))
 	}
@@ -472,11 +618,12 @@ func FormatMessageListAccordion(messages []types.Message, opts AccordionOptions)
 	// Tail messages (full format)
 	for i := middleEnd; i < len(messages); i++ {
 		if i >= headCount { // Avoid duplicates if list is small
+			lines = append(lines, opts.Interleave[i]...)
 			lines = append(lines, formatMsg(messages[i]))
 		}
 	}
 
-	return lines
+	return append(lines, opts.Interleave[len(messages)]...)
 }
 
 // formatReactionSummary formats reactions for display.
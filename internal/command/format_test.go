@@ -47,3 +47,36 @@ func TestFormatMessageTruncation(t *testing.T) {
 		t.Fatalf("expected truncation hint, got %q", output)
 	}
 }
+
+func TestFormatReplyPreview(t *testing.T) {
+	parentID := "msg-parent01"
+	parent := &types.Message{
+		ID:        parentID,
+		FromAgent: "alice",
+		Body:      "What do you think about\nmoving the auth checks into a shared middleware layer?",
+	}
+	reply := types.Message{ID: "msg-reply001", FromAgent: "bob", Body: "agreed", ReplyTo: &parentID}
+
+	preview := ansiEscapeRe.ReplaceAllString(ReplyPreviews{parentID: parent}.line(reply), "")
+	want := "↳ @alice: What do you think about moving the auth checks into a sha..."
+	if preview != want {
+		t.Fatalf("expected %q, got %q", want, preview)
+	}
+	if body := strings.TrimPrefix(preview, "↳ @alice: "); len(body) != replyPreviewLen {
+		t.Fatalf("expected preview body truncated to %d chars, got %d", replyPreviewLen, len(body))
+	}
+
+	// A parent that was pruned is still acknowledged rather than dropped.
+	missing := ansiEscapeRe.ReplaceAllString(ReplyPreviews{}.line(reply), "")
+	if missing != "↳ reply to msg-parent01 (not available)" {
+		t.Fatalf("expected not-available preview, got %q", missing)
+	}
+
+	if got := ReplyPreviews(nil).prefix(reply, "line"); got != "line" {
+		t.Fatalf("expected disabled previews to leave the line alone, got %q", got)
+	}
+	plain := types.Message{ID: "msg-plain001", FromAgent: "bob", Body: "hi"}
+	if got := (ReplyPreviews{parentID: parent}).prefix(plain, "line"); got != "line" {
+		t.Fatalf("expected no preview for a non-reply, got %q", got)
+	}
+}
@@ -3,6 +3,7 @@ package command
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -27,6 +28,23 @@ Paths:
   fray get design-thread      Specific thread by name
   fray get notifs             Notifications only (@mentions + followed threads)
   fray get msg-abc            Specific message (shorthand: fray msg-abc)
+  fray get --important        Only messages flagged important (high-signal view)
+
+Compact output for agents (stable formats, safe to parse):
+  fray get design --compact --last 20   id|ts|from|home|body, one per line;
+                                        body newlines/backslashes escaped,
+                                        cut at --max-body chars (default 280)
+                                        as "…[<n> chars]"
+  fray get design --ids-only            Message GUIDs only, for mv/pin
+  fray get design --compact --expand-code
+                                        Keep code blocks instead of
+                                        "[code: go, 42 lines]" placeholders
+  fray get design --code-only           Only messages with fenced code
+                                        (--no-code drops them instead)
+
+Time windows (--since/--until accept 24h, 7d, RFC3339, or a message GUID):
+  fray get --since 7d --until 2026-01-31T00:00:00Z
+  fray get notifs --as alice --since 24h   Mention history (leaves read state alone)
 
 Legacy (deprecated):
   fray get <agent>            Still works for agent-based room + mentions`,
@@ -43,6 +61,7 @@ Legacy (deprecated):
 			before, _ := cmd.Flags().GetString("before")
 			from, _ := cmd.Flags().GetString("from")
 			to, _ := cmd.Flags().GetString("to")
+			until, _ := cmd.Flags().GetString("until")
 			all, _ := cmd.Flags().GetBool("all")
 			room, _ := cmd.Flags().GetString("room")
 			mentions, _ := cmd.Flags().GetString("mentions")
@@ -52,9 +71,22 @@ Legacy (deprecated):
 			showEvents, _ := cmd.Flags().GetBool("show-events")
 			showAllMessages, _ := cmd.Flags().GetBool("show-all")
 			asRef, _ := cmd.Flags().GetString("as")
+			withParents, _ := cmd.Flags().GetBool("with-parents")
+			importantOnly, _ := cmd.Flags().GetBool("important")
+			withCommits, _ := cmd.Flags().GetBool("with-commits")
+			withThreads, _ := cmd.Flags().GetBool("with-threads")
 			if showEvents {
 				hideEvents = false
 			}
+			listing, err := compactListingFlags(cmd, ctx.JSONMode)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			withCode, err := codeFilterFlags(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			mdWidth := markdownWidthFor(cmd, ctx, listing)
 
 			projectName := GetProjectName(ctx.Project.Root)
 			var agentBases map[string]struct{}
@@ -65,6 +97,8 @@ Legacy (deprecated):
 				}
 			}
 
+			byAgents, notByAgents := authorFilterFlags(cmd, ctx)
+
 			// Determine what we're getting
 			var target string
 			if len(args) > 0 {
@@ -73,7 +107,7 @@ Legacy (deprecated):
 
 			// Handle special path: "notifs"
 			if target == "notifs" {
-				return getNotifications(cmd, ctx, asRef, projectName, agentBases, showAllMessages)
+				return getNotifications(cmd, ctx, asRef, projectName, agentBases, mdWidth, showAllMessages, since, until)
 			}
 
 			// Try to resolve as thread path first
@@ -81,10 +115,9 @@ Legacy (deprecated):
 				thread, err := resolveThreadRef(ctx.DB, target)
 				if err == nil && thread != nil {
 					pinnedOnly, _ := cmd.Flags().GetBool("pinned")
-					byAgent, _ := cmd.Flags().GetString("by")
 					withText, _ := cmd.Flags().GetString("with")
 					reactionsOnly, _ := cmd.Flags().GetBool("reactions")
-					return getThread(cmd, ctx, thread, last, since, showAllMessages, projectName, agentBases, hideEvents, pinnedOnly, byAgent, withText, reactionsOnly)
+					return getThread(cmd, ctx, thread, last, since, until, showAllMessages, projectName, agentBases, mdWidth, hideEvents, pinnedOnly, byAgents, notByAgents, withText, reactionsOnly, importantOnly)
 				}
 			}
 
@@ -92,19 +125,19 @@ Legacy (deprecated):
 			if target != "" && (strings.HasPrefix(target, "msg-") || len(target) <= 12) {
 				msg, err := resolveMessageRef(ctx.DB, target)
 				if err == nil && msg != nil {
-					return getMessage(cmd, ctx, msg, projectName, agentBases)
+					return getMessage(cmd, ctx, msg, projectName, agentBases, mdWidth)
 				}
 			}
 
 			// Query mode when using explicit range/limit flags
-			isQueryMode := (last != "" && len(args) == 0) || since != "" || before != "" || from != "" || to != "" || all
+			isQueryMode := (last != "" && len(args) == 0) || since != "" || before != "" || from != "" || to != "" || until != "" || all || (importantOnly && len(args) == 0)
 
 			// Legacy: try to resolve as agent ID for backward compatibility
 			var resolvedAgentID string
 			if target != "" {
 				resolvedAgentID, err = resolveAgentRef(ctx, target)
 				if err != nil {
-					return writeCommandError(cmd, fmt.Errorf("unknown path, thread, or agent: %s", target))
+					return writeCommandError(cmd, notFoundError("unknown path, thread, or agent: %s", target))
 				}
 			} else if asRef != "" {
 				resolvedAgentID, err = resolveAgentRef(ctx, asRef)
@@ -125,44 +158,56 @@ Legacy (deprecated):
 				var options types.MessageQueryOptions
 				options.Filter = filter
 				options.IncludeArchived = archived
+				options.FromAgents = byAgents
+				options.ExcludeFromAgents = notByAgents
+				options.ImportantOnly = importantOnly
+				if since != "" && from != "" {
+					return writeCommandError(cmd, validationError("use --since or --from, not both"))
+				}
+				if before != "" && to != "" {
+					return writeCommandError(cmd, validationError("use --before or --to, not both"))
+				}
+				if until != "" && (before != "" || to != "") {
+					return writeCommandError(cmd, validationError("use --until, --before, or --to, not more than one"))
+				}
 
-				if all {
-					// no limits
-				} else if since != "" || before != "" || from != "" || to != "" {
-					if since != "" && from != "" {
-						return writeCommandError(cmd, fmt.Errorf("use --since or --from, not both"))
+				// Range flags always apply, including alongside --important and
+				// --until; --last only caps the result.
+				start := since
+				if start == "" {
+					start = from
+				}
+				end := before
+				if end == "" {
+					end = to
+				}
+				if start != "" {
+					cursor, err := core.ParseTimeExpression(ctx.DB, start, "since")
+					if err != nil {
+						return writeCommandError(cmd, err)
 					}
-					if before != "" && to != "" {
-						return writeCommandError(cmd, fmt.Errorf("use --before or --to, not both"))
+					options.Since = cursor
+				}
+				if end != "" {
+					cursor, err := core.ParseTimeExpression(ctx.DB, end, "before")
+					if err != nil {
+						return writeCommandError(cmd, err)
 					}
-
-					start := since
-					if start == "" {
-						start = from
+					options.Before = cursor
+				}
+				if until != "" {
+					if err := applyTimeBounds(ctx.DB, "", until, &options); err != nil {
+						return writeCommandError(cmd, err)
 					}
-					end := before
-					if end == "" {
-						end = to
+					if options.Since != nil && options.Since.TS > *options.UntilTS {
+						return writeCommandError(cmd, validationError("--since is after --until"))
 					}
+				}
 
-					if start != "" {
-						cursor, err := core.ParseTimeExpression(ctx.DB, start, "since")
-						if err != nil {
-							return writeCommandError(cmd, err)
-						}
-						options.Since = cursor
-					}
-					if end != "" {
-						cursor, err := core.ParseTimeExpression(ctx.DB, end, "before")
-						if err != nil {
-							return writeCommandError(cmd, err)
-						}
-						options.Before = cursor
-					}
-				} else {
+				if last != "" && !all {
 					limit, err := strconv.Atoi(last)
 					if err != nil {
-						return writeCommandError(cmd, fmt.Errorf("invalid --last value"))
+						return writeCommandError(cmd, validationError("invalid --last value"))
 					}
 					options.Limit = limit
 				}
@@ -178,20 +223,59 @@ Legacy (deprecated):
 				if hideEvents {
 					messages = filterEventMessages(messages)
 				}
+				messages = filterCodeMessages(messages, withCode)
+
+				var parents []types.Message
+				if withParents {
+					parents, err = CollectReplyParents(ctx.DB, messages)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
+				}
 
 				if ctx.JSONMode {
+					if withParents {
+						return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+							"messages": messages,
+							"parents":  parents,
+						})
+					}
 					return json.NewEncoder(cmd.OutOrStdout()).Encode(messages)
 				}
 
 				out := cmd.OutOrStdout()
+				if listing != nil {
+					listing.write(out, messages)
+					return nil
+				}
 				if len(messages) == 0 {
 					fmt.Fprintln(out, "No messages")
 					return nil
 				}
+				previews, err := replyPreviews(cmd, ctx, messages)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				var interleave map[int][]string
+				if withThreads {
+					viewer := resolvedAgentID
+					if parsed, err := core.ParseAgentID(viewer); err == nil {
+						viewer = parsed.Base
+					}
+					interleave, err = roomThreadInterleave(ctx, viewer, messages)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
+				}
 				lines := FormatMessageListAccordion(messages, AccordionOptions{
-					ShowAll:     showAllMessages,
-					ProjectName: projectName,
-					AgentBases:  agentBases,
+					ShowAll:       showAllMessages,
+					ProjectName:   projectName,
+					AgentBases:    agentBases,
+					Parents:       replyParentMap(parents),
+					Previews:      previews,
+					ExpandCommits: withCommits,
+					MarkdownWidth: mdWidth,
+					Interleave:    interleave,
 				})
 				for _, line := range lines {
 					fmt.Fprintln(out, line)
@@ -219,7 +303,7 @@ Legacy (deprecated):
 					// Explicit --last flag: use that limit
 					roomLimit, err := strconv.Atoi(last)
 					if err != nil {
-						return writeCommandError(cmd, fmt.Errorf("invalid --last value"))
+						return writeCommandError(cmd, validationError("invalid --last value"))
 					}
 					roomMessages, err = db.GetMessages(ctx.DB, &types.MessageQueryOptions{Limit: roomLimit, Filter: filter, IncludeArchived: archived})
 				} else if watermark != nil {
@@ -257,6 +341,15 @@ Legacy (deprecated):
 				if hideEvents {
 					roomMessages = filterEventMessages(roomMessages)
 				}
+				roomMessages = filterCodeMessages(roomMessages, withCode)
+
+				var roomParents []types.Message
+				if withParents {
+					roomParents, err = CollectReplyParents(ctx.DB, roomMessages)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
+				}
 
 				// Check ghost cursor for session-aware unread logic
 				allHomes := ""
@@ -300,6 +393,7 @@ Legacy (deprecated):
 				if hideEvents {
 					mentionMessages = filterEventMessages(mentionMessages)
 				}
+				mentionMessages = filterCodeMessages(mentionMessages, withCode)
 
 				roomIDs := map[string]struct{}{}
 				for _, msg := range roomMessages {
@@ -354,18 +448,42 @@ Legacy (deprecated):
 						"read_to":       readTo,
 						"threads":       threadHints,
 					}
+					if withParents {
+						payload["parents"] = roomParents
+					}
 					return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
 				}
+				if listing != nil {
+					listing.write(cmd.OutOrStdout(), append(append([]types.Message{}, roomMessages...), filtered...))
+					return nil
+				}
+
+				previews, err := replyPreviews(cmd, ctx, append(append([]types.Message{}, roomMessages...), filtered...))
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
 
 				out := cmd.OutOrStdout()
 				if len(roomMessages) == 0 {
 					fmt.Fprintln(out, "ROOM: (no messages yet)")
 				} else {
 					fmt.Fprintln(out, "ROOM:")
+					var interleave map[int][]string
+					if withThreads {
+						interleave, err = roomThreadInterleave(ctx, agentBase, roomMessages)
+						if err != nil {
+							return writeCommandError(cmd, err)
+						}
+					}
 					lines := FormatMessageListAccordion(roomMessages, AccordionOptions{
-						ShowAll:     showAllMessages,
-						ProjectName: projectName,
-						AgentBases:  agentBases,
+						ShowAll:       showAllMessages,
+						ProjectName:   projectName,
+						AgentBases:    agentBases,
+						Parents:       replyParentMap(roomParents),
+						Previews:      previews,
+						ExpandCommits: withCommits,
+						MarkdownWidth: mdWidth,
+						Interleave:    interleave,
 					})
 					for _, line := range lines {
 						fmt.Fprintln(out, line)
@@ -396,7 +514,7 @@ Legacy (deprecated):
 					if len(direct) > 0 {
 						fmt.Fprintf(out, "Recent @%s:\n", agentBase)
 						for _, msg := range direct {
-							fmt.Fprintln(out, FormatMessage(msg, projectName, agentBases))
+							fmt.Fprintln(out, formatMessageWithPreview(msg, projectName, agentBases, previews, mdWidth))
 							for _, reactionLine := range formatReactionEvents(msg) {
 								fmt.Fprintf(out, "  %s\n", reactionLine)
 							}
@@ -409,7 +527,7 @@ Legacy (deprecated):
 						}
 						fmt.Fprintln(out, "You were FYI'd here:")
 						for _, msg := range fyi {
-							fmt.Fprintln(out, FormatMessage(msg, projectName, agentBases))
+							fmt.Fprintln(out, formatMessageWithPreview(msg, projectName, agentBases, previews, mdWidth))
 						}
 					}
 
@@ -468,7 +586,7 @@ Legacy (deprecated):
 				return nil
 			}
 
-			return writeCommandError(cmd, fmt.Errorf("usage: fray get <agent>           Unread room + @mentions (default)\n       fray get <agent> --last <n> Last N room messages\n       fray get --last <n>         Last N messages (no agent)\n       fray get --since <guid>     Messages after GUID\n       fray get --all              All messages"))
+			return writeCommandError(cmd, validationError("usage: fray get <agent>           Unread room + @mentions (default)\n       fray get <agent> --last <n> Last N room messages\n       fray get --last <n>         Last N messages (no agent)\n       fray get --since <guid>     Messages after GUID\n       fray get --all              All messages"))
 		},
 	}
 
@@ -477,6 +595,7 @@ Legacy (deprecated):
 	cmd.Flags().String("before", "", "show messages before time or GUID")
 	cmd.Flags().String("from", "", "range start (time or GUID)")
 	cmd.Flags().String("to", "", "range end (time or GUID)")
+	cmd.Flags().String("until", "", "show messages at or before a duration ago, RFC3339 time, or GUID")
 	cmd.Flags().Bool("all", false, "show all messages")
 	cmd.Flags().String("room", "10", "number of room messages in combined view")
 	cmd.Flags().String("mentions", "3", "number of @mentions in combined view")
@@ -485,20 +604,74 @@ Legacy (deprecated):
 	cmd.Flags().Bool("hide-events", false, "hide event messages")
 	cmd.Flags().Bool("show-events", false, "show event messages")
 	cmd.Flags().Bool("show-all", false, "disable accordion, show all messages fully")
+	cmd.Flags().Bool("with-threads", true, "interleave active threads' anchors in the room (human output only)")
 	cmd.Flags().String("as", "", "agent identity (uses FRAY_AGENT_ID if not set)")
 	cmd.Flags().Bool("replies", false, "show message with reply chain")
+	cmd.Flags().Bool("with-parents", false, "include parents of replies that fall outside the page (context only)")
+	cmd.Flags().Bool("important", false, "show only messages flagged important")
+	cmd.Flags().Bool("no-reply-preview", false, "don't show a preview of the parent above replies")
 
 	// Within-thread filters
 	cmd.Flags().Bool("pinned", false, "show only pinned messages (threads only)")
-	cmd.Flags().String("by", "", "filter messages by agent")
+	cmd.Flags().Bool("with-commits", false, "expand attached commits: files changed and captured patches")
+	cmd.Flags().Bool("no-header", false, "omit the thread participation header (threads only)")
+	cmd.Flags().Bool("with-stats", false, "include thread stats in JSON output (threads only)")
+	cmd.Flags().StringArray("by", nil, "only messages by this agent or its subagents (repeatable)")
+	cmd.Flags().StringArray("not-by", nil, "drop messages by this agent or its subagents (repeatable, wins over --by)")
 	cmd.Flags().String("with", "", "filter messages containing text")
 	cmd.Flags().Bool("reactions", false, "show only messages with reactions")
+	cmd.Flags().Bool("code-only", false, "show only messages containing fenced code blocks")
+	cmd.Flags().Bool("no-code", false, "drop messages containing fenced code blocks")
+	cmd.Flags().Bool("compact", false, "one message per line: id|ts|from|home|body (newlines escaped)")
+	cmd.Flags().Bool("ids-only", false, "print only message GUIDs, one per line")
+	cmd.Flags().Int("max-body", defaultCompactMaxBody, "truncate --compact bodies to N characters (0 for no limit)")
+	cmd.Flags().Bool("render-md", false, "render markdown in message bodies (terminal output only; also config render_markdown=true)")
+	cmd.Flags().Bool("expand-code", false, "keep fenced code blocks in --compact bodies instead of \"[code: go, 42 lines]\"")
 
 	return cmd
 }
 
+// codeFilterFlags reads --code-only and --no-code. It returns nil when
+// neither is set, else whether to keep messages with fenced code blocks.
+func codeFilterFlags(cmd *cobra.Command) (*bool, error) {
+	codeOnly, _ := cmd.Flags().GetBool("code-only")
+	noCode, _ := cmd.Flags().GetBool("no-code")
+	if codeOnly && noCode {
+		return nil, validationError("use --code-only or --no-code, not both")
+	}
+	if !codeOnly && !noCode {
+		return nil, nil
+	}
+	return &codeOnly, nil
+}
+
+// replyPreviews batches inline reply previews for text output, honouring
+// --no-reply-preview. JSON output carries reply_to instead.
+func replyPreviews(cmd *cobra.Command, ctx *CommandContext, messages []types.Message) (ReplyPreviews, error) {
+	if ctx.JSONMode {
+		return nil, nil
+	}
+	if off, _ := cmd.Flags().GetBool("no-reply-preview"); off {
+		return nil, nil
+	}
+	return CollectReplyPreviews(ctx.DB, messages)
+}
+
+// authorFilterFlags resolves --by and --not-by into agent IDs.
+func authorFilterFlags(cmd *cobra.Command, ctx *CommandContext) ([]string, []string) {
+	resolve := func(name string) []string {
+		refs, _ := cmd.Flags().GetStringArray(name)
+		agents := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			agents = append(agents, ResolveAgentRef(ref, ctx.ProjectConfig))
+		}
+		return agents
+	}
+	return resolve("by"), resolve("not-by")
+}
+
 // getThread displays messages from a thread.
-func getThread(cmd *cobra.Command, ctx *CommandContext, thread *types.Thread, last, since string, showAll bool, projectName string, agentBases map[string]struct{}, hideEvents bool, pinnedOnly bool, byAgent, withText string, reactionsOnly bool) error {
+func getThread(cmd *cobra.Command, ctx *CommandContext, thread *types.Thread, last, since, until string, showAll bool, projectName string, agentBases map[string]struct{}, mdWidth int, hideEvents bool, pinnedOnly bool, byAgents, notByAgents []string, withText string, reactionsOnly, importantOnly bool) error {
 	var messages []types.Message
 	var err error
 
@@ -537,12 +710,26 @@ func getThread(cmd *cobra.Command, ctx *CommandContext, thread *types.Thread, la
 		messages = filtered
 	}
 
-	// Apply --by filter (filter by agent)
-	if byAgent != "" {
-		agentID := ResolveAgentRef(byAgent, ctx.ProjectConfig)
+	// Apply --until filter (inclusive)
+	if until != "" {
+		bounds := types.MessageQueryOptions{}
+		if err := applyTimeBounds(ctx.DB, "", until, &bounds); err != nil {
+			return writeCommandError(cmd, err)
+		}
 		var filtered []types.Message
 		for _, msg := range messages {
-			if msg.FromAgent == agentID || strings.HasPrefix(msg.FromAgent, agentID+".") {
+			if msg.TS <= *bounds.UntilTS {
+				filtered = append(filtered, msg)
+			}
+		}
+		messages = filtered
+	}
+
+	// Apply --by / --not-by filters (filter by agent)
+	if len(byAgents) > 0 || len(notByAgents) > 0 {
+		var filtered []types.Message
+		for _, msg := range messages {
+			if db.MatchesAuthorFilter(msg.FromAgent, byAgents, notByAgents) {
 				filtered = append(filtered, msg)
 			}
 		}
@@ -572,11 +759,29 @@ func getThread(cmd *cobra.Command, ctx *CommandContext, thread *types.Thread, la
 		messages = filtered
 	}
 
+	// Apply --important filter
+	if importantOnly {
+		var filtered []types.Message
+		for _, msg := range messages {
+			if msg.Important {
+				filtered = append(filtered, msg)
+			}
+		}
+		messages = filtered
+	}
+
+	// Apply --code-only / --no-code filters
+	withCode, err := codeFilterFlags(cmd)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+	messages = filterCodeMessages(messages, withCode)
+
 	// Apply --last limit
 	if last != "" {
 		limit, err := strconv.Atoi(last)
 		if err != nil {
-			return writeCommandError(cmd, fmt.Errorf("invalid --last value: %s", last))
+			return writeCommandError(cmd, validationError("invalid --last value: %s", last))
 		}
 		if limit > 0 && len(messages) > limit {
 			messages = messages[len(messages)-limit:]
@@ -589,17 +794,54 @@ func getThread(cmd *cobra.Command, ctx *CommandContext, thread *types.Thread, la
 
 	path, _ := buildThreadPath(ctx.DB, thread)
 
+	var parents []types.Message
+	withParents, _ := cmd.Flags().GetBool("with-parents")
+	if withParents {
+		parents, err = CollectReplyParents(ctx.DB, messages)
+		if err != nil {
+			return writeCommandError(cmd, err)
+		}
+	}
+
+	listing, err := compactListingFlags(cmd, ctx.JSONMode)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+	noHeader, _ := cmd.Flags().GetBool("no-header")
+	withStats, _ := cmd.Flags().GetBool("with-stats")
+	var stats *types.ThreadStats
+	if (ctx.JSONMode && withStats) || (!ctx.JSONMode && !noHeader && listing == nil) {
+		stats, err = db.GetThreadStats(ctx.DB, thread.GUID)
+		if err != nil {
+			return writeCommandError(cmd, err)
+		}
+	}
+
 	if ctx.JSONMode {
 		payload := map[string]any{
 			"thread":   thread,
 			"path":     path,
 			"messages": messages,
 		}
+		if withParents {
+			payload["parents"] = parents
+		}
+		if withStats {
+			payload["stats"] = stats
+		}
 		return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
 	}
 
 	out := cmd.OutOrStdout()
-	fmt.Fprintf(out, "Thread %s (%s)\n\n", path, thread.GUID)
+	if listing != nil {
+		listing.write(out, messages)
+		return nil
+	}
+	fmt.Fprintf(out, "Thread %s (%s)\n", path, thread.GUID)
+	if stats != nil {
+		fmt.Fprintln(out, formatThreadStats(stats))
+	}
+	fmt.Fprintln(out)
 
 	if len(messages) == 0 {
 		fmt.Fprintln(out, "No messages in thread")
@@ -607,11 +849,20 @@ func getThread(cmd *cobra.Command, ctx *CommandContext, thread *types.Thread, la
 	}
 
 	quotedMsgs := CollectQuotedMessages(ctx.DB, messages)
+	previews, err := replyPreviews(cmd, ctx, messages)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+	withCommits, _ := cmd.Flags().GetBool("with-commits")
 	lines := FormatMessageListAccordion(messages, AccordionOptions{
-		ShowAll:     showAll,
-		ProjectName: projectName,
-		AgentBases:  agentBases,
-		QuotedMsgs:  quotedMsgs,
+		ShowAll:       showAll,
+		ProjectName:   projectName,
+		AgentBases:    agentBases,
+		QuotedMsgs:    quotedMsgs,
+		Parents:       replyParentMap(parents),
+		Previews:      previews,
+		ExpandCommits: withCommits,
+		MarkdownWidth: mdWidth,
 	})
 	for _, line := range lines {
 		fmt.Fprintln(out, line)
@@ -619,8 +870,27 @@ func getThread(cmd *cobra.Command, ctx *CommandContext, thread *types.Thread, la
 	return nil
 }
 
+// formatThreadStats renders the one-line thread header: participants with
+// message counts, then creation date, owner, subscribers, and pins.
+func formatThreadStats(stats *types.ThreadStats) string {
+	participants := make([]string, 0, len(stats.Participants))
+	for _, p := range stats.Participants {
+		participants = append(participants, fmt.Sprintf("@%s %d", p.AgentID, p.Messages))
+	}
+	parts := []string{"no messages yet"}
+	if len(participants) > 0 {
+		parts = []string{strings.Join(participants, ", ")}
+	}
+	parts = append(parts, "created "+time.Unix(stats.CreatedAt, 0).Format("2006-01-02"))
+	if stats.Owner != nil && *stats.Owner != "" {
+		parts = append(parts, "owner @"+*stats.Owner)
+	}
+	parts = append(parts, fmt.Sprintf("%d subscriber(s)", stats.Subscribers), fmt.Sprintf("%d pin(s)", stats.Pins))
+	return strings.Join(parts, " · ")
+}
+
 // getMessage displays a single message.
-func getMessage(cmd *cobra.Command, ctx *CommandContext, msg *types.Message, projectName string, agentBases map[string]struct{}) error {
+func getMessage(cmd *cobra.Command, ctx *CommandContext, msg *types.Message, projectName string, agentBases map[string]struct{}, mdWidth int) error {
 	showReplies, _ := cmd.Flags().GetBool("replies")
 
 	if ctx.JSONMode {
@@ -636,7 +906,23 @@ func getMessage(cmd *cobra.Command, ctx *CommandContext, msg *types.Message, pro
 	}
 
 	out := cmd.OutOrStdout()
-	fmt.Fprintln(out, FormatMessageFull(*msg, projectName, agentBases))
+	listing, err := compactListingFlags(cmd, ctx.JSONMode)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+	if listing != nil {
+		messages := []types.Message{*msg}
+		if showReplies {
+			replies, err := db.GetReplies(ctx.DB, msg.ID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			messages = append(messages, replies...)
+		}
+		listing.write(out, messages)
+		return nil
+	}
+	fmt.Fprintln(out, formatMessageWithOptions(*msg, projectName, agentBases, messageFormat{markdownWidth: mdWidth}))
 
 	if showReplies {
 		replies, err := db.GetReplies(ctx.DB, msg.ID)
@@ -646,7 +932,7 @@ func getMessage(cmd *cobra.Command, ctx *CommandContext, msg *types.Message, pro
 		if len(replies) > 0 {
 			fmt.Fprintln(out, "\nReplies:")
 			for _, reply := range replies {
-				fmt.Fprintln(out, FormatMessage(reply, projectName, agentBases))
+				fmt.Fprintln(out, formatMessageWithOptions(reply, projectName, agentBases, messageFormat{truncate: true, markdownWidth: mdWidth}))
 			}
 		}
 	}
@@ -654,10 +940,10 @@ func getMessage(cmd *cobra.Command, ctx *CommandContext, msg *types.Message, pro
 }
 
 // getNotifications displays notifications for an agent.
-func getNotifications(cmd *cobra.Command, ctx *CommandContext, asRef, projectName string, agentBases map[string]struct{}, showAll bool) error {
+func getNotifications(cmd *cobra.Command, ctx *CommandContext, asRef, projectName string, agentBases map[string]struct{}, mdWidth int, showAll bool, since, until string) error {
 	agentID, err := resolveSubscriptionAgent(ctx, asRef)
 	if err != nil {
-		return writeCommandError(cmd, fmt.Errorf("--as is required for notifications"))
+		return writeCommandError(cmd, validationError("--as is required for notifications"))
 	}
 
 	agentBase := agentID
@@ -675,9 +961,22 @@ func getNotifications(cmd *cobra.Command, ctx *CommandContext, asRef, projectNam
 		Home:                  &allHomes,
 	}
 
+	// An explicit time window is a history lookup: it ignores read state and
+	// leaves watermarks alone.
+	history := since != "" || until != ""
+	if history {
+		mentionOpts.Limit = 0
+		if err := applyTimeBounds(ctx.DB, since, until, mentionOpts); err != nil {
+			return writeCommandError(cmd, err)
+		}
+	}
+
 	// Check ghost cursor for session-aware unread logic
 	useGhostCursorBoundary := false
 	mentionGhostCursor, _ := db.GetGhostCursor(ctx.DB, agentBase, "room")
+	if history {
+		mentionGhostCursor = nil
+	}
 	if mentionGhostCursor != nil && mentionGhostCursor.SessionAckAt == nil {
 		msg, msgErr := db.GetMessage(ctx.DB, mentionGhostCursor.MessageGUID)
 		if msgErr == nil && msg != nil {
@@ -687,7 +986,7 @@ func getNotifications(cmd *cobra.Command, ctx *CommandContext, asRef, projectNam
 	}
 	// Fall back to watermark-based boundary if no ghost cursor
 	// This handles users and agents without ghost cursors
-	if !useGhostCursorBoundary {
+	if !useGhostCursorBoundary && !history {
 		mentionWatermark, _ := db.GetReadTo(ctx.DB, agentBase, "mentions")
 		if mentionWatermark != nil {
 			mentionOpts.Since = &types.MessageCursor{GUID: mentionWatermark.MessageGUID, TS: mentionWatermark.MessageTS}
@@ -729,6 +1028,19 @@ func getNotifications(cmd *cobra.Command, ctx *CommandContext, asRef, projectNam
 		}
 		return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
 	}
+	listing, err := compactListingFlags(cmd, ctx.JSONMode)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+	if listing != nil {
+		listing.write(cmd.OutOrStdout(), filtered)
+		return nil
+	}
+
+	previews, err := replyPreviews(cmd, ctx, filtered)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
 
 	out := cmd.OutOrStdout()
 	fmt.Fprintf(out, "Notifications for @%s\n\n", agentBase)
@@ -753,7 +1065,7 @@ func getNotifications(cmd *cobra.Command, ctx *CommandContext, asRef, projectNam
 		if len(direct) > 0 {
 			fmt.Fprintf(out, "Recent @%s:\n", agentBase)
 			for _, msg := range direct {
-				fmt.Fprintln(out, FormatMessage(msg, projectName, agentBases))
+				fmt.Fprintln(out, formatMessageWithPreview(msg, projectName, agentBases, previews, mdWidth))
 			}
 		}
 
@@ -763,7 +1075,7 @@ func getNotifications(cmd *cobra.Command, ctx *CommandContext, asRef, projectNam
 			}
 			fmt.Fprintln(out, "You were FYI'd here:")
 			for _, msg := range fyi {
-				fmt.Fprintln(out, FormatMessage(msg, projectName, agentBases))
+				fmt.Fprintln(out, formatMessageWithPreview(msg, projectName, agentBases, previews, mdWidth))
 			}
 		}
 
@@ -790,7 +1102,7 @@ func getNotifications(cmd *cobra.Command, ctx *CommandContext, asRef, projectNam
 	}
 
 	// Mark messages as read and update watermark
-	if len(filtered) > 0 {
+	if len(filtered) > 0 && !history {
 		ids := make([]string, 0, len(filtered))
 		for _, msg := range filtered {
 			ids = append(ids, msg.ID)
@@ -824,6 +1136,71 @@ func parseOptionalInt(value string, fallback int) int {
 	return parsed
 }
 
+// roomThreadInterleave places a one-line entry for each top-level thread with
+// a visible anchor that was active during the room window, keyed by the index
+// of the first room message after the thread's last activity (get
+// --with-threads). Counts run from the viewer's read marker for the thread;
+// with no viewer they cover the window.
+func roomThreadInterleave(ctx *CommandContext, viewer string, messages []types.Message) (map[int][]string, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	options := &types.ThreadQueryOptions{}
+	if viewer != "" {
+		options.Viewer = &viewer
+	}
+	threads, err := db.GetThreads(ctx.DB, options)
+	if err != nil {
+		return nil, err
+	}
+
+	windowStart := messages[0].TS
+	interleave := map[int][]string{}
+	for _, thread := range threads {
+		if thread.ParentThread != nil || thread.AnchorMessageGUID == nil || thread.AnchorHidden {
+			continue
+		}
+		if viewer == "" && thread.Type == types.ThreadTypeDM {
+			continue
+		}
+		if thread.LastActivityAt == nil || *thread.LastActivityAt < windowStart {
+			continue
+		}
+		anchor, err := db.GetMessage(ctx.DB, *thread.AnchorMessageGUID)
+		if err != nil || anchor == nil {
+			continue
+		}
+
+		var since *types.MessageCursor
+		if viewer != "" {
+			readTo, _ := db.GetReadTo(ctx.DB, viewer, thread.GUID)
+			if readTo != nil {
+				since = &types.MessageCursor{GUID: readTo.MessageGUID, TS: readTo.MessageTS}
+			}
+		}
+		home := thread.GUID
+		threadMessages, err := db.GetMessages(ctx.DB, &types.MessageQueryOptions{Home: &home, Since: since})
+		if err != nil {
+			return nil, err
+		}
+		count := 0
+		for _, msg := range threadMessages {
+			if viewer != "" || msg.TS >= windowStart {
+				count++
+			}
+		}
+
+		line := fmt.Sprintf("%s  thread #%s: %s", dim, thread.Name, truncateBody(anchor.Body, 60))
+		if count > 0 {
+			line += fmt.Sprintf(" (+%d new)", count)
+		}
+		last := *thread.LastActivityAt
+		pos := sort.Search(len(messages), func(i int) bool { return messages[i].TS > last })
+		interleave[pos] = append(interleave[pos], line+reset)
+	}
+	return interleave, nil
+}
+
 // ThreadActivityHint represents unread activity in a subscribed thread.
 type ThreadActivityHint struct {
 	ThreadGUID  string
@@ -888,6 +1265,11 @@ func getThreadActivityHints(ctx *CommandContext, agentID string) ([]ThreadActivi
 			continue
 		}
 
+		// mentions-level followers only see messages that mention them.
+		if sub, _ := db.GetThreadSubscription(ctx.DB, thread.GUID, agentID); sub != nil && sub.Level == types.SubscriptionMentions {
+			messages = filterMentioning(messages, agentID)
+		}
+
 		if len(messages) == 0 {
 			continue
 		}
@@ -905,6 +1287,20 @@ func getThreadActivityHints(ctx *CommandContext, agentID string) ([]ThreadActivi
 	return hints, nil
 }
 
+// filterMentioning keeps messages whose mentions match agentID.
+func filterMentioning(messages []types.Message, agentID string) []types.Message {
+	var kept []types.Message
+	for _, msg := range messages {
+		for _, mention := range msg.Mentions {
+			if core.MatchesMention(agentID, mention) {
+				kept = append(kept, msg)
+				break
+			}
+		}
+	}
+	return kept
+}
+
 // formatThreadHint formats a single thread activity hint.
 func formatThreadHint(hint ThreadActivityHint) string {
 	suffix := ""
@@ -0,0 +1,125 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+)
+
+func TestGetRoomInterleavesThreadAnchors(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "design")
+	runFray(t, "thread", "ops")
+	runFray(t, "thread", "quiet")
+
+	r1 := postJSON(t, "post", "--as", "alice", "room message one")
+	r2 := postJSON(t, "post", "--as", "alice", "room message two")
+	r3 := postJSON(t, "post", "--as", "alice", "room message three")
+	d1 := postJSON(t, "post", "--as", "alice", "design", "Pick the storage layout\nlonger rationale below")
+	d2 := postJSON(t, "post", "--as", "bob", "design", "sqlite for the cache, jsonl on disk")
+	d3 := postJSON(t, "post", "--as", "alice", "design", "agreed, writing it up now")
+	o1 := postJSON(t, "post", "--as", "alice", "ops", "Rotate the deploy keys")
+	q1 := postJSON(t, "post", "--as", "alice", "quiet", "Old decision nobody touched")
+	runFray(t, "anchor", "design", d1["id"].(string))
+	runFray(t, "anchor", "ops", o1["id"].(string))
+	runFray(t, "anchor", "ops", "--hide")
+	runFray(t, "anchor", "quiet", q1["id"].(string))
+
+	spreadTimestamps(t, projectDir, q1["id"], r1["id"], o1["id"], r2["id"], d1["id"], d2["id"], d3["id"], r3["id"])
+	dbConn := openProjectDB(t, projectDir)
+	for name, ts := range map[string]int64{"quiet": 1000, "ops": 1002, "design": 1006} {
+		if _, err := dbConn.Exec("UPDATE fray_threads SET last_activity_at = ? WHERE name = ?", ts, name); err != nil {
+			t.Fatalf("set last activity: %v", err)
+		}
+	}
+	design, err := db.GetThreadByName(dbConn, "design", nil)
+	if err != nil || design == nil {
+		t.Fatalf("get thread: %v", err)
+	}
+	// bob has read up to the anchor, so two design messages are new.
+	if err := db.SetReadTo(dbConn, "bob", design.GUID, d1["id"].(string), 1004); err != nil {
+		t.Fatalf("set read_to: %v", err)
+	}
+	_ = dbConn.Close()
+
+	output := runFray(t, "get", "--as", "bob", "--room", "3")
+	entry := strings.Index(output, "thread #design: Pick the storage layout (+2 new)")
+	if entry < 0 {
+		t.Fatalf("expected design entry with count, got %q", output)
+	}
+	if !(strings.Index(output, "room message two") < entry && entry < strings.Index(output, "room message three")) {
+		t.Fatalf("expected design entry between its surrounding room messages, got %q", output)
+	}
+	if strings.Contains(output, "thread #ops") {
+		t.Fatalf("expected hidden anchor to be excluded, got %q", output)
+	}
+	if strings.Contains(output, "thread #quiet") {
+		t.Fatalf("expected thread idle before the window to be excluded, got %q", output)
+	}
+
+	output = runFray(t, "get", "--as", "bob", "--room", "3", "--with-threads=false")
+	if strings.Contains(output, "thread #design") {
+		t.Fatalf("expected --with-threads=false to drop entries, got %q", output)
+	}
+	output = runFray(t, "get", "--as", "bob", "--room", "3", "--json")
+	if strings.Contains(output, "thread #design") {
+		t.Fatalf("expected no entries in JSON output, got %q", output)
+	}
+}
+
+func TestGetRoomThreadCountsWithoutViewer(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "design")
+
+	r1 := postJSON(t, "post", "--as", "alice", "room message one")
+	d1 := postJSON(t, "post", "--as", "alice", "design", "Pick the storage layout")
+	d2 := postJSON(t, "post", "--as", "alice", "design", "sqlite for the cache, jsonl on disk")
+	r2 := postJSON(t, "post", "--as", "alice", "room message two")
+	runFray(t, "anchor", "design", d1["id"].(string))
+
+	// Only d2 falls inside the two-message room window.
+	spreadTimestamps(t, projectDir, d1["id"], r1["id"], d2["id"], r2["id"])
+	dbConn := openProjectDB(t, projectDir)
+	if _, err := dbConn.Exec("UPDATE fray_threads SET last_activity_at = 1002 WHERE name = 'design'"); err != nil {
+		t.Fatalf("set last activity: %v", err)
+	}
+	_ = dbConn.Close()
+
+	output := runFray(t, "get", "--last", "2")
+	entry := strings.Index(output, "thread #design: Pick the storage layout (+1 new)")
+	if entry < 0 {
+		t.Fatalf("expected design entry counted over the window, got %q", output)
+	}
+	if !(strings.Index(output, "room message one") < entry && entry < strings.Index(output, "room message two")) {
+		t.Fatalf("expected design entry between its surrounding room messages, got %q", output)
+	}
+}
+
+func TestGetSinceDurationWithUntil(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	old := postJSON(t, "post", "--as", "alice", "two days old")
+	recent := postJSON(t, "post", "--as", "alice", "posted just now")
+
+	now := time.Now()
+	dbConn := openProjectDB(t, projectDir)
+	for id, ts := range map[any]int64{old["id"]: now.Add(-48 * time.Hour).Unix(), recent["id"]: now.Unix()} {
+		if _, err := dbConn.Exec("UPDATE fray_messages SET ts = ? WHERE guid = ?", ts, id); err != nil {
+			t.Fatalf("set ts: %v", err)
+		}
+	}
+	_ = dbConn.Close()
+
+	output := runFray(t, "get", "--since", "24h")
+	if !strings.Contains(output, "posted just now") || strings.Contains(output, "two days old") {
+		t.Fatalf("expected only the recent message, got %q", output)
+	}
+
+	// --until used to drop --since entirely.
+	until := now.Add(time.Hour).Format(time.RFC3339)
+	output = runFray(t, "get", "--since", "24h", "--until", until)
+	if !strings.Contains(output, "posted just now") || strings.Contains(output, "two days old") {
+		t.Fatalf("expected --since to apply alongside --until, got %q", output)
+	}
+}
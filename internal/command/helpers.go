@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
 	"github.com/adamavenir/fray/internal/types"
 )
@@ -67,6 +68,29 @@ func parseDuration(value string) (int64, error) {
 	}
 }
 
+// applyTimeBounds resolves --since/--until values into inclusive SinceTS and
+// UntilTS bounds. Empty values leave that side open.
+func applyTimeBounds(dbConn *sql.DB, since, until string, options *types.MessageQueryOptions) error {
+	if since != "" {
+		ts, err := core.ParseTimeBound(dbConn, since)
+		if err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+		options.SinceTS = &ts
+	}
+	if until != "" {
+		ts, err := core.ParseTimeBound(dbConn, until)
+		if err != nil {
+			return fmt.Errorf("--until: %w", err)
+		}
+		options.UntilTS = &ts
+	}
+	if options.SinceTS != nil && options.UntilTS != nil && *options.SinceTS > *options.UntilTS {
+		return fmt.Errorf("--since is after --until")
+	}
+	return nil
+}
+
 func stripHash(value string) string {
 	return strings.TrimPrefix(value, "#")
 }
@@ -154,6 +178,14 @@ func formatOptionalValue(value *string) string {
 	return *value
 }
 
+// formatListOrNone joins labels with commas, or returns "none".
+func formatListOrNone(labels []string) string {
+	if len(labels) == 0 {
+		return "none"
+	}
+	return strings.Join(labels, ", ")
+}
+
 func formatOptionalString(value string) string {
 	if value == "" {
 		return "--"
@@ -208,6 +240,21 @@ func filterEventMessages(messages []types.Message) []types.Message {
 	return filtered
 }
 
+// filterCodeMessages keeps messages whose bodies do (withCode) or do not
+// contain a fenced code block. A nil withCode keeps everything.
+func filterCodeMessages(messages []types.Message, withCode *bool) []types.Message {
+	if withCode == nil {
+		return messages
+	}
+	filtered := make([]types.Message, 0, len(messages))
+	for _, msg := range messages {
+		if core.HasCodeBlock(msg.Body) == *withCode {
+			filtered = append(filtered, msg)
+		}
+	}
+	return filtered
+}
+
 func filterDeletedMessages(messages []types.Message) []types.Message {
 	filtered := make([]types.Message, 0, len(messages))
 	for _, msg := range messages {
@@ -38,7 +38,7 @@ func NewHereCmd() *cobra.Command {
 				}
 			} else {
 				staleHours := 4
-				if value, err := db.GetConfig(ctx.DB, "stale_hours"); err == nil && value != "" {
+				if value, err := getConfigValue(ctx, "stale_hours"); err == nil && value != "" {
 					staleHours = parseInt(value, staleHours)
 				}
 				agents, err = db.GetActiveAgents(ctx.DB, staleHours)
@@ -62,9 +62,18 @@ func NewHereCmd() *cobra.Command {
 				return writeCommandError(cmd, err)
 			}
 
+			workingList, err := db.GetWorkingAgents(ctx.DB, db.WorkingTTL(ctx.DB), time.Now().UnixMilli())
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			working := make(map[string]types.AgentWorking, len(workingList))
+			for _, entry := range workingList {
+				working[entry.AgentID] = entry
+			}
+
 			if ctx.JSONMode {
 				payload := map[string]any{
-					"agents": buildHerePayload(agents, claimCounts, messageCounts, allRoles),
+					"agents": buildHerePayload(agents, claimCounts, messageCounts, allRoles, working),
 					"total":  len(agents),
 				}
 				return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
@@ -97,6 +106,9 @@ func NewHereCmd() *cobra.Command {
 				}
 				fmt.Fprintf(out, "  @%s%s%s%s\n", agent.AgentID, roleInfo, claimInfo, status)
 				fmt.Fprintf(out, "    last seen: %s\n", formatRelative(agent.LastSeen))
+				if entry, ok := working[agent.AgentID]; ok {
+					fmt.Fprintf(out, "    %s (%s)\n", formatWorkingLine(entry), formatRelative(entry.StartedAt/1000))
+				}
 			}
 
 			return nil
@@ -107,7 +119,7 @@ func NewHereCmd() *cobra.Command {
 	return cmd
 }
 
-func buildHerePayload(agents []types.Agent, claimCounts map[string]int64, messageCounts map[string]int64, allRoles map[string]*types.AgentRoles) []map[string]any {
+func buildHerePayload(agents []types.Agent, claimCounts map[string]int64, messageCounts map[string]int64, allRoles map[string]*types.AgentRoles, working map[string]types.AgentWorking) []map[string]any {
 	payload := make([]map[string]any, 0, len(agents))
 	for _, agent := range agents {
 		entry := map[string]any{
@@ -122,6 +134,9 @@ func buildHerePayload(agents []types.Agent, claimCounts map[string]int64, messag
 			entry["roles_held"] = roles.Held
 			entry["roles_playing"] = roles.Playing
 		}
+		if ping, ok := working[agent.AgentID]; ok {
+			entry["working"] = ping
+		}
 		payload = append(payload, entry)
 	}
 	return payload
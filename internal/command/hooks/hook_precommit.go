@@ -1,11 +1,13 @@
 package hooks
 
 import (
+	"database/sql"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
@@ -18,7 +20,7 @@ import (
 func NewHookPrecommitCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "hook-precommit",
-		Short: "Git pre-commit hook for file claim conflict detection",
+		Short: "Git pre-commit hook for file and branch claim conflict detection",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			exitCode := runHookPrecommit(cmd)
 			os.Exit(exitCode)
@@ -47,40 +49,62 @@ func runHookPrecommit(cmd *cobra.Command) int {
 		return 0
 	}
 
-	stagedFiles, err := gitStagedFiles(project.Root)
-	if err != nil || len(stagedFiles) == 0 {
-		return 0
+	return precommitCheck(dbConn, project.Root, agentID, cmd.ErrOrStderr())
+}
+
+// precommitCheck reports staged files and the current branch claimed by
+// other agents. Returns the hook exit code.
+func precommitCheck(dbConn *sql.DB, projectRoot, agentID string, errOut io.Writer) int {
+	var fileConflicts []types.Claim
+	stagedFiles, err := gitStagedFiles(projectRoot)
+	if err == nil && len(stagedFiles) > 0 {
+		fileConflicts, _ = db.FindConflictingFileClaims(dbConn, stagedFiles, agentID)
+	}
+
+	var branchConflict *types.Claim
+	if branch, err := gitCurrentBranch(projectRoot); err == nil && branch != "" && branch != "HEAD" {
+		if claim, err := db.GetClaim(dbConn, types.ClaimTypeBranch, branch); err == nil && claim != nil && claim.AgentID != agentID {
+			if claim.ExpiresAt == nil || *claim.ExpiresAt >= time.Now().Unix() {
+				branchConflict = claim
+			}
+		}
 	}
 
-	conflicts, err := db.FindConflictingFileClaims(dbConn, stagedFiles, agentID)
-	if err != nil || len(conflicts) == 0 {
+	if len(fileConflicts) == 0 && branchConflict == nil {
 		return 0
 	}
 
-	byAgent := groupClaimsByAgent(conflicts, stagedFiles)
-	printPrecommitConflicts(cmd.ErrOrStderr(), byAgent)
+	if len(fileConflicts) > 0 {
+		byAgent := groupClaimsByAgent(fileConflicts, stagedFiles)
+		printPrecommitConflicts(errOut, byAgent)
+	}
+	if branchConflict != nil {
+		printBranchConflict(errOut, *branchConflict)
+	}
 
 	strictMode := false
-	if raw, err := db.GetConfig(dbConn, "precommit_strict"); err == nil {
-		strictMode = raw == "true"
+	if raw, _, err := db.GetResolvedConfig(dbConn, projectRoot, "precommit_strict"); err == nil {
+		strictMode, _ = db.ParseConfigBool(raw)
 	}
 
 	if strictMode {
-		fmt.Fprintln(cmd.ErrOrStderr(), "Commit blocked (precommit_strict mode enabled).")
-		fmt.Fprintln(cmd.ErrOrStderr(), "Use \"fray config precommit_strict false\" to disable strict mode.")
-		fmt.Fprintln(cmd.ErrOrStderr(), "")
+		fmt.Fprintln(errOut, "Commit blocked (precommit_strict mode enabled).")
+		fmt.Fprintln(errOut, "Use \"fray config precommit_strict false\" to disable strict mode.")
+		fmt.Fprintln(errOut, "")
 		return 1
 	}
 
-	fmt.Fprintln(cmd.ErrOrStderr(), "Proceeding with commit (advisory mode).")
-	fmt.Fprintln(cmd.ErrOrStderr(), "Use \"fray config precommit_strict true\" to block commits with conflicts.")
-	fmt.Fprintln(cmd.ErrOrStderr(), "")
+	fmt.Fprintln(errOut, "Proceeding with commit (advisory mode).")
+	fmt.Fprintln(errOut, "Use \"fray config precommit_strict true\" to block commits with conflicts.")
+	fmt.Fprintln(errOut, "")
 	return 0
 }
 
 type claimMatch struct {
-	Pattern string
-	Files   []string
+	Pattern     string
+	Files       []string
+	Note        *string
+	MessageGUID *string
 }
 
 func groupClaimsByAgent(conflicts []types.Claim, stagedFiles []string) map[string][]claimMatch {
@@ -88,8 +112,10 @@ func groupClaimsByAgent(conflicts []types.Claim, stagedFiles []string) map[strin
 	for _, claim := range conflicts {
 		matches := matchClaimFiles(claim.Pattern, stagedFiles)
 		byAgent[claim.AgentID] = append(byAgent[claim.AgentID], claimMatch{
-			Pattern: claim.Pattern,
-			Files:   matches,
+			Pattern:     claim.Pattern,
+			Files:       matches,
+			Note:        claim.Reason,
+			MessageGUID: claim.MessageGUID,
 		})
 	}
 	return byAgent
@@ -123,9 +149,10 @@ func printPrecommitConflicts(errOut io.Writer, byAgent map[string][]claimMatch)
 				for _, file := range claim.Files {
 					fmt.Fprintf(errOut, "    %s (claimed via %s)\n", file, claim.Pattern)
 				}
-				continue
+			} else {
+				fmt.Fprintf(errOut, "    pattern: %s\n", claim.Pattern)
 			}
-			fmt.Fprintf(errOut, "    pattern: %s\n", claim.Pattern)
+			printClaimContext(errOut, "      ", claim.Note, claim.MessageGUID)
 		}
 	}
 
@@ -135,6 +162,38 @@ func printPrecommitConflicts(errOut io.Writer, byAgent map[string][]claimMatch)
 	fmt.Fprintln(errOut, "")
 }
 
+func printBranchConflict(errOut io.Writer, claim types.Claim) {
+	fmt.Fprintln(errOut, "")
+	fmt.Fprintln(errOut, "BRANCH CLAIM CONFLICT DETECTED")
+	fmt.Fprintln(errOut, "")
+	fmt.Fprintf(errOut, "  Branch %s is claimed by @%s.\n", claim.Pattern, claim.AgentID)
+	printClaimContext(errOut, "    ", claim.Reason, claim.MessageGUID)
+	fmt.Fprintln(errOut, "")
+	fmt.Fprintln(errOut, "Consider coordinating with this agent before committing.")
+	fmt.Fprintln(errOut, "")
+}
+
+// printClaimContext shows why a claim was taken so the committer can read
+// the context before coordinating.
+func printClaimContext(errOut io.Writer, indent string, note, messageGUID *string) {
+	if note != nil && *note != "" {
+		fmt.Fprintf(errOut, "%snote: %s\n", indent, *note)
+	}
+	if messageGUID != nil && *messageGUID != "" {
+		fmt.Fprintf(errOut, "%ssee: fray get %s\n", indent, *messageGUID)
+	}
+}
+
+func gitCurrentBranch(projectRoot string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = projectRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 func gitStagedFiles(projectRoot string) ([]string, error) {
 	cmd := exec.Command("git", "diff", "--cached", "--name-only")
 	cmd.Dir = projectRoot
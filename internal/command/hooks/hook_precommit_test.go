@@ -1,9 +1,14 @@
 package hooks
 
 import (
+	"bytes"
+	"os/exec"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
 	"github.com/adamavenir/fray/internal/types"
 )
 
@@ -38,3 +43,68 @@ func TestGroupClaimsByAgent(t *testing.T) {
 		t.Fatalf("unexpected bob group: %#v", grouped["bob"])
 	}
 }
+
+func TestPrecommitCheckBranchClaims(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"checkout", "-q", "-b", "feature/auth"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, output)
+		}
+	}
+
+	dbConn := testutil.NewProject(t).OpenDB(t, db.OpenDatabase, db.InitSchema)
+	if _, err := db.CreateClaim(dbConn, types.ClaimInput{AgentID: "dev", ClaimType: types.ClaimTypeBranch, Pattern: "feature/auth"}); err != nil {
+		t.Fatalf("create claim: %v", err)
+	}
+
+	var out bytes.Buffer
+	if code := precommitCheck(dbConn, repo, "dev", &out); code != 0 || out.Len() != 0 {
+		t.Fatalf("expected owner to commit quietly, got %d: %q", code, out.String())
+	}
+
+	if code := precommitCheck(dbConn, repo, "pm", &out); code != 0 {
+		t.Fatalf("expected advisory mode to allow commit, got %d", code)
+	}
+	if !strings.Contains(out.String(), "Branch feature/auth is claimed by @dev") {
+		t.Fatalf("expected branch conflict warning, got %q", out.String())
+	}
+
+	if err := db.SetConfig(dbConn, "precommit_strict", "true"); err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+	out.Reset()
+	if code := precommitCheck(dbConn, repo, "pm", &out); code != 1 {
+		t.Fatalf("expected strict mode to block, got %d: %q", code, out.String())
+	}
+}
+
+func TestPrecommitWarningShowsClaimContext(t *testing.T) {
+	note := "refactoring token refresh"
+	msgID := "msg-abc12345"
+	claims := []types.Claim{
+		{AgentID: "alice", ClaimType: types.ClaimTypeFile, Pattern: "src/auth.ts", Reason: &note, MessageGUID: &msgID},
+		{AgentID: "bob", ClaimType: types.ClaimTypeFile, Pattern: "README.md"},
+	}
+
+	var out bytes.Buffer
+	printPrecommitConflicts(&out, groupClaimsByAgent(claims, []string{"src/auth.ts", "README.md"}))
+	text := out.String()
+	if !strings.Contains(text, "note: "+note) || !strings.Contains(text, "see: fray get "+msgID) {
+		t.Fatalf("expected note and message link in warning, got %q", text)
+	}
+	if strings.Count(text, "note:") != 1 {
+		t.Fatalf("expected no note for a claim without one, got %q", text)
+	}
+
+	out.Reset()
+	printBranchConflict(&out, types.Claim{AgentID: "alice", ClaimType: types.ClaimTypeBranch, Pattern: "feature/auth", Reason: &note})
+	if !strings.Contains(out.String(), "note: "+note) {
+		t.Fatalf("expected note in branch warning, got %q", out.String())
+	}
+}
@@ -31,8 +31,9 @@ func NewHookPrecompactCmd() *cobra.Command {
 func buildPrecompactContext(agentID string) string {
 	return fmt.Sprintf(`[fray] Context compacting. Preserve your work:
 1. fray post %s/notes "# Handoff ..." --as %s
-2. bd close <completed-issues>
-3. fray bye %s
+2. fray post --standup "done: ... next: ... blocked: ..." --as %s
+3. bd close <completed-issues>
+4. fray bye %s
 
-Or run /land for full checklist.`, agentID, agentID, agentID)
+Or run /land for full checklist (post its standup with --standup).`, agentID, agentID, agentID, agentID)
 }
@@ -39,7 +39,7 @@ func NewHookSessionCmd() *cobra.Command {
 
 			agentID := os.Getenv("FRAY_AGENT_ID")
 			if agentID == "" {
-				output.AdditionalContext = buildHookRegistrationContext(dbConn)
+				output.AdditionalContext = buildHookRegistrationContext(dbConn, project.DBPath)
 				return writeHookOutput(cmd, output)
 			}
 
@@ -52,9 +52,9 @@ func NewHookSessionCmd() *cobra.Command {
 	return cmd
 }
 
-func buildHookRegistrationContext(dbConn *sql.DB) string {
+func buildHookRegistrationContext(dbConn *sql.DB, projectPath string) string {
 	staleHours := 4
-	if raw, err := db.GetConfig(dbConn, "stale_hours"); err == nil {
+	if raw, _, err := db.GetResolvedConfig(dbConn, projectPath, "stale_hours"); err == nil {
 		staleHours = parseInt(raw, 4)
 	}
 
@@ -88,8 +88,9 @@ func NewInstallNotifierCmd() *cobra.Command {
 	var force bool
 
 	cmd := &cobra.Command{
-		Use:   "install-notifier",
-		Short: "Install Fray-Notifier.app for macOS notifications with custom icon",
+		Use:         "install-notifier",
+		Short:       "Install Fray-Notifier.app for macOS notifications with custom icon",
+		Annotations: map[string]string{noJSONAnnotation: "true"},
 		Long: `Downloads and configures a custom notification app for macOS.
 
 This creates ~/Applications/Fray-Notifier.app which displays the fray icon
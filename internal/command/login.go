@@ -0,0 +1,101 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// NewLoginCmd creates the login command.
+func NewLoginCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "login <name>",
+		Short: "Record who the human at this checkout is",
+		Long: `Record your name as this checkout's human identity. It is stored in the
+local config layer (.fray/local/config.json), so teammates sharing the project
+keep their own.
+
+fray answer, react and post use it when neither --as nor FRAY_AGENT_ID is
+set, and fray chat uses it instead of prompting. It replaces the chat-only
+username key; an existing username is copied over the first time fray needs
+it.
+
+Examples:
+  fray login adam
+  fray answer          # answers questions for @adam`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			name := strings.TrimPrefix(strings.TrimSpace(args[0]), "@")
+			if !core.IsValidAgentID(name) {
+				return writeCommandError(cmd, validationError("invalid name: %s", args[0]))
+			}
+			agent, err := db.GetAgent(ctx.DB, name)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if agent != nil {
+				return writeCommandError(cmd, conflictError("@%s is a registered agent; pick another name", name))
+			}
+
+			previous, err := humanIdentity(ctx)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.SetConfigLayer(ctx.DB, ctx.Project.DBPath, db.ConfigScopeLocal, db.HumanIdentityConfigKey, name); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"identity": name,
+					"previous": optionalString(previous),
+				})
+			}
+			if previous != "" && previous != name {
+				fmt.Fprintf(cmd.OutOrStdout(), "Logged in as @%s (was @%s)\n", name, previous)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged in as @%s\n", name)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// humanIdentity returns the human at this checkout, as set by fray login (or
+// a migrated chat username), or "" when nobody has logged in.
+func humanIdentity(ctx *CommandContext) (string, error) {
+	return db.HumanIdentity(ctx.DB, ctx.Project.DBPath)
+}
+
+// identityRef returns asRef, then FRAY_AGENT_ID (set for daemon-spawned
+// agents), then the logged-in human. The error names every way to identify
+// when none is available.
+func identityRef(ctx *CommandContext, asRef string) (string, error) {
+	if asRef != "" {
+		return asRef, nil
+	}
+	if agentID := strings.TrimSpace(os.Getenv("FRAY_AGENT_ID")); agentID != "" {
+		return agentID, nil
+	}
+	identity, err := humanIdentity(ctx)
+	if err != nil {
+		return "", err
+	}
+	if identity == "" {
+		return "", validationError("--as is required (or set FRAY_AGENT_ID, or run 'fray login <name>')")
+	}
+	return identity, nil
+}
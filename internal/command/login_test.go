@@ -0,0 +1,100 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+)
+
+func TestAnswerIdentityFallbackChain(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	project, err := core.DiscoverProject(projectDir)
+	if err != nil {
+		t.Fatalf("discover project: %v", err)
+	}
+	ctx := &CommandContext{DB: openProjectDB(t, projectDir), Project: project}
+	defer ctx.DB.Close()
+
+	if _, err := answerIdentity(ctx, ""); err == nil || !strings.Contains(err.Error(), "fray login") {
+		t.Fatalf("expected a login hint with nothing configured, got %v", err)
+	}
+
+	// A legacy chat username is migrated into the local identity key.
+	runFray(t, "config", "username", "adam")
+	if got, err := answerIdentity(ctx, ""); err != nil || got != "adam" {
+		t.Fatalf("expected legacy username adam, got %q (%v)", got, err)
+	}
+	local, err := db.GetConfigLayer(ctx.DB, project.DBPath, db.ConfigScopeLocal)
+	if err != nil || local[db.HumanIdentityConfigKey] != "adam" {
+		t.Fatalf("expected username migrated to local identity, got %v (%v)", local, err)
+	}
+
+	// fray login takes over from the migrated value; --as still wins.
+	runFray(t, "login", "@bea")
+	if got, err := answerIdentity(ctx, ""); err != nil || got != "bea" {
+		t.Fatalf("expected logged-in bea, got %q (%v)", got, err)
+	}
+	if got, err := answerIdentity(ctx, "alice"); err != nil || got != "alice" {
+		t.Fatalf("expected --as alice to win, got %q (%v)", got, err)
+	}
+}
+
+func TestMigrateUsernameConfigKeepsExistingIdentity(t *testing.T) {
+	projectDir := newFlowProject(t)
+	runFray(t, "config", "identity", "bea", "--scope", "global")
+	runFray(t, "config", "username", "adam")
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	project, err := core.DiscoverProject(projectDir)
+	if err != nil {
+		t.Fatalf("discover project: %v", err)
+	}
+	if migrated, err := db.MigrateUsernameConfig(dbConn, project.DBPath); err != nil || migrated != "" {
+		t.Fatalf("expected no migration over an existing identity, got %q (%v)", migrated, err)
+	}
+	if got, err := db.HumanIdentity(dbConn, project.DBPath); err != nil || got != "bea" {
+		t.Fatalf("expected identity bea, got %q (%v)", got, err)
+	}
+}
+
+func TestLoginIdentityForPostAndReact(t *testing.T) {
+	newFlowProject(t, "alice")
+	t.Setenv("FRAY_AGENT_ID", "")
+
+	if _, err := executeCommand(NewRootCmd("test"), "post", "hello from nobody in particular"); err == nil {
+		t.Fatal("expected post without --as or login to fail")
+	}
+	if _, err := executeCommand(NewRootCmd("test"), "login", "alice"); err == nil {
+		t.Fatal("expected login as a registered agent to fail")
+	}
+
+	runFray(t, "login", "adam")
+	posted := postJSON(t, "post", "hello from the human at the keyboard")
+	if posted["from"] != "adam" {
+		t.Fatalf("expected post as adam, got %v", posted)
+	}
+	output := runFray(t, "react", "👍", posted["id"].(string), "--json")
+	if !strings.Contains(output, `"from":"adam"`) {
+		t.Fatalf("expected reaction from adam, got %q", output)
+	}
+}
+
+func TestAgentEnvWinsOverLogin(t *testing.T) {
+	newFlowProject(t, "alice")
+	runFray(t, "login", "adam")
+
+	t.Setenv("FRAY_AGENT_ID", "alice")
+	posted := postJSON(t, "post", "spawned agents post as themselves")
+	if posted["from"] != "alice" {
+		t.Fatalf("expected FRAY_AGENT_ID to win over the login, got %v", posted["from"])
+	}
+
+	t.Setenv("FRAY_AGENT_ID", "")
+	posted = postJSON(t, "post", "humans fall back to the login")
+	if posted["from"] != "adam" {
+		t.Fatalf("expected the login without FRAY_AGENT_ID, got %v", posted["from"])
+	}
+}
@@ -0,0 +1,161 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mdHeaderRe = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+	mdBulletRe = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdNumberRe = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	mdBoldRe   = regexp.MustCompile(`\*\*([^*\n]+)\*\*|__([^_\n]+)__`)
+	mdItalicRe = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*\n]*)\*|(^|[^\w_])_([^_\s][^_\n]*)_`)
+	mdCodeRe   = regexp.MustCompile("`[^`\n]+`")
+)
+
+// markdownStyles are the lipgloss styles used for rendered bodies.
+type markdownStyles struct {
+	header lipgloss.Style
+	bold   lipgloss.Style
+	italic lipgloss.Style
+	code   lipgloss.Style
+}
+
+func newMarkdownStyles(r *lipgloss.Renderer) markdownStyles {
+	return markdownStyles{
+		header: r.NewStyle().Bold(true).Underline(true),
+		bold:   r.NewStyle().Bold(true),
+		italic: r.NewStyle().Italic(true),
+		code:   r.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")).Padding(0, 1),
+	}
+}
+
+var defaultMarkdownStyles = newMarkdownStyles(lipgloss.DefaultRenderer())
+
+// markdownWidthFor returns the terminal width message bodies are rendered to
+// as markdown, or zero to leave them as typed. Rendering is on when
+// --render-md or render_markdown=true asks for it and the output is a
+// terminal of known width. JSON and the terse listings always stay raw.
+func markdownWidthFor(cmd *cobra.Command, ctx *CommandContext, listing *compactListing) int {
+	if ctx.JSONMode || listing != nil {
+		return 0
+	}
+	enabled, _ := cmd.Flags().GetBool("render-md")
+	if !enabled {
+		value, _ := getConfigValue(ctx, "render_markdown")
+		enabled, _ = strconv.ParseBool(value)
+	}
+	if !enabled {
+		return 0
+	}
+	out, ok := cmd.OutOrStdout().(*os.File)
+	if !ok || !isTTY(out) {
+		return 0
+	}
+	width, _, err := term.GetSize(out.Fd())
+	if err != nil || width <= 0 {
+		return 0
+	}
+	return width
+}
+
+// renderMarkdownBody renders body for a terminal of the given width with
+// the configured styles. It returns body unchanged when width is unknown or
+// rendering fails, so a message is never lost to the renderer.
+func renderMarkdownBody(body string, width int, styles markdownStyles) (rendered string) {
+	if width <= 0 || body == "" {
+		return body
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			rendered = body
+		}
+	}()
+
+	lines := strings.Split(body, "\n")
+	out := make([]string, 0, len(lines))
+	next := 0
+	for _, block := range core.ParseCodeBlocks(body) {
+		out = append(out, renderMarkdownLines(lines[next:block.StartLine], styles)...)
+		if block.Closed {
+			out = append(out, renderCodeBox(lines[block.StartLine:block.EndLine+1], block.Language, width, styles))
+		} else {
+			// An unterminated fence stays as typed.
+			out = append(out, lines[block.StartLine:]...)
+		}
+		next = block.EndLine + 1
+	}
+	out = append(out, renderMarkdownLines(lines[next:], styles)...)
+	return strings.Join(out, "\n")
+}
+
+func renderMarkdownLines(lines []string, styles markdownStyles) []string {
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		switch {
+		case mdHeaderRe.MatchString(line):
+			match := mdHeaderRe.FindStringSubmatch(line)
+			out = append(out, styles.header.Render(renderInline(match[2], styles)))
+		case mdBulletRe.MatchString(line):
+			match := mdBulletRe.FindStringSubmatch(line)
+			out = append(out, "  "+match[1]+"• "+renderInline(match[2], styles))
+		case mdNumberRe.MatchString(line):
+			match := mdNumberRe.FindStringSubmatch(line)
+			out = append(out, "  "+match[1]+match[2]+" "+renderInline(match[3], styles))
+		default:
+			out = append(out, renderInline(line, styles))
+		}
+	}
+	return out
+}
+
+// renderInline applies bold and italic spans, leaving `code` spans alone.
+func renderInline(text string, styles markdownStyles) string {
+	var out strings.Builder
+	last := 0
+	for _, span := range mdCodeRe.FindAllStringIndex(text, -1) {
+		out.WriteString(renderEmphasis(text[last:span[0]], styles))
+		out.WriteString(text[span[0]:span[1]])
+		last = span[1]
+	}
+	out.WriteString(renderEmphasis(text[last:], styles))
+	return out.String()
+}
+
+func renderEmphasis(text string, styles markdownStyles) string {
+	text = mdBoldRe.ReplaceAllStringFunc(text, func(match string) string {
+		parts := mdBoldRe.FindStringSubmatch(match)
+		return styles.bold.Render(parts[1] + parts[2])
+	})
+	return mdItalicRe.ReplaceAllStringFunc(text, func(match string) string {
+		parts := mdItalicRe.FindStringSubmatch(match)
+		return parts[1] + parts[3] + styles.italic.Render(parts[2]+parts[4])
+	})
+}
+
+// renderCodeBox draws a fenced block (fences included in lines) in a
+// bordered box labelled with its language. Blocks too wide for the terminal
+// stay as typed rather than wrapping code.
+func renderCodeBox(lines []string, lang string, width int, styles markdownStyles) string {
+	code := lines[1 : len(lines)-1]
+	box := styles.code.Render(strings.Join(code, "\n"))
+	for _, line := range strings.Split(box, "\n") {
+		if ansi.StringWidth(line) > width {
+			return strings.Join(lines, "\n")
+		}
+	}
+	if lang != "" {
+		box = fmt.Sprintf("%s\n%s", lang, box)
+	}
+	return box
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// plainMarkdownStyles renders without escape codes, so output compares as text.
+func plainMarkdownStyles() markdownStyles {
+	return newMarkdownStyles(lipgloss.NewRenderer(io.Discard))
+}
+
+func TestRenderMarkdownBody(t *testing.T) {
+	body := strings.Join([]string{
+		"## Rollout plan",
+		"We ship **today** if *qa* signs off:",
+		"- run `make test`",
+		"  * check __staging__",
+		"1. tag the release",
+		"```sh",
+		"make release",
+		"```",
+		"2 * 3 stays literal",
+	}, "\n")
+	want := strings.Join([]string{
+		"Rollout plan",
+		"We ship today if qa signs off:",
+		"  • run `make test`",
+		"    • check staging",
+		"  1. tag the release",
+		"sh",
+		"╭──────────────╮",
+		"│ make release │",
+		"╰──────────────╯",
+		"2 * 3 stays literal",
+	}, "\n")
+	if got := renderMarkdownBody(body, 80, plainMarkdownStyles()); got != want {
+		t.Fatalf("rendered body mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderMarkdownBodyFallsBackToRaw(t *testing.T) {
+	body := "**bold** and\n```go\nfmt.Println(1)\n```"
+	if got := renderMarkdownBody(body, 0, plainMarkdownStyles()); got != body {
+		t.Fatalf("expected raw body with unknown width, got %q", got)
+	}
+
+	narrow := renderMarkdownBody(body, 10, plainMarkdownStyles())
+	if !strings.HasSuffix(narrow, "```go\nfmt.Println(1)\n```") {
+		t.Fatalf("expected a code block wider than the terminal to stay raw, got %q", narrow)
+	}
+
+	unclosed := "intro\n```go\nfmt.Println(1)"
+	if got := renderMarkdownBody(unclosed, 80, plainMarkdownStyles()); got != unclosed {
+		t.Fatalf("expected an unterminated fence to stay raw, got %q", got)
+	}
+}
+
+func TestFormatMessageRendersMarkdownOnlyWhenEnabled(t *testing.T) {
+	msg := types.Message{ID: "msg-aaaa1111", FromAgent: "pm", Body: "**ship it**", Type: types.MessageTypeAgent}
+	if got := FormatMessage(msg, "proj", nil); !strings.Contains(got, "**ship it**") {
+		t.Fatalf("expected raw markdown by default, got %q", got)
+	}
+	if got := formatMessageWithOptions(msg, "proj", nil, messageFormat{truncate: true, markdownWidth: 80}); strings.Contains(got, "**") {
+		t.Fatalf("expected rendered markdown, got %q", got)
+	}
+}
+
+func TestRenderedMarkdownKeepsSenderColor(t *testing.T) {
+	msg := types.Message{ID: "msg-aaaa1111", FromAgent: "pm", Body: "**ship it** today", Type: types.MessageTypeAgent}
+	color := getAgentColor(msg.FromAgent, msg.Type, nil)
+	if color == "" {
+		t.Skip("colors disabled")
+	}
+	renderer := lipgloss.NewRenderer(io.Discard)
+	renderer.SetColorProfile(termenv.ANSI)
+	saved := defaultMarkdownStyles
+	defaultMarkdownStyles = newMarkdownStyles(renderer)
+	t.Cleanup(func() { defaultMarkdownStyles = saved })
+
+	got := formatMessageWithOptions(msg, "proj", nil, messageFormat{markdownWidth: 80})
+	if !strings.Contains(got, "\x1b[0m"+color+" today") {
+		t.Fatalf("expected the sender color restored after the bold span, got %q", got)
+	}
+}
+
+func TestGetRenderMarkdownSkippedOffTerminal(t *testing.T) {
+	newFlowProject(t, "pm")
+	runFray(t, "post", "--as", "pm", "**ship it** today")
+
+	// Test output is never a terminal, so --render-md must leave bodies raw.
+	if output := runFray(t, "get", "--last", "5", "--render-md"); !strings.Contains(output, "**ship it**") {
+		t.Fatalf("expected raw markdown off a terminal, got %q", output)
+	}
+}
@@ -2,17 +2,21 @@ package command
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 
+	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
 	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
 )
 
 func resolveMessageRef(dbConn *sql.DB, ref string) (*types.Message, error) {
 	trimmed := strings.TrimSpace(strings.TrimPrefix(ref, "#"))
 	if trimmed == "" {
-		return nil, fmt.Errorf("message reference is required")
+		return nil, validationError("message reference is required")
 	}
 
 	msg, err := db.GetMessage(dbConn, trimmed)
@@ -38,11 +42,166 @@ func resolveMessageRef(dbConn *sql.DB, ref string) (*types.Message, error) {
 		return nil, err
 	}
 	if msg == nil {
-		return nil, fmt.Errorf("message not found: %s", ref)
+		return nil, notFoundError("message not found: %s", ref)
 	}
 	return msg, nil
 }
 
+// checkMessageContent runs the content policy on body unless --allow-secrets
+// is set. The error names the pattern, never the matched text.
+func checkMessageContent(cmd *cobra.Command, ctx *CommandContext, body string) error {
+	if allow, _ := cmd.Flags().GetBool("allow-secrets"); allow {
+		return nil
+	}
+	err := db.CheckMessageContent(ctx.DB, body)
+	var blocked *core.BlockedContentError
+	if errors.As(err, &blocked) {
+		if cmd.Flags().Lookup("allow-secrets") == nil {
+			return validationError("%s", blocked)
+		}
+		return validationError("%s; use --allow-secrets if this is a false positive", blocked)
+	}
+	return err
+}
+
+// createMessage stores a message, skipping the content policy when
+// --allow-secrets is set.
+func createMessage(cmd *cobra.Command, ctx *CommandContext, message types.Message) (types.Message, error) {
+	if allow, _ := cmd.Flags().GetBool("allow-secrets"); allow {
+		return db.CreateMessageAllowingSecrets(ctx.DB, message)
+	}
+	return db.CreateMessage(ctx.DB, message)
+}
+
+// createQuestion stores a question, skipping the content policy when
+// --allow-secrets is set.
+func createQuestion(cmd *cobra.Command, ctx *CommandContext, question types.Question) (types.Question, error) {
+	if allow, _ := cmd.Flags().GetBool("allow-secrets"); allow {
+		return db.CreateQuestionAllowingSecrets(ctx.DB, question)
+	}
+	return db.CreateQuestion(ctx.DB, question)
+}
+
+// strictMentionsKey turns unknown @mentions from a warning into an error.
+const strictMentionsKey = "strict_mentions"
+
+// checkMentionTypos warns on w about @names in body that match no agent or
+// user, suggesting the closest known name. With strict_mentions=true the
+// first unknown mention is an error instead.
+func checkMentionTypos(w io.Writer, ctx *CommandContext, body string) error {
+	known, err := db.GetAgentBases(ctx.DB)
+	if err != nil {
+		return err
+	}
+	users, _ := db.GetActiveUsers(ctx.DB, ctx.Project.DBPath)
+	for _, u := range users {
+		known[u] = struct{}{}
+	}
+	unknown := core.UnknownMentions(body, known)
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	value, _ := getConfigValue(ctx, strictMentionsKey)
+	strict, _ := db.ParseConfigBool(value)
+	for _, name := range unknown {
+		problem := fmt.Sprintf("@%s matched no agent", name)
+		if suggestion := core.SuggestMention(name, known); suggestion != "" {
+			problem += fmt.Sprintf(" — did you mean @%s?", suggestion)
+		}
+		if strict {
+			return validationError("%s (strict_mentions is on; escape it as \\@%s if intended)", problem, name)
+		}
+		fmt.Fprintf(w, "Warning: %s\n", problem)
+	}
+	return nil
+}
+
+// replyLookback bounds how many recent messages reply shorthands scan.
+const replyLookback = 50
+
+// resolveReplyTarget resolves a --reply-to value. Besides message IDs and
+// prefixes it accepts "last" (the most recent message in home) and "@agent"
+// (that agent's most recent message in home). Event messages are skipped.
+// An empty home means the room.
+func resolveReplyTarget(ctx *CommandContext, ref, home string) (*types.Message, error) {
+	trimmed := strings.TrimSpace(ref)
+	if home == "" {
+		home = "room"
+	}
+
+	opts := &types.MessageQueryOptions{Limit: replyLookback, Home: &home}
+	var agentID string
+	switch {
+	case strings.EqualFold(trimmed, "last"):
+	case strings.HasPrefix(trimmed, "@"):
+		agentID = ResolveAgentRef(trimmed, ctx.ProjectConfig)
+		opts.FromAgents = []string{agentID}
+	default:
+		return resolveMessageRef(ctx.DB, ref)
+	}
+
+	messages, err := db.GetMessages(ctx.DB, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Type != types.MessageTypeEvent {
+			return &messages[i], nil
+		}
+	}
+
+	where := "in room"
+	if home != "room" {
+		where = "in this thread"
+		if thread, err := db.GetThread(ctx.DB, home); err == nil && thread != nil {
+			if path, err := buildThreadPath(ctx.DB, thread); err == nil && path != "" {
+				where = "in " + path
+			}
+		}
+	}
+	if agentID != "" {
+		return nil, notFoundError("no message from @%s %s to reply to", agentID, where)
+	}
+	return nil, notFoundError("no message %s to reply to", where)
+}
+
+// checkReplyTarget validates a reply to target posted into home with
+// db.CheckReplyTarget and turns its errors into command errors that say how
+// to fix the post.
+func checkReplyTarget(ctx *CommandContext, target *types.Message, home string, allowCrossHome bool) error {
+	_, err := db.CheckReplyTarget(ctx.DB, target.ID, home, allowCrossHome)
+	var replyErr *db.ReplyTargetError
+	if !errors.As(err, &replyErr) {
+		return err
+	}
+	switch replyErr.Reason {
+	case db.ReplyTargetMissing:
+		return notFoundError("%s", replyErr)
+	case db.ReplyTargetDeleted, db.ReplyTargetArchived:
+		return validationError("%s", replyErr)
+	}
+	where := homeLabel(ctx.DB, replyErr.Home)
+	fix := "post without --thread"
+	if where != "room" {
+		fix = "--thread " + where
+	}
+	return validationError("#%s is in %s; reply there (%s) or pass --cross-home to link across homes", replyErr.MessageGUID, where, fix)
+}
+
+// homeLabel names a home for messages: "room" or the thread's path.
+func homeLabel(dbConn *sql.DB, home string) string {
+	if home == "" || home == "room" {
+		return "room"
+	}
+	if thread, err := db.GetThread(dbConn, home); err == nil && thread != nil {
+		if path, err := buildThreadPath(dbConn, thread); err == nil && path != "" {
+			return path
+		}
+	}
+	return home
+}
+
 // CollectQuotedMessages fetches all quoted messages for a list of messages.
 // Returns a map of message ID -> quoted message for use in formatting.
 func CollectQuotedMessages(dbConn *sql.DB, messages []types.Message) map[string]*types.Message {
@@ -63,3 +222,83 @@ func CollectQuotedMessages(dbConn *sql.DB, messages []types.Message) map[string]
 	}
 	return quotedMsgs
 }
+
+// CollectReplyParents fetches the parents of replies whose parent isn't in the
+// page itself, so replies aren't shown orphaned. Uses one extra query.
+func CollectReplyParents(dbConn *sql.DB, messages []types.Message) ([]types.Message, error) {
+	inPage := make(map[string]struct{}, len(messages))
+	for _, msg := range messages {
+		inPage[msg.ID] = struct{}{}
+	}
+
+	var missing []string
+	seen := make(map[string]struct{})
+	for _, msg := range messages {
+		if msg.ReplyTo == nil {
+			continue
+		}
+		parentID := *msg.ReplyTo
+		if _, ok := inPage[parentID]; ok {
+			continue
+		}
+		if _, ok := seen[parentID]; ok {
+			continue
+		}
+		seen[parentID] = struct{}{}
+		missing = append(missing, parentID)
+	}
+	if len(missing) == 0 {
+		return []types.Message{}, nil
+	}
+	return db.GetMessagesByIDs(dbConn, missing)
+}
+
+// CollectReplyPreviews resolves the parent of every reply in messages for
+// inline previews. Parents on the page are reused; the rest are fetched in a
+// single query. Parents that no longer exist are left out of the map.
+func CollectReplyPreviews(dbConn *sql.DB, messages []types.Message) (ReplyPreviews, error) {
+	previews := make(ReplyPreviews)
+	byID := make(map[string]*types.Message, len(messages))
+	for i := range messages {
+		byID[messages[i].ID] = &messages[i]
+	}
+
+	var missing []string
+	for _, msg := range messages {
+		if msg.ReplyTo == nil || *msg.ReplyTo == "" {
+			continue
+		}
+		parentID := *msg.ReplyTo
+		if _, ok := previews[parentID]; ok {
+			continue
+		}
+		if parent, ok := byID[parentID]; ok {
+			previews[parentID] = parent
+			continue
+		}
+		previews[parentID] = nil
+		missing = append(missing, parentID)
+	}
+	if len(missing) > 0 {
+		parents, err := db.GetMessagesByIDs(dbConn, missing)
+		if err != nil {
+			return nil, err
+		}
+		for i := range parents {
+			previews[parents[i].ID] = &parents[i]
+		}
+	}
+	return previews, nil
+}
+
+// replyParentMap indexes context-only parents by ID for formatting.
+func replyParentMap(parents []types.Message) map[string]*types.Message {
+	if len(parents) == 0 {
+		return nil
+	}
+	byID := make(map[string]*types.Message, len(parents))
+	for i := range parents {
+		byID[parents[i].ID] = &parents[i]
+	}
+	return byID
+}
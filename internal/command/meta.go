@@ -0,0 +1,253 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// commandIDAnnotation pins a command's catalogue ID. Without it the ID
+	// is the dotted command path (e.g. "thread.add"); set it when renaming a
+	// command so wrappers keyed on the old ID keep working.
+	commandIDAnnotation = "fray:id"
+	// noJSONAnnotation marks a command, and everything under it, as ignoring
+	// --json (interactive or hook-facing commands).
+	noJSONAnnotation = "fray:no-json"
+)
+
+// NewMetaCmd creates the meta command.
+func NewMetaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "meta",
+		Short: "Introspect fray itself",
+	}
+
+	cmd.AddCommand(newMetaCommandsCmd())
+	cmd.AddCommand(newMetaRecordsCmd())
+	return cmd
+}
+
+func newMetaCommandsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "commands",
+		Short: "List every command with its args and flags (--json for a machine-readable catalogue)",
+		Long: `Walks the command tree and lists each command. With --json the output is a
+catalogue for generating UIs (editor extensions, launchers) without parsing
+--help text: command paths with stable IDs, descriptions, args, flags with
+types and defaults, whether the command honours --json and takes --as, and
+hidden/deprecated markers. Entries are sorted so the output diffs cleanly
+between releases.
+
+Examples:
+  fray meta commands
+  fray meta commands --json | jq '.commands[] | select(.as) | .path'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			catalogue := buildCommandCatalogue(cmd.Root())
+			if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(catalogue)
+			}
+
+			out := cmd.OutOrStdout()
+			width := 0
+			for _, spec := range catalogue.Commands {
+				if !spec.Hidden && len(spec.Path) > width {
+					width = len(spec.Path)
+				}
+			}
+			for _, spec := range catalogue.Commands {
+				if spec.Hidden {
+					continue
+				}
+				fmt.Fprintf(out, "%-*s  %s\n", width, spec.Path, spec.Short)
+			}
+			return nil
+		},
+	}
+}
+
+func newMetaRecordsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "records",
+		Short: "List the JSONL record types fray writes (--json for field descriptions)",
+		Long: `Lists every record type in the .fray JSONL files with the file it lives in
+and its current envelope version (the "v" field). With --json each entry also
+describes its fields, generated from the record structs, so external tools can
+read the logs without reverse-engineering them. Readers ignore fields they do
+not know, so new optional fields never bump a version.
+
+Examples:
+  fray meta records
+  fray meta records --json | jq '.records[] | select(.file == "threads.jsonl")'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			specs := db.RecordSpecs()
+			if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode {
+				catalogue := recordCatalogue{Records: make([]recordSpec, 0, len(specs))}
+				for _, spec := range specs {
+					catalogue.Records = append(catalogue.Records, recordSpec{
+						Type:        spec.Type,
+						File:        spec.File,
+						Version:     spec.Version,
+						Description: spec.Description,
+						Fields:      spec.Fields(),
+					})
+				}
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(catalogue)
+			}
+
+			out := cmd.OutOrStdout()
+			fileWidth, typeWidth := 0, 0
+			for _, spec := range specs {
+				fileWidth = max(fileWidth, len(spec.File))
+				typeWidth = max(typeWidth, len(spec.Type))
+			}
+			for _, spec := range specs {
+				fmt.Fprintf(out, "%-*s  %-*s  v%d  %s\n", fileWidth, spec.File, typeWidth, spec.Type, spec.Version, spec.Description)
+			}
+			return nil
+		},
+	}
+}
+
+// recordCatalogue is the output of fray meta records --json.
+type recordCatalogue struct {
+	Records []recordSpec `json:"records"`
+}
+
+// recordSpec describes one JSONL record type.
+type recordSpec struct {
+	Type        string           `json:"type"`
+	File        string           `json:"file"`
+	Version     int              `json:"version"`
+	Description string           `json:"description"`
+	Fields      []db.RecordField `json:"fields"`
+}
+
+// commandCatalogue is the output of fray meta commands --json.
+type commandCatalogue struct {
+	Version     string        `json:"version"`
+	GlobalFlags []flagSpec    `json:"global_flags"`
+	Commands    []commandSpec `json:"commands"`
+}
+
+// commandSpec describes one command.
+type commandSpec struct {
+	ID         string     `json:"id"`
+	Path       string     `json:"path"`
+	Aliases    []string   `json:"aliases,omitempty"`
+	Short      string     `json:"short"`
+	Long       string     `json:"long,omitempty"`
+	Args       string     `json:"args,omitempty"`
+	Runnable   bool       `json:"runnable"`
+	JSON       bool       `json:"json"`
+	As         bool       `json:"as"`
+	Hidden     bool       `json:"hidden,omitempty"`
+	Deprecated string     `json:"deprecated,omitempty"`
+	Flags      []flagSpec `json:"flags"`
+}
+
+// flagSpec describes one flag.
+type flagSpec struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default"`
+	Usage      string `json:"usage"`
+	Hidden     bool   `json:"hidden,omitempty"`
+	Deprecated string `json:"deprecated,omitempty"`
+}
+
+// buildCommandCatalogue walks the tree under root. Global flags are the
+// root's persistent flags; each command lists only the flags it defines.
+func buildCommandCatalogue(root *cobra.Command) commandCatalogue {
+	catalogue := commandCatalogue{
+		Version:     root.Version,
+		GlobalFlags: collectFlagSpecs(root.PersistentFlags()),
+	}
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, child := range cmd.Commands() {
+			catalogue.Commands = append(catalogue.Commands, buildCommandSpec(child))
+			walk(child)
+		}
+	}
+	walk(root)
+
+	sort.Slice(catalogue.Commands, func(i, j int) bool {
+		return catalogue.Commands[i].Path < catalogue.Commands[j].Path
+	})
+	return catalogue
+}
+
+func buildCommandSpec(cmd *cobra.Command) commandSpec {
+	spec := commandSpec{
+		ID:         commandID(cmd),
+		Path:       cmd.CommandPath(),
+		Aliases:    cmd.Aliases,
+		Short:      cmd.Short,
+		Long:       cmd.Long,
+		Runnable:   cmd.Runnable(),
+		JSON:       cmd.Runnable() && commandSupportsJSON(cmd),
+		As:         cmd.Flags().Lookup("as") != nil,
+		Hidden:     cmd.Hidden,
+		Deprecated: cmd.Deprecated,
+		Flags:      collectFlagSpecs(cmd.LocalNonPersistentFlags()),
+	}
+	if fields := strings.Fields(cmd.Use); len(fields) > 1 {
+		spec.Args = strings.Join(fields[1:], " ")
+	}
+	// Persistent flags a command defines for its subcommands are its own too.
+	spec.Flags = append(spec.Flags, collectFlagSpecs(cmd.PersistentFlags())...)
+	sort.Slice(spec.Flags, func(i, j int) bool { return spec.Flags[i].Name < spec.Flags[j].Name })
+	return spec
+}
+
+// commandID returns the pinned ID, or the command path without the root.
+func commandID(cmd *cobra.Command) string {
+	if id := cmd.Annotations[commandIDAnnotation]; id != "" {
+		return id
+	}
+	var parts []string
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		parts = append([]string{c.Name()}, parts...)
+	}
+	return strings.Join(parts, ".")
+}
+
+func commandSupportsJSON(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[noJSONAnnotation] != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func collectFlagSpecs(flags *pflag.FlagSet) []flagSpec {
+	specs := []flagSpec{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		// cobra only adds --help to the command being run; leave it out so
+		// the catalogue does not depend on which command produced it.
+		if f.Name == "help" {
+			return
+		}
+		specs = append(specs, flagSpec{
+			Name:       f.Name,
+			Shorthand:  f.Shorthand,
+			Type:       f.Value.Type(),
+			Default:    f.DefValue,
+			Usage:      f.Usage,
+			Hidden:     f.Hidden,
+			Deprecated: f.Deprecated,
+		})
+	})
+	return specs
+}
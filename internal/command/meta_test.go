@@ -0,0 +1,123 @@
+package command
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func metaCatalogue(t *testing.T) (string, commandCatalogue) {
+	t.Helper()
+	output, err := executeCommand(NewRootCmd("test"), "meta", "commands", "--json")
+	if err != nil {
+		t.Fatalf("meta commands: %v\n%s", err, output)
+	}
+	var catalogue commandCatalogue
+	if err := json.Unmarshal([]byte(output), &catalogue); err != nil {
+		t.Fatalf("decode catalogue: %v", err)
+	}
+	return output, catalogue
+}
+
+func TestMetaCommandsCatalogue(t *testing.T) {
+	_, catalogue := metaCatalogue(t)
+	if catalogue.Version != "test" {
+		t.Fatalf("expected version test, got %q", catalogue.Version)
+	}
+
+	byID := map[string]commandSpec{}
+	for _, spec := range catalogue.Commands {
+		byID[spec.ID] = spec
+	}
+
+	post, ok := byID["post"]
+	if !ok {
+		t.Fatalf("expected post in catalogue")
+	}
+	if post.Path != "fray post" || !post.JSON || !post.As || !post.Runnable {
+		t.Fatalf("unexpected post spec: %+v", post)
+	}
+	flags := map[string]flagSpec{}
+	for _, flag := range post.Flags {
+		flags[flag.Name] = flag
+	}
+	if reply := flags["reply-to"]; reply.Shorthand != "r" || reply.Type != "string" {
+		t.Fatalf("unexpected --reply-to spec: %+v", reply)
+	}
+	if commit := flags["commit"]; commit.Type != "stringArray" || commit.Default != "[]" {
+		t.Fatalf("unexpected --commit spec: %+v", commit)
+	}
+	if _, ok := flags["help"]; ok {
+		t.Fatalf("expected --help to be left out of flags")
+	}
+
+	if add, ok := byID["add"]; !ok || add.Args != "<thread> <message...>" {
+		t.Fatalf("expected add with its args spec, got %+v", add)
+	}
+	if create, ok := byID["agent.create"]; !ok || create.Path != "fray agent create" {
+		t.Fatalf("expected agent create keyed by its dotted path, got %+v", create)
+	}
+	if chat := byID["chat"]; chat.JSON {
+		t.Fatalf("expected chat not to advertise --json")
+	}
+	if filterShow := byID["filter.show"]; filterShow.JSON {
+		t.Fatalf("expected filter show to inherit the no-json annotation")
+	}
+
+	global := map[string]bool{}
+	for _, flag := range catalogue.GlobalFlags {
+		global[flag.Name] = true
+	}
+	if !global["json"] || !global["project"] {
+		t.Fatalf("expected --json and --project among global flags, got %+v", catalogue.GlobalFlags)
+	}
+}
+
+func TestMetaCommandsDeterministic(t *testing.T) {
+	first, _ := metaCatalogue(t)
+	second, _ := metaCatalogue(t)
+	if first != second {
+		t.Fatalf("expected identical catalogues across runs")
+	}
+}
+
+func TestCommandIDAnnotationPinsID(t *testing.T) {
+	root := &cobra.Command{Use: "fray"}
+	renamed := &cobra.Command{Use: "wonder", Annotations: map[string]string{commandIDAnnotation: "ask.open"}}
+	root.AddCommand(renamed)
+	if got := commandID(renamed); got != "ask.open" {
+		t.Fatalf("expected pinned id, got %q", got)
+	}
+}
+
+func TestMetaRecordsCatalogue(t *testing.T) {
+	output, err := executeCommand(NewRootCmd("test"), "meta", "records", "--json")
+	if err != nil {
+		t.Fatalf("meta records: %v\n%s", err, output)
+	}
+	var catalogue recordCatalogue
+	if err := json.Unmarshal([]byte(output), &catalogue); err != nil {
+		t.Fatalf("decode catalogue: %v", err)
+	}
+
+	var pin *recordSpec
+	for i := range catalogue.Records {
+		if catalogue.Records[i].Type == "thread_pin" {
+			pin = &catalogue.Records[i]
+		}
+	}
+	if pin == nil {
+		t.Fatalf("expected thread_pin in catalogue")
+	}
+	if pin.File != "threads.jsonl" || pin.Version != 1 {
+		t.Fatalf("unexpected thread_pin spec: %+v", pin)
+	}
+	fields := map[string]string{}
+	for _, field := range pin.Fields {
+		fields[field.Name] = field.Type
+	}
+	if fields["type"] != "string" || fields["v"] != "integer" || fields["thread_guid"] != "string" || fields["pinned_at"] != "integer" {
+		t.Fatalf("unexpected thread_pin fields: %+v", pin.Fields)
+	}
+}
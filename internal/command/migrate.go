@@ -61,8 +61,9 @@ func NewMigrateCmd() *cobra.Command {
 	var fixThreads bool
 
 	cmd := &cobra.Command{
-		Use:   "migrate",
-		Short: "Migrate fray project from v0.1.0 to v0.2.0 format, or fix thread hierarchy",
+		Use:         "migrate",
+		Short:       "Migrate fray project from v0.1.0 to v0.2.0 format, or fix thread hierarchy",
+		Annotations: map[string]string{noJSONAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			project, err := core.DiscoverProject("")
 			if err != nil {
@@ -705,7 +706,7 @@ func writeJSONLFile(filePath string, records any) error {
 	case []db.AgentJSONLRecord:
 		lines = make([]string, 0, len(v))
 		for _, record := range v {
-			row, err := json.Marshal(record)
+			row, err := db.MarshalRecord(record)
 			if err != nil {
 				return err
 			}
@@ -714,7 +715,7 @@ func writeJSONLFile(filePath string, records any) error {
 	case []db.MessageJSONLRecord:
 		lines = make([]string, 0, len(v))
 		for _, record := range v {
-			row, err := json.Marshal(record)
+			row, err := db.MarshalRecord(record)
 			if err != nil {
 				return err
 			}
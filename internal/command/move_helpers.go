@@ -0,0 +1,254 @@
+package command
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// movePlan describes the full effect of a message move before anything is written.
+type movePlan struct {
+	Destination string          `json:"destination"`
+	Messages    []types.Message `json:"messages"`
+	Skipped     []string        `json:"skipped,omitempty"`
+	Closures    []replyClosure  `json:"closures,omitempty"`
+	Warnings    []moveWarning   `json:"warnings,omitempty"`
+}
+
+// moveWarning flags a message whose move would detach it from thread curation.
+type moveWarning struct {
+	MessageGUID string `json:"message_guid"`
+	Kind        string `json:"kind"` // "anchor" | "pinned"
+	ThreadGUID  string `json:"thread_guid"`
+	ThreadName  string `json:"thread_name,omitempty"`
+}
+
+// replyClosure records how many replies --with-replies pulled in under a
+// requested message.
+type replyClosure struct {
+	MessageGUID string `json:"message_guid"`
+	Replies     int    `json:"replies"`
+}
+
+// moveSummary aggregates a move plan for display.
+type moveSummary struct {
+	Count   int      `json:"count"`
+	Authors []string `json:"authors"`
+	FirstTS int64    `json:"first_ts,omitempty"`
+	LastTS  int64    `json:"last_ts,omitempty"`
+	FirstID string   `json:"first_id,omitempty"`
+	LastID  string   `json:"last_id,omitempty"`
+}
+
+// planMessageMove resolves the messages a move would touch, including the reply
+// closure when withReplies is set; a closure larger than maxReplies is an error.
+// Messages already at newHome are skipped. Both dry runs and real moves use
+// this so previews match what gets written.
+func planMessageMove(dbConn *sql.DB, messageRefs []string, newHome string, withReplies bool, maxReplies int) (*movePlan, error) {
+	plan := &movePlan{Destination: newHome}
+	seen := make(map[string]struct{})
+
+	for _, messageRef := range messageRefs {
+		msg, err := resolveMessageRef(dbConn, messageRef)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := []types.Message{*msg}
+		if withReplies {
+			replies, err := getAllReplies(dbConn, msg.ID, maxReplies)
+			if err != nil {
+				return nil, err
+			}
+			plan.Closures = append(plan.Closures, replyClosure{MessageGUID: msg.ID, Replies: len(replies)})
+			candidates = append(candidates, replies...)
+		}
+
+		for _, m := range candidates {
+			if _, ok := seen[m.ID]; ok {
+				continue
+			}
+			seen[m.ID] = struct{}{}
+			if m.Home == newHome {
+				plan.Skipped = append(plan.Skipped, m.ID)
+				continue
+			}
+			plan.Messages = append(plan.Messages, m)
+		}
+	}
+
+	for _, m := range plan.Messages {
+		warnings, err := moveWarningsForMessage(dbConn, m, newHome)
+		if err != nil {
+			return nil, err
+		}
+		plan.Warnings = append(plan.Warnings, warnings...)
+	}
+
+	return plan, nil
+}
+
+// moveWarningsForMessage reports anchors and pins outside the destination.
+func moveWarningsForMessage(dbConn *sql.DB, msg types.Message, newHome string) ([]moveWarning, error) {
+	var warnings []moveWarning
+
+	anchored, err := db.GetThreadsByAnchor(dbConn, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, thread := range anchored {
+		if thread.GUID == newHome {
+			continue
+		}
+		warnings = append(warnings, moveWarning{
+			MessageGUID: msg.ID,
+			Kind:        "anchor",
+			ThreadGUID:  thread.GUID,
+			ThreadName:  thread.Name,
+		})
+	}
+
+	pinnedIn, err := db.GetMessagePinThreads(dbConn, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, threadGUID := range pinnedIn {
+		if threadGUID == newHome {
+			continue
+		}
+		warning := moveWarning{
+			MessageGUID: msg.ID,
+			Kind:        "pinned",
+			ThreadGUID:  threadGUID,
+		}
+		if thread, err := db.GetThread(dbConn, threadGUID); err == nil && thread != nil {
+			warning.ThreadName = thread.Name
+		}
+		warnings = append(warnings, warning)
+	}
+
+	return warnings, nil
+}
+
+// summarize computes counts, authors, and time span for the planned moves.
+func (p *movePlan) summarize() moveSummary {
+	summary := moveSummary{Count: len(p.Messages), Authors: []string{}}
+	if len(p.Messages) == 0 {
+		return summary
+	}
+
+	ordered := make([]types.Message, len(p.Messages))
+	copy(ordered, p.Messages)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].TS < ordered[j].TS
+	})
+
+	authors := make(map[string]struct{})
+	for _, m := range ordered {
+		if _, ok := authors[m.FromAgent]; ok {
+			continue
+		}
+		authors[m.FromAgent] = struct{}{}
+		summary.Authors = append(summary.Authors, m.FromAgent)
+	}
+
+	first := ordered[0]
+	last := ordered[len(ordered)-1]
+	summary.FirstTS = first.TS
+	summary.LastTS = last.TS
+	summary.FirstID = first.ID
+	summary.LastID = last.ID
+	return summary
+}
+
+func describeMoveWarning(w moveWarning) string {
+	thread := w.ThreadGUID
+	if w.ThreadName != "" {
+		thread = fmt.Sprintf("%s (%s)", w.ThreadName, w.ThreadGUID)
+	}
+	switch w.Kind {
+	case "anchor":
+		return fmt.Sprintf("%s is the anchor of %s", w.MessageGUID, thread)
+	case "pinned":
+		return fmt.Sprintf("%s is pinned in %s", w.MessageGUID, thread)
+	default:
+		return fmt.Sprintf("%s: %s %s", w.MessageGUID, w.Kind, thread)
+	}
+}
+
+// printMovePlan writes a human-readable impact summary for a move.
+func printMovePlan(out io.Writer, plan *movePlan, destName string) {
+	summary := plan.summarize()
+	if summary.Count == 0 {
+		fmt.Fprintf(out, "Nothing to move to %s", destName)
+		if len(plan.Skipped) > 0 {
+			fmt.Fprintf(out, " (%d already there)", len(plan.Skipped))
+		}
+		fmt.Fprintln(out)
+		return
+	}
+
+	span := time.Duration(summary.LastTS-summary.FirstTS) * time.Second
+	fmt.Fprintf(out, "Would move %d message(s) from %d author(s) spanning %s to %s\n",
+		summary.Count, len(summary.Authors), formatMoveSpan(span), destName)
+	fmt.Fprintf(out, "  authors: @%s\n", strings.Join(summary.Authors, ", @"))
+	printReplyClosures(out, plan.Closures, "  replies: ")
+	fmt.Fprintf(out, "  first:   %s  %s\n", summary.FirstID, time.Unix(summary.FirstTS, 0).Format("2006-01-02 15:04"))
+	fmt.Fprintf(out, "  last:    %s  %s\n", summary.LastID, time.Unix(summary.LastTS, 0).Format("2006-01-02 15:04"))
+	if len(plan.Skipped) > 0 {
+		fmt.Fprintf(out, "  skipped: %d already in %s\n", len(plan.Skipped), destName)
+	}
+	for _, w := range plan.Warnings {
+		fmt.Fprintf(out, "  warning: %s\n", describeMoveWarning(w))
+	}
+}
+
+// printReplyClosures writes one line per --with-replies closure.
+func printReplyClosures(out io.Writer, closures []replyClosure, prefix string) {
+	for _, c := range closures {
+		fmt.Fprintf(out, "%s%d under %s\n", prefix, c.Replies, c.MessageGUID)
+	}
+}
+
+func formatMoveSpan(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
+// moveInverse returns mv commands that return each message to its original home.
+// Moving back is forced: the forward move already cleared any curation warnings.
+func moveInverse(moved []types.Message) [][]string {
+	var order []string
+	byHome := make(map[string][]string)
+	for _, m := range moved {
+		home := m.Home
+		if home == "" {
+			home = "room"
+		}
+		if _, ok := byHome[home]; !ok {
+			order = append(order, home)
+		}
+		byHome[home] = append(byHome[home], m.ID)
+	}
+
+	inverse := make([][]string, 0, len(order))
+	for _, home := range order {
+		argv := append([]string{"mv"}, byHome[home]...)
+		inverse = append(inverse, append(argv, home, "--force"))
+	}
+	return inverse
+}
@@ -0,0 +1,288 @@
+package command
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// newFlowProject initializes a fray project in a temp dir and chdirs into it.
+func newFlowProject(t *testing.T, agents ...string) string {
+	t.Helper()
+
+	testutil.IsolateHome(t)
+	projectDir := t.TempDir()
+	testutil.Chdir(t, projectDir)
+
+	if _, err := executeCommand(NewRootCmd("test"), "init", "--defaults"); err != nil {
+		t.Fatalf("init command: %v", err)
+	}
+	for _, agent := range agents {
+		if _, err := executeCommand(NewRootCmd("test"), "new", agent, "hello"); err != nil {
+			t.Fatalf("new %s: %v", agent, err)
+		}
+	}
+	return projectDir
+}
+
+// runFray executes a fray command in the current project and fails on error.
+func runFray(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := executeCommand(NewRootCmd("test"), args...)
+	if err != nil {
+		t.Fatalf("fray %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return output
+}
+
+func TestMvDryRunMatchesRealMove(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "analysis")
+	runFray(t, "post", "--as", "alice", "root question")
+
+	dbConn := openProjectDB(t, projectDir)
+	rootID := findRoomMessageByBody(t, dbConn, "root question")
+	_ = dbConn.Close()
+
+	runFray(t, "post", "--as", "bob", "--reply-to", rootID, "this is the first reply to the question")
+	dbConn = openProjectDB(t, projectDir)
+	replyID := findRoomMessageByBody(t, dbConn, "this is the first reply to the question")
+	_ = dbConn.Close()
+	runFray(t, "post", "--as", "alice", "--reply-to", replyID, "and this is a nested reply underneath it")
+
+	output := runFray(t, "mv", rootID, "analysis", "--with-replies", "--dry-run", "--json")
+	var preview struct {
+		DryRun   bool            `json:"dry_run"`
+		Summary  moveSummary     `json:"summary"`
+		Messages []types.Message `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(output), &preview); err != nil {
+		t.Fatalf("decode dry run: %v\n%s", err, output)
+	}
+	if !preview.DryRun || preview.Summary.Count != 3 {
+		t.Fatalf("expected dry run of 3 messages, got %+v", preview.Summary)
+	}
+	if len(preview.Summary.Authors) != 2 {
+		t.Fatalf("expected 2 authors, got %v", preview.Summary.Authors)
+	}
+
+	dbConn = openProjectDB(t, projectDir)
+	room := "room"
+	roomMsgs, err := db.GetMessages(dbConn, &types.MessageQueryOptions{Home: &room})
+	if err != nil {
+		t.Fatalf("get messages: %v", err)
+	}
+	_ = dbConn.Close()
+	for _, m := range preview.Messages {
+		found := false
+		for _, r := range roomMsgs {
+			if r.ID == m.ID {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("dry run moved %s", m.ID)
+		}
+	}
+
+	runFray(t, "mv", rootID, "analysis", "--with-replies")
+
+	dbConn = openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	thread, err := db.GetThreadByName(dbConn, "analysis", nil)
+	if err != nil || thread == nil {
+		t.Fatalf("get thread: %v", err)
+	}
+	for _, m := range preview.Messages {
+		msg, err := db.GetMessage(dbConn, m.ID)
+		if err != nil {
+			t.Fatalf("get message: %v", err)
+		}
+		if msg.Home != thread.GUID {
+			t.Fatalf("expected %s moved to %s, got %s", m.ID, thread.GUID, msg.Home)
+		}
+	}
+}
+
+func TestMvWarnsOnAnchorAndPin(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "design")
+	runFray(t, "thread", "other")
+	runFray(t, "post", "design", "--as", "alice", "design summary")
+
+	dbConn := openProjectDB(t, projectDir)
+	design, err := db.GetThreadByName(dbConn, "design", nil)
+	if err != nil || design == nil {
+		t.Fatalf("get thread: %v", err)
+	}
+	msgs, err := db.GetMessages(dbConn, &types.MessageQueryOptions{Home: &design.GUID})
+	if err != nil || len(msgs) == 0 {
+		t.Fatalf("get thread messages: %v", err)
+	}
+	msgID := msgs[len(msgs)-1].ID
+	_ = dbConn.Close()
+
+	runFray(t, "anchor", "design", msgID)
+	runFray(t, "pin", msgID)
+
+	output := runFray(t, "mv", msgID, "other", "--dry-run")
+	if !strings.Contains(output, "is the anchor of design") {
+		t.Fatalf("expected anchor warning, got %q", output)
+	}
+	if !strings.Contains(output, "is pinned in design") {
+		t.Fatalf("expected pin warning, got %q", output)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "mv", msgID, "other"); err == nil {
+		t.Fatalf("expected move of anchored message to require --force")
+	}
+
+	runFray(t, "mv", msgID, "other", "--force")
+	dbConn = openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	moved, err := db.GetMessage(dbConn, msgID)
+	if err != nil {
+		t.Fatalf("get message: %v", err)
+	}
+	if moved.Home == design.GUID {
+		t.Fatalf("expected forced move to leave design")
+	}
+}
+
+// recursiveReplies is the original one-query-per-node traversal, kept as the
+// reference getAllReplies must agree with.
+func recursiveReplies(t testing.TB, conn *sql.DB, guid string, seen map[string]struct{}) []string {
+	t.Helper()
+	if _, ok := seen[guid]; ok {
+		return nil
+	}
+	seen[guid] = struct{}{}
+	replies, err := db.GetReplies(conn, guid)
+	if err != nil {
+		t.Fatalf("get replies: %v", err)
+	}
+	var ids []string
+	for _, reply := range replies {
+		if _, ok := seen[reply.ID]; ok {
+			continue
+		}
+		ids = append(ids, reply.ID)
+		ids = append(ids, recursiveReplies(t, conn, reply.ID, seen)...)
+	}
+	return ids
+}
+
+func openReplyDB(t testing.TB) *sql.DB {
+	t.Helper()
+	return testutil.NewProject(t).OpenDB(t, db.OpenDatabase, db.InitSchema)
+}
+
+func insertReply(t testing.TB, conn *sql.DB, ts int64, parent string) string {
+	t.Helper()
+	msg := types.Message{TS: ts, FromAgent: "alice", Body: "reply", Mentions: []string{}}
+	if parent != "" {
+		msg.ReplyTo = &parent
+	}
+	created, err := db.CreateMessage(conn, msg)
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	return created.ID
+}
+
+func TestGetAllRepliesMatchesRecursiveTraversal(t *testing.T) {
+	conn := openReplyDB(t)
+
+	// root -> a -> (a1 -> a1x, a2), root -> b -> b1, plus an unrelated chain.
+	root := insertReply(t, conn, 1, "")
+	a := insertReply(t, conn, 2, root)
+	b := insertReply(t, conn, 3, root)
+	a1 := insertReply(t, conn, 4, a)
+	insertReply(t, conn, 5, a)
+	insertReply(t, conn, 6, a1)
+	insertReply(t, conn, 7, b)
+	other := insertReply(t, conn, 8, "")
+	insertReply(t, conn, 9, other)
+
+	check := func(guid string, want int) {
+		t.Helper()
+		replies, err := getAllReplies(conn, guid, 100)
+		if err != nil {
+			t.Fatalf("getAllReplies: %v", err)
+		}
+		got := make([]string, 0, len(replies))
+		for _, reply := range replies {
+			got = append(got, reply.ID)
+		}
+		expected := recursiveReplies(t, conn, guid, map[string]struct{}{})
+		sort.Strings(got)
+		sort.Strings(expected)
+		if len(got) != want || !reflect.DeepEqual(got, expected) {
+			t.Fatalf("closure of %s = %v, want %d matching %v", guid, got, want, expected)
+		}
+	}
+	check(root, 6)
+	check(a, 3)
+	check(other, 1)
+
+	// A cycle back to the root must terminate and never include the root.
+	if _, err := conn.Exec("UPDATE fray_messages SET reply_to = ? WHERE guid = ?", a1, root); err != nil {
+		t.Fatalf("make cycle: %v", err)
+	}
+	check(root, 6)
+	check(a1, 6)
+}
+
+func TestMvWithRepliesLimit(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "analysis")
+	runFray(t, "post", "--as", "alice", "root of a long chain")
+
+	dbConn := openProjectDB(t, projectDir)
+	parent := findRoomMessageByBody(t, dbConn, "root of a long chain")
+	_ = dbConn.Close()
+	root := parent
+	for i := 0; i < 3; i++ {
+		out := postJSON(t, "post", "--as", "alice", "--reply-to", parent, fmt.Sprintf("this is link %d of a reply chain that keeps going", i))
+		parent = out["id"].(string)
+	}
+
+	output, err := executeCommand(NewRootCmd("test"), "mv", root, "analysis", "--with-replies", "--max-replies", "2")
+	if err == nil || !strings.Contains(output, "3 replies") {
+		t.Fatalf("expected limit error naming 3 replies, got %v %q", err, output)
+	}
+
+	runFray(t, "config", "mv_max_replies", "2")
+	if _, err := executeCommand(NewRootCmd("test"), "mv", root, "analysis", "--with-replies"); err == nil {
+		t.Fatal("expected mv_max_replies to refuse the move")
+	}
+
+	output = runFray(t, "mv", root, "analysis", "--with-replies", "--max-replies", "3")
+	if !strings.Contains(output, "Moving replies: 3 under "+root) || !strings.Contains(output, "Moved 4 message(s)") {
+		t.Fatalf("expected closure size reported before move, got %q", output)
+	}
+}
+
+func BenchmarkGetAllRepliesChain(b *testing.B) {
+	conn := openReplyDB(b)
+	root := insertReply(b, conn, 1, "")
+	parent := root
+	for i := 0; i < 1000; i++ {
+		parent = insertReply(b, conn, int64(i+2), parent)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		replies, err := getAllReplies(conn, root, db.DefaultMaxMoveReplies)
+		if err != nil || len(replies) != 1000 {
+			b.Fatalf("getAllReplies: %d replies, %v", len(replies), err)
+		}
+	}
+}
@@ -48,7 +48,7 @@ func NewNewCmd() *cobra.Command {
 			}
 
 			staleHours := 4
-			if value, err := db.GetConfig(ctx.DB, "stale_hours"); err == nil && value != "" {
+			if value, err := getConfigValue(ctx, "stale_hours"); err == nil && value != "" {
 				parsed := parseNumeric(value)
 				if parsed > 0 {
 					staleHours = parsed
@@ -76,8 +76,11 @@ func NewNewCmd() *cobra.Command {
 				}
 			} else {
 				agentID = core.NormalizeAgentRef(name)
-				if !core.IsValidAgentID(agentID) {
-					return writeCommandError(cmd, fmt.Errorf("invalid agent name: %s\nNames must start with a lowercase letter and contain only lowercase letters, numbers, and hyphens.\nExamples: alice, pm, eager-beaver, frontend-dev", agentID))
+				if normalize, _ := cmd.Flags().GetBool("normalize"); normalize {
+					agentID = core.NormalizeAgentID(agentID)
+				}
+				if err := core.ValidateNewAgentID(agentID); err != nil {
+					return writeCommandError(cmd, validationError("%v", err))
 				}
 				suggestion, err := suggestAgentDelimiter(ctx.DB, agentID)
 				if err != nil {
@@ -263,11 +266,11 @@ func NewNewCmd() *cobra.Command {
 			// Create default DM thread between agent and user (if username configured)
 			var dmThread *types.Thread
 			if !isRejoin {
-				username, _ := db.GetConfig(ctx.DB, "username")
+				username, _ := humanIdentity(ctx)
 				if username != "" {
 					threadName := fmt.Sprintf("dm-%s", agentID)
 					subscribers := []string{agentID, username}
-					dmThread, err = ensureThread(ctx, threadName, nil, subscribers)
+					dmThread, err = ensureThread(ctx, threadName, nil, subscribers, agentID)
 					if err != nil {
 						return writeCommandError(cmd, err)
 					}
@@ -346,6 +349,7 @@ func NewNewCmd() *cobra.Command {
 
 	cmd.Flags().String("status", "", "current task/focus")
 	cmd.Flags().String("purpose", "", "agent role/identity")
+	cmd.Flags().Bool("normalize", false, "register the normalized form of the name (lowercase, _ and spaces to -)")
 
 	return cmd
 }
@@ -454,7 +458,7 @@ func ensureMetaThread(ctx *CommandContext) (*types.Thread, error) {
 	if metaThread == nil {
 		thread, err := db.CreateThread(ctx.DB, types.Thread{
 			Name: "meta",
-			Type: types.ThreadTypeKnowledge,
+			Type: types.ThreadTypeMeta,
 		})
 		if err != nil {
 			return nil, err
@@ -463,13 +467,19 @@ func ensureMetaThread(ctx *CommandContext) (*types.Thread, error) {
 			return nil, err
 		}
 		metaThread = &thread
+	} else if metaThread.Type == types.ThreadTypeKnowledge {
+		// Projects created before typed threads stored meta/ as knowledge.
+		metaThread, err = setThreadType(ctx, metaThread, types.ThreadTypeMeta)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return metaThread, nil
 }
 
 // ensureAgentHierarchy creates the agent thread hierarchy if it doesn't exist.
-// Creates: meta/{agent}/ (knowledge), meta/{agent}/notes (system), meta/{agent}/jrnl (system)
+// Creates: meta/{agent}/ (knowledge), meta/{agent}/notes (notes), meta/{agent}/jrnl (journal)
 func ensureAgentHierarchy(ctx *CommandContext, agentID string) error {
 	// First ensure meta/ root thread exists
 	metaThread, err := ensureMetaThread(ctx)
@@ -489,6 +499,7 @@ func ensureAgentHierarchy(ctx *CommandContext, agentID string) error {
 			Name:         agentID,
 			ParentThread: &metaThread.GUID,
 			Type:         types.ThreadTypeKnowledge,
+			CreatedBy:    &agentID,
 		})
 		if err != nil {
 			return err
@@ -512,7 +523,8 @@ func ensureAgentHierarchy(ctx *CommandContext, agentID string) error {
 		thread, err := db.CreateThread(ctx.DB, types.Thread{
 			Name:         "notes",
 			ParentThread: &agentThread.GUID,
-			Type:         types.ThreadTypeSystem,
+			Type:         types.ThreadTypeNotes,
+			CreatedBy:    &agentID,
 		})
 		if err != nil {
 			return err
@@ -523,6 +535,8 @@ func ensureAgentHierarchy(ctx *CommandContext, agentID string) error {
 		if err := db.SubscribeThread(ctx.DB, thread.GUID, agentID, time.Now().Unix()); err != nil {
 			return err
 		}
+	} else if err := upgradeLegacyThreadType(ctx, notesThread, types.ThreadTypeNotes); err != nil {
+		return err
 	}
 
 	// Check and create jrnl subthread
@@ -534,7 +548,8 @@ func ensureAgentHierarchy(ctx *CommandContext, agentID string) error {
 		thread, err := db.CreateThread(ctx.DB, types.Thread{
 			Name:         "jrnl",
 			ParentThread: &agentThread.GUID,
-			Type:         types.ThreadTypeSystem,
+			Type:         types.ThreadTypeJournal,
+			CreatedBy:    &agentID,
 		})
 		if err != nil {
 			return err
@@ -545,6 +560,8 @@ func ensureAgentHierarchy(ctx *CommandContext, agentID string) error {
 		if err := db.SubscribeThread(ctx.DB, thread.GUID, agentID, time.Now().Unix()); err != nil {
 			return err
 		}
+	} else if err := upgradeLegacyThreadType(ctx, jrnlThread, types.ThreadTypeJournal); err != nil {
+		return err
 	}
 
 	return nil
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+)
+
+func TestNewSuggestsAndAppliesNormalizedName(t *testing.T) {
+	projectDir := newFlowProject(t)
+
+	output, err := executeCommand(NewRootCmd("test"), "new", "Frontend_Dev", "hello")
+	if err == nil || !strings.Contains(output, "did you mean 'frontend-dev'?") {
+		t.Fatalf("expected a normalization suggestion, got %v %q", err, output)
+	}
+
+	runFray(t, "new", "Frontend_Dev", "hello", "--normalize")
+	runFray(t, "agent", "create", "Review Bot", "--normalize")
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	for _, id := range []string{"frontend-dev", "review-bot"} {
+		agent, err := db.GetAgent(dbConn, id)
+		if err != nil || agent == nil {
+			t.Fatalf("expected @%s to be registered, got %v", id, err)
+		}
+	}
+}
+
+func TestNewRejectsReservedNames(t *testing.T) {
+	newFlowProject(t)
+
+	for _, args := range [][]string{
+		{"new", "system", "hello"},
+		{"new", "Room", "hello", "--normalize"},
+		{"agent", "create", "all"},
+	} {
+		output, err := executeCommand(NewRootCmd("test"), args...)
+		if err == nil || !strings.Contains(output, "reserved") {
+			t.Fatalf("expected %v to be rejected as reserved, got %v %q", args, err, output)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package command
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/adamavenir/fray/internal/chat"
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/daemon"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// desktopNotifier delivers a desktop notification for a message.
+type desktopNotifier interface {
+	Notify(msg types.Message, projectName string) error
+}
+
+// osNotifier uses the same notifier as fray chat (Fray-Notifier.app on macOS,
+// the platform default elsewhere).
+type osNotifier struct{}
+
+func (osNotifier) Notify(msg types.Message, projectName string) error {
+	return chat.SendNotification(msg, projectName)
+}
+
+// quietHours is a daily window (local time) during which notifications are dropped.
+// Start after End means the window wraps midnight (e.g. 22:00-08:00).
+type quietHours struct {
+	Start int // minutes after midnight
+	End   int
+}
+
+func parseQuietHours(value string) (*quietHours, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("notify_quiet must look like 22:00-08:00")
+	}
+	start, err := parseClockMinutes(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClockMinutes(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	return &quietHours{Start: start, End: end}, nil
+}
+
+func parseClockMinutes(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q in notify_quiet (use HH:MM)", strings.TrimSpace(value))
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether t falls inside the quiet window.
+func (q *quietHours) Contains(t time.Time) bool {
+	if q == nil || q.Start == q.End {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// shouldNotify reports whether msg mentions agentID or replies to one of its messages.
+func shouldNotify(dbConn *sql.DB, msg types.Message, agentID string) bool {
+	if core.MatchesMention(msg.FromAgent, agentID) {
+		return false
+	}
+	for _, mention := range msg.Mentions {
+		if core.IsAllMention(mention) || core.MatchesMention(agentID, mention) {
+			return true
+		}
+	}
+	// Events only notify when they mention you (e.g. a blocked done report).
+	if msg.Type == types.MessageTypeEvent {
+		return false
+	}
+	return daemon.IsReplyToAgent(dbConn, msg, agentID)
+}
+
+func notifyCursorPath(project core.Project, agentID string) string {
+	return filepath.Join(filepath.Dir(project.DBPath), "local", fmt.Sprintf("notify-%s.json", agentID))
+}
+
+// notifyCursor tracks progress by timestamp plus the IDs already seen at that
+// timestamp, since several messages can share a second and GUIDs don't sort by time.
+type notifyCursor struct {
+	TS   int64    `json:"ts"`
+	Seen []string `json:"seen,omitempty"`
+}
+
+func readNotifyCursor(path string) (*notifyCursor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var cursor notifyCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, nil
+	}
+	return &cursor, nil
+}
+
+func writeNotifyCursor(path string, cursor *notifyCursor) error {
+	if cursor == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// notifyPoller checks for new messages relevant to one human and notifies.
+type notifyPoller struct {
+	db          *sql.DB
+	agentID     string
+	projectName string
+	notifier    desktopNotifier
+	quiet       *quietHours
+	cursorPath  string
+	cursor      *notifyCursor
+}
+
+// unseen returns messages (in any home) not yet covered by the cursor.
+func (p *notifyPoller) unseen() ([]types.Message, error) {
+	allHomes := ""
+	options := &types.MessageQueryOptions{Home: &allHomes}
+	if p.cursor != nil {
+		// GUID "" makes the cursor inclusive of its own second.
+		options.Since = &types.MessageCursor{TS: p.cursor.TS}
+	}
+	messages, err := db.GetMessages(p.db, options)
+	if err != nil || p.cursor == nil {
+		return messages, err
+	}
+
+	seen := make(map[string]struct{}, len(p.cursor.Seen))
+	for _, id := range p.cursor.Seen {
+		seen[id] = struct{}{}
+	}
+	fresh := messages[:0]
+	for _, msg := range messages {
+		if _, ok := seen[msg.ID]; !ok {
+			fresh = append(fresh, msg)
+		}
+	}
+	return fresh, nil
+}
+
+func (p *notifyPoller) advance(messages []types.Message) error {
+	for _, msg := range messages {
+		if p.cursor == nil || msg.TS > p.cursor.TS {
+			p.cursor = &notifyCursor{TS: msg.TS}
+		}
+		if msg.TS == p.cursor.TS {
+			p.cursor.Seen = append(p.cursor.Seen, msg.ID)
+		}
+	}
+	return writeNotifyCursor(p.cursorPath, p.cursor)
+}
+
+// prime marks everything currently in the project as seen without notifying.
+func (p *notifyPoller) prime() error {
+	messages, err := p.unseen()
+	if err != nil {
+		return err
+	}
+	return p.advance(messages)
+}
+
+// poll notifies for unseen messages and persists the new cursor.
+// Messages that arrive during quiet hours are skipped, not deferred. When a
+// notification fails, the cursor stops just before that message, so the
+// next poll retries it without repeating the ones already delivered.
+func (p *notifyPoller) poll(now time.Time) (int, error) {
+	messages, err := p.unseen()
+	if err != nil {
+		return 0, err
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	sent := 0
+	quiet := p.quiet.Contains(now)
+	for i, msg := range messages {
+		if quiet || !shouldNotify(p.db, msg, p.agentID) {
+			continue
+		}
+		if err := p.notifier.Notify(msg, p.projectName); err != nil {
+			if advanceErr := p.advance(messages[:i]); advanceErr != nil {
+				return sent, advanceErr
+			}
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, p.advance(messages)
+}
+
+// NewNotifyCmd creates the notify command.
+func NewNotifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Desktop notifications for mentions and replies",
+		Long: `Watch for messages that mention you or reply to your messages and show a
+desktop notification, using the same notifier as fray chat.
+
+Progress is kept in .fray/local/, so restarting never repeats a notification.
+Set quiet hours with: fray config notify_quiet 22:00-08:00
+
+Examples:
+  fray notify --as adam               # Run in the foreground
+  fray notify --as adam --daemonize   # Detach and keep running`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			asRef, _ := cmd.Flags().GetString("as")
+			if asRef == "" {
+				return writeCommandError(cmd, fmt.Errorf("--as is required"))
+			}
+			agentID, err := resolveAgentRef(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			quietValue, err := getConfigValue(ctx, "notify_quiet")
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			quiet, err := parseQuietHours(quietValue)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			daemonize, _ := cmd.Flags().GetBool("daemonize")
+			if daemonize {
+				pid, err := startDetachedNotify(ctx, agentID)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if ctx.JSONMode {
+					return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{"agent_id": agentID, "pid": pid})
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Notifying @%s in the background (pid %d)\n", agentID, pid)
+				return nil
+			}
+
+			cursorPath := notifyCursorPath(ctx.Project, agentID)
+			cursor, err := readNotifyCursor(cursorPath)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			poller := &notifyPoller{
+				db:          ctx.DB,
+				agentID:     agentID,
+				projectName: GetProjectName(ctx.Project.Root),
+				notifier:    osNotifier{},
+				quiet:       quiet,
+				cursorPath:  cursorPath,
+				cursor:      cursor,
+			}
+			if cursor == nil {
+				// First run: start from now rather than replaying history.
+				if err := poller.prime(); err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
+
+			interval, _ := cmd.Flags().GetDuration("interval")
+			if interval <= 0 {
+				interval = 2 * time.Second
+			}
+
+			if !ctx.JSONMode {
+				fmt.Fprintf(cmd.OutOrStdout(), "--- notifying @%s (Ctrl+C to stop) ---\n", agentID)
+			}
+
+			stop := make(chan os.Signal, 1)
+			signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-stop:
+					return nil
+				case now := <-ticker.C:
+					if _, err := poller.poll(now); err != nil {
+						fmt.Fprintf(cmd.ErrOrStderr(), "notify: %v\n", err)
+					}
+				}
+			}
+		},
+	}
+
+	cmd.Flags().String("as", "", "human or agent to notify for")
+	cmd.Flags().Bool("daemonize", false, "run in the background")
+	cmd.Flags().Duration("interval", 2*time.Second, "poll interval")
+
+	return cmd
+}
+
+// startDetachedNotify re-runs `fray notify` for agentID in a new session.
+func startDetachedNotify(ctx *CommandContext, agentID string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer devNull.Close()
+
+	child := exec.Command(exe, "notify", "--as", agentID, "--project", ctx.Project.Root)
+	child.Stdin = devNull
+	child.Stdout = devNull
+	child.Stderr = devNull
+	child.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := child.Start(); err != nil {
+		return 0, err
+	}
+	pid := child.Process.Pid
+	_ = child.Process.Release()
+	return pid, nil
+}
@@ -0,0 +1,214 @@
+package command
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+type recordingNotifier struct {
+	messages []types.Message
+	bodies   []string
+	failOn   string // body that fails once, then delivers
+}
+
+func (r *recordingNotifier) Notify(msg types.Message, projectName string) error {
+	if r.failOn != "" && msg.Body == r.failOn {
+		r.failOn = ""
+		return errors.New("notification service unavailable")
+	}
+	r.messages = append(r.messages, msg)
+	r.bodies = append(r.bodies, msg.Body)
+	return nil
+}
+
+func TestNotifyPollerMentionsAndReplies(t *testing.T) {
+	projectDir := newFlowProject(t, "adam", "alice")
+
+	cursorPath := filepath.Join(projectDir, ".fray", "local", "notify-adam.json")
+	dbConn := openProjectDB(t, projectDir)
+	primer := &notifyPoller{db: dbConn, agentID: "adam", cursorPath: cursorPath}
+	if err := primer.prime(); err != nil {
+		t.Fatalf("prime: %v", err)
+	}
+	cursor := primer.cursor
+	_ = dbConn.Close()
+
+	runFray(t, "post", "--as", "adam", "here is my proposal for the api")
+	dbConn = openProjectDB(t, projectDir)
+	proposalID := findRoomMessageByBody(t, dbConn, "here is my proposal for the api")
+	_ = dbConn.Close()
+
+	runFray(t, "post", "--as", "alice", "unrelated chatter about lunch plans")
+	runFray(t, "post", "--as", "alice", "@adam can you look at this")
+	runFray(t, "post", "--as", "alice", "--reply-to", proposalID, "I think the proposal needs pagination")
+
+	dbConn = openProjectDB(t, projectDir)
+	defer dbConn.Close()
+
+	notifier := &recordingNotifier{}
+	poller := &notifyPoller{
+		db:         dbConn,
+		agentID:    "adam",
+		notifier:   notifier,
+		cursorPath: cursorPath,
+		cursor:     cursor,
+	}
+
+	sent, err := poller.poll(time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if sent != 2 {
+		t.Fatalf("expected mention and reply notifications, got %d: %v", sent, notifier.bodies)
+	}
+	if notifier.messages[0].FromAgent != "alice" {
+		t.Fatalf("expected alice's message, got %q", notifier.messages[0].FromAgent)
+	}
+
+	// A restarted poller resumes from the saved cursor and sends nothing new.
+	saved, err := readNotifyCursor(cursorPath)
+	if err != nil || saved == nil {
+		t.Fatalf("expected saved cursor: %v", err)
+	}
+	restarted := &notifyPoller{db: dbConn, agentID: "adam", notifier: notifier, cursorPath: cursorPath, cursor: saved}
+	sent, err = restarted.poll(time.Now())
+	if err != nil {
+		t.Fatalf("poll after restart: %v", err)
+	}
+	if sent != 0 {
+		t.Fatalf("expected no duplicate notifications after restart, got %d", sent)
+	}
+}
+
+func TestNotifyPollerRetriesFromFailedNotification(t *testing.T) {
+	projectDir := newFlowProject(t, "adam", "alice")
+	cursorPath := filepath.Join(projectDir, ".fray", "local", "notify-adam.json")
+	dbConn := openProjectDB(t, projectDir)
+	primer := &notifyPoller{db: dbConn, agentID: "adam", cursorPath: cursorPath}
+	if err := primer.prime(); err != nil {
+		t.Fatalf("prime: %v", err)
+	}
+	cursor := primer.cursor
+	_ = dbConn.Close()
+
+	// Distinct seconds keep the delivery order unambiguous.
+	for i, body := range []string{"@adam first", "@adam second", "@adam third"} {
+		postJSON(t, "post", "--as", "alice", body)
+		dbConn = openProjectDB(t, projectDir)
+		if _, err := dbConn.Exec("UPDATE fray_messages SET ts = ? WHERE body = ?", time.Now().Unix()+int64(i+1), body); err != nil {
+			t.Fatalf("set ts: %v", err)
+		}
+		_ = dbConn.Close()
+	}
+
+	dbConn = openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	notifier := &recordingNotifier{failOn: "@adam second"}
+	poller := &notifyPoller{db: dbConn, agentID: "adam", notifier: notifier, cursorPath: cursorPath, cursor: cursor}
+	if sent, err := poller.poll(time.Now()); err == nil || sent != 1 {
+		t.Fatalf("expected the second notification to fail after one was sent, got %d, %v", sent, err)
+	}
+
+	// A restarted poller picks up at the failed message, without repeating
+	// the first.
+	saved, err := readNotifyCursor(cursorPath)
+	if err != nil || saved == nil {
+		t.Fatalf("expected saved cursor: %v", err)
+	}
+	restarted := &notifyPoller{db: dbConn, agentID: "adam", notifier: notifier, cursorPath: cursorPath, cursor: saved}
+	if sent, err := restarted.poll(time.Now()); err != nil || sent != 2 {
+		t.Fatalf("expected the rest to be delivered, got %d, %v", sent, err)
+	}
+	want := []string{"@adam first", "@adam second", "@adam third"}
+	if strings.Join(notifier.bodies, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected each message notified once in order, got %v", notifier.bodies)
+	}
+}
+
+func TestNotifyQuietHoursSuppress(t *testing.T) {
+	projectDir := newFlowProject(t, "adam", "alice")
+	runFray(t, "config", "notify_quiet", "22:00-08:00")
+
+	cursorPath := filepath.Join(projectDir, ".fray", "local", "notify-adam.json")
+	dbConn := openProjectDB(t, projectDir)
+	primer := &notifyPoller{db: dbConn, agentID: "adam", cursorPath: cursorPath}
+	if err := primer.prime(); err != nil {
+		t.Fatalf("prime: %v", err)
+	}
+	cursor := primer.cursor
+	quietValue, _ := db.GetConfig(dbConn, "notify_quiet")
+	_ = dbConn.Close()
+
+	quiet, err := parseQuietHours(quietValue)
+	if err != nil {
+		t.Fatalf("parse quiet hours: %v", err)
+	}
+
+	runFray(t, "post", "--as", "alice", "@adam late night question")
+
+	dbConn = openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	notifier := &recordingNotifier{}
+	poller := &notifyPoller{
+		db:         dbConn,
+		agentID:    "adam",
+		notifier:   notifier,
+		quiet:      quiet,
+		cursorPath: cursorPath,
+		cursor:     cursor,
+	}
+
+	sent, err := poller.poll(time.Date(2026, 1, 1, 23, 30, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if sent != 0 || len(notifier.bodies) != 0 {
+		t.Fatalf("expected quiet hours to suppress, got %v", notifier.bodies)
+	}
+}
+
+func TestQuietHoursWindow(t *testing.T) {
+	wrap, err := parseQuietHours("22:00-08:00")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	day, err := parseQuietHours("12:00-13:30")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	at := func(h, m int) time.Time { return time.Date(2026, 1, 1, h, m, 0, 0, time.Local) }
+
+	cases := []struct {
+		q    *quietHours
+		t    time.Time
+		want bool
+	}{
+		{wrap, at(23, 0), true},
+		{wrap, at(7, 59), true},
+		{wrap, at(8, 0), false},
+		{wrap, at(12, 0), false},
+		{day, at(12, 45), true},
+		{day, at(13, 30), false},
+		{nil, at(23, 0), false},
+	}
+	for _, c := range cases {
+		if got := c.q.Contains(c.t); got != c.want {
+			t.Fatalf("Contains(%s) = %v, want %v", c.t.Format("15:04"), got, c.want)
+		}
+	}
+
+	if _, err := parseQuietHours("late"); err == nil {
+		t.Fatalf("expected invalid quiet hours to fail")
+	}
+
+	newFlowProject(t)
+	if _, err := executeCommand(NewRootCmd("test"), "config", "notify_quiet", "25:00-08:00"); ErrorCode(err) != ErrorKindValidation {
+		t.Fatalf("expected config to reject invalid notify_quiet as a validation error, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// openTarget is one thing a reference passed to fray open can point at.
+type openTarget struct {
+	Kind  string
+	ID    string
+	Label string
+	data  any
+}
+
+// NewOpenCmd creates the open command.
+func NewOpenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "open <ref>",
+		Short: "Show whatever a reference points at (message, thread, question, agent)",
+		Long: `Works out what a reference is and shows the most useful view of it:
+
+  msg-…      the message and its replies (fray get <msg>)
+  thrd-…     the thread (fray get <thread>)
+  qstn-…     the question and its answer status (fray question <id>)
+  @name      the agent (fray who <name>)
+
+References without a prefix are tried as a message, thread (GUID or name),
+question, and agent. When more than one kind matches, the candidates are
+listed; rerun with the prefixed ID. With --json the output is
+{"kind": ..., "data": ...}.
+
+Examples:
+  fray open msg-abc123
+  fray open design
+  fray open @dev --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			targets, err := resolveOpenTargets(ctx, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			switch len(targets) {
+			case 0:
+				return writeCommandError(cmd, notFoundError("nothing matches %s", args[0]))
+			case 1:
+			default:
+				lines := make([]string, 0, len(targets))
+				for _, target := range targets {
+					lines = append(lines, fmt.Sprintf("  %-8s %s  %s", target.Kind, target.ID, target.Label))
+				}
+				return writeCommandError(cmd, conflictError("%s is ambiguous; use one of:\n%s", args[0], strings.Join(lines, "\n")))
+			}
+
+			target := targets[0]
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{"kind": target.Kind, "data": target.data})
+			}
+
+			// The views open their own connection.
+			ctx.DB.Close()
+			switch target.Kind {
+			case "question":
+				return NewQuestionCmd().RunE(cmd, []string{target.ID})
+			case "agent":
+				return NewWhoCmd().RunE(cmd, []string{target.ID})
+			default:
+				return NewGetCmd().RunE(cmd, []string{target.ID})
+			}
+		},
+	}
+
+	return cmd
+}
+
+// resolveOpenTargets returns what ref may point at. A kind prefix (msg-,
+// thrd-, qstn-, @) limits the lookup to that kind; otherwise every kind is
+// tried and all matches are returned.
+func resolveOpenTargets(ctx *CommandContext, ref string) ([]openTarget, error) {
+	value := strings.TrimSpace(ref)
+	if value == "" {
+		return nil, validationError("reference is required")
+	}
+	lower := strings.ToLower(value)
+
+	if strings.HasPrefix(value, "@") {
+		agent, err := resolveAgentByRef(ctx, strings.TrimPrefix(value, "@"))
+		if err != nil {
+			return nil, err
+		}
+		return []openTarget{agentOpenTarget(*agent)}, nil
+	}
+
+	var targets []openTarget
+	prefixed := strings.HasPrefix(lower, "msg-") || strings.HasPrefix(lower, "thrd-") || strings.HasPrefix(lower, "qstn-")
+
+	if !prefixed || strings.HasPrefix(lower, "msg-") {
+		if msg, err := resolveMessageRef(ctx.DB, value); err == nil {
+			targets = append(targets, openTarget{Kind: "message", ID: msg.ID, Label: "@" + msg.FromAgent + ": " + truncateOpenLabel(msg.Body), data: msg})
+		}
+	}
+	if !prefixed || strings.HasPrefix(lower, "thrd-") {
+		if thread, err := resolveThreadRef(ctx.DB, value); err == nil {
+			label, _ := buildThreadPath(ctx.DB, thread)
+			targets = append(targets, openTarget{Kind: "thread", ID: thread.GUID, Label: label, data: thread})
+		}
+	}
+	if !prefixed || strings.HasPrefix(lower, "qstn-") {
+		if question, err := resolveQuestionRef(ctx.DB, value); err == nil {
+			targets = append(targets, openTarget{Kind: "question", ID: question.GUID, Label: truncateOpenLabel(question.Re), data: question})
+		}
+	}
+	if !prefixed {
+		agent, err := db.GetAgent(ctx.DB, ResolveAgentRef(value, ctx.ProjectConfig))
+		if err != nil {
+			return nil, err
+		}
+		if agent != nil {
+			targets = append(targets, agentOpenTarget(*agent))
+		}
+	}
+	return targets, nil
+}
+
+func agentOpenTarget(agent types.Agent) openTarget {
+	label := ""
+	if agent.Status != nil {
+		label = truncateOpenLabel(*agent.Status)
+	}
+	return openTarget{Kind: "agent", ID: agent.AgentID, Label: label, data: toAgentDetails(agent)}
+}
+
+func truncateOpenLabel(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > 50 {
+		return string(runes[:50]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func openJSON(t *testing.T, ref string) (string, map[string]any) {
+	t.Helper()
+	var payload struct {
+		Kind string         `json:"kind"`
+		Data map[string]any `json:"data"`
+	}
+	output := runFray(t, "open", ref, "--json")
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode %q: %v", output, err)
+	}
+	return payload.Kind, payload.Data
+}
+
+func TestOpenResolvesEachKind(t *testing.T) {
+	newFlowProject(t, "alice", "dev")
+	thread := postJSON(t, "thread", "design")
+	msg := postJSON(t, "post", "--as", "alice", "first idea")
+	msgID := msg["id"].(string)
+	asked := postJSON(t, "ask", "which cache?", "--to", "dev", "--as", "alice")
+	questionID := asked["question"].(map[string]any)["guid"].(string)
+
+	cases := []struct {
+		ref, kind, id, idField string
+	}{
+		{msgID, "message", msgID, "id"},
+		{strings.TrimPrefix(msgID, "msg-"), "message", msgID, "id"},
+		{"design", "thread", thread["thread"].(map[string]any)["guid"].(string), "guid"},
+		{questionID, "question", questionID, "guid"},
+		{"@dev", "agent", "dev", "agent_id"},
+	}
+	for _, tc := range cases {
+		kind, data := openJSON(t, tc.ref)
+		if kind != tc.kind || data[tc.idField] != tc.id {
+			t.Fatalf("open %s: got %s %v, want %s %s", tc.ref, kind, data[tc.idField], tc.kind, tc.id)
+		}
+	}
+
+	if output := runFray(t, "open", msgID); !strings.Contains(output, "first idea") {
+		t.Fatalf("expected the message view, got %q", output)
+	}
+	if output := runFray(t, "open", questionID); !strings.Contains(output, "status: open") {
+		t.Fatalf("expected the question view, got %q", output)
+	}
+}
+
+func TestOpenListsAmbiguousCandidates(t *testing.T) {
+	newFlowProject(t, "alice", "dev")
+	msg := postJSON(t, "post", "--as", "alice", "first idea")
+	short := strings.TrimPrefix(msg["id"].(string), "msg-")
+	runFray(t, "thread", short)
+
+	output, err := executeCommand(NewRootCmd("test"), "open", short)
+	if err == nil {
+		t.Fatalf("expected an ambiguity error, got %q", output)
+	}
+	if !strings.Contains(output, "ambiguous") || !strings.Contains(output, "message") || !strings.Contains(output, "thread") {
+		t.Fatalf("expected message and thread candidates, got %q", output)
+	}
+
+	if kind, _ := openJSON(t, msg["id"].(string)); kind != "message" {
+		t.Fatalf("expected the msg- prefix to pick the message, got %s", kind)
+	}
+	if output, err := executeCommand(NewRootCmd("test"), "open", "msg-nope"); err == nil || !strings.Contains(output, "nothing matches") {
+		t.Fatalf("expected not found, got %q (%v)", output, err)
+	}
+}
@@ -0,0 +1,112 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// NewPinboardCmd creates the pinboard command.
+func NewPinboardCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pinboard",
+		Short: "Messages faved by several agents",
+		Long: `List the project's "greatest hits": messages currently faved by at least
+--min-faves distinct agents, most faved first, newest first among ties.
+Unfaving takes a message's count back down.
+
+Examples:
+  fray pinboard
+  fray pinboard --min-faves 3 --thread design
+  fray pinboard --markdown > docs/pinboard.md`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			minFaves, _ := cmd.Flags().GetInt("min-faves")
+			if minFaves < 1 {
+				return writeCommandError(cmd, validationError("--min-faves must be at least 1"))
+			}
+			var home *string
+			if threadRef, _ := cmd.Flags().GetString("thread"); threadRef != "" {
+				thread, err := resolveThreadRef(ctx.DB, threadRef)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				home = &thread.GUID
+			}
+
+			entries, err := db.GetPinboard(ctx.DB, minFaves, home)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			out := cmd.OutOrStdout()
+			if ctx.JSONMode {
+				if entries == nil {
+					entries = []db.PinboardEntry{}
+				}
+				return json.NewEncoder(out).Encode(map[string]any{"min_faves": minFaves, "entries": entries})
+			}
+
+			homes := map[string]string{}
+			for _, entry := range entries {
+				if _, ok := homes[entry.Message.Home]; ok {
+					continue
+				}
+				name, err := resolveHomeName(ctx.DB, entry.Message.Home)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				homes[entry.Message.Home] = name
+			}
+
+			if markdown, _ := cmd.Flags().GetBool("markdown"); markdown {
+				writePinboardMarkdown(out, entries, homes, minFaves)
+				return nil
+			}
+			if len(entries) == 0 {
+				fmt.Fprintf(out, "No messages faved by %d+ agents\n", minFaves)
+				return nil
+			}
+			fmt.Fprintf(out, "Pinboard (%d message(s) faved by %d+ agents):\n", len(entries), minFaves)
+			for _, entry := range entries {
+				msg := entry.Message
+				fmt.Fprintf(out, "  ★%d  [%s] %s  @%s: %s\n", entry.FaveCount, msg.ID, homes[msg.Home], msg.FromAgent, truncateBody(msg.Body, 60))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Int("min-faves", 2, "minimum number of distinct agents who faved a message")
+	cmd.Flags().String("thread", "", "only messages in this thread")
+	cmd.Flags().Bool("markdown", false, "print as markdown for docs")
+	return cmd
+}
+
+// writePinboardMarkdown renders pinboard entries as a markdown list with the
+// full body quoted under each entry.
+func writePinboardMarkdown(out io.Writer, entries []db.PinboardEntry, homes map[string]string, minFaves int) {
+	fmt.Fprintf(out, "# Pinboard\n\nMessages faved by %d or more agents.\n", minFaves)
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "\n_Nothing yet._")
+		return
+	}
+	for _, entry := range entries {
+		msg := entry.Message
+		date := time.Unix(msg.TS, 0).Format("2006-01-02")
+		fmt.Fprintf(out, "\n- **%d faves** · %s · @%s · %s · `%s`\n", entry.FaveCount, homes[msg.Home], msg.FromAgent, date, msg.ID)
+		for _, line := range strings.Split(strings.TrimSpace(msg.Body), "\n") {
+			fmt.Fprintf(out, "  > %s\n", line)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPinboardCountsDistinctFaves(t *testing.T) {
+	newFlowProject(t, "alice", "bob", "carol")
+	runFray(t, "thread", "design")
+
+	popular := postJSON(t, "post", "--as", "alice", "the plan everyone liked")["id"].(string)
+	pair := postJSON(t, "post", "--as", "bob", "a plan two agents liked")["id"].(string)
+	lonely := postJSON(t, "post", "--as", "carol", "a plan only one agent liked")["id"].(string)
+	inThread := postJSON(t, "post", "--as", "alice", "design notes worth keeping", "--thread", "design")["id"].(string)
+
+	for _, agent := range []string{"alice", "bob", "carol"} {
+		runFray(t, "fave", popular, "--as", agent)
+	}
+	runFray(t, "fave", pair, "--as", "alice")
+	runFray(t, "fave", pair, "--as", "carol")
+	runFray(t, "fave", lonely, "--as", "bob")
+	runFray(t, "fave", inThread, "--as", "bob")
+	runFray(t, "fave", inThread, "--as", "carol")
+
+	type entry struct {
+		Message struct {
+			ID string `json:"id"`
+		} `json:"message"`
+		FaveCount int      `json:"fave_count"`
+		FavedBy   []string `json:"faved_by"`
+	}
+	pinboard := func(args ...string) []entry {
+		t.Helper()
+		output := runFray(t, append([]string{"pinboard", "--json"}, args...)...)
+		var payload struct {
+			Entries []entry `json:"entries"`
+		}
+		if err := json.Unmarshal([]byte(output), &payload); err != nil {
+			t.Fatalf("decode %q: %v", output, err)
+		}
+		return payload.Entries
+	}
+
+	entries := pinboard()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", entries)
+	}
+	if entries[0].Message.ID != popular || entries[0].FaveCount != 3 || len(entries[0].FavedBy) != 3 {
+		t.Fatalf("expected the 3-fave message first, got %+v", entries[0])
+	}
+	for _, e := range entries {
+		if e.Message.ID == lonely {
+			t.Fatalf("single-fave message should be below the threshold: %+v", entries)
+		}
+	}
+
+	runFray(t, "unfave", pair, "--as", "carol")
+	entries = pinboard()
+	if len(entries) != 2 {
+		t.Fatalf("expected the unfave to drop a message, got %+v", entries)
+	}
+	for _, e := range entries {
+		if e.Message.ID == pair {
+			t.Fatalf("unfaved message still listed: %+v", entries)
+		}
+	}
+	if got := pinboard("--min-faves", "1"); len(got) != 4 {
+		t.Fatalf("expected 4 entries at --min-faves 1, got %+v", got)
+	}
+
+	entries = pinboard("--thread", "design")
+	if len(entries) != 1 || entries[0].Message.ID != inThread || entries[0].FaveCount != 2 {
+		t.Fatalf("expected only the design message, got %+v", entries)
+	}
+
+	output := runFray(t, "pinboard")
+	if !strings.Contains(output, "★3  ["+popular+"] room  @alice: the plan everyone liked") {
+		t.Fatalf("unexpected text output %q", output)
+	}
+	output = runFray(t, "pinboard", "--markdown", "--thread", "design")
+	if !strings.HasPrefix(output, "# Pinboard") || !strings.Contains(output, "**2 faves** · design · @alice") || !strings.Contains(output, "  > design notes worth keeping") {
+		t.Fatalf("unexpected markdown output %q", output)
+	}
+	if _, err := executeCommand(NewRootCmd("test"), "pinboard", "--min-faves", "0"); err == nil {
+		t.Fatal("expected --min-faves 0 to be rejected")
+	}
+}
@@ -25,8 +25,23 @@ Paths:
   fray post meta "msg"               Post to project meta
   fray post opus/notes "msg"         Post to agent's notes
   fray post design-thread "msg"      Post to thread by name
-  fray post roles/architect/keys "msg"  Post to role's keys`,
-		Args: cobra.RangeArgs(1, 2),
+  fray post roles/architect/keys "msg"  Post to role's keys
+
+Reply targets (-r) resolve in the destination room or thread:
+  fray post -r msg-abc123 "msg"      Reply to a message (ID or prefix)
+  fray post -r @alice "agreed"       Reply to alice's most recent message
+  fray post -r last "+1"             Reply to the most recent message
+
+Flag high-signal messages with --important or an !important token in the
+body. They are marked in output, listed by 'fray get --important', and kept
+by 'fray prune' unless pruned with --with important.
+
+Mark standup reports with --standup so 'fray standup' can collect them.
+
+Long bodies can come from stdin or a file instead of an argument:
+  fray post --as dev - <<'EOF'       Read the body from stdin until EOF
+  fray post design --file notes.md   Read the body from a file`,
+		Args: cobra.MaximumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, err := GetContext(cmd)
 			if err != nil {
@@ -36,33 +51,60 @@ Paths:
 
 			agentRef, _ := cmd.Flags().GetString("as")
 			replyTo, _ := cmd.Flags().GetString("reply-to")
+			crossHome, _ := cmd.Flags().GetBool("cross-home")
 			threadRef, _ := cmd.Flags().GetString("thread")
 			answerRef, _ := cmd.Flags().GetString("answer")
 			quoteRef, _ := cmd.Flags().GetString("quote")
 			silent, _ := cmd.Flags().GetBool("silent")
-
-			// Determine path and message body
-			var messageBody string
-			if len(args) == 2 {
-				// First arg is path, second is message
+			important, _ := cmd.Flags().GetBool("important")
+			standup, _ := cmd.Flags().GetBool("standup")
+			commitRefs, _ := cmd.Flags().GetStringArray("commit")
+			withDiff, _ := cmd.Flags().GetBool("diff")
+			if withDiff && len(commitRefs) == 0 {
+				return writeCommandError(cmd, validationError("--diff requires --commit"))
+			}
+
+			// Determine path and message body. With --file, a single arg is
+			// the path, unless it does not name a thread.
+			bodyFile, _ := cmd.Flags().GetString("file")
+			bodyArgs := args
+			if len(args) == 2 || (len(args) == 1 && bodyFile != "" && threadRef == "" && args[0] != stdinBodyArg) {
 				pathArg := args[0]
-				messageBody = args[1]
+				bodyArgs = args[1:]
 
 				// Try to resolve path as thread (only if not already using --thread)
 				if threadRef == "" {
 					thread, err := resolveThreadRef(ctx.DB, pathArg)
 					if err == nil && thread != nil {
 						threadRef = thread.GUID
+					} else if len(args) == 1 {
+						return writeCommandError(cmd, validationError("got a message argument and --file; pass the body one way"))
 					} else {
-						return writeCommandError(cmd, fmt.Errorf("thread not found: %s", pathArg))
+						return writeCommandError(cmd, notFoundError("thread not found: %s", pathArg))
 					}
 				}
-			} else {
-				messageBody = args[0]
+			}
+			var bodyArg string
+			if len(bodyArgs) > 0 {
+				bodyArg = bodyArgs[0]
+			}
+			messageBody, err := readBodyInput(cmd.InOrStdin(), bodyArg, len(bodyArgs) > 0, bodyFile)
+			if err != nil {
+				return writeCommandError(cmd, err)
 			}
 
-			if agentRef == "" {
-				return writeCommandError(cmd, fmt.Errorf("--as is required"))
+			// !important anywhere in the body is shorthand for --important.
+			if stripped, marked := core.ExtractImportantMarker(messageBody); marked {
+				if stripped == "" {
+					return writeCommandError(cmd, validationError("message body is empty after removing !important"))
+				}
+				messageBody = stripped
+				important = true
+			}
+
+			agentRef, err = identityRef(ctx, agentRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
 			}
 			agentID, err := resolveAgentRef(ctx, agentRef)
 			if err != nil {
@@ -77,15 +119,15 @@ Paths:
 			// Check if this is the stored human username
 			isHumanUser := false
 			if agent == nil {
-				storedUsername, _ := db.GetConfig(ctx.DB, "username")
+				storedUsername, _ := humanIdentity(ctx)
 				if storedUsername != "" && storedUsername == agentID {
 					isHumanUser = true
 				} else {
-					return writeCommandError(cmd, fmt.Errorf("agent not found: @%s. Use 'fray new' first", agentID))
+					return writeCommandError(cmd, notFoundError("agent not found: @%s. Use 'fray new' first", agentID))
 				}
 			}
 			if agent != nil && agent.LeftAt != nil {
-				return writeCommandError(cmd, fmt.Errorf("agent @%s has left. Use 'fray back @%s' to resume", agentID, agentID))
+				return writeCommandError(cmd, conflictError("agent @%s has left. Use 'fray back @%s' to resume", agentID, agentID))
 			}
 
 			var answerQuestion *types.Question
@@ -125,7 +167,7 @@ Paths:
 				}
 				answerQuestion = question
 				if answerQuestion.Status == types.QuestionStatusClosed {
-					return writeCommandError(cmd, fmt.Errorf("question %s is closed", answerQuestion.GUID))
+					return writeCommandError(cmd, conflictError("question %s is closed", answerQuestion.GUID))
 				}
 				if threadRef == "" && answerQuestion.ThreadGUID != nil {
 					threadRef = *answerQuestion.ThreadGUID
@@ -143,7 +185,11 @@ Paths:
 			var replyID *string
 			var replyMsg *types.Message
 			if replyTo != "" {
-				msg, err := resolveMessageRef(ctx.DB, replyTo)
+				home := ""
+				if thread != nil {
+					home = thread.GUID
+				}
+				msg, err := resolveReplyTarget(ctx, replyTo, home)
 				if err != nil {
 					return writeCommandError(cmd, err)
 				}
@@ -161,7 +207,7 @@ Paths:
 			}
 
 			reactionText := ""
-			if replyID != nil && answerRef == "" {
+			if replyID != nil && answerRef == "" && !important {
 				if reaction, ok := core.NormalizeReactionText(messageBody); ok {
 					reactionText = reaction
 				}
@@ -177,6 +223,9 @@ Paths:
 				if err := db.AppendReaction(ctx.Project.DBPath, *replyID, agentID, reactionText, reactedAt); err != nil {
 					return writeCommandError(cmd, err)
 				}
+				if err := recordReactionUndo(cmd, ctx, *replyID, agentID, reactionText); err != nil {
+					return writeCommandError(cmd, err)
+				}
 
 				if !isHumanUser {
 					now := time.Now().Unix()
@@ -204,17 +253,42 @@ Paths:
 				return nil
 			}
 
+			if err := checkThreadPostRules(ctx.DB, thread, agentID, replyMsg, ctx.Force); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if replyMsg != nil {
+				home := ""
+				if thread != nil {
+					home = thread.GUID
+				}
+				if err := checkReplyTarget(ctx, replyMsg, home, crossHome); err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
+			if err := checkMessageContent(cmd, ctx, messageBody); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := checkMentionTypos(cmd.ErrOrStderr(), ctx, messageBody); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			commits, err := resolveCommits(ctx.Project.Root, commitRefs, withDiff)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
 			bases, err := db.GetAgentBases(ctx.DB)
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
 			// Include users in mentionable bases so @username mentions are extracted
-			users, _ := db.GetActiveUsers(ctx.DB)
+			users, _ := db.GetActiveUsers(ctx.DB, ctx.Project.DBPath)
 			for _, u := range users {
 				bases[u] = struct{}{}
 			}
 			mentions := core.ExtractMentions(messageBody, bases)
-			mentions = core.ExpandAllMention(mentions, bases)
+			if !threadExcludesBroadcast(thread) {
+				mentions = core.ExpandAllMention(mentions, bases)
+			}
 
 			now := time.Now().Unix()
 			home := ""
@@ -225,7 +299,7 @@ Paths:
 			if isHumanUser {
 				msgType = types.MessageTypeUser
 			}
-			created, err := db.CreateMessage(ctx.DB, types.Message{
+			created, err := createMessage(cmd, ctx, types.Message{
 				TS:               now,
 				FromAgent:        agentID,
 				Body:             messageBody,
@@ -234,6 +308,9 @@ Paths:
 				ReplyTo:          replyID,
 				QuoteMessageGUID: quoteID,
 				Type:             msgType,
+				Important:        important,
+				Standup:          standup,
+				Commits:          commits,
 			})
 			if err != nil {
 				return writeCommandError(cmd, err)
@@ -302,7 +379,7 @@ Paths:
 							toAgent = &target
 						}
 
-						question, err := db.CreateQuestion(ctx.DB, types.Question{
+						question, err := createQuestion(cmd, ctx, types.Question{
 							Re:         eq.Text,
 							FromAgent:  agentID,
 							ToAgent:    toAgent,
@@ -340,12 +417,17 @@ Paths:
 				}
 			}
 
+			crossHomeFrom := ""
+			if thread == nil && replyMsg != nil && replyMsg.Home != "room" {
+				crossHomeFrom = replyMsg.Home
+			}
 			if thread != nil && replyMsg != nil && replyMsg.Home != thread.GUID {
 				inThread, err := db.IsMessageInThread(ctx.DB, thread.GUID, replyMsg.ID)
 				if err != nil {
 					return writeCommandError(cmd, err)
 				}
 				if !inThread {
+					crossHomeFrom = replyMsg.Home
 					if err := db.AddMessageToThread(ctx.DB, thread.GUID, replyMsg.ID, agentID, now); err != nil {
 						return writeCommandError(cmd, err)
 					}
@@ -363,6 +445,9 @@ Paths:
 			if silent {
 				return nil
 			}
+			if crossHomeFrom != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: replied across homes; #%s is in %s\n", replyMsg.ID, homeLabel(ctx.DB, crossHomeFrom))
+			}
 
 			agentBase := agentID
 			if parsed, err := core.ParseAgentID(agentID); err == nil {
@@ -417,6 +502,12 @@ Paths:
 					"reply_to": replyID,
 					"unread":   len(filtered),
 				}
+				if important {
+					payload["important"] = true
+				}
+				if standup {
+					payload["standup"] = true
+				}
 				return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
 			}
 
@@ -487,14 +578,19 @@ Paths:
 		},
 	}
 
-	cmd.Flags().String("as", "", "agent ID to post as")
-	cmd.Flags().StringP("reply-to", "r", "", "reply to message GUID (threading)")
+	cmd.Flags().String("as", "", "agent ID to post as (default: FRAY_AGENT_ID, then your fray login)")
+	cmd.Flags().Bool("cross-home", false, "allow --reply-to a message in another home (links them, with a warning)")
+	cmd.Flags().Bool("allow-secrets", false, "post even if the content policy flags a secret")
+	cmd.Flags().StringP("reply-to", "r", "", "reply to message GUID, @agent (their last message here), or last")
 	cmd.Flags().String("thread", "", "post in thread (guid, name, or path)")
 	cmd.Flags().String("answer", "", "answer a question by guid or text")
 	cmd.Flags().StringP("quote", "q", "", "quote message GUID (inline quote)")
 	cmd.Flags().BoolP("silent", "s", false, "suppress output including unread mentions")
-
-	_ = cmd.MarkFlagRequired("as")
+	cmd.Flags().Bool("important", false, "flag the message as important (also: !important in the body)")
+	cmd.Flags().Bool("standup", false, "mark the message as a standup report (see fray standup)")
+	cmd.Flags().StringArray("commit", nil, "attach git commit metadata (sha, author, subject, files, +/-) for a ref (repeatable)")
+	cmd.Flags().String("file", "", "read the message body from a file")
+	cmd.Flags().Bool("diff", false, fmt.Sprintf("with --commit, also attach the patch (up to %d KB)", maxCommitPatchBytes/1024))
 
 	return cmd
 }
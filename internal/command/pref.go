@@ -0,0 +1,202 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// NewPrefCmd creates the pref command.
+func NewPrefCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pref",
+		Short: "Per-thread hints for client UIs",
+		Long: `Store per-thread hints such as priority, sound, or color for GUI clients,
+so each client does not invent its own.
+
+Prefs are yours and stay on this machine (.fray/local/client_prefs.json)
+unless --shared, which records a team-wide pref in threads.jsonl for
+everyone. Your local prefs override shared ones. Keys are free-form, up to
+64 bytes; values up to 1024 bytes.
+
+Examples:
+  fray pref set design priority high --as adam
+  fray pref set design color teal --shared --as adam
+  fray pref list --as adam
+  fray pref unset design priority --as adam`,
+	}
+
+	cmd.AddCommand(NewPrefSetCmd(), NewPrefUnsetCmd(), NewPrefListCmd())
+	return cmd
+}
+
+// NewPrefSetCmd creates the pref set command.
+func NewPrefSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <thread> <key> <value>",
+		Short: "Set a client pref for a thread",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if args[2] == "" {
+				return writeCommandError(cmd, validationError("pref value is required; use fray pref unset to remove one"))
+			}
+			return runPrefWrite(cmd, args[0], args[1], args[2])
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent or human setting the pref (default: FRAY_AGENT_ID, then your fray login)")
+	cmd.Flags().Bool("shared", false, "record a team-wide pref instead of a local one")
+	return cmd
+}
+
+// NewPrefUnsetCmd creates the pref unset command.
+func NewPrefUnsetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset <thread> <key>",
+		Short: "Remove a client pref from a thread",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPrefWrite(cmd, args[0], args[1], "")
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent or human removing the pref (default: FRAY_AGENT_ID, then your fray login)")
+	cmd.Flags().Bool("shared", false, "remove the team-wide pref instead of the local one")
+	return cmd
+}
+
+// NewPrefListCmd creates the pref list command.
+func NewPrefListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [thread]",
+		Short: "Show the client prefs that apply to you",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			asRef, _ := cmd.Flags().GetString("as")
+			asRef, err = identityRef(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			agentID, err := resolveAgentRef(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			prefs, err := db.GetClientPrefs(ctx.Project.DBPath, agentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if len(args) == 1 {
+				thread, err := resolveThreadRef(ctx.DB, args[0])
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				filtered := prefs[:0]
+				for _, pref := range prefs {
+					if pref.ThreadGUID == thread.GUID {
+						filtered = append(filtered, pref)
+					}
+				}
+				prefs = filtered
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"agent_id": agentID,
+					"prefs":    prefs,
+				})
+			}
+			out := cmd.OutOrStdout()
+			if len(prefs) == 0 {
+				fmt.Fprintln(out, "No client prefs")
+				return nil
+			}
+			for _, pref := range prefs {
+				name := pref.ThreadGUID
+				if thread, _ := db.GetThread(ctx.DB, pref.ThreadGUID); thread != nil {
+					name = thread.Name
+				}
+				scope := "local"
+				if pref.Shared {
+					scope = "shared by @" + pref.SetBy
+				}
+				fmt.Fprintf(out, "%s  %s = %s  (%s)\n", name, pref.Key, pref.Value, scope)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("as", "", "whose prefs to show (default: FRAY_AGENT_ID, then your fray login)")
+	return cmd
+}
+
+// runPrefWrite sets or, with an empty value, unsets a pref.
+func runPrefWrite(cmd *cobra.Command, threadRef, key, value string) error {
+	ctx, err := GetContext(cmd)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+	defer ctx.DB.Close()
+
+	asRef, _ := cmd.Flags().GetString("as")
+	asRef, err = identityRef(ctx, asRef)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+	agentID, err := resolveAgentRef(ctx, asRef)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+	thread, err := resolveThreadRef(ctx.DB, threadRef)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+	key = strings.TrimSpace(key)
+	if err := db.ValidateClientPref(key, value); err != nil {
+		return writeCommandError(cmd, validationError("%s", err))
+	}
+
+	shared, _ := cmd.Flags().GetBool("shared")
+	pref := types.ClientPref{
+		ThreadGUID: thread.GUID,
+		Key:        key,
+		Value:      value,
+		Shared:     shared,
+		SetBy:      agentID,
+		SetAt:      time.Now().Unix(),
+	}
+	if shared {
+		err = db.AppendClientPref(ctx.Project.DBPath, pref)
+	} else {
+		pref.AgentID = agentID
+		err = db.SetLocalClientPref(ctx.Project.DBPath, pref)
+	}
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+
+	if ctx.JSONMode {
+		return json.NewEncoder(cmd.OutOrStdout()).Encode(pref)
+	}
+	scope := "local"
+	if shared {
+		scope = "shared"
+	}
+	if value == "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "Unset %s on %s (%s)\n", key, thread.Name, scope)
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Set %s = %s on %s (%s)\n", key, value, thread.Name, scope)
+	return nil
+}
@@ -0,0 +1,91 @@
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func listPrefs(t *testing.T, args ...string) []types.ClientPref {
+	t.Helper()
+	output := runFray(t, append([]string{"pref", "list", "--json"}, args...)...)
+	var payload struct {
+		AgentID string             `json:"agent_id"`
+		Prefs   []types.ClientPref `json:"prefs"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode prefs: %v\n%s", err, output)
+	}
+	return payload.Prefs
+}
+
+func TestClientPrefsScopeAndOverwrite(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "design")
+
+	runFray(t, "pref", "set", "design", "priority", "high", "--as", "alice")
+	runFray(t, "pref", "set", "design", "priority", "low", "--as", "alice")
+	runFray(t, "pref", "set", "design", "color", "teal", "--shared", "--as", "alice")
+
+	prefs := listPrefs(t, "--as", "alice")
+	if len(prefs) != 2 || prefs[0].Key != "color" || prefs[1].Key != "priority" || prefs[1].Value != "low" {
+		t.Fatalf("expected color and the overwritten priority, got %+v", prefs)
+	}
+	if !prefs[0].Shared || prefs[0].AgentID != "" || prefs[0].SetBy != "alice" || prefs[1].Shared || prefs[1].AgentID != "alice" {
+		t.Fatalf("expected one shared and one local pref, got %+v", prefs)
+	}
+
+	// bob sees the shared pref but not alice's local one, and can override it locally.
+	prefs = listPrefs(t, "design", "--as", "bob")
+	if len(prefs) != 1 || prefs[0].Value != "teal" {
+		t.Fatalf("expected only the shared pref for bob, got %+v", prefs)
+	}
+	runFray(t, "pref", "set", "design", "color", "red", "--as", "bob")
+	if prefs = listPrefs(t, "--as", "bob"); len(prefs) != 1 || prefs[0].Value != "red" || prefs[0].Shared {
+		t.Fatalf("expected bob's local color to win, got %+v", prefs)
+	}
+
+	// Shared prefs live in threads.jsonl; local ones never do.
+	threads, err := os.ReadFile(filepath.Join(projectDir, ".fray", "threads.jsonl"))
+	if err != nil {
+		t.Fatalf("read threads.jsonl: %v", err)
+	}
+	if !strings.Contains(string(threads), `"teal"`) || strings.Contains(string(threads), `"low"`) {
+		t.Fatalf("expected only the shared pref synced, got %s", threads)
+	}
+
+	runFray(t, "pref", "unset", "design", "color", "--shared", "--as", "alice")
+	runFray(t, "pref", "unset", "design", "priority", "--as", "alice")
+	if prefs = listPrefs(t, "--as", "alice"); len(prefs) != 0 {
+		t.Fatalf("expected no prefs after unset, got %+v", prefs)
+	}
+
+	project, err := core.DiscoverProject(projectDir)
+	if err != nil {
+		t.Fatalf("discover project: %v", err)
+	}
+	prefs, err = db.GetClientPrefs(project.DBPath, "bob")
+	if err != nil || len(prefs) != 1 || prefs[0].ThreadGUID == "" || prefs[0].Key != "color" {
+		t.Fatalf("expected bob's local pref by thread guid, got %+v (%v)", prefs, err)
+	}
+}
+
+func TestClientPrefSizeCaps(t *testing.T) {
+	newFlowProject(t, "alice")
+	runFray(t, "thread", "design")
+
+	long := strings.Repeat("k", db.ClientPrefKeyMax+1)
+	if _, err := executeCommand(NewRootCmd("test"), "pref", "set", "design", long, "x", "--as", "alice"); err == nil {
+		t.Fatal("expected an oversized key to be rejected")
+	}
+	big := strings.Repeat("v", db.ClientPrefValueMax+1)
+	if _, err := executeCommand(NewRootCmd("test"), "pref", "set", "design", "note", big, "--as", "alice"); err == nil {
+		t.Fatal("expected an oversized value to be rejected")
+	}
+}
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// privacyTradeoff is printed wherever private records come up.
+const privacyTradeoff = `Private records stay in .fray/local/private.jsonl on this machine. They are
+not synced, so other machines and teammates never see them, and a fresh clone
+or another checkout starts without them.`
+
+// NewPrivacyCmd creates the privacy command.
+func NewPrivacyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "privacy",
+		Short: "Keep faves, subscriptions, and handoff cursors out of shared JSONL",
+		Long: `Some record classes are personal working state. Listing them in the
+private_records config (local or global scope) writes them to
+.fray/local/private.jsonl instead of the shared JSONL files; rebuilds read
+both.
+
+Classes:
+  faves           fave/unfave events
+  subscriptions   thread subscribe/unsubscribe events
+  read_markers    handoff read positions (ghost cursors); per-agent read
+                  markers are cache-only and never synced
+
+Changing the setting only affects new records. Run fray privacy migrate to
+move existing records to where the setting says they belong.
+
+` + privacyTradeoff + `
+
+Examples:
+  fray config private_records faves,subscriptions --scope local
+  fray privacy migrate
+  fray privacy`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			counts, err := db.CountPrivateRecords(ctx.Project.DBPath)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{"classes": counts})
+			}
+			writePrivacyStatus(cmd.OutOrStdout(), counts)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(newPrivacyMigrateCmd())
+	return cmd
+}
+
+func newPrivacyMigrateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Move existing records to match private_records",
+		Long: `Moves records of private classes from the shared JSONL files into
+.fray/local/private.jsonl, and records of classes no longer private back into
+the shared files. Safe to run repeatedly.
+
+` + privacyTradeoff,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			moved, err := db.MigratePrivateRecords(ctx.Project.DBPath)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			counts, err := db.CountPrivateRecords(ctx.Project.DBPath)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{"moved": moved, "classes": counts})
+			}
+
+			out := cmd.OutOrStdout()
+			total := 0
+			for _, count := range counts {
+				if n := moved[count.Class]; n > 0 {
+					where := "shared files"
+					if count.Private {
+						where = "private.jsonl"
+					}
+					fmt.Fprintf(out, "Moved %d %s record(s) to %s\n", n, count.Class, where)
+					total += n
+				}
+			}
+			if total == 0 {
+				fmt.Fprintln(out, "Nothing to move; records already match private_records")
+			}
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, privacyTradeoff)
+			return nil
+		},
+	}
+}
+
+func writePrivacyStatus(out io.Writer, counts []db.PrivateRecordCount) {
+	for _, count := range counts {
+		state := "shared"
+		if count.Private {
+			state = "private"
+		}
+		fmt.Fprintf(out, "%-14s %-8s shared: %d  local: %d\n", count.Class, state, count.Shared, count.Local)
+		if count.Private && count.Shared > 0 {
+			fmt.Fprintf(out, "  %d record(s) still shared; run fray privacy migrate\n", count.Shared)
+		} else if !count.Private && count.Local > 0 {
+			fmt.Fprintf(out, "  %d record(s) still private; run fray privacy migrate\n", count.Local)
+		}
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, privacyTradeoff)
+}
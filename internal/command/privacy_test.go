@@ -0,0 +1,33 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrivacyMigrateMovesFaves(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	msg := postJSON(t, "post", "--as", "alice", "keep this one")
+	runFray(t, "fave", msg["id"].(string), "--as", "alice")
+
+	if output, err := executeCommand(NewRootCmd("test"), "config", "private_records", "faves"); err == nil {
+		t.Fatalf("expected private_records to be refused at project scope, got %q", output)
+	}
+	runFray(t, "config", "private_records", "faves", "--scope", "local")
+
+	output := runFray(t, "privacy", "migrate")
+	if !strings.Contains(output, "Moved 1 faves record(s) to private.jsonl") || !strings.Contains(output, "not synced") {
+		t.Fatalf("unexpected migrate output %q", output)
+	}
+	agents, err := os.ReadFile(filepath.Join(projectDir, ".fray", "agents.jsonl"))
+	if err != nil || strings.Contains(string(agents), `"agent_fave"`) {
+		t.Fatalf("expected the fave to leave agents.jsonl (%v)", err)
+	}
+
+	runFray(t, "rebuild", "--quiet")
+	if output := runFray(t, "faves", "--as", "alice"); !strings.Contains(output, msg["id"].(string)) {
+		t.Fatalf("expected the private fave after rebuild, got %q", output)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
 	"github.com/spf13/cobra"
 )
 
@@ -33,11 +34,35 @@ func NewPruneCmd() *cobra.Command {
 				return writeCommandError(cmd, fmt.Errorf("invalid --keep value: %d", keep))
 			}
 
-			if err := checkPruneGuardrails(ctx.Project.Root); err != nil {
+			skipGit, _ := cmd.Flags().GetBool("skip-git-check")
+			yes, _ := cmd.Flags().GetBool("yes")
+			with, _ := cmd.Flags().GetStringSlice("with")
+			required, err := parsePruneWith(with)
+			if err != nil {
 				return writeCommandError(cmd, err)
 			}
 
-			result, err := pruneMessages(ctx.Project.DBPath, keep, pruneAll)
+			guard, err := checkPruneGuardrails(ctx.Project.Root, skipGit)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if !ctx.JSONMode {
+				for _, notice := range guard.Notices {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Note: %s\n", notice)
+				}
+			}
+			// Without git there is no commit to recover from, so prune only runs
+			// when confirmed and always archives to history.jsonl first.
+			backup := !guard.Git
+			if backup && !yes {
+				return writeCommandError(cmd, fmt.Errorf("git checks unavailable. Re-run with --yes to prune (messages.jsonl is backed up to history.jsonl)"))
+			}
+
+			if backup && pruneAll {
+				keep, pruneAll = 0, false
+			}
+
+			result, err := pruneMessages(ctx.Project.DBPath, keep, pruneAll, required)
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
@@ -45,11 +70,15 @@ func NewPruneCmd() *cobra.Command {
 			if err := db.RebuildDatabaseFromJSONL(ctx.DB, ctx.Project.DBPath); err != nil {
 				return writeCommandError(cmd, err)
 			}
+			if err := recordUndoBarrier(cmd, ctx, "prune"); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
 			if ctx.JSONMode {
 				payload := map[string]any{
 					"kept":     result.Kept,
 					"archived": result.Archived,
+					"notices":  guard.Notices,
 				}
 				if result.ClearedHistory {
 					payload["history"] = nil
@@ -70,7 +99,10 @@ func NewPruneCmd() *cobra.Command {
 	}
 
 	cmd.Flags().Int("keep", 20, "number of recent messages to keep")
-	cmd.Flags().Bool("all", false, "delete history.jsonl before pruning")
+	cmd.Flags().Bool("all", false, "delete history.jsonl before pruning (history is kept without git checks)")
+	cmd.Flags().Bool("yes", false, "confirm pruning when git checks are unavailable")
+	cmd.Flags().Bool("skip-git-check", false, "skip git guardrails (e.g. in CI); requires --yes")
+	cmd.Flags().StringSlice("with", nil, "also prune messages that are kept by default (important)")
 	return cmd
 }
 
@@ -81,7 +113,33 @@ type pruneResult struct {
 	ClearedHistory bool
 }
 
-func pruneMessages(projectPath string, keep int, pruneAll bool) (pruneResult, error) {
+// requiredMessageOptions controls which optional categories of messages
+// collectRequiredMessageIDs preserves on top of the integrity set.
+type requiredMessageOptions struct {
+	ProtectImportant bool
+}
+
+// defaultRequiredMessageOptions protects every optional category.
+func defaultRequiredMessageOptions() requiredMessageOptions {
+	return requiredMessageOptions{ProtectImportant: true}
+}
+
+// parsePruneWith turns --with values into required-message options, dropping
+// protection for each named category.
+func parsePruneWith(values []string) (requiredMessageOptions, error) {
+	opts := defaultRequiredMessageOptions()
+	for _, value := range values {
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "important":
+			opts.ProtectImportant = false
+		default:
+			return opts, fmt.Errorf("invalid --with value: %s (valid: important)", value)
+		}
+	}
+	return opts, nil
+}
+
+func pruneMessages(projectPath string, keep int, pruneAll bool, opts requiredMessageOptions) (pruneResult, error) {
 	frayDir := resolveFrayDir(projectPath)
 	messagesPath := filepath.Join(frayDir, "messages.jsonl")
 	historyPath := filepath.Join(frayDir, "history.jsonl")
@@ -90,6 +148,12 @@ func pruneMessages(projectPath string, keep int, pruneAll bool) (pruneResult, er
 		keep = 0
 	}
 
+	release, err := db.LockJSONL(projectPath)
+	if err != nil {
+		return pruneResult{}, err
+	}
+	defer release()
+
 	// Handle history archival
 	if pruneAll {
 		if err := os.Remove(historyPath); err != nil && !os.IsNotExist(err) {
@@ -112,7 +176,7 @@ func pruneMessages(projectPath string, keep int, pruneAll bool) (pruneResult, er
 	}
 
 	// Collect IDs that must be preserved for integrity
-	requiredIDs, err := collectRequiredMessageIDs(projectPath)
+	requiredIDs, err := collectRequiredMessageIDs(projectPath, opts)
 	if err != nil {
 		return pruneResult{}, err
 	}
@@ -124,7 +188,8 @@ func pruneMessages(projectPath string, keep int, pruneAll bool) (pruneResult, er
 		kept = messages[len(messages)-keep:]
 	}
 
-	if keep > 0 && len(kept) > 0 && len(kept) < len(messages) {
+	// Required messages survive every prune, --keep 0 and --all included.
+	if len(kept) < len(messages) {
 		keepIDs := make(map[string]struct{}, len(kept))
 		byID := make(map[string]db.MessageJSONLRecord, len(messages))
 		for _, msg := range messages {
@@ -218,8 +283,9 @@ func pruneMessages(projectPath string, keep int, pruneAll bool) (pruneResult, er
 	return pruneResult{Kept: len(kept), Archived: archived, HistoryPath: historyPath, ClearedHistory: pruneAll}, nil
 }
 
-// collectRequiredMessageIDs gathers message IDs that must be preserved for data integrity.
-func collectRequiredMessageIDs(projectPath string) (map[string]struct{}, error) {
+// collectRequiredMessageIDs gathers message IDs that must be preserved for data
+// integrity, plus any optional categories enabled in opts.
+func collectRequiredMessageIDs(projectPath string, opts requiredMessageOptions) (map[string]struct{}, error) {
 	required := make(map[string]struct{})
 	frayDir := resolveFrayDir(projectPath)
 
@@ -268,6 +334,17 @@ func collectRequiredMessageIDs(projectPath string) (map[string]struct{}, error)
 		if q.AnsweredIn != nil && *q.AnsweredIn != "" {
 			required[*q.AnsweredIn] = struct{}{}
 		}
+		// Evidence cited by an open question's options stays readable
+		// until it is answered. Thread refs are not messages.
+		if q.Status == string(types.QuestionStatusOpen) {
+			for _, opt := range q.Options {
+				for _, ref := range opt.Refs {
+					if strings.HasPrefix(ref, "msg-") {
+						required[ref] = struct{}{}
+					}
+				}
+			}
+		}
 	}
 
 	// Read messages for surface references
@@ -283,6 +360,9 @@ func collectRequiredMessageIDs(projectPath string) (map[string]struct{}, error)
 		if msg.SurfaceMessage != nil && *msg.SurfaceMessage != "" {
 			required[*msg.SurfaceMessage] = struct{}{}
 		}
+		if opts.ProtectImportant && msg.Important {
+			required[msg.ID] = struct{}{}
+		}
 	}
 
 	// Read thread_message events to preserve messages added to threads
@@ -353,7 +433,7 @@ func writeMessagesWithEvents(path string, messages []db.MessageJSONLRecord, keep
 	// Write messages first
 	for _, record := range messages {
 		record.Type = "message"
-		data, err := json.Marshal(record)
+		data, err := db.MarshalRecord(record)
 		if err != nil {
 			return err
 		}
@@ -378,10 +458,11 @@ func writeMessagesWithEvents(path string, messages []db.MessageJSONLRecord, keep
 		case "message_update":
 			// Check if the updated message is being kept
 			if _, ok := keepIDs[envelope.ID]; ok {
-				builder.WriteString(line)
-				builder.WriteByte('\n')
+				if err := writeEventLine(&builder, line); err != nil {
+					return err
+				}
 			}
-		case "message_pin", "message_unpin":
+		case "message_pin", "message_unpin", "announcement", "announcement_ack", "announcement_reminder":
 			// These use message_guid instead of id
 			var pinEvent struct {
 				MessageGUID string `json:"message_guid"`
@@ -390,8 +471,9 @@ func writeMessagesWithEvents(path string, messages []db.MessageJSONLRecord, keep
 				continue
 			}
 			if _, ok := keepIDs[pinEvent.MessageGUID]; ok {
-				builder.WriteString(line)
-				builder.WriteByte('\n')
+				if err := writeEventLine(&builder, line); err != nil {
+					return err
+				}
 			}
 		case "message_move":
 			var moveEvent struct {
@@ -401,8 +483,9 @@ func writeMessagesWithEvents(path string, messages []db.MessageJSONLRecord, keep
 				continue
 			}
 			if _, ok := keepIDs[moveEvent.MessageGUID]; ok {
-				builder.WriteString(line)
-				builder.WriteByte('\n')
+				if err := writeEventLine(&builder, line); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -410,6 +493,18 @@ func writeMessagesWithEvents(path string, messages []db.MessageJSONLRecord, keep
 	return os.WriteFile(path, []byte(builder.String()), 0o644)
 }
 
+// writeEventLine copies a kept event line, stamping its version if it was
+// written before versioning.
+func writeEventLine(builder *strings.Builder, line string) error {
+	stamped, err := db.StampRecordLine([]byte(line))
+	if err != nil {
+		return err
+	}
+	builder.Write(stamped)
+	builder.WriteByte('\n')
+	return nil
+}
+
 func resolveFrayDir(projectPath string) string {
 	if strings.HasSuffix(projectPath, ".db") {
 		return filepath.Dir(projectPath)
@@ -428,7 +523,7 @@ func writeMessages(path string, records []db.MessageJSONLRecord) error {
 	var builder strings.Builder
 	for _, record := range records {
 		record.Type = "message"
-		data, err := json.Marshal(record)
+		data, err := db.MarshalRecord(record)
 		if err != nil {
 			return err
 		}
@@ -454,43 +549,63 @@ func appendFile(path string, data []byte) error {
 	return err
 }
 
-func checkPruneGuardrails(root string) error {
+// pruneGuardrails records how checkPruneGuardrails vetted the project.
+type pruneGuardrails struct {
+	// Git is false when git checks could not run (no git, not a repo, or
+	// --skip-git-check); prune then needs --yes and always backs up.
+	Git     bool
+	Notices []string
+}
+
+func checkPruneGuardrails(root string, skipGit bool) (pruneGuardrails, error) {
+	if skipGit {
+		return pruneGuardrails{Notices: []string{"git checks skipped (--skip-git-check)"}}, nil
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return pruneGuardrails{Notices: []string{"git not found; skipping git checks"}}, nil
+	}
+	if _, err := runGitCommand(root, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return pruneGuardrails{Notices: []string{"not a git repository; skipping git checks"}}, nil
+	}
+
+	guard := pruneGuardrails{Git: true}
 	status, err := runGitCommand(root, "status", "--porcelain", ".fray/")
 	if err != nil {
-		return err
+		return guard, err
 	}
 	if strings.TrimSpace(status) != "" {
-		return fmt.Errorf("uncommitted changes in .fray/. Commit first")
+		return guard, fmt.Errorf("uncommitted changes in .fray/. Commit first")
 	}
 
 	_, err = runGitCommand(root, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
 	if err != nil {
-		return nil
+		guard.Notices = append(guard.Notices, "no upstream branch; skipping ahead/behind check")
+		return guard, nil
 	}
 
 	aheadStr, err := runGitCommand(root, "rev-list", "--count", "@{u}..HEAD")
 	if err != nil {
-		return err
+		return guard, err
 	}
 	behindStr, err := runGitCommand(root, "rev-list", "--count", "HEAD..@{u}")
 	if err != nil {
-		return err
+		return guard, err
 	}
 
 	ahead, err := strconv.Atoi(strings.TrimSpace(aheadStr))
 	if err != nil {
-		return err
+		return guard, err
 	}
 	behind, err := strconv.Atoi(strings.TrimSpace(behindStr))
 	if err != nil {
-		return err
+		return guard, err
 	}
 
 	if ahead > 0 || behind > 0 {
-		return fmt.Errorf("branch not synced. Push/pull first")
+		return guard, fmt.Errorf("branch not synced. Push/pull first")
 	}
 
-	return nil
+	return guard, nil
 }
 
 func runGitCommand(root string, args ...string) (string, error) {
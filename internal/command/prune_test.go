@@ -0,0 +1,130 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gitInit(t *testing.T, dir string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+}
+
+func TestPruneNonGitRequiresYesAndBacksUp(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "post", "--as", "alice", "first note")
+	runFray(t, "post", "--as", "alice", "second note")
+
+	output, err := executeCommand(NewRootCmd("test"), "prune", "--keep", "1")
+	if err == nil || !strings.Contains(output, "--yes") {
+		t.Fatalf("expected prune to require --yes outside git, got %v %q", err, output)
+	}
+
+	output = runFray(t, "prune", "--all", "--yes")
+	if !strings.Contains(output, "not a git repository") {
+		t.Fatalf("expected non-git notice, got %q", output)
+	}
+	history, err := os.ReadFile(filepath.Join(projectDir, ".fray", "history.jsonl"))
+	if err != nil {
+		t.Fatalf("expected history backup: %v", err)
+	}
+	if !strings.Contains(string(history), "second note") {
+		t.Fatalf("expected messages backed up to history.jsonl")
+	}
+}
+
+func TestPruneGitWithoutUpstreamSkipsSyncCheck(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "post", "--as", "alice", "first note")
+	runFray(t, "post", "--as", "alice", "second note")
+	gitInit(t, projectDir)
+
+	output := runFray(t, "prune", "--keep", "1")
+	if !strings.Contains(output, "no upstream branch") {
+		t.Fatalf("expected no-upstream notice, got %q", output)
+	}
+}
+
+func TestPruneDirtyFrayDir(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	gitInit(t, projectDir)
+	runFray(t, "post", "--as", "alice", "uncommitted note")
+
+	output, err := executeCommand(NewRootCmd("test"), "prune", "--keep", "1")
+	if err == nil || !strings.Contains(output, "uncommitted changes in .fray/") {
+		t.Fatalf("expected dirty .fray/ to block prune, got %v %q", err, output)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "prune", "--keep", "1", "--skip-git-check"); err == nil {
+		t.Fatalf("expected --skip-git-check to still require --yes")
+	}
+	runFray(t, "prune", "--keep", "1", "--skip-git-check", "--yes")
+}
+
+func TestPruneKeepsImportantByDefault(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "post", "--as", "alice", "--important", "release checklist")
+	runFray(t, "post", "--as", "alice", "first note")
+	runFray(t, "post", "--as", "alice", "second note")
+
+	readMessages := func() string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(projectDir, ".fray", "messages.jsonl"))
+		if err != nil {
+			t.Fatalf("read messages: %v", err)
+		}
+		return string(data)
+	}
+
+	runFray(t, "prune", "--keep", "1", "--yes")
+	if data := readMessages(); !strings.Contains(data, "release checklist") || strings.Contains(data, "first note") {
+		t.Fatalf("expected important message kept and old note pruned, got %q", data)
+	}
+
+	runFray(t, "prune", "--keep", "1", "--yes", "--with", "important")
+	if data := readMessages(); strings.Contains(data, "release checklist") {
+		t.Fatalf("expected --with important to prune the important message, got %q", data)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "prune", "--keep", "1", "--yes", "--with", "pins"); err == nil {
+		t.Fatal("expected unknown --with category to fail")
+	}
+}
+
+func TestPruneKeepZeroAndAllKeepRequiredMessages(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	readMessages := func() string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(projectDir, ".fray", "messages.jsonl"))
+		if err != nil {
+			t.Fatalf("read messages: %v", err)
+		}
+		return string(data)
+	}
+
+	runFray(t, "post", "--as", "alice", "--important", "release checklist")
+	runFray(t, "post", "--as", "alice", "passing note")
+	runFray(t, "prune", "--keep", "0", "--yes")
+	if data := readMessages(); !strings.Contains(data, "release checklist") || strings.Contains(data, "passing note") {
+		t.Fatalf("expected --keep 0 to keep only the important message, got %q", data)
+	}
+
+	runFray(t, "post", "--as", "alice", "another passing note")
+	runFray(t, "prune", "--all", "--yes")
+	if data := readMessages(); !strings.Contains(data, "release checklist") || strings.Contains(data, "another passing note") {
+		t.Fatalf("expected --all to keep the important message, got %q", data)
+	}
+}
@@ -0,0 +1,346 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// publishMaxDepth caps reply indentation in published pages.
+const publishMaxDepth = 4
+
+// NewPublishCmd creates the publish command.
+func NewPublishCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish <thread> [thread...]",
+		Short: "Export threads as a self-contained, read-only HTML page",
+		Long: `Render one or more threads as a single HTML file with inline CSS and no
+JavaScript, for sharing outside the team. The page shows each thread's
+anchor, pinned messages, and messages with reply indentation and reactions.
+Publishing several threads adds an index at the top.
+
+--redact replaces a participant's name (and @mentions of it) with a
+placeholder.
+
+Examples:
+  fray publish design --out design.html
+  fray publish design api-review --out review.html --redact @pm`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			outPath, _ := cmd.Flags().GetString("out")
+			redactRefs, _ := cmd.Flags().GetStringArray("redact")
+
+			redactions := map[string]string{}
+			for i, ref := range redactRefs {
+				agentID, err := resolveAgentRef(ctx, ref)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				redactions[agentID] = fmt.Sprintf("participant-%d", i+1)
+			}
+
+			page := publishPage{Generated: time.Now().UTC().Format("2006-01-02 15:04 UTC")}
+			for _, ref := range args {
+				thread, err := resolveThreadRef(ctx.DB, ref)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				section, err := buildPublishThread(ctx, thread, redactions)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				page.Threads = append(page.Threads, section)
+			}
+			page.Title = page.Threads[0].Path
+			if len(page.Threads) > 1 {
+				page.Title = fmt.Sprintf("%d threads", len(page.Threads))
+			}
+
+			var buf bytes.Buffer
+			if err := renderPublishHTML(&buf, page); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if outPath == "" || outPath == "-" {
+				_, err := cmd.OutOrStdout().Write(buf.Bytes())
+				return err
+			}
+			if err := os.WriteFile(outPath, buf.Bytes(), 0o644); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{"out": outPath, "threads": len(page.Threads), "bytes": buf.Len()})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Published %s to %s\n", page.Title, outPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("out", "", "file to write (default: stdout)")
+	cmd.Flags().StringArray("redact", nil, "participant to replace with a placeholder (repeatable)")
+
+	return cmd
+}
+
+// publishPage is everything a published HTML page renders.
+type publishPage struct {
+	Title     string
+	Generated string
+	Threads   []publishThread
+}
+
+// publishThread is one thread section of a published page.
+type publishThread struct {
+	ID       string // HTML anchor for the index
+	Path     string
+	Anchor   *publishMessage
+	Pinned   []publishMessage
+	Messages []publishMessage
+}
+
+// publishMessage is a rendered message: author, time, body, and its place in
+// the reply tree. ID is the element ID and is empty for repeated copies.
+type publishMessage struct {
+	ID        string
+	Author    string
+	Avatar    string
+	Color     template.CSS
+	Time      string
+	Body      string
+	Depth     int
+	Pinned    bool
+	Edited    bool
+	Reactions []publishReaction
+}
+
+// publishReaction is one reaction and who left it.
+type publishReaction struct {
+	Reaction string
+	Count    int
+	Who      string
+}
+
+// buildPublishThread gathers a thread's anchor, pins, and visible messages.
+// Event messages and archived messages are left out.
+func buildPublishThread(ctx *CommandContext, thread *types.Thread, redactions map[string]string) (publishThread, error) {
+	path, err := buildThreadPath(ctx.DB, thread)
+	if err != nil {
+		return publishThread{}, err
+	}
+	section := publishThread{ID: "thread-" + thread.GUID, Path: path}
+
+	avatars := map[string]string{}
+	avatarFor := func(agentID string) string {
+		if avatar, ok := avatars[agentID]; ok {
+			return avatar
+		}
+		avatar := ""
+		if agent, err := db.GetAgent(ctx.DB, agentID); err == nil && agent != nil && agent.Avatar != nil {
+			avatar = *agent.Avatar
+		}
+		avatars[agentID] = avatar
+		return avatar
+	}
+
+	pinned, err := db.GetPinnedMessages(ctx.DB, thread.GUID)
+	if err != nil {
+		return publishThread{}, err
+	}
+	pinnedIDs := map[string]bool{}
+	for _, msg := range pinned {
+		pinnedIDs[msg.ID] = true
+		pin := toPublishMessage(msg, 0, true, avatarFor(msg.FromAgent), redactions)
+		pin.ID = "" // the message itself carries the ID in the list below
+		section.Pinned = append(section.Pinned, pin)
+	}
+
+	if thread.AnchorMessageGUID != nil {
+		anchor, err := db.GetMessage(ctx.DB, *thread.AnchorMessageGUID)
+		if err != nil {
+			return publishThread{}, err
+		}
+		if anchor != nil {
+			rendered := toPublishMessage(*anchor, 0, pinnedIDs[anchor.ID], avatarFor(anchor.FromAgent), redactions)
+			section.Anchor = &rendered
+		}
+	}
+
+	messages, err := db.GetThreadMessages(ctx.DB, thread.GUID)
+	if err != nil {
+		return publishThread{}, err
+	}
+	depth := map[string]int{}
+	for _, msg := range messages {
+		if msg.Type == types.MessageTypeEvent || msg.ArchivedAt != nil {
+			continue
+		}
+		if thread.AnchorMessageGUID != nil && msg.ID == *thread.AnchorMessageGUID {
+			continue
+		}
+		level := 0
+		if msg.ReplyTo != nil {
+			if parent, ok := depth[*msg.ReplyTo]; ok {
+				level = min(parent+1, publishMaxDepth)
+			}
+		}
+		depth[msg.ID] = level
+		section.Messages = append(section.Messages, toPublishMessage(msg, level, pinnedIDs[msg.ID], avatarFor(msg.FromAgent), redactions))
+	}
+	return section, nil
+}
+
+// toPublishMessage renders msg for a page, applying redactions to the author,
+// reactors, and @mentions in the body.
+func toPublishMessage(msg types.Message, depth int, pinned bool, avatar string, redactions map[string]string) publishMessage {
+	author := redactName(msg.FromAgent, redactions)
+	if _, redacted := redactions[msg.FromAgent]; redacted || avatar == "" {
+		r, _ := utf8.DecodeRuneInString(author)
+		avatar = string(unicode.ToUpper(r))
+	}
+
+	rendered := publishMessage{
+		ID:     msg.ID,
+		Author: author,
+		Avatar: avatar,
+		Color:  avatarColor(author),
+		Time:   time.Unix(msg.TS, 0).UTC().Format("2006-01-02 15:04"),
+		Body:   redactMentions(msg.Body, redactions),
+		Depth:  depth,
+		Pinned: pinned,
+		Edited: msg.Edited || msg.EditCount > 0 || msg.EditedAt != nil,
+	}
+
+	reactions := make([]string, 0, len(msg.Reactions))
+	for reaction := range msg.Reactions {
+		reactions = append(reactions, reaction)
+	}
+	sort.Strings(reactions)
+	for _, reaction := range reactions {
+		entries := msg.Reactions[reaction]
+		if len(entries) == 0 {
+			continue
+		}
+		who := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			who = append(who, redactName(entry.AgentID, redactions))
+		}
+		rendered.Reactions = append(rendered.Reactions, publishReaction{Reaction: reaction, Count: len(entries), Who: strings.Join(who, ", ")})
+	}
+	return rendered
+}
+
+func redactName(agentID string, redactions map[string]string) string {
+	if placeholder, ok := redactions[agentID]; ok {
+		return placeholder
+	}
+	return agentID
+}
+
+var publishMentionRe = regexp.MustCompile(`@([a-z][a-z0-9]*(?:[-.][a-z0-9]+)*)`)
+
+// redactMentions replaces @mentions of redacted participants.
+func redactMentions(body string, redactions map[string]string) string {
+	if len(redactions) == 0 {
+		return body
+	}
+	return publishMentionRe.ReplaceAllStringFunc(body, func(mention string) string {
+		if placeholder, ok := redactions[mention[1:]]; ok {
+			return "@" + placeholder
+		}
+		return mention
+	})
+}
+
+// avatarColor picks a stable hue for a name. The value is generated here, not
+// taken from message data, so it is safe to mark as CSS.
+func avatarColor(name string) template.CSS {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return template.CSS(fmt.Sprintf("hsl(%d, 55%%, 45%%)", hash.Sum32()%360))
+}
+
+var publishTemplate = template.Must(template.New("publish").Funcs(template.FuncMap{
+	"indent": func(depth int) string { return fmt.Sprintf("%.1frem", float64(depth)*1.5) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font: 15px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; max-width: 50rem; margin: 2rem auto; padding: 0 1rem; }
+h1 { font-size: 1.4rem; } h2 { font-size: 1.2rem; border-bottom: 1px solid #d0d7de; padding-bottom: .3rem; margin-top: 2.5rem; }
+h3 { font-size: .8rem; text-transform: uppercase; letter-spacing: .05em; color: #656d76; }
+.meta, .time { color: #656d76; font-size: .8rem; }
+.msg { display: flex; gap: .6rem; margin: .8rem 0; }
+.avatar { flex: none; width: 1.8rem; height: 1.8rem; border-radius: 50%; color: #fff; text-align: center; line-height: 1.8rem; font-weight: 600; }
+.content { min-width: 0; }
+.author { font-weight: 600; }
+.body { white-space: pre-wrap; overflow-wrap: anywhere; }
+.anchor, .pinned { background: #f6f8fa; border-left: 3px solid #0969da; padding: .4rem .6rem; }
+.pinned { border-left-color: #bf8700; }
+.reactions span { display: inline-block; border: 1px solid #d0d7de; border-radius: 1rem; padding: 0 .5rem; margin: .2rem .2rem 0 0; font-size: .8rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Published from fray on {{.Generated}}. Read-only export.</p>
+{{- if gt (len .Threads) 1}}
+<nav>
+<ul>
+{{- range .Threads}}
+<li><a href="#{{.ID}}">{{.Path}}</a> ({{len .Messages}} messages)</li>
+{{- end}}
+</ul>
+</nav>
+{{- end}}
+{{- range .Threads}}
+<section id="{{.ID}}">
+<h2>{{.Path}}</h2>
+{{- with .Anchor}}
+<h3>Anchor</h3>
+<div class="anchor">{{template "message" .}}</div>
+{{- end}}
+{{- if .Pinned}}
+<h3>Pinned</h3>
+{{- range .Pinned}}
+<div class="pinned">{{template "message" .}}</div>
+{{- end}}
+{{- end}}
+<h3>Messages</h3>
+{{- range .Messages}}
+<div style="margin-left: {{indent .Depth}}">{{template "message" .}}</div>
+{{- else}}
+<p class="meta">No messages.</p>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+{{define "message"}}<article class="msg"{{if .ID}} id="{{.ID}}"{{end}}><div class="avatar" style="background: {{.Color}}">{{.Avatar}}</div><div class="content"><span class="author">@{{.Author}}</span> <span class="time">{{.Time}}{{if .Edited}} (edited){{end}}{{if .Pinned}} 📌{{end}}</span><div class="body">{{.Body}}</div>{{if .Reactions}}<div class="reactions">{{range .Reactions}}<span title="{{.Who}}">{{.Reaction}} {{.Count}}</span>{{end}}</div>{{end}}</div></article>{{end}}
+`))
+
+// renderPublishHTML writes page as a self-contained HTML document. Message
+// content is escaped by html/template.
+func renderPublishHTML(w io.Writer, page publishPage) error {
+	return publishTemplate.Execute(w, page)
+}
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestRenderPublishHTMLSnapshot(t *testing.T) {
+	avatar := "🦊"
+	at := int64(1767225600) // 2026-01-01 00:00 UTC
+	root := types.Message{ID: "msg-root", FromAgent: "pm", TS: at, Body: "Which schema should we ship?"}
+	reply := types.Message{ID: "msg-reply", FromAgent: "dev", TS: at + 60, Body: "The second one, @pm.", EditCount: 1,
+		Reactions: map[string][]types.ReactionEntry{"👍": {{AgentID: "pm"}, {AgentID: "qa"}}}}
+	nested := types.Message{ID: "msg-nested", FromAgent: "pm", TS: at + 120, Body: "Agreed."}
+
+	pin := toPublishMessage(reply, 0, true, avatar, nil)
+	pin.ID = ""
+	page := publishPage{
+		Title:     "2 threads",
+		Generated: "2026-01-02 09:30 UTC",
+		Threads: []publishThread{
+			{
+				ID:     "thread-design",
+				Path:   "design",
+				Anchor: &publishMessage{ID: "msg-anchor", Author: "pm", Avatar: "P", Color: avatarColor("pm"), Time: "2026-01-01 00:00", Body: "Schema decisions live here."},
+				Pinned: []publishMessage{pin},
+				Messages: []publishMessage{
+					toPublishMessage(root, 0, false, "", nil),
+					toPublishMessage(reply, 1, true, avatar, nil),
+					toPublishMessage(nested, 2, false, "", nil),
+				},
+			},
+			{ID: "thread-empty", Path: "design/empty"},
+		},
+	}
+
+	var out strings.Builder
+	if err := renderPublishHTML(&out, page); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "publish_threads.html")
+	if *updateGolden {
+		if err := os.WriteFile(golden, []byte(out.String()), 0o644); err != nil {
+			t.Fatalf("update golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if out.String() != string(want) {
+		t.Fatalf("published HTML differs from %s (rerun with -update to accept):\n%s", golden, out.String())
+	}
+}
+
+func TestPublishEscapesBodiesAndRedacts(t *testing.T) {
+	newFlowProject(t, "dev", "pm")
+	runFray(t, "thread", "design")
+	runFray(t, "post", "--as", "pm", "design", `<script>alert("x")</script><img src=x onerror=alert(1)> & "quotes"`)
+	runFray(t, "post", "--as", "dev", "design", "@pm see </div><a href=\"javascript:alert(1)\">this</a>")
+
+	outPath := filepath.Join(t.TempDir(), "design.html")
+	runFray(t, "publish", "design", "--out", outPath, "--redact", "@pm")
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	html := string(data)
+
+	for _, hostile := range []string{"<script>", "<img", "</div><a", `href="javascript:`} {
+		if strings.Contains(html, hostile) {
+			t.Fatalf("hostile markup %q was not escaped:\n%s", hostile, html)
+		}
+	}
+	for _, escaped := range []string{"&lt;script&gt;", "&lt;img src=x onerror=alert(1)&gt; &amp; &#34;quotes&#34;"} {
+		if !strings.Contains(html, escaped) {
+			t.Fatalf("expected escaped %q in output:\n%s", escaped, html)
+		}
+	}
+	if strings.Contains(html, "@pm") {
+		t.Fatalf("expected @pm to be redacted:\n%s", html)
+	}
+	if !strings.Contains(html, "@participant-1") || !strings.Contains(html, "@dev") {
+		t.Fatalf("expected redacted author and untouched @dev:\n%s", html)
+	}
+}
@@ -25,6 +25,7 @@ func NewQuestionsCmd() *cobra.Command {
 
 			unasked, _ := cmd.Flags().GetBool("unasked")
 			answered, _ := cmd.Flags().GetBool("answered")
+			declined, _ := cmd.Flags().GetBool("declined")
 			all, _ := cmd.Flags().GetBool("all")
 			room, _ := cmd.Flags().GetBool("room")
 			threadRef, _ := cmd.Flags().GetString("thread")
@@ -43,7 +44,10 @@ func NewQuestionsCmd() *cobra.Command {
 				if answered {
 					statuses = append(statuses, types.QuestionStatusAnswered)
 				}
-				if !unasked && !answered {
+				if declined {
+					statuses = append(statuses, types.QuestionStatusDeclined)
+				}
+				if !unasked && !answered && !declined {
 					statuses = append(statuses, types.QuestionStatusOpen)
 				}
 			}
@@ -114,6 +118,7 @@ func NewQuestionsCmd() *cobra.Command {
 
 	cmd.Flags().Bool("unasked", false, "show unasked questions")
 	cmd.Flags().Bool("answered", false, "show answered questions")
+	cmd.Flags().Bool("declined", false, "show declined questions")
 	cmd.Flags().Bool("all", false, "show all questions")
 	cmd.Flags().Bool("room", false, "show room-level questions only")
 	cmd.Flags().String("thread", "", "filter by thread")
@@ -30,7 +30,7 @@ func NewQuickstartCmd() *cobra.Command {
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
-			activeUsers, err := db.GetActiveUsers(ctx.DB)
+			activeUsers, err := db.GetActiveUsers(ctx.DB, ctx.Project.DBPath)
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
@@ -16,8 +16,10 @@ func NewReactCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "react <emoji> <message>",
 		Short: "React to a message with an emoji",
-		Long:  "Add a reaction to a message. Optionally chain a reply with --reply.",
-		Args:  cobra.ExactArgs(2),
+		Long: `Add a reaction to a message. Optionally chain a reply with --reply.
+
+Use --remove to take back your most recent reaction with that emoji.`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, err := GetContext(cmd)
 			if err != nil {
@@ -27,9 +29,11 @@ func NewReactCmd() *cobra.Command {
 
 			agentRef, _ := cmd.Flags().GetString("as")
 			replyText, _ := cmd.Flags().GetString("reply")
+			remove, _ := cmd.Flags().GetBool("remove")
 
-			if agentRef == "" {
-				return writeCommandError(cmd, fmt.Errorf("--as is required"))
+			agentRef, err = identityRef(ctx, agentRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
 			}
 
 			agentID, err := resolveAgentRef(ctx, agentRef)
@@ -41,10 +45,10 @@ func NewReactCmd() *cobra.Command {
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
-			if agent == nil {
+			if agent == nil && !isHumanActor(ctx, agentID) {
 				return writeCommandError(cmd, fmt.Errorf("agent not found: @%s. Use 'fray new' first", agentID))
 			}
-			if agent.LeftAt != nil {
+			if agent != nil && agent.LeftAt != nil {
 				return writeCommandError(cmd, fmt.Errorf("agent @%s has left. Use 'fray back @%s' to resume", agentID, agentID))
 			}
 
@@ -61,6 +65,10 @@ func NewReactCmd() *cobra.Command {
 				return writeCommandError(cmd, err)
 			}
 
+			if remove {
+				return removeReaction(cmd, ctx, msg, agentID, reaction)
+			}
+
 			_, reactedAt, err := db.AddReaction(ctx.DB, msg.ID, agentID, reaction)
 			if err != nil {
 				return writeCommandError(cmd, err)
@@ -70,11 +78,16 @@ func NewReactCmd() *cobra.Command {
 			if err := db.AppendReaction(ctx.Project.DBPath, msg.ID, agentID, reaction, reactedAt); err != nil {
 				return writeCommandError(cmd, err)
 			}
+			if err := recordReactionUndo(cmd, ctx, msg.ID, agentID, reaction); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
 			now := time.Now().Unix()
-			updates := db.AgentUpdates{LastSeen: types.OptionalInt64{Set: true, Value: &now}}
-			if err := db.UpdateAgent(ctx.DB, agentID, updates); err != nil {
-				return writeCommandError(cmd, err)
+			if agent != nil {
+				updates := db.AgentUpdates{LastSeen: types.OptionalInt64{Set: true, Value: &now}}
+				if err := db.UpdateAgent(ctx.DB, agentID, updates); err != nil {
+					return writeCommandError(cmd, err)
+				}
 			}
 
 			var replyMsg *types.Message
@@ -125,10 +138,46 @@ func NewReactCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().String("as", "", "agent ID to react as")
+	cmd.Flags().String("as", "", "agent ID to react as (default: FRAY_AGENT_ID, then your fray login)")
 	cmd.Flags().String("reply", "", "optional reply message to chain after reaction")
-
-	_ = cmd.MarkFlagRequired("as")
+	cmd.Flags().Bool("remove", false, "remove your most recent reaction with this emoji")
 
 	return cmd
 }
+
+// removeReaction takes back the agent's most recent matching reaction.
+func removeReaction(cmd *cobra.Command, ctx *CommandContext, msg *types.Message, agentID, reaction string) error {
+	reactedAt, ok, err := db.RemoveLatestReaction(ctx.DB, msg.ID, agentID, reaction)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+	if !ok {
+		return writeCommandError(cmd, fmt.Errorf("@%s has no %s reaction on #%s", agentID, reaction, msg.ID))
+	}
+	if err := db.AppendReactionRemove(ctx.Project.DBPath, msg.ID, agentID, reaction, reactedAt); err != nil {
+		return writeCommandError(cmd, err)
+	}
+	if err := recordUndo(cmd, ctx, "react", fmt.Sprintf("re-add %s to #%s", reaction, msg.ID),
+		[][]string{{"react", reaction, msg.ID, "--as", agentID}}); err != nil {
+		return writeCommandError(cmd, err)
+	}
+
+	if ctx.JSONMode {
+		payload := map[string]any{
+			"message_id": msg.ID,
+			"from":       agentID,
+			"reaction":   reaction,
+			"removed":    true,
+		}
+		return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed %s from #%s\n", reaction, msg.ID)
+	return nil
+}
+
+// recordReactionUndo records removing a just-added reaction as its inverse.
+func recordReactionUndo(cmd *cobra.Command, ctx *CommandContext, messageID, agentID, reaction string) error {
+	return recordUndo(cmd, ctx, "react", fmt.Sprintf("remove %s from #%s", reaction, messageID),
+		[][]string{{"react", "--remove", reaction, messageID, "--as", agentID}})
+}
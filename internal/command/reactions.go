@@ -47,7 +47,9 @@ func NewReactionsCmd() *cobra.Command {
 
 Examples:
   fray reactions --by alice   Messages alice has reacted to
-  fray reactions --to alice   Reactions on alice's messages`,
+  fray reactions --to alice   Reactions on alice's messages
+  fray reactions inbox --as alice        New reactions on alice's messages
+  fray reactions inbox --as alice --ack  ...and mark them seen`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, err := GetContext(cmd)
 			if err != nil {
@@ -130,9 +132,150 @@ Examples:
 	cmd.Flags().String("to", "", "show reactions on an agent's messages")
 	cmd.Flags().Int("last", 20, "limit results")
 
+	cmd.AddCommand(NewReactionsInboxCmd())
+
+	return cmd
+}
+
+// reactionInboxEntry groups the new reactions on one message.
+type reactionInboxEntry struct {
+	MessageGUID string                `json:"message_guid"`
+	Home        string                `json:"home"`
+	FromAgent   string                `json:"from_agent"`
+	Body        string                `json:"body"`
+	Reactions   []reactionInboxDetail `json:"reactions"`
+}
+
+type reactionInboxDetail struct {
+	Emoji     string `json:"emoji"`
+	ReactedBy string `json:"reacted_by"`
+	ReactedAt int64  `json:"reacted_at"`
+}
+
+// groupReactionInbox folds reactions, oldest first, into one entry per
+// message, ordered by each message's first new reaction.
+func groupReactionInbox(reactions []db.ReactionQueryResult) []reactionInboxEntry {
+	var entries []reactionInboxEntry
+	index := map[string]int{}
+	for _, r := range reactions {
+		i, ok := index[r.MessageGUID]
+		if !ok {
+			i = len(entries)
+			index[r.MessageGUID] = i
+			entries = append(entries, reactionInboxEntry{
+				MessageGUID: r.MessageGUID,
+				Home:        formatHome(r.Home),
+				FromAgent:   r.FromAgent,
+				Body:        r.Body,
+			})
+		}
+		entries[i].Reactions = append(entries[i].Reactions, reactionInboxDetail{Emoji: r.Emoji, ReactedBy: r.ReactedBy, ReactedAt: r.ReactedAt})
+	}
+	return entries
+}
+
+// NewReactionsInboxCmd creates the reactions inbox command.
+func NewReactionsInboxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inbox",
+		Short: "Reactions on your messages since you last acked",
+		Long: `List reactions others left on an agent's messages (and its sub-agents')
+since the agent's reaction watermark, grouped by message. --ack advances the
+watermark past everything listed, the way reading mentions does.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			asRef, _ := cmd.Flags().GetString("as")
+			ack, _ := cmd.Flags().GetBool("ack")
+			if asRef == "" {
+				return writeCommandError(cmd, validationError("--as is required"))
+			}
+			agent, err := resolveAgentByRef(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			var since int64
+			if agent.ReactionWatermark != nil {
+				since = *agent.ReactionWatermark
+			}
+			reactions, err := db.GetReactionsToAgentSince(ctx.DB, agent.AgentID, since)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			entries := groupReactionInbox(reactions)
+
+			acked := false
+			if ack && len(reactions) > 0 {
+				watermark := reactions[len(reactions)-1].ReactedAt
+				if err := db.UpdateAgentReactionWatermark(ctx.DB, agent.AgentID, watermark); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if err := db.AppendAgentUpdate(ctx.Project.DBPath, db.AgentUpdateJSONLRecord{AgentID: agent.AgentID, ReactionWatermark: &watermark}); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				acked = true
+			}
+
+			out := cmd.OutOrStdout()
+			if ctx.JSONMode {
+				if entries == nil {
+					entries = []reactionInboxEntry{}
+				}
+				return json.NewEncoder(out).Encode(map[string]any{
+					"agent_id": agent.AgentID,
+					"since":    since,
+					"messages": entries,
+					"acked":    acked,
+				})
+			}
+
+			if len(entries) == 0 {
+				fmt.Fprintf(out, "No new reactions on @%s's messages\n", agent.AgentID)
+				return nil
+			}
+			fmt.Fprintf(out, "New reactions on @%s's messages:\n\n", agent.AgentID)
+			for _, entry := range entries {
+				fmt.Fprintf(out, "  %s on %s: %s\n", entry.MessageGUID, entry.Home, truncateBody(entry.Body, 60))
+				for _, line := range formatInboxReactions(entry.Reactions) {
+					fmt.Fprintf(out, "    %s\n", line)
+				}
+			}
+			if acked {
+				fmt.Fprintf(out, "\nAcked %d reaction(s)\n", len(reactions))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent whose messages to check")
+	cmd.Flags().Bool("ack", false, "advance the reaction watermark past the listed reactions")
+
 	return cmd
 }
 
+// formatInboxReactions renders one line per emoji: "👍 @alice, @bob".
+func formatInboxReactions(details []reactionInboxDetail) []string {
+	var order []string
+	byEmoji := map[string][]string{}
+	for _, d := range details {
+		if _, ok := byEmoji[d.Emoji]; !ok {
+			order = append(order, d.Emoji)
+		}
+		byEmoji[d.Emoji] = append(byEmoji[d.Emoji], "@"+d.ReactedBy)
+	}
+	lines := make([]string, 0, len(order))
+	for _, emoji := range order {
+		lines = append(lines, emoji+" "+strings.Join(byEmoji[emoji], ", "))
+	}
+	return lines
+}
+
 func truncateBody(body string, maxLen int) string {
 	body = strings.TrimSpace(body)
 	if idx := strings.Index(body, "\n"); idx > 0 && idx < maxLen {
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+)
+
+type reactionInbox struct {
+	Messages []reactionInboxEntry `json:"messages"`
+	Acked    bool                 `json:"acked"`
+}
+
+func getReactionInbox(t *testing.T, args ...string) reactionInbox {
+	t.Helper()
+	output := runFray(t, append([]string{"reactions", "inbox", "--as", "dev", "--json"}, args...)...)
+	var inbox reactionInbox
+	if err := json.Unmarshal([]byte(output), &inbox); err != nil {
+		t.Fatalf("decode %q: %v", output, err)
+	}
+	return inbox
+}
+
+func TestReactionsInboxWatermark(t *testing.T) {
+	projectDir := newFlowProject(t, "dev", "qa", "pm")
+	first := postJSON(t, "post", "--as", "dev", "pushed the migration fix")
+	second := postJSON(t, "post", "--as", "dev", "release notes drafted")
+	other := postJSON(t, "post", "--as", "pm", "standup in five")
+
+	runFray(t, "react", "👍", first["id"].(string), "--as", "qa")
+	runFray(t, "react", "🎉", second["id"].(string), "--as", "pm")
+	runFray(t, "react", "👍", first["id"].(string), "--as", "pm")
+	runFray(t, "react", "👀", first["id"].(string), "--as", "dev")
+	runFray(t, "react", "👍", other["id"].(string), "--as", "dev")
+
+	inbox := getReactionInbox(t)
+	if len(inbox.Messages) != 2 || inbox.Messages[0].MessageGUID != first["id"] || inbox.Messages[1].MessageGUID != second["id"] {
+		t.Fatalf("expected reactions grouped under dev's two messages, got %+v", inbox.Messages)
+	}
+	if got := inbox.Messages[0].Reactions; len(got) != 2 || got[0].ReactedBy != "qa" || got[1].ReactedBy != "pm" {
+		t.Fatalf("expected qa and pm on the first message without dev's own reaction, got %+v", got)
+	}
+	if inbox.Acked {
+		t.Fatalf("listing without --ack must not move the watermark")
+	}
+
+	text := runFray(t, "reactions", "inbox", "--as", "dev", "--ack")
+	if !strings.Contains(text, "👍 @qa, @pm") || !strings.Contains(text, "Acked 3 reaction(s)") {
+		t.Fatalf("unexpected inbox output %q", text)
+	}
+	if inbox := getReactionInbox(t); len(inbox.Messages) != 0 {
+		t.Fatalf("expected an empty inbox after --ack, got %+v", inbox.Messages)
+	}
+
+	runFray(t, "react", "✅", second["id"].(string), "--as", "qa")
+	inbox = getReactionInbox(t)
+	if len(inbox.Messages) != 1 || len(inbox.Messages[0].Reactions) != 1 || inbox.Messages[0].Reactions[0].Emoji != "✅" {
+		t.Fatalf("expected only the new reaction, got %+v", inbox.Messages)
+	}
+
+	// The acked watermark is an agent_update in JSONL, so it survives a rebuild.
+	conn := openProjectDB(t, projectDir)
+	if err := db.RebuildDatabaseFromJSONL(conn, projectDir); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	_ = conn.Close()
+	if inbox := getReactionInbox(t); len(inbox.Messages) != 1 {
+		t.Fatalf("expected the watermark to survive a rebuild, got %+v", inbox.Messages)
+	}
+}
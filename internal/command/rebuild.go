@@ -3,8 +3,10 @@ package command
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
@@ -31,7 +33,12 @@ Use this command when:
 - You see schema errors (e.g., "no such column")
 - The database is corrupted
 - After manually editing JSONL files
-- After a git pull with JSONL changes`,
+- After a git pull with JSONL changes
+
+Progress goes to stderr every few thousand rows (--quiet to silence it).
+While a rebuild runs, .fray/local/rebuild.lock makes other fray commands
+wait for it; the duration and row counts of the last rebuild are kept in
+.fray/local/rebuild.json. A lock left by an interrupted rebuild is cleared.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Don't use GetContext - it tries to open the DB which may fail
 			// Just discover the project and delete/rebuild the DB directly
@@ -40,29 +47,37 @@ Use this command when:
 				return writeCommandError(cmd, err)
 			}
 
-			dbPath := project.DBPath
-
-			// Shelve read state before deleting DB (local state we want to preserve)
-			readState := shelveReadState(dbPath)
-
-			// Delete existing db files
-			os.Remove(dbPath)
-			os.Remove(dbPath + "-wal")
-			os.Remove(dbPath + "-shm")
+			// A lock left by an interrupted rebuild is what this fixes.
+			if err := db.WaitForRebuild(project.DBPath); err != nil {
+				if !errors.Is(err, db.ErrRebuildInterrupted) {
+					return writeCommandError(cmd, err)
+				}
+				if err := db.ClearRebuildLock(project.DBPath); err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
 
-			// Open fresh - this will trigger rebuild from JSONL
-			newDB, err := db.OpenDatabase(project)
+			var opts db.RebuildOptions
+			if quiet, _ := cmd.Flags().GetBool("quiet"); !quiet {
+				opts.Progress = cmd.ErrOrStderr()
+			}
+			newDB, err := rebuildProjectDatabase(project, opts)
 			if err != nil {
 				return writeCommandError(cmd, fmt.Errorf("rebuild: %w", err))
 			}
 			defer newDB.Close()
 
-			// Restore read state
-			restoreReadState(newDB, readState)
-
+			stats, _ := db.ReadRebuildStats(project.DBPath)
 			jsonMode, _ := cmd.Flags().GetBool("json")
 			if jsonMode {
-				json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]string{"status": "rebuilt"})
+				payload := map[string]any{"status": "rebuilt"}
+				if stats != nil {
+					payload["stats"] = stats
+				}
+				json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
+			} else if stats != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Database rebuilt from JSONL (%d rows in %s)\n",
+					stats.Rows, (time.Duration(stats.DurationMs) * time.Millisecond).String())
 			} else {
 				fmt.Fprintln(cmd.OutOrStdout(), "Database rebuilt from JSONL")
 			}
@@ -70,9 +85,34 @@ Use this command when:
 		},
 	}
 
+	cmd.Flags().Bool("quiet", false, "don't print rebuild progress to stderr")
 	return cmd
 }
 
+// rebuildProjectDatabase deletes the cache and reopens it, which rebuilds it
+// from JSONL, carrying read state across.
+func rebuildProjectDatabase(project core.Project, opts db.RebuildOptions) (*sql.DB, error) {
+	dbPath := project.DBPath
+
+	// Shelve read state before deleting DB (local state we want to preserve)
+	readState := shelveReadState(dbPath)
+
+	// Delete existing db files
+	os.Remove(dbPath)
+	os.Remove(dbPath + "-wal")
+	os.Remove(dbPath + "-shm")
+
+	// Open fresh - this will trigger rebuild from JSONL
+	newDB, err := db.OpenDatabaseWithOptions(project, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restore read state
+	restoreReadState(newDB, readState)
+	return newDB, nil
+}
+
 // shelveReadState extracts read state from the old database before deletion.
 func shelveReadState(dbPath string) []readToRecord {
 	oldDB, err := sql.Open("sqlite", dbPath)
@@ -46,7 +46,7 @@ func NewRenameCmd() *cobra.Command {
 			}
 
 			staleHours := 4
-			if value, err := db.GetConfig(ctx.DB, "stale_hours"); err == nil && value != "" {
+			if value, err := getConfigValue(ctx, "stale_hours"); err == nil && value != "" {
 				parsed := parseNumeric(value)
 				if parsed > 0 {
 					staleHours = parsed
@@ -1,31 +1,19 @@
 package command
 
 import (
-	"os"
 	"testing"
 	"time"
 
 	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
 	"github.com/adamavenir/fray/internal/types"
 )
 
 func TestRenameCommandRenamesAgent(t *testing.T) {
-	projectDir := t.TempDir()
-	project, err := core.InitProject(projectDir, false)
-	if err != nil {
-		t.Fatalf("init project: %v", err)
-	}
-
-	dbConn, err := db.OpenDatabase(project)
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-	if err := db.InitSchema(dbConn); err != nil {
-		_ = dbConn.Close()
-		t.Fatalf("init schema: %v", err)
-	}
-	_ = dbConn.Close()
+	p := testutil.NewProject(t)
+	project := p.Project
+	dbConn := p.OpenDB(t, db.OpenDatabase, db.InitSchema)
 
 	if _, err := db.UpdateProjectConfig(project.DBPath, db.ProjectConfig{
 		Version:     1,
@@ -36,50 +24,26 @@ func TestRenameCommandRenamesAgent(t *testing.T) {
 		t.Fatalf("update config: %v", err)
 	}
 
-	dbConn, err = db.OpenDatabase(project)
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-
-	now := time.Now().Unix()
 	agentID := "alice"
 	guid, err := core.GenerateGUID("usr")
 	if err != nil {
 		t.Fatalf("generate guid: %v", err)
 	}
-	if err := db.CreateAgent(dbConn, types.Agent{
-		GUID:         guid,
-		AgentID:      agentID,
-		RegisteredAt: now,
-		LastSeen:     now,
-	}); err != nil {
-		_ = dbConn.Close()
+	agent := testutil.NewAgent(agentID)
+	agent.GUID = guid
+	if err := db.CreateAgent(dbConn, agent); err != nil {
 		t.Fatalf("create agent: %v", err)
 	}
 
-	posted, err := db.CreateMessage(dbConn, types.Message{
-		TS:        now,
-		FromAgent: agentID,
-		Body:      "hi @alice",
-		Mentions:  []string{agentID},
-		Type:      types.MessageTypeAgent,
-	})
+	msg := testutil.NewMessage(agentID, "hi @alice", types.MessageTypeAgent)
+	msg.Mentions = []string{agentID}
+	posted, err := db.CreateMessage(dbConn, msg)
 	if err != nil {
-		_ = dbConn.Close()
 		t.Fatalf("create message: %v", err)
 	}
 	_ = dbConn.Close()
 
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("getwd: %v", err)
-	}
-	if err := os.Chdir(projectDir); err != nil {
-		t.Fatalf("chdir: %v", err)
-	}
-	t.Cleanup(func() {
-		_ = os.Chdir(cwd)
-	})
+	testutil.Chdir(t, p.Root)
 
 	cmd := NewRootCmd("test")
 	if _, err := executeCommand(cmd, "rename", "alice", "bob"); err != nil {
@@ -0,0 +1,374 @@
+package command
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// retentionPrefix starts retention policy config keys
+	// (retention.room, retention.thread_default, retention.<thread-name>).
+	retentionPrefix     = "retention."
+	retentionRoomKey    = retentionPrefix + "room"
+	retentionDefaultKey = retentionPrefix + "thread_default"
+
+	// retentionReportFile records the last run, under .fray/local/.
+	retentionReportFile = "retention.json"
+	// retentionInterval is how often the daemon applies retention.
+	retentionInterval = 24 * time.Hour
+)
+
+// NewRetentionCmd creates the retention command.
+func NewRetentionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retention",
+		Short: "Message retention policies applied daily by the daemon",
+		Long: `Retention policies let the daemon prune history per home instead of running
+fray prune by hand. Policies are config values:
+
+  retention.room             policy for the room
+  retention.thread_default   policy for threads without their own
+  retention.<thread-name>    policy for one thread
+
+A policy is keep:all, keep:<n> (newest n messages), or age:<duration>
+(e.g. age:90d). Homes without a policy are left alone.
+
+The daemon applies policies once a day with every prune protection on:
+anchors, pins, question and surface references, thread membership,
+important messages, and the reply chains of kept messages survive. The
+run is skipped with a warning when the prune git guardrails fail.
+
+Examples:
+  fray config retention.room keep:500
+  fray config retention.thread_default age:90d
+  fray config retention.design keep:all
+  fray retention status`,
+	}
+
+	cmd.AddCommand(newRetentionStatusCmd())
+	return cmd
+}
+
+func newRetentionStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show policies, the last run, and what the next run would remove",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			last, err := readRetentionReport(ctx.Project.DBPath)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			next, _, err := planRetention(ctx.DB, ctx.Project.DBPath, time.Now())
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"last_run": last,
+					"next_run": next,
+				})
+			}
+			printRetentionStatus(cmd.OutOrStdout(), last, next)
+			return nil
+		},
+	}
+}
+
+// retentionHome is one home's policy and what a run removes from it.
+type retentionHome struct {
+	Home     string `json:"home"`
+	Name     string `json:"name"`
+	Policy   string `json:"policy"`
+	Source   string `json:"source"`
+	Messages int    `json:"messages"`
+	Removed  int    `json:"removed"`
+}
+
+// retentionReport describes a retention run, or a planned one.
+type retentionReport struct {
+	RanAt   int64           `json:"ran_at"`
+	Skipped string          `json:"skipped,omitempty"`
+	Notices []string        `json:"notices,omitempty"`
+	Removed int             `json:"removed"`
+	Homes   []retentionHome `json:"homes"`
+}
+
+// retentionPolicyFor resolves the policy for a home: the room key, the
+// thread's own key, then the thread default. ok is false when none is set.
+func retentionPolicyFor(database *sql.DB, projectPath, home, threadName string) (core.RetentionPolicy, string, bool, error) {
+	keys := []string{retentionRoomKey}
+	if home != "room" {
+		keys = []string{normalizeConfigKey(retentionPrefix + threadName), retentionDefaultKey}
+	}
+	for _, key := range keys {
+		value, _, err := db.GetResolvedConfig(database, projectPath, key)
+		if err != nil {
+			return core.RetentionPolicy{}, "", false, err
+		}
+		if value == "" {
+			continue
+		}
+		policy, err := core.ParseRetentionPolicy(value)
+		if err != nil {
+			return core.RetentionPolicy{}, "", false, validationError("%s: %v", key, err)
+		}
+		return policy, key, true, nil
+	}
+	return core.RetentionPolicy{}, "", false, nil
+}
+
+// planRetention works out, per home with a policy, which messages a run at
+// now would remove. Prune's protected messages, and the reply chains of
+// every kept message, are never removed.
+func planRetention(database *sql.DB, projectPath string, now time.Time) (retentionReport, map[string]struct{}, error) {
+	report := retentionReport{RanAt: now.Unix()}
+	remove := map[string]struct{}{}
+
+	messages, err := db.ReadMessages(projectPath)
+	if err != nil {
+		return report, nil, err
+	}
+	threads, _, _, err := db.ReadThreads(projectPath)
+	if err != nil {
+		return report, nil, err
+	}
+	names := map[string]string{"room": "room"}
+	for _, thread := range threads {
+		names[thread.GUID] = thread.Name
+	}
+
+	byHome := map[string][]db.MessageJSONLRecord{}
+	var homes []string
+	for _, msg := range messages {
+		if _, ok := byHome[msg.Home]; !ok {
+			homes = append(homes, msg.Home)
+		}
+		byHome[msg.Home] = append(byHome[msg.Home], msg)
+	}
+	sort.SliceStable(homes, func(i, j int) bool {
+		if (homes[i] == "room") != (homes[j] == "room") {
+			return homes[i] == "room"
+		}
+		return names[homes[i]] < names[homes[j]]
+	})
+
+	for _, home := range homes {
+		name := names[home]
+		if name == "" {
+			name = home
+		}
+		policy, source, ok, err := retentionPolicyFor(database, projectPath, home, name)
+		if err != nil {
+			return report, nil, err
+		}
+		if !ok {
+			continue
+		}
+		homeMessages := byHome[home]
+		sort.SliceStable(homeMessages, func(i, j int) bool { return homeMessages[i].TS < homeMessages[j].TS })
+		timestamps := make([]int64, len(homeMessages))
+		for i, msg := range homeMessages {
+			timestamps[i] = msg.TS
+		}
+		for i, expired := range policy.Expired(timestamps, now) {
+			if expired {
+				remove[homeMessages[i].ID] = struct{}{}
+			}
+		}
+		report.Homes = append(report.Homes, retentionHome{
+			Home: home, Name: name, Policy: policy.String(), Source: source, Messages: len(homeMessages),
+		})
+	}
+	if len(remove) == 0 {
+		return report, remove, nil
+	}
+
+	required, err := collectRequiredMessageIDs(projectPath, defaultRequiredMessageOptions())
+	if err != nil {
+		return report, nil, err
+	}
+	for id := range required {
+		delete(remove, id)
+	}
+	byID := make(map[string]db.MessageJSONLRecord, len(messages))
+	for _, msg := range messages {
+		byID[msg.ID] = msg
+	}
+	for _, msg := range messages {
+		if _, removed := remove[msg.ID]; removed {
+			continue
+		}
+		for parent := msg.ReplyTo; parent != nil && *parent != ""; {
+			delete(remove, *parent)
+			next, ok := byID[*parent]
+			if !ok {
+				break
+			}
+			parent = next.ReplyTo
+		}
+	}
+
+	for i := range report.Homes {
+		for _, msg := range byHome[report.Homes[i].Home] {
+			if _, ok := remove[msg.ID]; ok {
+				report.Homes[i].Removed++
+			}
+		}
+		report.Removed += report.Homes[i].Removed
+	}
+	return report, remove, nil
+}
+
+// applyRetention archives messages.jsonl to history.jsonl, as prune does, and
+// rewrites it without the removed messages. It holds the JSONL lock so posts
+// from other processes wait for the rewrite instead of being dropped by it.
+func applyRetention(projectPath string, remove map[string]struct{}) error {
+	release, err := db.LockJSONL(projectPath)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	frayDir := resolveFrayDir(projectPath)
+	messagesPath := filepath.Join(frayDir, "messages.jsonl")
+	data, err := os.ReadFile(messagesPath)
+	if err != nil {
+		return err
+	}
+	if err := appendFile(filepath.Join(frayDir, "history.jsonl"), data); err != nil {
+		return err
+	}
+
+	messages, err := db.ReadMessages(projectPath)
+	if err != nil {
+		return err
+	}
+	kept := make([]db.MessageJSONLRecord, 0, len(messages))
+	keptIDs := make(map[string]struct{}, len(messages))
+	for _, msg := range messages {
+		if _, ok := remove[msg.ID]; ok {
+			continue
+		}
+		kept = append(kept, msg)
+		keptIDs[msg.ID] = struct{}{}
+	}
+	return writeMessagesWithEvents(messagesPath, kept, keptIDs)
+}
+
+// runScheduledRetention is the daemon's retention hook. It applies the
+// project's policies when a day has passed since the last run and writes the
+// run report. A skipped run returns the reason, and is retried next time.
+func runScheduledRetention(project core.Project, database *sql.DB, now time.Time) (string, error) {
+	last, err := readRetentionReport(project.DBPath)
+	if err != nil {
+		return "", err
+	}
+	if last != nil && last.Skipped == "" && now.Sub(time.Unix(last.RanAt, 0)) < retentionInterval {
+		return "", nil
+	}
+
+	report, remove, err := planRetention(database, project.DBPath, now)
+	if err != nil {
+		return "", err
+	}
+	if len(report.Homes) == 0 {
+		return "", nil
+	}
+	if len(remove) > 0 {
+		guard, err := checkPruneGuardrails(project.Root, false)
+		report.Notices = guard.Notices
+		if err != nil {
+			report.Skipped = err.Error()
+			report.Removed = 0
+			for i := range report.Homes {
+				report.Homes[i].Removed = 0
+			}
+			return report.Skipped, writeRetentionReport(project.DBPath, report)
+		}
+		if err := applyRetention(project.DBPath, remove); err != nil {
+			return "", err
+		}
+		if err := db.RebuildDatabaseFromJSONL(database, project.DBPath); err != nil {
+			return "", err
+		}
+		if err := db.AppendUndoBarrier(project.DBPath, "retention", now.Unix()); err != nil {
+			return "", err
+		}
+	}
+	return "", writeRetentionReport(project.DBPath, report)
+}
+
+func retentionReportPath(projectPath string) string {
+	return filepath.Join(resolveFrayDir(projectPath), "local", retentionReportFile)
+}
+
+// readRetentionReport returns the last run's report, or nil before the first
+// run.
+func readRetentionReport(projectPath string) (*retentionReport, error) {
+	data, err := os.ReadFile(retentionReportPath(projectPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report retentionReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("read %s: %w", retentionReportFile, err)
+	}
+	return &report, nil
+}
+
+func writeRetentionReport(projectPath string, report retentionReport) error {
+	path := retentionReportPath(projectPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func printRetentionStatus(out io.Writer, last *retentionReport, next retentionReport) {
+	if len(next.Homes) == 0 {
+		fmt.Fprintln(out, "No retention policies apply (set retention.room, retention.thread_default, or retention.<thread>)")
+	} else {
+		fmt.Fprintln(out, "Policies:")
+		for _, home := range next.Homes {
+			fmt.Fprintf(out, "  %-20s %-10s %4d messages, next run removes %d  (%s)\n", home.Name, home.Policy, home.Messages, home.Removed, home.Source)
+		}
+	}
+
+	fmt.Fprintln(out)
+	switch {
+	case last == nil:
+		fmt.Fprintln(out, "Last run: never")
+	case last.Skipped != "":
+		fmt.Fprintf(out, "Last run: %s, skipped: %s\n", time.Unix(last.RanAt, 0).Format("2006-01-02 15:04"), last.Skipped)
+	default:
+		fmt.Fprintf(out, "Last run: %s, removed %d messages\n", time.Unix(last.RanAt, 0).Format("2006-01-02 15:04"), last.Removed)
+	}
+	if len(next.Homes) > 0 {
+		fmt.Fprintf(out, "Next run would remove %d messages\n", next.Removed)
+	}
+}
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+)
+
+// retentionProject posts room and thread history and commits it, so the
+// prune guardrails pass.
+func retentionProject(t *testing.T) (string, core.Project) {
+	t.Helper()
+	// The room also holds the two thread-creation events.
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "notes")
+	runFray(t, "thread", "design")
+	runFray(t, "post", "--as", "alice", "--important", "room 1 important")
+	for _, body := range []string{"room 2", "room 3", "room 4"} {
+		runFray(t, "post", "--as", "alice", body)
+	}
+	for _, body := range []string{"notes 1", "notes 2", "notes 3"} {
+		runFray(t, "post", "--as", "alice", "notes", body)
+	}
+	for _, body := range []string{"design 1", "design 2"} {
+		runFray(t, "post", "--as", "alice", "design", body)
+	}
+	runFray(t, "config", "retention.room", "keep:2")
+	runFray(t, "config", "retention.thread_default", "keep:1")
+	runFray(t, "config", "retention.design", "keep:all")
+	gitInit(t, projectDir)
+
+	project, err := core.DiscoverProject(projectDir)
+	if err != nil {
+		t.Fatalf("discover project: %v", err)
+	}
+	return projectDir, project
+}
+
+func retainedBodies(t *testing.T, projectDir string) string {
+	t.Helper()
+	messages, err := db.ReadMessages(filepath.Join(projectDir, ".fray"))
+	if err != nil {
+		t.Fatalf("read messages: %v", err)
+	}
+	var bodies []string
+	for _, msg := range messages {
+		if msg.MsgType != "event" {
+			bodies = append(bodies, msg.Body)
+		}
+	}
+	return strings.Join(bodies, "|")
+}
+
+func TestRetentionAppliesPolicyPerHome(t *testing.T) {
+	projectDir, project := retentionProject(t)
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+
+	skipped, err := runScheduledRetention(project, dbConn, time.Now())
+	if err != nil || skipped != "" {
+		t.Fatalf("retention: skipped %q, err %v", skipped, err)
+	}
+
+	got := retainedBodies(t, projectDir)
+	for _, want := range []string{"room 1 important", "room 3", "room 4", "notes 3", "design 1", "design 2"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q retained, got %s", want, got)
+		}
+	}
+	for _, gone := range []string{"room 2", "notes 1", "notes 2"} {
+		if strings.Contains(got, gone) {
+			t.Fatalf("expected %q removed, got %s", gone, got)
+		}
+	}
+	if history, err := os.ReadFile(filepath.Join(projectDir, ".fray", "history.jsonl")); err != nil || !strings.Contains(string(history), "notes 1") {
+		t.Fatalf("expected removed messages archived to history.jsonl: %v", err)
+	}
+
+	report, err := readRetentionReport(project.DBPath)
+	if err != nil || report == nil {
+		t.Fatalf("expected a run report: %v", err)
+	}
+	if report.Removed != 5 || report.Skipped != "" || len(report.Homes) != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// A second run within the day is not due, even with new history.
+	runFray(t, "post", "--as", "alice", "notes", "notes 4")
+	if _, err := runScheduledRetention(project, dbConn, time.Now()); err != nil {
+		t.Fatalf("retention: %v", err)
+	}
+	if got := retainedBodies(t, projectDir); !strings.Contains(got, "notes 3") {
+		t.Fatalf("expected no run before a day passed, got %s", got)
+	}
+}
+
+func TestRetentionSkipsWhenFrayDirIsDirty(t *testing.T) {
+	projectDir, project := retentionProject(t)
+	runFray(t, "post", "--as", "alice", "uncommitted")
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+
+	skipped, err := runScheduledRetention(project, dbConn, time.Now())
+	if err != nil {
+		t.Fatalf("retention: %v", err)
+	}
+	if !strings.Contains(skipped, "uncommitted changes") {
+		t.Fatalf("expected dirty .fray/ to skip the run, got %q", skipped)
+	}
+	if got := retainedBodies(t, projectDir); !strings.Contains(got, "notes 1") {
+		t.Fatalf("expected nothing removed on a skipped run, got %s", got)
+	}
+	report, err := readRetentionReport(project.DBPath)
+	if err != nil || report == nil || report.Skipped == "" || report.Removed != 0 {
+		t.Fatalf("expected a skipped report, got %+v (%v)", report, err)
+	}
+}
+
+func TestRetentionStatus(t *testing.T) {
+	projectDir, _ := retentionProject(t)
+
+	output := runFray(t, "retention", "status")
+	for _, want := range []string{
+		"room                 keep:2        6 messages, next run removes 3",
+		"design               keep:all      2 messages, next run removes 0  (retention.design)",
+		"notes                keep:1        3 messages, next run removes 2  (retention.thread_default)",
+		"Last run: never",
+		"Next run would remove 5 messages",
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in status:\n%s", want, output)
+		}
+	}
+	if got := retainedBodies(t, projectDir); !strings.Contains(got, "room 2") {
+		t.Fatalf("status must not remove anything, got %s", got)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "config", "retention.room", "keep:lots"); err == nil {
+		t.Fatal("expected an invalid policy to be rejected")
+	}
+}
@@ -115,6 +115,10 @@ func deleteThread(cmd *cobra.Command, ctx *CommandContext, threadGUID string) er
 	if thread == nil {
 		return writeCommandError(cmd, fmt.Errorf("thread not found: %s", threadGUID))
 	}
+	asRef, _ := cmd.Flags().GetString("as")
+	if err := checkThreadCuration(ctx, thread, asRef, "delete"); err != nil {
+		return writeCommandError(cmd, err)
+	}
 
 	// Archive the thread (soft delete)
 	status := string(types.ThreadStatusArchived)
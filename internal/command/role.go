@@ -403,9 +403,11 @@ func ensureRoleHierarchy(ctx *CommandContext, roleName string) error {
 		return err
 	}
 	if keysThread == nil {
-		if _, err := createSystemThread(ctx, "keys", &roleThread.GUID); err != nil {
+		if _, err := createSystemThread(ctx, "keys", &roleThread.GUID, types.ThreadTypeKeys); err != nil {
 			return err
 		}
+	} else if err := upgradeLegacyThreadType(ctx, keysThread, types.ThreadTypeKeys); err != nil {
+		return err
 	}
 
 	return nil
@@ -429,12 +431,12 @@ func createKnowledgeThread(ctx *CommandContext, name string, parent *string) (*t
 	return &thread, nil
 }
 
-// createSystemThread creates a thread with type=system.
-func createSystemThread(ctx *CommandContext, name string, parent *string) (*types.Thread, error) {
+// createSystemThread creates a system-managed thread of the given type.
+func createSystemThread(ctx *CommandContext, name string, parent *string, threadType types.ThreadType) (*types.Thread, error) {
 	thread, err := db.CreateThread(ctx.DB, types.Thread{
 		Name:         name,
 		ParentThread: parent,
-		Type:         types.ThreadTypeSystem,
+		Type:         threadType,
 	})
 	if err != nil {
 		return nil, err
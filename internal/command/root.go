@@ -25,12 +25,16 @@ func NewRootCmd(version string) *cobra.Command {
 		},
 	}
 
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &CommandError{Kind: ErrorKindValidation, Err: err}
+	})
+
 	cmd.Version = version
 	cmd.SetVersionTemplate(AppName + " version {{.Version}}\n")
 	cmd.SetOut(os.Stdout)
 	cmd.SetErr(os.Stderr)
 
-	cmd.PersistentFlags().String("project", "", "operate in linked project")
+	cmd.PersistentFlags().String("project", "", "operate in linked project (alias) or project root (path)")
 	cmd.PersistentFlags().String("in", "", "operate in channel context")
 	cmd.PersistentFlags().Bool("json", false, "output in JSON format")
 	cmd.PersistentFlags().Bool("force", false, "force action (skip confirmations or suggestions)")
@@ -40,23 +44,38 @@ func NewRootCmd(version string) *cobra.Command {
 		NewDestroyCmd(),
 		NewNewCmd(),
 		NewAgentCmd(),
+		NewSubCmd(),
 		NewDaemonCmd(),
 		NewBatchUpdateCmd(),
 		NewBackCmd(),
 		NewByeCmd(),
+		NewDoneCmd(),
+		NewStandupCmd(),
+		NewExportCmd(),
+		NewBackupCmd(),
+		NewAnnounceCmd(),
+		NewAckCmd(),
+		NewPinboardCmd(),
+		NewAuditCmd(),
+		NewFlyContextCmd(),
+		NewUsageCmd(),
 		NewHereCmd(),
 		NewWhoCmd(),
 		NewWhoamiCmd(),
+		NewLoginCmd(),
 		NewNickCmd(),
 		NewNicksCmd(),
 		NewPostCmd(),
+		NewDMCmd(),
 		NewEditCmd(),
 		NewRmCmd(),
 		NewClaimCmd(),
 		NewClaimsCmd(),
+		NewBlameCmd(),
 		NewClearCmd(),
 		NewStatusCmd(),
 		NewGetCmd(),
+		NewOpenCmd(),
 		NewQuickstartCmd(),
 		NewReplyCmd(),
 		NewThreadCmd(),
@@ -65,6 +84,7 @@ func NewRootCmd(version string) *cobra.Command {
 		NewUnpinCmd(),
 		NewMvCmd(),
 		NewFollowCmd(),
+		NewFollowsCmd(),
 		NewUnfollowCmd(),
 		NewMuteCmd(),
 		NewUnmuteCmd(),
@@ -72,7 +92,9 @@ func NewRootCmd(version string) *cobra.Command {
 		NewRemoveCmd(),
 		NewArchiveCmd(),
 		NewRestoreCmd(),
+		NewUndoCmd(),
 		NewAnchorCmd(),
+		NewPublishCmd(),
 		NewWonderCmd(),
 		NewAskCmd(),
 		NewQuestionsCmd(),
@@ -86,31 +108,48 @@ func NewRootCmd(version string) *cobra.Command {
 		NewReactionsCmd(),
 		NewChatCmd(),
 		NewWatchCmd(),
+		NewNotifyCmd(),
 		NewPruneCmd(),
+		NewRetentionCmd(),
+		NewPrivacyCmd(),
 		NewConfigCmd(),
+		NewPrefCmd(),
 		NewRosterCmd(),
 		NewInfoCmd(),
 		NewRenameCmd(),
 		NewMergeCmd(),
 		NewVersionsCmd(),
+		NewVersionCmd(version),
 		NewFilterCmd(),
 		NewLsCmd(),
 		NewMigrateCmd(),
 		NewRoleCmd(),
 		NewRolesCmd(),
 		NewRebuildCmd(),
+		NewDoctorCmd(),
 		NewHeartbeatCmd(),
+		NewWorkingCmd(),
 		NewClockCmd(),
 		NewCursorCmd(),
 		NewInstallNotifierCmd(),
+		NewMetaCmd(),
+		NewDevtoolCmd(),
+	)
+
+	// Hook commands write what the calling tool expects, never fray JSON.
+	for _, hook := range []*cobra.Command{
 		hooks.NewHookInstallCmd(),
 		hooks.NewHookSessionCmd(),
 		hooks.NewHookPromptCmd(),
 		hooks.NewHookPrecommitCmd(),
 		hooks.NewHookPrecompactCmd(),
 		hooks.NewHookStatuslineCmd(),
-	)
+	} {
+		hook.Annotations = map[string]string{noJSONAnnotation: "true"}
+		cmd.AddCommand(hook)
+	}
 
+	installAuditHooks(cmd)
 	return cmd
 }
 
@@ -1,21 +1,20 @@
 package command
 
 import (
-	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
 )
 
 func executeCommand(cmd *cobra.Command, args ...string) (string, error) {
-	buf := new(bytes.Buffer)
-	cmd.SetOut(buf)
-	cmd.SetErr(buf)
-	cmd.SetArgs(args)
-
-	err := cmd.Execute()
-	return buf.String(), err
+	return testutil.Execute(cmd, args...)
 }
 
 func TestRootCommandVersion(t *testing.T) {
@@ -43,3 +42,98 @@ func TestRootCommandHelp(t *testing.T) {
 		t.Fatalf("expected help output, got %q", output)
 	}
 }
+
+func TestVersionCommandJSONOutsideProject(t *testing.T) {
+	testutil.IsolateHome(t)
+	t.Setenv("FRAY_PROJECT_ROOT", "")
+	t.Chdir(t.TempDir())
+
+	output, err := executeCommand(NewRootCmd("test"), "version", "--json")
+	if err != nil {
+		t.Fatalf("version: %v\n%s", err, output)
+	}
+	var info map[string]any
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		t.Fatalf("decode: %v\n%s", err, output)
+	}
+	if info["version"] != "test" {
+		t.Fatalf("expected version test, got %v", info["version"])
+	}
+	for _, key := range []string{"go_version", "platform", "cgo", "sqlite_driver"} {
+		if _, ok := info[key]; !ok {
+			t.Fatalf("expected %s in %v", key, info)
+		}
+	}
+	if _, ok := info["project"]; ok {
+		t.Fatalf("expected no project outside a project, got %v", info["project"])
+	}
+
+	plain, err := executeCommand(NewRootCmd("test"), "version")
+	if err != nil {
+		t.Fatalf("version: %v", err)
+	}
+	if !strings.Contains(plain, "fray version test") || !strings.Contains(plain, "project: none") {
+		t.Fatalf("unexpected plain output %q", plain)
+	}
+}
+
+func TestVersionCommandInsideProject(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+
+	output := runFray(t, "version", "--json")
+	var info VersionInfo
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		t.Fatalf("decode: %v\n%s", err, output)
+	}
+	if info.Project == nil {
+		t.Fatalf("expected project info, got %s", output)
+	}
+	if resolved, _ := filepath.EvalSymlinks(projectDir); info.Project.Root != projectDir && info.Project.Root != resolved {
+		t.Fatalf("expected root %s, got %s", projectDir, info.Project.Root)
+	}
+	if info.SQLiteVersion == "" {
+		t.Fatalf("expected sqlite version inside a project")
+	}
+	if info.Project.SchemaVersion == nil || *info.Project.SchemaVersion != db.SchemaVersion || info.Project.CurrentSchema != db.SchemaVersion {
+		t.Fatalf("expected schema version %d, got %+v", db.SchemaVersion, info.Project)
+	}
+
+	// A version query must not recreate or rebuild the store.
+	if err := os.Remove(info.Project.DBPath); err != nil {
+		t.Fatalf("remove db: %v", err)
+	}
+	runFray(t, "version")
+	if _, err := os.Stat(info.Project.DBPath); !os.IsNotExist(err) {
+		t.Fatalf("expected version to leave the missing db alone, got %v", err)
+	}
+}
+
+func TestVersionCommandHonorsProjectFlagAndEnv(t *testing.T) {
+	target := newFlowProject(t, "alice")
+	testutil.IsolateHome(t)
+	t.Setenv("FRAY_PROJECT_ROOT", "")
+	t.Chdir(t.TempDir())
+
+	rootOf := func(args ...string) string {
+		t.Helper()
+		output := runFray(t, append([]string{"version", "--json"}, args...)...)
+		var info VersionInfo
+		if err := json.Unmarshal([]byte(output), &info); err != nil {
+			t.Fatalf("decode: %v\n%s", err, output)
+		}
+		if info.Project == nil || info.Project.SchemaVersion == nil {
+			t.Fatalf("expected project and schema info, got %s", output)
+		}
+		resolved, _ := filepath.EvalSymlinks(info.Project.Root)
+		return resolved
+	}
+	want, _ := filepath.EvalSymlinks(target)
+
+	if got := rootOf("--project", target); got != want {
+		t.Fatalf("expected --project root %s, got %s", want, got)
+	}
+	t.Setenv("FRAY_PROJECT_ROOT", target)
+	if got := rootOf(); got != want {
+		t.Fatalf("expected FRAY_PROJECT_ROOT root %s, got %s", want, got)
+	}
+}
@@ -65,7 +65,7 @@ func NewRosterCmd() *cobra.Command {
 						_ = dbConn.Close()
 						continue
 					}
-					staleHours := resolveStaleHours(dbConn)
+					staleHours := resolveStaleHours(dbConn, project.DBPath)
 					projectConfig, _ := db.ReadProjectConfig(project.DBPath)
 					channelAgents, err := buildRosterAgents(dbConn, projectConfig, staleHours, &channelID, &channel.Name)
 					_ = dbConn.Close()
@@ -81,7 +81,7 @@ func NewRosterCmd() *cobra.Command {
 				}
 				defer ctx.DB.Close()
 
-				staleHours := resolveStaleHours(ctx.DB)
+				staleHours := resolveStaleHours(ctx.DB, ctx.Project.DBPath)
 				channelAgents, err := buildRosterAgents(ctx.DB, ctx.ProjectConfig, staleHours, nil, nil)
 				if err != nil {
 					return writeCommandError(cmd, err)
@@ -208,9 +208,9 @@ func writeRosterEmpty(cmd *cobra.Command, jsonMode bool) error {
 	return nil
 }
 
-func resolveStaleHours(dbConn *sql.DB) int {
+func resolveStaleHours(dbConn *sql.DB, projectPath string) int {
 	staleHours := 4
-	if value, err := db.GetConfig(dbConn, "stale_hours"); err == nil && value != "" {
+	if value, _, err := db.GetResolvedConfig(dbConn, projectPath, "stale_hours"); err == nil && value != "" {
 		staleHours = parseInt(value, staleHours)
 	}
 	return staleHours
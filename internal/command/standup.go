@@ -0,0 +1,97 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// NewStandupCmd creates the standup digest command.
+func NewStandupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "standup",
+		Short: "Digest of standup reports by day and agent",
+		Long: `Collect standup reports from the room and group them by day and agent.
+
+Standups are messages posted with 'fray post --standup', plus any message
+whose body starts with the standup marker ("standup:" by default; change it
+with 'fray config standup_marker <prefix>').
+
+Examples:
+  fray standup                      Last 7 days
+  fray standup --since 24h --agent @dev
+  fray standup --since 2026-01-01 --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			since, _ := cmd.Flags().GetString("since")
+			agentRef, _ := cmd.Flags().GetString("agent")
+
+			options := types.MessageQueryOptions{}
+			if err := applyTimeBounds(ctx.DB, since, "", &options); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if agentRef != "" {
+				agentID, err := resolveAgentRef(ctx, agentRef)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				options.FromAgents = []string{agentID}
+			}
+
+			marker, err := getConfigValue(ctx, "standup_marker")
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			messages, err := db.GetMessages(ctx.DB, &options)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			entries := core.CollectStandups(messages, marker, time.Local)
+
+			if ctx.JSONMode {
+				if entries == nil {
+					entries = []core.StandupEntry{}
+				}
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"since":   *options.SinceTS,
+					"entries": entries,
+				})
+			}
+
+			out := cmd.OutOrStdout()
+			sinceDate := time.Unix(*options.SinceTS, 0).Format("2006-01-02")
+			if len(entries) == 0 {
+				fmt.Fprintf(out, "No standups since %s\n", sinceDate)
+				return nil
+			}
+			fmt.Fprintf(out, "Standups since %s (%d)\n", sinceDate, len(entries))
+			for _, day := range core.GroupStandups(entries) {
+				fmt.Fprintf(out, "\n%s\n", day.Date)
+				for _, group := range day.Agents {
+					for _, entry := range group.Entries {
+						body := strings.ReplaceAll(entry.Body, "\n", "\n    ")
+						fmt.Fprintf(out, "  @%s [%s]: %s\n", group.AgentID, entry.MessageID, body)
+					}
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("since", "7d", "start of the window (24h, 7d, RFC3339, or a message GUID)")
+	cmd.Flags().String("agent", "", "only standups from this agent")
+	return cmd
+}
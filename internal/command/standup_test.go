@@ -0,0 +1,55 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestStandupDigest(t *testing.T) {
+	projectDir := newFlowProject(t, "dev", "pm")
+	flagged := postJSON(t, "post", "--as", "dev", "--standup", "done: auth\nnext: billing")
+	heuristic := postJSON(t, "post", "--as", "pm", "standup: triaged backlog")
+	runFray(t, "post", "--as", "pm", "unrelated chatter")
+
+	var payload struct {
+		Entries []core.StandupEntry `json:"entries"`
+	}
+	output := runFray(t, "--json", "standup")
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode %q: %v", output, err)
+	}
+	sources := map[string]string{}
+	for _, entry := range payload.Entries {
+		sources[entry.MessageID] = entry.Source
+	}
+	if len(payload.Entries) != 2 || sources[flagged["id"].(string)] != core.StandupSourceFlag || sources[heuristic["id"].(string)] != core.StandupSourceHeuristic {
+		t.Fatalf("expected one flagged and one heuristic standup, got %+v", payload.Entries)
+	}
+
+	// --standup marks the message without replacing its type, and the
+	// marker survives a rebuild from JSONL.
+	runFray(t, "rebuild")
+	dbConn := openProjectDB(t, projectDir)
+	stored, err := db.GetMessage(dbConn, flagged["id"].(string))
+	_ = dbConn.Close()
+	if err != nil || stored == nil || stored.Type != types.MessageTypeAgent || !stored.Standup {
+		t.Fatalf("expected an agent message marked standup, got %+v (%v)", stored, err)
+	}
+
+	text := runFray(t, "standup", "--agent", "@dev")
+	if !strings.Contains(text, "@dev ["+flagged["id"].(string)+"]: done: auth") || strings.Contains(text, "triaged") {
+		t.Fatalf("expected only dev's standup, got %q", text)
+	}
+
+	runFray(t, "config", "standup_marker", "[daily]")
+	runFray(t, "post", "--as", "pm", "[daily] reviewed PRs")
+	text = runFray(t, "standup")
+	if !strings.Contains(text, "reviewed PRs") || strings.Contains(text, "triaged backlog") {
+		t.Fatalf("expected configured marker to replace the default, got %q", text)
+	}
+}
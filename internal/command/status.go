@@ -32,6 +32,11 @@ func NewStatusCmd() *cobra.Command {
 			if len(args) > 1 {
 				message = args[1]
 			}
+			// Check the body before any state changes, so a blocked message
+			// does not leave the agent half-updated.
+			if err := checkMessageContent(cmd, ctx, message); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
 			agent, err := db.GetAgent(ctx.DB, agentID)
 			if err != nil {
@@ -43,10 +48,17 @@ func NewStatusCmd() *cobra.Command {
 
 			clear, _ := cmd.Flags().GetBool("clear")
 			if clear {
+				releasedClaims, err := db.GetClaimsByAgent(ctx.DB, agentID)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
 				clearedItems, clearedCount, err := clearClaims(ctx.DB, agentID)
 				if err != nil {
 					return writeCommandError(cmd, err)
 				}
+				if err := appendClaimClears(ctx, releasedClaims); err != nil {
+					return writeCommandError(cmd, err)
+				}
 
 				now := time.Now().Unix()
 				updates := db.AgentUpdates{
@@ -83,6 +95,16 @@ func NewStatusCmd() *cobra.Command {
 					return writeCommandError(cmd, err)
 				}
 
+				var inverse [][]string
+				if agent.Status != nil && *agent.Status != "" {
+					inverse = append(inverse, []string{"status", agentID, *agent.Status})
+				}
+				inverse = append(inverse, claimInverse(releasedClaims)...)
+				description := fmt.Sprintf("restore status and %d claim(s) for @%s", len(releasedClaims), agentID)
+				if err := recordUndo(cmd, ctx, "status", description, inverse); err != nil {
+					return writeCommandError(cmd, err)
+				}
+
 				if ctx.JSONMode {
 					payload := map[string]any{
 						"agent_id":        agentID,
@@ -139,6 +161,13 @@ func NewStatusCmd() *cobra.Command {
 				}
 				created = append(created, *createdClaim)
 			}
+			status := agent.Status
+			if message != "" {
+				status = &message
+			}
+			if err := appendClaimHistory(ctx, created, status); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
 			now := time.Now().Unix()
 			updates := db.AgentUpdates{LastSeen: types.OptionalInt64{Set: true, Value: &now}}
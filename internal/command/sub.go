@@ -0,0 +1,259 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/daemon"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// NewSubCmd creates the sub-agent command group.
+func NewSubCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sub",
+		Short: "Manage sub-agents (alice.1, alice.2) under a parent",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		NewSubSpawnCmd(),
+		NewSubListCmd(),
+		NewSubRetireCmd(),
+	)
+
+	return cmd
+}
+
+// NewSubSpawnCmd registers the next free sub-agent under a parent.
+func NewSubSpawnCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "spawn <parent>",
+		Short: "Register a managed sub-agent under a parent",
+		Long: `Register the next free sub-agent under a parent (alice.1, then alice.2, ...)
+as a managed agent. It inherits the parent's invoke settings; any invoke flag
+you pass overrides the inherited value.
+
+Set sub_mention_fanout=true to have the daemon wake active sub-agents when
+their parent is @mentioned.
+
+Examples:
+  fray sub spawn alice --purpose "frontend split"
+  fray sub spawn alice --driver codex --min-checkin 5m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			parent, err := resolveAgentByRef(ctx, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			apply, _, err := invokeChanges(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			invoke := inheritedInvoke(parent)
+			apply(&invoke)
+			if daemon.GetDriver(invoke.Driver) == nil {
+				return writeCommandError(cmd, validationError("unknown driver: %s (valid: claude, codex, opencode)", invoke.Driver))
+			}
+
+			existing, err := db.GetAgentsByPrefix(ctx.DB, parent.AgentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			taken := make([]string, 0, len(existing))
+			for _, agent := range existing {
+				taken = append(taken, agent.AgentID)
+			}
+			agentID, err := core.NextSubAgentID(parent.AgentID, taken)
+			if err != nil {
+				return writeCommandError(cmd, validationError("%v", err))
+			}
+
+			agentGUID, err := core.GenerateGUID("usr")
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			purpose, _ := cmd.Flags().GetString("purpose")
+			now := time.Now().Unix()
+			sub := types.Agent{
+				GUID:         agentGUID,
+				AgentID:      agentID,
+				Purpose:      optionalString(purpose),
+				RegisteredAt: now,
+				LastSeen:     now,
+				Managed:      true,
+				Invoke:       &invoke,
+				Presence:     types.PresenceOffline,
+				ParentAgent:  &parent.AgentID,
+			}
+			if err := db.CreateAgent(ctx.DB, sub); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.AppendAgent(ctx.Project.DBPath, sub); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(sub)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Registered @%s under @%s (driver: %s)\n", agentID, parent.AgentID, invoke.Driver)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("purpose", "", "what the sub-agent is for")
+	addInvokeFlags(cmd)
+
+	return cmd
+}
+
+// inheritedInvoke copies the parent's invoke settings, falling back to the
+// agent create defaults when the parent is not managed.
+func inheritedInvoke(parent *types.Agent) types.InvokeConfig {
+	if parent.Invoke != nil {
+		return *parent.Invoke
+	}
+	return types.InvokeConfig{
+		Driver:         "claude",
+		PromptDelivery: types.PromptDeliveryStdin,
+		SpawnTimeoutMs: 30000,
+		IdleAfterMs:    5000,
+		MinCheckinMs:   600000,
+	}
+}
+
+// NewSubListCmd lists the sub-agents of a parent.
+func NewSubListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list <parent>",
+		Short: "List sub-agents of a parent",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			parent, err := resolveAgentByRef(ctx, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			subs, err := db.GetSubAgents(ctx.DB, parent.AgentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				if subs == nil {
+					subs = []types.Agent{}
+				}
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(subs)
+			}
+
+			out := cmd.OutOrStdout()
+			if len(subs) == 0 {
+				fmt.Fprintf(out, "No sub-agents under @%s\n", parent.AgentID)
+				return nil
+			}
+			for _, sub := range subs {
+				state := string(sub.Presence)
+				if sub.LeftAt != nil {
+					state = "retired"
+				}
+				purpose := ""
+				if sub.Purpose != nil && *sub.Purpose != "" {
+					purpose = " - " + *sub.Purpose
+				}
+				fmt.Fprintf(out, "@%s: %s%s\n", sub.AgentID, state, purpose)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// NewSubRetireCmd retires a sub-agent.
+func NewSubRetireCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retire <sub-agent>",
+		Short: "Retire a sub-agent",
+		Long: `Retire a sub-agent: mark it left, clear its claims, and stop the daemon
+from waking it. 'fray back <sub-agent>' brings it back.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			sub, err := resolveAgentByRef(ctx, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if sub.ParentAgent == nil {
+				return writeCommandError(cmd, validationError("@%s is not a sub-agent", sub.AgentID))
+			}
+
+			claims, err := db.GetClaimsByAgent(ctx.DB, sub.AgentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			cleared, err := db.DeleteClaimsByAgent(ctx.DB, sub.AgentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := appendClaimClears(ctx, claims); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			now := time.Now().Unix()
+			if err := db.UpdateAgent(ctx.DB, sub.AgentID, db.AgentUpdates{
+				LeftAt:   types.OptionalInt64{Set: true, Value: &now},
+				LastSeen: types.OptionalInt64{Set: true, Value: &now},
+			}); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.UpdateAgentPresence(ctx.DB, sub.AgentID, types.PresenceOffline); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.UpdateAgentSessionID(ctx.DB, sub.AgentID, ""); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			updated, err := db.GetAgent(ctx.DB, sub.AgentID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if err := db.AppendAgent(ctx.Project.DBPath, *updated); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"agent_id":       sub.AgentID,
+					"status":         "retired",
+					"claims_cleared": cleared,
+				})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Retired @%s (%d claim(s) cleared)\n", sub.AgentID, cleared)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func spawnSub(t *testing.T, args ...string) types.Agent {
+	t.Helper()
+	output := runFray(t, append([]string{"--json", "sub", "spawn"}, args...)...)
+	var agent types.Agent
+	if err := json.Unmarshal([]byte(output), &agent); err != nil {
+		t.Fatalf("decode spawned sub-agent: %v (%q)", err, output)
+	}
+	return agent
+}
+
+func TestSubSpawnAllocatesAndInherits(t *testing.T) {
+	newManagedAgents(t)
+	runFray(t, "agent", "create", "alice", "--driver", "codex", "--min-checkin", "300000")
+
+	first := spawnSub(t, "alice", "--purpose", "frontend split")
+	if first.AgentID != "alice.1" || first.ParentAgent == nil || *first.ParentAgent != "alice" {
+		t.Fatalf("expected alice.1 under alice, got %+v", first)
+	}
+	if !first.Managed || first.Invoke == nil || first.Invoke.Driver != "codex" || first.Invoke.MinCheckinMs != 300000 {
+		t.Fatalf("expected inherited invoke config, got %+v", first.Invoke)
+	}
+	if first.Purpose == nil || *first.Purpose != "frontend split" {
+		t.Fatalf("expected purpose, got %v", first.Purpose)
+	}
+
+	second := spawnSub(t, "alice", "--driver", "claude")
+	if second.AgentID != "alice.2" || second.Invoke.Driver != "claude" || second.Invoke.MinCheckinMs != 300000 {
+		t.Fatalf("expected alice.2 with driver override, got %+v %+v", second, second.Invoke)
+	}
+
+	// Retired sub-agents keep their index.
+	runFray(t, "sub", "retire", "alice.1")
+	if third := spawnSub(t, "alice"); third.AgentID != "alice.3" {
+		t.Fatalf("expected alice.3, got %s", third.AgentID)
+	}
+
+	var subs []types.Agent
+	if err := json.Unmarshal([]byte(runFray(t, "--json", "sub", "list", "alice")), &subs); err != nil {
+		t.Fatalf("decode sub list: %v", err)
+	}
+	if len(subs) != 3 {
+		t.Fatalf("expected 3 sub-agents, got %+v", subs)
+	}
+	text := runFray(t, "sub", "list", "alice")
+	if !strings.Contains(text, "@alice.1: retired - frontend split") {
+		t.Fatalf("expected retired alice.1 in list, got %q", text)
+	}
+}
+
+func TestSubRetireClearsClaims(t *testing.T) {
+	projectDir := newManagedAgents(t, "alice")
+	spawnSub(t, "alice")
+	runFray(t, "claim", "@alice.1", "--file", "internal/ui/app.go")
+
+	output := runFray(t, "--json", "sub", "retire", "alice.1")
+	if !strings.Contains(output, `"claims_cleared":1`) {
+		t.Fatalf("expected one cleared claim, got %q", output)
+	}
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	agent, err := db.GetAgent(dbConn, "alice.1")
+	if err != nil || agent == nil {
+		t.Fatalf("get agent: %v", err)
+	}
+	if agent.LeftAt == nil || agent.Presence != types.PresenceOffline {
+		t.Fatalf("expected alice.1 left and offline, got %+v", agent)
+	}
+	claims, err := db.GetClaimsByAgent(dbConn, "alice.1")
+	if err != nil || len(claims) != 0 {
+		t.Fatalf("expected claims cleared, got %v (%v)", claims, err)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "sub", "retire", "alice"); err == nil {
+		t.Fatal("expected retiring a non-sub-agent to fail")
+	}
+}
@@ -0,0 +1,66 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// NewThreadBacklinksCmd creates the thread backlinks command.
+func NewThreadBacklinksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backlinks <thread>",
+		Short: "List messages that reference a thread",
+		Long: `List the messages, anywhere in the project, that mention a thread as
+#name, #parent/child, or thrd-GUID. References inside code spans don't count.
+
+Examples:
+  fray thread backlinks design-thread
+  fray thread backlinks thrd-abc123 --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			thread, err := resolveThreadRef(ctx.DB, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			messages, err := db.GetThreadBacklinks(ctx.DB, thread.GUID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			out := cmd.OutOrStdout()
+			if ctx.JSONMode {
+				return json.NewEncoder(out).Encode(map[string]any{"thread": thread.GUID, "messages": messages})
+			}
+
+			path, err := buildThreadPath(ctx.DB, thread)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if len(messages) == 0 {
+				fmt.Fprintf(out, "No messages reference %s\n", path)
+				return nil
+			}
+			agentBases, err := db.GetAgentBases(ctx.DB)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			projectName := GetProjectName(ctx.Project.Root)
+			fmt.Fprintf(out, "%d message(s) reference %s:\n", len(messages), path)
+			for _, msg := range messages {
+				fmt.Fprintln(out, FormatMessage(msg, projectName, agentBases))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
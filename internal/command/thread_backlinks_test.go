@@ -0,0 +1,86 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+)
+
+func TestThreadBacklinksTrackEditsAndRebuild(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "design")
+
+	dbConn := openProjectDB(t, projectDir)
+	design, err := resolveThreadRef(dbConn, "design")
+	if err != nil {
+		t.Fatalf("resolve design: %v", err)
+	}
+	_ = dbConn.Close()
+
+	first := postJSON(t, "post", "--as", "alice", "see #design for the plan")
+	second := postJSON(t, "post", "--as", "bob", "also "+design.GUID+", but not `#design` here")
+	postJSON(t, "post", "--as", "bob", "#nowhere is not a thread")
+
+	referencedCount := func() int {
+		t.Helper()
+		conn := openProjectDB(t, projectDir)
+		defer conn.Close()
+		thread, err := db.GetThread(conn, design.GUID)
+		if err != nil || thread == nil {
+			t.Fatalf("get thread: %v", err)
+		}
+		return thread.ReferencedCount
+	}
+	if got := referencedCount(); got != 2 {
+		t.Fatalf("expected 2 references, got %d", got)
+	}
+
+	output := runFray(t, "thread", "backlinks", "design", "--json")
+	var payload struct {
+		Messages []struct {
+			ID   string `json:"id"`
+			Body string `json:"body"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode %q: %v", output, err)
+	}
+	if len(payload.Messages) != 2 || (payload.Messages[0].ID != first["id"] && payload.Messages[1].ID != first["id"]) {
+		t.Fatalf("expected both citing messages, got %+v", payload.Messages)
+	}
+
+	runFray(t, "edit", first["id"].(string), "never mind the plan", "--as", "alice")
+	if got := referencedCount(); got != 1 {
+		t.Fatalf("expected the edit to drop a reference, got %d", got)
+	}
+
+	conn := openProjectDB(t, projectDir)
+	if err := db.RebuildDatabaseFromJSONL(conn, projectDir); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	_ = conn.Close()
+	if got := referencedCount(); got != 1 {
+		t.Fatalf("expected 1 reference after rebuild, got %d", got)
+	}
+
+	output = runFray(t, "thread", "backlinks", "design")
+	if !strings.Contains(output, "1 message(s) reference design") {
+		t.Fatalf("unexpected backlinks output %q", output)
+	}
+
+	// Deleted (archived) messages no longer count, before or after a rebuild.
+	runFray(t, "rm", second["id"].(string), "--as", "bob")
+	if got := referencedCount(); got != 0 {
+		t.Fatalf("expected the delete to drop the reference, got %d", got)
+	}
+	conn = openProjectDB(t, projectDir)
+	if err := db.RebuildDatabaseFromJSONL(conn, projectDir); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	_ = conn.Close()
+	if got := referencedCount(); got != 0 {
+		t.Fatalf("expected no references after rebuild, got %d", got)
+	}
+}
@@ -88,7 +88,7 @@ Examples:
 			if lastStr != "" {
 				limit, err := strconv.Atoi(lastStr)
 				if err != nil {
-					return writeCommandError(cmd, fmt.Errorf("invalid --last value: %s", lastStr))
+					return writeCommandError(cmd, validationError("invalid --last value: %s", lastStr))
 				}
 				if limit > 0 && len(messages) > limit {
 					messages = messages[len(messages)-limit:]
@@ -183,6 +183,9 @@ Examples:
 		NewThreadRenameCmd(),
 		NewThreadPinCmd(),
 		NewThreadUnpinCmd(),
+		NewThreadTypeCmd(),
+		NewThreadDigestCmd(),
+		NewThreadBacklinksCmd(),
 	)
 
 	return cmd
@@ -212,7 +215,7 @@ func createThreadFromPath(cmd *cobra.Command, ctx *CommandContext, args []string
 		parentPath := strings.Join(parts[:len(parts)-1], "/")
 		parent, err := resolveThreadRef(ctx.DB, parentPath)
 		if err != nil {
-			return writeCommandError(cmd, fmt.Errorf("parent thread not found: %s", parentPath))
+			return writeCommandError(cmd, notFoundError("parent thread not found: %s", parentPath))
 		}
 		parentGUID = &parent.GUID
 
@@ -222,21 +225,19 @@ func createThreadFromPath(cmd *cobra.Command, ctx *CommandContext, args []string
 			return writeCommandError(cmd, err)
 		}
 		if parentDepth >= MaxThreadNestingDepth {
-			return writeCommandError(cmd, fmt.Errorf("cannot create thread: maximum nesting depth (%d) exceeded", MaxThreadNestingDepth))
+			return writeCommandError(cmd, validationError("cannot create thread: maximum nesting depth (%d) exceeded", MaxThreadNestingDepth))
 		}
 	} else {
 		name = pathArg
 	}
 
 	name = strings.TrimSpace(name)
-	if err := validateThreadName(name); err != nil {
-		return writeCommandError(cmd, err)
-	}
 
 	// Sanitize name to kebab-case and confirm if changed
 	sanitized, changed := SanitizeThreadName(name)
 	if sanitized == "" {
-		return writeCommandError(cmd, fmt.Errorf("invalid thread name: '%s'", name))
+		// Nothing valid is left, so the raw name fails validation.
+		return writeCommandError(cmd, validateThreadName(name))
 	}
 	if changed {
 		confirmed, err := ConfirmSanitizedName(name, sanitized, os.Stdout, os.Stdin)
@@ -247,14 +248,17 @@ func createThreadFromPath(cmd *cobra.Command, ctx *CommandContext, args []string
 	} else {
 		name = sanitized
 	}
+	if err := validateThreadName(name); err != nil {
+		return writeCommandError(cmd, err)
+	}
 
 	// Check if thread already exists
-	existing, err := db.GetThreadByName(ctx.DB, name, parentGUID)
+	existing, err := db.GetThreadByNameFold(ctx.DB, name, parentGUID)
 	if err != nil {
 		return writeCommandError(cmd, err)
 	}
 	if existing != nil {
-		return writeCommandError(cmd, fmt.Errorf("thread already exists: %s", pathArg))
+		return writeCommandError(cmd, threadExistsError(ctx.DB, existing))
 	}
 
 	// Check for meta/ path collision (e.g., creating "opus/notes" when "meta/opus/notes" exists)
@@ -262,11 +266,17 @@ func createThreadFromPath(cmd *cobra.Command, ctx *CommandContext, args []string
 		return writeCommandError(cmd, err)
 	}
 
+	creator, err := curationActor(ctx, asRef)
+	if err != nil {
+		return writeCommandError(cmd, err)
+	}
+
 	// Create the thread
 	thread, err := db.CreateThread(ctx.DB, types.Thread{
 		Name:         name,
 		ParentThread: parentGUID,
 		Status:       types.ThreadStatusOpen,
+		CreatedBy:    optionalString(creator),
 	})
 	if err != nil {
 		return writeCommandError(cmd, err)
@@ -430,6 +440,10 @@ func NewThreadsCmd() *cobra.Command {
 			if activity {
 				options.SortByActivity = true
 			}
+			// dm threads are only listed for their participants
+			if !all && agentID != "" {
+				options.Viewer = &agentID
+			}
 
 			threads, err := db.GetThreads(ctx.DB, &options)
 			if err != nil {
@@ -628,12 +642,12 @@ func resolveSubscriptionAgent(ctx *CommandContext, ref string) (string, error) {
 	if ref != "" {
 		return ResolveAgentRef(ref, ctx.ProjectConfig), nil
 	}
-	username, err := db.GetConfig(ctx.DB, "username")
+	username, err := humanIdentity(ctx)
 	if err != nil {
 		return "", err
 	}
 	if username == "" {
-		return "", fmt.Errorf("--as is required unless --all is set")
+		return "", validationError("--as is required unless --all is set")
 	}
 	return username, nil
 }
@@ -652,14 +666,12 @@ func NewThreadNewCmd() *cobra.Command {
 			defer ctx.DB.Close()
 
 			name := strings.TrimSpace(args[0])
-			if err := validateThreadName(name); err != nil {
-				return writeCommandError(cmd, err)
-			}
 
 			// Sanitize name to kebab-case and confirm if changed
 			sanitized, changed := SanitizeThreadName(name)
 			if sanitized == "" {
-				return writeCommandError(cmd, fmt.Errorf("invalid thread name: '%s'", name))
+				// Nothing valid is left, so the raw name fails validation.
+				return writeCommandError(cmd, validateThreadName(name))
 			}
 			if changed {
 				confirmed, err := ConfirmSanitizedName(name, sanitized, os.Stdout, os.Stdin)
@@ -670,6 +682,9 @@ func NewThreadNewCmd() *cobra.Command {
 			} else {
 				name = sanitized
 			}
+			if err := validateThreadName(name); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
 			parentRef, _ := cmd.Flags().GetString("parent")
 			var parent *types.Thread
@@ -684,7 +699,7 @@ func NewThreadNewCmd() *cobra.Command {
 					return writeCommandError(cmd, err)
 				}
 				if parentDepth >= MaxThreadNestingDepth {
-					return writeCommandError(cmd, fmt.Errorf("cannot create thread: maximum nesting depth (%d) exceeded", MaxThreadNestingDepth))
+					return writeCommandError(cmd, validationError("cannot create thread: maximum nesting depth (%d) exceeded", MaxThreadNestingDepth))
 				}
 			}
 
@@ -693,12 +708,12 @@ func NewThreadNewCmd() *cobra.Command {
 				parentGUID = &parent.GUID
 			}
 
-			existing, err := db.GetThreadByName(ctx.DB, name, parentGUID)
+			existing, err := db.GetThreadByNameFold(ctx.DB, name, parentGUID)
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
 			if existing != nil {
-				return writeCommandError(cmd, fmt.Errorf("thread already exists: %s", name))
+				return writeCommandError(cmd, threadExistsError(ctx.DB, existing))
 			}
 
 			// Check for meta/ path collision
@@ -706,10 +721,17 @@ func NewThreadNewCmd() *cobra.Command {
 				return writeCommandError(cmd, err)
 			}
 
+			asRef, _ := cmd.Flags().GetString("as")
+			creator, err := curationActor(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
 			thread, err := db.CreateThread(ctx.DB, types.Thread{
 				Name:         name,
 				ParentThread: parentGUID,
 				Status:       types.ThreadStatusOpen,
+				CreatedBy:    optionalString(creator),
 			})
 			if err != nil {
 				return writeCommandError(cmd, err)
@@ -747,6 +769,7 @@ func NewThreadNewCmd() *cobra.Command {
 
 	cmd.Flags().String("parent", "", "parent thread guid or path")
 	cmd.Flags().String("subscribe", "", "comma-separated agent list to subscribe")
+	cmd.Flags().String("as", "", "agent creating the thread (recorded as creator)")
 
 	return cmd
 }
@@ -862,7 +885,7 @@ func NewThreadRemoveCmd() *cobra.Command {
 					return writeCommandError(cmd, err)
 				}
 				if msg.Home == thread.GUID {
-					return writeCommandError(cmd, fmt.Errorf("message %s has home %s and cannot be removed", msg.ID, thread.GUID))
+					return writeCommandError(cmd, conflictError("message %s has home %s and cannot be removed", msg.ID, thread.GUID))
 				}
 				if err := db.RemoveMessageFromThread(ctx.DB, thread.GUID, msg.ID); err != nil {
 					return writeCommandError(cmd, err)
@@ -1013,6 +1036,7 @@ func NewThreadArchiveCmd() *cobra.Command {
 			return updateThreadStatus(cmd, args[0], types.ThreadStatusArchived)
 		},
 	}
+	cmd.Flags().String("as", "", "agent performing the archive (for attribution)")
 	return cmd
 }
 
@@ -1026,6 +1050,7 @@ func NewThreadRestoreCmd() *cobra.Command {
 			return updateThreadStatus(cmd, args[0], types.ThreadStatusOpen)
 		},
 	}
+	cmd.Flags().String("as", "", "agent performing the restore (for attribution)")
 	return cmd
 }
 
@@ -1040,6 +1065,14 @@ func updateThreadStatus(cmd *cobra.Command, ref string, status types.ThreadStatu
 	if err != nil {
 		return writeCommandError(cmd, err)
 	}
+	action := "archive"
+	if status == types.ThreadStatusOpen {
+		action = "restore"
+	}
+	asRef, _ := cmd.Flags().GetString("as")
+	if err := checkThreadCuration(ctx, thread, asRef, action); err != nil {
+		return writeCommandError(cmd, err)
+	}
 
 	statusValue := string(status)
 	updated, err := db.UpdateThread(ctx.DB, thread.GUID, db.ThreadUpdates{
@@ -1055,6 +1088,9 @@ func updateThreadStatus(cmd *cobra.Command, ref string, status types.ThreadStatu
 	}); err != nil {
 		return writeCommandError(cmd, err)
 	}
+	if err := recordThreadStatusUndo(cmd, ctx, thread, updated.Status); err != nil {
+		return writeCommandError(cmd, err)
+	}
 
 	if ctx.JSONMode {
 		return json.NewEncoder(cmd.OutOrStdout()).Encode(updated)
@@ -1081,16 +1117,18 @@ func NewThreadRenameCmd() *cobra.Command {
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
-
-			name := strings.TrimSpace(args[1])
-			if err := validateThreadName(name); err != nil {
+			asRef, _ := cmd.Flags().GetString("as")
+			if err := checkThreadCuration(ctx, thread, asRef, "rename"); err != nil {
 				return writeCommandError(cmd, err)
 			}
 
+			name := strings.TrimSpace(args[1])
+
 			// Sanitize name to kebab-case and confirm if changed
 			sanitized, changed := SanitizeThreadName(name)
 			if sanitized == "" {
-				return writeCommandError(cmd, fmt.Errorf("invalid thread name: '%s'", name))
+				// Nothing valid is left, so the raw name fails validation.
+				return writeCommandError(cmd, validateThreadName(name))
 			}
 			if changed {
 				confirmed, err := ConfirmSanitizedName(name, sanitized, os.Stdout, os.Stdin)
@@ -1101,17 +1139,20 @@ func NewThreadRenameCmd() *cobra.Command {
 			} else {
 				name = sanitized
 			}
+			if err := validateThreadName(name); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
 			var parentGUID *string
 			if thread.ParentThread != nil {
 				parentGUID = thread.ParentThread
 			}
-			existing, err := db.GetThreadByName(ctx.DB, name, parentGUID)
+			existing, err := db.GetThreadByNameFold(ctx.DB, name, parentGUID)
 			if err != nil {
 				return writeCommandError(cmd, err)
 			}
 			if existing != nil && existing.GUID != thread.GUID {
-				return writeCommandError(cmd, fmt.Errorf("thread already exists: %s", name))
+				return writeCommandError(cmd, threadExistsError(ctx.DB, existing))
 			}
 
 			updated, err := db.UpdateThread(ctx.DB, thread.GUID, db.ThreadUpdates{
@@ -1137,5 +1178,7 @@ func NewThreadRenameCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().String("as", "", "agent performing the rename")
+
 	return cmd
 }
@@ -7,7 +7,7 @@ import (
 	"github.com/adamavenir/fray/internal/types"
 )
 
-func ensureThread(ctx *CommandContext, name string, parent *types.Thread, subscribers []string) (*types.Thread, error) {
+func ensureThread(ctx *CommandContext, name string, parent *types.Thread, subscribers []string, creator string) (*types.Thread, error) {
 	var parentGUID *string
 	if parent != nil {
 		parentGUID = &parent.GUID
@@ -22,6 +22,7 @@ func ensureThread(ctx *CommandContext, name string, parent *types.Thread, subscr
 			Name:         name,
 			ParentThread: parentGUID,
 			Status:       types.ThreadStatusOpen,
+			CreatedBy:    optionalString(creator),
 		})
 		if err != nil {
 			return nil, err
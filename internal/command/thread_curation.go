@@ -45,6 +45,9 @@ Examples:
 			hide, _ := cmd.Flags().GetBool("hide")
 			unhide, _ := cmd.Flags().GetBool("unhide")
 			asRef, _ := cmd.Flags().GetString("as")
+			if err := checkThreadCuration(ctx, thread, asRef, "anchor"); err != nil {
+				return writeCommandError(cmd, err)
+			}
 
 			// Handle hide/unhide toggles
 			if hide || unhide {
@@ -220,6 +223,11 @@ Examples:
 				}
 			}
 
+			wasPinned, err := isMessagePinnedIn(ctx.DB, msg.ID, threadGUID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
 			now := time.Now().Unix()
 			if err := db.PinMessage(ctx.DB, msg.ID, threadGUID, pinnedBy, now); err != nil {
 				return writeCommandError(cmd, err)
@@ -234,6 +242,13 @@ Examples:
 				return writeCommandError(cmd, err)
 			}
 
+			if !wasPinned {
+				if err := recordUndo(cmd, ctx, "pin", fmt.Sprintf("unpin %s from %s", msg.ID, threadGUID),
+					[][]string{{"unpin", msg.ID, "--thread", threadGUID}}); err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
+
 			if ctx.JSONMode {
 				payload := map[string]any{
 					"message": msg.ID,
@@ -301,6 +316,11 @@ If --thread is not specified, unpins from the message's home thread.`,
 				}
 			}
 
+			wasPinned, err := isMessagePinnedIn(ctx.DB, msg.ID, threadGUID)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
 			if err := db.UnpinMessage(ctx.DB, msg.ID, threadGUID); err != nil {
 				return writeCommandError(cmd, err)
 			}
@@ -315,6 +335,13 @@ If --thread is not specified, unpins from the message's home thread.`,
 				return writeCommandError(cmd, err)
 			}
 
+			if wasPinned {
+				if err := recordUndo(cmd, ctx, "unpin", fmt.Sprintf("re-pin %s in %s", msg.ID, threadGUID),
+					[][]string{{"pin", msg.ID, "--thread", threadGUID}}); err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
+
 			if ctx.JSONMode {
 				payload := map[string]any{
 					"message":  msg.ID,
@@ -344,7 +371,11 @@ func NewMvCmd() *cobra.Command {
 
 For messages:
   The destination can be a thread reference or "room" to move back to room.
-  Use --with-replies to move the message and all its replies.
+  Use --with-replies to move the message and all its replies. Reply sets
+  larger than mv_max_replies (default 1000) are refused unless --max-replies
+  raises the limit.
+  Use --dry-run to preview what would move (authors, time span, warnings).
+  Moving a thread anchor or a message pinned elsewhere requires --force.
 
 For threads:
   Move a thread to become a child of another thread.
@@ -355,6 +386,7 @@ Examples:
   fray mv msg-abc thrd-xyz               # Move message to thread
   fray mv msg-abc room                   # Move message back to room
   fray mv msg-abc thrd-xyz --with-replies
+  fray mv msg-abc thrd-xyz --with-replies --dry-run
   fray mv design-thread meta             # Reparent thread under meta
   fray mv design-thread meta "Summary"   # Reparent + set anchor
   fray mv design-thread root             # Make thread root-level`,
@@ -367,6 +399,7 @@ Examples:
 			defer ctx.DB.Close()
 
 			withReplies, _ := cmd.Flags().GetBool("with-replies")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
 			asRef, _ := cmd.Flags().GetString("as")
 
 			// Try to resolve first arg as a thread (for reparenting)
@@ -401,47 +434,69 @@ Examples:
 				}
 			}
 
-			now := time.Now().Unix()
-			moved := 0
+			maxReplies, err := maxMoveReplies(cmd, ctx)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			plan, err := planMessageMove(ctx.DB, messageRefs, newHome, withReplies, maxReplies)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
 
-			for _, messageRef := range messageRefs {
-				msg, err := resolveMessageRef(ctx.DB, messageRef)
-				if err != nil {
-					return writeCommandError(cmd, err)
+			destName := newHome
+			if newHome != "room" {
+				if thread, _ := db.GetThread(ctx.DB, newHome); thread != nil {
+					destName = thread.Name
 				}
+			}
 
-				messagesToMove := []types.Message{*msg}
-
-				// If --with-replies, get all replies recursively
-				if withReplies {
-					replies, err := getAllReplies(ctx.DB, msg.ID)
-					if err != nil {
-						return writeCommandError(cmd, err)
+			if dryRun {
+				if ctx.JSONMode {
+					payload := map[string]any{
+						"dry_run":     true,
+						"destination": newHome,
+						"summary":     plan.summarize(),
+						"messages":    plan.Messages,
+						"skipped":     plan.Skipped,
+						"warnings":    plan.Warnings,
 					}
-					messagesToMove = append(messagesToMove, replies...)
+					return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
 				}
+				printMovePlan(cmd.OutOrStdout(), plan, destName)
+				return nil
+			}
 
-				for _, m := range messagesToMove {
-					if m.Home == newHome {
-						continue
-					}
+			if len(plan.Warnings) > 0 && !ctx.Force {
+				lines := make([]string, 0, len(plan.Warnings))
+				for _, w := range plan.Warnings {
+					lines = append(lines, "  "+describeMoveWarning(w))
+				}
+				return writeCommandError(cmd, fmt.Errorf("move affects thread curation:\n%s\nUse --force to move anyway, or --dry-run to preview", strings.Join(lines, "\n")))
+			}
 
-					oldHome := m.Home
-					if err := db.MoveMessage(ctx.DB, m.ID, newHome); err != nil {
-						return writeCommandError(cmd, err)
-					}
+			if !ctx.JSONMode {
+				printReplyClosures(cmd.OutOrStdout(), plan.Closures, "Moving replies: ")
+			}
 
-					if err := db.AppendMessageMove(ctx.Project.DBPath, db.MessageMoveJSONLRecord{
-						MessageGUID: m.ID,
-						OldHome:     oldHome,
-						NewHome:     newHome,
-						MovedBy:     movedBy,
-						MovedAt:     now,
-					}); err != nil {
-						return writeCommandError(cmd, err)
-					}
-					moved++
+			now := time.Now().Unix()
+			moved := 0
+
+			for _, m := range plan.Messages {
+				oldHome := m.Home
+				if err := db.MoveMessage(ctx.DB, m.ID, newHome); err != nil {
+					return writeCommandError(cmd, err)
+				}
+
+				if err := db.AppendMessageMove(ctx.Project.DBPath, db.MessageMoveJSONLRecord{
+					MessageGUID: m.ID,
+					OldHome:     oldHome,
+					NewHome:     newHome,
+					MovedBy:     movedBy,
+					MovedAt:     now,
+				}); err != nil {
+					return writeCommandError(cmd, err)
 				}
+				moved++
 			}
 
 			// Update thread activity if moving to a thread
@@ -457,6 +512,11 @@ Examples:
 				}
 			}
 
+			description := fmt.Sprintf("move %d message(s) back from %s", moved, destName)
+			if err := recordUndo(cmd, ctx, "mv", description, moveInverse(plan.Messages)); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
 			if ctx.JSONMode {
 				payload := map[string]any{
 					"destination": newHome,
@@ -465,17 +525,40 @@ Examples:
 				return json.NewEncoder(cmd.OutOrStdout()).Encode(payload)
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Moved %d message(s) to %s\n", moved, newHome)
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved %d message(s) to %s\n", moved, destName)
 			return nil
 		},
 	}
 
 	cmd.Flags().Bool("with-replies", false, "move message and all its replies")
+	cmd.Flags().Bool("dry-run", false, "preview the move without writing anything")
 	cmd.Flags().String("as", "", "agent to attribute the move")
+	cmd.Flags().Int("max-replies", 0, "largest reply set --with-replies may move (default mv_max_replies or 1000)")
 
 	return cmd
 }
 
+// maxMoveReplies resolves the --with-replies closure limit from --max-replies,
+// then mv_max_replies, then the default.
+func maxMoveReplies(cmd *cobra.Command, ctx *CommandContext) (int, error) {
+	if cmd.Flags().Changed("max-replies") {
+		limit, _ := cmd.Flags().GetInt("max-replies")
+		if limit <= 0 {
+			return 0, validationError("--max-replies must be a positive integer")
+		}
+		return limit, nil
+	}
+	value, err := getConfigValue(ctx, db.MaxMoveRepliesConfigKey)
+	if err != nil || value == "" {
+		return db.DefaultMaxMoveReplies, nil
+	}
+	limit, err := db.ParseMaxMoveReplies(value)
+	if err != nil {
+		return db.DefaultMaxMoveReplies, nil
+	}
+	return limit, nil
+}
+
 // runThreadReparent handles moving a thread to a new parent.
 func runThreadReparent(cmd *cobra.Command, ctx *CommandContext, args []string, sourceThread *types.Thread, asRef string) error {
 	if len(args) < 2 {
@@ -488,6 +571,10 @@ func runThreadReparent(cmd *cobra.Command, ctx *CommandContext, args []string, s
 		anchorText = strings.Join(args[2:], " ")
 	}
 
+	if err := checkThreadCuration(ctx, sourceThread, asRef, "move"); err != nil {
+		return writeCommandError(cmd, err)
+	}
+
 	// Resolve agent
 	movedBy := "system"
 	if asRef != "" {
@@ -561,6 +648,15 @@ func runThreadReparent(cmd *cobra.Command, ctx *CommandContext, args []string, s
 		return writeCommandError(cmd, err)
 	}
 
+	oldParentRef := "root"
+	if currentParent != "" {
+		oldParentRef = currentParent
+	}
+	if err := recordUndo(cmd, ctx, "mv", fmt.Sprintf("move thread %s back under %s", sourceThread.Name, oldParentRef),
+		[][]string{{"mv", sourceThread.GUID, oldParentRef}}); err != nil {
+		return writeCommandError(cmd, err)
+	}
+
 	// If anchor text provided, create anchor message
 	if anchorText != "" {
 		bases, err := db.GetAgentBases(ctx.DB)
@@ -916,36 +1012,15 @@ Examples:
 	return cmd
 }
 
-// getAllReplies recursively gets all replies to a message.
-func getAllReplies(database *sql.DB, messageGUID string) ([]types.Message, error) {
-	seen := make(map[string]struct{})
-	return getAllRepliesWithGuard(database, messageGUID, seen)
-}
-
-func getAllRepliesWithGuard(database *sql.DB, messageGUID string, seen map[string]struct{}) ([]types.Message, error) {
-	if _, ok := seen[messageGUID]; ok {
-		return nil, nil // already visited, break cycle
-	}
-	seen[messageGUID] = struct{}{}
-
-	var result []types.Message
-
-	replies, err := db.GetReplies(database, messageGUID)
+// getAllReplies returns every direct and transitive reply to a message,
+// refusing closures larger than maxReplies.
+func getAllReplies(database *sql.DB, messageGUID string, maxReplies int) ([]types.Message, error) {
+	count, err := db.CountReplyClosure(database, messageGUID)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, reply := range replies {
-		if _, ok := seen[reply.ID]; ok {
-			continue // skip already visited
-		}
-		result = append(result, reply)
-		nested, err := getAllRepliesWithGuard(database, reply.ID, seen)
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, nested...)
+	if count > maxReplies {
+		return nil, validationError("%s has %d replies, over the limit of %d. Use --max-replies %d to move them anyway", messageGUID, count, maxReplies, count)
 	}
-
-	return result, nil
+	return db.GetReplyClosure(database, messageGUID)
 }
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/adamavenir/fray/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+// NewThreadDigestCmd creates the thread digest command.
+func NewThreadDigestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "digest <thread>",
+		Short: "Summarize a thread and make the summary its anchor",
+		Long: `Refresh a thread's anchor with a generated summary.
+
+Runs llm/digest.mld (via mlld) over the thread's recent messages, posts the
+result in the thread, and makes it the anchor. The previous anchor is pinned
+in the thread so the old summary stays reachable. Does nothing when mlld or
+the script is not available.
+
+With --auto the digest only runs once digest_every_n_messages messages have
+been posted since the current anchor. --enable opts the thread in to having
+the daemon do that automatically.
+
+Examples:
+  fray thread digest design-thread --as pm
+  fray thread digest design-thread --as pm --auto
+  fray config digest_every_n_messages 25
+  fray thread digest design-thread --enable`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			thread, err := resolveThreadRef(ctx.DB, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			enable, _ := cmd.Flags().GetBool("enable")
+			disable, _ := cmd.Flags().GetBool("disable")
+			auto, _ := cmd.Flags().GetBool("auto")
+			out := cmd.OutOrStdout()
+
+			if enable && disable {
+				return writeCommandError(cmd, fmt.Errorf("--enable and --disable are mutually exclusive"))
+			}
+			if enable || disable {
+				if err := daemon.SetDigestEnabled(ctx.DB, thread.GUID, enable); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if ctx.JSONMode {
+					return json.NewEncoder(out).Encode(map[string]any{"thread": thread.GUID, "auto_digest": enable})
+				}
+				if !enable {
+					fmt.Fprintf(out, "Auto digest disabled for %s\n", thread.Name)
+					return nil
+				}
+				fmt.Fprintf(out, "Auto digest enabled for %s\n", thread.Name)
+				if every, _ := daemon.DigestEvery(ctx.DB); every <= 0 {
+					fmt.Fprintf(out, "Set digest_every_n_messages for the daemon to refresh it (fray config digest_every_n_messages 25)\n")
+				}
+				return nil
+			}
+
+			asRef, _ := cmd.Flags().GetString("as")
+			if asRef == "" {
+				return writeCommandError(cmd, fmt.Errorf("--as is required"))
+			}
+			agentID, err := resolveAgentRef(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if auto {
+				every, err := daemon.DigestEvery(ctx.DB)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if every <= 0 {
+					return writeCommandError(cmd, fmt.Errorf("--auto needs digest_every_n_messages (fray config digest_every_n_messages 25)"))
+				}
+				due, err := daemon.ThreadDigestDue(ctx.DB, *thread, every)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if !due {
+					if ctx.JSONMode {
+						return json.NewEncoder(out).Encode(map[string]any{"thread": thread.GUID, "refreshed": false, "reason": "not due"})
+					}
+					fmt.Fprintf(out, "Digest for %s is not due (fewer than %d new messages)\n", thread.Name, every)
+					return nil
+				}
+			}
+
+			result, err := daemon.RefreshThreadDigest(context.Background(), ctx.DB, ctx.Project, *thread, agentID)
+			if errors.Is(err, daemon.ErrDigestUnavailable) {
+				if ctx.JSONMode {
+					return json.NewEncoder(out).Encode(map[string]any{"thread": thread.GUID, "refreshed": false, "reason": err.Error()})
+				}
+				fmt.Fprintf(out, "Skipping digest: %v\n", err)
+				return nil
+			}
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(out).Encode(result)
+			}
+			fmt.Fprintf(out, "[%s] Digest posted and set as anchor for %s\n", result.Message.ID, thread.Name)
+			if result.PreviousAnchor != "" {
+				fmt.Fprintf(out, "  previous summary %s pinned\n", result.PreviousAnchor)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent to attribute the digest")
+	cmd.Flags().Bool("auto", false, "only refresh once digest_every_n_messages new messages exist")
+	cmd.Flags().Bool("enable", false, "let the daemon refresh this thread's digest")
+	cmd.Flags().Bool("disable", false, "stop daemon digests for this thread")
+
+	return cmd
+}
@@ -0,0 +1,40 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/daemon"
+)
+
+func TestThreadDigestAutoAndEnable(t *testing.T) {
+	projectDir := newFlowProject(t, "pm")
+	runFray(t, "thread", "design", "initial plan", "--as", "pm")
+
+	output := runFray(t, "thread", "digest", "design", "--enable")
+	if !strings.Contains(output, "Auto digest enabled for design") {
+		t.Fatalf("unexpected enable output: %q", output)
+	}
+	dbConn := openProjectDB(t, projectDir)
+	guids, err := daemon.DigestEnabledThreads(dbConn)
+	_ = dbConn.Close()
+	if err != nil || len(guids) != 1 {
+		t.Fatalf("expected design to be opted in, got %v (%v)", guids, err)
+	}
+
+	if _, err := executeCommand(NewRootCmd("test"), "thread", "digest", "design", "--as", "pm", "--auto"); err == nil {
+		t.Fatalf("expected --auto to require digest_every_n_messages")
+	}
+
+	runFray(t, "config", "digest_every_n_messages", "5")
+	output = runFray(t, "thread", "digest", "design", "--as", "pm", "--auto")
+	if !strings.Contains(output, "not due") {
+		t.Fatalf("expected digest not to be due, got %q", output)
+	}
+
+	// Without mlld and llm/digest.mld the digest is a no-op.
+	output = runFray(t, "thread", "digest", "design", "--as", "pm")
+	if !strings.Contains(output, "Skipping digest") {
+		t.Fatalf("expected graceful skip, got %q", output)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
 	"github.com/adamavenir/fray/internal/types"
 )
@@ -16,7 +17,7 @@ import (
 func resolveThreadRef(dbConn *sql.DB, ref string) (*types.Thread, error) {
 	value := strings.TrimSpace(strings.TrimPrefix(ref, "#"))
 	if value == "" {
-		return nil, fmt.Errorf("thread reference is required")
+		return nil, validationError("thread reference is required")
 	}
 	if strings.Contains(value, "/") {
 		return resolveThreadPath(dbConn, value)
@@ -46,7 +47,15 @@ func resolveThreadRef(dbConn *sql.DB, ref string) (*types.Thread, error) {
 		return thread, nil
 	}
 
-	return nil, fmt.Errorf("thread not found: %s", ref)
+	thread, err = db.GetThreadByNameFold(dbConn, value, nil)
+	if err != nil {
+		return nil, err
+	}
+	if thread != nil {
+		return thread, nil
+	}
+
+	return nil, notFoundError("thread not found: %s", ref)
 }
 
 func resolveThreadPath(dbConn *sql.DB, path string) (*types.Thread, error) {
@@ -55,7 +64,7 @@ func resolveThreadPath(dbConn *sql.DB, path string) (*types.Thread, error) {
 	for _, part := range parts {
 		name := strings.TrimSpace(part)
 		if name == "" {
-			return nil, fmt.Errorf("invalid thread path: %s", path)
+			return nil, validationError("invalid thread path: %s", path)
 		}
 		var parentGUID *string
 		if parent != nil {
@@ -66,12 +75,18 @@ func resolveThreadPath(dbConn *sql.DB, path string) (*types.Thread, error) {
 			return nil, err
 		}
 		if thread == nil {
-			return nil, fmt.Errorf("thread not found: %s", path)
+			thread, err = db.GetThreadByNameFold(dbConn, name, parentGUID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if thread == nil {
+			return nil, notFoundError("thread not found: %s", path)
 		}
 		parent = thread
 	}
 	if parent == nil {
-		return nil, fmt.Errorf("thread not found: %s", path)
+		return nil, notFoundError("thread not found: %s", path)
 	}
 	return parent, nil
 }
@@ -101,17 +116,24 @@ func buildThreadPath(dbConn *sql.DB, thread *types.Thread) (string, error) {
 	return strings.Join(names, "/"), nil
 }
 
+// validateThreadName checks a sanitized name with core.ValidateThreadName,
+// reporting a failure as a validation error.
 func validateThreadName(name string) error {
-	trimmed := strings.TrimSpace(name)
-	if trimmed == "" {
-		return fmt.Errorf("thread name is required")
-	}
-	if strings.Contains(trimmed, "/") {
-		return fmt.Errorf("thread name cannot contain '/'")
+	if err := core.ValidateThreadName(name); err != nil {
+		return validationError("%s", err)
 	}
 	return nil
 }
 
+// threadExistsError reports a name collision and points at the thread to use.
+func threadExistsError(dbConn *sql.DB, existing *types.Thread) error {
+	path, _ := buildThreadPath(dbConn, existing)
+	if path == "" {
+		path = existing.Name
+	}
+	return conflictError("thread already exists: %s (%s). Use 'fray thread %s' instead", path, existing.GUID, path)
+}
+
 // validKebabCase matches lowercase kebab-case names (e.g., my-thread-name)
 var validKebabCase = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
 
@@ -180,6 +202,11 @@ func SanitizeThreadName(name string) (string, bool) {
 		case unicode.IsLower(r) || unicode.IsDigit(r):
 			result.WriteRune(r)
 			prevWasHyphen = false
+		case r == ':' || r == '.' || r == '+' || r == '/':
+			// Keep the punctuation core.ValidateThreadName allows, and '/',
+			// which it rejects: dropping it would silently join path segments.
+			result.WriteRune(r)
+			prevWasHyphen = false
 		case r == '-' || r == '_' || r == ' ':
 			if !prevWasHyphen {
 				result.WriteRune('-')
@@ -222,6 +249,20 @@ func filterMessage(messages []types.Message, excludeID string) []types.Message {
 	return result
 }
 
+// isMessagePinnedIn reports whether a message is pinned in the given thread.
+func isMessagePinnedIn(dbConn *sql.DB, messageGUID, threadGUID string) (bool, error) {
+	threads, err := db.GetMessagePinThreads(dbConn, messageGUID)
+	if err != nil {
+		return false, err
+	}
+	for _, guid := range threads {
+		if guid == threadGUID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func formatLastActivity(ts *int64) string {
 	if ts == nil {
 		return "unknown"
@@ -37,6 +37,11 @@ func TestSanitizeThreadName(t *testing.T) {
 		{"my@thread!", "mythread", true},
 		{"name#123", "name123", true},
 
+		// Thread name punctuation is kept; '/' is kept for validation to reject
+		{"v1.2", "v1.2", false},
+		{"bd:abc1-work", "bd:abc1-work", false},
+		{"notes/2024", "notes/2024", false},
+
 		// Edge cases
 		{"", "", false},
 		{"   ", "", false},
@@ -0,0 +1,108 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// threadCurationOpenKey lifts the thread curation checks when set to true.
+const threadCurationOpenKey = "thread_curation_open"
+
+// curationActor identifies who is curating a thread: --as, then the
+// FRAY_AGENT_ID of a daemon-spawned agent, then the logged-in human.
+// An empty result means the caller is unidentified.
+func curationActor(ctx *CommandContext, asRef string) (string, error) {
+	if asRef != "" {
+		return resolveAgentRef(ctx, asRef)
+	}
+	if agentID := strings.TrimSpace(os.Getenv("FRAY_AGENT_ID")); agentID != "" {
+		return agentID, nil
+	}
+	return humanIdentity(ctx)
+}
+
+// authorizeThreadCuration checks that actor may perform action (anchor,
+// archive, rename, ...) on thread. The thread's owner, its creator, and human
+// users are allowed; threads with neither owner nor creator stay open to all.
+// thread_curation_open=true restores the old free-for-all.
+func authorizeThreadCuration(ctx *CommandContext, thread *types.Thread, actor, action string) error {
+	if thread == nil {
+		return nil
+	}
+	value, _ := getConfigValue(ctx, threadCurationOpenKey)
+	if open, _ := db.ParseConfigBool(value); open {
+		return nil
+	}
+
+	owner := threadOwner(ctx.DB, thread)
+	creator := ""
+	if thread.CreatedBy != nil {
+		creator = *thread.CreatedBy
+	}
+	if owner == "" && creator == "" {
+		return nil
+	}
+
+	if actor != "" {
+		base := actor
+		if parsed, err := core.ParseAgentID(actor); err == nil {
+			base = parsed.Base
+		}
+		if actor == owner || base == owner || actor == creator || base == creator {
+			return nil
+		}
+		if isHumanActor(ctx, actor) {
+			return nil
+		}
+	}
+
+	var allowed []string
+	if owner != "" {
+		allowed = append(allowed, "@"+owner+" (owner)")
+	}
+	if creator != "" && creator != owner {
+		allowed = append(allowed, "@"+creator+" (creator)")
+	}
+	allowed = append(allowed, "human users")
+	who := "unidentified caller (use --as)"
+	if actor != "" {
+		who = "@" + actor
+	}
+	return fmt.Errorf("%s cannot %s thread %s: allowed are %s. Set %s=true to lift this", who, action, thread.Name, strings.Join(allowed, ", "), threadCurationOpenKey)
+}
+
+// isHumanActor reports whether id is a human user rather than an agent. A
+// registered agent never counts as human, even when a legacy username or an
+// agent registered later shares the login name.
+func isHumanActor(ctx *CommandContext, id string) bool {
+	if agent, err := db.GetAgent(ctx.DB, id); err != nil || agent != nil {
+		return false
+	}
+	if username, _ := humanIdentity(ctx); username != "" && username == id {
+		return true
+	}
+	users, err := db.GetActiveUsers(ctx.DB, ctx.Project.DBPath)
+	if err != nil {
+		return false
+	}
+	for _, user := range users {
+		if user == id {
+			return true
+		}
+	}
+	return false
+}
+
+// checkThreadCuration resolves the acting identity and authorizes it.
+func checkThreadCuration(ctx *CommandContext, thread *types.Thread, asRef, action string) error {
+	actor, err := curationActor(ctx, asRef)
+	if err != nil {
+		return err
+	}
+	return authorizeThreadCuration(ctx, thread, actor, action)
+}
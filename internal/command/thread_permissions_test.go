@@ -0,0 +1,73 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+)
+
+func TestThreadCurationRestrictedToCreator(t *testing.T) {
+	projectDir := newFlowProject(t, "bob", "eve")
+	runFray(t, "thread", "design", "--as", "bob")
+
+	dbConn := openProjectDB(t, projectDir)
+	thread, err := db.GetThreadByName(dbConn, "design", nil)
+	dbConn.Close()
+	if err != nil || thread == nil || thread.CreatedBy == nil || *thread.CreatedBy != "bob" {
+		t.Fatalf("expected design created by bob, got %+v (%v)", thread, err)
+	}
+
+	for _, args := range [][]string{
+		{"archive", "design", "--as", "eve"},
+		{"thread", "rename", "design", "plans", "--as", "eve"},
+		{"anchor", "design", "new anchor", "--as", "eve"},
+		{"rm", "design", "--as", "eve"},
+	} {
+		output, err := executeCommand(NewRootCmd("test"), args...)
+		if err == nil || !strings.Contains(output, "@eve cannot") || !strings.Contains(output, "@bob (creator)") {
+			t.Fatalf("expected %v to be denied naming the creator, got %q (%v)", args, output, err)
+		}
+	}
+
+	runFray(t, "archive", "design", "--as", "bob")
+	runFray(t, "restore", "design", "--as", "bob")
+
+	// Humans can always curate.
+	runFray(t, "config", "username", "adam")
+	runFray(t, "thread", "rename", "design", "plans")
+
+	// The escape hatch reopens curation to everyone.
+	runFray(t, "config", "thread_curation_open", "true")
+	runFray(t, "archive", "plans", "--as", "eve")
+}
+
+func TestThreadCurationOwnerAndUnownedThreads(t *testing.T) {
+	newFlowProject(t, "opus", "eve")
+	runFray(t, "thread", "meta")
+	runFray(t, "thread", "meta/opus")
+	runFray(t, "thread", "meta/opus/notes")
+	runFray(t, "thread", "scratch")
+
+	output, err := executeCommand(NewRootCmd("test"), "archive", "meta/opus/notes", "--as", "eve")
+	if err == nil || !strings.Contains(output, "@opus (owner)") {
+		t.Fatalf("expected owner-only archive, got %q (%v)", output, err)
+	}
+	runFray(t, "archive", "meta/opus/notes", "--as", "opus")
+
+	// Threads with no owner or creator stay open.
+	runFray(t, "archive", "scratch", "--as", "eve")
+}
+
+func TestThreadCurationLoginSharedWithAgentIsNotHuman(t *testing.T) {
+	newFlowProject(t, "bob", "eve")
+	runFray(t, "thread", "design", "--as", "bob")
+
+	// A legacy username that names a registered agent grants no human rights.
+	runFray(t, "config", "username", "eve")
+	t.Setenv("FRAY_AGENT_ID", "")
+	output, err := executeCommand(NewRootCmd("test"), "archive", "design")
+	if err == nil || !strings.Contains(output, "@eve cannot") {
+		t.Fatalf("expected the agent-named login to be denied, got %q (%v)", output, err)
+	}
+}
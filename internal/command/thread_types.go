@@ -0,0 +1,193 @@
+package command
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// settableThreadTypes lists the types accepted by `fray thread type`.
+var settableThreadTypes = []types.ThreadType{
+	types.ThreadTypeStandard,
+	types.ThreadTypeKnowledge,
+	types.ThreadTypeMeta,
+	types.ThreadTypeNotes,
+	types.ThreadTypeJournal,
+	types.ThreadTypeKeys,
+}
+
+func parseThreadType(value string) (types.ThreadType, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "jrnl" {
+		value = string(types.ThreadTypeJournal)
+	}
+	for _, t := range settableThreadTypes {
+		if string(t) == value {
+			return t, nil
+		}
+	}
+	names := make([]string, 0, len(settableThreadTypes))
+	for _, t := range settableThreadTypes {
+		names = append(names, string(t))
+	}
+	return "", fmt.Errorf("invalid thread type %q (expected one of: %s)", value, strings.Join(names, ", "))
+}
+
+// setThreadType updates a thread's type in the DB and JSONL.
+func setThreadType(ctx *CommandContext, thread *types.Thread, threadType types.ThreadType) (*types.Thread, error) {
+	value := string(threadType)
+	updated, err := db.UpdateThread(ctx.DB, thread.GUID, db.ThreadUpdates{
+		Type: types.OptionalString{Set: true, Value: &value},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AppendThreadUpdate(ctx.Project.DBPath, db.ThreadUpdateJSONLRecord{
+		GUID:       updated.GUID,
+		ThreadType: &value,
+	}); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// upgradeLegacyThreadType retypes hierarchy threads created before typed
+// notes/jrnl/keys existed. Threads with any other type were set deliberately
+// and are left alone.
+func upgradeLegacyThreadType(ctx *CommandContext, thread *types.Thread, threadType types.ThreadType) error {
+	if thread == nil || thread.Type != types.ThreadTypeSystem {
+		return nil
+	}
+	_, err := setThreadType(ctx, thread, threadType)
+	return err
+}
+
+// threadOwner returns the agent that owns a thread, if any. Hierarchy threads
+// (meta/{agent}/jrnl) are owned by the agent named by their parent.
+func threadOwner(dbConn *sql.DB, thread *types.Thread) string {
+	if thread == nil {
+		return ""
+	}
+	if thread.OwnerAgent != nil && *thread.OwnerAgent != "" {
+		return *thread.OwnerAgent
+	}
+	if thread.ParentThread == nil {
+		return ""
+	}
+	parent, err := db.GetThread(dbConn, *thread.ParentThread)
+	if err != nil || parent == nil || parent.ParentThread == nil {
+		return ""
+	}
+	grandparent, err := db.GetThread(dbConn, *parent.ParentThread)
+	if err != nil || grandparent == nil {
+		return ""
+	}
+	if grandparent.Name == "meta" && grandparent.ParentThread == nil {
+		return parent.Name
+	}
+	return ""
+}
+
+// checkThreadPostRules enforces per-type posting rules. replyMsg is the message
+// being replied to, if any. force bypasses the journal owner check.
+func checkThreadPostRules(dbConn *sql.DB, thread *types.Thread, agentID string, replyMsg *types.Message, force bool) error {
+	if replyMsg != nil {
+		replyHome := thread
+		if replyMsg.Home != "" && replyMsg.Home != "room" && (thread == nil || replyMsg.Home != thread.GUID) {
+			replyHome, _ = db.GetThread(dbConn, replyMsg.Home)
+		}
+		if replyHome != nil && replyHome.Type == types.ThreadTypeKeys {
+			return fmt.Errorf("keys threads don't take replies. React instead: fray react <emoji> %s", replyMsg.ID)
+		}
+	}
+
+	if thread != nil && thread.Type == types.ThreadTypeJournal && !force {
+		owner := threadOwner(dbConn, thread)
+		if owner != "" && owner != agentID {
+			return fmt.Errorf("journal %s belongs to @%s. Use --force to post anyway", thread.Name, owner)
+		}
+	}
+
+	return nil
+}
+
+// threadExcludesBroadcast reports whether @all should not expand in a thread.
+func threadExcludesBroadcast(thread *types.Thread) bool {
+	if thread == nil {
+		return false
+	}
+	switch thread.Type {
+	case types.ThreadTypeMeta, types.ThreadTypeNotes:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewThreadTypeCmd shows or sets a thread's type.
+func NewThreadTypeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "type <thread> [type]",
+		Short: "Show or set a thread's type",
+		Long: `Show or set a thread's type.
+
+Types:
+  standard   Normal thread (default)
+  knowledge  Knowledge hierarchy (meta/{agent}, role threads)
+  meta       Project meta root
+  notes      Agent notes; @all does not expand here
+  journal    Agent journal; only the owner may post (override with --force)
+  keys       Role keys; replies are rejected, react instead`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			thread, err := resolveThreadRef(ctx.DB, args[0])
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if len(args) == 2 {
+				threadType, err := parseThreadType(args[1])
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				thread, err = setThreadType(ctx, thread, threadType)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
+
+			threadType := thread.Type
+			if threadType == "" {
+				threadType = types.ThreadTypeStandard
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"guid": thread.GUID,
+					"name": thread.Name,
+					"type": threadType,
+				})
+			}
+
+			if len(args) == 2 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Set %s (%s) type to %s\n", thread.Name, thread.GUID, threadType)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s (%s): %s\n", thread.Name, thread.GUID, threadType)
+			return nil
+		},
+	}
+
+	return cmd
+}
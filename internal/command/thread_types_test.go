@@ -0,0 +1,141 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestHierarchyThreadsAreTyped(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "role", "add", "alice", "architect")
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+
+	cases := map[string]types.ThreadType{
+		"meta":                     types.ThreadTypeMeta,
+		"meta/alice":               types.ThreadTypeKnowledge,
+		"meta/alice/notes":         types.ThreadTypeNotes,
+		"meta/alice/jrnl":          types.ThreadTypeJournal,
+		"meta/role-architect/keys": types.ThreadTypeKeys,
+	}
+	for path, want := range cases {
+		thread, err := resolveThreadRef(dbConn, path)
+		if err != nil {
+			t.Fatalf("resolve %s: %v", path, err)
+		}
+		if thread.Type != want {
+			t.Fatalf("expected %s to be %s, got %s", path, want, thread.Type)
+		}
+	}
+}
+
+func TestJournalIsOwnerOnly(t *testing.T) {
+	newFlowProject(t, "alice", "bob")
+
+	runFray(t, "post", "meta/alice/jrnl", "--as", "alice", "today I learned about thread types")
+
+	output, err := executeCommand(NewRootCmd("test"), "post", "meta/alice/jrnl", "--as", "bob", "sneaking into alice's journal")
+	if err == nil {
+		t.Fatalf("expected non-owner journal post to fail")
+	}
+	if !strings.Contains(output, "belongs to @alice") {
+		t.Fatalf("expected ownership error, got %q", output)
+	}
+
+	runFray(t, "post", "meta/alice/jrnl", "--as", "bob", "--force", "leaving a note with permission")
+}
+
+func TestKeysRejectRepliesButAllowReactions(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	runFray(t, "role", "add", "alice", "architect")
+	runFray(t, "post", "meta/role-architect/keys", "--as", "alice", "always write the migration first")
+
+	dbConn := openProjectDB(t, projectDir)
+	keys, err := resolveThreadRef(dbConn, "meta/role-architect/keys")
+	if err != nil {
+		t.Fatalf("resolve keys: %v", err)
+	}
+	msgs, err := db.GetMessages(dbConn, &types.MessageQueryOptions{Home: &keys.GUID})
+	if err != nil || len(msgs) == 0 {
+		t.Fatalf("get keys messages: %v", err)
+	}
+	keyID := msgs[len(msgs)-1].ID
+	_ = dbConn.Close()
+
+	output, err := executeCommand(NewRootCmd("test"), "post", "--as", "bob", "--reply-to", keyID, "I disagree with this one quite strongly")
+	if err == nil {
+		t.Fatalf("expected reply in keys thread to fail")
+	}
+	if !strings.Contains(output, "fray react") {
+		t.Fatalf("expected reaction suggestion, got %q", output)
+	}
+
+	runFray(t, "post", "--as", "bob", "--reply-to", keyID, "+1")
+}
+
+func TestNotesDoNotExpandAll(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+	runFray(t, "post", "meta/alice/notes", "--as", "alice", "@all reminder to self about the release")
+	runFray(t, "post", "--as", "alice", "@all the release is out")
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+
+	notes, err := resolveThreadRef(dbConn, "meta/alice/notes")
+	if err != nil {
+		t.Fatalf("resolve notes: %v", err)
+	}
+	notesMsgs, err := db.GetMessages(dbConn, &types.MessageQueryOptions{Home: &notes.GUID})
+	if err != nil || len(notesMsgs) == 0 {
+		t.Fatalf("get notes messages: %v", err)
+	}
+	for _, mention := range notesMsgs[len(notesMsgs)-1].Mentions {
+		if mention == "bob" {
+			t.Fatalf("expected @all in notes not to reach bob")
+		}
+	}
+
+	roomID := findRoomMessageByBody(t, dbConn, "@all the release is out")
+	roomMsg, err := db.GetMessage(dbConn, roomID)
+	if err != nil {
+		t.Fatalf("get room message: %v", err)
+	}
+	found := false
+	for _, mention := range roomMsg.Mentions {
+		if mention == "bob" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected @all in room to reach bob, got %v", roomMsg.Mentions)
+	}
+}
+
+func TestThreadTypeCommand(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "scratch")
+
+	output := runFray(t, "thread", "type", "scratch")
+	if !strings.Contains(output, "standard") {
+		t.Fatalf("expected standard type, got %q", output)
+	}
+
+	runFray(t, "thread", "type", "scratch", "keys")
+	if _, err := executeCommand(NewRootCmd("test"), "thread", "type", "scratch", "bogus"); err == nil {
+		t.Fatalf("expected invalid type to fail")
+	}
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	thread, err := db.GetThreadByName(dbConn, "scratch", nil)
+	if err != nil || thread == nil {
+		t.Fatalf("get thread: %v", err)
+	}
+	if thread.Type != types.ThreadTypeKeys {
+		t.Fatalf("expected keys type, got %s", thread.Type)
+	}
+}
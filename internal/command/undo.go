@@ -0,0 +1,220 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// undoListLimit caps `fray undo --list` output.
+const undoListLimit = 10
+
+// undoReplayKey marks a command context as replaying an inverse, so the
+// replayed command doesn't record an undo entry of its own.
+type undoReplayKey struct{}
+
+// recordUndo appends an undo entry for a reversible command. Each inverse is a
+// fray argv that is replayed through the normal command path by `fray undo`.
+func recordUndo(cmd *cobra.Command, ctx *CommandContext, command, description string, inverse [][]string) error {
+	if len(inverse) == 0 || isUndoReplay(cmd) {
+		return nil
+	}
+	_, err := db.AppendUndoEntry(ctx.Project.DBPath, db.UndoRecord{
+		Command:     command,
+		Description: description,
+		Inverse:     inverse,
+		RecordedAt:  time.Now().Unix(),
+	})
+	return err
+}
+
+// recordUndoBarrier notes a non-reversible command; earlier entries can no
+// longer be undone once the data they refer to may be gone.
+func recordUndoBarrier(cmd *cobra.Command, ctx *CommandContext, command string) error {
+	if isUndoReplay(cmd) {
+		return nil
+	}
+	return db.AppendUndoBarrier(ctx.Project.DBPath, command, time.Now().Unix())
+}
+
+func isUndoReplay(cmd *cobra.Command) bool {
+	if cmd.Context() == nil {
+		return false
+	}
+	replay, _ := cmd.Context().Value(undoReplayKey{}).(bool)
+	return replay
+}
+
+// claimInverse returns commands that recreate the given claims.
+func claimInverse(claims []types.Claim) [][]string {
+	now := time.Now().Unix()
+	inverse := make([][]string, 0, len(claims))
+	for _, claim := range claims {
+		if claim.ExpiresAt != nil && *claim.ExpiresAt <= now {
+			continue
+		}
+		argv := append([]string{"claim", claim.AgentID}, claimFlag(claim.ClaimType, claim.Pattern)...)
+		if claim.Reason != nil && *claim.Reason != "" {
+			argv = append(argv, "--note", *claim.Reason)
+		}
+		if claim.MessageGUID != nil && *claim.MessageGUID != "" {
+			argv = append(argv, "--msg", *claim.MessageGUID)
+		}
+		if claim.ExpiresAt != nil {
+			minutes := (*claim.ExpiresAt - now + 59) / 60
+			argv = append(argv, "--ttl", fmt.Sprintf("%dm", minutes))
+		}
+		inverse = append(inverse, argv)
+	}
+	return inverse
+}
+
+// recordThreadStatusUndo records the inverse of an archive or restore.
+func recordThreadStatusUndo(cmd *cobra.Command, ctx *CommandContext, before *types.Thread, after types.ThreadStatus) error {
+	if before.Status == after {
+		return nil
+	}
+	switch after {
+	case types.ThreadStatusArchived:
+		return recordUndo(cmd, ctx, "archive", fmt.Sprintf("restore %s", before.Name),
+			[][]string{{"restore", before.GUID}})
+	case types.ThreadStatusOpen:
+		return recordUndo(cmd, ctx, "restore", fmt.Sprintf("archive %s", before.Name),
+			[][]string{{"archive", before.GUID}})
+	}
+	return nil
+}
+
+// NewUndoCmd creates the undo command.
+func NewUndoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Undo the most recent reversible operation",
+		Long: `Undo the most recent reversible operation.
+
+Reversible commands (clear, status --clear, mv, pin, unpin, archive, restore,
+react) record their inverse in .fray/local/undo.jsonl. fray undo shows what
+it will run and asks for confirmation (skip with --force). Each entry is
+undone at most once, newest first.
+
+prune is not reversible: running it discards all earlier undo entries.
+
+Examples:
+  fray undo --list     # Show the last 10 undoable operations
+  fray undo            # Undo the most recent one (asks first)
+  fray undo --force    # Undo without confirmation`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			pending, err := db.ReadPendingUndo(ctx.Project.DBPath)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			list, _ := cmd.Flags().GetBool("list")
+			if list {
+				if len(pending) > undoListLimit {
+					pending = pending[:undoListLimit]
+				}
+				if ctx.JSONMode {
+					return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{"entries": pending})
+				}
+				out := cmd.OutOrStdout()
+				if len(pending) == 0 {
+					fmt.Fprintln(out, "Nothing to undo")
+					return nil
+				}
+				for i, entry := range pending {
+					when := time.Unix(entry.RecordedAt, 0).Format("2006-01-02 15:04")
+					fmt.Fprintf(out, "%2d. %s  %-8s %s\n", i+1, when, entry.Command, entry.Description)
+				}
+				return nil
+			}
+
+			if len(pending) == 0 {
+				return writeCommandError(cmd, fmt.Errorf("nothing to undo"))
+			}
+			entry := pending[0]
+
+			if !ctx.Force {
+				if ctx.JSONMode {
+					return writeCommandError(cmd, fmt.Errorf("undo %s requires --force in JSON mode", entry.Command))
+				}
+				out := cmd.OutOrStdout()
+				fmt.Fprintf(out, "Undo %s: %s\n", entry.Command, entry.Description)
+				for _, argv := range entry.Inverse {
+					fmt.Fprintf(out, "  fray %s\n", strings.Join(argv, " "))
+				}
+				confirmed, err := confirmPrompt(cmd.InOrStdin(), out, "Apply? [y/N] ")
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if !confirmed {
+					fmt.Fprintln(out, "Cancelled")
+					return nil
+				}
+			}
+
+			for _, argv := range entry.Inverse {
+				if err := replayUndoCommand(cmd, ctx, argv); err != nil {
+					return writeCommandError(cmd, fmt.Errorf("undo %s failed at 'fray %s': %w", entry.Command, strings.Join(argv, " "), err))
+				}
+			}
+
+			if err := db.AppendUndoApplied(ctx.Project.DBPath, entry.ID, time.Now().Unix()); err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"undone":      entry.ID,
+					"command":     entry.Command,
+					"description": entry.Description,
+				})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Undid %s: %s\n", entry.Command, entry.Description)
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("list", false, "show the last 10 undoable operations")
+
+	return cmd
+}
+
+// replayUndoCommand runs an inverse argv through a fresh root command pinned to
+// the current project.
+func replayUndoCommand(cmd *cobra.Command, ctx *CommandContext, argv []string) error {
+	root := NewRootCmd(cmd.Root().Version)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetIn(cmd.InOrStdin())
+
+	replayArgs := append(append([]string{}, argv...), "--project", ctx.Project.Root)
+	root.SetArgs(replayArgs)
+
+	parent := cmd.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	if err := root.ExecuteContext(context.WithValue(parent, undoReplayKey{}, true)); err != nil {
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	return nil
+}
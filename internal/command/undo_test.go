@@ -0,0 +1,205 @@
+package command
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestUndoMoveRestoresHomeOnce(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "analysis")
+	runFray(t, "post", "--as", "alice", "this belongs in the room")
+
+	dbConn := openProjectDB(t, projectDir)
+	msgID := findRoomMessageByBody(t, dbConn, "this belongs in the room")
+	_ = dbConn.Close()
+
+	runFray(t, "mv", msgID, "analysis")
+	runFray(t, "undo", "--force")
+
+	dbConn = openProjectDB(t, projectDir)
+	msg, err := db.GetMessage(dbConn, msgID)
+	_ = dbConn.Close()
+	if err != nil {
+		t.Fatalf("get message: %v", err)
+	}
+	if msg.Home != "room" {
+		t.Fatalf("expected message back in room, got %s", msg.Home)
+	}
+
+	// The replayed mv must not itself become undoable.
+	if _, err := executeCommand(NewRootCmd("test"), "undo", "--force"); err == nil {
+		t.Fatalf("expected second undo to have nothing to do")
+	}
+}
+
+func TestUndoPinUnpinOrdering(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "design")
+	runFray(t, "post", "design", "--as", "alice", "design summary")
+
+	dbConn := openProjectDB(t, projectDir)
+	design, err := db.GetThreadByName(dbConn, "design", nil)
+	if err != nil || design == nil {
+		t.Fatalf("get thread: %v", err)
+	}
+	msgs, err := db.GetMessages(dbConn, &types.MessageQueryOptions{Home: &design.GUID})
+	if err != nil || len(msgs) == 0 {
+		t.Fatalf("get thread messages: %v", err)
+	}
+	msgID := msgs[len(msgs)-1].ID
+	_ = dbConn.Close()
+
+	pinned := func() bool {
+		conn := openProjectDB(t, projectDir)
+		defer conn.Close()
+		ok, err := isMessagePinnedIn(conn, msgID, design.GUID)
+		if err != nil {
+			t.Fatalf("pin lookup: %v", err)
+		}
+		return ok
+	}
+
+	runFray(t, "pin", msgID)
+	runFray(t, "unpin", msgID)
+
+	output := runFray(t, "undo", "--list")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "unpin") || !strings.Contains(lines[1], "pin") {
+		t.Fatalf("expected unpin then pin, got %q", output)
+	}
+
+	runFray(t, "undo", "--force")
+	if !pinned() {
+		t.Fatalf("expected undoing unpin to re-pin")
+	}
+	runFray(t, "undo", "--force")
+	if pinned() {
+		t.Fatalf("expected undoing pin to unpin")
+	}
+}
+
+func TestUndoArchive(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "old-ideas")
+	runFray(t, "archive", "old-ideas")
+	runFray(t, "undo", "--force")
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	thread, err := db.GetThreadByName(dbConn, "old-ideas", nil)
+	if err != nil || thread == nil {
+		t.Fatalf("get thread: %v", err)
+	}
+	if thread.Status != types.ThreadStatusOpen {
+		t.Fatalf("expected thread restored, got %s", thread.Status)
+	}
+}
+
+func TestUndoClearAndStatusClearRestoreClaims(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	msgID := postJSON(t, "post", "--as", "alice", "taking the auth refactor")["id"].(string)
+	runFray(t, "claim", "alice", "--file", "src/auth.go", "--note", "refactor", "--msg", msgID, "--ttl", "2h")
+	runFray(t, "clear", "alice")
+	runFray(t, "undo", "--force")
+
+	dbConn := openProjectDB(t, projectDir)
+	claim, err := db.GetClaim(dbConn, types.ClaimTypeFile, "src/auth.go")
+	_ = dbConn.Close()
+	if err != nil || claim == nil {
+		t.Fatalf("expected claim restored: %v", err)
+	}
+	if claim.AgentID != "alice" || claim.Reason == nil || *claim.Reason != "refactor" || claim.ExpiresAt == nil {
+		t.Fatalf("expected claim details restored, got %+v", claim)
+	}
+	if claim.MessageGUID == nil || *claim.MessageGUID != msgID {
+		t.Fatalf("expected the claim's message link restored, got %v", claim.MessageGUID)
+	}
+
+	runFray(t, "status", "alice", "working on auth")
+	runFray(t, "status", "alice", "--clear")
+	runFray(t, "undo", "--force")
+
+	dbConn = openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	agent, err := db.GetAgent(dbConn, "alice")
+	if err != nil || agent == nil {
+		t.Fatalf("get agent: %v", err)
+	}
+	if agent.Status == nil || *agent.Status != "working on auth" {
+		t.Fatalf("expected status restored, got %v", agent.Status)
+	}
+	claim, err = db.GetClaim(dbConn, types.ClaimTypeFile, "src/auth.go")
+	if err != nil || claim == nil {
+		t.Fatalf("expected claim restored after status --clear: %v", err)
+	}
+}
+
+func TestUndoReactRemovesReaction(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "post", "--as", "alice", "ship it?")
+
+	dbConn := openProjectDB(t, projectDir)
+	msgID := findRoomMessageByBody(t, dbConn, "ship it?")
+	_ = dbConn.Close()
+
+	runFray(t, "react", "🚀", msgID, "--as", "alice")
+	runFray(t, "undo", "--force")
+
+	dbConn = openProjectDB(t, projectDir)
+	reactions, err := db.GetReactionsForMessage(dbConn, msgID)
+	_ = dbConn.Close()
+	if err != nil {
+		t.Fatalf("get reactions: %v", err)
+	}
+	if len(reactions) != 0 {
+		t.Fatalf("expected reaction removed, got %v", reactions)
+	}
+
+	// Removal survives a rebuild from JSONL.
+	dbConn = openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	if err := db.RebuildDatabaseFromJSONL(dbConn, projectDir); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	reactions, err = db.GetReactionsForMessage(dbConn, msgID)
+	if err != nil {
+		t.Fatalf("get reactions: %v", err)
+	}
+	if len(reactions) != 0 {
+		t.Fatalf("expected reaction to stay removed after rebuild, got %v", reactions)
+	}
+}
+
+func TestUndoAsksForConfirmation(t *testing.T) {
+	projectDir := newFlowProject(t, "alice")
+	runFray(t, "thread", "old-ideas")
+	runFray(t, "archive", "old-ideas")
+
+	cmd := NewRootCmd("test")
+	cmd.SetIn(strings.NewReader("n\n"))
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs([]string{"undo"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if !strings.Contains(buf.String(), "fray restore") || !strings.Contains(buf.String(), "Cancelled") {
+		t.Fatalf("expected preview and cancellation, got %q", buf.String())
+	}
+
+	dbConn := openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	thread, err := db.GetThreadByName(dbConn, "old-ideas", nil)
+	if err != nil || thread == nil {
+		t.Fatalf("get thread: %v", err)
+	}
+	if thread.Status != types.ThreadStatusArchived {
+		t.Fatalf("expected declined undo to leave thread archived, got %s", thread.Status)
+	}
+}
@@ -0,0 +1,171 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// modelRatePrefix starts per-model rate config keys (model_rate.<model>).
+const modelRatePrefix = "model_rate."
+
+// NewUsageCmd creates the usage command.
+func NewUsageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Token usage per agent per day, with estimated cost",
+		Long: `Summarize the token usage the daemon recorded at the end of each managed
+session, per agent per day.
+
+Cost is estimated from per-model rates in USD per million input and output
+tokens, optionally followed by prompt-cache read and write rates. Sessions
+whose model has no rate (and no model_rate.default) are counted but not
+priced; cache tokens are left unpriced when the rate has no cache prices.
+
+Examples:
+  fray config model_rate.claude-sonnet-4-5 3,15,0.3,3.75
+  fray usage
+  fray usage --agent @dev --since 30d --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			since, _ := cmd.Flags().GetString("since")
+			agentRef, _ := cmd.Flags().GetString("agent")
+
+			sinceTS, err := core.ParseTimeBound(ctx.DB, since)
+			if err != nil {
+				return writeCommandError(cmd, validationError("--since: %v", err))
+			}
+			agentID := ""
+			if agentRef != "" {
+				if agentID, err = resolveAgentRef(ctx, agentRef); err != nil {
+					return writeCommandError(cmd, err)
+				}
+			}
+
+			events, err := db.ReadTokenUsage(ctx.Project.DBPath)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			var usage []types.TokenUsage
+			for _, event := range events {
+				if event.RecordedAt < sinceTS || (agentID != "" && event.AgentID != agentID) {
+					continue
+				}
+				usage = append(usage, event)
+			}
+
+			rates, err := modelRates(ctx, usage)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			rows := core.SummarizeUsage(usage, rates, time.Local)
+			total := core.TotalUsage(rows)
+
+			if ctx.JSONMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+					"since": sinceTS,
+					"rates": rates,
+					"rows":  rows,
+					"total": total,
+				})
+			}
+			printUsage(cmd.OutOrStdout(), sinceTS, rows, total)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("since", "7d", "start of the window (24h, 7d, RFC3339, or a message GUID)")
+	cmd.Flags().String("agent", "", "only this agent's sessions")
+	return cmd
+}
+
+// modelRateKey is the config key holding model's rate. Config keys are
+// normalized, so hyphens in model names become underscores.
+func modelRateKey(model string) string {
+	return normalizeConfigKey(modelRatePrefix + model)
+}
+
+// modelRates looks up the configured rate of every model in usage, falling
+// back to model_rate.default.
+func modelRates(ctx *CommandContext, usage []types.TokenUsage) (map[string]core.ModelRate, error) {
+	fallback, err := getConfigValue(ctx, modelRateKey("default"))
+	if err != nil {
+		return nil, err
+	}
+	rates := map[string]core.ModelRate{}
+	for _, entry := range usage {
+		if _, ok := rates[entry.Model]; ok {
+			continue
+		}
+		value, err := getConfigValue(ctx, modelRateKey(entry.Model))
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			value = fallback
+		}
+		if value == "" {
+			continue
+		}
+		rate, err := core.ParseModelRate(value)
+		if err != nil {
+			return nil, validationError("%s: %v", modelRateKey(entry.Model), err)
+		}
+		rates[entry.Model] = rate
+	}
+	return rates, nil
+}
+
+func printUsage(out io.Writer, sinceTS int64, rows []core.UsageRow, total core.UsageRow) {
+	sinceDate := time.Unix(sinceTS, 0).Format("2006-01-02")
+	if len(rows) == 0 {
+		fmt.Fprintf(out, "No token usage recorded since %s\n", sinceDate)
+		return
+	}
+	fmt.Fprintf(out, "Token usage since %s\n\n", sinceDate)
+	for _, row := range rows {
+		fmt.Fprintf(out, "%s  @%-12s %s\n", row.Date, row.AgentID, usageSummary(row))
+	}
+	fmt.Fprintf(out, "\nTotal%s%s\n", strings.Repeat(" ", 21), usageSummary(total))
+}
+
+func usageSummary(row core.UsageRow) string {
+	summary := fmt.Sprintf("%d session(s)  in %s", row.Sessions, formatTokens(row.InputTokens))
+	if row.CacheTokens > 0 {
+		summary += fmt.Sprintf("  cache %s", formatTokens(row.CacheTokens))
+	}
+	summary += fmt.Sprintf("  out %s  ~$%.2f", formatTokens(row.OutputTokens), row.Cost)
+	if row.Unpriced > 0 {
+		summary += fmt.Sprintf(" (%d unpriced)", row.Unpriced)
+	}
+	if row.UnpricedCache > 0 {
+		summary += fmt.Sprintf(" (%s cache tokens unpriced)", formatTokens(row.UnpricedCache))
+	}
+	return summary
+}
+
+// formatTokens renders a token count with thousands separators.
+func formatTokens(n int64) string {
+	digits := fmt.Sprintf("%d", n)
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
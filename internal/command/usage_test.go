@@ -0,0 +1,63 @@
+package command
+
+import (
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestUsageCommand(t *testing.T) {
+	projectDir := newFlowProject(t, "dev", "pm")
+	frayDir := filepath.Join(projectDir, ".fray")
+	now := time.Now().Unix()
+	for _, usage := range []types.TokenUsage{
+		{AgentID: "dev", SessionID: "s1", Model: "claude-sonnet", InputTokens: 1_000_000, CacheReadTokens: 2_000_000, OutputTokens: 100_000, RecordedAt: now},
+		{AgentID: "pm", SessionID: "s2", Model: "codex", InputTokens: 2_000, OutputTokens: 1_000, RecordedAt: now},
+		{AgentID: "dev", SessionID: "s0", Model: "claude-sonnet", InputTokens: 9, OutputTokens: 9, RecordedAt: now - 30*86400},
+	} {
+		if err := db.AppendTokenUsage(frayDir, usage); err != nil {
+			t.Fatalf("append token usage: %v", err)
+		}
+	}
+
+	runFray(t, "config", "model_rate.claude-sonnet", "3,15")
+	if _, err := executeCommand(NewRootCmd("test"), "config", "model_rate.opus", "cheap"); err == nil {
+		t.Fatal("expected an invalid model rate to be rejected")
+	}
+
+	var payload struct {
+		Rows  []core.UsageRow `json:"rows"`
+		Total core.UsageRow   `json:"total"`
+	}
+	output := runFray(t, "--json", "usage", "--agent", "@dev")
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode usage: %v (%q)", err, output)
+	}
+	if len(payload.Rows) != 1 || payload.Rows[0].InputTokens != 1_000_000 || payload.Rows[0].Cost != 4.5 {
+		t.Fatalf("expected dev's recent session priced at $4.50, got %+v", payload.Rows)
+	}
+
+	text := runFray(t, "usage")
+	if !strings.Contains(text, "@dev") || !strings.Contains(text, "in 1,000,000") || !strings.Contains(text, "(1 unpriced)") ||
+		!strings.Contains(text, "(2,000,000 cache tokens unpriced)") {
+		t.Fatalf("unexpected usage text: %q", text)
+	}
+
+	// 1M*3 + 2M*0.3 + 0.1M*15 = 5.1
+	runFray(t, "config", "model_rate.claude-sonnet", "3,15,0.3,3.75")
+	output = runFray(t, "--json", "usage", "--agent", "@dev")
+	payload.Rows = nil
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("decode usage: %v (%q)", err, output)
+	}
+	if len(payload.Rows) != 1 || math.Abs(payload.Rows[0].Cost-5.1) > 1e-9 || payload.Rows[0].UnpricedCache != 0 {
+		t.Fatalf("expected cache reads priced at the cache rate, got %+v", payload.Rows)
+	}
+}
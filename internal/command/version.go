@@ -0,0 +1,159 @@
+package command
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// Commit and BuildDate are overwritten at build time using -ldflags. When
+// unset they fall back to the VCS stamp in the binary's build info.
+var (
+	Commit    = ""
+	BuildDate = ""
+)
+
+const sqliteDriverModule = "modernc.org/sqlite"
+
+// VersionInfo describes the running binary and, inside a project, where its
+// store lives.
+type VersionInfo struct {
+	Version       string              `json:"version"`
+	Commit        string              `json:"commit,omitempty"`
+	BuildDate     string              `json:"build_date,omitempty"`
+	Modified      bool                `json:"modified,omitempty"`
+	GoVersion     string              `json:"go_version"`
+	Platform      string              `json:"platform"`
+	CGO           bool                `json:"cgo"`
+	SQLiteDriver  string              `json:"sqlite_driver"`
+	SQLiteVersion string              `json:"sqlite_version,omitempty"`
+	Project       *VersionProjectInfo `json:"project,omitempty"`
+}
+
+// VersionProjectInfo is the project part of VersionInfo.
+type VersionProjectInfo struct {
+	Root   string `json:"root"`
+	DBPath string `json:"db_path"`
+	// SchemaVersion is the database's recorded schema version, omitted when
+	// there is no database yet; CurrentSchema is what this binary writes.
+	SchemaVersion *int `json:"schema_version,omitempty"`
+	CurrentSchema int  `json:"current_schema"`
+}
+
+// NewVersionCmd creates the version command.
+func NewVersionCmd(version string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show version and build information",
+		Long: `Show the fray version, build metadata, and the SQLite driver in use.
+
+Run inside a project (or with --project / FRAY_PROJECT_ROOT) to also report
+the project root, database path and schema version. Works outside a project.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonMode, _ := cmd.Flags().GetBool("json")
+			projectFlag, _ := cmd.Flags().GetString("project")
+			info := collectVersionInfo(version, projectFlag)
+
+			if jsonMode {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(info)
+			}
+			printVersionInfo(cmd.OutOrStdout(), info)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func collectVersionInfo(version, projectFlag string) VersionInfo {
+	info := VersionInfo{
+		Version:      version,
+		Commit:       Commit,
+		BuildDate:    BuildDate,
+		GoVersion:    runtime.Version(),
+		Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+		SQLiteDriver: sqliteDriverModule,
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			case "CGO_ENABLED":
+				info.CGO = setting.Value == "1"
+			}
+		}
+		for _, dep := range build.Deps {
+			if dep.Path == sqliteDriverModule {
+				info.SQLiteDriver = dep.Path + " " + dep.Version
+			}
+		}
+	}
+
+	// The library version comes from a throwaway in-memory database, so a
+	// version query never creates, migrates or rebuilds the project store.
+	if mem, err := sql.Open("sqlite", ":memory:"); err == nil {
+		var sqliteVersion string
+		if err := mem.QueryRow("SELECT sqlite_version()").Scan(&sqliteVersion); err == nil {
+			info.SQLiteVersion = sqliteVersion
+		}
+		mem.Close()
+	}
+
+	project, err := projectForFlag(projectFlag)
+	if err != nil {
+		return info
+	}
+	info.Project = &VersionProjectInfo{Root: project.Root, DBPath: project.DBPath, CurrentSchema: db.SchemaVersion}
+	if schema, err := db.ReadSchemaVersion(project.DBPath); err == nil {
+		info.Project.SchemaVersion = &schema
+	}
+	return info
+}
+
+func printVersionInfo(out io.Writer, info VersionInfo) {
+	fmt.Fprintf(out, "%s version %s\n", AppName, info.Version)
+	if info.Commit != "" {
+		commit := info.Commit
+		if info.Modified {
+			commit += " (modified)"
+		}
+		fmt.Fprintf(out, "  commit:  %s\n", commit)
+	}
+	if info.BuildDate != "" {
+		fmt.Fprintf(out, "  built:   %s\n", info.BuildDate)
+	}
+	fmt.Fprintf(out, "  go:      %s %s (cgo %v)\n", info.GoVersion, info.Platform, info.CGO)
+	sqlite := info.SQLiteDriver
+	if info.SQLiteVersion != "" {
+		sqlite += " (sqlite " + info.SQLiteVersion + ")"
+	}
+	fmt.Fprintf(out, "  sqlite:  %s\n", sqlite)
+	if info.Project == nil {
+		fmt.Fprintln(out, "  project: none")
+		return
+	}
+	fmt.Fprintf(out, "  project: %s\n", info.Project.Root)
+	fmt.Fprintf(out, "  db:      %s\n", info.Project.DBPath)
+	if info.Project.SchemaVersion == nil {
+		fmt.Fprintf(out, "  schema:  none (current %d)\n", info.Project.CurrentSchema)
+		return
+	}
+	fmt.Fprintf(out, "  schema:  %d (current %d)\n", *info.Project.SchemaVersion, info.Project.CurrentSchema)
+}
@@ -1,34 +1,23 @@
 package command
 
 import (
-	"os"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
 	"github.com/adamavenir/fray/internal/types"
 )
 
 func TestGetMessageShowsFullMessage(t *testing.T) {
-	projectDir := t.TempDir()
-	project, err := core.InitProject(projectDir, false)
-	if err != nil {
-		t.Fatalf("init project: %v", err)
-	}
+	p := testutil.NewProject(t)
+	dbConn := p.OpenDB(t, db.OpenDatabase, db.InitSchema)
 
-	dbConn, err := db.OpenDatabase(project)
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-	if err := db.InitSchema(dbConn); err != nil {
-		_ = dbConn.Close()
-		t.Fatalf("init schema: %v", err)
-	}
-	_ = dbConn.Close()
-
-	if _, err := db.UpdateProjectConfig(project.DBPath, db.ProjectConfig{
+	if _, err := db.UpdateProjectConfig(p.Project.DBPath, db.ProjectConfig{
 		Version:     1,
 		ChannelID:   "ch-test",
 		ChannelName: "test",
@@ -37,46 +26,21 @@ func TestGetMessageShowsFullMessage(t *testing.T) {
 		t.Fatalf("update config: %v", err)
 	}
 
-	dbConn, err = db.OpenDatabase(project)
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-
-	now := time.Now().Unix()
-	if err := db.CreateAgent(dbConn, types.Agent{
-		GUID:         "usr-1234",
-		AgentID:      "alice",
-		RegisteredAt: now,
-		LastSeen:     now,
-	}); err != nil {
-		_ = dbConn.Close()
+	agent := testutil.NewAgent("alice")
+	agent.GUID = "usr-1234"
+	if err := db.CreateAgent(dbConn, agent); err != nil {
 		t.Fatalf("create agent: %v", err)
 	}
 
 	body := "line one\nline two\nline three"
-	posted, err := db.CreateMessage(dbConn, types.Message{
-		TS:        now,
-		FromAgent: "alice",
-		Body:      body,
-		Mentions:  []string{},
-		Type:      types.MessageTypeAgent,
-	})
+	msg := testutil.NewMessage("alice", body, types.MessageTypeAgent)
+	msg.Mentions = []string{}
+	posted, err := db.CreateMessage(dbConn, msg)
 	if err != nil {
-		_ = dbConn.Close()
 		t.Fatalf("create message: %v", err)
 	}
-	_ = dbConn.Close()
 
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("getwd: %v", err)
-	}
-	if err := os.Chdir(projectDir); err != nil {
-		t.Fatalf("chdir: %v", err)
-	}
-	t.Cleanup(func() {
-		_ = os.Chdir(cwd)
-	})
+	testutil.Chdir(t, p.Root)
 
 	cmd := NewRootCmd("test")
 	output, err := executeCommand(cmd, "get", posted.ID)
@@ -91,3 +55,180 @@ func TestGetMessageShowsFullMessage(t *testing.T) {
 		t.Fatalf("expected full body in output, got %q", output)
 	}
 }
+
+func TestGetWithParentsIncludesOutOfWindowParentsOnce(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+
+	// Explicit timestamps keep the page boundary deterministic.
+	dbConn := openProjectDB(t, projectDir)
+	dbPath := filepath.Join(projectDir, ".fray", "fray.db")
+	base := time.Now().Unix() + 10
+	post := func(offset int64, from, body string, replyTo *string) types.Message {
+		msg, err := db.CreateMessage(dbConn, types.Message{
+			TS:        base + offset,
+			FromAgent: from,
+			Body:      body,
+			Mentions:  []string{},
+			ReplyTo:   replyTo,
+		})
+		if err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+		if err := db.AppendMessage(dbPath, msg); err != nil {
+			t.Fatalf("append message: %v", err)
+		}
+		return msg
+	}
+	parent := post(0, "alice", "proposal: move auth to middleware", nil)
+	for i := int64(1); i <= 4; i++ {
+		post(i, "alice", fmt.Sprintf("unrelated chatter number %d", i), nil)
+	}
+	post(5, "bob", "good point, the middleware approach is cleaner", &parent.ID)
+	last := post(6, "alice", "agreed, and it lets us drop the per-route checks", &parent.ID)
+	_ = dbConn.Close()
+
+	output := runFray(t, "get", "--last", "3", "--with-parents", "--json")
+	var page struct {
+		Messages []types.Message `json:"messages"`
+		Parents  []types.Message `json:"parents"`
+	}
+	if err := json.Unmarshal([]byte(output), &page); err != nil {
+		t.Fatalf("decode: %v\n%s", err, output)
+	}
+	if len(page.Messages) != 3 {
+		t.Fatalf("expected 3 page messages, got %d", len(page.Messages))
+	}
+	if len(page.Parents) != 1 || page.Parents[0].ID != parent.ID {
+		t.Fatalf("expected parent included exactly once, got %v", page.Parents)
+	}
+
+	text := runFray(t, "get", "--last", "3", "--with-parents")
+	if strings.Count(text, "↳ in reply to @alice") != 1 {
+		t.Fatalf("expected one context line for the parent, got %q", text)
+	}
+
+	// The read cursor only covers the main page, not the context parents.
+	runFray(t, "get", "bob", "--last", "2", "--with-parents")
+	dbConn = openProjectDB(t, projectDir)
+	defer dbConn.Close()
+	readTo, err := db.GetReadTo(dbConn, "bob", "room")
+	if err != nil || readTo == nil {
+		t.Fatalf("expected room read cursor: %v", err)
+	}
+	if readTo.MessageGUID != last.ID {
+		t.Fatalf("expected cursor at last page message %s, got %s", last.ID, readTo.MessageGUID)
+	}
+}
+
+func TestGetByAndNotByFilters(t *testing.T) {
+	newFlowProject(t, "alice", "bob", "bot")
+	runFray(t, "thread", "review")
+	for _, author := range []string{"alice", "bob", "bot"} {
+		runFray(t, "post", "review", "--as", author, "note from "+author)
+		runFray(t, "post", "--as", author, "room note from "+author)
+	}
+
+	output := runFray(t, "get", "review", "--by", "@alice", "--by", "bob", "--not-by", "bob")
+	if !strings.Contains(output, "note from alice") || strings.Contains(output, "note from bob") || strings.Contains(output, "note from bot") {
+		t.Fatalf("expected only alice in thread, got %q", output)
+	}
+
+	output = runFray(t, "get", "--last", "20", "--not-by", "bot")
+	if strings.Contains(output, "room note from bot") || !strings.Contains(output, "room note from bob") {
+		t.Fatalf("expected bot excluded from room query, got %q", output)
+	}
+}
+
+func TestGetThreadParticipationHeader(t *testing.T) {
+	newFlowProject(t, "alice", "bob")
+	runFray(t, "thread", "review")
+	runFray(t, "post", "review", "--as", "alice", "first pass")
+	runFray(t, "post", "review", "--as", "alice", "second pass")
+	runFray(t, "post", "review", "--as", "bob", "lgtm")
+
+	output := runFray(t, "get", "review")
+	if !strings.Contains(output, "@alice 2, @bob 1 · created ") || !strings.Contains(output, "subscriber(s) · 0 pin(s)") {
+		t.Fatalf("expected participation header, got %q", output)
+	}
+	if output := runFray(t, "get", "review", "--no-header"); strings.Contains(output, "@alice 2") {
+		t.Fatalf("expected --no-header to drop the header, got %q", output)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(runFray(t, "get", "review", "--json")), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, ok := payload["stats"]; ok {
+		t.Fatalf("expected no stats in JSON without --with-stats")
+	}
+	if err := json.Unmarshal([]byte(runFray(t, "get", "review", "--json", "--with-stats")), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	stats, _ := payload["stats"].(map[string]any)
+	if stats == nil || stats["messages"] != float64(3) {
+		t.Fatalf("expected nested stats, got %v", payload["stats"])
+	}
+}
+
+func TestReplyPreviewsBatchLookup(t *testing.T) {
+	projectDir := newFlowProject(t, "alice", "bob")
+
+	dbConn := openProjectDB(t, projectDir)
+	dbPath := filepath.Join(projectDir, ".fray", "fray.db")
+	base := time.Now().Unix() + 10
+	post := func(offset int64, from, body string, replyTo *string) types.Message {
+		msg, err := db.CreateMessage(dbConn, types.Message{
+			TS:        base + offset,
+			FromAgent: from,
+			Body:      body,
+			Mentions:  []string{},
+			ReplyTo:   replyTo,
+		})
+		if err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+		if err := db.AppendMessage(dbPath, msg); err != nil {
+			t.Fatalf("append message: %v", err)
+		}
+		return msg
+	}
+	outside := post(0, "alice", "proposal: move auth to middleware", nil)
+	post(1, "alice", "unrelated chatter", nil)
+	inPage := post(2, "bob", "question: keep the per-route checks?", nil)
+	post(3, "alice", "good point on middleware", &outside.ID)
+	post(4, "alice", "no, drop them", &inPage.ID)
+	gone := "msg-pruned01"
+	post(5, "bob", "replying to something old", &gone)
+
+	page, err := db.GetMessages(dbConn, &types.MessageQueryOptions{Limit: 4})
+	if err != nil {
+		t.Fatalf("get messages: %v", err)
+	}
+	previews, err := CollectReplyPreviews(dbConn, page)
+	_ = dbConn.Close()
+	if err != nil {
+		t.Fatalf("collect previews: %v", err)
+	}
+	if len(previews) != 3 {
+		t.Fatalf("expected one entry per distinct parent, got %d", len(previews))
+	}
+	if p := previews[outside.ID]; p == nil || p.Body != outside.Body {
+		t.Fatalf("expected off-page parent fetched, got %+v", p)
+	}
+	if p := previews[inPage.ID]; p == nil || p.ID != inPage.ID {
+		t.Fatalf("expected on-page parent reused, got %+v", p)
+	}
+	if p, ok := previews[gone]; !ok || p != nil {
+		t.Fatalf("expected missing parent recorded as unavailable, got %+v", p)
+	}
+
+	text := runFray(t, "get", "--last", "4")
+	for _, want := range []string{"↳ @alice: proposal: move auth to middleware", "↳ @bob: question: keep the per-route checks?", "↳ reply to msg-pruned01 (not available)"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in output, got %q", want, text)
+		}
+	}
+	if text := runFray(t, "get", "--last", "4", "--no-reply-preview"); strings.Contains(text, "↳") {
+		t.Fatalf("expected --no-reply-preview to hide previews, got %q", text)
+	}
+}
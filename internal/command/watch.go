@@ -21,6 +21,13 @@ func NewWatchCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "watch",
 		Short: "Stream messages in real-time",
+		Long: `Stream messages in real-time.
+
+Use --log-file to also keep a plaintext transcript (ANSI colors stripped, or
+JSONL with --json). The last written message is remembered in
+<log-file>.cursor, so a restarted watch first catches the log up on anything
+it missed without duplicating lines. --rotate-daily writes one dated file per
+day (room.log -> room-2026-01-02.log).`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, err := GetContext(cmd)
 			if err != nil {
@@ -31,6 +38,7 @@ func NewWatchCmd() *cobra.Command {
 			last, _ := cmd.Flags().GetInt("last")
 			includeArchived, _ := cmd.Flags().GetBool("archived")
 			asAgent, _ := cmd.Flags().GetString("as")
+			byAgents, notByAgents := authorFilterFlags(cmd, ctx)
 
 			// Resolve agent filter - use --as flag or fall back to FRAY_AGENT_ID env var
 			var filterAgent string
@@ -54,6 +62,55 @@ func NewWatchCmd() *cobra.Command {
 				}
 			}
 
+			logFile, _ := cmd.Flags().GetString("log-file")
+			rotateDaily, _ := cmd.Flags().GetBool("rotate-daily")
+			if rotateDaily && logFile == "" {
+				return writeCommandError(cmd, fmt.Errorf("--rotate-daily requires --log-file"))
+			}
+			var transcript *transcriptLog
+			logMessages := func(messages []types.Message, previews ReplyPreviews) error {
+				if transcript == nil {
+					return nil
+				}
+				for _, msg := range messages {
+					formatted := ""
+					if !ctx.JSONMode {
+						formatted = FormatMessageWithPreview(msg, projectName, agentBases, previews)
+					}
+					if err := transcript.Write(msg, formatted); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if logFile != "" {
+				transcript, err = openTranscriptLog(logFile, rotateDaily, ctx.JSONMode)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				defer transcript.Close()
+
+				// Catch the log up on anything posted while it was not running.
+				if logCursor := transcript.Cursor(); logCursor != nil {
+					missed, err := db.GetMessages(ctx.DB, &types.MessageQueryOptions{Since: logCursor, IncludeArchived: includeArchived})
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
+					missed, err = db.ApplyMessageEditCounts(ctx.Project.DBPath, missed)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
+					missed = filterWatchMessages(ctx.DB, missed, filterAgent, byAgents, notByAgents)
+					previews, err := replyPreviews(cmd, ctx, missed)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
+					if err := logMessages(missed, previews); err != nil {
+						return writeCommandError(cmd, err)
+					}
+				}
+			}
+
 			var cursor *types.MessageCursor
 			if last == 0 {
 				cursor, err = db.GetLastMessageCursor(ctx.DB)
@@ -68,7 +125,12 @@ func NewWatchCmd() *cobra.Command {
 					fmt.Fprintf(out, "--- %s (Ctrl+C to stop) ---\n", watchLabel)
 				}
 			} else {
-				recent, err := db.GetMessages(ctx.DB, &types.MessageQueryOptions{Limit: last, IncludeArchived: includeArchived})
+				recent, err := db.GetMessages(ctx.DB, &types.MessageQueryOptions{
+					Limit:             last,
+					IncludeArchived:   includeArchived,
+					FromAgents:        byAgents,
+					ExcludeFromAgents: notByAgents,
+				})
 				if err != nil {
 					return writeCommandError(cmd, err)
 				}
@@ -89,13 +151,17 @@ func NewWatchCmd() *cobra.Command {
 				}
 
 				if len(recent) > 0 {
+					previews, err := replyPreviews(cmd, ctx, recent)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
 					if ctx.JSONMode {
 						for _, msg := range recent {
 							_ = json.NewEncoder(out).Encode(msg)
 						}
 					} else {
 						for _, msg := range recent {
-							fmt.Fprintln(out, FormatMessage(msg, projectName, agentBases))
+							fmt.Fprintln(out, FormatMessageWithPreview(msg, projectName, agentBases, previews))
 						}
 						watchLabel := "watching"
 						if filterAgent != "" {
@@ -103,6 +169,9 @@ func NewWatchCmd() *cobra.Command {
 						}
 						fmt.Fprintf(out, "--- %s (Ctrl+C to stop) ---\n", watchLabel)
 					}
+					if err := logMessages(recent, previews); err != nil {
+						return writeCommandError(cmd, err)
+					}
 					lastMsg := recent[len(recent)-1]
 					cursor = &types.MessageCursor{GUID: lastMsg.ID, TS: lastMsg.TS}
 				} else if !ctx.JSONMode {
@@ -173,11 +242,24 @@ func NewWatchCmd() *cobra.Command {
 				defer heartbeatTicker.Stop()
 			}
 
+			// Working pings are shown as they start, switch target, or stop.
+			working := newWorkingTracker()
+			workingTTL := db.WorkingTTL(ctx.DB)
+
 			for {
 				select {
 				case <-stop:
 					return nil
 				case <-ticker.C:
+					if !ctx.JSONMode {
+						current, err := db.GetWorkingAgents(ctx.DB, workingTTL, time.Now().UnixMilli())
+						if err != nil {
+							return writeCommandError(cmd, err)
+						}
+						for _, line := range working.update(current) {
+							fmt.Fprintln(out, dim+line+reset)
+						}
+					}
 					newMessages, err := db.GetMessages(ctx.DB, &types.MessageQueryOptions{Since: cursor, IncludeArchived: includeArchived})
 					if err != nil {
 						return writeCommandError(cmd, err)
@@ -204,21 +286,18 @@ func NewWatchCmd() *cobra.Command {
 						}
 					}
 
-					// Filter to agent-relevant messages if --as is set
-					if filterAgent != "" {
-						filtered := make([]types.Message, 0, len(newMessages))
-						for _, msg := range newMessages {
-							if isMessageRelevantToAgent(ctx.DB, msg, filterAgent) {
-								filtered = append(filtered, msg)
-							}
-						}
-						newMessages = filtered
-					}
-
+					// Filters run after the heartbeat check so --not-by never hides
+					// the watching agent's own activity from the timer.
+					newMessages = filterWatchMessages(ctx.DB, newMessages, filterAgent, byAgents, notByAgents)
 					if len(newMessages) == 0 {
 						continue
 					}
 
+					// One parent lookup per poll tick covers every new reply.
+					previews, err := replyPreviews(cmd, ctx, newMessages)
+					if err != nil {
+						return writeCommandError(cmd, err)
+					}
 					if ctx.JSONMode {
 						encoder := json.NewEncoder(out)
 						for _, msg := range newMessages {
@@ -226,9 +305,12 @@ func NewWatchCmd() *cobra.Command {
 						}
 					} else {
 						for _, msg := range newMessages {
-							fmt.Fprintln(out, FormatMessage(msg, projectName, agentBases))
+							fmt.Fprintln(out, FormatMessageWithPreview(msg, projectName, agentBases, previews))
 						}
 					}
+					if err := logMessages(newMessages, previews); err != nil {
+						return writeCommandError(cmd, err)
+					}
 
 				case <-func() <-chan time.Time {
 					if heartbeatTicker != nil {
@@ -275,9 +357,33 @@ func NewWatchCmd() *cobra.Command {
 	cmd.Flags().Int("last", 10, "show last N messages before streaming")
 	cmd.Flags().Bool("archived", false, "include archived messages")
 	cmd.Flags().String("as", "", "filter to agent-relevant events (mentions, reactions, replies)")
+	cmd.Flags().StringArray("by", nil, "only messages by this agent or its subagents (repeatable)")
+	cmd.Flags().StringArray("not-by", nil, "drop messages by this agent or its subagents (repeatable, wins over --by)")
+	cmd.Flags().String("log-file", "", "also append messages to this transcript file")
+	cmd.Flags().Bool("rotate-daily", false, "write one dated transcript file per day (requires --log-file)")
+	cmd.Flags().Bool("no-reply-preview", false, "don't show a preview of the parent above replies")
 	return cmd
 }
 
+// filterWatchMessages applies the --as relevance filter and --by/--not-by
+// author filters.
+func filterWatchMessages(database *sql.DB, messages []types.Message, filterAgent string, byAgents, notByAgents []string) []types.Message {
+	if filterAgent == "" && len(byAgents) == 0 && len(notByAgents) == 0 {
+		return messages
+	}
+	filtered := make([]types.Message, 0, len(messages))
+	for _, msg := range messages {
+		if filterAgent != "" && !isMessageRelevantToAgent(database, msg, filterAgent) {
+			continue
+		}
+		if !db.MatchesAuthorFilter(msg.FromAgent, byAgents, notByAgents) {
+			continue
+		}
+		filtered = append(filtered, msg)
+	}
+	return filtered
+}
+
 // isMessageRelevantToAgent checks if a message is relevant to the specified agent.
 // Relevant = mentions agent, is a reply to agent's message, or is a reaction to agent's message.
 func isMessageRelevantToAgent(database *sql.DB, msg types.Message, agentPrefix string) bool {
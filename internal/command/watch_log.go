@@ -0,0 +1,146 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+var ansiEscapeRe = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)")
+
+// transcriptLog tees watched messages to a plaintext (or JSONL) file. The
+// cursor of the last written message is kept in <path>.cursor so a restarted
+// watch resumes without duplicating lines.
+type transcriptLog struct {
+	path   string
+	rotate bool
+	json   bool
+	file   *os.File
+	day    string
+	cursor *types.MessageCursor
+}
+
+// openTranscriptLog prepares a transcript at path and loads its cursor. The
+// log file itself is opened lazily on the first write.
+func openTranscriptLog(path string, rotate, jsonMode bool) (*transcriptLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	log := &transcriptLog{path: path, rotate: rotate, json: jsonMode}
+
+	data, err := os.ReadFile(log.cursorPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read log cursor: %w", err)
+	}
+	if len(data) > 0 {
+		var cursor types.MessageCursor
+		if err := json.Unmarshal(data, &cursor); err != nil {
+			return nil, fmt.Errorf("parse log cursor %s: %w", log.cursorPath(), err)
+		}
+		log.cursor = &cursor
+	}
+	return log, nil
+}
+
+// Cursor returns the last message written to the log, or nil for a new log.
+func (l *transcriptLog) Cursor() *types.MessageCursor {
+	return l.cursor
+}
+
+// Write appends msg to the log unless it was already written, then flushes
+// and advances the persisted cursor.
+func (l *transcriptLog) Write(msg types.Message, formatted string) error {
+	if !messageAfterCursor(msg, l.cursor) {
+		return nil
+	}
+
+	day := time.Unix(msg.TS, 0).Local().Format("2006-01-02")
+	if err := l.openFor(day); err != nil {
+		return err
+	}
+
+	var line string
+	if l.json {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		line = string(data)
+	} else {
+		line = ansiEscapeRe.ReplaceAllString(formatted, "")
+	}
+	if _, err := fmt.Fprintln(l.file, line); err != nil {
+		return fmt.Errorf("write log: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("flush log: %w", err)
+	}
+
+	l.cursor = &types.MessageCursor{GUID: msg.ID, TS: msg.TS}
+	data, err := json.Marshal(l.cursor)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(l.cursorPath(), data, 0o644); err != nil {
+		return fmt.Errorf("write log cursor: %w", err)
+	}
+	return nil
+}
+
+// Close closes the current log file.
+func (l *transcriptLog) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *transcriptLog) openFor(day string) error {
+	if l.file != nil && (!l.rotate || l.day == day) {
+		return nil
+	}
+	if err := l.Close(); err != nil {
+		return err
+	}
+	name := l.path
+	if l.rotate {
+		name = datedLogPath(l.path, day)
+	}
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log: %w", err)
+	}
+	l.file = file
+	l.day = day
+	return nil
+}
+
+func (l *transcriptLog) cursorPath() string {
+	return l.path + ".cursor"
+}
+
+// datedLogPath inserts day before the extension: room.log -> room-2026-01-02.log.
+func datedLogPath(path, day string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + day + ext
+}
+
+// messageAfterCursor reports whether msg sorts after cursor in message order
+// (ts, then guid). A nil cursor admits everything.
+func messageAfterCursor(msg types.Message, cursor *types.MessageCursor) bool {
+	if cursor == nil {
+		return true
+	}
+	if msg.TS != cursor.TS {
+		return msg.TS > cursor.TS
+	}
+	return msg.ID > cursor.GUID
+}
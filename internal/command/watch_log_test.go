@@ -0,0 +1,104 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestTranscriptLogResumesWithoutDuplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcripts", "room.log")
+	messages := []types.Message{
+		{ID: "msg-aaaa1111", TS: 100, FromAgent: "alice", Body: "one"},
+		{ID: "msg-bbbb2222", TS: 100, FromAgent: "bob", Body: "two"},
+		{ID: "msg-cccc3333", TS: 200, FromAgent: "alice", Body: "three"},
+	}
+
+	log, err := openTranscriptLog(path, false, false)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	for _, msg := range messages[:2] {
+		if err := log.Write(msg, "\x1b[1m"+msg.FromAgent+"\x1b[0m: "+msg.Body); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	resumed, err := openTranscriptLog(path, false, false)
+	if err != nil {
+		t.Fatalf("reopen log: %v", err)
+	}
+	if cursor := resumed.Cursor(); cursor == nil || cursor.GUID != "msg-bbbb2222" {
+		t.Fatalf("expected cursor at second message, got %+v", cursor)
+	}
+	// A restarted watch replays overlapping messages; only new ones land.
+	for _, msg := range messages {
+		if err := resumed.Write(msg, msg.FromAgent+": "+msg.Body); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	resumed.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	want := "alice: one\nbob: two\nalice: three\n"
+	if string(data) != want {
+		t.Fatalf("expected %q, got %q", want, string(data))
+	}
+}
+
+func TestTranscriptLogRotatesDaily(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "room.log")
+	day1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local).Unix()
+	day2 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local).Unix()
+
+	log, err := openTranscriptLog(path, true, true)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	defer log.Close()
+	for _, msg := range []types.Message{
+		{ID: "msg-aaaa1111", TS: day1, FromAgent: "alice", Body: "one"},
+		{ID: "msg-bbbb2222", TS: day2, FromAgent: "bob", Body: "two"},
+	} {
+		if err := log.Write(msg, ""); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	for day, body := range map[string]string{"2026-03-01": "one", "2026-03-02": "two"} {
+		data, err := os.ReadFile(filepath.Join(dir, "room-"+day+".log"))
+		if err != nil {
+			t.Fatalf("read %s log: %v", day, err)
+		}
+		if !strings.Contains(string(data), `"body":"`+body+`"`) || strings.Count(string(data), "\n") != 1 {
+			t.Fatalf("expected one JSONL record with %q in %s log, got %q", body, day, string(data))
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no undated log when rotating, got %v", err)
+	}
+}
+
+func TestDatedLogPath(t *testing.T) {
+	cases := map[string]string{
+		"transcripts/room.log": "transcripts/room-2026-01-02.log",
+		"room":                 "room-2026-01-02",
+		"logs/room.jsonl":      "logs/room-2026-01-02.jsonl",
+	}
+	for in, want := range cases {
+		if got := datedLogPath(in, "2026-01-02"); got != want {
+			t.Fatalf("datedLogPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -25,7 +25,7 @@ func NewWhoCmd() *cobra.Command {
 
 			if args[0] == "here" {
 				staleHours := 4
-				if value, err := db.GetConfig(ctx.DB, "stale_hours"); err == nil && value != "" {
+				if value, err := getConfigValue(ctx, "stale_hours"); err == nil && value != "" {
 					staleHours = parseInt(value, staleHours)
 				}
 				agents, err := db.GetActiveAgents(ctx.DB, staleHours)
@@ -59,7 +59,7 @@ func NewWhoCmd() *cobra.Command {
 				return json.NewEncoder(cmd.OutOrStdout()).Encode(toAgentDetails(*agent))
 			}
 			staleHours := 4
-			if value, err := db.GetConfig(ctx.DB, "stale_hours"); err == nil && value != "" {
+			if value, err := getConfigValue(ctx, "stale_hours"); err == nil && value != "" {
 				staleHours = parseInt(value, staleHours)
 			}
 			displayAgent(cmd.OutOrStdout(), *agent, staleHours)
@@ -0,0 +1,142 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// NewWorkingCmd creates the working command.
+func NewWorkingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "working",
+		Short: "Tell others you're mid-generation (typing indicator)",
+		Long: `Record that you're working, optionally on a specific message. fray here
+and fray watch show it ("@dev is working on #msg-abc…"), and the daemon
+queues new mentions instead of spawning another session while it's fresh.
+
+The ping expires after working_ttl_seconds (default 120) without a refresh,
+so run it again during long work. It is transient: kept in the local cache
+only, never in JSONL. The daemon sets the same ping for sessions it spawns.
+
+Examples:
+  fray working --as dev --on msg-abc123
+  fray working --as dev
+  fray working --as dev --clear
+  fray config working_ttl_seconds 300`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := GetContext(cmd)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			defer ctx.DB.Close()
+
+			asRef, _ := cmd.Flags().GetString("as")
+			if asRef == "" {
+				asRef = os.Getenv("FRAY_AGENT_ID")
+			}
+			if asRef == "" {
+				return writeCommandError(cmd, validationError("--as flag or FRAY_AGENT_ID env var required"))
+			}
+			agentID, err := resolveAgentRef(ctx, asRef)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+
+			out := cmd.OutOrStdout()
+			if clear, _ := cmd.Flags().GetBool("clear"); clear {
+				if err := db.ClearAgentWorking(ctx.DB, agentID); err != nil {
+					return writeCommandError(cmd, err)
+				}
+				if ctx.JSONMode {
+					return json.NewEncoder(out).Encode(map[string]any{"agent_id": agentID, "working": nil})
+				}
+				fmt.Fprintf(out, "@%s is no longer working\n", agentID)
+				return nil
+			}
+
+			var target *string
+			if on, _ := cmd.Flags().GetString("on"); on != "" {
+				msg, err := resolveMessageRef(ctx.DB, on)
+				if err != nil {
+					return writeCommandError(cmd, err)
+				}
+				target = &msg.ID
+			}
+
+			now := time.Now().UnixMilli()
+			if err := db.SetAgentWorking(ctx.DB, agentID, target, db.WorkingSourceAgent, now); err != nil {
+				return writeCommandError(cmd, err)
+			}
+			working, err := db.GetAgentWorking(ctx.DB, agentID, db.WorkingTTL(ctx.DB), now)
+			if err != nil {
+				return writeCommandError(cmd, err)
+			}
+			if ctx.JSONMode {
+				return json.NewEncoder(out).Encode(map[string]any{"agent_id": agentID, "working": working})
+			}
+			fmt.Fprintln(out, formatWorkingLine(*working))
+			return nil
+		},
+	}
+
+	cmd.Flags().String("as", "", "agent that is working (uses FRAY_AGENT_ID if not set)")
+	cmd.Flags().String("on", "", "message being worked on")
+	cmd.Flags().Bool("clear", false, "clear the working ping")
+	return cmd
+}
+
+// formatWorkingLine renders a working ping, e.g. "@dev is working on #msg-abc…".
+func formatWorkingLine(working types.AgentWorking) string {
+	if working.MessageGUID == nil {
+		return fmt.Sprintf("@%s is working…", working.AgentID)
+	}
+	return fmt.Sprintf("@%s is working on #%s…", working.AgentID, *working.MessageGUID)
+}
+
+// workingTracker turns successive polls of working pings into the lines fray
+// watch shows: one when an agent starts or switches target, one when it stops.
+type workingTracker struct {
+	seen map[string]string // agent_id -> target ("" for none)
+}
+
+func newWorkingTracker() *workingTracker {
+	return &workingTracker{seen: make(map[string]string)}
+}
+
+// update returns the lines to print for the current set of fresh pings.
+func (w *workingTracker) update(current []types.AgentWorking) []string {
+	var lines []string
+	live := make(map[string]bool, len(current))
+	for _, working := range current {
+		live[working.AgentID] = true
+		target := ""
+		if working.MessageGUID != nil {
+			target = *working.MessageGUID
+		}
+		if prev, ok := w.seen[working.AgentID]; ok && prev == target {
+			continue
+		}
+		w.seen[working.AgentID] = target
+		lines = append(lines, formatWorkingLine(working))
+	}
+	var stopped []string
+	for agentID := range w.seen {
+		if !live[agentID] {
+			stopped = append(stopped, agentID)
+		}
+	}
+	sort.Strings(stopped)
+	for _, agentID := range stopped {
+		delete(w.seen, agentID)
+		lines = append(lines, fmt.Sprintf("@%s stopped working", agentID))
+	}
+	return lines
+}
@@ -0,0 +1,66 @@
+package command
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestWorkingShowsInHereAndClears(t *testing.T) {
+	newFlowProject(t, "alice", "dev")
+	msg := postJSON(t, "post", "--as", "alice", "@dev can you look at the build?")
+	msgID := msg["id"].(string)
+
+	output := runFray(t, "working", "--as", "dev", "--on", msgID)
+	if !strings.Contains(output, "@dev is working on #"+msgID) {
+		t.Fatalf("unexpected working output %q", output)
+	}
+	if output := runFray(t, "here"); !strings.Contains(output, "@dev is working on #"+msgID) {
+		t.Fatalf("expected here to show the ping, got %q", output)
+	}
+
+	var payload struct {
+		Agents []struct {
+			DisplayName string              `json:"display_name"`
+			Working     *types.AgentWorking `json:"working"`
+		} `json:"agents"`
+	}
+	if err := json.Unmarshal([]byte(runFray(t, "here", "--json")), &payload); err != nil {
+		t.Fatalf("decode here: %v", err)
+	}
+	for _, agent := range payload.Agents {
+		if (agent.Working != nil) != (agent.DisplayName == "dev") {
+			t.Fatalf("expected only dev to be working, got %+v", payload.Agents)
+		}
+	}
+
+	runFray(t, "working", "--as", "dev", "--clear")
+	if output := runFray(t, "here"); strings.Contains(output, "is working") {
+		t.Fatalf("expected the ping cleared, got %q", output)
+	}
+}
+
+func TestWorkingTrackerLines(t *testing.T) {
+	target := "msg-abc"
+	other := "msg-def"
+	tracker := newWorkingTracker()
+
+	steps := []struct {
+		current []types.AgentWorking
+		want    []string
+	}{
+		{[]types.AgentWorking{{AgentID: "dev", MessageGUID: &target}}, []string{"@dev is working on #msg-abc…"}},
+		{[]types.AgentWorking{{AgentID: "dev", MessageGUID: &target}, {AgentID: "pm"}}, []string{"@pm is working…"}},
+		{[]types.AgentWorking{{AgentID: "dev", MessageGUID: &other}, {AgentID: "pm"}}, []string{"@dev is working on #msg-def…"}},
+		{nil, []string{"@dev stopped working", "@pm stopped working"}},
+		{nil, nil},
+	}
+	for i, step := range steps {
+		if got := tracker.update(step.current); !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("step %d: expected %q, got %q", i, step.want, got)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/adamavenir/fray/internal/types"
 )
@@ -98,6 +100,85 @@ func IsValidBaseName(base string) bool {
 	return true
 }
 
+// MaxAgentIDLength caps the length of a newly registered agent ID.
+const MaxAgentIDLength = 64
+
+// reservedAgentIDs are names fray itself uses in mentions and message
+// authors, so no agent may register them.
+var reservedAgentIDs = map[string]bool{"all": true, "here": true, "system": true, "room": true}
+
+// IsReservedAgentID reports whether id is reserved for fray's own use.
+func IsReservedAgentID(id string) bool {
+	return reservedAgentIDs[id]
+}
+
+// NormalizeAgentID turns a free-form name into the closest agent ID:
+// lowercased, underscores and spaces become hyphens, other invalid runes are
+// dropped, and separators are collapsed. A hyphenated part starting with a
+// digit joins the part before it ("agent_2" -> "agent2"). The result can
+// still be invalid, e.g. for a name with no letters.
+func NormalizeAgentID(name string) string {
+	var cleaned strings.Builder
+	for _, r := range strings.TrimSpace(NormalizeAgentRef(strings.TrimSpace(name))) {
+		r = unicode.ToLower(r)
+		switch {
+		case r == '_' || unicode.IsSpace(r):
+			cleaned.WriteRune('-')
+		case r == '-' || r == '.' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			cleaned.WriteRune(r)
+		}
+	}
+
+	var segments []string
+	for i, segment := range strings.Split(cleaned.String(), ".") {
+		if i > 0 && positiveInt.MatchString(segment) {
+			segments = append(segments, segment)
+			continue
+		}
+		var parts []string
+		for _, part := range strings.Split(segment, "-") {
+			switch {
+			case part == "":
+			case part[0] >= '0' && part[0] <= '9' && len(parts) > 0:
+				parts[len(parts)-1] += part
+			default:
+				if part = strings.TrimLeft(part, "0123456789"); part != "" {
+					parts = append(parts, part)
+				}
+			}
+		}
+		if len(parts) > 0 {
+			segments = append(segments, strings.Join(parts, "-"))
+		}
+	}
+
+	id := strings.Join(segments, ".")
+	if len(id) > MaxAgentIDLength {
+		id = strings.TrimRight(id[:MaxAgentIDLength], ".-")
+	}
+	return id
+}
+
+// ValidateNewAgentID checks a name an agent is about to register under. On
+// top of IsValidAgentID it enforces MaxAgentIDLength and the reserved names.
+// When the name is merely malformed, the error suggests its normalized form.
+func ValidateNewAgentID(id string) error {
+	if IsReservedAgentID(id) {
+		return fmt.Errorf("agent name %q is reserved (reserved: all, here, room, system)", id)
+	}
+	if len(id) > MaxAgentIDLength {
+		return fmt.Errorf("agent name is %d characters; the limit is %d", len(id), MaxAgentIDLength)
+	}
+	if IsValidAgentID(id) {
+		return nil
+	}
+	msg := fmt.Sprintf("invalid agent name: %s\nNames must start with a lowercase letter and contain only lowercase letters, numbers, and hyphens.\nExamples: alice, pm, eager-beaver, frontend-dev", id)
+	if suggestion := NormalizeAgentID(id); suggestion != id && IsValidAgentID(suggestion) && !IsReservedAgentID(suggestion) {
+		msg += fmt.Sprintf("\ndid you mean '%s'? (--normalize applies it)", suggestion)
+	}
+	return errors.New(msg)
+}
+
 // IsValidAgentBase is an alias for IsValidBaseName.
 func IsValidAgentBase(base string) bool {
 	return IsValidBaseName(base)
@@ -130,3 +211,23 @@ func parseNumeric(value string) int {
 	}
 	return result
 }
+
+// NextSubAgentID returns the lowest free sub-agent ID under parent
+// (parent.1, parent.2, ...), skipping IDs in taken.
+func NextSubAgentID(parent string, taken []string) (string, error) {
+	used := map[int]bool{}
+	for _, id := range taken {
+		if !strings.HasPrefix(id, parent+".") {
+			continue
+		}
+		suffix := id[len(parent)+1:]
+		if positiveInt.MatchString(suffix) {
+			used[parseNumeric(suffix)] = true
+		}
+	}
+	index := 1
+	for used[index] {
+		index++
+	}
+	return FormatAgentID(parent, index)
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNextSubAgentID(t *testing.T) {
+	cases := []struct {
+		name  string
+		taken []string
+		want  string
+	}{
+		{"first", nil, "alice.1"},
+		{"next", []string{"alice", "alice.1"}, "alice.2"},
+		{"fills gap", []string{"alice.1", "alice.3"}, "alice.2"},
+		{"ignores nested and other agents", []string{"alice.1.1", "alicex.1", "bob.1"}, "alice.1"},
+	}
+	for _, tc := range cases {
+		got, err := NextSubAgentID("alice", tc.taken)
+		if err != nil || got != tc.want {
+			t.Errorf("%s: got (%q, %v), want %q", tc.name, got, err, tc.want)
+		}
+	}
+}
+
+func TestNormalizeAgentID(t *testing.T) {
+	cases := map[string]string{
+		"alice":           "alice",
+		"@Alice":          "alice",
+		"Frontend_Dev":    "frontend-dev",
+		"eager  beaver":   "eager-beaver",
+		"agent_2":         "agent2",
+		"2fast":           "fast",
+		"pm.3":            "pm.3",
+		"Zoë":             "zo",
+		"bob!!":           "bob",
+		"__x__":           "x",
+		"a..b--c":         "a.b-c",
+		"İstanbul":        "istanbul",
+		"123":             "",
+		"Mixed.Case_Name": "mixed.case-name",
+	}
+	for in, want := range cases {
+		if got := NormalizeAgentID(in); got != want {
+			t.Errorf("NormalizeAgentID(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	long := NormalizeAgentID(strings.Repeat("ab-", 40))
+	if len(long) > MaxAgentIDLength || !IsValidAgentID(long) {
+		t.Errorf("expected a valid ID within %d chars, got %q", MaxAgentIDLength, long)
+	}
+}
+
+func TestValidateNewAgentID(t *testing.T) {
+	if err := ValidateNewAgentID("frontend-dev"); err != nil {
+		t.Fatalf("expected valid name, got %v", err)
+	}
+	for _, reserved := range []string{"all", "here", "system", "room"} {
+		if err := ValidateNewAgentID(reserved); err == nil || !strings.Contains(err.Error(), "reserved") {
+			t.Errorf("expected %q to be reserved, got %v", reserved, err)
+		}
+	}
+	if err := ValidateNewAgentID(strings.Repeat("a", MaxAgentIDLength+1)); err == nil {
+		t.Errorf("expected over-long name to be rejected")
+	}
+
+	err := ValidateNewAgentID("Frontend_Dev")
+	if err == nil || !strings.Contains(err.Error(), "did you mean 'frontend-dev'?") {
+		t.Fatalf("expected a normalization suggestion, got %v", err)
+	}
+	// No suggestion when the normalized form would be rejected too.
+	if err := ValidateNewAgentID("ALL"); err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Fatalf("expected no suggestion for a reserved normalization, got %v", err)
+	}
+}
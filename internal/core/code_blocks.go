@@ -0,0 +1,213 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CodeBlock is a fenced code block within a message body. StartLine and
+// EndLine index the body's lines and point at the opening and closing fence;
+// an unterminated block runs to the last line and has Closed false.
+type CodeBlock struct {
+	Language  string `json:"language,omitempty"`
+	Lines     int    `json:"lines"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Closed    bool   `json:"closed"`
+}
+
+// ParseCodeBlocks finds the fenced code blocks in body. Fences are three or
+// more backticks or tildes; a block closes on a bare fence of the same
+// character at least as long as the opener, so a ```` block can quote ```
+// fences without ending early.
+func ParseCodeBlocks(body string) []CodeBlock {
+	if !strings.Contains(body, "```") && !strings.Contains(body, "~~~") {
+		return nil
+	}
+	lines := strings.Split(body, "\n")
+	var blocks []CodeBlock
+	for i := 0; i < len(lines); i++ {
+		fence, lang, ok := ParseFence(lines[i])
+		if !ok {
+			continue
+		}
+		block := CodeBlock{Language: lang, StartLine: i, EndLine: len(lines) - 1}
+		for j := i + 1; j < len(lines); j++ {
+			if IsClosingFence(lines[j], fence) {
+				block.EndLine = j
+				block.Closed = true
+				break
+			}
+		}
+		block.Lines = block.EndLine - block.StartLine - 1
+		if !block.Closed {
+			block.Lines++
+		}
+		blocks = append(blocks, block)
+		i = block.EndLine
+	}
+	return blocks
+}
+
+// CodeSpans returns the byte ranges of body covered by closed fenced code
+// blocks and inline `code` spans, where @mentions and #refs are not parsed.
+func CodeSpans(body string) [][2]int {
+	var spans [][2]int
+	lines := strings.Split(body, "\n")
+	offsets := make([]int, len(lines)+1)
+	for i, line := range lines {
+		offsets[i+1] = offsets[i] + len(line) + 1
+	}
+	next := 0
+	addInline := func(from, to int) {
+		for _, span := range inlineCodeSpans(body[from:to]) {
+			spans = append(spans, [2]int{from + span[0], from + span[1]})
+		}
+	}
+	for _, block := range ParseCodeBlocks(body) {
+		if !block.Closed {
+			// An unterminated fence is likely a typo; keep parsing the rest.
+			break
+		}
+		start := offsets[block.StartLine]
+		end := min(offsets[block.EndLine+1], len(body))
+		addInline(next, start)
+		spans = append(spans, [2]int{start, end})
+		next = end
+	}
+	addInline(next, len(body))
+	return spans
+}
+
+// inlineCodeSpans finds `code` spans: a backtick run closed by a run of the
+// same length. Single-backtick spans stay on one line; longer runs, such as
+// ``` used inline, may span lines.
+func inlineCodeSpans(text string) [][2]int {
+	var spans [][2]int
+	runAt := func(i int) int {
+		n := 0
+		for i+n < len(text) && text[i+n] == '`' {
+			n++
+		}
+		return n
+	}
+	for i := 0; i < len(text); {
+		if text[i] != '`' {
+			i++
+			continue
+		}
+		n := runAt(i)
+		end := -1
+		for j := i + n; j < len(text); {
+			if text[j] == '\n' && n == 1 {
+				break
+			}
+			if text[j] != '`' {
+				j++
+				continue
+			}
+			m := runAt(j)
+			if m == n {
+				end = j + m
+				break
+			}
+			j += m
+		}
+		if end < 0 {
+			i += n
+			continue
+		}
+		spans = append(spans, [2]int{i, end})
+		i = end
+	}
+	return spans
+}
+
+// InCodeSpan reports whether pos falls inside one of spans.
+func InCodeSpan(spans [][2]int, pos int) bool {
+	for _, span := range spans {
+		if pos >= span[0] && pos < span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCodeBlock reports whether body contains at least one fenced code block.
+func HasCodeBlock(body string) bool {
+	return len(ParseCodeBlocks(body)) > 0
+}
+
+// CollapseCodeBlocks replaces each fenced code block in body with a
+// one-line placeholder such as "[code: go, 42 lines]".
+func CollapseCodeBlocks(body string) string {
+	blocks := ParseCodeBlocks(body)
+	if len(blocks) == 0 {
+		return body
+	}
+	lines := strings.Split(body, "\n")
+	out := make([]string, 0, len(lines))
+	next := 0
+	for _, block := range blocks {
+		out = append(out, lines[next:block.StartLine]...)
+		out = append(out, block.Placeholder())
+		next = block.EndLine + 1
+	}
+	out = append(out, lines[next:]...)
+	return strings.Join(out, "\n")
+}
+
+// Placeholder is the collapsed form of the block.
+func (b CodeBlock) Placeholder() string {
+	unit := "lines"
+	if b.Lines == 1 {
+		unit = "line"
+	}
+	if b.Language == "" {
+		return fmt.Sprintf("[code: %d %s]", b.Lines, unit)
+	}
+	return fmt.Sprintf("[code: %s, %d %s]", b.Language, b.Lines, unit)
+}
+
+// ParseFence reports whether line opens a code fence, returning the fence
+// run and the language tag (the first word of the info string).
+func ParseFence(line string) (string, string, bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	if len(trimmed) < 3 {
+		return "", "", false
+	}
+
+	fenceChar := trimmed[0]
+	if fenceChar != '`' && fenceChar != '~' {
+		return "", "", false
+	}
+
+	count := 0
+	for count < len(trimmed) && trimmed[count] == fenceChar {
+		count++
+	}
+	if count < 3 {
+		return "", "", false
+	}
+
+	fence := trimmed[:count]
+	lang := ""
+	if parts := strings.Fields(trimmed[count:]); len(parts) > 0 {
+		lang = parts[0]
+	}
+	return fence, lang, true
+}
+
+// IsClosingFence reports whether line closes a block opened by fence.
+func IsClosingFence(line string, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) < len(fence) {
+		return false
+	}
+	for i := 0; i < len(trimmed); i++ {
+		if trimmed[i] != fence[0] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCodeBlocks(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want []CodeBlock
+	}{
+		{"none", "plain `inline` text", nil},
+		{"language tag", "see:\n```go\nfunc main() {}\nreturn\n```\ndone", []CodeBlock{
+			{Language: "go", Lines: 2, StartLine: 1, EndLine: 4, Closed: true},
+		}},
+		{"info string", "~~~  python title=x\nprint(1)\n~~~", []CodeBlock{
+			{Language: "python", Lines: 1, StartLine: 0, EndLine: 2, Closed: true},
+		}},
+		{"no language", "```\n```", []CodeBlock{
+			{Lines: 0, StartLine: 0, EndLine: 1, Closed: true},
+		}},
+		{"nested shorter fence", "````md\n```go\nx\n```\n````", []CodeBlock{
+			{Language: "md", Lines: 3, StartLine: 0, EndLine: 4, Closed: true},
+		}},
+		{"mismatched fence char", "```\n~~~\n```", []CodeBlock{
+			{Lines: 1, StartLine: 0, EndLine: 2, Closed: true},
+		}},
+		{"closer with info string does not close", "```sh\nls\n```sh\n```", []CodeBlock{
+			{Language: "sh", Lines: 2, StartLine: 0, EndLine: 3, Closed: true},
+		}},
+		{"unterminated", "intro\n```go\na\nb", []CodeBlock{
+			{Language: "go", Lines: 2, StartLine: 1, EndLine: 3, Closed: false},
+		}},
+		{"two blocks", "```a\n1\n```\nmid\n```b\n2\n3\n```", []CodeBlock{
+			{Language: "a", Lines: 1, StartLine: 0, EndLine: 2, Closed: true},
+			{Language: "b", Lines: 2, StartLine: 4, EndLine: 7, Closed: true},
+		}},
+	}
+	for _, tc := range cases {
+		got := ParseCodeBlocks(tc.body)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: ParseCodeBlocks = %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCollapseCodeBlocks(t *testing.T) {
+	cases := []struct {
+		body string
+		want string
+	}{
+		{"no code here", "no code here"},
+		{"fix:\n```go\na()\nb()\n```\nthoughts?", "fix:\n[code: go, 2 lines]\nthoughts?"},
+		{"```\none\n```", "[code: 1 line]"},
+		{"draft\n```py\nx = 1", "draft\n[code: py, 1 line]"},
+		{"````md\n```\nquoted\n```\n````\nafter", "[code: md, 3 lines]\nafter"},
+	}
+	for _, tc := range cases {
+		if got := CollapseCodeBlocks(tc.body); got != tc.want {
+			t.Errorf("CollapseCodeBlocks(%q) = %q, want %q", tc.body, got, tc.want)
+		}
+	}
+}
+
+func TestParseFence(t *testing.T) {
+	cases := []struct {
+		line, fence, lang string
+	}{
+		{"```go", "```", "go"},
+		{"~~~  mlld other", "~~~", "mlld"},
+		{"  ````", "````", ""},
+	}
+	for _, tc := range cases {
+		fence, lang, ok := ParseFence(tc.line)
+		if !ok || fence != tc.fence || lang != tc.lang {
+			t.Errorf("ParseFence(%q) = %q, %q, %v; want %q, %q", tc.line, fence, lang, ok, tc.fence, tc.lang)
+		}
+	}
+	if _, _, ok := ParseFence("``not a fence"); ok {
+		t.Error("expected two backticks not to open a fence")
+	}
+}
+
+func TestCodeSpans(t *testing.T) {
+	body := "a `x` b\n~~~\n@ghost\n~~~\n````\n```\n@nested\n```\n````\nc ``y`` d"
+	var got []string
+	for _, span := range CodeSpans(body) {
+		got = append(got, body[span[0]:span[1]])
+	}
+	want := []string{"`x`", "~~~\n@ghost\n~~~\n", "````\n```\n@nested\n```\n````\n", "``y``"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CodeSpans = %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 )
@@ -16,6 +17,8 @@ type GlobalConfig struct {
 type GlobalChannelRef struct {
 	Name string `json:"name"`
 	Path string `json:"path"`
+	// DaemonDisabled excludes the channel from `fray daemon --all-channels`.
+	DaemonDisabled bool `json:"daemon_disabled,omitempty"`
 }
 
 func globalConfigPath() (string, error) {
@@ -27,6 +30,16 @@ func globalConfigPath() (string, error) {
 	return filepath.Join(configDir, "fray-config.json"), nil
 }
 
+// GlobalSettingsPath returns the path of the user's global config layer
+// (~/.config/fray/config.json). It is separate from the channel registry.
+func GlobalSettingsPath() (string, error) {
+	path, err := globalConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "config.json"), nil
+}
+
 func ensureConfigDir() (string, error) {
 	path, err := globalConfigPath()
 	if err != nil {
@@ -93,8 +106,9 @@ func RegisterChannel(channelID, channelName, projectRoot string) (*GlobalConfig,
 	}
 
 	config.Channels[channelID] = GlobalChannelRef{
-		Name: channelName,
-		Path: projectRoot,
+		Name:           channelName,
+		Path:           projectRoot,
+		DaemonDisabled: config.Channels[channelID].DaemonDisabled,
 	}
 
 	if err := WriteGlobalConfig(*config); err != nil {
@@ -118,3 +132,22 @@ func FindChannelByRef(ref string, config *GlobalConfig) (string, GlobalChannelRe
 	}
 	return "", GlobalChannelRef{}, false
 }
+
+// SetChannelDaemonEnabled toggles whether the multi-project daemon runs the
+// channel identified by ref (ID or name).
+func SetChannelDaemonEnabled(ref string, enabled bool) (string, GlobalChannelRef, error) {
+	config, err := ReadGlobalConfig()
+	if err != nil {
+		return "", GlobalChannelRef{}, err
+	}
+	id, channel, ok := FindChannelByRef(ref, config)
+	if !ok {
+		return "", GlobalChannelRef{}, fmt.Errorf("channel not found: %s", ref)
+	}
+	channel.DaemonDisabled = !enabled
+	config.Channels[id] = channel
+	if err := WriteGlobalConfig(*config); err != nil {
+		return "", GlobalChannelRef{}, err
+	}
+	return id, channel, nil
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// secretDetector is a built-in credential pattern checked before posting.
+type secretDetector struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// secretDetectors catch common credentials so they never reach the shared
+// JSONL, which may sync to cloud storage.
+var secretDetectors = []secretDetector{
+	{"aws-access-key-id", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"aws-secret-access-key", regexp.MustCompile(`(?i)aws_?secret_?access_?key\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}`)},
+	{"github-token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"api-key", regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{"bearer-token", regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{20,}=*`)},
+	{"private-key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY-----`)},
+}
+
+// BlockedContentError reports which pattern a message body matched. It names
+// the pattern, never the matched text, so the secret is not echoed back.
+type BlockedContentError struct {
+	Pattern string
+	Custom  bool
+}
+
+func (e *BlockedContentError) Error() string {
+	if e.Custom {
+		return fmt.Sprintf("message blocked: matches post_block_patterns entry %q", e.Pattern)
+	}
+	return fmt.Sprintf("message blocked: looks like a secret (%s)", e.Pattern)
+}
+
+// CompileBlockPatterns compiles project-defined block patterns.
+func CompileBlockPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block pattern %q: %v", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// CheckContent returns a *BlockedContentError when body matches a built-in
+// secret detector or one of the custom patterns.
+func CheckContent(body string, custom []*regexp.Regexp) error {
+	for _, detector := range secretDetectors {
+		if detector.re.MatchString(body) {
+			return &BlockedContentError{Pattern: detector.name}
+		}
+	}
+	for _, re := range custom {
+		if re.MatchString(body) {
+			return &BlockedContentError{Pattern: re.String(), Custom: true}
+		}
+	}
+	return nil
+}
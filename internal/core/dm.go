@@ -0,0 +1,42 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// DMThreadRoot is the name of the root thread that holds direct messages.
+const DMThreadRoot = "dm"
+
+// DMThreadName returns the deterministic dm thread name for two identities,
+// e.g. DMThreadName("pm", "dev") == "dev+pm".
+func DMThreadName(a, b string) string {
+	pair := []string{a, b}
+	sort.Strings(pair)
+	return pair[0] + "+" + pair[1]
+}
+
+// DMParticipants returns the two identities in a dm thread, or nil if the
+// thread is not a conversation (e.g. the dm/ root).
+func DMParticipants(thread *types.Thread) []string {
+	if thread == nil || thread.Type != types.ThreadTypeDM {
+		return nil
+	}
+	parts := strings.Split(thread.Name, "+")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	return parts
+}
+
+// IsDMParticipant reports whether agentID is one side of a dm thread.
+func IsDMParticipant(thread *types.Thread, agentID string) bool {
+	for _, participant := range DMParticipants(thread) {
+		if participant == agentID {
+			return true
+		}
+	}
+	return false
+}
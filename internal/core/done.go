@@ -0,0 +1,42 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// ParseDoneStatus validates a fray done status, defaulting to success.
+func ParseDoneStatus(value string) (types.DoneStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", string(types.DoneStatusSuccess):
+		return types.DoneStatusSuccess, nil
+	case string(types.DoneStatusBlocked):
+		return types.DoneStatusBlocked, nil
+	case string(types.DoneStatusNeedsReview), "review":
+		return types.DoneStatusNeedsReview, nil
+	}
+	return "", fmt.Errorf("invalid done status %q (use success, blocked, or needs-review)", value)
+}
+
+// FormatDoneBody renders the body of a done message: "<status>: <summary>".
+func FormatDoneBody(status types.DoneStatus, summary string) string {
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return string(status)
+	}
+	return string(status) + ": " + summary
+}
+
+// ParseDoneBody recovers the status and summary from a done message body.
+// Unrecognised bodies are treated as a success with the whole body as summary.
+func ParseDoneBody(body string) (types.DoneStatus, string) {
+	body = strings.TrimSpace(body)
+	head, summary, _ := strings.Cut(body, ":")
+	status, err := ParseDoneStatus(head)
+	if err != nil || head == "" {
+		return types.DoneStatusSuccess, body
+	}
+	return status, strings.TrimSpace(summary)
+}
@@ -15,18 +15,27 @@ const (
 
 // GenerateGUID creates a short GUID with the provided prefix.
 func GenerateGUID(prefix string) (string, error) {
+	return GenerateGUIDWithLength(prefix, guidLength)
+}
+
+// GenerateGUIDWithLength creates a GUID with length random characters after
+// the prefix. A length <= 0 uses the default.
+func GenerateGUIDWithLength(prefix string, length int) (string, error) {
+	if length <= 0 {
+		length = guidLength
+	}
 	normalized := prefix
 	if len(normalized) > 0 && normalized[len(normalized)-1] == '-' {
 		normalized = normalized[:len(normalized)-1]
 	}
 
-	buf := make([]byte, guidLength)
+	buf := make([]byte, length)
 	if _, err := rand.Read(buf); err != nil {
 		return "", fmt.Errorf("generate guid: %w", err)
 	}
 
-	id := make([]byte, guidLength)
-	for i := 0; i < guidLength; i++ {
+	id := make([]byte, length)
+	for i := 0; i < length; i++ {
 		id[i] = guidAlphabet[int(buf[i])%len(guidAlphabet)]
 	}
 
@@ -0,0 +1,29 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// importantMarkerRe matches a standalone !important token (case-insensitive)
+// with at most one space or tab on either side.
+var importantMarkerRe = regexp.MustCompile(`(?im)(^|[ \t])!important([ \t]|$)`)
+
+// ExtractImportantMarker reports whether body carries the !important
+// shorthand and returns the body with the marker removed. Tokens glued to
+// other text (e.g. "css!important") are left alone.
+func ExtractImportantMarker(body string) (string, bool) {
+	if !importantMarkerRe.MatchString(body) {
+		return body, false
+	}
+	stripped := importantMarkerRe.ReplaceAllStringFunc(body, func(match string) string {
+		// Keep one separator when the marker sat between two words.
+		if strings.HasPrefix(match, " ") || strings.HasPrefix(match, "\t") {
+			if strings.HasSuffix(match, " ") || strings.HasSuffix(match, "\t") {
+				return " "
+			}
+		}
+		return ""
+	})
+	return strings.TrimSpace(stripped), true
+}
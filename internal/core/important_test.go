@@ -0,0 +1,25 @@
+package core
+
+import "testing"
+
+func TestExtractImportantMarker(t *testing.T) {
+	cases := []struct {
+		body      string
+		want      string
+		important bool
+	}{
+		{"!important deploy is blocked", "deploy is blocked", true},
+		{"deploy is blocked !important", "deploy is blocked", true},
+		{"deploy !IMPORTANT is blocked", "deploy is blocked", true},
+		{"first line\n!important\nsecond line", "first line\n\nsecond line", true},
+		{"color: red!important;", "color: red!important;", false},
+		{"nothing to see", "nothing to see", false},
+		{"!importantly not a marker", "!importantly not a marker", false},
+	}
+	for _, tc := range cases {
+		got, important := ExtractImportantMarker(tc.body)
+		if got != tc.want || important != tc.important {
+			t.Errorf("ExtractImportantMarker(%q) = (%q, %v), want (%q, %v)", tc.body, got, important, tc.want, tc.important)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package core
 
 import (
 	"regexp"
+	"sort"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -9,8 +11,13 @@ import (
 var (
 	mentionRe  = regexp.MustCompile(`@([a-z][a-z0-9]*(?:[-\.][a-z0-9]+)*)`)
 	issueRefRe = regexp.MustCompile(`@([a-z]+-[a-zA-Z0-9]+)`)
+	// tracker:id references like bd:abc1; the id needs a digit so prose like "note:" doesn't match
+	trackerRefRe = regexp.MustCompile(`(?:^|[^A-Za-z0-9_:/.@-])([a-z]{2,10}:[a-zA-Z0-9]*[0-9][a-zA-Z0-9]*)\b`)
 )
 
+// maxMentionSuggestDistance bounds how far a typo may be from a suggestion.
+const maxMentionSuggestDistance = 2
+
 // ExtractMentions returns mention targets without @ prefix.
 func ExtractMentions(body string, agentBases map[string]struct{}) []string {
 	matches := mentionRe.FindAllStringSubmatchIndex(body, -1)
@@ -53,21 +60,130 @@ func ExtractMentions(body string, agentBases map[string]struct{}) []string {
 	return mentions
 }
 
-// ExtractIssueRefs finds @prefix-id style references.
-func ExtractIssueRefs(body string) []string {
-	matches := issueRefRe.FindAllStringSubmatch(body, -1)
+// UnknownMentions returns @names in body that match no known base, in order
+// of appearance. @all, hyphenated names (issue references), names escaped
+// as \@name, and names inside code spans are skipped. A dotted name must be
+// known as a whole, or be a known base with a numeric version (dev.1), so
+// @dev.frontnd is unknown even when @dev exists. Returns nil when no bases
+// are known.
+func UnknownMentions(body string, known map[string]struct{}) []string {
+	if len(known) == 0 {
+		return nil
+	}
+	code := CodeSpans(body)
+	inCode := func(pos int) bool { return InCodeSpan(code, pos) }
+
+	var unknown []string
 	seen := map[string]struct{}{}
-	for _, match := range matches {
-		if len(match) < 2 {
+	for _, match := range mentionRe.FindAllStringSubmatchIndex(body, -1) {
+		start := match[0]
+		if start > 0 {
+			prev, _ := utf8.DecodeLastRuneInString(body[:start])
+			if isAlphaNum(prev) || prev == '\\' {
+				continue
+			}
+		}
+		if inCode(start) {
+			continue
+		}
+		name := body[match[2]:match[3]]
+		if name == "all" || strings.Contains(name, "-") {
+			continue
+		}
+		if _, ok := known[name]; ok {
+			continue
+		}
+		if parsed, err := ParseAgentID(name); err == nil && parsed.Version != nil {
+			if _, ok := known[parsed.Base]; ok {
+				continue
+			}
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		unknown = append(unknown, name)
+	}
+	return unknown
+}
+
+// SuggestMention returns the known name closest to name by edit distance,
+// or "" when none is within two edits. Ties go to the alphabetically first.
+func SuggestMention(name string, known map[string]struct{}) string {
+	candidates := make([]string, 0, len(known))
+	for candidate := range known {
+		candidates = append(candidates, candidate)
+	}
+	sort.Strings(candidates)
+
+	best := ""
+	bestDistance := maxMentionSuggestDistance + 1
+	for _, candidate := range candidates {
+		distance := editDistance(name, candidate)
+		if distance < bestDistance && distance < len(candidate) {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// editDistance is the Damerau-Levenshtein (optimal string alignment)
+// distance, so a swapped pair of letters (dve/dev) counts as one edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)]
+}
+
+// ExtractIssueRefs finds @prefix-id and tracker:id (e.g. bd:abc1) style
+// references. @names that are known agent bases (@code-reviewer) are
+// mentions, not issues. A tracker id needs a letter and a digit and must not
+// continue as a host or path, so localhost:8080 is not a reference.
+func ExtractIssueRefs(body string, agentBases map[string]struct{}) []string {
+	seen := map[string]struct{}{}
+	for _, match := range issueRefRe.FindAllStringSubmatch(body, -1) {
+		if _, ok := agentBases[match[1]]; ok {
 			continue
 		}
 		seen[lower(match[1])] = struct{}{}
 	}
+	for _, match := range trackerRefRe.FindAllStringSubmatchIndex(body, -1) {
+		ref := body[match[2]:match[3]]
+		id := ref[strings.Index(ref, ":")+1:]
+		if !strings.ContainsFunc(id, unicode.IsLetter) {
+			continue
+		}
+		if rest := body[match[3]:]; len(rest) > 1 && strings.ContainsRune(":/.", rune(rest[0])) && isAlphaNum(rune(rest[1])) {
+			continue
+		}
+		seen[lower(ref)] = struct{}{}
+	}
 
 	refs := make([]string, 0, len(seen))
 	for ref := range seen {
 		refs = append(refs, ref)
 	}
+	sort.Strings(refs)
 	return refs
 }
 
@@ -28,3 +28,54 @@ func assertMention(t *testing.T, mentions []string, value string) {
 	}
 	t.Fatalf("expected mention %s", value)
 }
+
+func TestExtractIssueRefs(t *testing.T) {
+	refs := ExtractIssueRefs("starting on bd:abc1, see @fray-x9z and BD:ABC1 again; note: later, https://x.io:8080", nil)
+	if len(refs) != 2 || refs[0] != "bd:abc1" || refs[1] != "fray-x9z" {
+		t.Fatalf("unexpected refs: %v", refs)
+	}
+
+	bases := map[string]struct{}{"code-reviewer": {}}
+	body := "@code-reviewer take a look; server on localhost:8080 and db:5432, docs at wiki:page2/setup, tracking gh:a12."
+	if refs := ExtractIssueRefs(body, bases); len(refs) != 1 || refs[0] != "gh:a12" {
+		t.Fatalf("expected only gh:a12, got %v", refs)
+	}
+}
+
+func TestUnknownMentions(t *testing.T) {
+	known := map[string]struct{}{"dev": {}, "pm": {}, "adam": {}}
+
+	body := "@dve and @dev.1 please, cc @pm @all @fray-x9z, not \\@dve or `@dve`, email a@dve.io @zzzz @dve"
+	got := UnknownMentions(body, known)
+	if len(got) != 2 || got[0] != "dve" || got[1] != "zzzz" {
+		t.Fatalf("unexpected unknown mentions: %v", got)
+	}
+	// Tilde and nested fences hide mentions too.
+	if got := UnknownMentions("~~~\n@dve\n~~~\n````\n```\n@zzzz\n```\n````", known); got != nil {
+		t.Fatalf("expected mentions in fenced blocks to be skipped, got %v", got)
+	}
+	// A dotted name is not vouched for by its first segment.
+	known["dev.frontend"] = struct{}{}
+	if got := UnknownMentions("@dev.frontend @dev.2 @dev.frontnd", known); len(got) != 1 || got[0] != "dev.frontnd" {
+		t.Fatalf("expected only the dotted typo, got %v", got)
+	}
+	if got := UnknownMentions("@dve", nil); got != nil {
+		t.Fatalf("expected no check without known bases, got %v", got)
+	}
+}
+
+func TestSuggestMention(t *testing.T) {
+	known := map[string]struct{}{"dev": {}, "pm": {}, "designer": {}}
+	cases := map[string]string{
+		"dve":      "dev",
+		"deev":     "dev",
+		"desinger": "designer",
+		"x":        "",
+		"zzzz":     "",
+	}
+	for name, want := range cases {
+		if got := SuggestMention(name, known); got != want {
+			t.Errorf("SuggestMention(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
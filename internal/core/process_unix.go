@@ -0,0 +1,12 @@
+//go:build !windows
+
+package core
+
+import "syscall"
+
+// ProcessAlive reports whether a process with the given pid exists on this
+// host. Non-positive pids never match: kill(0, 0) would signal our own
+// process group and always succeed.
+func ProcessAlive(pid int) bool {
+	return pid > 0 && syscall.Kill(pid, 0) == nil
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package core
+
+import "os"
+
+// ProcessAlive reports whether a process with the given pid exists on this
+// host. On Windows FindProcess opens a handle, which fails for exited pids.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = proc.Release()
+	return true
+}
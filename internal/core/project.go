@@ -13,10 +13,38 @@ type Project struct {
 	DBPath string
 }
 
+// ProjectRootEnv pins project discovery to an explicit root, skipping the walk up from cwd.
+const ProjectRootEnv = "FRAY_PROJECT_ROOT"
+
+// ErrNoProject is matched (via errors.Is) by every NoProjectError.
+var ErrNoProject = errors.New("not initialized. Run 'fray init' first")
+
+// NoProjectError reports that discovery found no .fray directory, listing where it looked.
+type NoProjectError struct {
+	Searched []string
+}
+
+func (e *NoProjectError) Error() string {
+	if len(e.Searched) == 0 {
+		return ErrNoProject.Error()
+	}
+	return fmt.Sprintf("%s (searched: %s)", ErrNoProject.Error(), stringsJoin(e.Searched, ", "))
+}
+
+// Is reports whether target is ErrNoProject.
+func (e *NoProjectError) Is(target error) bool {
+	return target == ErrNoProject
+}
+
 // DiscoverProject walks up from startDir to find a .fray directory.
+// An empty startDir uses FRAY_PROJECT_ROOT when set (no walking), otherwise cwd.
+// The walk stops at the user's home directory and at the filesystem root.
 func DiscoverProject(startDir string) (Project, error) {
 	current := startDir
 	if current == "" {
+		if root := os.Getenv(ProjectRootEnv); root != "" {
+			return OpenProjectAt(root)
+		}
 		cwd, err := os.Getwd()
 		if err != nil {
 			return Project{}, err
@@ -28,38 +56,79 @@ func DiscoverProject(startDir string) (Project, error) {
 		return Project{}, err
 	}
 
+	home := ""
+	if dir, err := os.UserHomeDir(); err == nil && dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			home = filepath.Clean(abs)
+		}
+	}
+
+	var searched []string
 	for {
-		frayDir := filepath.Join(current, ".fray")
-		info, err := os.Stat(frayDir)
-		if err == nil && info.IsDir() {
-			dbPath := filepath.Join(frayDir, "fray.db")
-			// DB file is optional - OpenDatabase will rebuild from JSONL if needed
-			// Just check that either db or at least one JSONL file exists
-			hasDB := false
-			if _, err := os.Stat(dbPath); err == nil {
-				hasDB = true
-			}
-			hasJSONL := false
-			for _, name := range []string{"messages.jsonl", "agents.jsonl"} {
-				if _, err := os.Stat(filepath.Join(frayDir, name)); err == nil {
-					hasJSONL = true
-					break
-				}
-			}
-			if !hasDB && !hasJSONL {
-				return Project{}, fmt.Errorf("fray database not found. Run 'fray init' first")
-			}
-			return Project{Root: current, DBPath: dbPath}, nil
+		searched = append(searched, current)
+		project, found, err := projectAt(current)
+		if err != nil {
+			return Project{}, err
+		}
+		if found {
+			return project, nil
 		}
 
+		if current == home {
+			return Project{}, &NoProjectError{Searched: searched}
+		}
 		parent := filepath.Dir(current)
 		if parent == current {
-			return Project{}, fmt.Errorf("not initialized. Run 'fray init' first")
+			return Project{}, &NoProjectError{Searched: searched}
 		}
 		current = parent
 	}
 }
 
+// OpenProjectAt returns the project rooted exactly at root without walking up.
+func OpenProjectAt(root string) (Project, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return Project{}, err
+	}
+	project, found, err := projectAt(root)
+	if err != nil {
+		return Project{}, err
+	}
+	if !found {
+		return Project{}, &NoProjectError{Searched: []string{root}}
+	}
+	return project, nil
+}
+
+// projectAt checks dir for a usable .fray directory.
+func projectAt(dir string) (Project, bool, error) {
+	frayDir := filepath.Join(dir, ".fray")
+	info, err := os.Stat(frayDir)
+	if err != nil || !info.IsDir() {
+		return Project{}, false, nil
+	}
+
+	dbPath := filepath.Join(frayDir, "fray.db")
+	// DB file is optional - OpenDatabase will rebuild from JSONL if needed
+	// Just check that either db or at least one JSONL file exists
+	hasDB := false
+	if _, err := os.Stat(dbPath); err == nil {
+		hasDB = true
+	}
+	hasJSONL := false
+	for _, name := range []string{"messages.jsonl", "agents.jsonl"} {
+		if _, err := os.Stat(filepath.Join(frayDir, name)); err == nil {
+			hasJSONL = true
+			break
+		}
+	}
+	if !hasDB && !hasJSONL {
+		return Project{}, false, fmt.Errorf("fray database not found. Run 'fray init' first")
+	}
+	return Project{Root: dir, DBPath: dbPath}, true, nil
+}
+
 // InitProject initializes a new fray project at dir.
 func InitProject(dir string, force bool) (Project, error) {
 	root := dir
@@ -96,10 +165,10 @@ func InitProject(dir string, force bool) (Project, error) {
 	return Project{Root: root, DBPath: dbPath}, nil
 }
 
-// EnsureFrayGitignore ensures .fray/.gitignore contains sqlite ignores.
+// EnsureFrayGitignore ensures .fray/.gitignore ignores sqlite files and local state.
 func EnsureFrayGitignore(frayDir string) {
 	gitignore := filepath.Join(frayDir, ".gitignore")
-	entries := []string{"*.db", "*.db-wal", "*.db-shm"}
+	entries := []string{"*.db", "*.db-wal", "*.db-shm", "local/"}
 
 	data, err := os.ReadFile(gitignore)
 	if err != nil {
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func makeFrayDir(t *testing.T, root string) {
+	t.Helper()
+	frayDir := filepath.Join(root, ".fray")
+	if err := os.MkdirAll(frayDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(frayDir, "messages.jsonl"), nil, 0o644); err != nil {
+		t.Fatalf("write jsonl: %v", err)
+	}
+}
+
+func TestDiscoverProjectHonorsEnvRoot(t *testing.T) {
+	pinned := t.TempDir()
+	makeFrayDir(t, pinned)
+
+	other := t.TempDir()
+	makeFrayDir(t, other)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(other); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	t.Setenv(ProjectRootEnv, pinned)
+	project, err := DiscoverProject("")
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if project.Root != pinned {
+		t.Fatalf("expected env root %s, got %s", pinned, project.Root)
+	}
+
+	// An explicit start dir still wins over the env.
+	project, err = DiscoverProject(other)
+	if err != nil {
+		t.Fatalf("discover explicit: %v", err)
+	}
+	if project.Root != other {
+		t.Fatalf("expected explicit root %s, got %s", other, project.Root)
+	}
+}
+
+func TestDiscoverProjectEnvRootDoesNotWalk(t *testing.T) {
+	parent := t.TempDir()
+	makeFrayDir(t, parent)
+	child := filepath.Join(parent, "child")
+	if err := os.MkdirAll(child, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	t.Setenv(ProjectRootEnv, child)
+	_, err := DiscoverProject("")
+	if !errors.Is(err, ErrNoProject) {
+		t.Fatalf("expected ErrNoProject, got %v", err)
+	}
+}
+
+func TestDiscoverProjectStopsAtHome(t *testing.T) {
+	base := t.TempDir()
+	makeFrayDir(t, base) // would be found if discovery walked past home
+
+	home := filepath.Join(base, "home")
+	start := filepath.Join(home, "work", "repo")
+	if err := os.MkdirAll(start, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	t.Setenv("HOME", home)
+
+	_, err := DiscoverProject(start)
+	var noProject *NoProjectError
+	if !errors.As(err, &noProject) {
+		t.Fatalf("expected NoProjectError, got %v", err)
+	}
+	want := []string{start, filepath.Join(home, "work"), home}
+	if len(noProject.Searched) != len(want) {
+		t.Fatalf("expected searched %v, got %v", want, noProject.Searched)
+	}
+	for i, path := range want {
+		if noProject.Searched[i] != path {
+			t.Fatalf("expected searched %v, got %v", want, noProject.Searched)
+		}
+	}
+	if !strings.Contains(err.Error(), "fray init") || !strings.Contains(err.Error(), home) {
+		t.Fatalf("expected error to mention init and searched paths, got %q", err.Error())
+	}
+}
+
+func TestDiscoverProjectFindsHomeProject(t *testing.T) {
+	home := t.TempDir()
+	makeFrayDir(t, home)
+	start := filepath.Join(home, "notes")
+	if err := os.MkdirAll(start, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	t.Setenv("HOME", home)
+
+	project, err := DiscoverProject(start)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if project.Root != home {
+		t.Fatalf("expected home project, got %s", project.Root)
+	}
+}
@@ -36,8 +36,6 @@ var (
 	letteredOptionRe = regexp.MustCompile(`^([a-z])\.\s+(.+)$`)
 	// Match pro/con bullets
 	proConRe = regexp.MustCompile(`(?i)^-\s*(pro|con)s?:\s*(.+)$`)
-	// Match code fence start/end
-	codeFenceRe = regexp.MustCompile("^```")
 )
 
 // ExtractQuestionSections parses markdown and extracts question/wondering sections.
@@ -47,24 +45,20 @@ func ExtractQuestionSections(body string) ([]QuestionSection, string) {
 	var sections []QuestionSection
 	var cleanedLines []string
 
-	inCodeFence := false
+	codeLines := make(map[int]bool)
+	for _, block := range ParseCodeBlocks(body) {
+		for i := block.StartLine; i <= block.EndLine; i++ {
+			codeLines[i] = true
+		}
+	}
 	inSection := false
 	var currentSection *QuestionSection
 	var currentQuestion *ExtractedQuestion
 	var currentOption *QuestionOption
 
 	for lineNum, line := range lines {
-		// Track code fences
-		if codeFenceRe.MatchString(strings.TrimSpace(line)) {
-			inCodeFence = !inCodeFence
-			if !inSection {
-				cleanedLines = append(cleanedLines, line)
-			}
-			continue
-		}
-
-		// Skip parsing inside code fences
-		if inCodeFence {
+		// Skip parsing inside code fences, fence lines included
+		if codeLines[lineNum] {
 			if !inSection {
 				cleanedLines = append(cleanedLines, line)
 			}
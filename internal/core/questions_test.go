@@ -131,6 +131,14 @@ func TestExtractQuestionSections_SkipsCodeBlocks(t *testing.T) {
 	}
 }
 
+func TestExtractQuestionSections_SkipsTildeAndNestedFences(t *testing.T) {
+	body := "~~~\n# Questions for @adam\n1. Not real\n~~~\n````md\n```\n# Questions\n1. Also not real\n```\n````"
+
+	if sections, _ := ExtractQuestionSections(body); len(sections) != 0 {
+		t.Errorf("expected 0 sections inside fences, got %d", len(sections))
+	}
+}
+
 func TestExtractQuestionSections_HashHashHeader(t *testing.T) {
 	body := `## Questions for @opus
 
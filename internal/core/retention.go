@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy says how much history a home keeps: everything, the newest
+// Keep messages, or messages younger than MaxAge.
+type RetentionPolicy struct {
+	KeepAll bool
+	Keep    int
+	MaxAge  time.Duration
+}
+
+// ParseRetentionPolicy parses "keep:all", "keep:<n>", or "age:<n><m|h|d|w>"
+// (e.g. "keep:500", "age:90d").
+func ParseRetentionPolicy(value string) (RetentionPolicy, error) {
+	kind, arg, ok := strings.Cut(strings.ToLower(strings.TrimSpace(value)), ":")
+	if !ok || arg == "" {
+		return RetentionPolicy{}, fmt.Errorf("retention policy must be keep:all, keep:<n>, or age:<duration> (e.g. age:90d)")
+	}
+	switch kind {
+	case "keep":
+		if arg == "all" {
+			return RetentionPolicy{KeepAll: true}, nil
+		}
+		keep, err := strconv.Atoi(arg)
+		if err != nil || keep < 0 {
+			return RetentionPolicy{}, fmt.Errorf("invalid keep count %q", arg)
+		}
+		return RetentionPolicy{Keep: keep}, nil
+	case "age":
+		age, err := parseRetentionAge(arg)
+		if err != nil {
+			return RetentionPolicy{}, err
+		}
+		return RetentionPolicy{MaxAge: age}, nil
+	default:
+		return RetentionPolicy{}, fmt.Errorf("unknown retention policy %q (use keep or age)", kind)
+	}
+}
+
+func parseRetentionAge(value string) (time.Duration, error) {
+	units := map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	unit, ok := units[value[len(value)-1]]
+	amount, err := strconv.Atoi(value[:len(value)-1])
+	if !ok || err != nil || amount <= 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 90d, 12h, 2w)", value)
+	}
+	return time.Duration(amount) * unit, nil
+}
+
+// String renders the policy in the form ParseRetentionPolicy accepts.
+func (p RetentionPolicy) String() string {
+	switch {
+	case p.KeepAll:
+		return "keep:all"
+	case p.MaxAge > 0:
+		if p.MaxAge%(24*time.Hour) == 0 {
+			return fmt.Sprintf("age:%dd", p.MaxAge/(24*time.Hour))
+		}
+		if p.MaxAge%time.Hour == 0 {
+			return fmt.Sprintf("age:%dh", p.MaxAge/time.Hour)
+		}
+		return fmt.Sprintf("age:%dm", p.MaxAge/time.Minute)
+	default:
+		return fmt.Sprintf("keep:%d", p.Keep)
+	}
+}
+
+// Expired reports which of a home's message timestamps, oldest first, fall
+// outside the policy at now.
+func (p RetentionPolicy) Expired(timestamps []int64, now time.Time) []bool {
+	expired := make([]bool, len(timestamps))
+	switch {
+	case p.KeepAll:
+	case p.MaxAge > 0:
+		cutoff := now.Add(-p.MaxAge).Unix()
+		for i, ts := range timestamps {
+			expired[i] = ts < cutoff
+		}
+	default:
+		for i := 0; i < len(timestamps)-p.Keep; i++ {
+			expired[i] = true
+		}
+	}
+	return expired
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRetentionPolicy(t *testing.T) {
+	cases := map[string]RetentionPolicy{
+		"keep:all": {KeepAll: true},
+		"keep:500": {Keep: 500},
+		"keep:0":   {Keep: 0},
+		" AGE:90d": {MaxAge: 90 * 24 * time.Hour},
+		"age:12h":  {MaxAge: 12 * time.Hour},
+		"age:2w":   {MaxAge: 14 * 24 * time.Hour},
+	}
+	for input, want := range cases {
+		got, err := ParseRetentionPolicy(input)
+		if err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("%q: expected %+v, got %+v", input, want, got)
+		}
+	}
+
+	for _, bad := range []string{"", "500", "keep:", "keep:-1", "keep:many", "age:90", "age:0d", "age:3y", "drop:10"} {
+		if _, err := ParseRetentionPolicy(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+
+	for _, value := range []string{"keep:all", "keep:20", "age:90d", "age:12h", "age:30m"} {
+		policy, _ := ParseRetentionPolicy(value)
+		if policy.String() != value {
+			t.Fatalf("expected %q to round-trip, got %q", value, policy.String())
+		}
+	}
+}
+
+func TestRetentionPolicyExpired(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	day := int64(86400)
+	timestamps := []int64{now.Unix() - 10*day, now.Unix() - 5*day, now.Unix() - day, now.Unix()}
+
+	check := func(value string, want []bool) {
+		t.Helper()
+		policy, err := ParseRetentionPolicy(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := policy.Expired(timestamps, now)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: expected %v, got %v", value, want, got)
+			}
+		}
+	}
+	check("keep:all", []bool{false, false, false, false})
+	check("keep:1", []bool{true, true, true, false})
+	check("keep:10", []bool{false, false, false, false})
+	check("age:3d", []bool{true, true, false, false})
+}
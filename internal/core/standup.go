@@ -0,0 +1,113 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// DefaultStandupMarker is the body prefix that identifies a standup posted
+// without --standup. Projects can change it with the standup_marker config.
+const DefaultStandupMarker = "standup:"
+
+// Standup sources record how a message was recognized as a standup.
+const (
+	StandupSourceFlag      = "flag"      // posted with fray post --standup
+	StandupSourceHeuristic = "heuristic" // body starts with the standup marker
+)
+
+// StandupEntry is one standup message, bucketed by local calendar day.
+type StandupEntry struct {
+	Date      string `json:"date"`
+	AgentID   string `json:"agent_id"`
+	MessageID string `json:"message_id"`
+	TS        int64  `json:"ts"`
+	Body      string `json:"body"`
+	Source    string `json:"source"`
+}
+
+// StandupDay holds one day's standups grouped by agent.
+type StandupDay struct {
+	Date   string         `json:"date"`
+	Agents []StandupGroup `json:"agents"`
+}
+
+// StandupGroup is one agent's standups on a given day.
+type StandupGroup struct {
+	AgentID string         `json:"agent_id"`
+	Entries []StandupEntry `json:"entries"`
+}
+
+// DetectStandup reports whether msg is a standup and returns its body with
+// any marker removed.
+func DetectStandup(msg types.Message, marker string) (string, string, bool) {
+	if marker == "" {
+		marker = DefaultStandupMarker
+	}
+	body := strings.TrimSpace(msg.Body)
+	stripped, hasMarker := stripStandupMarker(body, marker)
+	if msg.Standup || msg.Type == types.MessageTypeStandup {
+		return StandupSourceFlag, stripped, true
+	}
+	if hasMarker {
+		return StandupSourceHeuristic, stripped, true
+	}
+	return "", "", false
+}
+
+func stripStandupMarker(body, marker string) (string, bool) {
+	if len(body) < len(marker) || !strings.EqualFold(body[:len(marker)], marker) {
+		return body, false
+	}
+	return strings.TrimSpace(body[len(marker):]), true
+}
+
+// CollectStandups picks the standups out of messages, dating each in loc.
+func CollectStandups(messages []types.Message, marker string, loc *time.Location) []StandupEntry {
+	if loc == nil {
+		loc = time.Local
+	}
+	var entries []StandupEntry
+	for _, msg := range messages {
+		source, body, ok := DetectStandup(msg, marker)
+		if !ok {
+			continue
+		}
+		entries = append(entries, StandupEntry{
+			Date:      time.Unix(msg.TS, 0).In(loc).Format("2006-01-02"),
+			AgentID:   msg.FromAgent,
+			MessageID: msg.ID,
+			TS:        msg.TS,
+			Body:      body,
+			Source:    source,
+		})
+	}
+	return entries
+}
+
+// GroupStandups buckets entries by day (oldest first), then by agent (by
+// name), keeping each agent's entries in posting order.
+func GroupStandups(entries []StandupEntry) []StandupDay {
+	byDay := map[string]map[string][]StandupEntry{}
+	for _, entry := range entries {
+		if byDay[entry.Date] == nil {
+			byDay[entry.Date] = map[string][]StandupEntry{}
+		}
+		byDay[entry.Date][entry.AgentID] = append(byDay[entry.Date][entry.AgentID], entry)
+	}
+
+	days := make([]StandupDay, 0, len(byDay))
+	for date, agents := range byDay {
+		day := StandupDay{Date: date}
+		for agentID, agentEntries := range agents {
+			sort.SliceStable(agentEntries, func(i, j int) bool { return agentEntries[i].TS < agentEntries[j].TS })
+			day.Agents = append(day.Agents, StandupGroup{AgentID: agentID, Entries: agentEntries})
+		}
+		sort.Slice(day.Agents, func(i, j int) bool { return day.Agents[i].AgentID < day.Agents[j].AgentID })
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestDetectStandup(t *testing.T) {
+	cases := []struct {
+		name       string
+		msg        types.Message
+		marker     string
+		wantSource string
+		wantBody   string
+	}{
+		{"flagged", types.Message{Type: types.MessageTypeStandup, Body: "shipped auth"}, "", StandupSourceFlag, "shipped auth"},
+		{"flagged with marker", types.Message{Type: types.MessageTypeStandup, Body: "standup: shipped auth"}, "", StandupSourceFlag, "shipped auth"},
+		{"heuristic", types.Message{Type: types.MessageTypeAgent, Body: "  Standup: fixed login"}, "", StandupSourceHeuristic, "fixed login"},
+		{"custom marker", types.Message{Type: types.MessageTypeAgent, Body: "[daily] reviewed PRs"}, "[daily]", StandupSourceHeuristic, "reviewed PRs"},
+		{"default marker off with custom", types.Message{Type: types.MessageTypeAgent, Body: "standup: x"}, "[daily]", "", ""},
+		{"marker mid-body", types.Message{Type: types.MessageTypeAgent, Body: "see my standup: later"}, "", "", ""},
+	}
+	for _, tc := range cases {
+		source, body, ok := DetectStandup(tc.msg, tc.marker)
+		if ok != (tc.wantSource != "") || source != tc.wantSource || body != tc.wantBody {
+			t.Errorf("%s: got (%q, %q, %v), want (%q, %q)", tc.name, source, body, ok, tc.wantSource, tc.wantBody)
+		}
+	}
+}
+
+func TestGroupStandupsByDayAndAgent(t *testing.T) {
+	loc := time.FixedZone("PST", -8*3600)
+	// 23:30 local on the 1st, 00:30 local on the 2nd: one hour apart, two days.
+	lateDay1 := time.Date(2026, 3, 1, 23, 30, 0, 0, loc).Unix()
+	earlyDay2 := lateDay1 + 3600
+
+	messages := []types.Message{
+		{ID: "msg-3", TS: earlyDay2 + 60, FromAgent: "alice", Type: types.MessageTypeStandup, Body: "day two"},
+		{ID: "msg-1", TS: lateDay1, FromAgent: "bob", Type: types.MessageTypeAgent, Body: "standup: day one"},
+		{ID: "msg-2", TS: earlyDay2, FromAgent: "bob", Type: types.MessageTypeStandup, Body: "day two"},
+		{ID: "msg-4", TS: earlyDay2 + 120, FromAgent: "alice", Type: types.MessageTypeAgent, Body: "just chatting"},
+	}
+
+	entries := CollectStandups(messages, "", loc)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 standups, got %+v", entries)
+	}
+	days := GroupStandups(entries)
+	if len(days) != 2 || days[0].Date != "2026-03-01" || days[1].Date != "2026-03-02" {
+		t.Fatalf("expected two local days in order, got %+v", days)
+	}
+	if len(days[0].Agents) != 1 || days[0].Agents[0].AgentID != "bob" {
+		t.Fatalf("expected bob alone on day one, got %+v", days[0].Agents)
+	}
+	second := days[1].Agents
+	if len(second) != 2 || second[0].AgentID != "alice" || second[1].AgentID != "bob" {
+		t.Fatalf("expected alice then bob on day two, got %+v", second)
+	}
+	if second[1].Entries[0].MessageID != "msg-2" || second[1].Entries[0].Source != StandupSourceFlag {
+		t.Fatalf("unexpected bob entry: %+v", second[1].Entries[0])
+	}
+}
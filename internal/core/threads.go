@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// threadNameRe is the allowed thread name charset. + joins dm participants;
+// / is left out because it separates names in a thread path.
+var threadNameRe = regexp.MustCompile(`^[a-z0-9:.+-]+$`)
+
+// reservedThreadNames cannot be used because they mean something else in
+// thread references.
+var reservedThreadNames = map[string]bool{
+	"room": true,
+	"main": true,
+	"all":  true,
+}
+
+// NormalizeThreadName trims and lowercases a thread name.
+func NormalizeThreadName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ValidateThreadName checks a normalized thread name.
+func ValidateThreadName(name string) error {
+	if name == "" {
+		return fmt.Errorf("thread name is required")
+	}
+	if reservedThreadNames[name] {
+		return fmt.Errorf("thread name %q is reserved", name)
+	}
+	if !threadNameRe.MatchString(name) {
+		return fmt.Errorf("invalid thread name %q: use only lowercase letters, digits, and : . + -", name)
+	}
+	return nil
+}
+
+var (
+	// #name thread references; names use the thread name charset
+	threadNameRefRe = regexp.MustCompile(`(?i)#([a-z0-9][a-z0-9:./+-]*)`)
+	// bare thread GUIDs
+	threadGUIDRefRe = regexp.MustCompile(`(?i)\bthrd-[a-z0-9]+\b`)
+)
+
+// ThreadRefSpan is a thread reference found in a message body. Ref is a
+// thrd- GUID or a thread name (path) without the leading #.
+type ThreadRefSpan struct {
+	Start int
+	End   int
+	Ref   string
+}
+
+// FindThreadRefs returns the #name and thrd-GUID references in body in
+// order of appearance. References inside code spans, escaped as \#name,
+// glued to a preceding word (C#, &#39;, URL fragments), or made only of
+// digits (#123 is an issue number) are skipped, as is trailing punctuation.
+func FindThreadRefs(body string) []ThreadRefSpan {
+	code := CodeSpans(body)
+	inCode := func(pos int) bool { return InCodeSpan(code, pos) }
+	gluedBefore := func(pos int) bool {
+		if pos == 0 {
+			return false
+		}
+		prev, _ := utf8.DecodeLastRuneInString(body[:pos])
+		return isAlphaNum(prev) || strings.ContainsRune(`&#/\_`, prev)
+	}
+
+	var spans []ThreadRefSpan
+	for _, match := range threadNameRefRe.FindAllStringSubmatchIndex(body, -1) {
+		if gluedBefore(match[0]) || inCode(match[0]) {
+			continue
+		}
+		name := strings.TrimRight(body[match[2]:match[3]], ":./+-")
+		if name == "" || strings.Trim(name, "0123456789") == "" {
+			continue
+		}
+		spans = append(spans, ThreadRefSpan{Start: match[0], End: match[2] + len(name), Ref: strings.ToLower(name)})
+	}
+	for _, match := range threadGUIDRefRe.FindAllStringIndex(body, -1) {
+		start := match[0]
+		if gluedBefore(start) || inCode(start) || (start > 0 && body[start-1] == '-') {
+			continue
+		}
+		spans = append(spans, ThreadRefSpan{Start: start, End: match[1], Ref: strings.ToLower(body[start:match[1]])})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	return spans
+}
+
+// ExtractThreadRefs returns the distinct thread references in body, in
+// order of first appearance. See FindThreadRefs for what counts.
+func ExtractThreadRefs(body string) []string {
+	var refs []string
+	seen := map[string]struct{}{}
+	for _, span := range FindThreadRefs(body) {
+		if _, ok := seen[span.Ref]; ok {
+			continue
+		}
+		seen[span.Ref] = struct{}{}
+		refs = append(refs, span.Ref)
+	}
+	return refs
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateThreadName(t *testing.T) {
+	valid := []string{"design", "bd:abc1-work", "v1.2", "dm", "dev+pm"}
+	for _, name := range valid {
+		if err := ValidateThreadName(name); err != nil {
+			t.Errorf("expected %q to be valid: %v", name, err)
+		}
+	}
+
+	invalid := []string{"", "room", "main", "all", "Design", "design thread", "design_thread", "#design", "notes/2024"}
+	for _, name := range invalid {
+		if err := ValidateThreadName(name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+
+	if got := NormalizeThreadName("  Design "); got != "design" {
+		t.Errorf("expected normalized name design, got %q", got)
+	}
+}
+
+func TestExtractThreadRefs(t *testing.T) {
+	cases := []struct {
+		body string
+		want []string
+	}{
+		{"see #design and #Design.", []string{"design"}},
+		{"moved to thrd-ab12cd34, also #thrd-ab12cd34 and #opus/notes:", []string{"thrd-ab12cd34", "opus/notes"}},
+		{"fixes #123, C# and &#39; and https://x.io/#frag", nil},
+		{"`#design` and ```\nthrd-ab12cd34\n``` but #real", []string{"real"}},
+		{`escaped \#design and x-thrd-abc1`, nil},
+		{"#", nil},
+	}
+	for _, tc := range cases {
+		got := ExtractThreadRefs(tc.body)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("ExtractThreadRefs(%q) = %v, want %v", tc.body, got, tc.want)
+		}
+	}
+
+	spans := FindThreadRefs("see #design.")
+	if len(spans) != 1 || spans[0].Start != 4 || spans[0].End != 11 {
+		t.Fatalf("expected the span to stop before the period, got %+v", spans)
+	}
+}
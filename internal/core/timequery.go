@@ -60,6 +60,19 @@ func parseAbsoluteTime(value string) *time.Time {
 	return nil
 }
 
+// parseTimestamp accepts RFC3339 timestamps and bare YYYY-MM-DD dates (local
+// midnight).
+func parseTimestamp(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return &ts
+	}
+	if ts, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return &ts
+	}
+	return nil
+}
+
 func resolveGUIDCursor(db *sql.DB, expr string) (*types.MessageCursor, error) {
 	trimmed := strings.TrimSpace(expr)
 	if trimmed == "" {
@@ -166,27 +179,57 @@ func cursorForTime(ts time.Time, mode string) types.MessageCursor {
 	return types.MessageCursor{GUID: guid, TS: ts.Unix()}
 }
 
-// ParseTimeExpression converts a time expression or GUID into a cursor.
-func ParseTimeExpression(db *sql.DB, expression string, mode string) (*types.MessageCursor, error) {
+// resolveTimeExpression is the one parser behind ParseTimeExpression and
+// ParseTimeBound. Timestamps, today/yesterday, and durations are tried before
+// message GUIDs, so 24h or 2026-01-02 are never read as a GUID prefix. It
+// returns the time, plus the message cursor when the expression named one.
+func resolveTimeExpression(db *sql.DB, expression string) (time.Time, *types.MessageCursor, error) {
 	trimmed := strings.TrimSpace(expression)
+	if trimmed == "" {
+		return time.Time{}, nil, fmt.Errorf("empty time expression")
+	}
+
+	if stamp := parseTimestamp(trimmed); stamp != nil {
+		return *stamp, nil, nil
+	}
+	if absolute := parseAbsoluteTime(trimmed); absolute != nil {
+		return *absolute, nil, nil
+	}
+	if relative := parseRelativeTime(trimmed); relative != nil {
+		return *relative, nil, nil
+	}
 
 	guidCursor, err := resolveGUIDCursor(db, trimmed)
 	if err != nil {
-		return nil, err
+		return time.Time{}, nil, err
 	}
 	if guidCursor != nil {
-		return guidCursor, nil
+		return time.Unix(guidCursor.TS, 0), guidCursor, nil
 	}
 
-	if absolute := parseAbsoluteTime(trimmed); absolute != nil {
-		cursor := cursorForTime(*absolute, mode)
-		return &cursor, nil
-	}
+	return time.Time{}, nil, fmt.Errorf("invalid time expression: %s (use 24h, 7d, RFC3339, or a message GUID)", expression)
+}
 
-	if relative := parseRelativeTime(trimmed); relative != nil {
-		cursor := cursorForTime(*relative, mode)
-		return &cursor, nil
+// ParseTimeExpression converts a time expression or GUID into a cursor.
+func ParseTimeExpression(db *sql.DB, expression string, mode string) (*types.MessageCursor, error) {
+	ts, guidCursor, err := resolveTimeExpression(db, expression)
+	if err != nil {
+		return nil, err
+	}
+	if guidCursor != nil {
+		return guidCursor, nil
 	}
+	cursor := cursorForTime(ts, mode)
+	return &cursor, nil
+}
 
-	return nil, fmt.Errorf("invalid time expression: %s", expression)
+// ParseTimeBound resolves a --since/--until value to a unix timestamp. It
+// accepts a duration back from now (30m, 24h, 7d, 2w), today/yesterday, an
+// RFC3339 timestamp or YYYY-MM-DD date, or a message GUID (its timestamp).
+func ParseTimeBound(db *sql.DB, expression string) (int64, error) {
+	ts, _, err := resolveTimeExpression(db, expression)
+	if err != nil {
+		return 0, err
+	}
+	return ts.Unix(), nil
 }
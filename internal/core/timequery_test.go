@@ -0,0 +1,75 @@
+package core
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestParseTimeBound(t *testing.T) {
+	dbConn, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer dbConn.Close()
+	if _, err := dbConn.Exec(`CREATE TABLE fray_messages (guid TEXT PRIMARY KEY, ts INTEGER NOT NULL)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if _, err := dbConn.Exec(`INSERT INTO fray_messages (guid, ts) VALUES ('msg-abcd1234', 1700000000)`); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	now := time.Now().Unix()
+	near := func(got, want int64) bool { return got >= want-5 && got <= want+5 }
+
+	cases := []struct {
+		expr  string
+		check func(int64) bool
+	}{
+		{"24h", func(ts int64) bool { return near(ts, now-86400) }},
+		{"7d", func(ts int64) bool { return near(ts, now-7*86400) }},
+		{"2026-01-02T03:04:05Z", func(ts int64) bool { return ts == time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Unix() }},
+		{"2026-01-02", func(ts int64) bool { return ts == time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local).Unix() }},
+		{"msg-abcd1234", func(ts int64) bool { return ts == 1700000000 }},
+		{"abcd", func(ts int64) bool { return ts == 1700000000 }},
+	}
+	for _, tc := range cases {
+		got, err := ParseTimeBound(dbConn, tc.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+		if !tc.check(got) {
+			t.Errorf("%s: unexpected timestamp %d", tc.expr, got)
+		}
+	}
+
+	for _, expr := range []string{"", "soon", "msg-missing"} {
+		if _, err := ParseTimeBound(dbConn, expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestParseTimeExpressionPrefersDurations(t *testing.T) {
+	dbConn, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer dbConn.Close()
+	if _, err := dbConn.Exec(`CREATE TABLE fray_messages (guid TEXT PRIMARY KEY, ts INTEGER NOT NULL)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	// No message matches "24h", so reading it as a GUID prefix would fail.
+	cursor, err := ParseTimeExpression(dbConn, "24h", "since")
+	if err != nil {
+		t.Fatalf("24h: %v", err)
+	}
+	want := time.Now().Add(-24 * time.Hour).Unix()
+	if cursor.TS < want-5 || cursor.TS > want+5 || cursor.GUID != "zzzzzzzz" {
+		t.Fatalf("unexpected cursor %+v", cursor)
+	}
+}
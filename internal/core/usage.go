@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// ModelRate is a model's price in USD per million tokens. Cache rates are
+// optional; prompt-cache tokens of a model without them are left unpriced.
+type ModelRate struct {
+	Input      float64  `json:"input"`
+	Output     float64  `json:"output"`
+	CacheRead  *float64 `json:"cache_read,omitempty"`
+	CacheWrite *float64 `json:"cache_write,omitempty"`
+}
+
+// ParseModelRate parses "<input>,<output>[,<cache_read>,<cache_write>]" in
+// USD per million tokens (e.g. "3,15" or "3,15,0.3,3.75").
+func ParseModelRate(value string) (ModelRate, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 && len(parts) != 4 {
+		return ModelRate{}, fmt.Errorf("model rate must be <input>,<output>[,<cache_read>,<cache_write>] USD per million tokens (e.g. 3,15)")
+	}
+	names := []string{"input", "output", "cache read", "cache write"}
+	rates := make([]float64, len(parts))
+	for i, part := range parts {
+		rate, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || rate < 0 {
+			return ModelRate{}, fmt.Errorf("invalid %s rate %q", names[i], strings.TrimSpace(part))
+		}
+		rates[i] = rate
+	}
+	rate := ModelRate{Input: rates[0], Output: rates[1]}
+	if len(rates) == 4 {
+		rate.CacheRead, rate.CacheWrite = &rates[2], &rates[3]
+	}
+	return rate, nil
+}
+
+// Cost returns the estimated USD cost of a session's tokens, and how many of
+// its cache tokens had no rate to price them.
+func (r ModelRate) Cost(usage types.TokenUsage) (cost float64, unpricedCache int64) {
+	cost = float64(usage.InputTokens)*r.Input + float64(usage.OutputTokens)*r.Output
+	if r.CacheRead != nil {
+		cost += float64(usage.CacheReadTokens) * *r.CacheRead
+	} else {
+		unpricedCache += usage.CacheReadTokens
+	}
+	if r.CacheWrite != nil {
+		cost += float64(usage.CacheWriteTokens) * *r.CacheWrite
+	} else {
+		unpricedCache += usage.CacheWriteTokens
+	}
+	return cost / 1_000_000, unpricedCache
+}
+
+// UsageRow is one agent's token usage on one local calendar day.
+type UsageRow struct {
+	Date         string  `json:"date"`
+	AgentID      string  `json:"agent_id"`
+	Sessions     int     `json:"sessions"`
+	InputTokens  int64   `json:"input_tokens"`
+	CacheTokens  int64   `json:"cache_tokens,omitempty"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"estimated_cost_usd"`
+	// Unpriced counts sessions whose model has no configured rate; their
+	// tokens are in the totals but not in Cost.
+	Unpriced int `json:"unpriced_sessions,omitempty"`
+	// UnpricedCache counts cache tokens of priced sessions whose rate has no
+	// cache prices.
+	UnpricedCache int64 `json:"unpriced_cache_tokens,omitempty"`
+}
+
+// SummarizeUsage totals usage per agent per day (dated in loc), pricing each
+// session by rates[model]. Rows are ordered by day, then agent.
+func SummarizeUsage(usage []types.TokenUsage, rates map[string]ModelRate, loc *time.Location) []UsageRow {
+	if loc == nil {
+		loc = time.Local
+	}
+	type key struct{ date, agent string }
+	rows := map[key]*UsageRow{}
+	for _, entry := range usage {
+		k := key{time.Unix(entry.RecordedAt, 0).In(loc).Format("2006-01-02"), entry.AgentID}
+		row := rows[k]
+		if row == nil {
+			row = &UsageRow{Date: k.date, AgentID: k.agent}
+			rows[k] = row
+		}
+		row.Sessions++
+		row.InputTokens += entry.InputTokens
+		row.CacheTokens += entry.CacheReadTokens + entry.CacheWriteTokens
+		row.OutputTokens += entry.OutputTokens
+		if rate, ok := rates[entry.Model]; ok {
+			cost, unpricedCache := rate.Cost(entry)
+			row.Cost += cost
+			row.UnpricedCache += unpricedCache
+		} else {
+			row.Unpriced++
+		}
+	}
+
+	result := make([]UsageRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date < result[j].Date
+		}
+		return result[i].AgentID < result[j].AgentID
+	})
+	return result
+}
+
+// TotalUsage sums usage rows into one row with no date or agent.
+func TotalUsage(rows []UsageRow) UsageRow {
+	var total UsageRow
+	for _, row := range rows {
+		total.Sessions += row.Sessions
+		total.InputTokens += row.InputTokens
+		total.CacheTokens += row.CacheTokens
+		total.OutputTokens += row.OutputTokens
+		total.Cost += row.Cost
+		total.Unpriced += row.Unpriced
+		total.UnpricedCache += row.UnpricedCache
+	}
+	return total
+}
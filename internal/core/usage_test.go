@@ -0,0 +1,87 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestParseModelRate(t *testing.T) {
+	rate, err := ParseModelRate(" 3, 15 ")
+	if err != nil || rate.Input != 3 || rate.Output != 15 {
+		t.Fatalf("got %+v, %v", rate, err)
+	}
+	if rate.CacheRead != nil || rate.CacheWrite != nil {
+		t.Fatalf("expected no cache rates, got %+v", rate)
+	}
+	rate, err = ParseModelRate("3,15,0.3,3.75")
+	if err != nil || rate.CacheRead == nil || *rate.CacheRead != 0.3 || rate.CacheWrite == nil || *rate.CacheWrite != 3.75 {
+		t.Fatalf("got %+v, %v", rate, err)
+	}
+	for _, bad := range []string{"3", "a,15", "3,-1", "1,2,3", "3,15,x,1"} {
+		if _, err := ParseModelRate(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestSummarizeUsage(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC).Unix()
+	day2 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC).Unix()
+	usage := []types.TokenUsage{
+		{AgentID: "pm", Model: "sonnet", InputTokens: 1_000_000, OutputTokens: 100_000, RecordedAt: day1},
+		{AgentID: "dev", Model: "sonnet", InputTokens: 2_000_000, OutputTokens: 200_000, RecordedAt: day1},
+		{AgentID: "dev", Model: "opus", InputTokens: 500_000, OutputTokens: 10_000, RecordedAt: day1 + 60},
+		{AgentID: "dev", Model: "mystery", InputTokens: 10, OutputTokens: 5, RecordedAt: day2},
+	}
+	rates := map[string]ModelRate{"sonnet": {Input: 3, Output: 15}, "opus": {Input: 15, Output: 75}}
+
+	rows := SummarizeUsage(usage, rates, time.UTC)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", rows)
+	}
+	dev := rows[0]
+	if dev.Date != "2026-03-01" || dev.AgentID != "dev" || dev.Sessions != 2 || dev.InputTokens != 2_500_000 || dev.OutputTokens != 210_000 {
+		t.Fatalf("unexpected dev row: %+v", dev)
+	}
+	// sonnet: 2M*3 + 0.2M*15 = 9; opus: 0.5M*15 + 0.01M*75 = 8.25
+	if math.Abs(dev.Cost-17.25) > 1e-9 {
+		t.Fatalf("expected dev cost 17.25, got %v", dev.Cost)
+	}
+	if rows[1].AgentID != "pm" || math.Abs(rows[1].Cost-4.5) > 1e-9 {
+		t.Fatalf("unexpected pm row: %+v", rows[1])
+	}
+	if rows[2].Date != "2026-03-02" || rows[2].Unpriced != 1 || rows[2].Cost != 0 {
+		t.Fatalf("expected an unpriced row, got %+v", rows[2])
+	}
+
+	total := TotalUsage(rows)
+	if total.Sessions != 4 || total.InputTokens != 3_500_010 || math.Abs(total.Cost-21.75) > 1e-9 || total.Unpriced != 1 {
+		t.Fatalf("unexpected total: %+v", total)
+	}
+}
+
+func TestSummarizeUsagePricesCacheSeparately(t *testing.T) {
+	day := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC).Unix()
+	usage := []types.TokenUsage{
+		{AgentID: "dev", Model: "sonnet", InputTokens: 1_000_000, CacheReadTokens: 10_000_000, CacheWriteTokens: 1_000_000, RecordedAt: day},
+		{AgentID: "pm", Model: "plain", InputTokens: 1_000_000, CacheReadTokens: 10_000_000, RecordedAt: day},
+	}
+	cacheRead, cacheWrite := 0.3, 3.75
+	rates := map[string]ModelRate{
+		"sonnet": {Input: 3, Output: 15, CacheRead: &cacheRead, CacheWrite: &cacheWrite},
+		"plain":  {Input: 3, Output: 15},
+	}
+
+	rows := SummarizeUsage(usage, rates, time.UTC)
+	// 1M*3 + 10M*0.3 + 1M*3.75 = 9.75
+	if rows[0].AgentID != "dev" || math.Abs(rows[0].Cost-9.75) > 1e-9 || rows[0].CacheTokens != 11_000_000 || rows[0].UnpricedCache != 0 {
+		t.Fatalf("unexpected dev row: %+v", rows[0])
+	}
+	// Without cache rates, cache reads are not billed at the input rate.
+	if rows[1].AgentID != "pm" || math.Abs(rows[1].Cost-3) > 1e-9 || rows[1].UnpricedCache != 10_000_000 {
+		t.Fatalf("unexpected pm row: %+v", rows[1])
+	}
+}
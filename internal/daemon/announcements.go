@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// checkAnnouncementReminders re-mentions agents who haven't acked an
+// ack-required announcement once announce_reminder_minutes have passed.
+// Each announcement gets at most one reminder.
+func (d *Daemon) checkAnnouncementReminders() {
+	value, err := db.GetConfig(d.database, db.AnnounceReminderConfigKey)
+	if err != nil || strings.TrimSpace(value) == "" {
+		return
+	}
+	interval, err := db.ParseAnnounceReminder(value)
+	if err != nil || interval == 0 {
+		return
+	}
+
+	now := time.Now()
+	announcements, err := db.GetUnremindedAnnouncements(d.database, now.Add(-interval).Unix())
+	if err != nil {
+		d.debugf("announcements: error getting announcements: %v", err)
+		return
+	}
+	for _, announcement := range announcements {
+		if err := d.remindAnnouncement(announcement, now.Unix()); err != nil {
+			d.debugf("announcements: reminder for %s: %v", announcement.MessageGUID, err)
+		}
+	}
+}
+
+// remindAnnouncement posts one reminder mentioning the agents still
+// missing from an announcement's acks and records that it went out.
+func (d *Daemon) remindAnnouncement(announcement types.Announcement, now int64) error {
+	acks, err := db.GetAnnouncementAcks(d.database, announcement)
+	if err != nil {
+		return err
+	}
+	pending := db.PendingAnnouncementAcks(announcement, acks)
+	if len(pending) == 0 {
+		return nil
+	}
+
+	mentions := make([]string, 0, len(pending))
+	for _, agentID := range pending {
+		mentions = append(mentions, "@"+agentID)
+	}
+	announced := announcement.MessageGUID
+	reminder, err := db.CreateMessage(d.database, types.Message{
+		TS:        now,
+		Home:      "room",
+		FromAgent: "system",
+		Body:      fmt.Sprintf("%s reminder: please ack #%s (fray ack %s, or react %s)", strings.Join(mentions, " "), announced, announced, db.AnnouncementAckReaction),
+		Mentions:  pending,
+		Type:      types.MessageTypeEvent,
+		ReplyTo:   &announced,
+	})
+	if err != nil {
+		return err
+	}
+	if err := db.AppendMessage(d.project.DBPath, reminder); err != nil {
+		return err
+	}
+
+	if err := db.MarkAnnouncementReminded(d.database, announced, now); err != nil {
+		return err
+	}
+	d.debugf("announcements: reminded %s about %s", strings.Join(mentions, " "), announced)
+	return db.AppendAnnouncementReminder(d.project.DBPath, db.AnnouncementReminderJSONLRecord{
+		MessageGUID: announced,
+		Agents:      pending,
+		RemindedAt:  now,
+	})
+}
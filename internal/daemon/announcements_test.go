@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestAnnouncementReminderFiresOnce(t *testing.T) {
+	h := newTestHarness(t)
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+
+	announced := h.postMessage("pm", "@all rebase onto main", types.MessageTypeAgent)
+	announcement := types.Announcement{
+		MessageGUID: announced.ID,
+		FromAgent:   "pm",
+		AckRequired: true,
+		Audience:    []string{"dev", "qa", "ops"},
+		CreatedAt:   time.Now().Add(-45 * time.Minute).Unix(),
+	}
+	if err := db.CreateAnnouncement(h.db, announcement); err != nil {
+		t.Fatalf("create announcement: %v", err)
+	}
+	if err := db.AppendAnnouncement(h.projectPath, announcement); err != nil {
+		t.Fatalf("append announcement: %v", err)
+	}
+	if _, err := db.AckAnnouncement(h.db, types.AnnouncementAck{MessageGUID: announced.ID, AgentID: "dev", Source: db.AckSourceCommand, AckedAt: time.Now().Unix()}); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	h.react(announced.ID, "qa", db.AnnouncementAckReaction, time.Now().UnixMilli())
+
+	reminders := func() []types.Message {
+		t.Helper()
+		messages, err := db.GetMessages(h.db, &types.MessageQueryOptions{})
+		if err != nil {
+			t.Fatalf("get messages: %v", err)
+		}
+		var found []types.Message
+		for _, msg := range messages {
+			if msg.FromAgent == "system" && msg.ReplyTo != nil && *msg.ReplyTo == announced.ID {
+				found = append(found, msg)
+			}
+		}
+		return found
+	}
+
+	d.checkAnnouncementReminders()
+	if got := reminders(); len(got) != 0 {
+		t.Fatalf("expected no reminder while announce_reminder_minutes is unset, got %+v", got)
+	}
+
+	if err := db.SetConfig(h.db, db.AnnounceReminderConfigKey, "60"); err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+	d.checkAnnouncementReminders()
+	if got := reminders(); len(got) != 0 {
+		t.Fatalf("expected no reminder before the interval, got %+v", got)
+	}
+
+	if err := db.SetConfig(h.db, db.AnnounceReminderConfigKey, "30"); err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+	d.checkAnnouncementReminders()
+	d.checkAnnouncementReminders()
+	got := reminders()
+	if len(got) != 1 {
+		t.Fatalf("expected exactly one reminder, got %d", len(got))
+	}
+	if !reflect.DeepEqual(got[0].Mentions, []string{"ops"}) || !strings.HasPrefix(got[0].Body, "@ops reminder") {
+		t.Fatalf("expected only the non-acker to be reminded, got %+v", got[0])
+	}
+
+	stored, err := db.GetAnnouncement(h.db, announced.ID)
+	if err != nil || stored == nil || stored.RemindedAt == nil {
+		t.Fatalf("expected reminded_at to be set, got %+v (%v)", stored, err)
+	}
+	announcements, _, err := db.ReadAnnouncements(h.projectPath)
+	if err != nil {
+		t.Fatalf("read announcements: %v", err)
+	}
+	if len(announcements) != 1 || announcements[0].RemindedAt == nil {
+		t.Fatalf("expected the reminder in JSONL, got %+v", announcements)
+	}
+}
@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+const (
+	// autoQuestionsConfigKey opts a project in to questions detected from
+	// plain "@agent ...?" posts.
+	autoQuestionsConfigKey = "auto_questions"
+	// autoQuestionsWatermarkKey is the timestamp of the last message scanned.
+	autoQuestionsWatermarkKey = "auto_questions_watermark"
+	// noQuestionMarker at the end of a post keeps it from becoming a question.
+	noQuestionMarker = "(no-q)"
+)
+
+// detectMentionQuestion returns the agent a post asks a question of: the body
+// opens with a single @mention and ends with "?". Posts addressed to several
+// agents, to @all, FYIs, and posts ending in (no-q) are not questions.
+func detectMentionQuestion(body string) (string, bool) {
+	body = strings.TrimSpace(body)
+	if strings.HasSuffix(strings.ToLower(body), noQuestionMarker) {
+		return "", false
+	}
+	if !strings.HasSuffix(body, "?") || !strings.HasPrefix(body, "@") {
+		return "", false
+	}
+
+	var mentions []string
+	words := strings.Fields(body)
+	for _, word := range words {
+		if !strings.HasPrefix(word, "@") {
+			break
+		}
+		if mention := strings.TrimRight(strings.TrimPrefix(word, "@"), ".,;:!?"); mention != "" {
+			mentions = append(mentions, mention)
+		}
+	}
+	// The question needs words of its own beyond the @-block.
+	if len(mentions) != 1 || len(mentions) == len(words) {
+		return "", false
+	}
+	if mentions[0] == "all" {
+		return "", false
+	}
+	return mentions[0], true
+}
+
+// checkAutoQuestions turns new posts that ask a single agent something into
+// open questions, so they show up in the answer flow like fray ask questions.
+func (d *Daemon) checkAutoQuestions() {
+	if !d.configEnabled(autoQuestionsConfigKey) {
+		return
+	}
+
+	d.autoQMu.Lock()
+	defer d.autoQMu.Unlock()
+
+	watermark, err := db.GetConfig(d.database, autoQuestionsWatermarkKey)
+	if err != nil {
+		d.debugf("auto questions: error reading watermark: %v", err)
+		return
+	}
+	if watermark == "" {
+		// First run: only pick up questions posted from now on.
+		cursor, err := db.GetLastMessageCursor(d.database)
+		if err != nil || cursor == nil {
+			return
+		}
+		db.SetConfig(d.database, autoQuestionsWatermarkKey, strconv.FormatInt(cursor.TS, 10))
+		return
+	}
+	sinceTS, err := strconv.ParseInt(watermark, 10, 64)
+	if err != nil {
+		d.debugf("auto questions: invalid watermark %q: %v", watermark, err)
+		return
+	}
+
+	// Messages sharing the watermark second are rescanned; the per-message
+	// dedup in ensureMentionQuestion makes that harmless.
+	allHomes := ""
+	messages, err := db.GetMessages(d.database, &types.MessageQueryOptions{
+		Since: &types.MessageCursor{TS: sinceTS},
+		Home:  &allHomes,
+	})
+	if err != nil {
+		d.debugf("auto questions: error getting messages: %v", err)
+		return
+	}
+
+	for _, msg := range messages {
+		if msg.Type == types.MessageTypeEvent || msg.FromAgent == "system" {
+			continue
+		}
+		if _, err := d.ensureMentionQuestion(msg); err != nil {
+			d.debugf("auto questions: %s: %v", msg.ID, err)
+		}
+	}
+	if len(messages) > 0 {
+		last := strconv.FormatInt(messages[len(messages)-1].TS, 10)
+		if err := db.SetConfig(d.database, autoQuestionsWatermarkKey, last); err != nil {
+			d.debugf("auto questions: error saving watermark: %v", err)
+		}
+	}
+}
+
+// ensureMentionQuestion records msg as a question when it asks a known agent
+// or human. It returns nil when msg is not a question or already has one.
+func (d *Daemon) ensureMentionQuestion(msg types.Message) (*types.Question, error) {
+	toAgent, ok := detectMentionQuestion(msg.Body)
+	if !ok || toAgent == msg.FromAgent {
+		return nil, nil
+	}
+	known, err := d.isQuestionTarget(toAgent)
+	if err != nil || !known {
+		return nil, err
+	}
+
+	msgID := msg.ID
+	existing, err := db.GetQuestions(d.database, &types.QuestionQueryOptions{AskedIn: &msgID})
+	if err != nil || len(existing) > 0 {
+		return nil, err
+	}
+	// fray ask posts "@to <question>" and links the message a moment later,
+	// so match its question by content as well.
+	re := strings.TrimSpace(msg.Body)
+	asked := strings.TrimSpace(strings.TrimPrefix(re, "@"+toAgent))
+	sameRe, err := db.GetQuestionsByRe(d.database, asked)
+	if err != nil {
+		return nil, err
+	}
+	for _, question := range sameRe {
+		if question.FromAgent == msg.FromAgent && question.ToAgent != nil && *question.ToAgent == toAgent {
+			return nil, nil
+		}
+	}
+
+	var threadGUID *string
+	if msg.Home != "" && msg.Home != "room" {
+		home := msg.Home
+		threadGUID = &home
+	}
+	// The question repeats text from a message that already passed (or was
+	// explicitly let past) the content policy.
+	created, err := db.CreateQuestionAllowingSecrets(d.database, types.Question{
+		Re:         re,
+		FromAgent:  msg.FromAgent,
+		ToAgent:    &toAgent,
+		Status:     types.QuestionStatusOpen,
+		ThreadGUID: threadGUID,
+		AskedIn:    &msgID,
+		CreatedAt:  msg.TS,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AppendQuestion(d.project.DBPath, created); err != nil {
+		return nil, err
+	}
+	d.debugf("auto questions: %s asked %s in %s (%s)", msg.FromAgent, toAgent, msg.ID, created.GUID)
+	return &created, nil
+}
+
+// isQuestionTarget reports whether name can be asked a question: a
+// registered agent, or a human at this checkout (fray login), who can never
+// be registered as an agent.
+func (d *Daemon) isQuestionTarget(name string) (bool, error) {
+	agent, err := db.GetAgent(d.database, name)
+	if err != nil || agent != nil {
+		return agent != nil, err
+	}
+	users, err := db.GetActiveUsers(d.database, d.project.DBPath)
+	if err != nil {
+		return false, err
+	}
+	for _, user := range users {
+		if user == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestDetectMentionQuestion(t *testing.T) {
+	cases := []struct {
+		body string
+		want string
+	}{
+		{"@bob which cache should we use?", "bob"},
+		{"  @bob.2, ready to merge?  ", "bob.2"},
+		{"@bob which cache should we use? (no-q)", ""},
+		{"@bob which cache should we use?(NO-Q)", ""},
+		{"@bob which cache should we use.", ""},
+		{"@bob @carol which cache should we use?", ""},
+		{"@all which cache should we use?", ""},
+		{"hey @bob which cache should we use?", ""},
+		{"fyi @bob the cache is in?", ""},
+		{"@bob?", ""},
+	}
+	for _, tc := range cases {
+		got, ok := detectMentionQuestion(tc.body)
+		if ok != (tc.want != "") || got != tc.want {
+			t.Errorf("detectMentionQuestion(%q) = %q, %v; want %q", tc.body, got, ok, tc.want)
+		}
+	}
+}
+
+func (h *testHarness) autoQuestionDaemon() *Daemon {
+	h.t.Helper()
+	h.createAgent("pm", false)
+	h.createAgent("bob", false)
+	if err := db.SetConfig(h.db, autoQuestionsConfigKey, "true"); err != nil {
+		h.t.Fatalf("set config: %v", err)
+	}
+	h.postMessage("pm", "morning all", types.MessageTypeAgent)
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.checkAutoQuestions() // primes the watermark
+	return d
+}
+
+func (h *testHarness) questionsAskedIn(msgID string) []types.Question {
+	h.t.Helper()
+	questions, err := db.GetQuestions(h.db, &types.QuestionQueryOptions{AskedIn: &msgID})
+	if err != nil {
+		h.t.Fatalf("get questions: %v", err)
+	}
+	return questions
+}
+
+func TestAutoQuestionsCreatesQuestionOnce(t *testing.T) {
+	h := newTestHarness(t)
+	d := h.autoQuestionDaemon()
+
+	msg := h.postMessage("pm", "@bob which cache should we use?", types.MessageTypeAgent)
+	d.checkAutoQuestions()
+	d.checkAutoQuestions()
+
+	questions := h.questionsAskedIn(msg.ID)
+	if len(questions) != 1 {
+		t.Fatalf("expected one question, got %d", len(questions))
+	}
+	question := questions[0]
+	if question.Re != msg.Body || question.FromAgent != "pm" || question.ToAgent == nil || *question.ToAgent != "bob" {
+		t.Fatalf("unexpected question %+v", question)
+	}
+	if question.Status != types.QuestionStatusOpen || len(question.Options) != 0 {
+		t.Fatalf("expected an open question without options, got %+v", question)
+	}
+
+	records, err := db.ReadQuestions(h.projectPath)
+	if err != nil {
+		t.Fatalf("read questions: %v", err)
+	}
+	if len(records) != 1 || records[0].GUID != question.GUID {
+		t.Fatalf("expected the question in questions.jsonl, got %+v", records)
+	}
+}
+
+func TestAutoQuestionsAcceptsLoggedInHuman(t *testing.T) {
+	h := newTestHarness(t)
+	d := h.autoQuestionDaemon()
+	if err := db.SetConfigLayer(h.db, h.projectPath, db.ConfigScopeLocal, db.HumanIdentityConfigKey, "adam"); err != nil {
+		t.Fatalf("set identity: %v", err)
+	}
+
+	msg := h.postMessage("pm", "@adam should we use X or Y?", types.MessageTypeAgent)
+	d.checkAutoQuestions()
+
+	questions := h.questionsAskedIn(msg.ID)
+	if len(questions) != 1 || questions[0].ToAgent == nil || *questions[0].ToAgent != "adam" {
+		t.Fatalf("expected a question for the logged-in human, got %+v", questions)
+	}
+}
+
+func TestAutoQuestionsSkipsAskedAndOptedOut(t *testing.T) {
+	h := newTestHarness(t)
+	d := h.autoQuestionDaemon()
+	h.createAgent("carol", false)
+
+	asked, askMsg := h.askQuestion("bob", "which cache should we use?")
+	// fray ask creates its question before the message that carries it.
+	bob := "bob"
+	if _, err := db.CreateQuestion(h.db, types.Question{Re: "is the bench green?", FromAgent: "pm", ToAgent: &bob, Status: types.QuestionStatusOpen}); err != nil {
+		t.Fatalf("create question: %v", err)
+	}
+	unlinked := h.postMessage("pm", "@bob is the bench green?", types.MessageTypeAgent)
+	optOut := h.postMessage("pm", "@bob did you see the benchmark? (no-q)", types.MessageTypeAgent)
+	self := h.postMessage("bob", "@bob note to self?", types.MessageTypeAgent)
+	unknown := h.postMessage("pm", "@nobody are you there?", types.MessageTypeAgent)
+	group := h.postMessage("pm", "@bob @carol are we shipping today?", types.MessageTypeAgent)
+	d.checkAutoQuestions()
+
+	if got := h.questionsAskedIn(askMsg.ID); len(got) != 1 || got[0].GUID != asked.GUID {
+		t.Fatalf("expected only the asked question, got %+v", got)
+	}
+	for _, msg := range []types.Message{unlinked, optOut, self, unknown, group} {
+		if got := h.questionsAskedIn(msg.ID); len(got) != 0 {
+			t.Fatalf("expected no question for %q, got %+v", msg.Body, got)
+		}
+	}
+}
+
+func TestAutoQuestionsDisabledByDefault(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("pm", false)
+	h.createAgent("bob", false)
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+
+	h.postMessage("pm", "morning all", types.MessageTypeAgent)
+	d.checkAutoQuestions()
+	msg := h.postMessage("pm", "@bob which cache should we use?", types.MessageTypeAgent)
+	d.checkAutoQuestions()
+
+	if got := h.questionsAskedIn(msg.ID); len(got) != 0 {
+		t.Fatalf("expected no questions without auto_questions, got %+v", got)
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/adamavenir/fray/internal/core"
@@ -24,27 +23,38 @@ type Daemon struct {
 	database     *sql.DB
 	debouncer    *MentionDebouncer
 	detector     ActivityDetector
-	processes    map[string]*Process // agent_id -> process
-	handled      map[string]bool     // agent_id -> true if exit already handled
-	drivers      map[string]Driver   // driver name -> driver
+	processes    map[string]*Process   // agent_id -> process
+	warm         map[string]*warmEntry // agent_id -> pre-started session (warm_agents)
+	warmRetryAt  map[string]time.Time  // agent_id -> earliest next prestart after a failure
+	handled      map[string]bool       // agent_id -> true if exit already handled
+	drivers      map[string]Driver     // driver name -> driver
 	stopCh       chan struct{}
+	stopOnce     sync.Once
 	cancelFunc   context.CancelFunc // cancels spawned process contexts
 	wg           sync.WaitGroup
-	lockPath     string
+	frayDir      string
+	lease        *Lease
+	takeover     bool
 	pollInterval time.Duration
 	debug        bool
-}
-
-// LockInfo represents the daemon lock file contents.
-type LockInfo struct {
-	PID       int   `json:"pid"`
-	StartedAt int64 `json:"started_at"`
+	logPrefix    string     // "[daemon]" or "[daemon:<project>]" in multi-project mode
+	issueMu      sync.Mutex // serializes auto_thread_issues scans
+	autoQMu      sync.Mutex // serializes auto_questions scans
+	digestMu     sync.Mutex // held while a thread digest pass runs
+	retention    RetentionFunc
+	retentionAt  time.Time // last retention check
 }
 
 // Config holds daemon configuration options.
 type Config struct {
 	PollInterval time.Duration
 	Debug        bool
+	// Name labels log lines when several projects share one process.
+	Name string
+	// Retention applies message retention policies; nil disables it.
+	Retention RetentionFunc
+	// Takeover replaces a fresh lease whose daemon has died (--takeover).
+	Takeover bool
 }
 
 // DefaultConfig returns default daemon configuration.
@@ -60,18 +70,28 @@ func New(project core.Project, database *sql.DB, cfg Config) *Daemon {
 		cfg.PollInterval = DefaultConfig().PollInterval
 	}
 
+	logPrefix := "[daemon]"
+	if cfg.Name != "" {
+		logPrefix = "[daemon:" + cfg.Name + "]"
+	}
+
 	d := &Daemon{
 		project:      project,
 		database:     database,
 		debouncer:    NewMentionDebouncer(database, project.DBPath),
 		detector:     NewActivityDetector(),
 		processes:    make(map[string]*Process),
+		warm:         make(map[string]*warmEntry),
+		warmRetryAt:  make(map[string]time.Time),
 		handled:      make(map[string]bool),
 		drivers:      make(map[string]Driver),
 		stopCh:       make(chan struct{}),
-		lockPath:     filepath.Join(filepath.Dir(project.DBPath), "daemon.lock"),
+		frayDir:      filepath.Dir(project.DBPath),
+		takeover:     cfg.Takeover,
 		pollInterval: cfg.PollInterval,
 		debug:        cfg.Debug,
+		logPrefix:    logPrefix,
+		retention:    cfg.Retention,
 	}
 
 	// Register drivers
@@ -84,25 +104,55 @@ func New(project core.Project, database *sql.DB, cfg Config) *Daemon {
 
 // Start begins the daemon watch loop.
 func (d *Daemon) Start(ctx context.Context) error {
-	// Acquire lock
-	if err := d.acquireLock(); err != nil {
-		return fmt.Errorf("acquire lock: %w", err)
+	procCtx, err := d.begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	d.wg.Add(1)
+	go d.watchLoop(procCtx)
+
+	return nil
+}
+
+// begin takes the project lease and returns the context spawned processes run
+// under. Callers then drive poll themselves or via watchLoop.
+func (d *Daemon) begin(ctx context.Context) (context.Context, error) {
+	lease, err := AcquireLease(d.frayDir, d.takeover)
+	if err != nil {
+		return nil, fmt.Errorf("acquire lease: %w", err)
 	}
+	if err := d.recoverPendingWork(); err != nil {
+		_ = ReleaseLease(d.frayDir, lease)
+		return nil, fmt.Errorf("recover pending work: %w", err)
+	}
+	d.lease = lease
 
 	// Create cancellable context for spawned processes
 	procCtx, cancel := context.WithCancel(ctx)
 	d.cancelFunc = cancel
+	return procCtx, nil
+}
 
-	d.wg.Add(1)
-	go d.watchLoop(procCtx)
+// halt signals the watch loop to stop. Safe to call more than once.
+func (d *Daemon) halt() {
+	d.stopOnce.Do(func() { close(d.stopCh) })
+}
 
-	return nil
+// stopped reports whether the daemon has been told to stop.
+func (d *Daemon) stopped() bool {
+	select {
+	case <-d.stopCh:
+		return true
+	default:
+		return false
+	}
 }
 
 // Stop gracefully shuts down the daemon.
 func (d *Daemon) Stop() error {
 	// Signal watch loop to stop
-	close(d.stopCh)
+	d.halt()
 
 	// Cancel process contexts - this kills spawned processes via CommandContext,
 	// allowing monitorProcess goroutines to exit
@@ -128,45 +178,32 @@ func (d *Daemon) Stop() error {
 	d.handled = make(map[string]bool)
 	d.mu.Unlock()
 
-	// Release lock
-	return d.releaseLock()
-}
+	d.stopWarmPool()
 
-// acquireLock creates the lock file, detecting stale locks.
-func (d *Daemon) acquireLock() error {
-	// Check for existing lock
-	if data, err := os.ReadFile(d.lockPath); err == nil {
-		var info LockInfo
-		if json.Unmarshal(data, &info) == nil {
-			// Check if process is still running using syscall.Kill with signal 0
-			if syscall.Kill(info.PID, 0) == nil {
-				return fmt.Errorf("daemon already running (pid %d)", info.PID)
-			}
-			// Stale lock, remove it
-		}
+	// Release lease
+	if d.lease == nil {
+		return nil
 	}
+	return ReleaseLease(d.frayDir, d.lease)
+}
 
-	// Write new lock
-	info := LockInfo{
-		PID:       os.Getpid(),
-		StartedAt: time.Now().Unix(),
+// refreshLease rewrites the lease heartbeat once LeaseRefreshInterval has
+// passed. Losing the lease to another daemon stops this one, so the two
+// never spawn agents side by side.
+func (d *Daemon) refreshLease() {
+	if d.lease == nil || time.Since(time.Unix(d.lease.Heartbeat, 0)) < LeaseRefreshInterval {
+		return
 	}
-	data, err := json.Marshal(info)
-	if err != nil {
-		return err
+	if err := RefreshLease(d.frayDir, d.lease); err != nil {
+		fmt.Fprintf(os.Stderr, "%s Error: %v; stopping\n", d.logPrefix, err)
+		d.halt()
 	}
-	return os.WriteFile(d.lockPath, data, 0600)
-}
-
-// releaseLock removes the lock file.
-func (d *Daemon) releaseLock() error {
-	return os.Remove(d.lockPath)
 }
 
 // debugf logs a debug message if debug mode is enabled.
 func (d *Daemon) debugf(format string, args ...any) {
 	if d.debug {
-		fmt.Fprintf(os.Stderr, "[daemon] "+format+"\n", args...)
+		fmt.Fprintf(os.Stderr, d.logPrefix+" "+format+"\n", args...)
 	}
 }
 
@@ -189,22 +226,10 @@ func isSchemaError(err error) bool {
 		strings.Contains(msg, "has no column")
 }
 
-// IsLocked returns true if a daemon is currently running.
+// IsLocked returns true if a daemon currently holds the project's lease.
 func IsLocked(frayDir string) bool {
-	lockPath := filepath.Join(frayDir, "daemon.lock")
-	data, err := os.ReadFile(lockPath)
-	if err != nil {
-		return false
-	}
-
-	var info LockInfo
-	if json.Unmarshal(data, &info) != nil {
-		return false
-	}
-
-	// Use syscall.Kill with signal 0 to check if process exists
-	// This is more reliable than proc.Signal(nil) on macOS
-	return syscall.Kill(info.PID, 0) == nil
+	lease, err := ReadLease(frayDir)
+	return err == nil && lease != nil && lease.Held(time.Now())
 }
 
 // watchLoop is the main daemon loop.
@@ -228,16 +253,39 @@ func (d *Daemon) watchLoop(ctx context.Context) {
 
 // poll checks for new mentions and updates process states.
 func (d *Daemon) poll(ctx context.Context) {
+	d.refreshLease()
+	if d.stopped() {
+		return
+	}
+
+	// Issue threads apply to everyone's posts, managed agents or not
+	d.checkIssueRefs()
+
+	// Refresh due digests for threads that opted in
+	d.checkDigests(ctx)
+
+	// Recipients' ✅/👎 reactions settle the questions they were asked
+	d.checkQuestionReactions()
+
+	// Direct "@agent ...?" posts become questions when auto_questions is on
+	d.checkAutoQuestions()
+
+	// Re-mention agents who haven't acked an announcement, once
+	d.checkAnnouncementReminders()
+
+	// Apply retention policies once they are due
+	d.checkRetention()
+
 	// Get managed agents
 	agents, err := d.getManagedAgents()
 	if err != nil {
 		d.debugf("poll: error getting managed agents: %v", err)
 		// Check for schema errors
 		if isSchemaError(err) {
-			fmt.Fprintf(os.Stderr, "Error: database schema mismatch. Run 'fray rebuild' to fix.\n")
-			fmt.Fprintf(os.Stderr, "Details: %v\n", err)
+			fmt.Fprintf(os.Stderr, "%s Error: database schema mismatch. Run 'fray rebuild' to fix.\n", d.logPrefix)
+			fmt.Fprintf(os.Stderr, "%s Details: %v\n", d.logPrefix, err)
 			// Signal stop - can't continue with schema errors
-			close(d.stopCh)
+			d.halt()
 		}
 		return
 	}
@@ -251,34 +299,32 @@ func (d *Daemon) poll(ctx context.Context) {
 
 	// Check for new mentions for each managed agent
 	for _, agent := range agents {
+		if isRetiredSub(agent) {
+			continue
+		}
 		d.checkMentions(ctx, agent)
 	}
 
+	// Link spawned sessions' first posts to the mentions that woke them
+	d.checkSessionResponses()
+
 	// Update presence for running processes
 	d.updatePresence()
+
+	// Keep warm_agents sessions pre-started
+	d.refillWarmPool(ctx, agents)
 }
 
 // getManagedAgents returns all agents with managed=true.
 func (d *Daemon) getManagedAgents() ([]types.Agent, error) {
-	allAgents, err := db.GetAllAgents(d.database)
-	if err != nil {
-		return nil, err
-	}
-
-	var managed []types.Agent
-	for _, agent := range allAgents {
-		if agent.Managed {
-			managed = append(managed, agent)
-		}
-	}
-	return managed, nil
+	return db.GetManagedAgents(d.database)
 }
 
 // checkMentions looks for new @mentions of an agent.
 func (d *Daemon) checkMentions(ctx context.Context, agent types.Agent) {
 	// Get messages mentioning this agent since watermark
 	watermark := d.debouncer.GetWatermark(agent.AgentID)
-	messages, err := d.getMessagesAfter(watermark, agent.AgentID)
+	messages, err := d.getMessagesAfter(watermark, agent)
 	if err != nil {
 		d.debugf("  @%s: error getting messages: %v", agent.AgentID, err)
 		return
@@ -294,38 +340,10 @@ func (d *Daemon) checkMentions(ctx context.Context, agent types.Agent) {
 	var lastProcessedID string
 
 	for _, msg := range messages {
-		// Skip self-mentions - only advance watermark if we haven't queued anything.
-		// Once we queue, we can't advance past queued messages (they'd be lost on restart).
-		if IsSelfMention(msg, agent.AgentID) {
-			d.debugf("    %s: skip (self-mention)", msg.ID)
-			if !hasQueued && !spawned {
-				lastProcessedID = msg.ID
-			}
-			continue
-		}
-
-		// Check if this is a direct address OR a reply to the agent's message
-		// Direct address: @agent at start of message
-		// Reply to agent: threaded reply to something the agent wrote
-		isDirectAddress := IsDirectAddress(msg, agent.AgentID)
-		isReplyToAgent := IsReplyToAgent(d.database, msg, agent.AgentID)
-
-		if !isDirectAddress && !isReplyToAgent {
-			d.debugf("    %s: skip (not direct address or reply) - body: %q", msg.ID, truncate(msg.Body, 50))
-			if !hasQueued && !spawned {
-				lastProcessedID = msg.ID
-			}
-			continue
-		}
-
-		// Check thread ownership - only human or thread owner can trigger spawn
-		var thread *types.Thread
-		if msg.Home != "" && msg.Home != "room" {
-			thread, _ = db.GetThread(d.database, msg.Home)
-		}
-		if !CanTriggerSpawn(msg, thread) {
-			isHuman := msg.Type == types.MessageTypeUser
-			d.debugf("    %s: skip (ownership check failed) - from: %s, type: %s, isHuman: %v", msg.ID, msg.FromAgent, msg.Type, isHuman)
+		// Skipped messages only advance the watermark if we haven't queued
+		// anything; the watermark never moves past a queued message.
+		if reason := d.mentionSkipReason(agent, msg); reason != "" {
+			d.debugf("    %s: skip (%s)", msg.ID, reason)
 			if !hasQueued && !spawned {
 				lastProcessedID = msg.ID
 			}
@@ -344,10 +362,11 @@ func (d *Daemon) checkMentions(ctx context.Context, agent types.Agent) {
 			agent.Presence = currentAgent.Presence
 		}
 
-		// If we already spawned this poll, or agent is busy, queue the mention
-		// Note: Don't advance watermark for queued messages - pending is in-memory,
-		// so on restart we need to re-query and re-queue them
-		if spawned || agent.Presence == types.PresenceSpawning || agent.Presence == types.PresenceActive {
+		// If we already spawned this poll, or agent is busy (including a fresh
+		// working ping), queue the mention.
+		// Don't advance the watermark past queued messages, so a restart
+		// re-queries them even if the persisted queue is lost.
+		if spawned || agent.Presence == types.PresenceSpawning || agent.Presence == types.PresenceActive || d.debouncer.IsWorking(agent.AgentID) {
 			d.debugf("    %s: queued (agent busy or already spawned)", msg.ID)
 			d.debouncer.QueueMention(agent.AgentID, msg.ID)
 			hasQueued = true
@@ -377,21 +396,69 @@ func (d *Daemon) checkMentions(ctx context.Context, agent types.Agent) {
 	}
 }
 
+// mentionSkipReason applies the wake rules to one message. It returns "" when
+// msg should wake agent, otherwise why it should not.
+func (d *Daemon) mentionSkipReason(agent types.Agent, msg types.Message) string {
+	if IsSelfMention(msg, agent.AgentID) {
+		return "self-mention"
+	}
+
+	var thread *types.Thread
+	if msg.Home != "" && msg.Home != "room" {
+		thread, _ = db.GetThread(d.database, msg.Home)
+	}
+
+	// Check if this is a direct address OR a reply to the agent's message
+	// Direct address: @agent at start of message, or any dm to the agent
+	// Reply to agent: threaded reply to something the agent wrote
+	isDirectAddress := IsDirectAddress(msg, agent.AgentID) || IsDMToAgent(msg, thread, agent.AgentID)
+	isReplyToAgent := IsReplyToAgent(d.database, msg, agent.AgentID)
+	if !isDirectAddress && !isReplyToAgent {
+		return fmt.Sprintf("not direct address or reply - body: %q", truncate(msg.Body, 50))
+	}
+
+	// Digest-level followers read the thread on their own schedule; the
+	// thread never wakes them, not even for a direct address.
+	if thread != nil {
+		if sub, _ := db.GetThreadSubscription(d.database, thread.GUID, agent.AgentID); sub != nil && sub.Level == types.SubscriptionDigest {
+			return "digest-level follower of " + thread.Name
+		}
+	}
+
+	// Check thread ownership - only human, thread owner, or dm participant can trigger spawn
+	if !CanTriggerSpawn(msg, thread) {
+		isHuman := msg.Type == types.MessageTypeUser
+		return fmt.Sprintf("ownership check failed - from: %s, type: %s, isHuman: %v", msg.FromAgent, msg.Type, isHuman)
+	}
+	return ""
+}
+
 // getMessagesAfter returns messages mentioning agent after the given watermark.
 // Includes mentions in all threads (not just room) and replies to agent's messages.
-func (d *Daemon) getMessagesAfter(watermark, agentID string) ([]types.Message, error) {
+// With sub_mention_fanout on, a sub-agent also gets mentions of its parent.
+func (d *Daemon) getMessagesAfter(watermark string, agent types.Agent) ([]types.Message, error) {
 	// Empty string means all threads (room + threads)
 	allHomes := ""
 	opts := &types.MessageQueryOptions{
 		Limit:                 100,
 		Home:                  &allHomes,
-		IncludeRepliesToAgent: agentID,
+		IncludeRepliesToAgent: agent.AgentID,
 	}
 	if watermark != "" {
 		opts.SinceID = watermark
 	}
 
-	return db.GetMessagesWithMention(d.database, agentID, opts)
+	messages, err := db.GetMessagesWithMention(d.database, agent.AgentID, opts)
+	if err != nil || agent.ParentAgent == nil || !d.subMentionFanout() {
+		return messages, err
+	}
+	parentOpts := *opts
+	parentOpts.IncludeRepliesToAgent = ""
+	parentMessages, err := db.GetMessagesWithMention(d.database, *agent.ParentAgent, &parentOpts)
+	if err != nil {
+		return nil, err
+	}
+	return mergeMessages(messages, parentMessages), nil
 }
 
 // spawnAgent starts a new session for an agent.
@@ -417,18 +484,39 @@ func (d *Daemon) spawnAgent(ctx context.Context, agent types.Agent, triggerMsgID
 	prompt, allMentions := d.buildWakePrompt(agent, triggerMsgID)
 	d.debugf("  wake prompt includes %d mentions", len(allMentions))
 
-	// Spawn process
-	proc, err := driver.Spawn(ctx, agent, prompt)
-	if err != nil {
-		d.debugf("  spawn error: %v", err)
-		db.UpdateAgentPresence(d.database, agent.AgentID, types.PresenceError)
-		return "", err
+	// Hand the prompt to a warm session if one is ready, else spawn cold
+	proc := d.takeWarm(agent)
+	warmDriver := d.warmDriverFor(agent)
+	if proc != nil {
+		if err := warmDriver.Deliver(proc, prompt); err != nil {
+			d.debugf("  warm delivery failed, spawning cold: %v", err)
+			d.stopWarm(agent.AgentID, &warmEntry{proc: proc})
+			proc = nil
+		} else {
+			proc.StartedAt = time.Now()
+			d.debugf("  delivered to warm pid %d", proc.Cmd.Process.Pid)
+		}
+	}
+	if proc == nil {
+		var err error
+		proc, err = driver.Spawn(ctx, agent, prompt)
+		if err != nil {
+			d.debugf("  spawn error: %v", err)
+			db.UpdateAgentPresence(d.database, agent.AgentID, types.PresenceError)
+			return "", err
+		}
 	}
 
 	d.debugf("  spawned pid %d, session %s", proc.Cmd.Process.Pid, proc.SessionID)
 
 	// Store session ID for future resume - this ensures each agent keeps their own session
 	db.UpdateAgentSessionID(d.database, agent.AgentID, proc.SessionID)
+	proc.TriggeredBy = triggerMsgID
+	// Mark the session as ours, so a restarted daemon only resets presence
+	// it left behind and not sessions started from the CLI.
+	if err := db.SetDaemonSession(d.database, agent.AgentID, proc.SessionID); err != nil {
+		fmt.Fprintf(os.Stderr, "%s @%s: error recording session: %v\n", d.logPrefix, agent.AgentID, err)
+	}
 
 	// Track process
 	d.mu.Lock()
@@ -436,6 +524,9 @@ func (d *Daemon) spawnAgent(ctx context.Context, agent types.Agent, triggerMsgID
 	delete(d.handled, agent.AgentID) // Clear handled flag for new session
 	d.mu.Unlock()
 
+	// Show the agent as working on the message that woke it
+	db.SetAgentWorking(d.database, agent.AgentID, &triggerMsgID, db.WorkingSourceDaemon, time.Now().UnixMilli())
+
 	// Record session start
 	sessionStart := types.SessionStart{
 		AgentID:     agent.AgentID,
@@ -476,6 +567,9 @@ func (d *Daemon) monitorProcess(agentID string, proc *Process) {
 			buf := make([]byte, 4096)
 			for {
 				n, err := proc.Stdout.Read(buf)
+				if n > 0 {
+					proc.output.Write(buf[:n])
+				}
 				if n > 0 && proc.Cmd.Process != nil {
 					d.detector.RecordActivity(proc.Cmd.Process.Pid)
 				}
@@ -507,7 +601,11 @@ func (d *Daemon) monitorProcess(agentID string, proc *Process) {
 	wg.Wait()
 
 	// Wait for process to exit
-	proc.Cmd.Wait()
+	if proc.exited != nil {
+		<-proc.exited
+	} else {
+		proc.Cmd.Wait()
+	}
 
 	// Handle exit
 	d.mu.Lock()
@@ -519,8 +617,12 @@ func (d *Daemon) monitorProcess(agentID string, proc *Process) {
 // Returns the prompt and the list of all msgIDs included.
 func (d *Daemon) buildWakePrompt(agent types.Agent, triggerMsgID string) (string, []string) {
 	// Include any pending mentions
-	pending := d.debouncer.FlushPending(agent.AgentID)
-	allMentions := append([]string{triggerMsgID}, pending...)
+	allMentions := []string{triggerMsgID}
+	for _, msgID := range d.debouncer.FlushPending(agent.AgentID) {
+		if msgID != triggerMsgID {
+			allMentions = append(allMentions, msgID)
+		}
+	}
 
 	// Get min_checkin for the prompt
 	_, _, minCheckin, _ := GetTimeouts(agent.Invoke)
@@ -559,14 +661,32 @@ Trigger messages:
 %s
 
 Run: fray get %s
-
+%s
 ---
 Checkin: Posting to fray resets a %dm timer. Silence = session recycled (resumable on @mention).`,
-		triggerInfo, agent.AgentID, minCheckinMins)
+		triggerInfo, agent.AgentID, d.flyContextSection(agent), minCheckinMins)
 
 	return prompt, allMentions
 }
 
+// flyContextSection embeds the fly-context document for fresh sessions, so a
+// cold start does not need to gather it. Resumed sessions already have it.
+func (d *Daemon) flyContextSection(agent types.Agent) string {
+	if agent.LastSessionID != nil && *agent.LastSessionID != "" {
+		return ""
+	}
+	fly, err := db.GetFlyContext(d.database, d.project.DBPath, agent.AgentID)
+	if err != nil {
+		d.debugf("  fly context for @%s: %v", agent.AgentID, err)
+		return ""
+	}
+	data, err := json.MarshalIndent(fly, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return "\nFly context (same as fray fly-context --as " + agent.AgentID + " --json):\n" + string(data) + "\n"
+}
+
 // updatePresence checks running processes and updates their presence.
 // Implements done-detection: idle presence + no fray posts for min_checkin = kill session.
 func (d *Daemon) updatePresence() {
@@ -583,6 +703,11 @@ func (d *Daemon) updatePresence() {
 		pid := proc.Cmd.Process.Pid
 		if d.detector.IsActive(pid) {
 			db.UpdateAgentPresence(d.database, agentID, types.PresenceActive)
+			d.refreshWorking(agentID, proc)
+		} else if done := d.doneReport(agentID, proc); done != nil {
+			// Agent reported fray done: end the session now instead of
+			// waiting out min_checkin.
+			d.killProcess(agentID, proc, "done report")
 		} else {
 			// Check timeouts
 			agent, _ := db.GetAgent(d.database, agentID)
@@ -663,7 +788,10 @@ func (d *Daemon) handleProcessExit(agentID string, proc *Process) {
 		exitCode = proc.Cmd.ProcessState.ExitCode()
 	}
 
-	// Record session end for audit trail
+	// Catch a reply posted just before exit, between polls
+	d.recordSessionResponse(agentID, proc)
+
+	// Record session end for audit trail, with the outcome if the agent reported done
 	sessionEnd := types.SessionEnd{
 		AgentID:    agentID,
 		SessionID:  proc.SessionID,
@@ -671,7 +799,16 @@ func (d *Daemon) handleProcessExit(agentID string, proc *Process) {
 		DurationMs: time.Since(proc.StartedAt).Milliseconds(),
 		EndedAt:    time.Now().Unix(),
 	}
+	done := d.doneReport(agentID, proc)
+	if done != nil {
+		status, summary := core.ParseDoneBody(done.Body)
+		sessionEnd.Outcome = status
+		if summary != "" {
+			sessionEnd.Summary = &summary
+		}
+	}
 	db.AppendSessionEnd(d.project.DBPath, sessionEnd)
+	d.recordTokenUsage(agentID, proc)
 
 	// Session ID is now stored at spawn time (we generate it ourselves with --session-id)
 	// No need to detect it from Claude's files anymore - see fix for fray-8ld6
@@ -684,7 +821,13 @@ func (d *Daemon) handleProcessExit(agentID string, proc *Process) {
 
 	// Only update presence and remove from map if this is the current process
 	if isCurrentProc {
-		if exitCode == 0 {
+		if done != nil {
+			// A reported done is a clean end even though we killed the process.
+			db.UpdateAgentPresence(d.database, agentID, types.PresenceIdle)
+			if sessionEnd.Outcome == types.DoneStatusBlocked {
+				d.notifyBlocked(agentID, proc, *done)
+			}
+		} else if exitCode == 0 {
 			db.UpdateAgentPresence(d.database, agentID, types.PresenceIdle)
 		} else {
 			db.UpdateAgentPresence(d.database, agentID, types.PresenceError)
@@ -698,10 +841,31 @@ func (d *Daemon) handleProcessExit(agentID string, proc *Process) {
 			LeftAt: types.OptionalInt64{Set: true, Value: &now},
 		})
 
+		db.ClearAgentWorking(d.database, agentID)
+		db.ClearDaemonSession(d.database, agentID)
 		delete(d.processes, agentID)
 	}
 }
 
+// refreshWorking keeps a daemon working ping fresh while the session is
+// producing output. A ping the agent set itself with fray working wins, so
+// its target isn't overwritten.
+func (d *Daemon) refreshWorking(agentID string, proc *Process) {
+	now := time.Now().UnixMilli()
+	current, err := db.GetAgentWorking(d.database, agentID, db.WorkingTTL(d.database), now)
+	if err != nil {
+		return
+	}
+	if current != nil && current.Source == db.WorkingSourceAgent {
+		return
+	}
+	var target *string
+	if proc.TriggeredBy != "" {
+		target = &proc.TriggeredBy
+	}
+	db.SetAgentWorking(d.database, agentID, target, db.WorkingSourceDaemon, now)
+}
+
 // getDriver returns the driver for an agent.
 func (d *Daemon) getDriver(agentID string) Driver {
 	agent, err := db.GetAgent(d.database, agentID)
@@ -710,3 +874,85 @@ func (d *Daemon) getDriver(agentID string) Driver {
 	}
 	return d.drivers[agent.Invoke.Driver]
 }
+
+// doneReport returns the done message the agent posted during this session, if any.
+func (d *Daemon) doneReport(agentID string, proc *Process) *types.Message {
+	done, err := db.GetLatestDoneMessage(d.database, agentID, proc.StartedAt.Unix())
+	if err != nil {
+		d.debugf("  @%s: error checking done report: %v", agentID, err)
+		return nil
+	}
+	return done
+}
+
+// triggerThread returns the thread whose message woke this session, if any.
+func (d *Daemon) triggerThread(proc *Process) *types.Thread {
+	if proc.TriggeredBy == "" {
+		return nil
+	}
+	trigger, err := db.GetMessage(d.database, proc.TriggeredBy)
+	if err != nil || trigger == nil || trigger.Home == "" || trigger.Home == "room" {
+		return nil
+	}
+	thread, _ := db.GetThread(d.database, trigger.Home)
+	return thread
+}
+
+// blockedRecipients picks who hears about a blocked session: the owner of the
+// thread that woke the agent, else agents holding the pm role, else @pm.
+func (d *Daemon) blockedRecipients(agentID string, thread *types.Thread) []string {
+	if thread != nil && thread.OwnerAgent != nil && *thread.OwnerAgent != agentID {
+		return []string{*thread.OwnerAgent}
+	}
+
+	var recipients []string
+	if holders, err := db.GetAgentsByRole(d.database, "pm"); err == nil {
+		for _, holder := range holders {
+			if holder.AgentID != agentID {
+				recipients = append(recipients, holder.AgentID)
+			}
+		}
+	}
+	if len(recipients) > 0 {
+		return recipients
+	}
+	if pm, err := db.GetAgent(d.database, "pm"); err == nil && pm != nil && agentID != "pm" {
+		return []string{"pm"}
+	}
+	return nil
+}
+
+// notifyBlocked mentions the thread owner or pm when a session ends blocked.
+func (d *Daemon) notifyBlocked(agentID string, proc *Process, done types.Message) {
+	recipients := d.blockedRecipients(agentID, d.triggerThread(proc))
+	if len(recipients) == 0 {
+		d.debugf("  @%s: blocked, but no owner or pm to notify", agentID)
+		return
+	}
+
+	_, summary := core.ParseDoneBody(done.Body)
+	var mentions []string
+	for _, recipient := range recipients {
+		mentions = append(mentions, "@"+recipient)
+	}
+	body := fmt.Sprintf("%s @%s is blocked", strings.Join(mentions, " "), agentID)
+	if summary != "" {
+		body += ": " + summary
+	}
+
+	doneID := done.ID
+	notice, err := db.CreateMessage(d.database, types.Message{
+		TS:        time.Now().Unix(),
+		Home:      done.Home,
+		FromAgent: agentID,
+		Body:      body,
+		Mentions:  recipients,
+		Type:      types.MessageTypeEvent,
+		ReplyTo:   &doneID,
+	})
+	if err != nil {
+		d.debugf("  @%s: error posting blocked notice: %v", agentID, err)
+		return
+	}
+	db.AppendMessage(d.project.DBPath, notice)
+}
@@ -1,14 +1,20 @@
 package daemon
 
 import (
+	"context"
+	"fmt"
 	"database/sql"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
 	"github.com/adamavenir/fray/internal/types"
 )
 
@@ -25,53 +31,15 @@ type testHarness struct {
 func newTestHarness(t *testing.T) *testHarness {
 	t.Helper()
 
-	tmpHome := t.TempDir()
-	t.Setenv("HOME", tmpHome)
-
-	projectDir := t.TempDir()
-	frayDir := filepath.Join(projectDir, ".fray")
-	if err := os.MkdirAll(frayDir, 0755); err != nil {
-		t.Fatalf("mkdir .fray: %v", err)
-	}
-
-	// Write minimal config
-	configPath := filepath.Join(frayDir, "fray-config.json")
-	if err := os.WriteFile(configPath, []byte(`{"channel_id":"ch-test","channel_name":"test"}`), 0644); err != nil {
-		t.Fatalf("write config: %v", err)
-	}
-
-	// Create empty JSONL files so DiscoverProject finds the project
-	for _, name := range []string{"messages.jsonl", "agents.jsonl"} {
-		path := filepath.Join(frayDir, name)
-		if err := os.WriteFile(path, []byte{}, 0644); err != nil {
-			t.Fatalf("write %s: %v", name, err)
-		}
-	}
-
-	project, err := core.DiscoverProject(projectDir)
-	if err != nil {
-		t.Fatalf("discover project: %v", err)
-	}
-
-	database, err := db.OpenDatabase(project)
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-
-	if err := db.InitSchema(database); err != nil {
-		t.Fatalf("init schema: %v", err)
-	}
-
-	t.Cleanup(func() {
-		database.Close()
-	})
+	project := testutil.NewProject(t)
+	database := project.OpenDB(t, db.OpenDatabase, db.InitSchema)
 
 	return &testHarness{
 		t:           t,
-		projectDir:  projectDir,
-		projectPath: project.DBPath,
+		projectDir:  project.Root,
+		projectPath: project.Project.DBPath,
 		db:          database,
-		debouncer:   NewMentionDebouncer(database, project.DBPath),
+		debouncer:   NewMentionDebouncer(database, project.Project.DBPath),
 	}
 }
 
@@ -79,19 +47,9 @@ func newTestHarness(t *testing.T) *testHarness {
 func (h *testHarness) createAgent(agentID string, managed bool) types.Agent {
 	h.t.Helper()
 
-	now := time.Now().Unix()
-	agent := types.Agent{
-		AgentID:      agentID,
-		RegisteredAt: now,
-		LastSeen:     now,
-		Managed:      managed,
-		Presence:     types.PresenceOffline,
-	}
+	agent := testutil.NewAgent(agentID)
 	if managed {
-		agent.Invoke = &types.InvokeConfig{
-			Driver:         "claude",
-			PromptDelivery: types.PromptDeliveryStdin,
-		}
+		agent = testutil.NewManagedAgent(agentID)
 	}
 
 	if err := db.CreateAgent(h.db, agent); err != nil {
@@ -108,18 +66,15 @@ func (h *testHarness) createAgent(agentID string, managed bool) types.Agent {
 // postMessage creates a test message.
 func (h *testHarness) postMessage(fromAgent, body string, msgType types.MessageType) types.Message {
 	h.t.Helper()
+	return h.createMessage(testutil.NewMessage(fromAgent, body, msgType))
+}
 
-	msg := types.Message{
-		TS:        time.Now().Unix(),
-		FromAgent: fromAgent,
-		Body:      body,
-		Type:      msgType,
-		Home:      "room",
-	}
+// createMessage fills in mentions and stores msg.
+func (h *testHarness) createMessage(msg types.Message) types.Message {
+	h.t.Helper()
 
-	// Extract mentions
 	bases, _ := db.GetAgentBases(h.db)
-	msg.Mentions = core.ExtractMentions(body, bases)
+	msg.Mentions = core.ExtractMentions(msg.Body, bases)
 
 	created, err := db.CreateMessage(h.db, msg)
 	if err != nil {
@@ -131,24 +86,9 @@ func (h *testHarness) postMessage(fromAgent, body string, msgType types.MessageT
 // postReply creates a reply to an existing message.
 func (h *testHarness) postReply(fromAgent, body, replyTo string, msgType types.MessageType) types.Message {
 	h.t.Helper()
-
-	msg := types.Message{
-		TS:        time.Now().Unix(),
-		FromAgent: fromAgent,
-		Body:      body,
-		Type:      msgType,
-		Home:      "room",
-		ReplyTo:   &replyTo,
-	}
-
-	bases, _ := db.GetAgentBases(h.db)
-	msg.Mentions = core.ExtractMentions(body, bases)
-
-	created, err := db.CreateMessage(h.db, msg)
-	if err != nil {
-		h.t.Fatalf("create reply: %v", err)
-	}
-	return created
+	msg := testutil.NewMessage(fromAgent, body, msgType)
+	msg.ReplyTo = &replyTo
+	return h.createMessage(msg)
 }
 
 // --- Helper Function Tests (Unit-style) ---
@@ -489,7 +429,691 @@ func TestMentionDetection_ChainReplyWakes(t *testing.T) {
 	}
 }
 
+// --- DM Tests ---
+
+// recordingDriver spawns a placeholder process and records who was woken.
+type recordingDriver struct {
+	t       *testing.T
+	spawned []string
+}
+
+func (r *recordingDriver) Name() string { return "claude" }
+
+func (r *recordingDriver) Spawn(ctx context.Context, agent types.Agent, prompt string) (*Process, error) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	r.t.Cleanup(func() { _ = cmd.Process.Kill() })
+	r.spawned = append(r.spawned, agent.AgentID)
+	return &Process{Cmd: cmd, StartedAt: time.Now()}, nil
+}
+
+func (r *recordingDriver) Cleanup(proc *Process) error {
+	return proc.Cmd.Process.Kill()
+}
+
+func TestDMFromAgentTriggersSpawn(t *testing.T) {
+	h := newTestHarness(t)
+	dev := h.createAgent("dev", true)
+	h.createAgent("pm", false)
+
+	driver := &recordingDriver{t: t}
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.drivers["claude"] = driver
+
+	// An agent addressing dev in the room cannot wake it...
+	h.postMessage("pm", "@dev please rebase", types.MessageTypeAgent)
+	d.checkMentions(context.Background(), dev)
+	if len(driver.spawned) != 0 {
+		t.Fatalf("expected room message from agent not to spawn, got %v", driver.spawned)
+	}
+
+	// ...but a dm can, even without a leading @mention.
+	root, err := db.CreateThread(h.db, types.Thread{Name: core.DMThreadRoot, Type: types.ThreadTypeDM})
+	if err != nil {
+		t.Fatalf("create dm root: %v", err)
+	}
+	pair, err := db.CreateThread(h.db, types.Thread{Name: core.DMThreadName("pm", "dev"), ParentThread: &root.GUID, Type: types.ThreadTypeDM})
+	if err != nil {
+		t.Fatalf("create dm thread: %v", err)
+	}
+	if _, err := db.CreateMessage(h.db, types.Message{
+		TS:        time.Now().Unix() + 1, // sort after the room message's watermark
+		FromAgent: "pm",
+		Body:      "please rebase before continuing",
+		Mentions:  []string{"dev"},
+		Home:      pair.GUID,
+		Type:      types.MessageTypeAgent,
+	}); err != nil {
+		t.Fatalf("create dm: %v", err)
+	}
+
+	d.checkMentions(context.Background(), dev)
+	if len(driver.spawned) != 1 || driver.spawned[0] != "dev" {
+		t.Fatalf("expected dm to wake dev, got %v", driver.spawned)
+	}
+}
+
+// --- Warm Pool Tests ---
+
+// warmRecordingDriver prestarts placeholder processes and records deliveries.
+type warmRecordingDriver struct {
+	recordingDriver
+	prestarted []*Process
+	resumed    []string // LastSessionID each prestart was asked to resume
+	delivered  []*Process
+}
+
+func (w *warmRecordingDriver) Prestart(ctx context.Context, agent types.Agent) (*Process, error) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	w.t.Cleanup(func() { _ = cmd.Process.Kill() })
+	proc := &Process{Cmd: cmd, StartedAt: time.Now(), SessionID: fmt.Sprintf("warm-%s-%d", agent.AgentID, len(w.prestarted))}
+	w.prestarted = append(w.prestarted, proc)
+	w.resumed = append(w.resumed, agentSessionID(agent))
+	return proc, nil
+}
+
+func (w *warmRecordingDriver) Deliver(proc *Process, prompt string) error {
+	w.delivered = append(w.delivered, proc)
+	return nil
+}
+
+func TestWarmPool_DeliversToPrestartedAndRefills(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("dev", true)
+	if err := db.SetConfig(h.db, "warm_agents", "dev"); err != nil {
+		t.Fatalf("set warm_agents: %v", err)
+	}
+
+	driver := &warmRecordingDriver{recordingDriver: recordingDriver{t: t}}
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.drivers["claude"] = driver
+
+	agents, _ := d.getManagedAgents()
+	d.refillWarmPool(context.Background(), agents)
+	if len(driver.prestarted) != 1 {
+		t.Fatalf("expected one prestarted session, got %d", len(driver.prestarted))
+	}
+	dev, _ := db.GetAgent(h.db, "dev")
+	if dev.Presence != types.PresenceWarm {
+		t.Fatalf("expected presence warm, got %s", dev.Presence)
+	}
+
+	// A second pass keeps the existing warm session.
+	d.refillWarmPool(context.Background(), agents)
+	if len(driver.prestarted) != 1 {
+		t.Fatalf("expected pool to stay at one session, got %d", len(driver.prestarted))
+	}
+
+	first := h.postMessage("adam", "@dev ship it", types.MessageTypeUser)
+	d.checkMentions(context.Background(), *dev)
+
+	if len(driver.spawned) != 0 {
+		t.Fatalf("expected no cold spawn, got %v", driver.spawned)
+	}
+	if len(driver.delivered) != 1 || driver.delivered[0] != driver.prestarted[0] {
+		t.Fatalf("expected prompt delivered to the prestarted session")
+	}
+	d.mu.RLock()
+	active := d.processes["dev"]
+	d.mu.RUnlock()
+	if active != driver.prestarted[0] {
+		t.Fatalf("expected the warm session to become the active one")
+	}
+	// The active session is still live, so nothing can resume it yet.
+	agents, _ = d.getManagedAgents()
+	d.refillWarmPool(context.Background(), agents)
+	if len(driver.prestarted) != 1 {
+		t.Fatalf("expected no replacement while the session runs, got %d prestarts", len(driver.prestarted))
+	}
+
+	// Once it ends, the replacement resumes it.
+	active.Cmd.Process.Kill()
+	h.waitForExit(d, "dev")
+	// The kill reads as a crash; treat it as the clean exit it stands for.
+	if err := db.UpdateAgentPresence(h.db, "dev", types.PresenceIdle); err != nil {
+		t.Fatalf("set presence: %v", err)
+	}
+	agents, _ = d.getManagedAgents()
+	d.refillWarmPool(context.Background(), agents)
+	d.mu.RLock()
+	refill := d.warm["dev"]
+	d.mu.RUnlock()
+	if len(driver.prestarted) != 2 || refill == nil || driver.resumed[1] != active.SessionID {
+		t.Fatalf("expected a replacement resuming %s, got %d prestarts resuming %v", active.SessionID, len(driver.prestarted), driver.resumed)
+	}
+
+	// The second wake lands in the resumed session.
+	dev, _ = db.GetAgent(h.db, "dev")
+	next := testutil.NewMessage("adam", "@dev one more thing", types.MessageTypeUser)
+	next.TS = first.TS + 1
+	h.createMessage(next)
+	d.checkMentions(context.Background(), *dev)
+	if len(driver.spawned) != 0 || len(driver.delivered) != 2 || driver.delivered[1] != refill.proc {
+		t.Fatalf("expected the second wake delivered to the resumed session, got spawned=%v delivered=%d", driver.spawned, len(driver.delivered))
+	}
+
+	// Teardown stops the session.
+	refill.proc.Cmd.Process.Kill()
+	h.waitForExit(d, "dev")
+	d.stopWarmPool()
+	d.wg.Wait()
+}
+
+func TestWarmPool_FallsBackToColdSpawn(t *testing.T) {
+	h := newTestHarness(t)
+	dev := h.createAgent("dev", true)
+
+	driver := &warmRecordingDriver{recordingDriver: recordingDriver{t: t}}
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.drivers["claude"] = driver
+
+	// Not listed in warm_agents: nothing is prestarted.
+	d.refillWarmPool(context.Background(), []types.Agent{dev})
+	if len(driver.prestarted) != 0 {
+		t.Fatalf("expected no prestart without warm_agents, got %d", len(driver.prestarted))
+	}
+
+	h.postMessage("adam", "@dev ship it", types.MessageTypeUser)
+	d.checkMentions(context.Background(), dev)
+	if len(driver.spawned) != 1 || len(driver.delivered) != 0 {
+		t.Fatalf("expected cold spawn, got spawned=%v delivered=%d", driver.spawned, len(driver.delivered))
+	}
+}
+
+// --- Done Report Tests ---
+
+// startIdleSession registers a long-running process as agentID's current session.
+func (h *testHarness) startIdleSession(d *Daemon, agentID, triggeredBy string) *Process {
+	h.t.Helper()
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		h.t.Fatalf("start process: %v", err)
+	}
+	h.t.Cleanup(func() { _ = cmd.Process.Kill() })
+
+	proc := &Process{
+		Cmd:         cmd,
+		StartedAt:   time.Now().Add(-2 * time.Second),
+		SessionID:   "sess-" + agentID,
+		TriggeredBy: triggeredBy,
+	}
+	d.processes[agentID] = proc
+	d.wg.Add(1)
+	go d.monitorProcess(agentID, proc)
+
+	if err := db.UpdateAgentPresence(h.db, agentID, types.PresenceIdle); err != nil {
+		h.t.Fatalf("set presence: %v", err)
+	}
+	return proc
+}
+
+// createWorker creates a managed agent whose min_checkin is far away, so any
+// session end in the test comes from something other than the timeout.
+func (h *testHarness) createWorker(agentID string) {
+	h.t.Helper()
+	now := time.Now().Unix()
+	if err := db.CreateAgent(h.db, types.Agent{
+		AgentID:      agentID,
+		RegisteredAt: now,
+		LastSeen:     now,
+		Managed:      true,
+		Presence:     types.PresenceOffline,
+		Invoke: &types.InvokeConfig{
+			Driver:       "claude",
+			MinCheckinMs: int64(time.Hour / time.Millisecond),
+		},
+	}); err != nil {
+		h.t.Fatalf("create agent %s: %v", agentID, err)
+	}
+}
+
+func (h *testHarness) waitForExit(d *Daemon, agentID string) {
+	h.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		d.mu.RLock()
+		_, running := d.processes[agentID]
+		d.mu.RUnlock()
+		if !running {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	h.t.Fatalf("session for @%s did not end", agentID)
+}
+
+func TestDoneReport_SkipsTimeoutRecycle(t *testing.T) {
+	h := newTestHarness(t)
+	h.createWorker("dev")
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+
+	proc := h.startIdleSession(d, "dev", "")
+
+	// Idle but well within min_checkin and no done report: session keeps running.
+	d.updatePresence()
+	if proc.Cmd.ProcessState != nil {
+		t.Fatalf("expected session to keep running without a done report")
+	}
+
+	h.postMessage("dev", core.FormatDoneBody(types.DoneStatusSuccess, "auth shipped"), types.MessageTypeDone)
+	d.updatePresence()
+	h.waitForExit(d, "dev")
+
+	agent, err := db.GetAgent(h.db, "dev")
+	if err != nil || agent == nil {
+		t.Fatalf("get agent: %v", err)
+	}
+	if agent.Presence != types.PresenceIdle {
+		t.Fatalf("expected clean end to leave presence idle, got %s", agent.Presence)
+	}
+
+	sessions, err := db.ReadAgentSessions(h.projectPath, "dev")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("expected one session, got %v (%v)", sessions, err)
+	}
+	if sessions[0].Outcome != types.DoneStatusSuccess || sessions[0].Summary == nil || *sessions[0].Summary != "auth shipped" {
+		t.Fatalf("expected outcome recorded on session, got %+v", sessions[0])
+	}
+}
+
+func TestDoneReport_BlockedNotifiesPM(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("pm", false)
+	h.createWorker("dev")
+
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	h.startIdleSession(d, "dev", "")
+	h.postMessage("dev", core.FormatDoneBody(types.DoneStatusBlocked, "need staging API keys"), types.MessageTypeDone)
+	d.updatePresence()
+	h.waitForExit(d, "dev")
+
+	messages, err := db.GetMessagesWithMention(h.db, "pm", nil)
+	if err != nil {
+		t.Fatalf("get mentions: %v", err)
+	}
+	if len(messages) != 1 || messages[0].FromAgent != "dev" || messages[0].Type != types.MessageTypeEvent {
+		t.Fatalf("expected one blocked notice mentioning @pm, got %v", messages)
+	}
+	if !strings.Contains(messages[0].Body, "need staging API keys") {
+		t.Fatalf("expected summary in notice, got %q", messages[0].Body)
+	}
+}
+
+func TestDoneReport_BlockedRecipients(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("dev", true)
+
+	if got := (&Daemon{database: h.db}).blockedRecipients("dev", nil); len(got) != 0 {
+		t.Fatalf("expected nobody to notify without owner or pm, got %v", got)
+	}
+
+	h.createAgent("lead", false)
+	if err := db.AddRoleAssignment(h.db, "lead", "pm"); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+	d := &Daemon{database: h.db}
+	if got := d.blockedRecipients("dev", nil); len(got) != 1 || got[0] != "lead" {
+		t.Fatalf("expected pm role holder, got %v", got)
+	}
+
+	owned := &types.Thread{GUID: "thrd-auth", Name: "auth", OwnerAgent: strPtr("alice")}
+	if got := d.blockedRecipients("dev", owned); len(got) != 1 || got[0] != "alice" {
+		t.Fatalf("expected thread owner first, got %v", got)
+	}
+}
+
+// --- Issue Thread Tests ---
+
+func (h *testHarness) issueDaemon() *Daemon {
+	h.t.Helper()
+	if err := db.SetConfig(h.db, autoThreadConfigKey, "true"); err != nil {
+		h.t.Fatalf("set config: %v", err)
+	}
+	h.postMessage("alice", "morning all", types.MessageTypeAgent)
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.checkIssueRefs() // primes the watermark
+	return d
+}
+
+func (h *testHarness) pointerReplies(anchor string) int {
+	h.t.Helper()
+	replies, err := db.GetReplies(h.db, anchor)
+	if err != nil {
+		h.t.Fatalf("get replies: %v", err)
+	}
+	count := 0
+	for _, reply := range replies {
+		if reply.FromAgent == "system" {
+			count++
+		}
+	}
+	return count
+}
+
+func TestIssueThreads_FirstReferenceCreatesThread(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("alice", false)
+	d := h.issueDaemon()
+
+	msg := h.postMessage("alice", "starting on bd:abc1", types.MessageTypeAgent)
+	d.checkIssueRefs()
+
+	thread, err := db.GetThreadByName(h.db, "bd:abc1-work", nil)
+	if err != nil || thread == nil {
+		t.Fatalf("expected bd:abc1-work thread: %v", err)
+	}
+	if thread.AnchorMessageGUID == nil || *thread.AnchorMessageGUID != msg.ID {
+		t.Fatalf("expected triggering message as anchor, got %v", thread.AnchorMessageGUID)
+	}
+	subscribed, err := db.GetThreads(h.db, &types.ThreadQueryOptions{SubscribedAgent: strPtr("alice")})
+	if err != nil || len(subscribed) != 1 || subscribed[0].GUID != thread.GUID {
+		t.Fatalf("expected author subscribed, got %v (%v)", subscribed, err)
+	}
+	if got := h.pointerReplies(msg.ID); got != 1 {
+		t.Fatalf("expected one pointer reply, got %d", got)
+	}
+}
+
+func TestIssueThreads_ReusesExistingThread(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("alice", false)
+	h.createAgent("bob", false)
+	d := h.issueDaemon()
+
+	h.postMessage("alice", "starting on bd:abc1", types.MessageTypeAgent)
+	d.checkIssueRefs()
+	second := h.postMessage("bob", "I can pair on bd:abc1 after lunch", types.MessageTypeAgent)
+	d.checkIssueRefs()
+
+	threads, err := db.GetThreads(h.db, nil)
+	if err != nil || len(threads) != 1 {
+		t.Fatalf("expected a single work thread, got %v (%v)", threads, err)
+	}
+	if got := h.pointerReplies(second.ID); got != 0 {
+		t.Fatalf("expected no pointer for a later reference, got %d", got)
+	}
+	subscribed, _ := db.GetThreads(h.db, &types.ThreadQueryOptions{SubscribedAgent: strPtr("bob")})
+	if len(subscribed) != 1 {
+		t.Fatalf("expected later author subscribed to existing thread, got %v", subscribed)
+	}
+}
+
+func TestIssueThreads_DuplicateTriggerIsIdempotent(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("alice", false)
+	d := h.issueDaemon()
+	watermark, _ := db.GetConfig(h.db, autoThreadWatermarkKey)
+
+	msg := h.postMessage("alice", "starting on bd:abc1", types.MessageTypeAgent)
+
+	// Overlapping ticks, then a replay of the same message from a stale watermark.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.checkIssueRefs()
+		}()
+	}
+	wg.Wait()
+	if err := db.SetConfig(h.db, autoThreadWatermarkKey, watermark); err != nil {
+		t.Fatalf("reset watermark: %v", err)
+	}
+	d.checkIssueRefs()
+
+	threads, err := db.GetThreads(h.db, nil)
+	if err != nil || len(threads) != 1 {
+		t.Fatalf("expected exactly one thread, got %v (%v)", threads, err)
+	}
+	if got := h.pointerReplies(msg.ID); got != 1 {
+		t.Fatalf("expected exactly one pointer reply, got %d", got)
+	}
+}
+
+func TestIssueThreads_SeedsWatermark(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("alice", false)
+	h.createAgent("code-reviewer", false)
+	if err := db.SetConfig(h.db, autoThreadConfigKey, "true"); err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+
+	// An empty room still seeds, so the first message is not skipped.
+	d.checkIssueRefs()
+	if watermark, _ := db.GetConfig(h.db, autoThreadWatermarkKey); watermark == "" {
+		t.Fatal("expected the first tick to seed the watermark")
+	}
+	first := h.postMessage("alice", "starting on bd:abc1, @code-reviewer please look", types.MessageTypeAgent)
+	d.checkIssueRefs()
+	if got := h.pointerReplies(first.ID); got != 1 {
+		t.Fatalf("expected the first reference to be handled, got %d pointer replies", got)
+	}
+	threads, _ := db.GetThreads(h.db, nil)
+	if len(threads) != 1 || threads[0].Name != "bd:abc1-work" {
+		t.Fatalf("expected only the bd:abc1 thread, not one for an agent mention, got %v", threads)
+	}
+
+	// Enabling on a project with history does not back-process it.
+	other := newTestHarness(t)
+	other.createAgent("alice", false)
+	old := other.postMessage("alice", "old news about bd:zzz9", types.MessageTypeAgent)
+	if _, err := other.db.Exec("UPDATE fray_messages SET ts = ts - 60"); err != nil {
+		t.Fatalf("backdate history: %v", err)
+	}
+	d = other.issueDaemon()
+	d.checkIssueRefs()
+	if got := other.pointerReplies(old.ID); got != 0 {
+		t.Fatalf("expected history before enabling to be skipped, got %d pointer replies", got)
+	}
+}
+
 // Helper
 func strPtr(s string) *string {
 	return &s
 }
+
+// --- Thread Digest Tests ---
+
+// stubDigestScript puts a fake mlld on PATH that prints summary and saves the
+// payload it was given, and writes a placeholder llm/digest.mld.
+func (h *testHarness) stubDigestScript(summary string) string {
+	h.t.Helper()
+	binDir := h.t.TempDir()
+	payloadPath := filepath.Join(binDir, "payload.json")
+	script := "#!/bin/sh\nprintf '%s' \"$3\" > " + payloadPath + "\necho '" + summary + "'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "mlld"), []byte(script), 0755); err != nil {
+		h.t.Fatalf("write fake mlld: %v", err)
+	}
+	h.t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	llmDir := filepath.Join(h.projectDir, "llm")
+	if err := os.MkdirAll(llmDir, 0755); err != nil {
+		h.t.Fatalf("mkdir llm: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(llmDir, "digest.mld"), []byte(">> stub\n"), 0644); err != nil {
+		h.t.Fatalf("write digest.mld: %v", err)
+	}
+	return payloadPath
+}
+
+func (h *testHarness) postInThread(thread types.Thread, fromAgent, body string, ts int64) types.Message {
+	h.t.Helper()
+	created, err := db.CreateMessage(h.db, types.Message{TS: ts, FromAgent: fromAgent, Body: body, Home: thread.GUID, Type: types.MessageTypeAgent})
+	if err != nil {
+		h.t.Fatalf("create message: %v", err)
+	}
+	return created
+}
+
+func TestThreadDigest_ReplacesAnchorAndPinsPrevious(t *testing.T) {
+	h := newTestHarness(t)
+	payloadPath := h.stubDigestScript("- decided on sqlite")
+	project := core.Project{Root: h.projectDir, DBPath: h.projectPath}
+
+	thread, err := db.CreateThread(h.db, types.Thread{Name: "design", Status: types.ThreadStatusOpen})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	now := time.Now().Unix()
+	old := h.postInThread(thread, "pm", "old summary", now-10)
+	updated, err := db.UpdateThread(h.db, thread.GUID, db.ThreadUpdates{AnchorMessageGUID: types.OptionalString{Set: true, Value: &old.ID}})
+	if err != nil {
+		t.Fatalf("set anchor: %v", err)
+	}
+	h.postInThread(thread, "dev", "let's use sqlite", now-5)
+
+	result, err := RefreshThreadDigest(context.Background(), h.db, project, *updated, "pm")
+	if err != nil {
+		t.Fatalf("refresh digest: %v", err)
+	}
+	if result.Message.Body != "- decided on sqlite" || result.Message.FromAgent != "pm" || result.Message.Home != thread.GUID {
+		t.Fatalf("unexpected digest message: %+v", result.Message)
+	}
+	if result.Thread.AnchorMessageGUID == nil || *result.Thread.AnchorMessageGUID != result.Message.ID {
+		t.Fatalf("expected digest to become the anchor, got %v", result.Thread.AnchorMessageGUID)
+	}
+	if pinned, _ := db.IsMessagePinned(h.db, old.ID, thread.GUID); !pinned || result.PreviousAnchor != old.ID {
+		t.Fatalf("expected previous anchor %s to be pinned", old.ID)
+	}
+
+	payload, err := os.ReadFile(payloadPath)
+	if err != nil {
+		t.Fatalf("read payload: %v", err)
+	}
+	if !strings.Contains(string(payload), `"previousSummary":"old summary"`) || !strings.Contains(string(payload), "let's use sqlite") {
+		t.Fatalf("unexpected payload: %s", payload)
+	}
+}
+
+func TestThreadDigest_UnavailableWithoutScript(t *testing.T) {
+	h := newTestHarness(t)
+	thread, err := db.CreateThread(h.db, types.Thread{Name: "design", Status: types.ThreadStatusOpen})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	h.postInThread(thread, "dev", "hello", time.Now().Unix())
+
+	_, err = RefreshThreadDigest(context.Background(), h.db, core.Project{Root: h.projectDir, DBPath: h.projectPath}, thread, "pm")
+	if err != ErrDigestUnavailable {
+		t.Fatalf("expected ErrDigestUnavailable, got %v", err)
+	}
+}
+
+func TestThreadDigest_DaemonRefreshesDueThreads(t *testing.T) {
+	h := newTestHarness(t)
+	h.stubDigestScript("auto summary")
+
+	due, err := db.CreateThread(h.db, types.Thread{Name: "busy", Status: types.ThreadStatusOpen})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	quiet, err := db.CreateThread(h.db, types.Thread{Name: "quiet", Status: types.ThreadStatusOpen})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	now := time.Now().Unix()
+	for i := 0; i < 3; i++ {
+		h.postInThread(due, "dev", "progress", now-3+int64(i))
+	}
+	h.postInThread(quiet, "dev", "progress", now)
+
+	db.SetConfig(h.db, DigestEveryConfigKey, "3")
+	for _, guid := range []string{due.GUID, quiet.GUID} {
+		if err := SetDigestEnabled(h.db, guid, true); err != nil {
+			t.Fatalf("enable digest: %v", err)
+		}
+	}
+
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.checkDigests(context.Background())
+	d.wg.Wait()
+
+	busy, _ := db.GetThread(h.db, due.GUID)
+	if busy.AnchorMessageGUID == nil {
+		t.Fatalf("expected due thread to get a digest anchor")
+	}
+	if still, _ := db.GetThread(h.db, quiet.GUID); still.AnchorMessageGUID != nil {
+		t.Fatalf("expected quiet thread to be left alone")
+	}
+
+	// The new anchor resets the count.
+	if isDue, _ := ThreadDigestDue(h.db, *busy, 3); isDue {
+		t.Fatalf("expected digest not to be due right after refresh")
+	}
+}
+
+// --- Subscription Level Tests ---
+
+func TestSubscriptionLevels_SpawnBehavior(t *testing.T) {
+	cases := []struct {
+		level types.SubscriptionLevel
+		spawn bool
+	}{
+		{types.SubscriptionAll, true},
+		{types.SubscriptionMentions, true},
+		{types.SubscriptionDigest, false},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.level), func(t *testing.T) {
+			h := newTestHarness(t)
+			dev := h.createAgent("dev", true)
+
+			design, err := db.CreateThread(h.db, types.Thread{Name: "design", Status: types.ThreadStatusOpen})
+			if err != nil {
+				t.Fatalf("create thread: %v", err)
+			}
+			other, err := db.CreateThread(h.db, types.Thread{Name: "other", Status: types.ThreadStatusOpen})
+			if err != nil {
+				t.Fatalf("create thread: %v", err)
+			}
+			if err := db.SubscribeThreadLevel(h.db, design.GUID, "dev", tc.level, 0); err != nil {
+				t.Fatalf("subscribe: %v", err)
+			}
+
+			driver := &recordingDriver{t: t}
+			d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+			d.drivers["claude"] = driver
+
+			if _, err := db.CreateMessage(h.db, types.Message{
+				FromAgent: "adam",
+				Body:      "@dev can you review the schema?",
+				Mentions:  []string{"dev"},
+				Home:      design.GUID,
+				Type:      types.MessageTypeUser,
+			}); err != nil {
+				t.Fatalf("create message: %v", err)
+			}
+			d.checkMentions(context.Background(), dev)
+
+			spawned := len(driver.spawned) == 1
+			if spawned != tc.spawn {
+				t.Fatalf("level %s: expected spawn=%v, got %v", tc.level, tc.spawn, driver.spawned)
+			}
+
+			if !tc.spawn {
+				// The level only applies to the followed thread.
+				if _, err := db.CreateMessage(h.db, types.Message{
+					TS:        time.Now().Unix() + 1,
+					FromAgent: "adam",
+					Body:      "@dev and this one too",
+					Mentions:  []string{"dev"},
+					Home:      other.GUID,
+					Type:      types.MessageTypeUser,
+				}); err != nil {
+					t.Fatalf("create message: %v", err)
+				}
+				d.checkMentions(context.Background(), dev)
+				if len(driver.spawned) != 1 {
+					t.Fatalf("expected mention in another thread to spawn, got %v", driver.spawned)
+				}
+			}
+		})
+	}
+}
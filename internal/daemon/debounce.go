@@ -4,12 +4,16 @@ import (
 	"database/sql"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/db"
 	"github.com/adamavenir/fray/internal/types"
 )
 
 // MentionDebouncer tracks mention watermarks and pending mentions per agent.
+// Pending mentions are written through to SQLite so a restarted daemon picks
+// up where the last one stopped.
 type MentionDebouncer struct {
 	mu          sync.RWMutex
 	pending     map[string][]string // agent_id -> []msg_id
@@ -17,10 +21,15 @@ type MentionDebouncer struct {
 	projectPath string
 }
 
-// NewMentionDebouncer creates a debouncer backed by the given database.
+// NewMentionDebouncer creates a debouncer backed by the given database,
+// reloading any mentions a previous daemon left queued.
 func NewMentionDebouncer(database *sql.DB, projectPath string) *MentionDebouncer {
+	pending, err := db.GetPendingMentions(database)
+	if err != nil {
+		pending = make(map[string][]string)
+	}
 	return &MentionDebouncer{
-		pending:     make(map[string][]string),
+		pending:     pending,
 		database:    database,
 		projectPath: projectPath,
 	}
@@ -70,6 +79,7 @@ func (d *MentionDebouncer) QueueMention(agentID, msgID string) {
 	}
 
 	d.pending[agentID] = append(d.pending[agentID], msgID)
+	db.AddPendingMention(d.database, agentID, msgID)
 }
 
 // FlushPending returns and clears all pending mentions for an agent.
@@ -80,6 +90,7 @@ func (d *MentionDebouncer) FlushPending(agentID string) []string {
 
 	pending := d.pending[agentID]
 	delete(d.pending, agentID)
+	db.ClearPendingMentions(d.database, agentID)
 	return pending
 }
 
@@ -99,6 +110,28 @@ func (d *MentionDebouncer) PendingCount(agentID string) int {
 	return len(d.pending[agentID])
 }
 
+// PendingTotals returns how many agents have queued mentions and how many
+// mentions are queued in all.
+func (d *MentionDebouncer) PendingTotals() (agents, mentions int) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, ids := range d.pending {
+		if len(ids) > 0 {
+			agents++
+			mentions += len(ids)
+		}
+	}
+	return agents, mentions
+}
+
+// IsWorking reports whether the agent has a fresh working ping, meaning it
+// is mid-generation and new mentions should wait rather than spawn.
+func (d *MentionDebouncer) IsWorking(agentID string) bool {
+	working, err := db.GetAgentWorking(d.database, agentID, db.WorkingTTL(d.database), time.Now().UnixMilli())
+	return err == nil && working != nil
+}
+
 // IsSelfMention returns true if the message is from the given agent.
 func IsSelfMention(msg types.Message, agentID string) bool {
 	return msg.FromAgent == agentID
@@ -164,11 +197,18 @@ func matchesMention(mention, agentID string) bool {
 	return false
 }
 
+// IsDMToAgent returns true if msg was sent to the agent in their dm thread.
+// Every dm is addressed to the other participant, so it counts as direct.
+func IsDMToAgent(msg types.Message, thread *types.Thread, agentID string) bool {
+	return msg.FromAgent != agentID && core.IsDMParticipant(thread, agentID)
+}
+
 // CanTriggerSpawn returns true if the message author can trigger a spawn for the agent.
 // Rules:
 // - In room: only human (non-agent) can trigger
 // - In thread with owner: human OR owner can trigger
 // - In thread without owner (user-started): only human can trigger
+// - In dm thread: either participant can trigger
 func CanTriggerSpawn(msg types.Message, thread *types.Thread) bool {
 	// Check if author is human (message type "user" vs "agent")
 	isHuman := msg.Type == types.MessageTypeUser
@@ -177,6 +217,10 @@ func CanTriggerSpawn(msg types.Message, thread *types.Thread) bool {
 		return true
 	}
 
+	if core.IsDMParticipant(thread, msg.FromAgent) {
+		return true
+	}
+
 	// Author is an agent - only allowed if they own the thread
 	if thread != nil && thread.OwnerAgent != nil && *thread.OwnerAgent == msg.FromAgent {
 		return true
@@ -212,6 +256,7 @@ func IsReplyToAgent(database *sql.DB, msg types.Message, agentID string) bool {
 // ShouldSpawn determines if a mention should trigger a spawn.
 // Returns false if:
 // - Message is a self-mention
+// - Agent has a fresh working ping (mention should be queued instead)
 // - Agent is currently spawning/active (mention should be queued instead)
 // Note: Watermark filtering is done by the caller via GetMessagesWithMention.
 func (d *MentionDebouncer) ShouldSpawn(agent types.Agent, msg types.Message) bool {
@@ -220,9 +265,14 @@ func (d *MentionDebouncer) ShouldSpawn(agent types.Agent, msg types.Message) boo
 		return false
 	}
 
-	// Check presence state - only spawn if offline or idle
+	// A fresh working ping means it's mid-generation: queue, don't respawn
+	if d.IsWorking(agent.AgentID) {
+		return false
+	}
+
+	// Check presence state - only spawn if offline, idle, or warm
 	switch agent.Presence {
-	case types.PresenceOffline, types.PresenceIdle, "":
+	case types.PresenceOffline, types.PresenceIdle, types.PresenceWarm, "":
 		return true
 	case types.PresenceSpawning, types.PresenceActive:
 		// Queue instead of spawning
@@ -0,0 +1,310 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+const (
+	// DigestEveryConfigKey is how many new thread messages make a digest due.
+	DigestEveryConfigKey = "digest_every_n_messages"
+	// DigestThreadsConfigKey lists thread GUIDs opted in to daemon digests.
+	DigestThreadsConfigKey = "digest_threads"
+
+	// digestScript is the mlld summarizer, relative to the project root.
+	digestScript = "llm/digest.mld"
+	// digestMessageLimit caps how many recent messages the script sees.
+	digestMessageLimit = 50
+	digestTimeout      = 2 * time.Minute
+)
+
+// ErrDigestUnavailable means mlld or the digest script is missing.
+var ErrDigestUnavailable = errors.New("mlld or llm/digest.mld not available")
+
+// DigestResult describes a refreshed thread digest.
+type DigestResult struct {
+	Thread         types.Thread  `json:"thread"`
+	Message        types.Message `json:"message"`
+	PreviousAnchor string        `json:"previous_anchor,omitempty"`
+}
+
+type digestPayload struct {
+	Thread          digestThread    `json:"thread"`
+	PreviousSummary string          `json:"previousSummary,omitempty"`
+	Messages        []digestMessage `json:"messages"`
+}
+
+type digestThread struct {
+	GUID string `json:"guid"`
+	Name string `json:"name"`
+}
+
+type digestMessage struct {
+	ID   string `json:"id"`
+	From string `json:"from"`
+	TS   int64  `json:"ts"`
+	Body string `json:"body"`
+}
+
+// DigestEnabledThreads returns the thread GUIDs listed in digest_threads.
+func DigestEnabledThreads(database *sql.DB) ([]string, error) {
+	value, err := db.GetConfig(database, DigestThreadsConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	var guids []string
+	for _, part := range strings.Split(value, ",") {
+		if guid := strings.TrimSpace(part); guid != "" {
+			guids = append(guids, guid)
+		}
+	}
+	return guids, nil
+}
+
+// SetDigestEnabled adds or removes a thread from digest_threads.
+func SetDigestEnabled(database *sql.DB, threadGUID string, enabled bool) error {
+	guids, err := DigestEnabledThreads(database)
+	if err != nil {
+		return err
+	}
+	var next []string
+	for _, guid := range guids {
+		if guid != threadGUID {
+			next = append(next, guid)
+		}
+	}
+	if enabled {
+		next = append(next, threadGUID)
+	}
+	return db.SetConfig(database, DigestThreadsConfigKey, strings.Join(next, ","))
+}
+
+// DigestEvery returns digest_every_n_messages, or 0 when unset.
+func DigestEvery(database *sql.DB) (int, error) {
+	value, err := db.GetConfig(database, DigestEveryConfigKey)
+	if err != nil || strings.TrimSpace(value) == "" {
+		return 0, err
+	}
+	every, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q", DigestEveryConfigKey, value)
+	}
+	return every, nil
+}
+
+// ThreadDigestDue reports whether at least every messages were posted in
+// the thread after the second its current anchor was posted (or ever, if it
+// has none).
+func ThreadDigestDue(database *sql.DB, thread types.Thread, every int) (bool, error) {
+	if every <= 0 {
+		return false, nil
+	}
+	messages, err := db.GetThreadMessages(database, thread.GUID)
+	if err != nil {
+		return false, err
+	}
+	var since int64
+	if thread.AnchorMessageGUID != nil {
+		if anchor, err := db.GetMessage(database, *thread.AnchorMessageGUID); err == nil && anchor != nil {
+			since = anchor.TS
+		}
+	}
+	count := 0
+	for _, msg := range messages {
+		if (since > 0 && msg.TS <= since) || msg.Type == types.MessageTypeEvent {
+			continue
+		}
+		count++
+	}
+	return count >= every, nil
+}
+
+// DigestAvailable reports whether mlld and the project's digest script exist.
+func DigestAvailable(projectRoot string) bool {
+	if _, err := exec.LookPath("mlld"); err != nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(projectRoot, digestScript))
+	return err == nil
+}
+
+// RefreshThreadDigest summarizes the thread's recent messages with
+// llm/digest.mld, posts the summary as agentID, makes it the anchor, and pins
+// the previous anchor in the thread so the old summary stays reachable.
+// Returns ErrDigestUnavailable when mlld or the script is missing.
+func RefreshThreadDigest(ctx context.Context, database *sql.DB, project core.Project, thread types.Thread, agentID string) (*DigestResult, error) {
+	if !DigestAvailable(project.Root) {
+		return nil, ErrDigestUnavailable
+	}
+
+	messages, err := db.GetThreadMessages(database, thread.GUID)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) > digestMessageLimit {
+		messages = messages[len(messages)-digestMessageLimit:]
+	}
+
+	payload := digestPayload{Thread: digestThread{GUID: thread.GUID, Name: thread.Name}}
+	var previous *types.Message
+	if thread.AnchorMessageGUID != nil {
+		previous, _ = db.GetMessage(database, *thread.AnchorMessageGUID)
+		if previous != nil {
+			payload.PreviousSummary = previous.Body
+		}
+	}
+	for _, msg := range messages {
+		if msg.Type == types.MessageTypeEvent || (previous != nil && msg.ID == previous.ID) {
+			continue
+		}
+		payload.Messages = append(payload.Messages, digestMessage{ID: msg.ID, From: msg.FromAgent, TS: msg.TS, Body: msg.Body})
+	}
+	if len(payload.Messages) == 0 {
+		return nil, fmt.Errorf("thread %s has no messages to summarize", thread.Name)
+	}
+
+	summary, err := runDigestScript(ctx, project.Root, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	bases, err := db.GetAgentBases(database)
+	if err != nil {
+		return nil, err
+	}
+	created, err := db.CreateMessage(database, types.Message{
+		TS:        now,
+		Home:      thread.GUID,
+		FromAgent: agentID,
+		Body:      summary,
+		Mentions:  core.ExtractMentions(summary, bases),
+		Type:      types.MessageTypeAgent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AppendMessage(project.DBPath, created); err != nil {
+		return nil, err
+	}
+
+	result := &DigestResult{Message: created}
+	if previous != nil {
+		if err := db.PinMessage(database, previous.ID, thread.GUID, agentID, now); err != nil {
+			return nil, err
+		}
+		if err := db.AppendMessagePin(project.DBPath, db.MessagePinJSONLRecord{
+			MessageGUID: previous.ID,
+			ThreadGUID:  thread.GUID,
+			PinnedBy:    agentID,
+			PinnedAt:    now,
+		}); err != nil {
+			return nil, err
+		}
+		result.PreviousAnchor = previous.ID
+	}
+
+	anchor := created.ID
+	updated, err := db.UpdateThread(database, thread.GUID, db.ThreadUpdates{
+		AnchorMessageGUID: types.OptionalString{Set: true, Value: &anchor},
+		LastActivityAt:    types.OptionalInt64{Set: true, Value: &now},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AppendThreadUpdate(project.DBPath, db.ThreadUpdateJSONLRecord{
+		GUID:              thread.GUID,
+		AnchorMessageGUID: &anchor,
+		LastActivityAt:    &now,
+	}); err != nil {
+		return nil, err
+	}
+	result.Thread = *updated
+	return result, nil
+}
+
+// runDigestScript runs mlld llm/digest.mld --payload <json> from the project
+// root and returns the trimmed summary it prints.
+func runDigestScript(ctx context.Context, projectRoot string, payload digestPayload) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, digestTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "mlld", digestScript, "--payload", string(data))
+	cmd.Dir = projectRoot
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("digest script failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	summary := strings.TrimSpace(stdout.String())
+	if summary == "" {
+		return "", fmt.Errorf("digest script produced no summary")
+	}
+	return summary, nil
+}
+
+// checkDigests refreshes digests for opted-in threads that are due. Runs at
+// most one pass at a time in the background, since summarizing is slow.
+func (d *Daemon) checkDigests(ctx context.Context) {
+	every, err := DigestEvery(d.database)
+	if err != nil || every <= 0 {
+		return
+	}
+	guids, err := DigestEnabledThreads(d.database)
+	if err != nil || len(guids) == 0 {
+		return
+	}
+	if !DigestAvailable(d.project.Root) {
+		return
+	}
+	if !d.digestMu.TryLock() {
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer d.digestMu.Unlock()
+		d.refreshDueDigests(ctx, guids, every)
+	}()
+}
+
+func (d *Daemon) refreshDueDigests(ctx context.Context, guids []string, every int) {
+	for _, guid := range guids {
+		thread, err := db.GetThread(d.database, guid)
+		if err != nil || thread == nil {
+			continue
+		}
+		due, err := ThreadDigestDue(d.database, *thread, every)
+		if err != nil || !due {
+			continue
+		}
+		result, err := RefreshThreadDigest(ctx, d.database, d.project, *thread, "system")
+		if err != nil {
+			d.debugf("digest: %s: %v", thread.Name, err)
+			continue
+		}
+		d.debugf("digest: refreshed %s (anchor %s)", thread.Name, result.Message.ID)
+	}
+}
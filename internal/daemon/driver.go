@@ -18,6 +18,16 @@ type Process struct {
 	StartedAt time.Time
 	SessionID string
 	TempFiles []string // Temp files to clean up after process exits
+
+	TriggeredBy string // msg_id that woke the agent (set by the daemon)
+	responded   bool   // first post after the trigger has been recorded
+
+	// output keeps the tail of stdout for token usage parsing.
+	output outputTail
+
+	// exited is closed once the process is reaped, for processes whose Wait
+	// is owned by the warm pool rather than monitorProcess.
+	exited chan struct{}
 }
 
 // Driver defines the interface for CLI-specific agent spawning.
@@ -33,6 +43,18 @@ type Driver interface {
 	Cleanup(proc *Process) error
 }
 
+// WarmDriver is implemented by drivers that can start a session ahead of
+// time and hand it the prompt later (see the warm_agents pool).
+type WarmDriver interface {
+	Driver
+
+	// Prestart starts a session that waits for its prompt.
+	Prestart(ctx context.Context, agent types.Agent) (*Process, error)
+
+	// Deliver hands the prompt to a prestarted session.
+	Deliver(proc *Process, prompt string) error
+}
+
 // GetDriver returns a driver for the given name.
 // Returns nil if the driver is not recognized.
 func GetDriver(name string) Driver {
@@ -124,11 +124,48 @@ func (d *ClaudeDriver) Name() string {
 // Spawn starts a Claude Code session with the given prompt.
 // Prompt is delivered via stdin (PromptDeliveryStdin) by default.
 func (d *ClaudeDriver) Spawn(ctx context.Context, agent types.Agent, prompt string) (*Process, error) {
-	delivery := types.PromptDeliveryStdin
+	delivery := claudePromptDelivery(agent)
+	proc, err := d.start(ctx, agent, delivery, prompt)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == types.PromptDeliveryStdin {
+		d.Deliver(proc, prompt)
+	}
+	return proc, nil
+}
+
+// Prestart starts a Claude Code session that waits on stdin for its prompt.
+// Only stdin delivery can be warmed; args delivery needs the prompt up front.
+func (d *ClaudeDriver) Prestart(ctx context.Context, agent types.Agent) (*Process, error) {
+	if delivery := claudePromptDelivery(agent); delivery != types.PromptDeliveryStdin {
+		return nil, fmt.Errorf("warm start needs stdin prompt delivery, got %s", delivery)
+	}
+	return d.start(ctx, agent, types.PromptDeliveryStdin, "")
+}
+
+// Deliver writes the prompt to a stdin-delivery process and closes stdin.
+func (d *ClaudeDriver) Deliver(proc *Process, prompt string) error {
+	if proc == nil || proc.Stdin == nil {
+		return fmt.Errorf("process has no stdin")
+	}
+	go func() {
+		io.WriteString(proc.Stdin, prompt)
+		proc.Stdin.Close()
+	}()
+	return nil
+}
+
+func claudePromptDelivery(agent types.Agent) types.PromptDelivery {
 	if agent.Invoke != nil && agent.Invoke.PromptDelivery != "" {
-		delivery = agent.Invoke.PromptDelivery
+		return agent.Invoke.PromptDelivery
 	}
+	return types.PromptDeliveryStdin
+}
 
+// start launches the claude process. With stdin delivery the prompt is not
+// written; the caller delivers it.
+func (d *ClaudeDriver) start(ctx context.Context, agent types.Agent, delivery types.PromptDelivery, prompt string) (*Process, error) {
 	// Resolve claude executable path
 	claudePath, err := resolveClaudePath()
 	if err != nil {
@@ -157,6 +194,9 @@ func (d *ClaudeDriver) Spawn(ctx context.Context, agent types.Agent, prompt stri
 	} else {
 		args = append(args, "--session-id", sessionID)
 	}
+	// stream-json keeps output flowing for activity detection and ends with
+	// a result event carrying token usage.
+	args = append(args, "--output-format", "stream-json", "--verbose")
 
 	switch delivery {
 	case types.PromptDeliveryArgs:
@@ -205,24 +245,19 @@ func (d *ClaudeDriver) Spawn(ctx context.Context, agent types.Agent, prompt stri
 		return nil, fmt.Errorf("start claude: %w", err)
 	}
 
-	proc := &Process{
+	return &Process{
 		Cmd:       cmd,
 		Stdin:     stdin,
 		Stdout:    stdout,
 		Stderr:    stderr,
 		StartedAt: time.Now(),
 		SessionID: sessionID,
-	}
-
-	// Write prompt to stdin if using stdin delivery
-	if delivery == types.PromptDeliveryStdin {
-		go func() {
-			io.WriteString(stdin, prompt)
-			stdin.Close()
-		}()
-	}
+	}, nil
+}
 
-	return proc, nil
+// ParseUsage reads token usage from the session's stream-json result event.
+func (d *ClaudeDriver) ParseUsage(output []byte) *types.TokenUsage {
+	return parseClaudeUsage(output)
 }
 
 // Cleanup terminates the Claude Code process.
@@ -19,8 +19,9 @@ func (d *CodexDriver) Name() string {
 }
 
 // Spawn starts a Codex session with the given prompt.
-// Codex uses args prompt delivery by default.
-// Resume syntax: codex resume <session-id> <prompt>
+// Codex uses args prompt delivery by default. Sessions run non-interactively
+// with JSON events on stdout, whose turn events carry token usage.
+// Resume syntax: codex exec --json resume <session-id> <prompt>
 func (d *CodexDriver) Spawn(ctx context.Context, agent types.Agent, prompt string) (*Process, error) {
 	delivery := types.PromptDeliveryArgs
 	if agent.Invoke != nil && agent.Invoke.PromptDelivery != "" {
@@ -40,10 +41,9 @@ func (d *CodexDriver) Spawn(ctx context.Context, agent types.Agent, prompt strin
 	switch delivery {
 	case types.PromptDeliveryArgs:
 		if isResume {
-			// codex resume <session-id> <prompt>
-			cmd = exec.CommandContext(ctx, "codex", "resume", sessionID, prompt)
+			cmd = exec.CommandContext(ctx, "codex", "exec", "--json", "resume", sessionID, prompt)
 		} else {
-			cmd = exec.CommandContext(ctx, "codex", prompt)
+			cmd = exec.CommandContext(ctx, "codex", "exec", "--json", prompt)
 		}
 
 	case types.PromptDeliveryStdin:
@@ -82,6 +82,11 @@ func (d *CodexDriver) Spawn(ctx context.Context, agent types.Agent, prompt strin
 	}, nil
 }
 
+// ParseUsage sums token usage from codex's JSON turn events.
+func (d *CodexDriver) ParseUsage(output []byte) *types.TokenUsage {
+	return parseCodexUsage(output)
+}
+
 // Cleanup terminates the Codex process.
 func (d *CodexDriver) Cleanup(proc *Process) error {
 	if proc == nil || proc.Cmd == nil || proc.Cmd.Process == nil {
@@ -0,0 +1,187 @@
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+const (
+	// autoThreadConfigKey opts a project in to issue-thread creation.
+	autoThreadConfigKey = "auto_thread_issues"
+	// autoThreadWatermarkKey is the timestamp of the last room message scanned.
+	autoThreadWatermarkKey = "auto_thread_watermark"
+)
+
+// issueThreadName returns the work thread name for an issue reference.
+func issueThreadName(ref string) string {
+	return ref + "-work"
+}
+
+// autoThreadEnabled reports whether auto_thread_issues is set.
+func (d *Daemon) autoThreadEnabled() bool {
+	return d.configEnabled(autoThreadConfigKey)
+}
+
+// configEnabled reports whether a boolean config key is switched on.
+func (d *Daemon) configEnabled(key string) bool {
+	value, err := db.GetConfig(d.database, key)
+	if err != nil {
+		return false
+	}
+	enabled, _ := db.ParseConfigBool(value)
+	return enabled
+}
+
+// checkIssueRefs scans new room messages for issue references and moves the
+// work into a {ref}-work thread. Safe to call from overlapping ticks.
+func (d *Daemon) checkIssueRefs() {
+	if !d.autoThreadEnabled() {
+		return
+	}
+
+	d.issueMu.Lock()
+	defer d.issueMu.Unlock()
+
+	watermark, err := db.GetConfig(d.database, autoThreadWatermarkKey)
+	if err != nil {
+		d.debugf("issue threads: error reading watermark: %v", err)
+		return
+	}
+	if watermark == "" {
+		// First run: only react to references posted from now on. An empty
+		// room seeds at the current time, so its first message is scanned.
+		cursor, err := db.GetLastMessageCursor(d.database)
+		if err != nil {
+			d.debugf("issue threads: error reading last message: %v", err)
+			return
+		}
+		seed := time.Now().Unix()
+		if cursor != nil {
+			seed = cursor.TS
+		}
+		if err := db.SetConfig(d.database, autoThreadWatermarkKey, strconv.FormatInt(seed, 10)); err != nil {
+			d.debugf("issue threads: error saving watermark: %v", err)
+		}
+		return
+	}
+	sinceTS, err := strconv.ParseInt(watermark, 10, 64)
+	if err != nil {
+		d.debugf("issue threads: invalid watermark %q: %v", watermark, err)
+		return
+	}
+
+	// Messages sharing the watermark second are rescanned, since ordering
+	// within a second is not stable; ensureIssueThread makes that harmless.
+	messages, err := db.GetMessages(d.database, &types.MessageQueryOptions{
+		Since: &types.MessageCursor{TS: sinceTS},
+	})
+	if err != nil {
+		d.debugf("issue threads: error getting messages: %v", err)
+		return
+	}
+
+	bases, err := db.GetAgentBases(d.database)
+	if err != nil {
+		d.debugf("issue threads: error getting agent bases: %v", err)
+		return
+	}
+
+	for _, msg := range messages {
+		if msg.Type == types.MessageTypeEvent || msg.FromAgent == "system" {
+			continue
+		}
+		for _, ref := range core.ExtractIssueRefs(msg.Body, bases) {
+			if _, _, err := d.ensureIssueThread(msg, ref); err != nil {
+				d.debugf("issue threads: %s: %v", ref, err)
+			}
+		}
+	}
+	if len(messages) > 0 {
+		last := strconv.FormatInt(messages[len(messages)-1].TS, 10)
+		if err := db.SetConfig(d.database, autoThreadWatermarkKey, last); err != nil {
+			d.debugf("issue threads: error saving watermark: %v", err)
+		}
+	}
+}
+
+// ensureIssueThread returns the work thread for ref, creating it on first
+// reference with msg as anchor and a pointer reply in the room. The author is
+// subscribed either way. Re-running for the same message is a no-op.
+func (d *Daemon) ensureIssueThread(msg types.Message, ref string) (*types.Thread, bool, error) {
+	name := issueThreadName(ref)
+	now := time.Now().Unix()
+
+	existing, err := db.GetThreadByName(d.database, name, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		if err := d.subscribeIssueThread(existing.GUID, msg.FromAgent, now); err != nil {
+			return nil, false, err
+		}
+		return existing, false, nil
+	}
+
+	anchor := msg.ID
+	author := msg.FromAgent
+	created, err := db.CreateThread(d.database, types.Thread{
+		Name:              name,
+		Status:            types.ThreadStatusOpen,
+		CreatedAt:         now,
+		CreatedBy:         &author,
+		AnchorMessageGUID: &anchor,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if err := db.AppendThread(d.project.DBPath, created, []string{author}); err != nil {
+		return nil, false, err
+	}
+	if err := db.SubscribeThread(d.database, created.GUID, author, now); err != nil {
+		return nil, false, err
+	}
+
+	pointer, err := db.CreateMessage(d.database, types.Message{
+		TS:        now,
+		FromAgent: "system",
+		Body:      fmt.Sprintf("%s work moved to thread %s (fray get %s)", ref, name, name),
+		Type:      types.MessageTypeEvent,
+		ReplyTo:   &anchor,
+		Home:      msg.Home,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if err := db.AppendMessage(d.project.DBPath, pointer); err != nil {
+		return nil, false, err
+	}
+
+	d.debugf("issue threads: created %s for %s (anchor %s)", name, ref, anchor)
+	return &created, true, nil
+}
+
+// subscribeIssueThread subscribes agentID unless it already is.
+func (d *Daemon) subscribeIssueThread(threadGUID, agentID string, now int64) error {
+	subscribed, err := db.GetThreads(d.database, &types.ThreadQueryOptions{SubscribedAgent: &agentID})
+	if err != nil {
+		return err
+	}
+	for _, thread := range subscribed {
+		if thread.GUID == threadGUID {
+			return nil
+		}
+	}
+	if err := db.SubscribeThread(d.database, threadGUID, agentID, now); err != nil {
+		return err
+	}
+	return db.AppendThreadSubscribe(d.project.DBPath, db.ThreadSubscribeJSONLRecord{
+		ThreadGUID:   threadGUID,
+		AgentID:      agentID,
+		SubscribedAt: now,
+	})
+}
@@ -0,0 +1,247 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+)
+
+// Lease timing. A running daemon rewrites its heartbeat every
+// LeaseRefreshInterval; a lease whose heartbeat is older than LeaseTTL is
+// stale and may be taken by the next daemon.
+const (
+	LeaseRefreshInterval = 10 * time.Second
+	LeaseTTL             = 30 * time.Second
+)
+
+// Lease is the content of .fray/local/daemon.lease, held by the one daemon
+// allowed to serve a project.
+type Lease struct {
+	PID       int    `json:"pid"`
+	Hostname  string `json:"hostname"`
+	StartedAt int64  `json:"started_at"`
+	Heartbeat int64  `json:"heartbeat"`
+}
+
+// LeasePath returns the lease file for the project whose .fray directory is
+// frayDir.
+func LeasePath(frayDir string) string {
+	return filepath.Join(frayDir, "local", "daemon.lease")
+}
+
+// Age returns how long ago the lease holder last wrote its heartbeat.
+func (l Lease) Age(now time.Time) time.Duration {
+	return now.Sub(time.Unix(l.Heartbeat, 0))
+}
+
+// Stale reports whether the heartbeat is older than LeaseTTL.
+func (l Lease) Stale(now time.Time) bool {
+	return l.Age(now) > LeaseTTL
+}
+
+// local reports whether the lease was written on this host, where its pid
+// can be checked.
+func (l Lease) local() bool {
+	host, _ := os.Hostname()
+	return l.Hostname == host
+}
+
+// pidAlive reports whether the holder's process is still running on this
+// host.
+func (l Lease) pidAlive() bool {
+	return core.ProcessAlive(l.PID)
+}
+
+// Held reports whether a daemon still holds the lease: the heartbeat is
+// fresh and, when written on this host, the process is still running.
+func (l Lease) Held(now time.Time) bool {
+	if l.Stale(now) {
+		return false
+	}
+	return !l.local() || l.pidAlive()
+}
+
+// ReadLease returns the project's lease, or nil when there is none.
+func ReadLease(frayDir string) (*Lease, error) {
+	return readLeaseFile(LeasePath(frayDir))
+}
+
+func readLeaseFile(path string) (*Lease, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var lease Lease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		// A lease cut off mid-write has no heartbeat, so it reads as stale.
+		return &Lease{}, nil
+	}
+	return &lease, nil
+}
+
+// ClearLease removes the project's lease file.
+func ClearLease(frayDir string) error {
+	if err := os.Remove(LeasePath(frayDir)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// AcquireLease takes the project's lease for this process. A fresh lease
+// held by another daemon is refused; with takeover it is replaced once its
+// pid is confirmed dead, which is only possible on the host that wrote it.
+func AcquireLease(frayDir string, takeover bool) (*Lease, error) {
+	now := time.Now()
+	existing, err := ReadLease(frayDir)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !existing.Stale(now) && existing.PID != os.Getpid() {
+		age := existing.Age(now).Round(time.Second)
+		switch {
+		case !takeover:
+			return nil, fmt.Errorf("daemon already running (pid %d on %s, heartbeat %s ago); use --takeover if that process is gone",
+				existing.PID, existing.Hostname, age)
+		case !existing.local():
+			return nil, fmt.Errorf("cannot check pid %d on %s from this host; its lease goes stale %s after the last heartbeat",
+				existing.PID, existing.Hostname, LeaseTTL)
+		case existing.pidAlive():
+			return nil, fmt.Errorf("daemon pid %d is still running; stop it before taking over", existing.PID)
+		}
+	}
+
+	host, _ := os.Hostname()
+	lease := &Lease{
+		PID:       os.Getpid(),
+		Hostname:  host,
+		StartedAt: now.Unix(),
+		Heartbeat: now.Unix(),
+	}
+	// Create exclusively so two daemons starting or taking over together
+	// cannot both win; the loser re-reads the winner's lease.
+	if existing == nil {
+		err = writeLeaseExclusive(frayDir, lease)
+	} else {
+		err = replaceLease(frayDir, *existing, lease)
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrExist) || errors.Is(err, os.ErrNotExist) {
+			return AcquireLease(frayDir, takeover)
+		}
+		return nil, err
+	}
+	return lease, nil
+}
+
+// replaceLease swaps the lease that was read as old for lease. The old file
+// is first moved aside, which only one daemon can do, and checked to still
+// be old; a lease another daemon wrote in between is put back and
+// os.ErrExist returned.
+func replaceLease(frayDir string, old Lease, lease *Lease) error {
+	path := LeasePath(frayDir)
+	aside, err := os.CreateTemp(filepath.Dir(path), "daemon.lease.*.old")
+	if err != nil {
+		return err
+	}
+	asidePath := aside.Name()
+	aside.Close()
+	defer os.Remove(asidePath)
+
+	if err := os.Rename(path, asidePath); err != nil {
+		return err
+	}
+	moved, err := readLeaseFile(asidePath)
+	if err != nil {
+		return err
+	}
+	if moved == nil || *moved != old {
+		// Link fails if yet another daemon has already created a lease.
+		_ = os.Link(asidePath, path)
+		return os.ErrExist
+	}
+	return writeLeaseExclusive(frayDir, lease)
+}
+
+// RefreshLease rewrites the heartbeat of a lease this process holds. It
+// fails if another daemon has taken the lease in the meantime.
+func RefreshLease(frayDir string, lease *Lease) error {
+	current, err := ReadLease(frayDir)
+	if err != nil {
+		return err
+	}
+	if current != nil && (current.PID != lease.PID || current.Hostname != lease.Hostname) {
+		return fmt.Errorf("lease taken over by pid %d on %s", current.PID, current.Hostname)
+	}
+	lease.Heartbeat = time.Now().Unix()
+	return writeLease(frayDir, lease)
+}
+
+// ReleaseLease removes the lease if this process still holds it.
+func ReleaseLease(frayDir string, lease *Lease) error {
+	current, err := ReadLease(frayDir)
+	if err != nil || current == nil {
+		return err
+	}
+	if current.PID != lease.PID || current.Hostname != lease.Hostname {
+		return nil
+	}
+	return ClearLease(frayDir)
+}
+
+func writeLeaseExclusive(frayDir string, lease *Lease) error {
+	path := LeasePath(frayDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, writeErr := f.Write(data)
+	closeErr := f.Close()
+	if writeErr != nil || closeErr != nil {
+		_ = os.Remove(path)
+		return errors.Join(writeErr, closeErr)
+	}
+	return nil
+}
+
+// writeLease replaces the lease file in one rename, so readers never see a
+// partial heartbeat.
+func writeLease(frayDir string, lease *Lease) error {
+	path := LeasePath(frayDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "daemon.lease.*.tmp")
+	if err != nil {
+		return err
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Join(writeErr, closeErr)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
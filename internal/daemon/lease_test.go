@@ -0,0 +1,202 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// deadPID is above any real pid limit, so kill(pid, 0) always fails.
+const deadPID = 1073741824
+
+func writeTestLease(t *testing.T, frayDir string, lease Lease) {
+	t.Helper()
+	path := LeasePath(frayDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	data, err := json.Marshal(lease)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write lease: %v", err)
+	}
+}
+
+func TestAcquireLeaseWritesHolder(t *testing.T) {
+	frayDir := t.TempDir()
+	lease, err := AcquireLease(frayDir, false)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	stored, err := ReadLease(frayDir)
+	if err != nil || stored == nil {
+		t.Fatalf("read lease: %v", err)
+	}
+	host, _ := os.Hostname()
+	if stored.PID != os.Getpid() || stored.Hostname != host || stored.Heartbeat == 0 {
+		t.Fatalf("unexpected lease %+v", stored)
+	}
+	if !stored.Held(time.Now()) || !IsLocked(frayDir) {
+		t.Fatal("expected a fresh lease from this process to be held")
+	}
+
+	if err := ReleaseLease(frayDir, lease); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if stored, _ := ReadLease(frayDir); stored != nil {
+		t.Fatalf("expected release to remove the lease, got %+v", stored)
+	}
+}
+
+func TestAcquireLeaseRefusesFreshLease(t *testing.T) {
+	frayDir := t.TempDir()
+	host, _ := os.Hostname()
+	// The test runner's parent is alive for the whole test.
+	writeTestLease(t, frayDir, Lease{PID: os.Getppid(), Hostname: host, Heartbeat: time.Now().Unix()})
+
+	if _, err := AcquireLease(frayDir, false); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Fatalf("expected refusal, got %v", err)
+	}
+	if _, err := AcquireLease(frayDir, true); err == nil || !strings.Contains(err.Error(), "still running") {
+		t.Fatalf("expected takeover of a live pid to be refused, got %v", err)
+	}
+
+	writeTestLease(t, frayDir, Lease{PID: deadPID, Hostname: "elsewhere", Heartbeat: time.Now().Unix()})
+	if _, err := AcquireLease(frayDir, true); err == nil || !strings.Contains(err.Error(), "cannot check pid") {
+		t.Fatalf("expected takeover of another host's lease to be refused, got %v", err)
+	}
+	if !IsLocked(frayDir) {
+		t.Fatal("expected another host's fresh lease to count as held")
+	}
+}
+
+func TestAcquireLeaseTakeoverOfDeadPID(t *testing.T) {
+	frayDir := t.TempDir()
+	host, _ := os.Hostname()
+	writeTestLease(t, frayDir, Lease{PID: deadPID, Hostname: host, Heartbeat: time.Now().Unix()})
+
+	if IsLocked(frayDir) {
+		t.Fatal("expected a dead pid's lease not to count as held")
+	}
+	if _, err := AcquireLease(frayDir, false); err == nil {
+		t.Fatal("expected a fresh lease to need --takeover")
+	}
+	if _, err := AcquireLease(frayDir, true); err != nil {
+		t.Fatalf("takeover: %v", err)
+	}
+	if stored, _ := ReadLease(frayDir); stored == nil || stored.PID != os.Getpid() {
+		t.Fatalf("expected this process to hold the lease, got %+v", stored)
+	}
+}
+
+func TestReplaceLeaseOnlyOneWinner(t *testing.T) {
+	frayDir := t.TempDir()
+	stale := Lease{PID: deadPID, Hostname: "old-host", Heartbeat: time.Now().Add(-time.Hour).Unix()}
+	writeTestLease(t, frayDir, stale)
+
+	const contenders = 8
+	var wg sync.WaitGroup
+	wins := make(chan int, contenders)
+	for i := 1; i <= contenders; i++ {
+		wg.Add(1)
+		go func(pid int) {
+			defer wg.Done()
+			lease := &Lease{PID: pid, Hostname: "new-host", Heartbeat: time.Now().Unix()}
+			if replaceLease(frayDir, stale, lease) == nil {
+				wins <- pid
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(wins)
+
+	var winners []int
+	for pid := range wins {
+		winners = append(winners, pid)
+	}
+	if len(winners) != 1 {
+		t.Fatalf("expected exactly one takeover to win, got %v", winners)
+	}
+	if stored, _ := ReadLease(frayDir); stored == nil || stored.PID != winners[0] {
+		t.Fatalf("expected the winner's lease, got %+v", stored)
+	}
+}
+
+func TestReplaceLeaseKeepsNewerLease(t *testing.T) {
+	frayDir := t.TempDir()
+	stale := Lease{PID: deadPID, Hostname: "old-host", Heartbeat: time.Now().Add(-time.Hour).Unix()}
+	// Another daemon replaced the stale lease after we read it.
+	fresh := Lease{PID: 42, Hostname: "other-host", Heartbeat: time.Now().Unix()}
+	writeTestLease(t, frayDir, fresh)
+
+	err := replaceLease(frayDir, stale, &Lease{PID: 7, Hostname: "new-host", Heartbeat: time.Now().Unix()})
+	if !errors.Is(err, os.ErrExist) {
+		t.Fatalf("expected ErrExist, got %v", err)
+	}
+	if stored, _ := ReadLease(frayDir); stored == nil || *stored != fresh {
+		t.Fatalf("expected the newer lease to be restored, got %+v", stored)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(LeasePath(frayDir)))
+	if len(entries) != 1 {
+		t.Fatalf("expected no leftover lease files, got %d entries", len(entries))
+	}
+}
+
+func TestStaleLeaseDetection(t *testing.T) {
+	frayDir := t.TempDir()
+	old := time.Now().Add(-2 * LeaseTTL).Unix()
+	writeTestLease(t, frayDir, Lease{PID: os.Getppid(), Hostname: "elsewhere", Heartbeat: old})
+
+	stored, err := ReadLease(frayDir)
+	if err != nil || stored == nil {
+		t.Fatalf("read lease: %v", err)
+	}
+	if !stored.Stale(time.Now()) || stored.Held(time.Now()) {
+		t.Fatalf("expected lease with an old heartbeat to be stale, got %+v", stored)
+	}
+	if _, err := AcquireLease(frayDir, false); err != nil {
+		t.Fatalf("expected a stale lease to be taken without --takeover: %v", err)
+	}
+
+	// A lease cut off mid-write reads as stale too.
+	if err := os.WriteFile(LeasePath(frayDir), []byte(`{"pid":`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if stored, _ := ReadLease(frayDir); stored == nil || !stored.Stale(time.Now()) {
+		t.Fatalf("expected truncated lease to be stale, got %+v", stored)
+	}
+}
+
+func TestRefreshLeaseStopsAfterTakeover(t *testing.T) {
+	frayDir := t.TempDir()
+	lease, err := AcquireLease(frayDir, false)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	lease.Heartbeat = 1
+	if err := RefreshLease(frayDir, lease); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if stored, _ := ReadLease(frayDir); stored == nil || stored.Stale(time.Now()) {
+		t.Fatalf("expected refresh to renew the heartbeat, got %+v", stored)
+	}
+
+	writeTestLease(t, frayDir, Lease{PID: deadPID, Hostname: "elsewhere", Heartbeat: time.Now().Unix()})
+	if err := RefreshLease(frayDir, lease); err == nil || !strings.Contains(err.Error(), "taken over") {
+		t.Fatalf("expected refresh to notice the takeover, got %v", err)
+	}
+	if err := ReleaseLease(frayDir, lease); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if stored, _ := ReadLease(frayDir); stored == nil || stored.PID != deadPID {
+		t.Fatalf("expected release to leave another holder's lease, got %+v", stored)
+	}
+}
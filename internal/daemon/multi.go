@@ -0,0 +1,280 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+)
+
+// DefaultRefreshInterval is how often a Supervisor re-reads the channel
+// registry to pick up new, removed, or toggled projects.
+const DefaultRefreshInterval = 30 * time.Second
+
+// ProjectEntry is one project a Supervisor may run.
+type ProjectEntry struct {
+	ChannelID string `json:"channel_id"`
+	Name      string `json:"name"`
+	Root      string `json:"path"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// RegistryProjects lists the channels in the global registry, sorted by name.
+func RegistryProjects() ([]ProjectEntry, error) {
+	config, err := core.ReadGlobalConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+	entries := make([]ProjectEntry, 0, len(config.Channels))
+	for id, channel := range config.Channels {
+		entries = append(entries, ProjectEntry{
+			ChannelID: id,
+			Name:      channel.Name,
+			Root:      channel.Path,
+			Enabled:   !channel.DaemonDisabled,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name == entries[j].Name {
+			return entries[i].ChannelID < entries[j].ChannelID
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// SupervisorConfig configures a multi-project Supervisor.
+type SupervisorConfig struct {
+	Daemon          Config
+	RefreshInterval time.Duration
+	// Discover lists candidate projects; defaults to RegistryProjects.
+	Discover func() ([]ProjectEntry, error)
+	// Warn receives notices about projects being dropped; defaults to stderr.
+	Warn io.Writer
+}
+
+// Supervisor runs one Daemon per enabled project in a single process. Each
+// project keeps its own database, lock, debouncer, and processes; the
+// supervisor only drives their polls from one ticker.
+type Supervisor struct {
+	mu       sync.Mutex
+	cfg      SupervisorConfig
+	projects map[string]*supervisedProject // channel ID -> running project
+	prepare  func(entry ProjectEntry, d *Daemon)
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+type supervisedProject struct {
+	entry    ProjectEntry
+	daemon   *Daemon
+	database *sql.DB
+	ctx      context.Context
+}
+
+// NewSupervisor creates a Supervisor. Call Start to begin polling.
+func NewSupervisor(cfg SupervisorConfig) *Supervisor {
+	if cfg.Daemon.PollInterval == 0 {
+		cfg.Daemon.PollInterval = DefaultConfig().PollInterval
+	}
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = DefaultRefreshInterval
+	}
+	if cfg.Discover == nil {
+		cfg.Discover = RegistryProjects
+	}
+	if cfg.Warn == nil {
+		cfg.Warn = os.Stderr
+	}
+	return &Supervisor{
+		cfg:      cfg,
+		projects: make(map[string]*supervisedProject),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start discovers projects and begins the shared poll loop. It fails only if
+// the project list cannot be read at all.
+func (s *Supervisor) Start(ctx context.Context) error {
+	if err := s.refresh(ctx); err != nil {
+		return err
+	}
+	s.wg.Add(1)
+	go s.loop(ctx)
+	return nil
+}
+
+// Stop halts every project daemon and releases their locks.
+func (s *Supervisor) Stop() error {
+	close(s.stopCh)
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var firstErr error
+	for id, project := range s.projects {
+		if err := s.stopProject(project); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(s.projects, id)
+	}
+	return firstErr
+}
+
+// Running returns the projects currently being supervised, sorted by name.
+func (s *Supervisor) Running() []ProjectEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]ProjectEntry, 0, len(s.projects))
+	for _, project := range s.projects {
+		entries = append(entries, project.entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+func (s *Supervisor) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	poll := time.NewTicker(s.cfg.Daemon.PollInterval)
+	defer poll.Stop()
+	refresh := time.NewTicker(s.cfg.RefreshInterval)
+	defer refresh.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-refresh.C:
+			if err := s.refresh(ctx); err != nil {
+				s.warnf("refresh projects: %v", err)
+			}
+		case <-poll.C:
+			s.pollAll()
+		}
+	}
+}
+
+// pollAll runs one tick for every project, dropping any whose directory has
+// disappeared or whose daemon stopped itself (e.g. on a schema mismatch).
+func (s *Supervisor) pollAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, project := range s.projects {
+		if !projectExists(project.entry.Root) {
+			s.warnf("dropping %s: %s no longer exists", project.entry.Name, project.entry.Root)
+			_ = s.stopProject(project)
+			delete(s.projects, id)
+			continue
+		}
+		if project.daemon.stopped() {
+			s.warnf("dropping %s: daemon stopped", project.entry.Name)
+			_ = s.stopProject(project)
+			delete(s.projects, id)
+			continue
+		}
+		project.daemon.poll(project.ctx)
+	}
+}
+
+// refresh reconciles running daemons with the discovered project list.
+func (s *Supervisor) refresh(ctx context.Context) error {
+	entries, err := s.cfg.Discover()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := make(map[string]ProjectEntry, len(entries))
+	for _, entry := range entries {
+		if entry.Enabled {
+			wanted[entry.ChannelID] = entry
+		}
+	}
+
+	for id, project := range s.projects {
+		if _, ok := wanted[id]; !ok {
+			s.warnf("dropping %s: removed or disabled", project.entry.Name)
+			_ = s.stopProject(project)
+			delete(s.projects, id)
+		}
+	}
+
+	for _, entry := range entries {
+		if !entry.Enabled {
+			continue
+		}
+		if _, running := s.projects[entry.ChannelID]; running {
+			continue
+		}
+		project, err := s.startProject(ctx, entry)
+		if err != nil {
+			s.warnf("skipping %s: %v", entry.Name, err)
+			continue
+		}
+		s.projects[entry.ChannelID] = project
+	}
+	return nil
+}
+
+func (s *Supervisor) startProject(ctx context.Context, entry ProjectEntry) (*supervisedProject, error) {
+	if !projectExists(entry.Root) {
+		return nil, fmt.Errorf("%s has no .fray directory", entry.Root)
+	}
+	project := core.Project{Root: entry.Root, DBPath: filepath.Join(entry.Root, ".fray", "fray.db")}
+	database, err := db.OpenDatabase(project)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.InitSchema(database); err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	cfg := s.cfg.Daemon
+	cfg.Name = entry.Name
+	d := New(project, database, cfg)
+	if s.prepare != nil {
+		s.prepare(entry, d)
+	}
+	procCtx, err := d.begin(ctx)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+	return &supervisedProject{entry: entry, daemon: d, database: database, ctx: procCtx}, nil
+}
+
+func (s *Supervisor) stopProject(project *supervisedProject) error {
+	err := project.daemon.Stop()
+	if err != nil && !projectExists(project.entry.Root) {
+		// The lease file went away with the project.
+		err = nil
+	}
+	project.database.Close()
+	return err
+}
+
+func (s *Supervisor) warnf(format string, args ...any) {
+	fmt.Fprintf(s.cfg.Warn, "[daemon] warning: "+format+"\n", args...)
+}
+
+func projectExists(root string) bool {
+	info, err := os.Stat(filepath.Join(root, ".fray"))
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,98 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// cancelableDriver records spawns like recordingDriver, but its processes die
+// with the daemon's context so stopping a project does not wait on them.
+type cancelableDriver struct {
+	recordingDriver
+}
+
+func (c *cancelableDriver) Spawn(ctx context.Context, agent types.Agent, prompt string) (*Process, error) {
+	cmd := exec.CommandContext(ctx, "sleep", "30")
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	c.t.Cleanup(func() { _ = cmd.Process.Kill() })
+	c.spawned = append(c.spawned, agent.AgentID)
+	return &Process{Cmd: cmd, StartedAt: time.Now()}, nil
+}
+
+func TestSupervisor_ProjectIsolation(t *testing.T) {
+	alpha := newTestHarness(t)
+	beta := newTestHarness(t)
+	alpha.createAgent("dev", true)
+	beta.createAgent("dev", true)
+
+	entries := []ProjectEntry{
+		{ChannelID: "ch-alpha", Name: "alpha", Root: alpha.projectDir, Enabled: true},
+		{ChannelID: "ch-beta", Name: "beta", Root: beta.projectDir, Enabled: true},
+		{ChannelID: "ch-off", Name: "off", Root: t.TempDir(), Enabled: false},
+	}
+	var warnings bytes.Buffer
+	s := NewSupervisor(SupervisorConfig{
+		Discover: func() ([]ProjectEntry, error) { return entries, nil },
+		Warn:     &warnings,
+	})
+	drivers := map[string]*cancelableDriver{}
+	s.prepare = func(entry ProjectEntry, d *Daemon) {
+		driver := &cancelableDriver{recordingDriver{t: t}}
+		drivers[entry.Name] = driver
+		d.drivers["claude"] = driver
+	}
+
+	ctx := context.Background()
+	if err := s.refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Stop() })
+	if running := s.Running(); len(running) != 2 || running[0].Name != "alpha" || running[1].Name != "beta" {
+		t.Fatalf("expected alpha and beta running, got %+v", running)
+	}
+
+	alpha.postMessage("adam", "@dev please look at the build", types.MessageTypeUser)
+	s.pollAll()
+	s.pollAll()
+
+	if got := drivers["alpha"].spawned; len(got) != 1 || got[0] != "dev" {
+		t.Fatalf("expected alpha's mention to wake alpha's dev, got %v", got)
+	}
+	if got := drivers["beta"].spawned; len(got) != 0 {
+		t.Fatalf("expected beta untouched by alpha's mention, got %v", got)
+	}
+
+	// A project whose directory disappears is dropped with a warning.
+	if err := os.RemoveAll(filepath.Join(beta.projectDir, ".fray")); err != nil {
+		t.Fatalf("remove beta: %v", err)
+	}
+	s.pollAll()
+	if running := s.Running(); len(running) != 1 || running[0].Name != "alpha" {
+		t.Fatalf("expected only alpha after beta removal, got %+v", running)
+	}
+	if !strings.Contains(warnings.String(), "dropping beta") {
+		t.Fatalf("expected drop warning, got %q", warnings.String())
+	}
+
+	// Disabling a channel stops its daemon on the next refresh.
+	entries[0].Enabled = false
+	if err := s.refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if running := s.Running(); len(running) != 0 {
+		t.Fatalf("expected no projects after disabling alpha, got %+v", running)
+	}
+	if IsLocked(filepath.Join(alpha.projectDir, ".fray")) {
+		t.Fatal("expected alpha's lock released after disabling")
+	}
+}
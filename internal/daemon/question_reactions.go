@@ -0,0 +1,149 @@
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+const (
+	// QuestionResolveReactionsConfigKey lists the reactions that answer a
+	// question when its recipient leaves one on the asking message.
+	QuestionResolveReactionsConfigKey = "question_resolve_reactions"
+	// QuestionDeclineReactionsConfigKey lists the reactions that decline one.
+	QuestionDeclineReactionsConfigKey = "question_decline_reactions"
+	// questionReactionWatermarkKey is the reacted_at (ms) of the last
+	// reaction scanned.
+	questionReactionWatermarkKey = "question_reaction_watermark"
+
+	defaultResolveReactions = "✅"
+	defaultDeclineReactions = "👎"
+)
+
+// reactionSet reads a comma-separated reaction list from config, falling
+// back to def when unset.
+func (d *Daemon) reactionSet(key, def string) map[string]bool {
+	value, err := db.GetConfig(d.database, key)
+	if err != nil || strings.TrimSpace(value) == "" {
+		value = def
+	}
+	set := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		if reaction := strings.TrimSpace(part); reaction != "" {
+			set[reaction] = true
+		}
+	}
+	return set
+}
+
+// checkQuestionReactions treats a question recipient's reaction on the
+// asking message as an answer: a resolve reaction marks the question
+// answered and a decline reaction marks it declined. Each transition posts a
+// system note, which an answer's AnsweredIn points at.
+func (d *Daemon) checkQuestionReactions() {
+	watermark, err := db.GetConfig(d.database, questionReactionWatermarkKey)
+	if err != nil {
+		d.debugf("question reactions: error reading watermark: %v", err)
+		return
+	}
+	if watermark == "" {
+		// First run: only act on reactions left from now on.
+		db.SetConfig(d.database, questionReactionWatermarkKey, strconv.FormatInt(time.Now().UnixMilli(), 10))
+		return
+	}
+	since, err := strconv.ParseInt(watermark, 10, 64)
+	if err != nil {
+		d.debugf("question reactions: invalid watermark %q: %v", watermark, err)
+		return
+	}
+
+	reactions, err := db.GetReactionsSince(d.database, since)
+	if err != nil {
+		d.debugf("question reactions: error getting reactions: %v", err)
+		return
+	}
+	if len(reactions) == 0 {
+		return
+	}
+
+	resolve := d.reactionSet(QuestionResolveReactionsConfigKey, defaultResolveReactions)
+	decline := d.reactionSet(QuestionDeclineReactionsConfigKey, defaultDeclineReactions)
+	for _, reaction := range reactions {
+		var status types.QuestionStatus
+		switch {
+		case resolve[reaction.Emoji]:
+			status = types.QuestionStatusAnswered
+		case decline[reaction.Emoji]:
+			status = types.QuestionStatusDeclined
+		default:
+			continue
+		}
+		if err := d.settleQuestionsByReaction(reaction, status); err != nil {
+			d.debugf("question reactions: %s on %s: %v", reaction.Emoji, reaction.MessageGUID, err)
+		}
+	}
+
+	last := strconv.FormatInt(reactions[len(reactions)-1].ReactedAt, 10)
+	if err := db.SetConfig(d.database, questionReactionWatermarkKey, last); err != nil {
+		d.debugf("question reactions: error saving watermark: %v", err)
+	}
+}
+
+// settleQuestionsByReaction moves the open questions asked in the reacted-to
+// message and addressed to the reactor to status.
+func (d *Daemon) settleQuestionsByReaction(reaction db.ReactionQueryResult, status types.QuestionStatus) error {
+	questions, err := db.GetQuestions(d.database, &types.QuestionQueryOptions{
+		Statuses: []types.QuestionStatus{types.QuestionStatusOpen},
+		AskedIn:  &reaction.MessageGUID,
+		ToAgent:  &reaction.ReactedBy,
+	})
+	if err != nil || len(questions) == 0 {
+		return err
+	}
+
+	verb := "answered"
+	if status == types.QuestionStatusDeclined {
+		verb = "declined"
+	}
+	res := make([]string, 0, len(questions))
+	for _, question := range questions {
+		res = append(res, fmt.Sprintf("%q", question.Re))
+	}
+	asked := reaction.MessageGUID
+	note, err := db.CreateMessage(d.database, types.Message{
+		TS:        time.Now().Unix(),
+		FromAgent: "system",
+		Body:      fmt.Sprintf("@%s %s %s with %s", reaction.ReactedBy, verb, strings.Join(res, ", "), reaction.Emoji),
+		Type:      types.MessageTypeEvent,
+		ReplyTo:   &asked,
+		Home:      reaction.Home,
+	})
+	if err != nil {
+		return err
+	}
+	if err := db.AppendMessage(d.project.DBPath, note); err != nil {
+		return err
+	}
+
+	statusValue := string(status)
+	for _, question := range questions {
+		updates := db.QuestionUpdates{Status: types.OptionalString{Set: true, Value: &statusValue}}
+		record := db.QuestionUpdateJSONLRecord{GUID: question.GUID, Status: &statusValue}
+		if status == types.QuestionStatusAnswered {
+			updates.AnsweredIn = types.OptionalString{Set: true, Value: &note.ID}
+			record.AnsweredIn = &note.ID
+		}
+		if _, err := db.UpdateQuestion(d.database, question.GUID, updates); err != nil {
+			return err
+		}
+		if err := db.AppendQuestionUpdate(d.project.DBPath, record); err != nil {
+			return err
+		}
+		d.debugf("question reactions: @%s %s %s", reaction.ReactedBy, verb, question.GUID)
+	}
+	return nil
+}
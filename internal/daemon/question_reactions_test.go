@@ -0,0 +1,147 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// askQuestion posts a question from pm to recipient and returns it with the
+// asking message.
+func (h *testHarness) askQuestion(recipient, re string) (types.Question, types.Message) {
+	h.t.Helper()
+	msg := h.postMessage("pm", "@"+recipient+" "+re, types.MessageTypeAgent)
+	question, err := db.CreateQuestion(h.db, types.Question{
+		Re:        re,
+		FromAgent: "pm",
+		ToAgent:   &recipient,
+		Status:    types.QuestionStatusOpen,
+		AskedIn:   &msg.ID,
+		CreatedAt: msg.TS,
+	})
+	if err != nil {
+		h.t.Fatalf("create question: %v", err)
+	}
+	return question, msg
+}
+
+func (h *testHarness) react(messageGUID, agentID, emoji string, reactedAt int64) {
+	h.t.Helper()
+	if err := db.InsertReaction(h.db, messageGUID, agentID, emoji, reactedAt); err != nil {
+		h.t.Fatalf("insert reaction: %v", err)
+	}
+}
+
+func (h *testHarness) questionStatus(guid string) *types.Question {
+	h.t.Helper()
+	question, err := db.GetQuestion(h.db, guid)
+	if err != nil || question == nil {
+		h.t.Fatalf("get question %s: %v", guid, err)
+	}
+	return question
+}
+
+func TestQuestionReactionsResolveFromRecipientOnly(t *testing.T) {
+	h := newTestHarness(t)
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	question, asked := h.askQuestion("dev", "ship the migration today?")
+
+	d.checkQuestionReactions() // sets the watermark
+	now := time.Now().UnixMilli() + 1
+	h.react(asked.ID, "qa", "✅", now)
+	h.react(asked.ID, "dev", "🎉", now+1)
+	d.checkQuestionReactions()
+	if got := h.questionStatus(question.GUID); got.Status != types.QuestionStatusOpen {
+		t.Fatalf("expected non-recipient and non-resolving reactions to leave the question open, got %s", got.Status)
+	}
+
+	h.react(asked.ID, "dev", "✅", now+2)
+	d.checkQuestionReactions()
+	got := h.questionStatus(question.GUID)
+	if got.Status != types.QuestionStatusAnswered || got.AnsweredIn == nil {
+		t.Fatalf("expected the recipient's ✅ to answer the question, got %+v", got)
+	}
+	note, err := db.GetMessage(h.db, *got.AnsweredIn)
+	if err != nil || note == nil {
+		t.Fatalf("get note: %v", err)
+	}
+	if note.FromAgent != "system" || note.ReplyTo == nil || *note.ReplyTo != asked.ID || !strings.Contains(note.Body, "@dev answered") || !strings.Contains(note.Body, "✅") {
+		t.Fatalf("unexpected note %+v", note)
+	}
+
+	updates, err := db.ReadQuestions(h.projectPath)
+	if err != nil {
+		t.Fatalf("read questions: %v", err)
+	}
+	for _, q := range updates {
+		if q.GUID == question.GUID && (q.Status != string(types.QuestionStatusAnswered) || q.AnsweredIn == nil || *q.AnsweredIn != note.ID) {
+			t.Fatalf("expected the answer in JSONL, got %+v", q)
+		}
+	}
+}
+
+func TestQuestionReactionsDecline(t *testing.T) {
+	h := newTestHarness(t)
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	question, asked := h.askQuestion("dev", "can you own the rollout?")
+
+	d.checkQuestionReactions()
+	h.react(asked.ID, "dev", "👎", time.Now().UnixMilli()+1)
+	d.checkQuestionReactions()
+
+	got := h.questionStatus(question.GUID)
+	if got.Status != types.QuestionStatusDeclined || got.AnsweredIn != nil {
+		t.Fatalf("expected 👎 to decline without an answer, got %+v", got)
+	}
+}
+
+func TestQuestionReactionsConfiguredSet(t *testing.T) {
+	h := newTestHarness(t)
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	if err := db.SetConfig(h.db, QuestionResolveReactionsConfigKey, "👍, 🆗"); err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+	first, firstAsked := h.askQuestion("dev", "merge now?")
+	second, secondAsked := h.askQuestion("dev", "tag the release?")
+
+	d.checkQuestionReactions()
+	now := time.Now().UnixMilli() + 1
+	h.react(firstAsked.ID, "dev", "✅", now)
+	h.react(secondAsked.ID, "dev", "🆗", now+1)
+	d.checkQuestionReactions()
+
+	if got := h.questionStatus(first.GUID); got.Status != types.QuestionStatusOpen {
+		t.Fatalf("expected ✅ to no longer resolve once the set is configured, got %s", got.Status)
+	}
+	if got := h.questionStatus(second.GUID); got.Status != types.QuestionStatusAnswered {
+		t.Fatalf("expected configured 🆗 to resolve, got %s", got.Status)
+	}
+}
+
+func TestQuestionReactionsNotReplayedAfterRestart(t *testing.T) {
+	h := newTestHarness(t)
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	question, asked := h.askQuestion("dev", "bump the version?")
+
+	d.checkQuestionReactions()
+	h.react(asked.ID, "dev", "✅", time.Now().UnixMilli()+1)
+	d.checkQuestionReactions()
+	if got := h.questionStatus(question.GUID); got.Status != types.QuestionStatusAnswered {
+		t.Fatalf("expected the question answered, got %s", got.Status)
+	}
+
+	// Reopen it so a replayed reaction would be visible, then restart.
+	open := string(types.QuestionStatusOpen)
+	if _, err := db.UpdateQuestion(h.db, question.GUID, db.QuestionUpdates{Status: types.OptionalString{Set: true, Value: &open}}); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	restarted := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	restarted.checkQuestionReactions()
+	if got := h.questionStatus(question.GUID); got.Status != types.QuestionStatusOpen {
+		t.Fatalf("expected the watermark to stop the old ✅ being processed again, got %s", got.Status)
+	}
+}
@@ -0,0 +1,69 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// recoverPendingWork picks up what a previous daemon left unfinished. The
+// debouncer has already reloaded its persisted queue; this clears presence
+// held by sessions that died with the old daemon and re-runs the wake rules
+// over every managed agent's messages past its mention watermark, queuing
+// the ones that would have woken it so the next wake includes them.
+// Sessions started from the CLI are left alone.
+func (d *Daemon) recoverPendingWork() error {
+	agents, err := d.getManagedAgents()
+	if err != nil {
+		return fmt.Errorf("get managed agents: %w", err)
+	}
+	sessions, err := db.GetDaemonSessions(d.database)
+	if err != nil {
+		return fmt.Errorf("get daemon sessions: %w", err)
+	}
+
+	var errs []error
+	reset := 0
+	for _, agent := range agents {
+		if isRetiredSub(agent) {
+			continue
+		}
+
+		// Daemon sessions run under the daemon's context, so none outlive
+		// it. A spawning/active presence left behind would queue mentions
+		// forever.
+		if _, ours := sessions[agent.AgentID]; ours {
+			if agent.Presence == types.PresenceSpawning || agent.Presence == types.PresenceActive {
+				if err := db.UpdateAgentPresence(d.database, agent.AgentID, types.PresenceOffline); err != nil {
+					errs = append(errs, fmt.Errorf("@%s: reset presence: %w", agent.AgentID, err))
+				} else {
+					reset++
+				}
+			}
+			if err := db.ClearDaemonSession(d.database, agent.AgentID); err != nil {
+				errs = append(errs, fmt.Errorf("@%s: clear session: %w", agent.AgentID, err))
+			}
+		}
+
+		messages, err := d.getMessagesAfter(d.debouncer.GetWatermark(agent.AgentID), agent)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("@%s: get messages: %w", agent.AgentID, err))
+			continue
+		}
+		for _, msg := range messages {
+			if d.mentionSkipReason(agent, msg) == "" {
+				d.debouncer.QueueMention(agent.AgentID, msg.ID)
+			}
+		}
+	}
+
+	pendingAgents, mentions := d.debouncer.PendingTotals()
+	if mentions > 0 || reset > 0 {
+		fmt.Fprintf(os.Stderr, "%s Recovered %d pending mention(s) for %d agent(s); reset %d stale session(s)\n",
+			d.logPrefix, mentions, pendingAgents, reset)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestDebouncer_PendingSurvivesRestart(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("alice", true)
+
+	h.debouncer.QueueMention("alice", "msg-1")
+	h.debouncer.QueueMention("alice", "msg-2")
+	h.debouncer.QueueMention("bob", "msg-3")
+
+	restarted := NewMentionDebouncer(h.db, h.projectPath)
+	if got := restarted.FlushPending("alice"); !reflect.DeepEqual(got, []string{"msg-1", "msg-2"}) {
+		t.Fatalf("expected alice's queue to survive restart, got %v", got)
+	}
+
+	// A flush is persisted too: only bob's mention is left after another restart.
+	again := NewMentionDebouncer(h.db, h.projectPath)
+	if again.HasPending("alice") {
+		t.Fatalf("expected flushed mentions to stay flushed, got %v", again.FlushPending("alice"))
+	}
+	if got := again.FlushPending("bob"); !reflect.DeepEqual(got, []string{"msg-3"}) {
+		t.Fatalf("expected bob's queue to survive restart, got %v", got)
+	}
+}
+
+// busyDaemon queues two wakes for dev while a session is active, and returns
+// the flush set the running daemon would have used.
+func busyDaemon(h *testHarness) []string {
+	h.t.Helper()
+	dev := h.createAgent("dev", true)
+	h.createAgent("adam", false)
+	if err := db.UpdateAgentPresence(h.db, "dev", types.PresenceActive); err != nil {
+		h.t.Fatalf("set presence: %v", err)
+	}
+	if err := db.SetDaemonSession(h.db, "dev", "sess-dev"); err != nil {
+		h.t.Fatalf("record session: %v", err)
+	}
+	dev.Presence = types.PresenceActive
+
+	h.postMessage("adam", "@dev first task", types.MessageTypeUser)
+	h.postMessage("adam", "fyi @dev nothing to do", types.MessageTypeUser)
+	h.postMessage("adam", "@dev second task", types.MessageTypeUser)
+
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.checkMentions(context.Background(), dev)
+	queued := append([]string(nil), d.debouncer.pending["dev"]...)
+	if len(queued) != 2 {
+		h.t.Fatalf("expected both wakes queued before restart, got %v", queued)
+	}
+	return queued
+}
+
+func TestRecoverPendingWork_ReloadsQueue(t *testing.T) {
+	h := newTestHarness(t)
+	want := busyDaemon(h)
+
+	// The daemon dies with the session; a new one starts.
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	if err := d.recoverPendingWork(); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	agent, _ := db.GetAgent(h.db, "dev")
+	if agent.Presence != types.PresenceOffline {
+		t.Fatalf("expected stale active presence to be reset, got %s", agent.Presence)
+	}
+	if sessions, _ := db.GetDaemonSessions(h.db); len(sessions) != 0 {
+		t.Fatalf("expected the dead session to be forgotten, got %v", sessions)
+	}
+	if got := d.debouncer.FlushPending("dev"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected recovered flush set %v, got %v", want, got)
+	}
+}
+
+func TestRecoverPendingWork_LeavesCLISessions(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("dev", true)
+	// fray back marks a managed agent active without the daemon.
+	if err := db.UpdateAgentPresence(h.db, "dev", types.PresenceActive); err != nil {
+		t.Fatalf("set presence: %v", err)
+	}
+
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	if err := d.recoverPendingWork(); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if agent, _ := db.GetAgent(h.db, "dev"); agent.Presence != types.PresenceActive {
+		t.Fatalf("expected a CLI session's presence to be kept, got %s", agent.Presence)
+	}
+}
+
+func TestRecoverPendingWork_RescansWithoutQueue(t *testing.T) {
+	h := newTestHarness(t)
+	want := busyDaemon(h)
+
+	// Even without the persisted queue, the watermark scan finds the same
+	// wakes, since queued messages never advance it.
+	if err := db.ClearPendingMentions(h.db, "dev"); err != nil {
+		t.Fatalf("clear pending: %v", err)
+	}
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	if err := d.recoverPendingWork(); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if got := d.debouncer.FlushPending("dev"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected rescanned flush set %v, got %v", want, got)
+	}
+
+	// The next poll wakes dev once with every recovered mention.
+	if err := d.recoverPendingWork(); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	driver := &recordingDriver{t: t}
+	d.drivers["claude"] = driver
+	dev, _ := db.GetAgent(h.db, "dev")
+	d.checkMentions(context.Background(), *dev)
+	if len(driver.spawned) != 1 {
+		t.Fatalf("expected one spawn after recovery, got %v", driver.spawned)
+	}
+	if got := d.debouncer.GetWatermark("dev"); got != want[len(want)-1] {
+		t.Fatalf("expected watermark past %s, got %q", want[len(want)-1], got)
+	}
+}
@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// checkSessionResponses links each running session's first post to the
+// mention that woke it.
+func (d *Daemon) checkSessionResponses() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for agentID, proc := range d.processes {
+		d.recordSessionResponse(agentID, proc)
+	}
+}
+
+// recordSessionResponse appends a session_response once a spawned agent
+// first posts, with the latency from the triggering mention. Sessions
+// without a trigger, or already linked, are skipped. Must be called with
+// d.mu held.
+func (d *Daemon) recordSessionResponse(agentID string, proc *Process) {
+	if proc.TriggeredBy == "" || proc.responded {
+		return
+	}
+	msg, err := db.GetFirstMessageSince(d.database, agentID, proc.StartedAt.Unix())
+	if err != nil || msg == nil {
+		return
+	}
+	trigger, err := db.GetMessage(d.database, proc.TriggeredBy)
+	if err != nil || trigger == nil {
+		return
+	}
+	proc.responded = true
+
+	latency := (msg.TS - trigger.TS) * 1000
+	if latency < 0 {
+		latency = 0
+	}
+	d.debugf("  @%s responded to %s with %s after %dms", agentID, trigger.ID, msg.ID, latency)
+	db.AppendSessionResponse(d.project.DBPath, types.SessionResponse{
+		AgentID:      agentID,
+		SessionID:    proc.SessionID,
+		MessageGUID:  msg.ID,
+		RespondingTo: trigger.ID,
+		LatencyMs:    latency,
+		RespondedAt:  time.Now().Unix(),
+	})
+}
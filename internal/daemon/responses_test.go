@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestSessionResponseLinksFirstPostToTrigger(t *testing.T) {
+	h := newTestHarness(t)
+	dev := h.createAgent("dev", true)
+
+	driver := &recordingDriver{t: t}
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.drivers["claude"] = driver
+
+	now := time.Now().Unix()
+	mention := testutil.NewMessage("adam", "@dev can you look at the flaky test?", types.MessageTypeUser)
+	mention.TS = now - 100
+	trigger := h.createMessage(mention)
+
+	d.checkMentions(context.Background(), dev)
+	if len(driver.spawned) != 1 {
+		t.Fatalf("expected the mention to spawn dev, got %v", driver.spawned)
+	}
+	d.mu.Lock()
+	proc := d.processes["dev"]
+	d.mu.Unlock()
+	if proc == nil || proc.TriggeredBy != trigger.ID {
+		t.Fatalf("expected the session to record its trigger, got %+v", proc)
+	}
+
+	// Nothing to link until dev posts.
+	d.checkSessionResponses()
+	sessions, err := db.ReadAgentSessions(h.projectPath, "dev")
+	if err != nil || len(sessions) != 1 || sessions[0].ResponseGUID != nil {
+		t.Fatalf("expected an unlinked session, got %+v (%v)", sessions, err)
+	}
+
+	reply := testutil.NewMessage("dev", "found it, the fixture reused a port", types.MessageTypeAgent)
+	reply.TS = now + 5
+	first := h.createMessage(reply)
+	later := testutil.NewMessage("dev", "and pushed the fix", types.MessageTypeAgent)
+	later.TS = now + 30
+	h.createMessage(later)
+
+	d.checkSessionResponses()
+	d.checkSessionResponses()
+
+	sessions, err = db.ReadAgentSessions(h.projectPath, "dev")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("read sessions: %+v (%v)", sessions, err)
+	}
+	session := sessions[0]
+	if session.TriggeredBy == nil || *session.TriggeredBy != trigger.ID {
+		t.Fatalf("expected trigger %s, got %+v", trigger.ID, session)
+	}
+	if session.ResponseGUID == nil || *session.ResponseGUID != first.ID {
+		t.Fatalf("expected the first post %s to be linked, got %+v", first.ID, session)
+	}
+	if session.ResponseLatencyMs == nil || *session.ResponseLatencyMs != 105000 {
+		t.Fatalf("expected 105s latency, got %+v", session.ResponseLatencyMs)
+	}
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(h.projectPath), "agents.jsonl"))
+	if err != nil {
+		t.Fatalf("read agents.jsonl: %v", err)
+	}
+	if got := strings.Count(string(data), `"type":"session_response"`); got != 1 {
+		t.Fatalf("expected one session_response record, got %d", got)
+	}
+}
@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+)
+
+// retentionCheckInterval is how often the daemon asks whether a retention
+// run is due. The runner itself decides due-ness from its last report.
+const retentionCheckInterval = time.Hour
+
+// RetentionFunc applies a project's retention policies if a run is due. It
+// returns a non-empty reason when a due run was skipped (e.g. the git
+// guardrails failed).
+type RetentionFunc func(project core.Project, database *sql.DB, now time.Time) (skipped string, err error)
+
+// checkRetention runs the retention hook at most once per
+// retentionCheckInterval. It runs inline so the rebuild it may do never
+// overlaps a poll.
+func (d *Daemon) checkRetention() {
+	if d.retention == nil {
+		return
+	}
+	now := time.Now()
+	if !d.retentionAt.IsZero() && now.Sub(d.retentionAt) < retentionCheckInterval {
+		return
+	}
+	d.retentionAt = now
+
+	skipped, err := d.retention(d.project, d.database, now)
+	if err != nil {
+		d.debugf("retention: %v", err)
+		return
+	}
+	if skipped != "" {
+		fmt.Fprintf(os.Stderr, "%s Warning: retention run skipped: %s\n", d.logPrefix, skipped)
+	}
+}
@@ -0,0 +1,38 @@
+package daemon
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+)
+
+func TestCheckRetentionThrottlesRuns(t *testing.T) {
+	h := newTestHarness(t)
+	calls := 0
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{
+		Retention: func(project core.Project, database *sql.DB, now time.Time) (string, error) {
+			calls++
+			if project.Root != h.projectDir || database != h.db {
+				t.Errorf("retention got the wrong project or database")
+			}
+			return "", nil
+		},
+	})
+
+	d.checkRetention()
+	d.checkRetention()
+	if calls != 1 {
+		t.Fatalf("expected one retention check within the interval, got %d", calls)
+	}
+
+	d.retentionAt = time.Now().Add(-retentionCheckInterval)
+	d.checkRetention()
+	if calls != 2 {
+		t.Fatalf("expected another retention check after the interval, got %d", calls)
+	}
+
+	// No hook, no retention.
+	New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{}).checkRetention()
+}
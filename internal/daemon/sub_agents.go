@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"sort"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// subMentionFanoutConfigKey makes mentions of a parent agent wake its active
+// sub-agents too.
+const subMentionFanoutConfigKey = "sub_mention_fanout"
+
+// isRetiredSub reports whether agent is a sub-agent retired with fray sub
+// retire. Retired sub-agents are not woken until they come back.
+func isRetiredSub(agent types.Agent) bool {
+	return agent.ParentAgent != nil && agent.LeftAt != nil
+}
+
+// subMentionFanout reports whether sub_mention_fanout is enabled.
+func (d *Daemon) subMentionFanout() bool {
+	value, err := db.GetConfig(d.database, subMentionFanoutConfigKey)
+	if err != nil {
+		return false
+	}
+	enabled, _ := db.ParseConfigBool(value)
+	return enabled
+}
+
+// mergeMessages merges two mention lists, dropping duplicates and keeping
+// the result in (ts, id) order.
+func mergeMessages(a, b []types.Message) []types.Message {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]types.Message, 0, len(a)+len(b))
+	for _, list := range [][]types.Message{a, b} {
+		for _, msg := range list {
+			if seen[msg.ID] {
+				continue
+			}
+			seen[msg.ID] = true
+			merged = append(merged, msg)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].TS != merged[j].TS {
+			return merged[i].TS < merged[j].TS
+		}
+		return merged[i].ID < merged[j].ID
+	})
+	return merged
+}
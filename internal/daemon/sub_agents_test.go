@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// createSubAgent creates a managed sub-agent of parent.
+func (h *testHarness) createSubAgent(agentID, parent string, leftAt *int64) types.Agent {
+	h.t.Helper()
+
+	now := time.Now().Unix()
+	agent := types.Agent{
+		AgentID:      agentID,
+		RegisteredAt: now,
+		LastSeen:     now,
+		LeftAt:       leftAt,
+		Managed:      true,
+		Invoke:       &types.InvokeConfig{Driver: "claude", PromptDelivery: types.PromptDeliveryStdin},
+		Presence:     types.PresenceOffline,
+		ParentAgent:  &parent,
+	}
+	if err := db.CreateAgent(h.db, agent); err != nil {
+		h.t.Fatalf("create agent %s: %v", agentID, err)
+	}
+	created, err := db.GetAgent(h.db, agentID)
+	if err != nil {
+		h.t.Fatalf("get agent %s: %v", agentID, err)
+	}
+	return *created
+}
+
+func TestSubMentionFanout(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("alice", false)
+	sub := h.createSubAgent("alice.1", "alice", nil)
+
+	driver := &recordingDriver{t: t}
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.drivers["claude"] = driver
+
+	h.postMessage("adam", "@alice can you split the frontend?", types.MessageTypeUser)
+
+	d.checkMentions(context.Background(), sub)
+	if len(driver.spawned) != 0 {
+		t.Fatalf("expected parent mention not to wake sub without fanout, got %v", driver.spawned)
+	}
+
+	if err := db.SetConfig(h.db, subMentionFanoutConfigKey, "true"); err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+	d.checkMentions(context.Background(), sub)
+	if len(driver.spawned) != 1 || driver.spawned[0] != "alice.1" {
+		t.Fatalf("expected parent mention to wake alice.1, got %v", driver.spawned)
+	}
+}
+
+func TestRetiredSubIsNotWoken(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("alice", false)
+	left := time.Now().Unix()
+	h.createSubAgent("alice.1", "alice", &left)
+	if err := db.SetConfig(h.db, subMentionFanoutConfigKey, "true"); err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+
+	driver := &recordingDriver{t: t}
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.drivers["claude"] = driver
+
+	h.postMessage("adam", "@alice.1 are you there?", types.MessageTypeUser)
+	h.postMessage("adam", "@alice status?", types.MessageTypeUser)
+
+	d.poll(context.Background())
+	if len(driver.spawned) != 0 {
+		t.Fatalf("expected retired sub-agent not to be woken, got %v", driver.spawned)
+	}
+}
@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// usageTailBytes bounds how much trailing stdout is kept for usage parsing.
+const usageTailBytes = 64 * 1024
+
+// UsageDriver is implemented by drivers that can report token usage from a
+// finished session's output.
+type UsageDriver interface {
+	// ParseUsage extracts token usage from the tail of the session's stdout.
+	// Returns nil when the output carries no usage.
+	ParseUsage(output []byte) *types.TokenUsage
+}
+
+// outputTail keeps the last usageTailBytes of a process's stdout.
+type outputTail struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (t *outputTail) Write(p []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - usageTailBytes; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+}
+
+func (t *outputTail) Bytes() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]byte(nil), t.buf...)
+}
+
+// recordTokenUsage stores the session's token usage, if its driver reports
+// any, on the agent and as a token_usage event.
+func (d *Daemon) recordTokenUsage(agentID string, proc *Process) {
+	usageDriver, ok := d.getDriver(agentID).(UsageDriver)
+	if !ok {
+		return
+	}
+	usage := usageDriver.ParseUsage(proc.output.Bytes())
+	if usage == nil {
+		return
+	}
+	usage.AgentID = agentID
+	usage.SessionID = proc.SessionID
+	usage.RecordedAt = time.Now().Unix()
+	if usage.Model == "" {
+		if driver, ok := usageDriver.(Driver); ok {
+			usage.Model = driver.Name()
+		}
+	}
+
+	// The agent's last known input is the whole context, cached or not.
+	totalInput := usage.TotalInput()
+	if err := db.UpdateAgent(d.database, agentID, db.AgentUpdates{
+		LastKnownInput:  types.OptionalInt64{Set: true, Value: &totalInput},
+		LastKnownOutput: types.OptionalInt64{Set: true, Value: &usage.OutputTokens},
+		TokensUpdatedAt: types.OptionalInt64{Set: true, Value: &usage.RecordedAt},
+	}); err != nil {
+		d.debugf("  @%s: record token usage: %v", agentID, err)
+		return
+	}
+	if err := db.AppendTokenUsage(d.project.DBPath, *usage); err != nil {
+		d.debugf("  @%s: append token usage: %v", agentID, err)
+	}
+}
+
+// jsonLines decodes each line of output that is a JSON object, skipping
+// anything else (partial lines, plain text).
+func jsonLines(output []byte, visit func(line []byte)) {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), usageTailBytes)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) > 0 && line[0] == '{' && json.Valid(line) {
+			visit(line)
+		}
+	}
+}
+
+// parseClaudeUsage reads the result event of claude's stream-json output.
+func parseClaudeUsage(output []byte) *types.TokenUsage {
+	var usage *types.TokenUsage
+	jsonLines(output, func(line []byte) {
+		var result struct {
+			Type  string `json:"type"`
+			Usage *struct {
+				InputTokens              int64 `json:"input_tokens"`
+				CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+				CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+				OutputTokens             int64 `json:"output_tokens"`
+			} `json:"usage"`
+			ModelUsage map[string]struct {
+				OutputTokens int64 `json:"outputTokens"`
+			} `json:"modelUsage"`
+		}
+		if err := json.Unmarshal(line, &result); err != nil || result.Type != "result" || result.Usage == nil {
+			return
+		}
+		usage = &types.TokenUsage{
+			InputTokens:      result.Usage.InputTokens,
+			CacheReadTokens:  result.Usage.CacheReadInputTokens,
+			CacheWriteTokens: result.Usage.CacheCreationInputTokens,
+			OutputTokens:     result.Usage.OutputTokens,
+		}
+		// Attribute to the model that produced the most output.
+		var most int64 = -1
+		for model, stats := range result.ModelUsage {
+			if stats.OutputTokens > most || (stats.OutputTokens == most && model < usage.Model) {
+				usage.Model = model
+				most = stats.OutputTokens
+			}
+		}
+	})
+	return usage
+}
+
+// parseCodexUsage sums the usage of the turn events codex exec --json prints.
+func parseCodexUsage(output []byte) *types.TokenUsage {
+	var usage *types.TokenUsage
+	jsonLines(output, func(line []byte) {
+		var event struct {
+			Model string `json:"model"`
+			Usage *struct {
+				InputTokens       int64 `json:"input_tokens"`
+				CachedInputTokens int64 `json:"cached_input_tokens"`
+				OutputTokens      int64 `json:"output_tokens"`
+			} `json:"usage"`
+		}
+		if err := json.Unmarshal(line, &event); err != nil || event.Usage == nil {
+			return
+		}
+		if usage == nil {
+			usage = &types.TokenUsage{}
+		}
+		// input_tokens includes cached_input_tokens; codex reports no cache writes.
+		usage.InputTokens += event.Usage.InputTokens - event.Usage.CachedInputTokens
+		usage.CacheReadTokens += event.Usage.CachedInputTokens
+		usage.OutputTokens += event.Usage.OutputTokens
+		if event.Model != "" {
+			usage.Model = event.Model
+		}
+	})
+	return usage
+}
@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+const claudeStreamOutput = `{"type":"system","subtype":"init","session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"on it"}]}}
+not json at all
+{"type":"result","subtype":"success","usage":{"input_tokens":1200,"cache_creation_input_tokens":300,"cache_read_input_tokens":500,"output_tokens":800},"modelUsage":{"claude-haiku":{"outputTokens":20},"claude-sonnet":{"outputTokens":780}}}
+`
+
+func TestParseClaudeUsage(t *testing.T) {
+	usage := parseClaudeUsage([]byte(claudeStreamOutput))
+	if usage == nil {
+		t.Fatal("expected usage from result event")
+	}
+	if usage.InputTokens != 1200 || usage.CacheReadTokens != 500 || usage.CacheWriteTokens != 300 || usage.OutputTokens != 800 || usage.Model != "claude-sonnet" {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+	if parseClaudeUsage([]byte("plain text output\n")) != nil {
+		t.Fatal("expected no usage from text output")
+	}
+}
+
+func TestParseCodexUsage(t *testing.T) {
+	output := `{"type":"thread.started"}
+{"type":"turn.completed","usage":{"input_tokens":1000,"cached_input_tokens":400,"output_tokens":100}}
+{"type":"turn.completed","usage":{"input_tokens":500,"cached_input_tokens":0,"output_tokens":50}}
+`
+	usage := parseCodexUsage([]byte(output))
+	if usage == nil || usage.InputTokens != 1100 || usage.CacheReadTokens != 400 || usage.OutputTokens != 150 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestOutputTailKeepsEnd(t *testing.T) {
+	var tail outputTail
+	tail.Write([]byte(strings.Repeat("x", usageTailBytes)))
+	tail.Write([]byte(claudeStreamOutput))
+	got := tail.Bytes()
+	if len(got) != usageTailBytes || !strings.HasSuffix(string(got), claudeStreamOutput) {
+		t.Fatalf("expected the last %d bytes, got %d", usageTailBytes, len(got))
+	}
+	if parseClaudeUsage(got) == nil {
+		t.Fatal("expected usage to survive truncation of earlier output")
+	}
+}
+
+// usageDriver is a recordingDriver that reports claude-style usage.
+type usageDriver struct {
+	recordingDriver
+}
+
+func (u *usageDriver) ParseUsage(output []byte) *types.TokenUsage {
+	return parseClaudeUsage(output)
+}
+
+func TestRecordTokenUsage(t *testing.T) {
+	h := newTestHarness(t)
+	h.createAgent("dev", true)
+
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.drivers["claude"] = &usageDriver{recordingDriver{t: t}}
+
+	proc := &Process{SessionID: "sess-1"}
+	proc.output.Write([]byte(claudeStreamOutput))
+	d.recordTokenUsage("dev", proc)
+
+	agent, err := db.GetAgent(h.db, "dev")
+	if err != nil || agent == nil {
+		t.Fatalf("get agent: %v", err)
+	}
+	if agent.LastKnownInput == nil || *agent.LastKnownInput != 2000 || agent.LastKnownOutput == nil || *agent.LastKnownOutput != 800 || agent.TokensUpdatedAt == nil {
+		t.Fatalf("expected last known usage on the agent, got %+v", agent)
+	}
+
+	events, err := db.ReadTokenUsage(h.projectPath)
+	if err != nil {
+		t.Fatalf("read token usage: %v", err)
+	}
+	if len(events) != 1 || events[0].AgentID != "dev" || events[0].SessionID != "sess-1" || events[0].Model != "claude-sonnet" || events[0].CacheReadTokens != 500 {
+		t.Fatalf("expected one attributed token_usage event, got %+v", events)
+	}
+}
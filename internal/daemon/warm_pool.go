@@ -0,0 +1,212 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+const (
+	// warmAgentsConfigKey lists agents that get a pre-started session.
+	warmAgentsConfigKey = "warm_agents"
+	// warmRetryDelay backs off after a warm session fails to start or dies early.
+	warmRetryDelay = 30 * time.Second
+)
+
+// warmEntry is a pre-started session waiting for its prompt.
+type warmEntry struct {
+	proc      *Process
+	sessionID string // agent's last_session_id the session was started for
+}
+
+// warmAgentIDs returns the set of agents listed in warm_agents.
+func (d *Daemon) warmAgentIDs() map[string]bool {
+	value, err := db.GetConfig(d.database, warmAgentsConfigKey)
+	if err != nil || strings.TrimSpace(value) == "" {
+		return nil
+	}
+	ids := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		id := strings.TrimPrefix(strings.TrimSpace(part), "@")
+		if id != "" {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// warmDriverFor returns the agent's driver if it supports prestarting.
+func (d *Daemon) warmDriverFor(agent types.Agent) WarmDriver {
+	if agent.Invoke == nil {
+		return nil
+	}
+	driver, _ := d.drivers[agent.Invoke.Driver].(WarmDriver)
+	return driver
+}
+
+// refillWarmPool keeps one pre-started session per warm_agents entry that
+// has no session running, drops entries that died, went stale, or were
+// removed from the list, and marks agents with nothing running as warm.
+func (d *Daemon) refillWarmPool(ctx context.Context, agents []types.Agent) {
+	wanted := d.warmAgentIDs()
+
+	d.mu.Lock()
+	removed := make(map[string]*warmEntry)
+	for agentID, entry := range d.warm {
+		if !wanted[agentID] {
+			delete(d.warm, agentID)
+			removed[agentID] = entry
+		}
+	}
+	d.mu.Unlock()
+	for agentID, entry := range removed {
+		d.stopWarm(agentID, entry)
+	}
+
+	for _, agent := range agents {
+		if !wanted[agent.AgentID] {
+			continue
+		}
+		driver := d.warmDriverFor(agent)
+		if driver == nil {
+			continue
+		}
+
+		d.mu.Lock()
+		entry := d.warm[agent.AgentID]
+		var stale *warmEntry
+		if entry != nil && (warmExited(entry.proc) || entry.sessionID != agentSessionID(agent)) {
+			if warmExited(entry.proc) {
+				d.warmRetryAt[agent.AgentID] = time.Now().Add(warmRetryDelay)
+			}
+			delete(d.warm, agent.AgentID)
+			stale, entry = entry, nil
+		}
+		_, running := d.processes[agent.AgentID]
+		retryAt := d.warmRetryAt[agent.AgentID]
+		d.mu.Unlock()
+		if stale != nil {
+			d.stopWarm(agent.AgentID, stale)
+		}
+
+		if entry == nil {
+			// A live session can't be resumed yet. Its replacement is
+			// started once it ends, resuming it, so the next wake keeps
+			// the conversation.
+			if running || time.Now().Before(retryAt) {
+				continue
+			}
+			if !d.prestartWarm(ctx, driver, agent, agentSessionID(agent)) {
+				continue
+			}
+		}
+
+		if running {
+			continue
+		}
+		switch agent.Presence {
+		case types.PresenceOffline, types.PresenceIdle, "":
+			db.UpdateAgentPresence(d.database, agent.AgentID, types.PresenceWarm)
+		}
+	}
+}
+
+// prestartWarm starts a warm session for agent and adds it to the pool.
+// basis is the last_session_id the session stands in for; the pool drops
+// it once the agent's last session moves on.
+func (d *Daemon) prestartWarm(ctx context.Context, driver WarmDriver, agent types.Agent, basis string) bool {
+	proc, err := driver.Prestart(ctx, agent)
+	if err != nil {
+		d.debugf("warm pool: prestart @%s failed: %v", agent.AgentID, err)
+		d.mu.Lock()
+		d.warmRetryAt[agent.AgentID] = time.Now().Add(warmRetryDelay)
+		d.mu.Unlock()
+		return false
+	}
+
+	// The pool reaps the process itself so a session that dies before it is
+	// used is noticed; monitorProcess waits on exited instead of Cmd.Wait.
+	proc.exited = make(chan struct{})
+	go func() {
+		proc.Cmd.Wait()
+		close(proc.exited)
+	}()
+
+	d.mu.Lock()
+	old := d.warm[agent.AgentID]
+	d.warm[agent.AgentID] = &warmEntry{proc: proc, sessionID: basis}
+	delete(d.warmRetryAt, agent.AgentID)
+	d.mu.Unlock()
+	if old != nil {
+		d.stopWarm(agent.AgentID, old)
+	}
+
+	d.debugf("warm pool: prestarted @%s (pid %d)", agent.AgentID, proc.Cmd.Process.Pid)
+	return true
+}
+
+// takeWarm removes and returns the agent's warm session if it is still usable.
+func (d *Daemon) takeWarm(agent types.Agent) *Process {
+	d.mu.Lock()
+	entry := d.warm[agent.AgentID]
+	delete(d.warm, agent.AgentID)
+	d.mu.Unlock()
+
+	if entry == nil {
+		return nil
+	}
+	if warmExited(entry.proc) || entry.sessionID != agentSessionID(agent) {
+		d.stopWarm(agent.AgentID, entry)
+		return nil
+	}
+	return entry.proc
+}
+
+// stopWarm terminates a warm session and waits for it to be reaped. It can
+// block for seconds, so it must be called without d.mu held.
+func (d *Daemon) stopWarm(agentID string, entry *warmEntry) {
+	if driver := d.getDriver(agentID); driver != nil {
+		driver.Cleanup(entry.proc)
+	} else if entry.proc.Cmd.Process != nil {
+		entry.proc.Cmd.Process.Kill()
+	}
+	select {
+	case <-entry.proc.exited:
+	case <-time.After(5 * time.Second):
+		d.debugf("warm pool: @%s (pid %d) did not exit", agentID, entry.proc.Cmd.Process.Pid)
+	}
+	if agent, err := db.GetAgent(d.database, agentID); err == nil && agent != nil && agent.Presence == types.PresenceWarm {
+		db.UpdateAgentPresence(d.database, agentID, types.PresenceOffline)
+	}
+}
+
+// stopWarmPool tears down every warm session. Used on shutdown.
+func (d *Daemon) stopWarmPool() {
+	d.mu.Lock()
+	entries := d.warm
+	d.warm = make(map[string]*warmEntry)
+	d.mu.Unlock()
+
+	for agentID, entry := range entries {
+		d.stopWarm(agentID, entry)
+	}
+}
+
+func warmExited(proc *Process) bool {
+	select {
+	case <-proc.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+func agentSessionID(agent types.Agent) string {
+	if agent.LastSessionID == nil {
+		return ""
+	}
+	return *agent.LastSessionID
+}
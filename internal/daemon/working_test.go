@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestWorkingPingQueuesMentionsInsteadOfSpawning(t *testing.T) {
+	h := newTestHarness(t)
+	dev := h.createAgent("dev", true)
+
+	driver := &recordingDriver{t: t}
+	d := New(core.Project{Root: h.projectDir, DBPath: h.projectPath}, h.db, Config{})
+	d.drivers["claude"] = driver
+	// Runs after the driver kills the spawned session: let monitorProcess
+	// finish writing to .fray before the temp dir is removed.
+	t.Cleanup(d.wg.Wait)
+
+	if err := db.SetAgentWorking(h.db, "dev", nil, db.WorkingSourceAgent, time.Now().UnixMilli()); err != nil {
+		t.Fatalf("set working: %v", err)
+	}
+	if h.debouncer.ShouldSpawn(dev, types.Message{FromAgent: "alice"}) {
+		t.Fatalf("expected a fresh working ping to block spawning")
+	}
+
+	msg := h.postMessage("alice", "@dev are you on it?", types.MessageTypeUser)
+	d.checkMentions(context.Background(), dev)
+	if len(driver.spawned) != 0 {
+		t.Fatalf("expected no spawn while working, got %v", driver.spawned)
+	}
+	if pending := d.debouncer.FlushPending("dev"); len(pending) != 1 || pending[0] != msg.ID {
+		t.Fatalf("expected the mention queued, got %v", pending)
+	}
+
+	// Once the ping goes stale the queued mention wakes the agent.
+	stale := time.Now().Add(-db.DefaultWorkingTTL - time.Second).UnixMilli()
+	if err := db.SetAgentWorking(h.db, "dev", nil, db.WorkingSourceAgent, stale); err != nil {
+		t.Fatalf("set stale working: %v", err)
+	}
+	d.checkMentions(context.Background(), dev)
+	if len(driver.spawned) != 1 {
+		t.Fatalf("expected a spawn after the ping expired, got %v", driver.spawned)
+	}
+	working, err := db.GetAgentWorking(h.db, "dev", db.DefaultWorkingTTL, time.Now().UnixMilli())
+	if err != nil || working == nil || working.Source != db.WorkingSourceDaemon || working.MessageGUID == nil || *working.MessageGUID != msg.ID {
+		t.Fatalf("expected the daemon to mark dev working on %s, got %+v (%v)", msg.ID, working, err)
+	}
+}
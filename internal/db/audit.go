@@ -0,0 +1,52 @@
+package db
+
+import (
+	"encoding/json"
+	"path/filepath"
+)
+
+const auditFile = "audit.jsonl"
+
+// AuditRecordEntry is the record type of audit log lines.
+const AuditRecordEntry = "audit_entry"
+
+// AuditRecord is a line in the audit log (.fray/audit.jsonl): one
+// administrative command, who ran it, and how it ended. The log sits next to
+// the other shared JSONL files so it syncs with them; it is read directly and
+// never rebuilt into the cache.
+type AuditRecord struct {
+	Type       string   `json:"type"`
+	Actor      string   `json:"actor"`
+	Command    string   `json:"command"`
+	Args       []string `json:"args,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	RecordedAt int64    `json:"recorded_at"`
+}
+
+func auditLogPath(projectPath string) string {
+	return filepath.Join(resolveFrayDir(projectPath), auditFile)
+}
+
+// AppendAuditEntry records an administrative command in the audit log.
+func AppendAuditEntry(projectPath string, entry AuditRecord) error {
+	entry.Type = AuditRecordEntry
+	return appendJSONLine(auditLogPath(projectPath), entry)
+}
+
+// ReadAuditEntries returns the audit log oldest first. Lines that don't
+// parse are skipped.
+func ReadAuditEntries(projectPath string) ([]AuditRecord, error) {
+	lines, err := readJSONLLines(auditLogPath(projectPath))
+	if err != nil {
+		return nil, err
+	}
+	var entries []AuditRecord
+	for _, line := range lines {
+		var record AuditRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil || record.Type != AuditRecordEntry {
+			continue
+		}
+		entries = append(entries, record)
+	}
+	return entries, nil
+}
@@ -0,0 +1,70 @@
+package db_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/devtool"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// generatedProject builds a devtool project for benchmarks. FRAY_BENCH_MESSAGES
+// overrides the default size of 5000 messages.
+func generatedProject(b *testing.B) (core.Project, *sql.DB) {
+	b.Helper()
+	b.Setenv("HOME", b.TempDir())
+	messages := 5000
+	if value, err := strconv.Atoi(os.Getenv("FRAY_BENCH_MESSAGES")); err == nil && value > 0 {
+		messages = value
+	}
+	dir := filepath.Join(b.TempDir(), "bench")
+	if _, err := devtool.Generate(devtool.Options{Dir: dir, Messages: messages, Agents: 12, Threads: 40, Seed: 42}); err != nil {
+		b.Fatalf("generate: %v", err)
+	}
+	project := core.Project{Root: dir, DBPath: filepath.Join(dir, ".fray", "fray.db")}
+	conn, err := db.OpenDatabase(project)
+	if err != nil {
+		b.Fatalf("open: %v", err)
+	}
+	b.Cleanup(func() { _ = conn.Close() })
+	if err := db.InitSchema(conn); err != nil {
+		b.Fatalf("init schema: %v", err)
+	}
+	return project, conn
+}
+
+func BenchmarkRebuildGeneratedProject(b *testing.B) {
+	project, conn := generatedProject(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := db.RebuildDatabaseFromJSONL(conn, project.DBPath); err != nil {
+			b.Fatalf("rebuild: %v", err)
+		}
+	}
+}
+
+func BenchmarkGetRoomMessagesGenerated(b *testing.B) {
+	_, conn := generatedProject(b)
+	room := "room"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := db.GetMessages(conn, &types.MessageQueryOptions{Limit: 100, Home: &room}); err != nil {
+			b.Fatalf("get messages: %v", err)
+		}
+	}
+}
+
+func BenchmarkReadMessagesJSONLGenerated(b *testing.B) {
+	project, _ := generatedProject(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := db.ReadMessages(project.DBPath); err != nil {
+			b.Fatalf("read messages: %v", err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// AppendClaimRequest records a claim request in agents.jsonl. Like claim
+// history, requests are read straight from JSONL rather than cached.
+func AppendClaimRequest(projectPath string, request types.ClaimRequest) error {
+	frayDir := resolveFrayDir(projectPath)
+	record := ClaimRequestJSONLRecord{
+		Type:        "claim_request",
+		GUID:        request.GUID,
+		RequestedBy: request.RequestedBy,
+		Holder:      request.Holder,
+		ClaimType:   string(request.ClaimType),
+		Pattern:     request.Pattern,
+		Note:        request.Note,
+		MessageGUID: request.MessageGUID,
+		RequestedAt: request.RequestedAt,
+	}
+	if err := appendJSONLine(filepath.Join(frayDir, agentsFile), record); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
+// ReadClaimRequests returns claim requests oldest first. A request resolves
+// at the first release of its claim after it was made; GrantedTo is set when
+// that release handed the claim to the requester.
+func ReadClaimRequests(projectPath string) ([]types.ClaimRequest, error) {
+	frayDir := resolveFrayDir(projectPath)
+	lines, err := readRecordLines(frayDir, agentsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []types.ClaimRequest
+	pending := map[string][]int{}
+	for _, line := range lines {
+		var envelope struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(line), &envelope); err != nil {
+			continue
+		}
+
+		switch envelope.Type {
+		case "claim_request":
+			var record ClaimRequestJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			key := record.ClaimType + ":" + record.Pattern
+			pending[key] = append(pending[key], len(requests))
+			requests = append(requests, types.ClaimRequest{
+				GUID:        record.GUID,
+				RequestedBy: record.RequestedBy,
+				Holder:      record.Holder,
+				ClaimType:   types.ClaimType(record.ClaimType),
+				Pattern:     record.Pattern,
+				Note:        record.Note,
+				MessageGUID: record.MessageGUID,
+				RequestedAt: record.RequestedAt,
+			})
+		case "claim_clear":
+			var record ClaimClearJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			key := record.ClaimType + ":" + record.Pattern
+			for _, idx := range pending[key] {
+				resolvedAt := record.ClearedAt
+				requests[idx].ResolvedAt = &resolvedAt
+				if record.TransferredTo != nil && *record.TransferredTo == requests[idx].RequestedBy {
+					requests[idx].GrantedTo = record.TransferredTo
+				}
+			}
+			delete(pending, key)
+		}
+	}
+	return requests, nil
+}
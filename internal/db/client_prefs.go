@@ -0,0 +1,174 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// clientPrefsFile holds local client prefs under .fray/local/.
+const clientPrefsFile = "client_prefs.json"
+
+// Client pref size caps, in bytes.
+const (
+	ClientPrefKeyMax   = 64
+	ClientPrefValueMax = 1024
+)
+
+// ValidateClientPref checks a pref key and value against the size caps.
+func ValidateClientPref(key, value string) error {
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("pref key is required")
+	}
+	if len(key) > ClientPrefKeyMax {
+		return fmt.Errorf("pref key is %d bytes, over the %d byte limit", len(key), ClientPrefKeyMax)
+	}
+	if len(value) > ClientPrefValueMax {
+		return fmt.Errorf("pref value is %d bytes, over the %d byte limit", len(value), ClientPrefValueMax)
+	}
+	return nil
+}
+
+// AppendClientPref records a shared client pref in threads.jsonl. An empty
+// value unsets it. Like claim history, shared prefs are read straight from
+// JSONL rather than cached.
+func AppendClientPref(projectPath string, pref types.ClientPref) error {
+	frayDir := resolveFrayDir(projectPath)
+	record := ClientPrefJSONLRecord{
+		Type:       "client_pref",
+		ThreadGUID: pref.ThreadGUID,
+		Key:        pref.Key,
+		Value:      pref.Value,
+		SetBy:      pref.SetBy,
+		SetAt:      pref.SetAt,
+	}
+	if err := appendJSONLine(filepath.Join(frayDir, threadsFile), record); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
+// SetLocalClientPref stores an agent's pref in .fray/local/client_prefs.json,
+// replacing any earlier value for the same thread and key. An empty value
+// removes it.
+func SetLocalClientPref(projectPath string, pref types.ClientPref) error {
+	prefs, err := readLocalClientPrefs(projectPath)
+	if err != nil {
+		return err
+	}
+	kept := prefs[:0]
+	for _, existing := range prefs {
+		if existing.AgentID == pref.AgentID && existing.ThreadGUID == pref.ThreadGUID && existing.Key == pref.Key {
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	if pref.Value != "" {
+		pref.Shared = false
+		kept = append(kept, pref)
+	}
+
+	path := localClientPrefsPath(projectPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// GetClientPrefs returns the prefs that apply to agentID, ordered by thread
+// then key: shared prefs, with the agent's local prefs overriding them.
+func GetClientPrefs(projectPath, agentID string) ([]types.ClientPref, error) {
+	shared, err := readSharedClientPrefs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	local, err := readLocalClientPrefs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]types.ClientPref, len(shared))
+	for key, pref := range shared {
+		resolved[key] = pref
+	}
+	for _, pref := range local {
+		if pref.AgentID == agentID {
+			resolved[pref.ThreadGUID+"\x00"+pref.Key] = pref
+		}
+	}
+
+	prefs := make([]types.ClientPref, 0, len(resolved))
+	for _, pref := range resolved {
+		prefs = append(prefs, pref)
+	}
+	sort.Slice(prefs, func(i, j int) bool {
+		if prefs[i].ThreadGUID != prefs[j].ThreadGUID {
+			return prefs[i].ThreadGUID < prefs[j].ThreadGUID
+		}
+		return prefs[i].Key < prefs[j].Key
+	})
+	return prefs, nil
+}
+
+// readSharedClientPrefs replays client_pref records; the last write per
+// thread and key wins.
+func readSharedClientPrefs(projectPath string) (map[string]types.ClientPref, error) {
+	lines, err := readJSONLLines(filepath.Join(resolveFrayDir(projectPath), threadsFile))
+	if err != nil {
+		return nil, err
+	}
+	prefs := map[string]types.ClientPref{}
+	for _, line := range lines {
+		if !strings.Contains(line, `"client_pref"`) {
+			continue
+		}
+		var record ClientPrefJSONLRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil || record.Type != "client_pref" {
+			continue
+		}
+		key := record.ThreadGUID + "\x00" + record.Key
+		if record.Value == "" {
+			delete(prefs, key)
+			continue
+		}
+		prefs[key] = types.ClientPref{
+			ThreadGUID: record.ThreadGUID,
+			Key:        record.Key,
+			Value:      record.Value,
+			Shared:     true,
+			SetBy:      record.SetBy,
+			SetAt:      record.SetAt,
+		}
+	}
+	return prefs, nil
+}
+
+func readLocalClientPrefs(projectPath string) ([]types.ClientPref, error) {
+	path := localClientPrefsPath(projectPath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var prefs []types.ClientPref
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return prefs, nil
+}
+
+func localClientPrefsPath(projectPath string) string {
+	return filepath.Join(resolveFrayDir(projectPath), localDir, clientPrefsFile)
+}
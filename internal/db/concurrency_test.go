@@ -0,0 +1,82 @@
+package db
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/testutil"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// TestConcurrentPostsOnOneHandle hammers a single *sql.DB from many goroutines,
+// the way an embedding host shares one handle across threads.
+func TestConcurrentPostsOnOneHandle(t *testing.T) {
+	project := testutil.NewProject(t)
+	conn := project.OpenDB(t, OpenDatabase, InitSchema)
+
+	const workers = 32
+	const perWorker = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				msg, err := CreateMessage(conn, types.Message{
+					FromAgent: fmt.Sprintf("agent%d", w),
+					Body:      fmt.Sprintf("message %d from worker %d", i, w),
+					Mentions:  []string{},
+				})
+				if err != nil {
+					errs <- err
+					continue
+				}
+				if err := AppendMessage(project.Project.DBPath, msg); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent post: %v", err)
+	}
+
+	var count int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM fray_messages").Scan(&count); err != nil {
+		t.Fatalf("count messages: %v", err)
+	}
+	if count != workers*perWorker {
+		t.Fatalf("expected %d messages in db, got %d", workers*perWorker, count)
+	}
+
+	records, err := ReadMessages(project.Project.DBPath)
+	if err != nil {
+		t.Fatalf("read jsonl: %v", err)
+	}
+	if len(records) != workers*perWorker {
+		t.Fatalf("expected %d jsonl records, got %d", workers*perWorker, len(records))
+	}
+	seen := make(map[string]struct{}, len(records))
+	lastByWorker := make(map[string]int)
+	for _, r := range records {
+		if _, dup := seen[r.ID]; dup {
+			t.Fatalf("duplicate jsonl record %s", r.ID)
+		}
+		seen[r.ID] = struct{}{}
+
+		// Each worker posts sequentially, so its lines must stay in order.
+		var i, w int
+		if _, err := fmt.Sscanf(r.Body, "message %d from worker %d", &i, &w); err != nil {
+			t.Fatalf("unexpected body %q", r.Body)
+		}
+		if last, ok := lastByWorker[r.FromAgent]; ok && i <= last {
+			t.Fatalf("%s: message %d appended after %d", r.FromAgent, i, last)
+		}
+		lastByWorker[r.FromAgent] = i
+	}
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/adamavenir/fray/internal/core"
+)
+
+// ConfigScope names a config layer.
+type ConfigScope string
+
+const (
+	// ConfigScopeGlobal is the user's ~/.config/fray/config.json.
+	ConfigScopeGlobal ConfigScope = "global"
+	// ConfigScopeProject is the shared fray_config table.
+	ConfigScopeProject ConfigScope = "project"
+	// ConfigScopeLocal is this machine's .fray/local/config.json.
+	ConfigScopeLocal ConfigScope = "local"
+)
+
+const localConfigFile = "config.json"
+
+// ConfigScopes lists the layers from lowest to highest precedence:
+// local overrides project, which overrides global.
+var ConfigScopes = []ConfigScope{ConfigScopeGlobal, ConfigScopeProject, ConfigScopeLocal}
+
+// ParseConfigScope validates a scope name.
+func ParseConfigScope(value string) (ConfigScope, error) {
+	for _, scope := range ConfigScopes {
+		if string(scope) == value {
+			return scope, nil
+		}
+	}
+	return "", fmt.Errorf("invalid scope %q (use global, project, or local)", value)
+}
+
+// ResolvedConfigEntry is an effective config value and the layer it came from.
+type ResolvedConfigEntry struct {
+	Key   string      `json:"key"`
+	Value string      `json:"value"`
+	Scope ConfigScope `json:"scope"`
+}
+
+// GetConfigLayer returns all values set in one layer.
+func GetConfigLayer(dbConn *sql.DB, projectPath string, scope ConfigScope) (map[string]string, error) {
+	if scope == ConfigScopeProject {
+		entries, err := GetAllConfig(dbConn)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]string, len(entries))
+		for _, entry := range entries {
+			values[entry.Key] = entry.Value
+		}
+		return values, nil
+	}
+
+	path, err := configLayerPath(projectPath, scope)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// SetConfigLayer sets key in one layer.
+func SetConfigLayer(dbConn *sql.DB, projectPath string, scope ConfigScope, key, value string) error {
+	if scope == ConfigScopeProject {
+		return SetConfig(dbConn, key, value)
+	}
+
+	values, err := GetConfigLayer(dbConn, projectPath, scope)
+	if err != nil {
+		return err
+	}
+	values[key] = value
+
+	path, err := configLayerPath(projectPath, scope)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ResolveConfig merges the layers and reports where each value came from.
+func ResolveConfig(dbConn *sql.DB, projectPath string) ([]ResolvedConfigEntry, error) {
+	merged := map[string]ResolvedConfigEntry{}
+	for _, scope := range ConfigScopes {
+		values, err := GetConfigLayer(dbConn, projectPath, scope)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range values {
+			merged[key] = ResolvedConfigEntry{Key: key, Value: value, Scope: scope}
+		}
+	}
+
+	entries := make([]ResolvedConfigEntry, 0, len(merged))
+	for _, entry := range merged {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// GetResolvedConfig returns the effective value of key across the layers, or
+// "" when no layer sets it.
+func GetResolvedConfig(dbConn *sql.DB, projectPath, key string) (string, ConfigScope, error) {
+	for i := len(ConfigScopes) - 1; i >= 0; i-- {
+		scope := ConfigScopes[i]
+		var value string
+		if scope == ConfigScopeProject {
+			var err error
+			value, err = GetConfig(dbConn, key)
+			if err != nil {
+				return "", "", err
+			}
+		} else {
+			values, err := GetConfigLayer(dbConn, projectPath, scope)
+			if err != nil {
+				return "", "", err
+			}
+			value = values[key]
+		}
+		if value != "" {
+			return value, scope, nil
+		}
+	}
+	return "", "", nil
+}
+
+func configLayerPath(projectPath string, scope ConfigScope) (string, error) {
+	switch scope {
+	case ConfigScopeGlobal:
+		return core.GlobalSettingsPath()
+	case ConfigScopeLocal:
+		return filepath.Join(resolveFrayDir(projectPath), localDir, localConfigFile), nil
+	default:
+		return "", fmt.Errorf("config scope %q has no file", scope)
+	}
+}
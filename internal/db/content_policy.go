@@ -0,0 +1,79 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/core"
+)
+
+// PostBlockPatternsConfigKey holds project-defined content-policy regexes as
+// a JSON array of strings.
+const PostBlockPatternsConfigKey = "post_block_patterns"
+
+// ParseBlockPatterns parses and compiles a post_block_patterns value.
+func ParseBlockPatterns(value string) ([]*regexp.Regexp, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var patterns []string
+	if err := json.Unmarshal([]byte(value), &patterns); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON array of regular expressions", PostBlockPatternsConfigKey)
+	}
+	return core.CompileBlockPatterns(patterns)
+}
+
+// CheckMessageContent applies the content policy to a message body: the
+// built-in secret detectors plus post_block_patterns. Returns a
+// *core.BlockedContentError when the body is blocked.
+func CheckMessageContent(db *sql.DB, body string) error {
+	value, err := GetConfig(db, PostBlockPatternsConfigKey)
+	if err != nil {
+		return err
+	}
+	custom, err := ParseBlockPatterns(value)
+	if err != nil {
+		return err
+	}
+	return core.CheckContent(body, custom)
+}
+
+// checkTextContent applies the content policy to the free-text fields of a
+// non-message record, such as a question or a claim note. Those land in the
+// same shared JSONL as messages, so they get the same check.
+func checkTextContent(db *sql.DB, texts ...string) error {
+	var nonEmpty []string
+	for _, text := range texts {
+		if strings.TrimSpace(text) != "" {
+			nonEmpty = append(nonEmpty, text)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return nil
+	}
+	value, err := GetConfig(db, PostBlockPatternsConfigKey)
+	if err != nil {
+		return err
+	}
+	custom, err := ParseBlockPatterns(value)
+	if err != nil {
+		return err
+	}
+	for _, text := range nonEmpty {
+		if err := core.CheckContent(text, custom); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// optionalText returns the value of an optional field, or "".
+func optionalText(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
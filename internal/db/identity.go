@@ -0,0 +1,41 @@
+package db
+
+import "database/sql"
+
+// HumanIdentityConfigKey names the human using this checkout. fray login
+// writes it to the local layer; answer, react, post and chat fall back to it
+// when --as is omitted.
+const HumanIdentityConfigKey = "identity"
+
+// UsernameConfigKey is the chat-only predecessor of identity.
+//
+// Deprecated: read HumanIdentity instead; values are migrated on first read.
+const UsernameConfigKey = "username"
+
+// HumanIdentity returns the configured human identity, or "" when none is
+// set. A legacy username is migrated first.
+func HumanIdentity(dbConn *sql.DB, projectPath string) (string, error) {
+	identity, _, err := GetResolvedConfig(dbConn, projectPath, HumanIdentityConfigKey)
+	if err != nil || identity != "" {
+		return identity, err
+	}
+	return MigrateUsernameConfig(dbConn, projectPath)
+}
+
+// MigrateUsernameConfig copies a legacy username into the local identity key
+// when no layer sets identity yet, and returns the migrated value. The
+// username key is left in place for older fray builds.
+func MigrateUsernameConfig(dbConn *sql.DB, projectPath string) (string, error) {
+	identity, _, err := GetResolvedConfig(dbConn, projectPath, HumanIdentityConfigKey)
+	if err != nil || identity != "" {
+		return "", err
+	}
+	username, _, err := GetResolvedConfig(dbConn, projectPath, UsernameConfigKey)
+	if err != nil || username == "" {
+		return "", err
+	}
+	if err := SetConfigLayer(dbConn, projectPath, ConfigScopeLocal, HumanIdentityConfigKey, username); err != nil {
+		return "", err
+	}
+	return username, nil
+}
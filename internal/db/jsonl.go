@@ -12,33 +12,39 @@ const (
 
 // MessageJSONLRecord represents a message entry in JSONL.
 type MessageJSONLRecord struct {
-	Type             string              `json:"type"`
-	ID               string              `json:"id"`
-	ChannelID        *string             `json:"channel_id"`
-	Home             string              `json:"home,omitempty"`
-	FromAgent        string              `json:"from_agent"`
-	Body             string              `json:"body"`
-	Mentions         []string            `json:"mentions"`
-	Reactions        map[string][]string `json:"reactions,omitempty"`
-	MsgType          types.MessageType   `json:"message_type"`
-	References       *string             `json:"references,omitempty"`
-	SurfaceMessage   *string             `json:"surface_message,omitempty"`
-	ReplyTo          *string             `json:"reply_to"`
-	QuoteMessageGUID *string             `json:"quote_message_guid,omitempty"`
-	TS               int64               `json:"ts"`
-	EditedAt         *int64              `json:"edited_at"`
-	ArchivedAt       *int64              `json:"archived_at"`
+	Type             string            `json:"type"`
+	ID               string            `json:"id"`
+	ChannelID        *string           `json:"channel_id"`
+	Home             string            `json:"home,omitempty"`
+	FromAgent        string            `json:"from_agent"`
+	Body             string            `json:"body"`
+	Mentions         []string          `json:"mentions"`
+	Reactions        ReactionSet       `json:"reactions,omitempty"`
+	MsgType          types.MessageType `json:"message_type"`
+	References       *string           `json:"references,omitempty"`
+	SurfaceMessage   *string           `json:"surface_message,omitempty"`
+	ReplyTo          *string           `json:"reply_to"`
+	QuoteMessageGUID *string           `json:"quote_message_guid,omitempty"`
+	TS               int64             `json:"ts"`
+	EditedAt         *int64            `json:"edited_at"`
+	ArchivedAt       *int64            `json:"archived_at"`
+	Important        bool              `json:"important,omitempty"`
+	Standup          bool              `json:"standup,omitempty"`
+	Commits          []types.CommitRef `json:"commits,omitempty"`
+	ThreadRefs       []string          `json:"thread_refs,omitempty"`
 }
 
 // MessageUpdateJSONLRecord represents a message update entry in JSONL.
 type MessageUpdateJSONLRecord struct {
-	Type       string               `json:"type"`
-	ID         string               `json:"id"`
-	Body       *string              `json:"body,omitempty"`
-	EditedAt   *int64               `json:"edited_at,omitempty"`
-	ArchivedAt *int64               `json:"archived_at,omitempty"`
-	Reactions  *map[string][]string `json:"reactions,omitempty"`
-	Reason     *string              `json:"reason,omitempty"`
+	Type       string       `json:"type"`
+	ID         string       `json:"id"`
+	Body       *string      `json:"body,omitempty"`
+	Mentions   *[]string    `json:"mentions,omitempty"`
+	ThreadRefs *[]string    `json:"thread_refs,omitempty"`
+	EditedAt   *int64       `json:"edited_at,omitempty"`
+	ArchivedAt *int64       `json:"archived_at,omitempty"`
+	Reactions  *ReactionSet `json:"reactions,omitempty"`
+	Reason     *string      `json:"reason,omitempty"`
 }
 
 // QuestionJSONLRecord represents a question entry in JSONL.
@@ -80,6 +86,7 @@ type ThreadJSONLRecord struct {
 	AnchorMessageGUID *string  `json:"anchor_message_guid,omitempty"`
 	AnchorHidden      bool     `json:"anchor_hidden,omitempty"`
 	LastActivityAt    *int64   `json:"last_activity_at,omitempty"`
+	CreatedBy         *string  `json:"created_by,omitempty"`
 }
 
 // ThreadUpdateJSONLRecord represents a thread update entry in JSONL.
@@ -97,10 +104,11 @@ type ThreadUpdateJSONLRecord struct {
 
 // ThreadSubscribeJSONLRecord represents a subscription event.
 type ThreadSubscribeJSONLRecord struct {
-	Type         string `json:"type"`
-	ThreadGUID   string `json:"thread_guid"`
-	AgentID      string `json:"agent_id"`
-	SubscribedAt int64  `json:"subscribed_at"`
+	Type         string                  `json:"type"`
+	ThreadGUID   string                  `json:"thread_guid"`
+	AgentID      string                  `json:"agent_id"`
+	SubscribedAt int64                   `json:"subscribed_at"`
+	Level        types.SubscriptionLevel `json:"level,omitempty"` // empty keeps the current level
 }
 
 // ThreadUnsubscribeJSONLRecord represents an unsubscribe event.
@@ -140,11 +148,39 @@ type MessagePinJSONLRecord struct {
 
 // MessageUnpinJSONLRecord represents a message unpin event.
 type MessageUnpinJSONLRecord struct {
-	Type         string `json:"type"`
-	MessageGUID  string `json:"message_guid"`
-	ThreadGUID   string `json:"thread_guid"`
-	UnpinnedBy   string `json:"unpinned_by"`
-	UnpinnedAt   int64  `json:"unpinned_at"`
+	Type        string `json:"type"`
+	MessageGUID string `json:"message_guid"`
+	ThreadGUID  string `json:"thread_guid"`
+	UnpinnedBy  string `json:"unpinned_by"`
+	UnpinnedAt  int64  `json:"unpinned_at"`
+}
+
+// AnnouncementJSONLRecord represents a room-wide announcement.
+type AnnouncementJSONLRecord struct {
+	Type        string   `json:"type"`
+	MessageGUID string   `json:"message_guid"`
+	FromAgent   string   `json:"from_agent"`
+	AckRequired bool     `json:"ack_required,omitempty"`
+	Audience    []string `json:"audience,omitempty"`
+	CreatedAt   int64    `json:"created_at"`
+}
+
+// AnnouncementAckJSONLRecord represents an agent acknowledging an announcement.
+type AnnouncementAckJSONLRecord struct {
+	Type        string `json:"type"`
+	MessageGUID string `json:"message_guid"`
+	AgentID     string `json:"agent_id"`
+	Source      string `json:"source"`
+	AckedAt     int64  `json:"acked_at"`
+}
+
+// AnnouncementReminderJSONLRecord represents the daemon re-mentioning agents
+// who hadn't acked an announcement.
+type AnnouncementReminderJSONLRecord struct {
+	Type        string   `json:"type"`
+	MessageGUID string   `json:"message_guid"`
+	Agents      []string `json:"agents"`
+	RemindedAt  int64    `json:"reminded_at"`
 }
 
 // MessageMoveJSONLRecord represents a message move event.
@@ -167,10 +203,10 @@ type ThreadPinJSONLRecord struct {
 
 // ThreadUnpinJSONLRecord represents a thread unpin event.
 type ThreadUnpinJSONLRecord struct {
-	Type        string `json:"type"`
-	ThreadGUID  string `json:"thread_guid"`
-	UnpinnedBy  string `json:"unpinned_by"`
-	UnpinnedAt  int64  `json:"unpinned_at"`
+	Type       string `json:"type"`
+	ThreadGUID string `json:"thread_guid"`
+	UnpinnedBy string `json:"unpinned_by"`
+	UnpinnedAt int64  `json:"unpinned_at"`
 }
 
 // ThreadMuteJSONLRecord represents a thread mute event.
@@ -190,45 +226,59 @@ type ThreadUnmuteJSONLRecord struct {
 	UnmutedAt  int64  `json:"unmuted_at"`
 }
 
+// ClientPrefJSONLRecord represents a shared client pref change. An empty
+// value unsets the pref.
+type ClientPrefJSONLRecord struct {
+	Type       string `json:"type"`
+	ThreadGUID string `json:"thread_guid"`
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	SetBy      string `json:"set_by"`
+	SetAt      int64  `json:"set_at"`
+}
+
 // AgentJSONLRecord represents an agent entry in JSONL.
 type AgentJSONLRecord struct {
-	Type             string              `json:"type"`
-	ID               string              `json:"id"`
-	Name             string              `json:"name"`
-	GlobalName       *string             `json:"global_name,omitempty"`
-	HomeChannel      *string             `json:"home_channel,omitempty"`
-	CreatedAt        *string             `json:"created_at,omitempty"`
-	ActiveStatus     *string             `json:"active_status,omitempty"`
-	AgentID          string              `json:"agent_id"`
-	Status           *string             `json:"status,omitempty"`
-	Purpose          *string             `json:"purpose,omitempty"`
-	Avatar           *string             `json:"avatar,omitempty"`
-	Goal             *string             `json:"goal,omitempty"`
-	Bio              *string             `json:"bio,omitempty"`
-	RegisteredAt     int64               `json:"registered_at"`
-	LastSeen         int64               `json:"last_seen"`
-	LeftAt           *int64              `json:"left_at"`
-	Managed          bool                `json:"managed,omitempty"`
-	Invoke           *types.InvokeConfig `json:"invoke,omitempty"`
-	Presence         string              `json:"presence,omitempty"`
-	MentionWatermark *string             `json:"mention_watermark,omitempty"`
-	LastHeartbeat    *int64              `json:"last_heartbeat,omitempty"`
+	Type              string              `json:"type"`
+	ID                string              `json:"id"`
+	Name              string              `json:"name"`
+	GlobalName        *string             `json:"global_name,omitempty"`
+	HomeChannel       *string             `json:"home_channel,omitempty"`
+	CreatedAt         *string             `json:"created_at,omitempty"`
+	ActiveStatus      *string             `json:"active_status,omitempty"`
+	AgentID           string              `json:"agent_id"`
+	Status            *string             `json:"status,omitempty"`
+	Purpose           *string             `json:"purpose,omitempty"`
+	Avatar            *string             `json:"avatar,omitempty"`
+	Goal              *string             `json:"goal,omitempty"`
+	Bio               *string             `json:"bio,omitempty"`
+	RegisteredAt      int64               `json:"registered_at"`
+	LastSeen          int64               `json:"last_seen"`
+	LeftAt            *int64              `json:"left_at"`
+	Managed           bool                `json:"managed,omitempty"`
+	Invoke            *types.InvokeConfig `json:"invoke,omitempty"`
+	Presence          string              `json:"presence,omitempty"`
+	MentionWatermark  *string             `json:"mention_watermark,omitempty"`
+	LastHeartbeat     *int64              `json:"last_heartbeat,omitempty"`
+	ParentAgent       *string             `json:"parent_agent,omitempty"`
+	ReactionWatermark *int64              `json:"reaction_watermark,omitempty"`
 }
 
 // AgentUpdateJSONLRecord represents an agent update entry in JSONL.
 type AgentUpdateJSONLRecord struct {
-	Type             string              `json:"type"`
-	AgentID          string              `json:"agent_id"`
-	Status           *string             `json:"status,omitempty"`
-	Purpose          *string             `json:"purpose,omitempty"`
-	Avatar           *string             `json:"avatar,omitempty"`
-	LastSeen         *int64              `json:"last_seen,omitempty"`
-	LeftAt           *int64              `json:"left_at,omitempty"`
-	Managed          *bool               `json:"managed,omitempty"`
-	Invoke           *types.InvokeConfig `json:"invoke,omitempty"`
-	Presence         *string             `json:"presence,omitempty"`
-	MentionWatermark *string             `json:"mention_watermark,omitempty"`
-	LastHeartbeat    *int64              `json:"last_heartbeat,omitempty"`
+	Type              string              `json:"type"`
+	AgentID           string              `json:"agent_id"`
+	Status            *string             `json:"status,omitempty"`
+	Purpose           *string             `json:"purpose,omitempty"`
+	Avatar            *string             `json:"avatar,omitempty"`
+	LastSeen          *int64              `json:"last_seen,omitempty"`
+	LeftAt            *int64              `json:"left_at,omitempty"`
+	Managed           *bool               `json:"managed,omitempty"`
+	Invoke            *types.InvokeConfig `json:"invoke,omitempty"`
+	Presence          *string             `json:"presence,omitempty"`
+	MentionWatermark  *string             `json:"mention_watermark,omitempty"`
+	LastHeartbeat     *int64              `json:"last_heartbeat,omitempty"`
+	ReactionWatermark *int64              `json:"reaction_watermark,omitempty"`
 }
 
 // SessionStartJSONLRecord represents a session start event in JSONL.
@@ -241,14 +291,51 @@ type SessionStartJSONLRecord struct {
 	StartedAt   int64   `json:"started_at"`
 }
 
+// SessionResponseJSONLRecord represents a spawned session's first post in JSONL.
+type SessionResponseJSONLRecord struct {
+	Type         string `json:"type"`
+	AgentID      string `json:"agent_id"`
+	SessionID    string `json:"session_id"`
+	MessageGUID  string `json:"message_guid"`
+	RespondingTo string `json:"responding_to"`
+	LatencyMs    int64  `json:"latency_ms"`
+	RespondedAt  int64  `json:"responded_at"`
+}
+
 // SessionEndJSONLRecord represents a session end event in JSONL.
 type SessionEndJSONLRecord struct {
-	Type       string `json:"type"`
-	AgentID    string `json:"agent_id"`
-	SessionID  string `json:"session_id"`
-	ExitCode   int    `json:"exit_code"`
-	DurationMs int64  `json:"duration_ms"`
-	EndedAt    int64  `json:"ended_at"`
+	Type       string  `json:"type"`
+	AgentID    string  `json:"agent_id"`
+	SessionID  string  `json:"session_id"`
+	ExitCode   int     `json:"exit_code"`
+	DurationMs int64   `json:"duration_ms"`
+	EndedAt    int64   `json:"ended_at"`
+	Outcome    string  `json:"outcome,omitempty"`
+	Summary    *string `json:"summary,omitempty"`
+}
+
+// SessionDoneJSONLRecord represents an agent's done report in JSONL.
+type SessionDoneJSONLRecord struct {
+	Type      string  `json:"type"`
+	AgentID   string  `json:"agent_id"`
+	SessionID string  `json:"session_id,omitempty"`
+	Status    string  `json:"status"`
+	Summary   *string `json:"summary,omitempty"`
+	MessageID string  `json:"message_id"`
+	At        int64   `json:"at"`
+}
+
+// TokenUsageJSONLRecord represents a session's token usage in JSONL.
+type TokenUsageJSONLRecord struct {
+	Type             string `json:"type"`
+	AgentID          string `json:"agent_id"`
+	SessionID        string `json:"session_id,omitempty"`
+	Model            string `json:"model,omitempty"`
+	InputTokens      int64  `json:"input_tokens"`
+	CacheReadTokens  int64  `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64  `json:"cache_write_tokens,omitempty"`
+	OutputTokens     int64  `json:"output_tokens"`
+	RecordedAt       int64  `json:"recorded_at"`
 }
 
 // SessionHeartbeatJSONLRecord represents a session heartbeat event in JSONL.
@@ -272,7 +359,7 @@ type GhostCursorJSONLRecord struct {
 
 // ReactionJSONLRecord represents a reaction event in JSONL.
 type ReactionJSONLRecord struct {
-	Type        string `json:"type"` // "reaction"
+	Type        string `json:"type"` // "reaction" | "reaction_remove"
 	MessageGUID string `json:"message_guid"`
 	AgentID     string `json:"agent_id"`
 	Emoji       string `json:"emoji"`
@@ -330,6 +417,44 @@ type RoleStopJSONLRecord struct {
 	StoppedAt int64  `json:"stopped_at"`
 }
 
+// ClaimJSONLRecord records a claim being taken. Status is the agent's status
+// text at claim time.
+type ClaimJSONLRecord struct {
+	Type        string  `json:"type"` // "claim"
+	AgentID     string  `json:"agent_id"`
+	ClaimType   string  `json:"claim_type"`
+	Pattern     string  `json:"pattern"`
+	Reason      *string `json:"reason,omitempty"`
+	MessageGUID *string `json:"message_guid,omitempty"`
+	Status      *string `json:"status,omitempty"`
+	CreatedAt   int64   `json:"created_at"`
+	ExpiresAt   *int64  `json:"expires_at,omitempty"`
+}
+
+// ClaimClearJSONLRecord records a claim being released.
+type ClaimClearJSONLRecord struct {
+	Type      string `json:"type"` // "claim_clear"
+	AgentID   string `json:"agent_id"`
+	ClaimType string `json:"claim_type"`
+	Pattern   string `json:"pattern"`
+	ClearedAt int64  `json:"cleared_at"`
+	// TransferredTo names the agent the claim was released to, if any.
+	TransferredTo *string `json:"transferred_to,omitempty"`
+}
+
+// ClaimRequestJSONLRecord records an agent asking for another agent's claim.
+type ClaimRequestJSONLRecord struct {
+	Type        string  `json:"type"` // "claim_request"
+	GUID        string  `json:"guid"`
+	RequestedBy string  `json:"requested_by"`
+	Holder      string  `json:"holder"`
+	ClaimType   string  `json:"claim_type"`
+	Pattern     string  `json:"pattern"`
+	Note        *string `json:"note,omitempty"`
+	MessageGUID string  `json:"message_guid"`
+	RequestedAt int64   `json:"requested_at"`
+}
+
 // ProjectKnownAgent stores per-project known-agent data.
 type ProjectKnownAgent struct {
 	Name        *string  `json:"name,omitempty"`
@@ -1,25 +1,38 @@
 package db
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/adamavenir/fray/internal/types"
 )
 
+// appendMu serializes appends within a process so concurrent writers sharing
+// a handle never interleave partial lines.
+var appendMu sync.Mutex
+
 func appendJSONLine(filePath string, record any) error {
 	if err := ensureDir(filepath.Dir(filePath)); err != nil {
 		return err
 	}
 
-	data, err := json.Marshal(record)
+	data, err := MarshalRecord(record)
 	if err != nil {
 		return err
 	}
 
+	appendMu.Lock()
+	defer appendMu.Unlock()
+
+	release, err := lockJSONL(jsonlFrayDir(filePath), false)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 	if err != nil {
 		return err
@@ -40,9 +53,7 @@ func AppendMessage(projectPath string, message types.Message) error {
 	if home == "" {
 		home = "room"
 	}
-	// Convert new reactions format to legacy format for JSONL compatibility.
-	// Reactions are now stored in separate reaction records, so this is usually empty.
-	legacyReactions := ConvertToLegacyReactions(message.Reactions)
+	// Reactions are stored in separate reaction records, so this is usually empty.
 	record := MessageJSONLRecord{
 		Type:             "message",
 		ID:               message.ID,
@@ -51,7 +62,7 @@ func AppendMessage(projectPath string, message types.Message) error {
 		FromAgent:        message.FromAgent,
 		Body:             message.Body,
 		Mentions:         message.Mentions,
-		Reactions:        legacyReactions,
+		Reactions:        ReactionSet(message.Reactions),
 		MsgType:          message.Type,
 		References:       message.References,
 		SurfaceMessage:   message.SurfaceMessage,
@@ -60,6 +71,10 @@ func AppendMessage(projectPath string, message types.Message) error {
 		TS:               message.TS,
 		EditedAt:         message.EditedAt,
 		ArchivedAt:       message.ArchivedAt,
+		Important:        message.Important,
+		Standup:          message.Standup,
+		Commits:          message.Commits,
+		ThreadRefs:       message.ThreadRefs,
 	}
 
 	if err := appendJSONLine(filepath.Join(frayDir, messagesFile), record); err != nil {
@@ -108,24 +123,26 @@ func AppendAgent(projectPath string, agent types.Agent) error {
 	}
 
 	record := AgentJSONLRecord{
-		Type:             "agent",
-		ID:               agent.GUID,
-		Name:             name,
-		GlobalName:       &globalName,
-		HomeChannel:      nil,
-		CreatedAt:        &createdAt,
-		ActiveStatus:     &activeStatus,
-		AgentID:          agent.AgentID,
-		Status:           agent.Status,
-		Purpose:          agent.Purpose,
-		Avatar:           agent.Avatar,
-		RegisteredAt:     agent.RegisteredAt,
-		LastSeen:         agent.LastSeen,
-		LeftAt:           agent.LeftAt,
-		Managed:          agent.Managed,
-		Invoke:           agent.Invoke,
-		Presence:         string(agent.Presence),
-		MentionWatermark: agent.MentionWatermark,
+		Type:              "agent",
+		ID:                agent.GUID,
+		Name:              name,
+		GlobalName:        &globalName,
+		HomeChannel:       nil,
+		CreatedAt:         &createdAt,
+		ActiveStatus:      &activeStatus,
+		AgentID:           agent.AgentID,
+		Status:            agent.Status,
+		Purpose:           agent.Purpose,
+		Avatar:            agent.Avatar,
+		RegisteredAt:      agent.RegisteredAt,
+		LastSeen:          agent.LastSeen,
+		LeftAt:            agent.LeftAt,
+		Managed:           agent.Managed,
+		Invoke:            agent.Invoke,
+		Presence:          string(agent.Presence),
+		MentionWatermark:  agent.MentionWatermark,
+		ParentAgent:       agent.ParentAgent,
+		ReactionWatermark: agent.ReactionWatermark,
 	}
 
 	if channelID != "" {
@@ -168,6 +185,25 @@ func AppendSessionStart(projectPath string, event types.SessionStart) error {
 	return nil
 }
 
+// AppendSessionResponse appends a session's first-response link to JSONL.
+func AppendSessionResponse(projectPath string, event types.SessionResponse) error {
+	frayDir := resolveFrayDir(projectPath)
+	record := SessionResponseJSONLRecord{
+		Type:         "session_response",
+		AgentID:      event.AgentID,
+		SessionID:    event.SessionID,
+		MessageGUID:  event.MessageGUID,
+		RespondingTo: event.RespondingTo,
+		LatencyMs:    event.LatencyMs,
+		RespondedAt:  event.RespondedAt,
+	}
+	if err := appendJSONLine(filepath.Join(frayDir, agentsFile), record); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
 // AppendSessionEnd appends a session end event to JSONL.
 func AppendSessionEnd(projectPath string, event types.SessionEnd) error {
 	frayDir := resolveFrayDir(projectPath)
@@ -178,6 +214,48 @@ func AppendSessionEnd(projectPath string, event types.SessionEnd) error {
 		ExitCode:   event.ExitCode,
 		DurationMs: event.DurationMs,
 		EndedAt:    event.EndedAt,
+		Outcome:    string(event.Outcome),
+		Summary:    event.Summary,
+	}
+	if err := appendJSONLine(filepath.Join(frayDir, agentsFile), record); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
+// AppendTokenUsage appends a session's token usage to JSONL.
+func AppendTokenUsage(projectPath string, usage types.TokenUsage) error {
+	frayDir := resolveFrayDir(projectPath)
+	record := TokenUsageJSONLRecord{
+		Type:             "token_usage",
+		AgentID:          usage.AgentID,
+		SessionID:        usage.SessionID,
+		Model:            usage.Model,
+		InputTokens:      usage.InputTokens,
+		CacheReadTokens:  usage.CacheReadTokens,
+		CacheWriteTokens: usage.CacheWriteTokens,
+		OutputTokens:     usage.OutputTokens,
+		RecordedAt:       usage.RecordedAt,
+	}
+	if err := appendJSONLine(filepath.Join(frayDir, agentsFile), record); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
+// AppendSessionDone appends an agent done report to JSONL.
+func AppendSessionDone(projectPath string, event types.SessionDone) error {
+	frayDir := resolveFrayDir(projectPath)
+	record := SessionDoneJSONLRecord{
+		Type:      "session_done",
+		AgentID:   event.AgentID,
+		SessionID: event.SessionID,
+		Status:    string(event.Status),
+		Summary:   event.Summary,
+		MessageID: event.MessageID,
+		At:        event.At,
 	}
 	if err := appendJSONLine(filepath.Join(frayDir, agentsFile), record); err != nil {
 		return err
@@ -252,6 +330,7 @@ func AppendThread(projectPath string, thread types.Thread, subscribed []string)
 		AnchorMessageGUID: thread.AnchorMessageGUID,
 		AnchorHidden:      thread.AnchorHidden,
 		LastActivityAt:    thread.LastActivityAt,
+		CreatedBy:         thread.CreatedBy,
 	}
 	if err := appendJSONLine(filepath.Join(frayDir, threadsFile), record); err != nil {
 		return err
@@ -273,9 +352,8 @@ func AppendThreadUpdate(projectPath string, update ThreadUpdateJSONLRecord) erro
 
 // AppendThreadSubscribe appends a thread subscribe event to JSONL.
 func AppendThreadSubscribe(projectPath string, event ThreadSubscribeJSONLRecord) error {
-	frayDir := resolveFrayDir(projectPath)
 	event.Type = "thread_subscribe"
-	if err := appendJSONLine(filepath.Join(frayDir, threadsFile), event); err != nil {
+	if err := appendJSONLine(recordFilePath(projectPath, threadsFile, "thread_subscribe"), event); err != nil {
 		return err
 	}
 	touchDatabaseFile(projectPath)
@@ -284,9 +362,8 @@ func AppendThreadSubscribe(projectPath string, event ThreadSubscribeJSONLRecord)
 
 // AppendThreadUnsubscribe appends a thread unsubscribe event to JSONL.
 func AppendThreadUnsubscribe(projectPath string, event ThreadUnsubscribeJSONLRecord) error {
-	frayDir := resolveFrayDir(projectPath)
 	event.Type = "thread_unsubscribe"
-	if err := appendJSONLine(filepath.Join(frayDir, threadsFile), event); err != nil {
+	if err := appendJSONLine(recordFilePath(projectPath, threadsFile, "thread_unsubscribe"), event); err != nil {
 		return err
 	}
 	touchDatabaseFile(projectPath)
@@ -326,6 +403,52 @@ func AppendMessagePin(projectPath string, event MessagePinJSONLRecord) error {
 	return nil
 }
 
+// AppendAnnouncement appends an announcement record to JSONL.
+func AppendAnnouncement(projectPath string, announcement types.Announcement) error {
+	frayDir := resolveFrayDir(projectPath)
+	record := AnnouncementJSONLRecord{
+		Type:        "announcement",
+		MessageGUID: announcement.MessageGUID,
+		FromAgent:   announcement.FromAgent,
+		AckRequired: announcement.AckRequired,
+		Audience:    announcement.Audience,
+		CreatedAt:   announcement.CreatedAt,
+	}
+	if err := appendJSONLine(filepath.Join(frayDir, messagesFile), record); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
+// AppendAnnouncementAck appends an announcement ack to JSONL.
+func AppendAnnouncementAck(projectPath string, ack types.AnnouncementAck) error {
+	frayDir := resolveFrayDir(projectPath)
+	record := AnnouncementAckJSONLRecord{
+		Type:        "announcement_ack",
+		MessageGUID: ack.MessageGUID,
+		AgentID:     ack.AgentID,
+		Source:      ack.Source,
+		AckedAt:     ack.AckedAt,
+	}
+	if err := appendJSONLine(filepath.Join(frayDir, messagesFile), record); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
+// AppendAnnouncementReminder appends an announcement reminder to JSONL.
+func AppendAnnouncementReminder(projectPath string, event AnnouncementReminderJSONLRecord) error {
+	frayDir := resolveFrayDir(projectPath)
+	event.Type = "announcement_reminder"
+	if err := appendJSONLine(filepath.Join(frayDir, messagesFile), event); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
 // AppendMessageUnpin appends a message unpin event to JSONL.
 func AppendMessageUnpin(projectPath string, event MessageUnpinJSONLRecord) error {
 	frayDir := resolveFrayDir(projectPath)
@@ -394,7 +517,6 @@ func AppendThreadUnmute(projectPath string, event ThreadUnmuteJSONLRecord) error
 
 // AppendGhostCursor appends a ghost cursor event to JSONL.
 func AppendGhostCursor(projectPath string, cursor types.GhostCursor) error {
-	frayDir := resolveFrayDir(projectPath)
 	record := GhostCursorJSONLRecord{
 		Type:        "ghost_cursor",
 		AgentID:     cursor.AgentID,
@@ -403,7 +525,7 @@ func AppendGhostCursor(projectPath string, cursor types.GhostCursor) error {
 		MustRead:    cursor.MustRead,
 		SetAt:       cursor.SetAt,
 	}
-	if err := appendJSONLine(filepath.Join(frayDir, agentsFile), record); err != nil {
+	if err := appendJSONLine(recordFilePath(projectPath, agentsFile, "ghost_cursor"), record); err != nil {
 		return err
 	}
 	touchDatabaseFile(projectPath)
@@ -427,9 +549,26 @@ func AppendReaction(projectPath, messageGUID, agentID, emoji string, reactedAt i
 	return nil
 }
 
+// AppendReactionRemove appends a reaction removal record to JSONL.
+// reactedAt identifies which of the agent's reactions was removed.
+func AppendReactionRemove(projectPath, messageGUID, agentID, emoji string, reactedAt int64) error {
+	frayDir := resolveFrayDir(projectPath)
+	record := ReactionJSONLRecord{
+		Type:        "reaction_remove",
+		MessageGUID: messageGUID,
+		AgentID:     agentID,
+		Emoji:       emoji,
+		ReactedAt:   reactedAt,
+	}
+	if err := appendJSONLine(filepath.Join(frayDir, messagesFile), record); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
 // AppendAgentFave appends a fave record to JSONL.
 func AppendAgentFave(projectPath, agentID, itemType, itemGUID string, favedAt int64) error {
-	frayDir := resolveFrayDir(projectPath)
 	record := AgentFaveJSONLRecord{
 		Type:     "agent_fave",
 		AgentID:  agentID,
@@ -437,7 +576,7 @@ func AppendAgentFave(projectPath, agentID, itemType, itemGUID string, favedAt in
 		ItemGUID: itemGUID,
 		FavedAt:  favedAt,
 	}
-	if err := appendJSONLine(filepath.Join(frayDir, agentsFile), record); err != nil {
+	if err := appendJSONLine(recordFilePath(projectPath, agentsFile, "agent_fave"), record); err != nil {
 		return err
 	}
 	touchDatabaseFile(projectPath)
@@ -446,7 +585,6 @@ func AppendAgentFave(projectPath, agentID, itemType, itemGUID string, favedAt in
 
 // AppendAgentUnfave appends an unfave record to JSONL.
 func AppendAgentUnfave(projectPath, agentID, itemType, itemGUID string, unfavedAt int64) error {
-	frayDir := resolveFrayDir(projectPath)
 	record := AgentUnfaveJSONLRecord{
 		Type:      "agent_unfave",
 		AgentID:   agentID,
@@ -454,6 +592,56 @@ func AppendAgentUnfave(projectPath, agentID, itemType, itemGUID string, unfavedA
 		ItemGUID:  itemGUID,
 		UnfavedAt: unfavedAt,
 	}
+	if err := appendJSONLine(recordFilePath(projectPath, agentsFile, "agent_unfave"), record); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
+// AppendClaim appends a claim record to JSONL. Claims themselves live only in
+// SQLite; these records keep their history for `fray blame`.
+func AppendClaim(projectPath string, claim types.Claim, status *string) error {
+	frayDir := resolveFrayDir(projectPath)
+	record := ClaimJSONLRecord{
+		Type:        "claim",
+		AgentID:     claim.AgentID,
+		ClaimType:   string(claim.ClaimType),
+		Pattern:     claim.Pattern,
+		Reason:      claim.Reason,
+		MessageGUID: claim.MessageGUID,
+		Status:      status,
+		CreatedAt:   claim.CreatedAt,
+		ExpiresAt:   claim.ExpiresAt,
+	}
+	if err := appendJSONLine(filepath.Join(frayDir, agentsFile), record); err != nil {
+		return err
+	}
+	touchDatabaseFile(projectPath)
+	return nil
+}
+
+// AppendClaimClear appends a claim release record to JSONL.
+func AppendClaimClear(projectPath string, claim types.Claim, clearedAt int64) error {
+	return appendClaimClear(projectPath, claim, clearedAt, nil)
+}
+
+// AppendClaimTransfer appends a release of claim straight to another agent.
+// The new holder's claim is recorded separately with AppendClaim.
+func AppendClaimTransfer(projectPath string, claim types.Claim, to string, clearedAt int64) error {
+	return appendClaimClear(projectPath, claim, clearedAt, &to)
+}
+
+func appendClaimClear(projectPath string, claim types.Claim, clearedAt int64, transferredTo *string) error {
+	frayDir := resolveFrayDir(projectPath)
+	record := ClaimClearJSONLRecord{
+		Type:          "claim_clear",
+		AgentID:       claim.AgentID,
+		ClaimType:     string(claim.ClaimType),
+		Pattern:       claim.Pattern,
+		ClearedAt:     clearedAt,
+		TransferredTo: transferredTo,
+	}
 	if err := appendJSONLine(filepath.Join(frayDir, agentsFile), record); err != nil {
 		return err
 	}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// jsonlLockFile guards the JSONL files across processes. Appends hold it
+// shared; rewrites (prune, retention, private-record migration) hold it
+// exclusively, so no append lands in a file that is about to be replaced.
+const jsonlLockFile = "jsonl.lock"
+
+// LockJSONL takes the project's JSONL lock exclusively, waiting for appends
+// in flight, and returns its release. The holder must not append JSONL
+// records until it releases the lock.
+func LockJSONL(projectPath string) (func(), error) {
+	return lockJSONL(resolveFrayDir(projectPath), true)
+}
+
+func lockJSONL(frayDir string, exclusive bool) (func(), error) {
+	path := filepath.Join(frayDir, localDir, jsonlLockFile)
+	if err := ensureDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// jsonlFrayDir returns the .fray directory a JSONL file belongs to. Private
+// records live one level down, in .fray/local.
+func jsonlFrayDir(filePath string) string {
+	dir := filepath.Dir(filePath)
+	if filepath.Base(dir) == localDir {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package db
+
+import "os"
+
+// Windows builds skip the cross-process lock; appends are still serialized
+// within a process by appendMu.
+func lockFile(f *os.File, exclusive bool) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
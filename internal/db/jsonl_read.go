@@ -96,6 +96,8 @@ func ReadMessages(projectPath string) ([]MessageJSONLRecord, error) {
 			var update struct {
 				ID         string          `json:"id"`
 				Body       json.RawMessage `json:"body"`
+				Mentions   json.RawMessage `json:"mentions"`
+				ThreadRefs json.RawMessage `json:"thread_refs"`
 				EditedAt   json.RawMessage `json:"edited_at"`
 				ArchivedAt json.RawMessage `json:"archived_at"`
 				Reactions  json.RawMessage `json:"reactions"`
@@ -113,6 +115,18 @@ func ReadMessages(projectPath string) ([]MessageJSONLRecord, error) {
 					existing.Body = body
 				}
 			}
+			if update.Mentions != nil && string(update.Mentions) != "null" {
+				var mentions []string
+				if err := json.Unmarshal(update.Mentions, &mentions); err == nil {
+					existing.Mentions = mentions
+				}
+			}
+			if update.ThreadRefs != nil && string(update.ThreadRefs) != "null" {
+				var refs []string
+				if err := json.Unmarshal(update.ThreadRefs, &refs); err == nil {
+					existing.ThreadRefs = refs
+				}
+			}
 			if update.EditedAt != nil {
 				if string(update.EditedAt) == "null" {
 					existing.EditedAt = nil
@@ -134,9 +148,9 @@ func ReadMessages(projectPath string) ([]MessageJSONLRecord, error) {
 				}
 			}
 			if update.Reactions != nil && string(update.Reactions) != "null" {
-				var reactions map[string][]string
+				var reactions ReactionSet
 				if err := json.Unmarshal(update.Reactions, &reactions); err == nil {
-					existing.Reactions = normalizeReactionsLegacy(reactions)
+					existing.Reactions = reactions
 				}
 			}
 			messageMap[update.ID] = existing
@@ -151,6 +165,8 @@ func ReadMessages(projectPath string) ([]MessageJSONLRecord, error) {
 			}
 			existing.Home = move.NewHome
 			messageMap[move.MessageGUID] = existing
+		default:
+			noteUnknownRecordType(envelope.Type)
 		}
 	}
 
@@ -510,6 +526,8 @@ func ReadQuestions(projectPath string) ([]QuestionJSONLRecord, error) {
 				existing.AnsweredIn = update.AnsweredIn
 			}
 			questionMap[update.GUID] = existing
+		default:
+			noteUnknownRecordType(envelope.Type)
 		}
 	}
 
@@ -527,7 +545,7 @@ func ReadQuestions(projectPath string) ([]QuestionJSONLRecord, error) {
 // ReadThreads reads thread records and subscription/membership events.
 func ReadThreads(projectPath string) ([]ThreadJSONLRecord, []threadSubscriptionEvent, []threadMessageEvent, error) {
 	frayDir := resolveFrayDir(projectPath)
-	lines, err := readJSONLLines(filepath.Join(frayDir, threadsFile))
+	lines, err := readRecordLines(frayDir, threadsFile)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -602,6 +620,7 @@ func ReadThreads(projectPath string) ([]ThreadJSONLRecord, []threadSubscriptionE
 				ThreadGUID: event.ThreadGUID,
 				AgentID:    event.AgentID,
 				At:         event.SubscribedAt,
+				Level:      event.Level,
 			})
 		case "thread_unsubscribe":
 			var event ThreadUnsubscribeJSONLRecord
@@ -638,6 +657,8 @@ func ReadThreads(projectPath string) ([]ThreadJSONLRecord, []threadSubscriptionE
 				RemovedBy:   event.RemovedBy,
 				RemovedAt:   event.RemovedAt,
 			})
+		default:
+			noteUnknownRecordType(envelope.Type)
 		}
 	}
 
@@ -656,7 +677,7 @@ func ReadThreads(projectPath string) ([]ThreadJSONLRecord, []threadSubscriptionE
 // ReadAgents reads agent JSONL records and applies updates.
 func ReadAgents(projectPath string) ([]AgentJSONLRecord, error) {
 	frayDir := resolveFrayDir(projectPath)
-	lines, err := readJSONLLines(filepath.Join(frayDir, agentsFile))
+	lines, err := readRecordLines(frayDir, agentsFile)
 	if err != nil {
 		return nil, err
 	}
@@ -723,9 +744,14 @@ func ReadAgents(projectPath string) ([]AgentJSONLRecord, error) {
 			if update.LastHeartbeat != nil {
 				existing.LastHeartbeat = update.LastHeartbeat
 			}
+			if update.ReactionWatermark != nil {
+				existing.ReactionWatermark = update.ReactionWatermark
+			}
 			agentMap[update.AgentID] = existing
 		// session_start, session_end, session_heartbeat are events, not agent records
 		// They are handled separately when needed
+		default:
+			noteUnknownRecordType(envelope.Type)
 		}
 	}
 
@@ -745,6 +771,7 @@ type threadSubscriptionEvent struct {
 	ThreadGUID string
 	AgentID    string
 	At         int64
+	Level      types.SubscriptionLevel
 }
 
 type threadMessageEvent struct {
@@ -779,7 +806,7 @@ type threadMuteEvent struct {
 // ReadThreadPins reads thread pin events from JSONL for rebuilding the database.
 func ReadThreadPins(projectPath string) ([]threadPinEvent, error) {
 	frayDir := resolveFrayDir(projectPath)
-	lines, err := readJSONLLines(filepath.Join(frayDir, threadsFile))
+	lines, err := readRecordLines(frayDir, threadsFile)
 	if err != nil {
 		return nil, err
 	}
@@ -825,7 +852,7 @@ func ReadThreadPins(projectPath string) ([]threadPinEvent, error) {
 // ReadThreadMutes reads thread mute events from JSONL for rebuilding the database.
 func ReadThreadMutes(projectPath string) ([]threadMuteEvent, error) {
 	frayDir := resolveFrayDir(projectPath)
-	lines, err := readJSONLLines(filepath.Join(frayDir, threadsFile))
+	lines, err := readRecordLines(frayDir, threadsFile)
 	if err != nil {
 		return nil, err
 	}
@@ -874,7 +901,7 @@ func ReadThreadMutes(projectPath string) ([]threadMuteEvent, error) {
 // Ghost cursors track recommended read positions for session handoffs.
 func ReadGhostCursors(projectPath string) ([]GhostCursorJSONLRecord, error) {
 	frayDir := resolveFrayDir(projectPath)
-	lines, err := readJSONLLines(filepath.Join(frayDir, agentsFile))
+	lines, err := readRecordLines(frayDir, agentsFile)
 	if err != nil {
 		return nil, err
 	}
@@ -911,7 +938,129 @@ func ReadGhostCursors(projectPath string) ([]GhostCursorJSONLRecord, error) {
 	return cursors, nil
 }
 
-// ReadReactions reads all reaction records from messages.jsonl.
+// ReadAgentSessions assembles session_start, session_response, session_done,
+// and session_end events from agents.jsonl into per-session summaries for one agent, oldest first.
+// A done report without a session ID attaches to the agent's latest session.
+func ReadAgentSessions(projectPath, agentID string) ([]types.AgentSession, error) {
+	frayDir := resolveFrayDir(projectPath)
+	lines, err := readRecordLines(frayDir, agentsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*types.AgentSession
+	byID := make(map[string]*types.AgentSession)
+	session := func(id string) *types.AgentSession {
+		if id == "" {
+			if len(sessions) == 0 {
+				sessions = append(sessions, &types.AgentSession{AgentID: agentID})
+			}
+			return sessions[len(sessions)-1]
+		}
+		if existing, ok := byID[id]; ok {
+			return existing
+		}
+		created := &types.AgentSession{AgentID: agentID, SessionID: id}
+		byID[id] = created
+		sessions = append(sessions, created)
+		return created
+	}
+
+	for _, line := range lines {
+		var envelope struct {
+			Type    string `json:"type"`
+			AgentID string `json:"agent_id"`
+		}
+		if err := json.Unmarshal([]byte(line), &envelope); err != nil || envelope.AgentID != agentID {
+			continue
+		}
+
+		switch envelope.Type {
+		case "session_start":
+			var record SessionStartJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			entry := session(record.SessionID)
+			entry.StartedAt = record.StartedAt
+			entry.TriggeredBy = record.TriggeredBy
+		case "session_response":
+			var record SessionResponseJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			entry := session(record.SessionID)
+			response, latency := record.MessageGUID, record.LatencyMs
+			entry.ResponseGUID = &response
+			entry.ResponseLatencyMs = &latency
+		case "session_done":
+			var record SessionDoneJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			entry := session(record.SessionID)
+			entry.Outcome = types.DoneStatus(record.Status)
+			entry.Summary = record.Summary
+		case "session_end":
+			var record SessionEndJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			entry := session(record.SessionID)
+			endedAt := record.EndedAt
+			exitCode := record.ExitCode
+			entry.EndedAt = &endedAt
+			entry.ExitCode = &exitCode
+			entry.DurationMs = record.DurationMs
+			if record.Outcome != "" {
+				entry.Outcome = types.DoneStatus(record.Outcome)
+				entry.Summary = record.Summary
+			}
+		}
+	}
+
+	result := make([]types.AgentSession, 0, len(sessions))
+	for _, entry := range sessions {
+		result = append(result, *entry)
+	}
+	return result, nil
+}
+
+// ReadTokenUsage reads token_usage events from agents.jsonl in file order.
+func ReadTokenUsage(projectPath string) ([]types.TokenUsage, error) {
+	frayDir := resolveFrayDir(projectPath)
+	lines, err := readRecordLines(frayDir, agentsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage []types.TokenUsage
+	for _, line := range lines {
+		var envelope struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(line), &envelope); err != nil || envelope.Type != "token_usage" {
+			continue
+		}
+		var record TokenUsageJSONLRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			continue
+		}
+		usage = append(usage, types.TokenUsage{
+			AgentID:          record.AgentID,
+			SessionID:        record.SessionID,
+			Model:            record.Model,
+			InputTokens:      record.InputTokens,
+			CacheReadTokens:  record.CacheReadTokens,
+			CacheWriteTokens: record.CacheWriteTokens,
+			OutputTokens:     record.OutputTokens,
+			RecordedAt:       record.RecordedAt,
+		})
+	}
+	return usage, nil
+}
+
+// ReadReactions reads reaction records from messages.jsonl, dropping removed ones.
 func ReadReactions(projectPath string) ([]ReactionJSONLRecord, error) {
 	frayDir := resolveFrayDir(projectPath)
 	lines, err := readJSONLLines(filepath.Join(frayDir, messagesFile))
@@ -928,17 +1077,101 @@ func ReadReactions(projectPath string) ([]ReactionJSONLRecord, error) {
 			continue
 		}
 
-		if envelope.Type == "reaction" {
+		switch envelope.Type {
+		case "reaction":
 			var record ReactionJSONLRecord
 			if err := json.Unmarshal([]byte(line), &record); err != nil {
 				continue
 			}
 			reactions = append(reactions, record)
+		case "reaction_remove":
+			var record ReactionJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			for i := len(reactions) - 1; i >= 0; i-- {
+				r := reactions[i]
+				if r.MessageGUID == record.MessageGUID && r.AgentID == record.AgentID &&
+					r.Emoji == record.Emoji && r.ReactedAt == record.ReactedAt {
+					reactions = append(reactions[:i], reactions[i+1:]...)
+					break
+				}
+			}
 		}
 	}
 	return reactions, nil
 }
 
+// ReadAnnouncements reads announcements, with reminders applied, and their
+// recorded acks from JSONL. Only the first ack per agent is kept.
+func ReadAnnouncements(projectPath string) ([]types.Announcement, []types.AnnouncementAck, error) {
+	frayDir := resolveFrayDir(projectPath)
+	lines, err := readJSONLLines(filepath.Join(frayDir, messagesFile))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var announcements []types.Announcement
+	index := map[string]int{}
+	var acks []types.AnnouncementAck
+	acked := map[[2]string]bool{}
+	for _, line := range lines {
+		var envelope struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(line), &envelope); err != nil {
+			continue
+		}
+
+		switch envelope.Type {
+		case "announcement":
+			var record AnnouncementJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			announcement := types.Announcement{
+				MessageGUID: record.MessageGUID,
+				FromAgent:   record.FromAgent,
+				AckRequired: record.AckRequired,
+				Audience:    record.Audience,
+				CreatedAt:   record.CreatedAt,
+			}
+			if i, ok := index[record.MessageGUID]; ok {
+				announcements[i] = announcement
+				continue
+			}
+			index[record.MessageGUID] = len(announcements)
+			announcements = append(announcements, announcement)
+		case "announcement_ack":
+			var record AnnouncementAckJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			key := [2]string{record.MessageGUID, record.AgentID}
+			if acked[key] {
+				continue
+			}
+			acked[key] = true
+			acks = append(acks, types.AnnouncementAck{
+				MessageGUID: record.MessageGUID,
+				AgentID:     record.AgentID,
+				Source:      record.Source,
+				AckedAt:     record.AckedAt,
+			})
+		case "announcement_reminder":
+			var record AnnouncementReminderJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			if i, ok := index[record.MessageGUID]; ok {
+				remindedAt := record.RemindedAt
+				announcements[i].RemindedAt = &remindedAt
+			}
+		}
+	}
+	return announcements, acks, nil
+}
+
 // faveEvent represents a fave or unfave event for rebuilding.
 type faveEvent struct {
 	Type      string
@@ -952,7 +1185,7 @@ type faveEvent struct {
 // ReadFaves reads fave events from agents.jsonl for rebuilding the database.
 func ReadFaves(projectPath string) ([]faveEvent, error) {
 	frayDir := resolveFrayDir(projectPath)
-	lines, err := readJSONLLines(filepath.Join(frayDir, agentsFile))
+	lines, err := readRecordLines(frayDir, agentsFile)
 	if err != nil {
 		return nil, err
 	}
@@ -1010,7 +1243,7 @@ type roleEvent struct {
 // ReadRoles reads role events from agents.jsonl for rebuilding the database.
 func ReadRoles(projectPath string) ([]roleEvent, error) {
 	frayDir := resolveFrayDir(projectPath)
-	lines, err := readJSONLLines(filepath.Join(frayDir, agentsFile))
+	lines, err := readRecordLines(frayDir, agentsFile)
 	if err != nil {
 		return nil, err
 	}
@@ -1074,3 +1307,58 @@ func ReadRoles(projectPath string) ([]roleEvent, error) {
 	}
 	return events, nil
 }
+
+// ReadClaimHistory reads claim events from agents.jsonl and pairs each claim
+// with the release that followed it, in the order claims were taken.
+func ReadClaimHistory(projectPath string) ([]types.ClaimHistoryEntry, error) {
+	frayDir := resolveFrayDir(projectPath)
+	lines, err := readRecordLines(frayDir, agentsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []types.ClaimHistoryEntry
+	open := map[string]int{}
+	for _, line := range lines {
+		var envelope struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(line), &envelope); err != nil {
+			continue
+		}
+
+		switch envelope.Type {
+		case "claim":
+			var record ClaimJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			open[record.ClaimType+":"+record.Pattern] = len(entries)
+			entries = append(entries, types.ClaimHistoryEntry{
+				AgentID:     record.AgentID,
+				ClaimType:   types.ClaimType(record.ClaimType),
+				Pattern:     record.Pattern,
+				Reason:      record.Reason,
+				MessageGUID: record.MessageGUID,
+				Status:      record.Status,
+				CreatedAt:   record.CreatedAt,
+				ExpiresAt:   record.ExpiresAt,
+			})
+		case "claim_clear":
+			var record ClaimClearJSONLRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			key := record.ClaimType + ":" + record.Pattern
+			idx, ok := open[key]
+			if !ok {
+				continue
+			}
+			clearedAt := record.ClearedAt
+			entries[idx].ClearedAt = &clearedAt
+			entries[idx].TransferredTo = record.TransferredTo
+			delete(open, key)
+		}
+	}
+	return entries, nil
+}
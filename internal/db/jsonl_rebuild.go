@@ -139,6 +139,36 @@ func ReadProjectConfig(projectPath string) (*ProjectConfig, error) {
 
 // RebuildDatabaseFromJSONL resets the SQLite cache using JSONL sources.
 func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
+	return RebuildDatabase(db, projectPath, RebuildOptions{})
+}
+
+// RebuildDatabase resets the SQLite cache from JSONL under the rebuild lock
+// (.fray/local/rebuild.lock), so other processes wait instead of reading a
+// half-built cache, and records the run in .fray/local/rebuild.json.
+func RebuildDatabase(db DBTX, projectPath string, opts RebuildOptions) error {
+	release, err := acquireRebuildLock(projectPath)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	started := time.Now()
+	w := newRebuildWriter(db, opts.Progress)
+	if err := rebuildFromJSONL(db, projectPath, w); err != nil {
+		w.rollback()
+		return err
+	}
+	return writeRebuildStats(projectPath, RebuildStats{
+		FinishedAt: time.Now().Unix(),
+		DurationMs: time.Since(started).Milliseconds(),
+		Rows:       w.rows,
+		Tables:     w.tables,
+	})
+}
+
+// rebuildFromJSONL drops and refills the JSONL-backed tables, writing rows
+// through w one table batch at a time.
+func rebuildFromJSONL(db DBTX, projectPath string, w *rebuildWriter) error {
 	messages, err := ReadMessages(projectPath)
 	if err != nil {
 		return err
@@ -183,6 +213,14 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 	if err != nil {
 		return err
 	}
+	tokenUsage, err := ReadTokenUsage(projectPath)
+	if err != nil {
+		return err
+	}
+	announcements, announcementAcks, err := ReadAnnouncements(projectPath)
+	if err != nil {
+		return err
+	}
 	config, err := ReadProjectConfig(projectPath)
 	if err != nil {
 		return err
@@ -233,6 +271,12 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 	if _, err := db.Exec("DROP TABLE IF EXISTS fray_session_roles"); err != nil {
 		return err
 	}
+	if _, err := db.Exec("DROP TABLE IF EXISTS fray_announcements"); err != nil {
+		return err
+	}
+	if _, err := db.Exec("DROP TABLE IF EXISTS fray_announcement_acks"); err != nil {
+		return err
+	}
 	if err := initSchemaWith(db); err != nil {
 		return fmt.Errorf("initSchemaWith: %w", err)
 	}
@@ -248,10 +292,13 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 	}
 
+	if err := w.batch("agents"); err != nil {
+		return err
+	}
 	insertAgent := `
 		INSERT OR REPLACE INTO fray_agents (
-			guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, parent_agent, reaction_watermark
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	for _, agent := range agents {
@@ -284,7 +331,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 			presence = "offline"
 		}
 
-		if _, err := db.Exec(insertAgent,
+		if _, err := w.Exec(insertAgent,
 			agent.ID,
 			agent.AgentID,
 			status,
@@ -298,15 +345,30 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 			presence,
 			agent.MentionWatermark,
 			agent.LastHeartbeat,
+			agent.ParentAgent,
+			agent.ReactionWatermark,
 		); err != nil {
 			return err
 		}
 	}
 
+	// The latest token_usage event per agent restores its last known usage.
+	for _, usage := range tokenUsage {
+		if _, err := w.Exec(`
+			UPDATE fray_agents SET last_known_input = ?, last_known_output = ?, tokens_updated_at = ?
+			WHERE agent_id = ?
+		`, usage.InputTokens, usage.OutputTokens, usage.RecordedAt, usage.AgentID); err != nil {
+			return err
+		}
+	}
+
+	if err := w.batch("messages"); err != nil {
+		return err
+	}
 	insertMessage := `
 		INSERT OR REPLACE INTO fray_messages (
-			guid, ts, channel_id, home, from_agent, body, mentions, type, "references", surface_message, reply_to, quote_message_guid, edited_at, archived_at, reactions
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			guid, ts, channel_id, home, from_agent, body, mentions, type, "references", surface_message, reply_to, quote_message_guid, edited_at, archived_at, reactions, important, standup, commits, thread_refs
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	for _, message := range messages {
@@ -314,7 +376,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		if err != nil {
 			return err
 		}
-		reactionsJSON, err := json.Marshal(normalizeReactionsLegacy(message.Reactions))
+		reactionsJSON, err := json.Marshal(normalizeReactionsLegacy(ConvertToLegacyReactions(message.Reactions)))
 		if err != nil {
 			return err
 		}
@@ -327,8 +389,16 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		if home == "" {
 			home = "room"
 		}
+		commitsJSON, err := marshalCommits(message.Commits)
+		if err != nil {
+			return err
+		}
+		threadRefsJSON, err := marshalThreadRefs(message.ThreadRefs)
+		if err != nil {
+			return err
+		}
 
-		if _, err := db.Exec(insertMessage,
+		if _, err := w.Exec(insertMessage,
 			message.ID,
 			message.TS,
 			message.ChannelID,
@@ -344,11 +414,18 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 			message.EditedAt,
 			message.ArchivedAt,
 			string(reactionsJSON),
+			message.Important,
+			message.Standup,
+			commitsJSON,
+			threadRefsJSON,
 		); err != nil {
 			return err
 		}
 	}
 
+	if err := w.batch("questions"); err != nil {
+		return err
+	}
 	if len(questions) > 0 {
 		insertQuestion := `
 			INSERT OR REPLACE INTO fray_questions (
@@ -368,7 +445,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 				}
 				optionsJSON = string(optBytes)
 			}
-			if _, err := db.Exec(insertQuestion,
+			if _, err := w.Exec(insertQuestion,
 				question.GUID,
 				question.Re,
 				question.FromAgent,
@@ -385,6 +462,9 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 	}
 
+	if err := w.batch("threads"); err != nil {
+		return err
+	}
 	if len(threads) > 0 {
 		// Topologically sort threads so parents are inserted before children
 		// (required for FK constraint on parent_thread)
@@ -392,8 +472,8 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 
 		insertThread := `
 			INSERT OR REPLACE INTO fray_threads (
-				guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+				guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at, created_by
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`
 		for i, thread := range threads {
 			status := thread.Status
@@ -408,7 +488,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 			if thread.AnchorHidden {
 				anchorHidden = 1
 			}
-			if _, err := db.Exec(insertThread,
+			if _, err := w.Exec(insertThread,
 				thread.GUID,
 				thread.Name,
 				thread.ParentThread,
@@ -418,6 +498,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 				thread.AnchorMessageGUID,
 				anchorHidden,
 				thread.LastActivityAt,
+				thread.CreatedBy,
 			); err != nil {
 				parent := ""
 				if thread.ParentThread != nil {
@@ -427,17 +508,21 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 			}
 		}
 
-		subscriptions := make(map[string]map[string]int64)
+		type subscriptionState struct {
+			at    int64
+			level types.SubscriptionLevel
+		}
+		subscriptions := make(map[string]map[string]subscriptionState)
 		for _, thread := range threads {
 			if len(thread.Subscribed) == 0 {
 				continue
 			}
-			set := make(map[string]int64, len(thread.Subscribed))
+			set := make(map[string]subscriptionState, len(thread.Subscribed))
 			for _, agentID := range thread.Subscribed {
 				if agentID == "" {
 					continue
 				}
-				set[agentID] = thread.CreatedAt
+				set[agentID] = subscriptionState{at: thread.CreatedAt, level: types.SubscriptionAll}
 			}
 			if len(set) > 0 {
 				subscriptions[thread.GUID] = set
@@ -457,23 +542,32 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 			}
 			set, ok := subscriptions[event.ThreadGUID]
 			if !ok {
-				set = make(map[string]int64)
+				set = make(map[string]subscriptionState)
 				subscriptions[event.ThreadGUID] = set
 			}
 			switch event.Type {
 			case "thread_subscribe":
-				set[event.AgentID] = event.At
+				// A subscribe without a level (e.g. auto-subscribe on post)
+				// keeps the level already chosen.
+				state := subscriptionState{at: event.At, level: event.Level}
+				if state.level == "" {
+					state.level = types.SubscriptionAll
+					if prev, ok := set[event.AgentID]; ok {
+						state.level = prev.level
+					}
+				}
+				set[event.AgentID] = state
 			case "thread_unsubscribe":
 				delete(set, event.AgentID)
 			}
 		}
 
 		for threadGUID, set := range subscriptions {
-			for agentID, subscribedAt := range set {
-				if _, err := db.Exec(`
-					INSERT OR REPLACE INTO fray_thread_subscriptions (thread_guid, agent_id, subscribed_at)
-					VALUES (?, ?, ?)
-				`, threadGUID, agentID, subscribedAt); err != nil {
+			for agentID, state := range set {
+				if _, err := w.Exec(`
+					INSERT OR REPLACE INTO fray_thread_subscriptions (thread_guid, agent_id, subscribed_at, level)
+					VALUES (?, ?, ?, ?)
+				`, threadGUID, agentID, state.at, string(state.level)); err != nil {
 					return fmt.Errorf("subscription thread=%s agent=%s: %w", threadGUID, agentID, err)
 				}
 			}
@@ -505,7 +599,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 
 		for _, messages := range threadMessages {
 			for _, entry := range messages {
-				if _, err := db.Exec(`
+				if _, err := w.Exec(`
 					INSERT OR REPLACE INTO fray_thread_messages (thread_guid, message_guid, added_by, added_at)
 					VALUES (?, ?, ?, ?)
 				`, entry.ThreadGUID, entry.MessageGUID, entry.AddedBy, entry.AddedAt); err != nil {
@@ -515,6 +609,10 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 	}
 
+	if err := w.batch("message_pins"); err != nil {
+		return err
+	}
+
 	// Rebuild message pins
 	if len(pinEvents) > 0 {
 		// Track current pin state per (message, thread) pair
@@ -535,7 +633,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 
 		for _, pin := range pins {
-			if _, err := db.Exec(`
+			if _, err := w.Exec(`
 				INSERT OR REPLACE INTO fray_message_pins (message_guid, thread_guid, pinned_by, pinned_at)
 				VALUES (?, ?, ?, ?)
 			`, pin.MessageGUID, pin.ThreadGUID, pin.PinnedBy, pin.PinnedAt); err != nil {
@@ -544,6 +642,10 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 	}
 
+	if err := w.batch("thread_pins"); err != nil {
+		return err
+	}
+
 	// Rebuild thread pins
 	if len(threadPinEvents) > 0 {
 		threadPins := make(map[string]threadPinEvent)
@@ -558,7 +660,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 
 		for _, pin := range threadPins {
-			if _, err := db.Exec(`
+			if _, err := w.Exec(`
 				INSERT OR REPLACE INTO fray_thread_pins (thread_guid, pinned_by, pinned_at)
 				VALUES (?, ?, ?)
 			`, pin.ThreadGUID, pin.PinnedBy, pin.PinnedAt); err != nil {
@@ -567,6 +669,10 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 	}
 
+	if err := w.batch("thread_mutes"); err != nil {
+		return err
+	}
+
 	// Rebuild thread mutes
 	if len(threadMuteEvents) > 0 {
 		type muteKey struct {
@@ -586,7 +692,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 
 		for _, mute := range mutes {
-			if _, err := db.Exec(`
+			if _, err := w.Exec(`
 				INSERT OR REPLACE INTO fray_thread_mutes (thread_guid, agent_id, muted_at, expires_at)
 				VALUES (?, ?, ?, ?)
 			`, mute.ThreadGUID, mute.AgentID, mute.MutedAt, mute.ExpiresAt); err != nil {
@@ -595,6 +701,10 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 	}
 
+	if err := w.batch("ghost_cursors"); err != nil {
+		return err
+	}
+
 	// Rebuild ghost cursors
 	if len(ghostCursors) > 0 {
 		for _, cursor := range ghostCursors {
@@ -602,7 +712,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 			if cursor.MustRead {
 				mustRead = 1
 			}
-			if _, err := db.Exec(`
+			if _, err := w.Exec(`
 				INSERT OR REPLACE INTO fray_ghost_cursors (agent_id, home, message_guid, must_read, set_at)
 				VALUES (?, ?, ?, ?, ?)
 			`, cursor.AgentID, cursor.Home, cursor.MessageGUID, mustRead, cursor.SetAt); err != nil {
@@ -611,10 +721,17 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 	}
 
-	// Rebuild reactions from reaction records
+	if err := w.batch("reactions"); err != nil {
+		return err
+	}
+
+	// Rebuild reactions from reaction records, then fold in reactions carried
+	// inline on message records (older projects). Inline reactions without a
+	// timestamp are dated to their message so recency ordering still works.
+	reactions = append(reactions, inlineReactionRecords(messages, reactions)...)
 	if len(reactions) > 0 {
 		for _, r := range reactions {
-			if _, err := db.Exec(`
+			if _, err := w.Exec(`
 				INSERT INTO fray_reactions (message_guid, agent_id, emoji, reacted_at)
 				VALUES (?, ?, ?, ?)
 			`, r.MessageGUID, r.AgentID, r.Emoji, r.ReactedAt); err != nil {
@@ -623,6 +740,38 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 	}
 
+	if err := w.batch("announcements"); err != nil {
+		return err
+	}
+	for _, announcement := range announcements {
+		audience, err := json.Marshal(announcement.Audience)
+		if err != nil {
+			return err
+		}
+		ackRequired := 0
+		if announcement.AckRequired {
+			ackRequired = 1
+		}
+		if _, err := w.Exec(`
+			INSERT OR REPLACE INTO fray_announcements (message_guid, from_agent, ack_required, audience, created_at, reminded_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, announcement.MessageGUID, announcement.FromAgent, ackRequired, string(audience), announcement.CreatedAt, announcement.RemindedAt); err != nil {
+			return err
+		}
+	}
+	for _, ack := range announcementAcks {
+		if _, err := w.Exec(`
+			INSERT OR IGNORE INTO fray_announcement_acks (message_guid, agent_id, source, acked_at)
+			VALUES (?, ?, ?, ?)
+		`, ack.MessageGUID, ack.AgentID, ack.Source, ack.AckedAt); err != nil {
+			return err
+		}
+	}
+
+	if err := w.batch("faves"); err != nil {
+		return err
+	}
+
 	// Rebuild faves from fave events
 	if len(faveEvents) > 0 {
 		type faveKey struct {
@@ -643,7 +792,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 
 		for _, fave := range faves {
-			if _, err := db.Exec(`
+			if _, err := w.Exec(`
 				INSERT OR REPLACE INTO fray_faves (agent_id, item_type, item_guid, faved_at)
 				VALUES (?, ?, ?, ?)
 			`, fave.AgentID, fave.ItemType, fave.ItemGUID, fave.FavedAt); err != nil {
@@ -652,6 +801,10 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 	}
 
+	if err := w.batch("roles"); err != nil {
+		return err
+	}
+
 	// Rebuild roles from role events
 	if len(roleEvents) > 0 {
 		// Track held roles (persistent assignments)
@@ -687,7 +840,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 
 		// Insert held roles
 		for key, assignedAt := range heldRoles {
-			if _, err := db.Exec(`
+			if _, err := w.Exec(`
 				INSERT OR REPLACE INTO fray_role_assignments (agent_id, role_name, assigned_at)
 				VALUES (?, ?, ?)
 			`, key.agentID, key.roleName, assignedAt); err != nil {
@@ -697,7 +850,7 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 
 		// Insert session roles
 		for _, role := range sessionRoles {
-			if _, err := db.Exec(`
+			if _, err := w.Exec(`
 				INSERT OR REPLACE INTO fray_session_roles (agent_id, role_name, session_id, started_at)
 				VALUES (?, ?, ?, ?)
 			`, role.AgentID, role.RoleName, role.SessionID, role.StartedAt); err != nil {
@@ -706,7 +859,12 @@ func RebuildDatabaseFromJSONL(db DBTX, projectPath string) error {
 		}
 	}
 
-	return nil
+	if err := w.commit(); err != nil {
+		return err
+	}
+	// Reference counts are derived from the messages, so they're recomputed
+	// once everything is in place.
+	return refreshAllThreadReferenceCounts(db)
 }
 
 // topoSortThreads sorts threads so parents appear before children.
@@ -0,0 +1,261 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RecordSpec describes one JSONL record type: the file it lives in, the
+// envelope version writers stamp into its "v" field, and the struct it
+// decodes into. Records written before versioning have no "v" and read as
+// version 1.
+type RecordSpec struct {
+	Type        string
+	File        string
+	Version     int
+	Description string
+	record      any
+}
+
+// undoLogFile is the undo log's path relative to .fray/.
+const undoLogFile = localDir + "/" + undoFile
+
+// recordSpecs is the catalogue of every record type fray writes. Bump a
+// type's Version when its meaning changes in a way older readers would get
+// wrong; adding optional fields does not need a bump.
+var recordSpecs = []RecordSpec{
+	{"message", messagesFile, 1, "A posted message", MessageJSONLRecord{}},
+	{"message_update", messagesFile, 1, "Edit, archive, or reaction change to a message", MessageUpdateJSONLRecord{}},
+	{"message_move", messagesFile, 1, "Message moved to another home", MessageMoveJSONLRecord{}},
+	{"message_pin", messagesFile, 1, "Message pinned in a thread", MessagePinJSONLRecord{}},
+	{"message_unpin", messagesFile, 1, "Message unpinned from a thread", MessageUnpinJSONLRecord{}},
+	{"announcement", messagesFile, 1, "Room-wide announcement, optionally ack-required", AnnouncementJSONLRecord{}},
+	{"announcement_ack", messagesFile, 1, "Agent acknowledged an announcement", AnnouncementAckJSONLRecord{}},
+	{"announcement_reminder", messagesFile, 1, "Non-ackers re-mentioned about an announcement", AnnouncementReminderJSONLRecord{}},
+	{"reaction", messagesFile, 1, "Reaction left on a message", ReactionJSONLRecord{}},
+	{"reaction_remove", messagesFile, 1, "Reaction taken back", ReactionJSONLRecord{}},
+	{"agent", agentsFile, 1, "Agent registration", AgentJSONLRecord{}},
+	{"agent_update", agentsFile, 1, "Change to an agent's fields", AgentUpdateJSONLRecord{}},
+	{"session_start", agentsFile, 1, "Agent session started", SessionStartJSONLRecord{}},
+	{"session_end", agentsFile, 1, "Agent session ended", SessionEndJSONLRecord{}},
+	{"session_response", agentsFile, 1, "First post of a spawned session, linked to its trigger", SessionResponseJSONLRecord{}},
+	{"session_done", agentsFile, 1, "Structured done report for a session", SessionDoneJSONLRecord{}},
+	{"session_heartbeat", agentsFile, 1, "Agent heartbeat", SessionHeartbeatJSONLRecord{}},
+	{"token_usage", agentsFile, 1, "Token usage snapshot for a session", TokenUsageJSONLRecord{}},
+	{"ghost_cursor", agentsFile, 1, "Handoff read position for an agent", GhostCursorJSONLRecord{}},
+	{"agent_fave", agentsFile, 1, "Thread or message faved", AgentFaveJSONLRecord{}},
+	{"agent_unfave", agentsFile, 1, "Thread or message unfaved", AgentUnfaveJSONLRecord{}},
+	{"claim", agentsFile, 1, "Resource claim", ClaimJSONLRecord{}},
+	{"claim_clear", agentsFile, 1, "Resource claim released", ClaimClearJSONLRecord{}},
+	{"claim_request", agentsFile, 1, "Agent asked a claim holder to hand the claim over", ClaimRequestJSONLRecord{}},
+	{"role_hold", agentsFile, 1, "Role assigned to an agent", RoleHoldJSONLRecord{}},
+	{"role_drop", agentsFile, 1, "Role assignment dropped", RoleDropJSONLRecord{}},
+	{"role_play", agentsFile, 1, "Role played for a session", RolePlayJSONLRecord{}},
+	{"role_stop", agentsFile, 1, "Session role stopped", RoleStopJSONLRecord{}},
+	{"question", questionsFile, 1, "Tracked question", QuestionJSONLRecord{}},
+	{"question_update", questionsFile, 1, "Change to a question", QuestionUpdateJSONLRecord{}},
+	{"thread", threadsFile, 1, "Thread creation", ThreadJSONLRecord{}},
+	{"thread_update", threadsFile, 1, "Change to a thread", ThreadUpdateJSONLRecord{}},
+	{"thread_subscribe", threadsFile, 1, "Agent subscribed to a thread", ThreadSubscribeJSONLRecord{}},
+	{"thread_unsubscribe", threadsFile, 1, "Agent unsubscribed from a thread", ThreadUnsubscribeJSONLRecord{}},
+	{"thread_message", threadsFile, 1, "Message added to a thread", ThreadMessageJSONLRecord{}},
+	{"thread_message_remove", threadsFile, 1, "Message removed from a thread", ThreadMessageRemoveJSONLRecord{}},
+	{"thread_pin", threadsFile, 1, "Thread pinned", ThreadPinJSONLRecord{}},
+	{"thread_unpin", threadsFile, 1, "Thread unpinned", ThreadUnpinJSONLRecord{}},
+	{"thread_mute", threadsFile, 1, "Thread muted for an agent", ThreadMuteJSONLRecord{}},
+	{"thread_unmute", threadsFile, 1, "Thread unmuted for an agent", ThreadUnmuteJSONLRecord{}},
+	{"client_pref", threadsFile, 1, "Team-wide client hint for a thread (priority, color, ...)", ClientPrefJSONLRecord{}},
+	{AuditRecordEntry, auditFile, 1, "Administrative command and who ran it", AuditRecord{}},
+	{UndoRecordEntry, undoLogFile, 1, "Undoable operation and its inverse (local only)", UndoRecord{}},
+	{UndoRecordApplied, undoLogFile, 1, "Undo entry applied (local only)", UndoRecord{}},
+	{UndoRecordBarrier, undoLogFile, 1, "Non-undoable operation (local only)", UndoRecord{}},
+}
+
+var recordsByType = func() map[string]RecordSpec {
+	specs := make(map[string]RecordSpec, len(recordSpecs))
+	for _, spec := range recordSpecs {
+		specs[spec.Type] = spec
+	}
+	return specs
+}()
+
+// RecordSpecs returns the record catalogue ordered by file, then type.
+func RecordSpecs() []RecordSpec {
+	specs := append([]RecordSpec(nil), recordSpecs...)
+	sort.Slice(specs, func(i, j int) bool {
+		if specs[i].File != specs[j].File {
+			return specs[i].File < specs[j].File
+		}
+		return specs[i].Type < specs[j].Type
+	})
+	return specs
+}
+
+// LookupRecordSpec returns the spec for a record type.
+func LookupRecordSpec(recordType string) (RecordSpec, bool) {
+	spec, ok := recordsByType[recordType]
+	return spec, ok
+}
+
+// DecodeRecord decodes a JSONL line into its registered struct, returned as
+// a pointer. Unknown fields are ignored so newer writers stay readable; the
+// version is 1 for records written before versioning.
+func DecodeRecord(line []byte) (RecordSpec, int, any, error) {
+	var envelope struct {
+		Type string `json:"type"`
+		V    int    `json:"v"`
+	}
+	if err := json.Unmarshal(line, &envelope); err != nil {
+		return RecordSpec{}, 0, nil, err
+	}
+	spec, ok := recordsByType[envelope.Type]
+	if !ok {
+		return RecordSpec{}, 0, nil, fmt.Errorf("unknown record type: %q", envelope.Type)
+	}
+	record := reflect.New(reflect.TypeOf(spec.record)).Interface()
+	if err := json.Unmarshal(line, record); err != nil {
+		return spec, 0, nil, err
+	}
+	version := envelope.V
+	if version == 0 {
+		version = 1
+	}
+	return spec, version, record, nil
+}
+
+// MarshalRecord encodes a record for JSONL, stamping the registered version
+// into a "v" field right after "type".
+func MarshalRecord(record any) ([]byte, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	spec, ok := recordsByType[envelope.Type]
+	if !ok {
+		return data, nil
+	}
+	return stampRecordVersion(data, envelope.Type, spec.Version)
+}
+
+// StampRecordLine prepares an existing JSONL line for a rewrite. A line
+// written before versioning gets an explicit "v":1, the version it reads
+// as; versioned lines and unknown types are returned unchanged.
+func StampRecordLine(line []byte) ([]byte, error) {
+	var envelope struct {
+		Type string          `json:"type"`
+		V    json.RawMessage `json:"v"`
+	}
+	if err := json.Unmarshal(line, &envelope); err != nil {
+		return nil, err
+	}
+	if _, ok := recordsByType[envelope.Type]; !ok || envelope.V != nil {
+		return line, nil
+	}
+	return stampRecordVersion(line, envelope.Type, 1)
+}
+
+// stampRecordVersion inserts a "v" field into data, right after "type" when
+// that is the first field.
+func stampRecordVersion(data []byte, recordType string, version int) ([]byte, error) {
+	typeJSON, err := json.Marshal(recordType)
+	if err != nil {
+		return nil, err
+	}
+	prefix := append([]byte(`{"type":`), typeJSON...)
+	stamp := []byte(fmt.Sprintf(`,"v":%d`, version))
+	if bytes.HasPrefix(data, prefix) {
+		return append(append(append([]byte{}, prefix...), stamp...), data[len(prefix):]...), nil
+	}
+	return append(append([]byte(`{`), stamp[1:]...), append([]byte(","), data[1:]...)...), nil
+}
+
+// unknownRecordLog receives the one-time notice for unknown record types.
+var unknownRecordLog io.Writer = os.Stderr
+
+var unknownRecordTypes sync.Map
+
+// noteUnknownRecordType is called by readers for record types they do not
+// handle. Types missing from the catalogue (written by a newer fray) are
+// reported once per process instead of being dropped silently.
+func noteUnknownRecordType(recordType string) {
+	if _, ok := recordsByType[recordType]; ok {
+		return
+	}
+	if _, seen := unknownRecordTypes.LoadOrStore(recordType, true); seen {
+		return
+	}
+	fmt.Fprintf(unknownRecordLog, "fray: skipping unknown JSONL record type %q (written by a newer fray?)\n", recordType)
+}
+
+// RecordField describes one field of a record type.
+type RecordField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Items    string `json:"items,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Fields describes the record's JSON fields, derived from its struct: the
+// "type" and "v" envelope first, then the struct fields in order.
+func (s RecordSpec) Fields() []RecordField {
+	fields := []RecordField{
+		{Name: "type", Type: "string"},
+		{Name: "v", Type: "integer", Optional: true},
+	}
+	t := reflect.TypeOf(s.record)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || name == "type" {
+			continue
+		}
+		spec := RecordField{
+			Name:     name,
+			Optional: strings.Contains(opts, "omitempty") || field.Type.Kind() == reflect.Pointer,
+		}
+		spec.Type, spec.Items = jsonSchemaType(field.Type)
+		fields = append(fields, spec)
+	}
+	return fields
+}
+
+// jsonSchemaType maps a Go type to a JSON-schema-style type name, plus the
+// element type for arrays.
+func jsonSchemaType(t reflect.Type) (string, string) {
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return "any", ""
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string", ""
+	case reflect.Bool:
+		return "boolean", ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer", ""
+	case reflect.Float32, reflect.Float64:
+		return "number", ""
+	case reflect.Slice, reflect.Array:
+		items, _ := jsonSchemaType(t.Elem())
+		return "array", items
+	case reflect.Map, reflect.Struct:
+		return "object", ""
+	}
+	return "any", ""
+}
@@ -0,0 +1,251 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/testutil"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestAppendHelpersStampRecordVersion(t *testing.T) {
+	projectDir := testutil.NewProject(t).Root
+
+	appends := []struct {
+		name string
+		fn   func() error
+	}{
+		{"message", func() error {
+			return AppendMessage(projectDir, types.Message{ID: "msg-a", TS: 1, FromAgent: "alice", Body: "hi", Type: types.MessageTypeAgent})
+		}},
+		{"message_update", func() error {
+			return AppendMessageUpdate(projectDir, MessageUpdateJSONLRecord{ID: "msg-a"})
+		}},
+		{"message_move", func() error {
+			return AppendMessageMove(projectDir, MessageMoveJSONLRecord{MessageGUID: "msg-a", NewHome: "thrd-a"})
+		}},
+		{"message_pin", func() error { return AppendMessagePin(projectDir, MessagePinJSONLRecord{MessageGUID: "msg-a"}) }},
+		{"message_unpin", func() error { return AppendMessageUnpin(projectDir, MessageUnpinJSONLRecord{MessageGUID: "msg-a"}) }},
+		{"announcement", func() error {
+			return AppendAnnouncement(projectDir, types.Announcement{MessageGUID: "msg-a", FromAgent: "alice", AckRequired: true, CreatedAt: 1})
+		}},
+		{"announcement_ack", func() error {
+			return AppendAnnouncementAck(projectDir, types.AnnouncementAck{MessageGUID: "msg-a", AgentID: "bob", Source: AckSourceCommand, AckedAt: 2})
+		}},
+		{"announcement_reminder", func() error {
+			return AppendAnnouncementReminder(projectDir, AnnouncementReminderJSONLRecord{MessageGUID: "msg-a", Agents: []string{"carol"}, RemindedAt: 3})
+		}},
+		{"reaction", func() error { return AppendReaction(projectDir, "msg-a", "bob", "👍", 2) }},
+		{"reaction_remove", func() error { return AppendReactionRemove(projectDir, "msg-a", "bob", "👍", 3) }},
+		{"agent", func() error {
+			return AppendAgent(projectDir, types.Agent{GUID: "usr-a", AgentID: "alice", RegisteredAt: 1, LastSeen: 1})
+		}},
+		{"agent_update", func() error { return AppendAgentUpdate(projectDir, AgentUpdateJSONLRecord{AgentID: "alice"}) }},
+		{"session_start", func() error {
+			return AppendSessionStart(projectDir, types.SessionStart{AgentID: "alice", SessionID: "s1", StartedAt: 1})
+		}},
+		{"session_end", func() error {
+			return AppendSessionEnd(projectDir, types.SessionEnd{AgentID: "alice", SessionID: "s1", EndedAt: 2})
+		}},
+		{"session_response", func() error {
+			return AppendSessionResponse(projectDir, types.SessionResponse{AgentID: "alice", SessionID: "s1", MessageGUID: "msg-b", RespondingTo: "msg-a", LatencyMs: 4000, RespondedAt: 5})
+		}},
+		{"session_done", func() error {
+			return AppendSessionDone(projectDir, types.SessionDone{AgentID: "alice", MessageID: "msg-a", At: 2})
+		}},
+		{"session_heartbeat", func() error {
+			return AppendSessionHeartbeat(projectDir, types.SessionHeartbeat{AgentID: "alice", SessionID: "s1", At: 2})
+		}},
+		{"token_usage", func() error { return AppendTokenUsage(projectDir, types.TokenUsage{AgentID: "alice", RecordedAt: 2}) }},
+		{"ghost_cursor", func() error {
+			return AppendGhostCursor(projectDir, types.GhostCursor{AgentID: "alice", Home: "room", MessageGUID: "msg-a", SetAt: 2})
+		}},
+		{"agent_fave", func() error { return AppendAgentFave(projectDir, "alice", "message", "msg-a", 2) }},
+		{"agent_unfave", func() error { return AppendAgentUnfave(projectDir, "alice", "message", "msg-a", 3) }},
+		{"claim", func() error {
+			return AppendClaim(projectDir, types.Claim{AgentID: "alice", ClaimType: types.ClaimTypeFile, Pattern: "a.go", CreatedAt: 1}, nil)
+		}},
+		{"claim_clear", func() error {
+			return AppendClaimClear(projectDir, types.Claim{AgentID: "alice", ClaimType: types.ClaimTypeFile, Pattern: "a.go"}, 2)
+		}},
+		{"claim_request", func() error {
+			return AppendClaimRequest(projectDir, types.ClaimRequest{GUID: "creq-a", RequestedBy: "bob", Holder: "alice", ClaimType: types.ClaimTypeFile, Pattern: "a.go", MessageGUID: "msg-a", RequestedAt: 3})
+		}},
+		{"role_hold", func() error { return AppendRoleHold(projectDir, "alice", "reviewer", 1) }},
+		{"role_drop", func() error { return AppendRoleDrop(projectDir, "alice", "reviewer", 2) }},
+		{"role_play", func() error { return AppendRolePlay(projectDir, "alice", "reviewer", nil, 1) }},
+		{"role_stop", func() error { return AppendRoleStop(projectDir, "alice", "reviewer", 2) }},
+		{"question", func() error {
+			return AppendQuestion(projectDir, types.Question{GUID: "qstn-a", Re: "why?", FromAgent: "alice", Status: types.QuestionStatusOpen, CreatedAt: 1})
+		}},
+		{"question_update", func() error { return AppendQuestionUpdate(projectDir, QuestionUpdateJSONLRecord{GUID: "qstn-a"}) }},
+		{"thread", func() error {
+			return AppendThread(projectDir, types.Thread{GUID: "thrd-a", Name: "design", Status: types.ThreadStatusOpen, CreatedAt: 1}, nil)
+		}},
+		{"thread_update", func() error { return AppendThreadUpdate(projectDir, ThreadUpdateJSONLRecord{GUID: "thrd-a"}) }},
+		{"thread_subscribe", func() error {
+			return AppendThreadSubscribe(projectDir, ThreadSubscribeJSONLRecord{ThreadGUID: "thrd-a", AgentID: "alice"})
+		}},
+		{"thread_unsubscribe", func() error {
+			return AppendThreadUnsubscribe(projectDir, ThreadUnsubscribeJSONLRecord{ThreadGUID: "thrd-a", AgentID: "alice"})
+		}},
+		{"thread_message", func() error {
+			return AppendThreadMessage(projectDir, ThreadMessageJSONLRecord{ThreadGUID: "thrd-a", MessageGUID: "msg-a"})
+		}},
+		{"thread_message_remove", func() error {
+			return AppendThreadMessageRemove(projectDir, ThreadMessageRemoveJSONLRecord{ThreadGUID: "thrd-a", MessageGUID: "msg-a"})
+		}},
+		{"thread_pin", func() error { return AppendThreadPin(projectDir, ThreadPinJSONLRecord{ThreadGUID: "thrd-a"}) }},
+		{"thread_unpin", func() error { return AppendThreadUnpin(projectDir, ThreadUnpinJSONLRecord{ThreadGUID: "thrd-a"}) }},
+		{"thread_mute", func() error {
+			return AppendThreadMute(projectDir, ThreadMuteJSONLRecord{ThreadGUID: "thrd-a", AgentID: "alice"})
+		}},
+		{"thread_unmute", func() error {
+			return AppendThreadUnmute(projectDir, ThreadUnmuteJSONLRecord{ThreadGUID: "thrd-a", AgentID: "alice"})
+		}},
+		{"client_pref", func() error {
+			return AppendClientPref(projectDir, types.ClientPref{ThreadGUID: "thrd-a", Key: "color", Value: "teal", SetBy: "alice", SetAt: 2})
+		}},
+		{AuditRecordEntry, func() error {
+			return AppendAuditEntry(projectDir, AuditRecord{Actor: "adam", Command: "prune", RecordedAt: 1})
+		}},
+		{UndoRecordEntry, func() error {
+			_, err := AppendUndoEntry(projectDir, UndoRecord{Command: "fray edit", RecordedAt: 1})
+			return err
+		}},
+		{UndoRecordApplied, func() error { return AppendUndoApplied(projectDir, "undo-a", 2) }},
+		{UndoRecordBarrier, func() error { return AppendUndoBarrier(projectDir, "fray prune", 3) }},
+	}
+	for _, a := range appends {
+		if err := a.fn(); err != nil {
+			t.Fatalf("append %s: %v", a.name, err)
+		}
+	}
+
+	written := map[string]bool{}
+	for _, file := range []string{messagesFile, agentsFile, questionsFile, threadsFile, auditFile, undoLogFile} {
+		for _, line := range readLines(t, filepath.Join(projectDir, ".fray", file)) {
+			var envelope struct {
+				Type string `json:"type"`
+				V    int    `json:"v"`
+			}
+			if err := json.Unmarshal(line, &envelope); err != nil {
+				t.Fatalf("%s: bad line %s: %v", file, line, err)
+			}
+			lineSpec, ok := LookupRecordSpec(envelope.Type)
+			if !ok {
+				t.Fatalf("%s: record type %q is missing from the catalogue", file, envelope.Type)
+			}
+			if lineSpec.File != file {
+				t.Fatalf("record type %q written to %s, catalogue says %s", envelope.Type, file, lineSpec.File)
+			}
+			if envelope.V != lineSpec.Version {
+				t.Fatalf("record type %q stamped v=%d, want %d: %s", envelope.Type, envelope.V, lineSpec.Version, line)
+			}
+			if !bytes.HasPrefix(line, []byte(`{"type":"`+envelope.Type+`","v":`)) {
+				t.Fatalf("expected v right after type, got %s", line)
+			}
+			written[envelope.Type] = true
+		}
+	}
+	for _, spec := range RecordSpecs() {
+		if !written[spec.Type] {
+			t.Fatalf("no append path exercised record type %q", spec.Type)
+		}
+	}
+}
+
+func TestReadersTolerateUnknownRecords(t *testing.T) {
+	project := testutil.NewProject(t)
+	projectDir := project.Root
+	project.WriteJSONL(t, messagesFile,
+		`{"type":"message","v":2,"id":"msg-a","ts":1,"from_agent":"alice","body":"hi","mentions":[],"future_field":{"nested":true}}`,
+		`{"type":"hologram","v":1,"id":"holo-a"}`,
+		`{"type":"hologram","v":1,"id":"holo-b"}`,
+		`{"type":"message","id":"msg-b","ts":2,"from_agent":"bob","body":"unversioned","mentions":[]}`,
+	)
+
+	var logged bytes.Buffer
+	prev := unknownRecordLog
+	unknownRecordLog = &logged
+	t.Cleanup(func() { unknownRecordLog = prev })
+
+	messages, err := ReadMessages(projectDir)
+	if err != nil {
+		t.Fatalf("read messages: %v", err)
+	}
+	if len(messages) != 2 || messages[0].ID != "msg-a" || messages[1].ID != "msg-b" {
+		t.Fatalf("expected both messages to survive unknown records, got %+v", messages)
+	}
+	if _, err := ReadMessages(projectDir); err != nil {
+		t.Fatalf("second read: %v", err)
+	}
+	if got := strings.Count(logged.String(), `"hologram"`); got != 1 {
+		t.Fatalf("expected one notice for the unknown type, got %d: %q", got, logged.String())
+	}
+}
+
+func TestDecodeRecordDefaultsVersion(t *testing.T) {
+	spec, version, record, err := DecodeRecord([]byte(`{"type":"thread_pin","thread_guid":"thrd-a","pinned_by":"alice","pinned_at":5,"later":1}`))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if spec.File != threadsFile || version != 1 {
+		t.Fatalf("expected threads.jsonl v1, got %s v%d", spec.File, version)
+	}
+	pin, ok := record.(*ThreadPinJSONLRecord)
+	if !ok || pin.ThreadGUID != "thrd-a" {
+		t.Fatalf("expected thread pin record, got %#v", record)
+	}
+
+	if _, _, _, err := DecodeRecord([]byte(`{"type":"hologram"}`)); err == nil {
+		t.Fatalf("expected unknown type to fail decoding")
+	}
+}
+
+func TestStampRecordLine(t *testing.T) {
+	cases := map[string]string{
+		`{"type":"thread_pin","thread_guid":"thrd-a"}`:       `{"type":"thread_pin","v":1,"thread_guid":"thrd-a"}`,
+		`{"thread_guid":"thrd-a","type":"thread_pin"}`:       `{"v":1,"thread_guid":"thrd-a","type":"thread_pin"}`,
+		`{"type":"thread_pin","v":3,"thread_guid":"thrd-a"}`: `{"type":"thread_pin","v":3,"thread_guid":"thrd-a"}`,
+		`{"type":"hologram","id":"holo-a"}`:                  `{"type":"hologram","id":"holo-a"}`,
+	}
+	for line, want := range cases {
+		got, err := StampRecordLine([]byte(line))
+		if err != nil {
+			t.Fatalf("stamp %s: %v", line, err)
+		}
+		if string(got) != want {
+			t.Fatalf("stamp %s: expected %s, got %s", line, want, got)
+		}
+	}
+	if _, err := StampRecordLine([]byte("not json")); err == nil {
+		t.Fatalf("expected a malformed line to fail")
+	}
+}
+
+func readLines(t *testing.T, path string) [][]byte {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			lines = append(lines, append([]byte(nil), line...))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scan %s: %v", path, err)
+	}
+	return lines
+}
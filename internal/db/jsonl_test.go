@@ -2,15 +2,17 @@ package db
 
 import (
 	"encoding/json"
-	"os"
-	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/adamavenir/fray/internal/testutil"
 	"github.com/adamavenir/fray/internal/types"
 )
 
 func TestAppendAndReadMessages(t *testing.T) {
-	projectDir := t.TempDir()
+	projectDir := testutil.NewProject(t).Root
 
 	message := types.Message{
 		ID:        "msg-abc12345",
@@ -45,7 +47,7 @@ func TestAppendAndReadMessages(t *testing.T) {
 }
 
 func TestGetMessageVersions(t *testing.T) {
-	projectDir := t.TempDir()
+	projectDir := testutil.NewProject(t).Root
 
 	message := types.Message{
 		ID:        "msg-abc12345",
@@ -123,7 +125,7 @@ func TestGetMessageVersions(t *testing.T) {
 }
 
 func TestApplyMessageEditCounts(t *testing.T) {
-	projectDir := t.TempDir()
+	projectDir := testutil.NewProject(t).Root
 
 	msg1 := types.Message{
 		ID:        "msg-aaa11111",
@@ -159,7 +161,7 @@ func TestApplyMessageEditCounts(t *testing.T) {
 		t.Fatalf("append edit update: %v", err)
 	}
 
-	reactions := map[string][]string{":+1:": {"alice"}}
+	reactions := ReactionSet{":+1:": {{AgentID: "alice", ReactedAt: 250}}}
 	if err := AppendMessageUpdate(projectDir, MessageUpdateJSONLRecord{
 		ID:        msg2.ID,
 		Reactions: &reactions,
@@ -180,7 +182,7 @@ func TestApplyMessageEditCounts(t *testing.T) {
 }
 
 func TestAppendAndReadAgents(t *testing.T) {
-	projectDir := t.TempDir()
+	projectDir := testutil.NewProject(t).Root
 
 	agent := types.Agent{
 		GUID:         "usr-abc12345",
@@ -212,11 +214,8 @@ func TestAppendAndReadAgents(t *testing.T) {
 }
 
 func TestReadAgentsFiltersAndAppliesUpdates(t *testing.T) {
-	projectDir := t.TempDir()
-	frayDir := filepath.Join(projectDir, ".fray")
-	if err := os.MkdirAll(frayDir, 0o755); err != nil {
-		t.Fatalf("mkdir: %v", err)
-	}
+	project := testutil.NewProject(t)
+	projectDir := project.Root
 
 	// Write mixed record types to agents.jsonl
 	agentRecord := `{"type":"agent","id":"usr-abc12345","agent_id":"alice","name":"alice","registered_at":100,"last_seen":100,"managed":false,"presence":"offline"}`
@@ -225,10 +224,7 @@ func TestReadAgentsFiltersAndAppliesUpdates(t *testing.T) {
 	sessionEnd := `{"type":"session_end","agent_id":"alice","session_id":"sess-123","exit_code":0,"duration_ms":1000,"ended_at":1200}`
 	sessionHeartbeat := `{"type":"session_heartbeat","agent_id":"alice","session_id":"sess-123","status":"active","at":500}`
 
-	content := agentRecord + "\n" + agentUpdate + "\n" + sessionStart + "\n" + sessionEnd + "\n" + sessionHeartbeat + "\n"
-	if err := os.WriteFile(filepath.Join(frayDir, agentsFile), []byte(content), 0o644); err != nil {
-		t.Fatalf("write: %v", err)
-	}
+	project.WriteJSONL(t, agentsFile, agentRecord, agentUpdate, sessionStart, sessionEnd, sessionHeartbeat)
 
 	readBack, err := ReadAgents(projectDir)
 	if err != nil {
@@ -253,17 +249,11 @@ func TestReadAgentsFiltersAndAppliesUpdates(t *testing.T) {
 }
 
 func TestReadAgentsUpdateDoesNotCreateNewAgent(t *testing.T) {
-	projectDir := t.TempDir()
-	frayDir := filepath.Join(projectDir, ".fray")
-	if err := os.MkdirAll(frayDir, 0o755); err != nil {
-		t.Fatalf("mkdir: %v", err)
-	}
+	project := testutil.NewProject(t)
+	projectDir := project.Root
 
 	// Write an agent_update for a non-existent agent
-	agentUpdate := `{"type":"agent_update","agent_id":"ghost","status":"working"}`
-	if err := os.WriteFile(filepath.Join(frayDir, agentsFile), []byte(agentUpdate+"\n"), 0o644); err != nil {
-		t.Fatalf("write: %v", err)
-	}
+	project.WriteJSONL(t, agentsFile, `{"type":"agent_update","agent_id":"ghost","status":"working"}`)
 
 	readBack, err := ReadAgents(projectDir)
 	if err != nil {
@@ -277,7 +267,7 @@ func TestReadAgentsUpdateDoesNotCreateNewAgent(t *testing.T) {
 }
 
 func TestRebuildPreservesManagedAgentFields(t *testing.T) {
-	projectDir := t.TempDir()
+	projectDir := testutil.NewProject(t).Root
 
 	invokeConfig := &types.InvokeConfig{
 		Driver:         "claude",
@@ -338,7 +328,7 @@ func TestRebuildPreservesManagedAgentFields(t *testing.T) {
 }
 
 func TestUpdateProjectConfigMergesKnownAgents(t *testing.T) {
-	projectDir := t.TempDir()
+	projectDir := testutil.NewProject(t).Root
 
 	if _, err := UpdateProjectConfig(projectDir, ProjectConfig{
 		ChannelID:   "ch-11111111",
@@ -381,19 +371,13 @@ func TestUpdateProjectConfigMergesKnownAgents(t *testing.T) {
 }
 
 func TestReadMessagesSkipsMalformedLines(t *testing.T) {
-	projectDir := t.TempDir()
-	frayDir := filepath.Join(projectDir, ".fray")
-	if err := os.MkdirAll(frayDir, 0o755); err != nil {
-		t.Fatalf("mkdir: %v", err)
-	}
-	path := filepath.Join(frayDir, messagesFile)
-
-	good1, _ := json.Marshal(map[string]any{"type": "message", "id": "msg-good1", "mentions": []string{}})
-	good2, _ := json.Marshal(map[string]any{"type": "message", "id": "msg-good2", "mentions": []string{}})
-	contents := string(good1) + "\n" + "not-json\n" + string(good2) + "\n"
-	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
-		t.Fatalf("write: %v", err)
-	}
+	project := testutil.NewProject(t)
+	projectDir := project.Root
+	project.WriteJSONL(t, messagesFile,
+		map[string]any{"type": "message", "id": "msg-good1", "mentions": []string{}},
+		"not-json",
+		map[string]any{"type": "message", "id": "msg-good2", "mentions": []string{}},
+	)
 
 	readBack, err := ReadMessages(projectDir)
 	if err != nil {
@@ -408,7 +392,7 @@ func TestReadMessagesSkipsMalformedLines(t *testing.T) {
 }
 
 func TestAppendAndReadQuestions(t *testing.T) {
-	projectDir := t.TempDir()
+	projectDir := testutil.NewProject(t).Root
 
 	question := types.Question{
 		GUID:      "qstn-abc12345",
@@ -448,7 +432,7 @@ func TestAppendAndReadQuestions(t *testing.T) {
 }
 
 func TestReadThreadsEvents(t *testing.T) {
-	projectDir := t.TempDir()
+	projectDir := testutil.NewProject(t).Root
 
 	thread := types.Thread{
 		GUID:      "thrd-abc12345",
@@ -505,7 +489,7 @@ func TestReadThreadsEvents(t *testing.T) {
 }
 
 func TestRebuildDatabaseFromJSONL(t *testing.T) {
-	projectDir := t.TempDir()
+	projectDir := testutil.NewProject(t).Root
 
 	thread := types.Thread{
 		GUID:      "thrd-abc12345",
@@ -644,3 +628,210 @@ func TestRebuildDatabaseFromJSONL(t *testing.T) {
 		t.Fatalf("expected bob unsubscribed after rebuild")
 	}
 }
+
+func TestReadReactionsAppliesRemovals(t *testing.T) {
+	projectDir := testutil.NewProject(t).Root
+
+	if err := AppendReaction(projectDir, "msg-abc12345", "alice", "👍", 100); err != nil {
+		t.Fatalf("append reaction: %v", err)
+	}
+	if err := AppendReaction(projectDir, "msg-abc12345", "alice", "👍", 200); err != nil {
+		t.Fatalf("append reaction: %v", err)
+	}
+	if err := AppendReactionRemove(projectDir, "msg-abc12345", "alice", "👍", 200); err != nil {
+		t.Fatalf("append reaction remove: %v", err)
+	}
+
+	reactions, err := ReadReactions(projectDir)
+	if err != nil {
+		t.Fatalf("read reactions: %v", err)
+	}
+	if len(reactions) != 1 || reactions[0].ReactedAt != 100 {
+		t.Fatalf("expected only the first reaction to survive, got %+v", reactions)
+	}
+}
+
+func TestRebuildIngestsLegacyInlineReactions(t *testing.T) {
+	project := testutil.NewProject(t)
+	projectDir := project.Root
+	project.WriteJSONL(t, messagesFile,
+		`{"type":"message","id":"msg-old11111","from_agent":"alice","body":"old","mentions":[],"reactions":{"👍":["bob","bob"]},"message_type":"agent","reply_to":null,"ts":100,"edited_at":null,"archived_at":null}`,
+		`{"type":"message","id":"msg-old22222","from_agent":"alice","body":"older","mentions":[],"message_type":"agent","reply_to":null,"ts":50,"edited_at":null,"archived_at":null}`,
+		`{"type":"message_update","id":"msg-old22222","reactions":{"🎉":["carol"]}}`,
+		`{"type":"reaction","message_guid":"msg-old11111","agent_id":"carol","emoji":"🔥","reacted_at":300}`,
+		`{"type":"reaction","message_guid":"msg-old11111","agent_id":"bob","emoji":"👍","reacted_at":150}`,
+	)
+
+	dbConn := openTestDB(t)
+	if err := RebuildDatabaseFromJSONL(dbConn, projectDir); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+
+	reactions, err := GetReactionsForMessage(dbConn, "msg-old11111")
+	if err != nil {
+		t.Fatalf("get reactions: %v", err)
+	}
+	if got := reactions["👍"]; len(got) != 1 || got[0].AgentID != "bob" || got[0].ReactedAt != 150 {
+		t.Fatalf("expected explicit record to win over inline reaction, got %+v", got)
+	}
+
+	older, err := GetReactionsForMessage(dbConn, "msg-old22222")
+	if err != nil {
+		t.Fatalf("get reactions: %v", err)
+	}
+	if got := older["🎉"]; len(got) != 1 || got[0].AgentID != "carol" || got[0].ReactedAt != 50 {
+		t.Fatalf("expected legacy update reaction dated to its message, got %+v", got)
+	}
+
+	// Most recent reaction first, including the ingested legacy one.
+	recent, err := GetMessagesReactedToByAgent(dbConn, "carol", 10)
+	if err != nil {
+		t.Fatalf("reacted to: %v", err)
+	}
+	if len(recent) != 2 || recent[0].Emoji != "🔥" || recent[1].Emoji != "🎉" {
+		t.Fatalf("expected reactions ranked by recency, got %+v", recent)
+	}
+}
+
+func TestReactionSetRoundTrip(t *testing.T) {
+	original := MessageUpdateJSONLRecord{
+		Type:      "message_update",
+		ID:        "msg-abc12345",
+		Reactions: &ReactionSet{"👍": {{AgentID: "alice", ReactedAt: 100}, {AgentID: "bob", ReactedAt: 200}}},
+	}
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"reacted_at":200`) {
+		t.Fatalf("expected timestamps in JSONL, got %s", data)
+	}
+
+	var decoded MessageUpdateJSONLRecord
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(*decoded.Reactions, *original.Reactions) {
+		t.Fatalf("round trip mismatch: got %+v", *decoded.Reactions)
+	}
+
+	var mixed ReactionSet
+	if err := json.Unmarshal([]byte(`{"👀":["alice",{"agent_id":"bob","reacted_at":5}]," ":["x"]}`), &mixed); err != nil {
+		t.Fatalf("unmarshal mixed: %v", err)
+	}
+	want := ReactionSet{"👀": {{AgentID: "alice"}, {AgentID: "bob", ReactedAt: 5}}}
+	if !reflect.DeepEqual(mixed, want) {
+		t.Fatalf("expected %+v, got %+v", want, mixed)
+	}
+}
+
+func TestReadPendingUndoOrderingAndBarrier(t *testing.T) {
+	projectDir := testutil.NewProject(t).Root
+
+	first, err := AppendUndoEntry(projectDir, UndoRecord{Command: "pin", Inverse: [][]string{{"unpin", "msg-a"}}})
+	if err != nil {
+		t.Fatalf("append undo: %v", err)
+	}
+	second, err := AppendUndoEntry(projectDir, UndoRecord{Command: "mv", Inverse: [][]string{{"mv", "msg-a", "room"}}})
+	if err != nil {
+		t.Fatalf("append undo: %v", err)
+	}
+
+	pending, err := ReadPendingUndo(projectDir)
+	if err != nil {
+		t.Fatalf("read undo: %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != second.ID || pending[1].ID != first.ID {
+		t.Fatalf("expected newest first, got %+v", pending)
+	}
+
+	if err := AppendUndoApplied(projectDir, second.ID, 0); err != nil {
+		t.Fatalf("append applied: %v", err)
+	}
+	pending, _ = ReadPendingUndo(projectDir)
+	if len(pending) != 1 || pending[0].ID != first.ID {
+		t.Fatalf("expected applied entry to be skipped, got %+v", pending)
+	}
+
+	if err := AppendUndoBarrier(projectDir, "prune", 0); err != nil {
+		t.Fatalf("append barrier: %v", err)
+	}
+	pending, _ = ReadPendingUndo(projectDir)
+	if len(pending) != 0 {
+		t.Fatalf("expected barrier to discard earlier entries, got %+v", pending)
+	}
+}
+
+func TestSubscriptionLevelPersistsAndRebuilds(t *testing.T) {
+	projectDir := testutil.NewProject(t).Root
+	thread := types.Thread{GUID: "thrd-lvl12345", Name: "design", Status: types.ThreadStatusOpen, CreatedAt: 10}
+	if err := AppendThread(projectDir, thread, nil); err != nil {
+		t.Fatalf("append thread: %v", err)
+	}
+	for _, record := range []ThreadSubscribeJSONLRecord{
+		{ThreadGUID: thread.GUID, AgentID: "dev", SubscribedAt: 11, Level: types.SubscriptionMentions},
+		{ThreadGUID: thread.GUID, AgentID: "dev", SubscribedAt: 12}, // auto-subscribe on post
+		{ThreadGUID: thread.GUID, AgentID: "pm", SubscribedAt: 13},
+	} {
+		if err := AppendThreadSubscribe(projectDir, record); err != nil {
+			t.Fatalf("append subscribe: %v", err)
+		}
+	}
+
+	dbConn := openTestDB(t)
+	if err := RebuildDatabaseFromJSONL(dbConn, projectDir); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+
+	dev, err := GetThreadSubscription(dbConn, thread.GUID, "dev")
+	if err != nil || dev == nil || dev.Level != types.SubscriptionMentions || dev.SubscribedAt != 12 {
+		t.Fatalf("expected dev to keep mentions level, got %+v (%v)", dev, err)
+	}
+	pm, err := GetThreadSubscription(dbConn, thread.GUID, "pm")
+	if err != nil || pm == nil || pm.Level != types.SubscriptionAll {
+		t.Fatalf("expected pm at default level, got %+v (%v)", pm, err)
+	}
+
+	// Live subscribes follow the same rule.
+	if err := SubscribeThread(dbConn, thread.GUID, "dev", 20); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if dev, _ := GetThreadSubscription(dbConn, thread.GUID, "dev"); dev.Level != types.SubscriptionMentions {
+		t.Fatalf("expected plain subscribe to keep level, got %s", dev.Level)
+	}
+	if err := SubscribeThreadLevel(dbConn, thread.GUID, "dev", types.SubscriptionDigest, 21); err != nil {
+		t.Fatalf("subscribe level: %v", err)
+	}
+	if dev, _ := GetThreadSubscription(dbConn, thread.GUID, "dev"); dev.Level != types.SubscriptionDigest {
+		t.Fatalf("expected digest level, got %s", dev.Level)
+	}
+	if err := SubscribeThreadLevel(dbConn, thread.GUID, "dev", "loud", 22); err == nil {
+		t.Fatalf("expected invalid level to be rejected")
+	}
+}
+
+func TestAppendWaitsForJSONLRewrite(t *testing.T) {
+	projectDir := testutil.NewProject(t).Root
+
+	release, err := LockJSONL(projectDir)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- AppendMessage(projectDir, types.Message{ID: "msg-abc12345", TS: 1, FromAgent: "alice", Body: "hi", Type: types.MessageTypeAgent})
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected append to wait for the rewrite, got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if messages, err := ReadMessages(projectDir); err != nil || len(messages) != 1 {
+		t.Fatalf("expected the append to land after release, got %d (%v)", len(messages), err)
+	}
+}
@@ -11,9 +11,20 @@ import (
 
 // OpenDatabase opens the SQLite database for a project.
 func OpenDatabase(project core.Project) (*sql.DB, error) {
+	return OpenDatabaseWithOptions(project, RebuildOptions{})
+}
+
+// OpenDatabaseWithOptions opens the database, passing opts to the rebuild
+// that runs when JSONL is newer than the cache. It first waits out any
+// rebuild another process is running.
+func OpenDatabaseWithOptions(project core.Project, opts RebuildOptions) (*sql.DB, error) {
 	frayDir := filepath.Dir(project.DBPath)
 	core.EnsureFrayGitignore(frayDir)
 
+	if err := WaitForRebuild(project.DBPath); err != nil {
+		return nil, err
+	}
+
 	dbExists := true
 	if _, err := os.Stat(project.DBPath); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -35,26 +46,17 @@ func OpenDatabase(project core.Project) (*sql.DB, error) {
 
 	shouldRebuild := jsonlMtime > 0 && (!dbExists || jsonlMtime > dbMtime)
 
-	conn, err := sql.Open("sqlite", project.DBPath)
+	conn, err := sql.Open("sqlite", sqliteDSN(project.DBPath))
 	if err != nil {
 		return nil, err
 	}
-
-	if _, err := conn.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		_ = conn.Close()
-		return nil, err
-	}
-	if _, err := conn.Exec("PRAGMA journal_mode = WAL"); err != nil {
-		_ = conn.Close()
-		return nil, err
-	}
-	if _, err := conn.Exec("PRAGMA busy_timeout = 5000"); err != nil {
+	if err := conn.Ping(); err != nil {
 		_ = conn.Close()
 		return nil, err
 	}
 
 	if shouldRebuild {
-		if err := RebuildDatabaseFromJSONL(conn, project.DBPath); err != nil {
+		if err := RebuildDatabase(conn, project.DBPath, opts); err != nil {
 			_ = conn.Close()
 			return nil, err
 		}
@@ -63,8 +65,15 @@ func OpenDatabase(project core.Project) (*sql.DB, error) {
 	return conn, nil
 }
 
+// sqliteDSN builds a DSN whose pragmas apply to every pooled connection.
+// Setting them with Exec only configures whichever connection ran it, so
+// concurrent writers on other connections would fail fast with SQLITE_BUSY.
+func sqliteDSN(path string) string {
+	return path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+}
+
 func getJSONLMtime(frayDir string) int64 {
-	files := []string{"messages.jsonl", "agents.jsonl", "questions.jsonl", "threads.jsonl"}
+	files := []string{"messages.jsonl", "agents.jsonl", "questions.jsonl", "threads.jsonl", filepath.Join(localDir, privateFile)}
 	latest := int64(0)
 	for _, name := range files {
 		path := filepath.Join(frayDir, name)
@@ -0,0 +1,428 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PrivateRecordsConfigKey lists the record classes kept out of the shared
+// JSONL files, e.g. "faves,subscriptions". It is read from the local and
+// global config layers only, since it decides where this machine writes.
+const PrivateRecordsConfigKey = "private_records"
+
+// privateFile holds this machine's private records, next to the undo log.
+const privateFile = "private.jsonl"
+
+// PrivateRecordClass is a group of record types that may be kept private.
+type PrivateRecordClass struct {
+	Name        string
+	Description string
+	// Types are the record types the class routes.
+	Types []string
+}
+
+// privateRecordClasses lists the classes private_records accepts.
+var privateRecordClasses = []PrivateRecordClass{
+	{"faves", "Faved and unfaved threads and messages", []string{"agent_fave", "agent_unfave"}},
+	{"subscriptions", "Thread subscribes and unsubscribes (creation-time subscribers stay shared)", []string{"thread_subscribe", "thread_unsubscribe"}},
+	{"read_markers", "Handoff read positions (ghost cursors); per-agent read_to markers live in the cache only and are never synced", []string{"ghost_cursor"}},
+}
+
+// PrivateRecordClasses returns the classes private_records accepts.
+func PrivateRecordClasses() []PrivateRecordClass {
+	return append([]PrivateRecordClass(nil), privateRecordClasses...)
+}
+
+// ParsePrivateRecords validates a comma-separated private_records value.
+func ParsePrivateRecords(value string) (map[string]bool, error) {
+	classes := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		name := strings.TrimSpace(part)
+		if name == "" {
+			continue
+		}
+		if lookupPrivateRecordClass(name) == nil {
+			names := make([]string, 0, len(privateRecordClasses))
+			for _, class := range privateRecordClasses {
+				names = append(names, class.Name)
+			}
+			return nil, fmt.Errorf("unknown record class %q (use %s)", name, strings.Join(names, ", "))
+		}
+		classes[name] = true
+	}
+	return classes, nil
+}
+
+func lookupPrivateRecordClass(name string) *PrivateRecordClass {
+	for i := range privateRecordClasses {
+		if privateRecordClasses[i].Name == name {
+			return &privateRecordClasses[i]
+		}
+	}
+	return nil
+}
+
+// PrivateRecords returns the classes this machine keeps private. Unknown
+// class names are ignored so a typo never blocks writes.
+func PrivateRecords(projectPath string) map[string]bool {
+	classes := map[string]bool{}
+	for _, scope := range []ConfigScope{ConfigScopeLocal, ConfigScopeGlobal} {
+		values, err := GetConfigLayer(nil, projectPath, scope)
+		if err != nil {
+			continue
+		}
+		value, ok := values[PrivateRecordsConfigKey]
+		if !ok {
+			continue
+		}
+		for _, part := range strings.Split(value, ",") {
+			if name := strings.TrimSpace(part); lookupPrivateRecordClass(name) != nil {
+				classes[name] = true
+			}
+		}
+		return classes
+	}
+	return classes
+}
+
+func privateRecordsPath(frayDir string) string {
+	return filepath.Join(frayDir, localDir, privateFile)
+}
+
+// recordTypeClass returns the private record class of a record type, or "".
+func recordTypeClass(recordType string) string {
+	for _, class := range privateRecordClasses {
+		for _, t := range class.Types {
+			if t == recordType {
+				return class.Name
+			}
+		}
+	}
+	return ""
+}
+
+// recordFilePath returns where a record of recordType is appended: the
+// local private file when its class is private, else the shared file.
+func recordFilePath(projectPath, sharedFile, recordType string) string {
+	frayDir := resolveFrayDir(projectPath)
+	if class := recordTypeClass(recordType); class != "" && PrivateRecords(projectPath)[class] {
+		return privateRecordsPath(frayDir)
+	}
+	return filepath.Join(frayDir, sharedFile)
+}
+
+// readRecordLines reads a shared JSONL file with the private records that
+// belong to it merged in by time, so readers replay both in order.
+func readRecordLines(frayDir, sharedFile string) ([]string, error) {
+	lines, err := readJSONLLines(filepath.Join(frayDir, sharedFile))
+	if err != nil {
+		return nil, err
+	}
+	private, err := readJSONLLines(privateRecordsPath(frayDir))
+	if err != nil {
+		return nil, err
+	}
+	var own []string
+	for _, line := range private {
+		if spec, ok := recordsByType[recordLineType(line)]; ok && spec.File == sharedFile {
+			own = append(own, line)
+		}
+	}
+	return mergeRecordLines(lines, own), nil
+}
+
+// recordTimeFields are the timestamp fields tried, in order, to place a
+// record in time; any other *_at field is the fallback.
+var recordTimeFields = []string{"ts", "at", "created_at", "registered_at", "set_at", "faved_at", "unfaved_at", "subscribed_at", "unsubscribed_at"}
+
+// recordLineTime returns the time a JSONL record was written, if it has one.
+func recordLineTime(line string) (int64, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return 0, false
+	}
+	names := append([]string(nil), recordTimeFields...)
+	var rest []string
+	for name := range fields {
+		if strings.HasSuffix(name, "_at") {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range append(names, rest...) {
+		var ts int64
+		if raw, ok := fields[name]; ok && json.Unmarshal(raw, &ts) == nil && ts != 0 {
+			return ts, true
+		}
+	}
+	return 0, false
+}
+
+// recordLineTimes places each line in time; a line without a timestamp
+// takes the time of the line before it.
+func recordLineTimes(lines []string) []int64 {
+	times := make([]int64, len(lines))
+	var last int64
+	for i, line := range lines {
+		if ts, ok := recordLineTime(line); ok {
+			last = ts
+		}
+		times[i] = last
+	}
+	return times
+}
+
+// mergeRecordLines merges two time-ordered runs of records, keeping each
+// run's own order. On equal times lines from a come first.
+func mergeRecordLines(a, b []string) []string {
+	if len(a) == 0 || len(b) == 0 {
+		return append(append([]string(nil), a...), b...)
+	}
+	aTimes, bTimes := recordLineTimes(a), recordLineTimes(b)
+	merged := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if bTimes[j] < aTimes[i] {
+			merged = append(merged, b[j])
+			j++
+		} else {
+			merged = append(merged, a[i])
+			i++
+		}
+	}
+	merged = append(merged, a[i:]...)
+	return append(merged, b[j:]...)
+}
+
+func recordLineType(line string) string {
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(line), &envelope); err != nil {
+		return ""
+	}
+	return envelope.Type
+}
+
+// PrivateRecordCount is how many records of a class sit in each place.
+type PrivateRecordCount struct {
+	Class   string `json:"class"`
+	Private bool   `json:"private"`
+	Shared  int    `json:"shared"`
+	Local   int    `json:"local"`
+}
+
+// CountPrivateRecords reports, per class, how many records are in the shared
+// files and in the local private file.
+func CountPrivateRecords(projectPath string) ([]PrivateRecordCount, error) {
+	frayDir := resolveFrayDir(projectPath)
+	private := PrivateRecords(projectPath)
+	counts := map[string]*PrivateRecordCount{}
+	result := make([]PrivateRecordCount, 0, len(privateRecordClasses))
+	for _, class := range privateRecordClasses {
+		result = append(result, PrivateRecordCount{Class: class.Name, Private: private[class.Name]})
+	}
+	for i := range result {
+		counts[result[i].Class] = &result[i]
+	}
+
+	for _, file := range privateRecordFiles() {
+		lines, err := readJSONLLines(filepath.Join(frayDir, file))
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			if class := recordTypeClass(recordLineType(line)); class != "" {
+				counts[class].Shared++
+			}
+		}
+	}
+	lines, err := readJSONLLines(privateRecordsPath(frayDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		if class := recordTypeClass(recordLineType(line)); class != "" {
+			counts[class].Local++
+		}
+	}
+	return result, nil
+}
+
+// privateRecordFiles lists the shared files that hold routable records.
+func privateRecordFiles() []string {
+	seen := map[string]bool{}
+	var files []string
+	for _, class := range privateRecordClasses {
+		for _, t := range class.Types {
+			if spec, ok := recordsByType[t]; ok && !seen[spec.File] {
+				seen[spec.File] = true
+				files = append(files, spec.File)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// MigratePrivateRecords moves records to match the current private_records
+// setting: private classes out of the shared files into the local private
+// file, and shared classes back. Moved records are merged into their new
+// file by time. It holds the JSONL lock exclusively, so appends from other
+// processes wait for the rewrite. It returns how many records of each class
+// moved.
+func MigratePrivateRecords(projectPath string) (map[string]int, error) {
+	frayDir := resolveFrayDir(projectPath)
+	private := PrivateRecords(projectPath)
+	moved := map[string]int{}
+
+	appendMu.Lock()
+	defer appendMu.Unlock()
+	unlock, err := lockJSONL(frayDir, true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	privatePath := privateRecordsPath(frayDir)
+	privateLines, err := readJSONLLines(privatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Plan every move before touching a file.
+	type sharedFile struct {
+		path            string
+		lines, toShared []string
+		leaving         bool
+	}
+	var shared []sharedFile
+	var toPrivate []string
+	for _, file := range privateRecordFiles() {
+		path := filepath.Join(frayDir, file)
+		lines, err := readJSONLLines(path)
+		if err != nil {
+			return nil, err
+		}
+		entry := sharedFile{path: path, lines: lines}
+		for _, line := range lines {
+			if class := recordTypeClass(recordLineType(line)); class != "" && private[class] {
+				toPrivate = append(toPrivate, line)
+				moved[class]++
+				entry.leaving = true
+			}
+		}
+		for _, line := range privateLines {
+			recordType := recordLineType(line)
+			class := recordTypeClass(recordType)
+			if class != "" && !private[class] && recordsByType[recordType].File == file {
+				entry.toShared = append(entry.toShared, line)
+				moved[class]++
+			}
+		}
+		shared = append(shared, entry)
+	}
+	var leavingPrivate bool
+	for _, line := range privateLines {
+		if class := recordTypeClass(recordLineType(line)); class != "" && !private[class] {
+			leavingPrivate = true
+		}
+	}
+
+	// Copy moved records into their new file before removing them from the
+	// old one, so a failure in between leaves duplicates, which the next run
+	// cleans up, rather than losing records.
+	if len(toPrivate) > 0 {
+		if err := ensureDir(filepath.Join(frayDir, localDir)); err != nil {
+			return nil, err
+		}
+		privateLines = mergeRecordLines(privateLines, missingLines(privateLines, toPrivate))
+		if err := rewriteJSONLLines(privatePath, privateLines); err != nil {
+			return nil, err
+		}
+	}
+	for i, entry := range shared {
+		if len(entry.toShared) == 0 {
+			continue
+		}
+		shared[i].lines = mergeRecordLines(entry.lines, missingLines(entry.lines, entry.toShared))
+		if err := rewriteJSONLLines(entry.path, shared[i].lines); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range shared {
+		if !entry.leaving {
+			continue
+		}
+		kept := make([]string, 0, len(entry.lines))
+		for _, line := range entry.lines {
+			if class := recordTypeClass(recordLineType(line)); class == "" || !private[class] {
+				kept = append(kept, line)
+			}
+		}
+		if err := rewriteJSONLLines(entry.path, kept); err != nil {
+			return nil, err
+		}
+	}
+	if leavingPrivate {
+		var stay []string
+		for _, line := range privateLines {
+			if class := recordTypeClass(recordLineType(line)); class == "" || private[class] {
+				stay = append(stay, line)
+			}
+		}
+		if err := rewriteJSONLLines(privatePath, stay); err != nil {
+			return nil, err
+		}
+	}
+	return moved, nil
+}
+
+// missingLines returns the lines of add that are not already in lines,
+// comparing them as they would be written.
+func missingLines(lines, add []string) []string {
+	key := func(line string) string {
+		if stamped, err := StampRecordLine([]byte(line)); err == nil {
+			return string(stamped)
+		}
+		return line
+	}
+	present := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		present[key(line)] = true
+	}
+	var missing []string
+	for _, line := range add {
+		if !present[key(line)] {
+			missing = append(missing, line)
+		}
+	}
+	return missing
+}
+
+// rewriteJSONLLines is writeJSONLLines; tests swap it to fail mid-migration.
+var rewriteJSONLLines = writeJSONLLines
+
+// writeJSONLLines replaces a JSONL file through a temp file and rename,
+// stamping versions on lines written before versioning.
+func writeJSONLLines(path string, lines []string) error {
+	var builder strings.Builder
+	for _, line := range lines {
+		stamped, err := StampRecordLine([]byte(line))
+		if err != nil {
+			return err
+		}
+		builder.Write(stamped)
+		builder.WriteByte('\n')
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(builder.String()), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
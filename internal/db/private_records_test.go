@@ -0,0 +1,252 @@
+package db
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func setPrivateRecords(t *testing.T, project core.Project, value string) {
+	t.Helper()
+	if err := SetConfigLayer(nil, project.DBPath, ConfigScopeLocal, PrivateRecordsConfigKey, value); err != nil {
+		t.Fatalf("set private_records: %v", err)
+	}
+}
+
+// countRecordTypes counts lines of each record type in a JSONL file.
+func countRecordTypes(t *testing.T, path string) map[string]int {
+	t.Helper()
+	lines, err := readJSONLLines(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	counts := map[string]int{}
+	for _, line := range lines {
+		counts[recordLineType(line)]++
+	}
+	return counts
+}
+
+func TestPrivateRecordsRouteAppends(t *testing.T) {
+	project := rebuildTestProject(t)
+	frayDir := resolveFrayDir(project.DBPath)
+	setPrivateRecords(t, project, "faves")
+
+	if err := AppendAgentFave(project.DBPath, "alice", "message", "msg-aaaaaaaa", 10); err != nil {
+		t.Fatalf("append fave: %v", err)
+	}
+	if err := AppendThreadSubscribe(project.DBPath, ThreadSubscribeJSONLRecord{ThreadGUID: "thrd-1", AgentID: "alice", SubscribedAt: 11}); err != nil {
+		t.Fatalf("append subscribe: %v", err)
+	}
+
+	if got := countRecordTypes(t, privateRecordsPath(frayDir)); got["agent_fave"] != 1 || got["thread_subscribe"] != 0 {
+		t.Fatalf("expected only the fave in private.jsonl, got %v", got)
+	}
+	if got := countRecordTypes(t, filepath.Join(frayDir, agentsFile)); got["agent_fave"] != 0 {
+		t.Fatalf("expected no fave in agents.jsonl, got %v", got)
+	}
+	if got := countRecordTypes(t, filepath.Join(frayDir, threadsFile)); got["thread_subscribe"] != 1 {
+		t.Fatalf("expected the subscribe in threads.jsonl, got %v", got)
+	}
+}
+
+func TestRebuildReadsPrivateRecords(t *testing.T) {
+	project := rebuildTestProject(t)
+	setPrivateRecords(t, project, "faves")
+	if err := AppendAgentFave(project.DBPath, "alice", "message", "msg-aaaaaaaa", 10); err != nil {
+		t.Fatalf("append fave: %v", err)
+	}
+
+	conn, err := OpenDatabase(project)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer conn.Close()
+	if err := RebuildDatabaseFromJSONL(conn, project.DBPath); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	faved, err := IsFaved(conn, "alice", "message", "msg-aaaaaaaa")
+	if err != nil || !faved {
+		t.Fatalf("expected the private fave after rebuild, got %v (%v)", faved, err)
+	}
+}
+
+func TestMigratePrivateRecords(t *testing.T) {
+	project := rebuildTestProject(t)
+	frayDir := resolveFrayDir(project.DBPath)
+	for i, guid := range []string{"msg-aaaaaaaa", "msg-bbbbbbbb"} {
+		if err := AppendAgentFave(project.DBPath, "alice", "message", guid, int64(10+i)); err != nil {
+			t.Fatalf("append fave: %v", err)
+		}
+	}
+	if err := AppendAgentUnfave(project.DBPath, "alice", "message", "msg-aaaaaaaa", 20); err != nil {
+		t.Fatalf("append unfave: %v", err)
+	}
+
+	setPrivateRecords(t, project, "faves")
+	moved, err := MigratePrivateRecords(project.DBPath)
+	if err != nil || moved["faves"] != 3 {
+		t.Fatalf("expected 3 faves moved, got %v (%v)", moved, err)
+	}
+	if got := countRecordTypes(t, filepath.Join(frayDir, agentsFile)); got["agent_fave"]+got["agent_unfave"] != 0 {
+		t.Fatalf("expected faves gone from agents.jsonl, got %v", got)
+	}
+	lines, err := readJSONLLines(privateRecordsPath(frayDir))
+	if err != nil || len(lines) != 3 || recordLineType(lines[2]) != "agent_unfave" {
+		t.Fatalf("expected the faves in order in private.jsonl, got %v (%v)", lines, err)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, `"v":1`) {
+			t.Fatalf("expected moved lines to carry a version, got %s", line)
+		}
+	}
+	if moved, err := MigratePrivateRecords(project.DBPath); err != nil || len(moved) != 0 {
+		t.Fatalf("expected a second migrate to be a no-op, got %v (%v)", moved, err)
+	}
+
+	// Switching back moves them home again in their original order.
+	setPrivateRecords(t, project, "")
+	moved, err = MigratePrivateRecords(project.DBPath)
+	if err != nil || moved["faves"] != 3 {
+		t.Fatalf("expected 3 faves moved back, got %v (%v)", moved, err)
+	}
+	if got := countRecordTypes(t, privateRecordsPath(frayDir)); len(got) != 0 {
+		t.Fatalf("expected private.jsonl to be empty, got %v", got)
+	}
+	events, err := ReadFaves(project.DBPath)
+	if err != nil || len(events) != 3 || events[2].Type != "agent_unfave" {
+		t.Fatalf("expected faves back in order, got %+v (%v)", events, err)
+	}
+}
+
+func TestMigratePrivateRecordsSurvivesFailedRewrite(t *testing.T) {
+	project := rebuildTestProject(t)
+	frayDir := resolveFrayDir(project.DBPath)
+	for i, guid := range []string{"msg-aaaaaaaa", "msg-bbbbbbbb", "msg-cccccccc"} {
+		if err := AppendAgentFave(project.DBPath, "alice", "message", guid, int64(10+i)); err != nil {
+			t.Fatalf("append fave: %v", err)
+		}
+	}
+	setPrivateRecords(t, project, "faves")
+
+	// Fail the second write: the faves are copied but not yet removed.
+	writes := 0
+	rewriteJSONLLines = func(path string, lines []string) error {
+		writes++
+		if writes == 2 {
+			return errors.New("disk full")
+		}
+		return writeJSONLLines(path, lines)
+	}
+	t.Cleanup(func() { rewriteJSONLLines = writeJSONLLines })
+	if _, err := MigratePrivateRecords(project.DBPath); err == nil {
+		t.Fatal("expected the injected failure")
+	}
+	if got := countRecordTypes(t, filepath.Join(frayDir, agentsFile)); got["agent_fave"] != 3 {
+		t.Fatalf("expected faves still in agents.jsonl, got %v", got)
+	}
+	if got := countRecordTypes(t, privateRecordsPath(frayDir)); got["agent_fave"] != 3 {
+		t.Fatalf("expected faves copied to private.jsonl, got %v", got)
+	}
+
+	// The next run finishes the move without duplicating anything.
+	rewriteJSONLLines = writeJSONLLines
+	if _, err := MigratePrivateRecords(project.DBPath); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if got := countRecordTypes(t, filepath.Join(frayDir, agentsFile)); got["agent_fave"] != 0 {
+		t.Fatalf("expected faves gone from agents.jsonl, got %v", got)
+	}
+	events, err := ReadFaves(project.DBPath)
+	if err != nil || len(events) != 3 {
+		t.Fatalf("expected exactly 3 faves, got %+v (%v)", events, err)
+	}
+}
+
+func TestReadRecordLinesMergesPrivateByTime(t *testing.T) {
+	project := rebuildTestProject(t)
+	frayDir := resolveFrayDir(project.DBPath)
+
+	if err := AppendThreadSubscribe(project.DBPath, ThreadSubscribeJSONLRecord{ThreadGUID: "thrd-1", AgentID: "alice", SubscribedAt: 10}); err != nil {
+		t.Fatalf("append subscribe: %v", err)
+	}
+	setPrivateRecords(t, project, "subscriptions")
+	if err := AppendThreadSubscribe(project.DBPath, ThreadSubscribeJSONLRecord{ThreadGUID: "thrd-2", AgentID: "alice", SubscribedAt: 20}); err != nil {
+		t.Fatalf("append subscribe: %v", err)
+	}
+	setPrivateRecords(t, project, "")
+	if err := AppendThreadUnsubscribe(project.DBPath, ThreadUnsubscribeJSONLRecord{ThreadGUID: "thrd-2", AgentID: "alice", UnsubscribedAt: 30}); err != nil {
+		t.Fatalf("append unsubscribe: %v", err)
+	}
+
+	lines, err := readRecordLines(frayDir, threadsFile)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var times []int64
+	for _, line := range lines {
+		if ts, ok := recordLineTime(line); ok {
+			times = append(times, ts)
+		}
+	}
+	if len(times) != 3 || times[0] != 10 || times[1] != 20 || times[2] != 30 {
+		t.Fatalf("expected the private subscribe replayed before the later unsubscribe, got %v", times)
+	}
+}
+
+func TestReadMarkersRouteGhostCursors(t *testing.T) {
+	project := rebuildTestProject(t)
+	frayDir := resolveFrayDir(project.DBPath)
+	setPrivateRecords(t, project, "read_markers")
+
+	if err := AppendGhostCursor(project.DBPath, types.GhostCursor{AgentID: "alice", Home: "room", MessageGUID: "msg-aaaaaaaa", SetAt: 10}); err != nil {
+		t.Fatalf("append ghost cursor: %v", err)
+	}
+	if got := countRecordTypes(t, privateRecordsPath(frayDir)); got["ghost_cursor"] != 1 {
+		t.Fatalf("expected the ghost cursor in private.jsonl, got %v", got)
+	}
+	if got := countRecordTypes(t, filepath.Join(frayDir, agentsFile)); got["ghost_cursor"] != 0 {
+		t.Fatalf("expected no ghost cursor in agents.jsonl, got %v", got)
+	}
+}
+
+func TestMigratePrivateRecordsWaitsForJSONLLock(t *testing.T) {
+	project := rebuildTestProject(t)
+	if err := AppendAgentFave(project.DBPath, "alice", "message", "msg-aaaaaaaa", 10); err != nil {
+		t.Fatalf("append fave: %v", err)
+	}
+	setPrivateRecords(t, project, "faves")
+
+	release, err := LockJSONL(project.DBPath)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := MigratePrivateRecords(project.DBPath)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("expected migrate to wait for the lock holder, got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+}
+
+func TestParsePrivateRecords(t *testing.T) {
+	if classes, err := ParsePrivateRecords("faves, read_markers,"); err != nil || !classes["faves"] || !classes["read_markers"] {
+		t.Fatalf("unexpected parse %v (%v)", classes, err)
+	}
+	if _, err := ParsePrivateRecords("faves,bookmarks"); err == nil {
+		t.Fatalf("expected an unknown class to be rejected")
+	}
+}
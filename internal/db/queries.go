@@ -3,13 +3,85 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/adamavenir/fray/internal/core"
 )
 
+// GUIDEntropyConfigKey sets how many random characters new GUIDs get, so
+// large projects can opt into longer, less collision-prone IDs.
+const GUIDEntropyConfigKey = "guid_entropy_bytes"
+
+// Bounds for guid_entropy_bytes. The minimum is the historical GUID length.
+const (
+	MinGUIDEntropy = 8
+	MaxGUIDEntropy = 32
+)
+
+// guidAttempts bounds how many GUIDs an insert tries before giving up.
+const guidAttempts = 5
+
+// newGUID generates a GUID of the given length. Tests swap it to force
+// collisions.
+var newGUID = core.GenerateGUIDWithLength
+
+// ParseGUIDEntropy parses a guid_entropy_bytes value.
+func ParseGUIDEntropy(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < MinGUIDEntropy || n > MaxGUIDEntropy {
+		return 0, fmt.Errorf("%s must be an integer from %d to %d", GUIDEntropyConfigKey, MinGUIDEntropy, MaxGUIDEntropy)
+	}
+	return n, nil
+}
+
+// guidEntropy returns the configured GUID length, or the default when unset
+// or invalid.
+func guidEntropy(db *sql.DB) int {
+	value, err := GetConfig(db, GUIDEntropyConfigKey)
+	if err != nil || value == "" {
+		return MinGUIDEntropy
+	}
+	n, err := ParseGUIDEntropy(value)
+	if err != nil {
+		return MinGUIDEntropy
+	}
+	return n
+}
+
+// insertWithGUID runs insert with a fresh GUID for table and retries with a
+// new one when the insert hits the table's guid uniqueness constraint (another
+// writer took the GUID between generation and insert). A non-empty fixed GUID
+// is inserted as-is without retrying.
+func insertWithGUID(db *sql.DB, table, prefix, fixed string, insert func(guid string) error) (string, error) {
+	if fixed != "" {
+		return fixed, insert(fixed)
+	}
+	for attempt := 0; attempt < guidAttempts; attempt++ {
+		guid, err := generateUniqueGUIDForTable(db, table, prefix)
+		if err != nil {
+			return "", err
+		}
+		err = insert(guid)
+		if err == nil {
+			return guid, nil
+		}
+		if !isGUIDConflict(err, table) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("failed to generate unique %s GUID", prefix)
+}
+
+// isGUIDConflict reports whether err is a uniqueness violation on table.guid.
+func isGUIDConflict(err error, table string) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: "+table+".guid")
+}
+
 func generateUniqueGUIDForTable(db *sql.DB, table, prefix string) (string, error) {
-	for attempt := 0; attempt < 5; attempt++ {
-		guid, err := core.GenerateGUID(prefix)
+	length := guidEntropy(db)
+	for attempt := 0; attempt < guidAttempts; attempt++ {
+		guid, err := newGUID(prefix, length)
 		if err != nil {
 			return "", err
 		}
@@ -16,12 +16,16 @@ type AgentUpdates struct {
 	Avatar   types.OptionalString
 	LastSeen types.OptionalInt64
 	LeftAt   types.OptionalInt64
+
+	LastKnownInput  types.OptionalInt64
+	LastKnownOutput types.OptionalInt64
+	TokensUpdatedAt types.OptionalInt64
 }
 
 // GetAgent returns an agent by exact ID.
 func GetAgent(db *sql.DB, agentID string) (*types.Agent, error) {
 	row := db.QueryRow(`
-		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id
+		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id, parent_agent, last_known_input, last_known_output, tokens_updated_at, reaction_watermark
 		FROM fray_agents
 		WHERE agent_id = ?
 	`, agentID)
@@ -39,7 +43,7 @@ func GetAgent(db *sql.DB, agentID string) (*types.Agent, error) {
 // GetAgentsByPrefix returns agents matching a prefix.
 func GetAgentsByPrefix(db *sql.DB, prefix string) ([]types.Agent, error) {
 	rows, err := db.Query(`
-		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id
+		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id, parent_agent, last_known_input, last_known_output, tokens_updated_at, reaction_watermark
 		FROM fray_agents
 		WHERE agent_id = ? OR agent_id LIKE ?
 		ORDER BY agent_id
@@ -66,7 +70,7 @@ func GetAgentsByPrefix(db *sql.DB, prefix string) ([]types.Agent, error) {
 // GetAgents returns all agents.
 func GetAgents(db *sql.DB) ([]types.Agent, error) {
 	rows, err := db.Query(`
-		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id
+		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id, parent_agent, last_known_input, last_known_output, tokens_updated_at, reaction_watermark
 		FROM fray_agents
 		ORDER BY agent_id
 	`)
@@ -89,17 +93,12 @@ func GetAgents(db *sql.DB) ([]types.Agent, error) {
 	return agents, nil
 }
 
-// CreateAgent inserts a new agent.
+// CreateAgent inserts a new agent. Its status and purpose are checked
+// against the content policy.
 func CreateAgent(db *sql.DB, agent types.Agent) error {
-	guid := agent.GUID
-	if guid == "" {
-		var err error
-		guid, err = generateUniqueGUIDForTable(db, "fray_agents", "usr")
-		if err != nil {
-			return err
-		}
+	if err := checkTextContent(db, optionalText(agent.Status), optionalText(agent.Purpose)); err != nil {
+		return err
 	}
-
 	var invokeJSON *string
 	if agent.Invoke != nil {
 		data, err := json.Marshal(agent.Invoke)
@@ -120,10 +119,13 @@ func CreateAgent(db *sql.DB, agent types.Agent) error {
 		presence = string(types.PresenceOffline)
 	}
 
-	_, err := db.Exec(`
-		INSERT INTO fray_agents (guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, guid, agent.AgentID, agent.Status, agent.Purpose, agent.Avatar, agent.RegisteredAt, agent.LastSeen, agent.LeftAt, managed, invokeJSON, presence, agent.MentionWatermark, agent.LastHeartbeat)
+	_, err := insertWithGUID(db, "fray_agents", "usr", agent.GUID, func(guid string) error {
+		_, err := db.Exec(`
+			INSERT INTO fray_agents (guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, parent_agent)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, guid, agent.AgentID, agent.Status, agent.Purpose, agent.Avatar, agent.RegisteredAt, agent.LastSeen, agent.LeftAt, managed, invokeJSON, presence, agent.MentionWatermark, agent.LastHeartbeat, agent.ParentAgent)
+		return err
+	})
 	return err
 }
 
@@ -133,6 +135,13 @@ func UpdateAgentWatermark(db *sql.DB, agentID, msgID string) error {
 	return err
 }
 
+// UpdateAgentReactionWatermark records the reacted_at (ms) of the last
+// reaction an agent has acked.
+func UpdateAgentReactionWatermark(db *sql.DB, agentID string, reactedAt int64) error {
+	_, err := db.Exec(`UPDATE fray_agents SET reaction_watermark = ? WHERE agent_id = ?`, reactedAt, agentID)
+	return err
+}
+
 // UpdateAgentPresence updates the presence state for an agent.
 func UpdateAgentPresence(db *sql.DB, agentID string, presence types.PresenceState) error {
 	_, err := db.Exec(`UPDATE fray_agents SET presence = ? WHERE agent_id = ?`, string(presence), agentID)
@@ -151,8 +160,13 @@ func UpdateAgentSessionID(db *sql.DB, agentID, sessionID string) error {
 	return err
 }
 
-// UpdateAgent updates agent fields.
+// UpdateAgent updates agent fields. A new status or purpose is checked
+// against the content policy.
 func UpdateAgent(db *sql.DB, agentID string, updates AgentUpdates) error {
+	if err := checkTextContent(db, optionalText(updates.Status.Value), optionalText(updates.Purpose.Value)); err != nil {
+		return err
+	}
+
 	var fields []string
 	var args []any
 
@@ -176,6 +190,18 @@ func UpdateAgent(db *sql.DB, agentID string, updates AgentUpdates) error {
 		fields = append(fields, "left_at = ?")
 		args = append(args, nullableValue(updates.LeftAt.Value))
 	}
+	if updates.LastKnownInput.Set {
+		fields = append(fields, "last_known_input = ?")
+		args = append(args, nullableValue(updates.LastKnownInput.Value))
+	}
+	if updates.LastKnownOutput.Set {
+		fields = append(fields, "last_known_output = ?")
+		args = append(args, nullableValue(updates.LastKnownOutput.Value))
+	}
+	if updates.TokensUpdatedAt.Set {
+		fields = append(fields, "tokens_updated_at = ?")
+		args = append(args, nullableValue(updates.TokensUpdatedAt.Value))
+	}
 
 	if len(fields) == 0 {
 		return nil
@@ -190,7 +216,7 @@ func UpdateAgent(db *sql.DB, agentID string, updates AgentUpdates) error {
 // GetActiveAgents returns non-stale agents.
 func GetActiveAgents(db *sql.DB, staleHours int) ([]types.Agent, error) {
 	rows, err := db.Query(`
-		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id
+		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id, parent_agent, last_known_input, last_known_output, tokens_updated_at, reaction_watermark
 		FROM fray_agents
 		WHERE left_at IS NULL
 		  AND last_seen > (strftime('%s', 'now') - ? * 3600)
@@ -215,10 +241,64 @@ func GetActiveAgents(db *sql.DB, staleHours int) ([]types.Agent, error) {
 	return agents, nil
 }
 
+// GetManagedAgents returns daemon-managed agents ordered by agent ID.
+func GetManagedAgents(db *sql.DB) ([]types.Agent, error) {
+	rows, err := db.Query(`
+		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id, parent_agent, last_known_input, last_known_output, tokens_updated_at, reaction_watermark
+		FROM fray_agents
+		WHERE managed = 1
+		ORDER BY agent_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var agents []types.Agent
+	for rows.Next() {
+		agent, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, agent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
+// GetSubAgents returns the sub-agents registered under parent, ordered by agent ID.
+func GetSubAgents(db *sql.DB, parent string) ([]types.Agent, error) {
+	rows, err := db.Query(`
+		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id, parent_agent, last_known_input, last_known_output, tokens_updated_at, reaction_watermark
+		FROM fray_agents
+		WHERE parent_agent = ?
+		ORDER BY agent_id
+	`, parent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var agents []types.Agent
+	for rows.Next() {
+		agent, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, agent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
 // GetAllAgents returns all agents.
 func GetAllAgents(db *sql.DB) ([]types.Agent, error) {
 	rows, err := db.Query(`
-		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id
+		SELECT guid, agent_id, status, purpose, avatar, registered_at, last_seen, left_at, managed, invoke, presence, mention_watermark, last_heartbeat, last_session_id, parent_agent, last_known_input, last_known_output, tokens_updated_at, reaction_watermark
 		FROM fray_agents
 		ORDER BY agent_id
 	`)
@@ -241,9 +321,9 @@ func GetAllAgents(db *sql.DB) ([]types.Agent, error) {
 	return agents, nil
 }
 
-// GetActiveUsers returns active chat users.
-func GetActiveUsers(db *sql.DB) ([]string, error) {
-	username, err := GetConfig(db, "username")
+// GetActiveUsers returns the human users of this checkout.
+func GetActiveUsers(db *sql.DB, projectPath string) ([]string, error) {
+	username, err := HumanIdentity(db, projectPath)
 	if err != nil {
 		return nil, err
 	}
@@ -562,43 +642,53 @@ func NextVersion(db *sql.DB, base string) (int, error) {
 
 func scanAgent(scanner interface{ Scan(dest ...any) error }) (types.Agent, error) {
 	var row agentRow
-	if err := scanner.Scan(&row.GUID, &row.AgentID, &row.Status, &row.Purpose, &row.Avatar, &row.RegisteredAt, &row.LastSeen, &row.LeftAt, &row.Managed, &row.Invoke, &row.Presence, &row.MentionWatermark, &row.LastHeartbeat, &row.LastSessionID); err != nil {
+	if err := scanner.Scan(&row.GUID, &row.AgentID, &row.Status, &row.Purpose, &row.Avatar, &row.RegisteredAt, &row.LastSeen, &row.LeftAt, &row.Managed, &row.Invoke, &row.Presence, &row.MentionWatermark, &row.LastHeartbeat, &row.LastSessionID, &row.ParentAgent, &row.LastKnownInput, &row.LastKnownOutput, &row.TokensUpdatedAt, &row.ReactionWatermark); err != nil {
 		return types.Agent{}, err
 	}
 	return row.toAgent(), nil
 }
 
 type agentRow struct {
-	GUID             string
-	AgentID          string
-	Status           sql.NullString
-	Purpose          sql.NullString
-	Avatar           sql.NullString
-	RegisteredAt     int64
-	LastSeen         int64
-	LeftAt           sql.NullInt64
-	Managed          int
-	Invoke           sql.NullString
-	Presence         sql.NullString
-	MentionWatermark sql.NullString
-	LastHeartbeat    sql.NullInt64
-	LastSessionID    sql.NullString
+	GUID              string
+	AgentID           string
+	Status            sql.NullString
+	Purpose           sql.NullString
+	Avatar            sql.NullString
+	RegisteredAt      int64
+	LastSeen          int64
+	LeftAt            sql.NullInt64
+	Managed           int
+	Invoke            sql.NullString
+	Presence          sql.NullString
+	MentionWatermark  sql.NullString
+	LastHeartbeat     sql.NullInt64
+	LastSessionID     sql.NullString
+	ParentAgent       sql.NullString
+	LastKnownInput    sql.NullInt64
+	LastKnownOutput   sql.NullInt64
+	TokensUpdatedAt   sql.NullInt64
+	ReactionWatermark sql.NullInt64
 }
 
 func (row agentRow) toAgent() types.Agent {
 	agent := types.Agent{
-		GUID:             row.GUID,
-		AgentID:          row.AgentID,
-		Status:           nullStringPtr(row.Status),
-		Purpose:          nullStringPtr(row.Purpose),
-		Avatar:           nullStringPtr(row.Avatar),
-		RegisteredAt:     row.RegisteredAt,
-		LastSeen:         row.LastSeen,
-		LeftAt:           nullIntPtr(row.LeftAt),
-		Managed:          row.Managed != 0,
-		MentionWatermark: nullStringPtr(row.MentionWatermark),
-		LastHeartbeat:    nullIntPtr(row.LastHeartbeat),
-		LastSessionID:    nullStringPtr(row.LastSessionID),
+		GUID:              row.GUID,
+		AgentID:           row.AgentID,
+		Status:            nullStringPtr(row.Status),
+		Purpose:           nullStringPtr(row.Purpose),
+		Avatar:            nullStringPtr(row.Avatar),
+		RegisteredAt:      row.RegisteredAt,
+		LastSeen:          row.LastSeen,
+		LeftAt:            nullIntPtr(row.LeftAt),
+		Managed:           row.Managed != 0,
+		MentionWatermark:  nullStringPtr(row.MentionWatermark),
+		LastHeartbeat:     nullIntPtr(row.LastHeartbeat),
+		LastSessionID:     nullStringPtr(row.LastSessionID),
+		ParentAgent:       nullStringPtr(row.ParentAgent),
+		LastKnownInput:    nullIntPtr(row.LastKnownInput),
+		LastKnownOutput:   nullIntPtr(row.LastKnownOutput),
+		TokensUpdatedAt:   nullIntPtr(row.TokensUpdatedAt),
+		ReactionWatermark: nullIntPtr(row.ReactionWatermark),
 	}
 	if row.Presence.Valid {
 		agent.Presence = types.PresenceState(row.Presence.String)
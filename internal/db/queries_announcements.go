@@ -0,0 +1,203 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// AnnounceReminderConfigKey is how many minutes after an ack-required
+// announcement the daemon re-mentions agents who haven't acked. Unset or 0
+// turns reminders off.
+const AnnounceReminderConfigKey = "announce_reminder_minutes"
+
+// AnnouncementAckReaction is the reaction that counts as an ack.
+const AnnouncementAckReaction = "✅"
+
+// Announcement ack sources.
+const (
+	AckSourceCommand  = "command"
+	AckSourceReaction = "reaction"
+)
+
+// ParseAnnounceReminder parses an announce_reminder_minutes value; 0 means
+// reminders are off.
+func ParseAnnounceReminder(value string) (time.Duration, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative number of minutes", AnnounceReminderConfigKey)
+	}
+	return time.Duration(n) * time.Minute, nil
+}
+
+// CreateAnnouncement records an announcement.
+func CreateAnnouncement(db DBTX, announcement types.Announcement) error {
+	audience, err := json.Marshal(announcement.Audience)
+	if err != nil {
+		return err
+	}
+	ackRequired := 0
+	if announcement.AckRequired {
+		ackRequired = 1
+	}
+	_, err = db.Exec(`
+		INSERT OR REPLACE INTO fray_announcements (message_guid, from_agent, ack_required, audience, created_at, reminded_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, announcement.MessageGUID, announcement.FromAgent, ackRequired, string(audience), announcement.CreatedAt, announcement.RemindedAt)
+	return err
+}
+
+// GetAnnouncement returns the announcement posted as messageGUID, or nil.
+func GetAnnouncement(db *sql.DB, messageGUID string) (*types.Announcement, error) {
+	row := db.QueryRow(`
+		SELECT message_guid, from_agent, ack_required, audience, created_at, reminded_at
+		FROM fray_announcements WHERE message_guid = ?
+	`, messageGUID)
+	announcement, err := scanAnnouncement(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+// GetUnremindedAnnouncements returns ack-required announcements created at
+// or before cutoff (unix seconds) that haven't had their reminder yet.
+func GetUnremindedAnnouncements(db *sql.DB, cutoff int64) ([]types.Announcement, error) {
+	rows, err := db.Query(`
+		SELECT message_guid, from_agent, ack_required, audience, created_at, reminded_at
+		FROM fray_announcements
+		WHERE ack_required = 1 AND reminded_at IS NULL AND created_at <= ?
+		ORDER BY created_at ASC
+	`, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var announcements []types.Announcement
+	for rows.Next() {
+		announcement, err := scanAnnouncement(rows)
+		if err != nil {
+			return nil, err
+		}
+		announcements = append(announcements, announcement)
+	}
+	return announcements, rows.Err()
+}
+
+// MarkAnnouncementReminded records when an announcement's reminder went out.
+func MarkAnnouncementReminded(db DBTX, messageGUID string, at int64) error {
+	_, err := db.Exec(`UPDATE fray_announcements SET reminded_at = ? WHERE message_guid = ?`, at, messageGUID)
+	return err
+}
+
+// AckAnnouncement records an ack. Only the first ack per agent is kept; it
+// reports whether this one was new.
+func AckAnnouncement(db DBTX, ack types.AnnouncementAck) (bool, error) {
+	result, err := db.Exec(`
+		INSERT OR IGNORE INTO fray_announcement_acks (message_guid, agent_id, source, acked_at)
+		VALUES (?, ?, ?, ?)
+	`, ack.MessageGUID, ack.AgentID, ack.Source, ack.AckedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetAnnouncementAcks returns who has acked an announcement, oldest first:
+// recorded acks plus ✅ reactions left by audience members.
+func GetAnnouncementAcks(db *sql.DB, announcement types.Announcement) ([]types.AnnouncementAck, error) {
+	rows, err := db.Query(`
+		SELECT message_guid, agent_id, source, acked_at
+		FROM fray_announcement_acks WHERE message_guid = ?
+	`, announcement.MessageGUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	acked := map[string]types.AnnouncementAck{}
+	for rows.Next() {
+		var ack types.AnnouncementAck
+		if err := rows.Scan(&ack.MessageGUID, &ack.AgentID, &ack.Source, &ack.AckedAt); err != nil {
+			return nil, err
+		}
+		acked[ack.AgentID] = ack
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	reactions, err := GetReactionsForMessage(db, announcement.MessageGUID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range reactions[AnnouncementAckReaction] {
+		ackedAt := entry.ReactedAt / 1000
+		if prior, ok := acked[entry.AgentID]; ok && prior.AckedAt <= ackedAt {
+			continue
+		}
+		acked[entry.AgentID] = types.AnnouncementAck{
+			MessageGUID: announcement.MessageGUID,
+			AgentID:     entry.AgentID,
+			Source:      AckSourceReaction,
+			AckedAt:     ackedAt,
+		}
+	}
+
+	acks := make([]types.AnnouncementAck, 0, len(acked))
+	for _, ack := range acked {
+		acks = append(acks, ack)
+	}
+	sort.Slice(acks, func(i, j int) bool {
+		if acks[i].AckedAt != acks[j].AckedAt {
+			return acks[i].AckedAt < acks[j].AckedAt
+		}
+		return acks[i].AgentID < acks[j].AgentID
+	})
+	return acks, nil
+}
+
+// PendingAnnouncementAcks returns the audience members missing from acks,
+// in audience order.
+func PendingAnnouncementAcks(announcement types.Announcement, acks []types.AnnouncementAck) []string {
+	acked := make(map[string]bool, len(acks))
+	for _, ack := range acks {
+		acked[ack.AgentID] = true
+	}
+	var pending []string
+	for _, agentID := range announcement.Audience {
+		if !acked[agentID] {
+			pending = append(pending, agentID)
+		}
+	}
+	return pending
+}
+
+func scanAnnouncement(scanner interface{ Scan(dest ...any) error }) (types.Announcement, error) {
+	var announcement types.Announcement
+	var ackRequired int
+	var audience string
+	var remindedAt sql.NullInt64
+	if err := scanner.Scan(&announcement.MessageGUID, &announcement.FromAgent, &ackRequired, &audience, &announcement.CreatedAt, &remindedAt); err != nil {
+		return types.Announcement{}, err
+	}
+	announcement.AckRequired = ackRequired != 0
+	if err := json.Unmarshal([]byte(audience), &announcement.Audience); err != nil {
+		return types.Announcement{}, err
+	}
+	if remindedAt.Valid {
+		announcement.RemindedAt = &remindedAt.Int64
+	}
+	return announcement, nil
+}
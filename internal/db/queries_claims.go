@@ -26,18 +26,32 @@ func PruneExpiredClaims(db *sql.DB) (int64, error) {
 	return result.RowsAffected()
 }
 
-// CreateClaim inserts a new claim.
+// ClaimTakenError is returned by CreateClaim when another claim already holds
+// the same type and pattern.
+type ClaimTakenError struct {
+	Existing types.Claim
+}
+
+func (e *ClaimTakenError) Error() string {
+	return fmt.Sprintf("already claimed by @%s: %s:%s", e.Existing.AgentID, e.Existing.ClaimType, e.Existing.Pattern)
+}
+
+// CreateClaim inserts a new claim. Its note is checked against the content
+// policy.
 func CreateClaim(db *sql.DB, claim types.ClaimInput) (*types.Claim, error) {
+	if err := checkTextContent(db, optionalText(claim.Reason)); err != nil {
+		return nil, err
+	}
 	now := time.Now().Unix()
 	result, err := db.Exec(`
-		INSERT INTO fray_claims (agent_id, claim_type, pattern, reason, created_at, expires_at)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, claim.AgentID, claim.ClaimType, claim.Pattern, claim.Reason, now, claim.ExpiresAt)
+		INSERT INTO fray_claims (agent_id, claim_type, pattern, reason, message_guid, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, claim.AgentID, claim.ClaimType, claim.Pattern, claim.Reason, claim.MessageGUID, now, claim.ExpiresAt)
 	if err != nil {
 		if isConstraintError(err) {
 			existing, lookupErr := GetClaim(db, claim.ClaimType, claim.Pattern)
 			if lookupErr == nil && existing != nil {
-				return nil, fmt.Errorf("already claimed by @%s: %s:%s", existing.AgentID, claim.ClaimType, claim.Pattern)
+				return nil, &ClaimTakenError{Existing: *existing}
 			}
 		}
 		return nil, err
@@ -49,20 +63,21 @@ func CreateClaim(db *sql.DB, claim types.ClaimInput) (*types.Claim, error) {
 	}
 
 	return &types.Claim{
-		ID:        id,
-		AgentID:   claim.AgentID,
-		ClaimType: claim.ClaimType,
-		Pattern:   claim.Pattern,
-		Reason:    claim.Reason,
-		CreatedAt: now,
-		ExpiresAt: claim.ExpiresAt,
+		ID:          id,
+		AgentID:     claim.AgentID,
+		ClaimType:   claim.ClaimType,
+		Pattern:     claim.Pattern,
+		Reason:      claim.Reason,
+		MessageGUID: claim.MessageGUID,
+		CreatedAt:   now,
+		ExpiresAt:   claim.ExpiresAt,
 	}, nil
 }
 
 // GetClaim returns a claim by type and pattern.
 func GetClaim(db *sql.DB, claimType types.ClaimType, pattern string) (*types.Claim, error) {
 	row := db.QueryRow(`
-		SELECT id, agent_id, claim_type, pattern, reason, created_at, expires_at
+		SELECT id, agent_id, claim_type, pattern, reason, message_guid, created_at, expires_at
 		FROM fray_claims
 		WHERE claim_type = ? AND pattern = ?
 	`, claimType, pattern)
@@ -79,7 +94,7 @@ func GetClaim(db *sql.DB, claimType types.ClaimType, pattern string) (*types.Cla
 // GetClaimsByAgent returns claims for an agent.
 func GetClaimsByAgent(db *sql.DB, agentID string) ([]types.Claim, error) {
 	rows, err := db.Query(`
-		SELECT id, agent_id, claim_type, pattern, reason, created_at, expires_at
+		SELECT id, agent_id, claim_type, pattern, reason, message_guid, created_at, expires_at
 		FROM fray_claims
 		WHERE agent_id = ?
 		ORDER BY created_at
@@ -95,7 +110,7 @@ func GetClaimsByAgent(db *sql.DB, agentID string) ([]types.Claim, error) {
 // GetClaimsByType returns claims of a type.
 func GetClaimsByType(db *sql.DB, claimType types.ClaimType) ([]types.Claim, error) {
 	rows, err := db.Query(`
-		SELECT id, agent_id, claim_type, pattern, reason, created_at, expires_at
+		SELECT id, agent_id, claim_type, pattern, reason, message_guid, created_at, expires_at
 		FROM fray_claims
 		WHERE claim_type = ?
 		ORDER BY created_at
@@ -114,7 +129,7 @@ func GetAllClaims(db *sql.DB) ([]types.Claim, error) {
 		return nil, err
 	}
 	rows, err := db.Query(`
-		SELECT id, agent_id, claim_type, pattern, reason, created_at, expires_at
+		SELECT id, agent_id, claim_type, pattern, reason, message_guid, created_at, expires_at
 		FROM fray_claims
 		ORDER BY created_at
 	`)
@@ -226,7 +241,7 @@ func GetClaimCountsByAgent(db *sql.DB) (map[string]int64, error) {
 
 func scanClaim(scanner interface{ Scan(dest ...any) error }) (types.Claim, error) {
 	var row claimRow
-	if err := scanner.Scan(&row.ID, &row.AgentID, &row.ClaimType, &row.Pattern, &row.Reason, &row.CreatedAt, &row.ExpiresAt); err != nil {
+	if err := scanner.Scan(&row.ID, &row.AgentID, &row.ClaimType, &row.Pattern, &row.Reason, &row.MessageGUID, &row.CreatedAt, &row.ExpiresAt); err != nil {
 		return types.Claim{}, err
 	}
 	return row.toClaim(), nil
@@ -257,23 +272,25 @@ func isConstraintError(err error) bool {
 }
 
 type claimRow struct {
-	ID        int64
-	AgentID   string
-	ClaimType types.ClaimType
-	Pattern   string
-	Reason    sql.NullString
-	CreatedAt int64
-	ExpiresAt sql.NullInt64
+	ID          int64
+	AgentID     string
+	ClaimType   types.ClaimType
+	Pattern     string
+	Reason      sql.NullString
+	MessageGUID sql.NullString
+	CreatedAt   int64
+	ExpiresAt   sql.NullInt64
 }
 
 func (row claimRow) toClaim() types.Claim {
 	return types.Claim{
-		ID:        row.ID,
-		AgentID:   row.AgentID,
-		ClaimType: row.ClaimType,
-		Pattern:   row.Pattern,
-		Reason:    nullStringPtr(row.Reason),
-		CreatedAt: row.CreatedAt,
-		ExpiresAt: nullIntPtr(row.ExpiresAt),
+		ID:          row.ID,
+		AgentID:     row.AgentID,
+		ClaimType:   row.ClaimType,
+		Pattern:     row.Pattern,
+		Reason:      nullStringPtr(row.Reason),
+		MessageGUID: nullStringPtr(row.MessageGUID),
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   nullIntPtr(row.ExpiresAt),
 	}
 }
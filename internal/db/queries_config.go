@@ -2,6 +2,8 @@ package db
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/adamavenir/fray/internal/types"
@@ -20,12 +22,39 @@ func GetConfig(db *sql.DB, key string) (string, error) {
 	return value, nil
 }
 
+// ParseConfigBool parses a boolean config value: true/false or 1/0, in any
+// case.
+func ParseConfigBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("must be true or false")
+}
+
 // SetConfig sets a config value.
 func SetConfig(db *sql.DB, key, value string) error {
 	_, err := db.Exec("INSERT OR REPLACE INTO fray_config (key, value) VALUES (?, ?)", key, value)
 	return err
 }
 
+// SetConfigEntries sets several config values in one transaction.
+func SetConfigEntries(db *sql.DB, entries []types.ConfigEntry) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, err := tx.Exec("INSERT OR REPLACE INTO fray_config (key, value) VALUES (?, ?)", entry.Key, entry.Value); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 // GetAllConfig returns all config entries.
 func GetAllConfig(db *sql.DB) ([]types.ConfigEntry, error) {
 	rows, err := db.Query("SELECT key, value FROM fray_config ORDER BY key")
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SetDaemonSession records that the daemon spawned sessionID for an agent,
+// replacing any earlier record.
+func SetDaemonSession(db *sql.DB, agentID, sessionID string) error {
+	_, err := db.Exec(`
+		INSERT OR REPLACE INTO fray_daemon_sessions (agent_id, session_id, started_at)
+		VALUES (?, ?, ?)
+	`, agentID, sessionID, time.Now().UnixMilli())
+	return err
+}
+
+// ClearDaemonSession forgets the daemon session recorded for an agent.
+func ClearDaemonSession(db *sql.DB, agentID string) error {
+	_, err := db.Exec(`DELETE FROM fray_daemon_sessions WHERE agent_id = ?`, agentID)
+	return err
+}
+
+// GetDaemonSessions returns the recorded daemon session ID per agent.
+func GetDaemonSessions(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query(`SELECT agent_id, COALESCE(session_id, '') FROM fray_daemon_sessions`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make(map[string]string)
+	for rows.Next() {
+		var agentID, sessionID string
+		if err := rows.Scan(&agentID, &sessionID); err != nil {
+			return nil, err
+		}
+		sessions[agentID] = sessionID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
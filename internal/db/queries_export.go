@@ -0,0 +1,64 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// StreamMessages calls fn for each unarchived message, oldest first, along
+// with its reaction count, without loading the whole result into memory.
+// Unlike GetMessages, a nil options.Home means every home, and only SinceTS
+// and UntilTS are applied otherwise. An error from fn stops the walk and is
+// returned as-is.
+func StreamMessages(db *sql.DB, options *types.MessageQueryOptions, fn func(msg types.Message, reactionCount int) error) error {
+	conditions := []string{"m.archived_at IS NULL"}
+	var params []any
+	if options != nil && options.Home != nil {
+		conditions = append(conditions, "m.home = ?")
+		params = append(params, *options.Home)
+	}
+	if clause, args := buildTimeBoundConditions("m.", options); clause != "" {
+		conditions = append(conditions, clause)
+		params = append(params, args...)
+	}
+	if clause, args := buildAuthorConditions(options); clause != "" {
+		conditions = append(conditions, clause)
+		params = append(params, args...)
+	}
+
+	rows, err := db.Query(`
+		SELECT `+messageColumnsAliased+`,
+			(SELECT COUNT(*) FROM fray_reactions r WHERE r.message_guid = m.guid)
+		FROM fray_messages m
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY m.ts ASC, m.guid ASC
+	`, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var count int
+		msg, err := scanMessage(trailingScanner{rows: rows, extra: []any{&count}})
+		if err != nil {
+			return err
+		}
+		if err := fn(msg, count); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// trailingScanner scans a message row followed by extra aggregate columns.
+type trailingScanner struct {
+	rows  *sql.Rows
+	extra []any
+}
+
+func (s trailingScanner) Scan(dest ...any) error {
+	return s.rows.Scan(append(dest, s.extra...)...)
+}
@@ -2,7 +2,11 @@ package db
 
 import (
 	"database/sql"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/adamavenir/fray/internal/types"
 )
 
 // Fave represents a faved item.
@@ -159,3 +163,53 @@ func GetThreadNicknames(db *sql.DB, agentID string) (map[string]string, error) {
 	}
 	return nicknames, rows.Err()
 }
+
+// PinboardEntry is a message faved by several agents.
+type PinboardEntry struct {
+	Message   types.Message `json:"message"`
+	FaveCount int           `json:"fave_count"`
+	FavedBy   []string      `json:"faved_by"`
+}
+
+// GetPinboard returns unarchived messages currently faved by at least
+// minFaves distinct agents, most faved first and newest first among ties.
+// A non-nil home limits it to messages living there.
+func GetPinboard(db *sql.DB, minFaves int, home *string) ([]PinboardEntry, error) {
+	query := `
+		SELECT ` + messageColumnsAliased + `, group_concat(f.agent_id, ',') AS faved_by, COUNT(DISTINCT f.agent_id) AS fave_count
+		FROM fray_faves f
+		JOIN fray_messages m ON m.guid = f.item_guid
+		WHERE f.item_type = 'message' AND m.archived_at IS NULL
+	`
+	params := []any{}
+	if home != nil {
+		query += " AND m.home = ?"
+		params = append(params, *home)
+	}
+	query += `
+		GROUP BY m.guid
+		HAVING fave_count >= ?
+		ORDER BY fave_count DESC, m.ts DESC, m.guid DESC
+	`
+	params = append(params, minFaves)
+
+	rows, err := db.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []PinboardEntry
+	for rows.Next() {
+		var favedBy string
+		var count int
+		msg, err := scanMessage(trailingScanner{rows: rows, extra: []any{&favedBy, &count}})
+		if err != nil {
+			return nil, err
+		}
+		agents := strings.Split(favedBy, ",")
+		sort.Strings(agents)
+		entries = append(entries, PinboardEntry{Message: msg, FaveCount: count, FavedBy: agents})
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,236 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/gobwas/glob"
+)
+
+// flyRecentMessages is how many recent meta thread messages a fly context carries.
+const flyRecentMessages = 5
+
+// flyMentionLimit caps the unread mentions in a fly context.
+const flyMentionLimit = 20
+
+// FlyContext bundles what an agent needs to start a session: its last
+// handoff, the project meta thread, unread mentions, open questions, nearby
+// claims by others, and the thread it was last working in.
+type FlyContext struct {
+	AgentID        string           `json:"agent_id"`
+	Handoff        *types.Message   `json:"handoff,omitempty"`
+	Meta           *FlyMeta         `json:"meta,omitempty"`
+	UnreadMentions []types.Message  `json:"unread_mentions"`
+	OpenQuestions  []types.Question `json:"open_questions"`
+	NearbyClaims   []types.Claim    `json:"nearby_claims"`
+	FocusThread    *types.Thread    `json:"focus_thread,omitempty"`
+}
+
+// FlyMeta is the project meta thread's anchor and latest messages.
+type FlyMeta struct {
+	ThreadGUID string          `json:"thread_guid"`
+	Anchor     *types.Message  `json:"anchor,omitempty"`
+	Recent     []types.Message `json:"recent"`
+}
+
+// GetFlyContext assembles the fly context for agentID. projectPath locates
+// agents.jsonl, whose claim history supplies the agent's usual paths.
+func GetFlyContext(db *sql.DB, projectPath, agentID string) (*FlyContext, error) {
+	base := agentBase(agentID)
+	fly := &FlyContext{
+		AgentID:        agentID,
+		UnreadMentions: []types.Message{},
+		OpenQuestions:  []types.Question{},
+		NearbyClaims:   []types.Claim{},
+	}
+
+	var err error
+	if fly.Handoff, err = flyHandoff(db, base); err != nil {
+		return nil, err
+	}
+	if fly.Meta, err = flyMeta(db); err != nil {
+		return nil, err
+	}
+	if fly.UnreadMentions, err = flyUnreadMentions(db, base); err != nil {
+		return nil, err
+	}
+	questions, err := GetQuestions(db, &types.QuestionQueryOptions{
+		Statuses: []types.QuestionStatus{types.QuestionStatusOpen},
+		ToAgent:  &base,
+	})
+	if err != nil {
+		return nil, err
+	}
+	fly.OpenQuestions = append(fly.OpenQuestions, questions...)
+	if fly.NearbyClaims, err = flyNearbyClaims(db, projectPath, base); err != nil {
+		return nil, err
+	}
+	if fly.FocusThread, err = flyFocusThread(db, base); err != nil {
+		return nil, err
+	}
+	return fly, nil
+}
+
+// agentBase strips a numeric session suffix (alice.1 -> alice). Dotted
+// names such as dev.frontend are kept whole.
+func agentBase(agentID string) string {
+	if parsed, err := core.ParseAgentID(agentID); err == nil {
+		return parsed.Base
+	}
+	return agentID
+}
+
+// getThreadByPath walks a slash-separated path of thread names from the root.
+func getThreadByPath(db *sql.DB, path string) (*types.Thread, error) {
+	var parent *string
+	var thread *types.Thread
+	for _, name := range strings.Split(path, "/") {
+		next, err := GetThreadByName(db, name, parent)
+		if err != nil || next == nil {
+			return nil, err
+		}
+		thread = next
+		parent = &next.GUID
+	}
+	return thread, nil
+}
+
+// flyHandoff returns the last message in meta/<agent>/notes.
+func flyHandoff(db *sql.DB, base string) (*types.Message, error) {
+	notes, err := getThreadByPath(db, "meta/"+base+"/notes")
+	if err != nil || notes == nil {
+		return nil, err
+	}
+	messages, err := GetMessages(db, &types.MessageQueryOptions{Home: &notes.GUID, Limit: 1})
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	return &messages[0], nil
+}
+
+// flyMeta returns the root meta thread's anchor and latest messages.
+func flyMeta(db *sql.DB) (*FlyMeta, error) {
+	meta, err := GetThreadByName(db, "meta", nil)
+	if err != nil || meta == nil {
+		return nil, err
+	}
+	recent, err := GetMessages(db, &types.MessageQueryOptions{Home: &meta.GUID, Limit: flyRecentMessages})
+	if err != nil {
+		return nil, err
+	}
+	result := &FlyMeta{ThreadGUID: meta.GUID, Recent: recent}
+	if result.Recent == nil {
+		result.Recent = []types.Message{}
+	}
+	if meta.AnchorMessageGUID != nil {
+		if result.Anchor, err = GetMessage(db, *meta.AnchorMessageGUID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// flyUnreadMentions returns mentions of base past its mentions watermark,
+// excluding the agent's own messages.
+func flyUnreadMentions(db *sql.DB, base string) ([]types.Message, error) {
+	allHomes := ""
+	opts := &types.MessageQueryOptions{
+		Limit:                 flyMentionLimit,
+		IncludeRepliesToAgent: base,
+		AgentPrefix:           base,
+		Home:                  &allHomes,
+	}
+	watermark, err := GetReadTo(db, base, "mentions")
+	if err != nil {
+		return nil, err
+	}
+	if watermark != nil {
+		opts.Since = &types.MessageCursor{GUID: watermark.MessageGUID, TS: watermark.MessageTS}
+	} else {
+		opts.UnreadOnly = true
+	}
+	messages, err := GetMessagesWithMention(db, base, opts)
+	if err != nil {
+		return nil, err
+	}
+	unread := []types.Message{}
+	for _, msg := range messages {
+		if agentBase(msg.FromAgent) != base {
+			unread = append(unread, msg)
+		}
+	}
+	return unread, nil
+}
+
+// flyNearbyClaims returns other agents' file claims that overlap the paths
+// base has claimed before, now or in the claim history.
+func flyNearbyClaims(db *sql.DB, projectPath, base string) ([]types.Claim, error) {
+	usual := map[string]struct{}{}
+	history, err := ReadClaimHistory(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range history {
+		if entry.ClaimType == types.ClaimTypeFile && agentBase(entry.AgentID) == base {
+			usual[entry.Pattern] = struct{}{}
+		}
+	}
+	claims, err := GetClaimsByType(db, types.ClaimTypeFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, claim := range claims {
+		if agentBase(claim.AgentID) == base {
+			usual[claim.Pattern] = struct{}{}
+		}
+	}
+
+	nearby := []types.Claim{}
+	for _, claim := range claims {
+		if agentBase(claim.AgentID) == base {
+			continue
+		}
+		for pattern := range usual {
+			if claimPatternsOverlap(claim.Pattern, pattern) {
+				nearby = append(nearby, claim)
+				break
+			}
+		}
+	}
+	return nearby, nil
+}
+
+// claimPatternsOverlap reports whether either glob matches the other.
+func claimPatternsOverlap(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if matcher, err := glob.Compile(a); err == nil && matcher.Match(b) {
+		return true
+	}
+	if matcher, err := glob.Compile(b); err == nil && matcher.Match(a) {
+		return true
+	}
+	return false
+}
+
+// flyFocusThread returns the thread of the agent's most recent post, if that
+// post was in a thread.
+func flyFocusThread(db *sql.DB, base string) (*types.Thread, error) {
+	allHomes := ""
+	messages, err := GetMessages(db, &types.MessageQueryOptions{
+		Home:       &allHomes,
+		FromAgents: []string{base},
+		Limit:      1,
+	})
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	home := messages[0].Home
+	if home == "" || home == "room" {
+		return nil, nil
+	}
+	return GetThread(db, home)
+}
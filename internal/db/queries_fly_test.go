@@ -0,0 +1,151 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+func TestGetFlyContext(t *testing.T) {
+	database := openTestDB(t)
+	requireSchema(t, database)
+	projectPath := filepath.Join(t.TempDir(), ".fray")
+
+	thread := func(name string, parent *string) types.Thread {
+		t.Helper()
+		created, err := CreateThread(database, types.Thread{Name: name, ParentThread: parent, Status: types.ThreadStatusOpen})
+		if err != nil {
+			t.Fatalf("create thread %s: %v", name, err)
+		}
+		return created
+	}
+	post := func(ts int64, home, from, body string, mentions ...string) types.Message {
+		t.Helper()
+		if mentions == nil {
+			mentions = []string{}
+		}
+		msg, err := CreateMessage(database, types.Message{TS: ts, Home: home, FromAgent: from, Body: body, Mentions: mentions})
+		if err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+		return msg
+	}
+
+	meta := thread("meta", nil)
+	devMeta := thread("dev", &meta.GUID)
+	notes := thread("notes", &devMeta.GUID)
+	design := thread("design", nil)
+
+	post(100, notes.GUID, "dev", "old handoff")
+	handoff := post(200, notes.GUID, "dev", "handoff: finish the parser")
+	anchor := post(110, meta.GUID, "pm", "project goals")
+	if _, err := UpdateThread(database, meta.GUID, ThreadUpdates{
+		AnchorMessageGUID: types.OptionalString{Set: true, Value: &anchor.ID},
+	}); err != nil {
+		t.Fatalf("set anchor: %v", err)
+	}
+	for i := int64(0); i < 6; i++ {
+		post(300+i, meta.GUID, "pm", "meta update")
+	}
+
+	read := post(400, "room", "pm", "@dev old ping", "dev")
+	if err := SetReadTo(database, "dev", "mentions", read.ID, read.TS); err != nil {
+		t.Fatalf("set watermark: %v", err)
+	}
+	unread := post(500, "room", "pm", "@dev new ping", "dev")
+	post(510, "room", "dev.1", "@dev note to self", "dev")
+	post(600, design.GUID, "dev", "working on the design")
+
+	if _, err := CreateQuestion(database, types.Question{Re: "which parser?", FromAgent: "pm", ToAgent: strPtr("dev"), Status: types.QuestionStatusOpen, CreatedAt: 500}); err != nil {
+		t.Fatalf("create question: %v", err)
+	}
+	if _, err := CreateQuestion(database, types.Question{Re: "for someone else", FromAgent: "pm", ToAgent: strPtr("qa"), Status: types.QuestionStatusOpen, CreatedAt: 500}); err != nil {
+		t.Fatalf("create question: %v", err)
+	}
+
+	// dev once claimed internal/parser/*; that claim is cleared now.
+	past := types.Claim{AgentID: "dev", ClaimType: types.ClaimTypeFile, Pattern: "internal/parser/*", CreatedAt: 50}
+	if err := AppendClaim(projectPath, past, nil); err != nil {
+		t.Fatalf("append claim: %v", err)
+	}
+	if err := AppendClaimClear(projectPath, past, 60); err != nil {
+		t.Fatalf("append claim clear: %v", err)
+	}
+	for _, claim := range []types.ClaimInput{
+		{AgentID: "qa", ClaimType: types.ClaimTypeFile, Pattern: "internal/parser/lexer.go"},
+		{AgentID: "qa", ClaimType: types.ClaimTypeFile, Pattern: "docs/*"},
+	} {
+		if _, err := CreateClaim(database, claim); err != nil {
+			t.Fatalf("create claim: %v", err)
+		}
+	}
+
+	fly, err := GetFlyContext(database, projectPath, "dev")
+	if err != nil {
+		t.Fatalf("fly context: %v", err)
+	}
+
+	t.Run("handoff", func(t *testing.T) {
+		if fly.Handoff == nil || fly.Handoff.ID != handoff.ID {
+			t.Fatalf("expected latest notes message as handoff, got %+v", fly.Handoff)
+		}
+	})
+	t.Run("meta", func(t *testing.T) {
+		if fly.Meta == nil || fly.Meta.Anchor == nil || fly.Meta.Anchor.ID != anchor.ID {
+			t.Fatalf("expected meta anchor, got %+v", fly.Meta)
+		}
+		if len(fly.Meta.Recent) != 5 || fly.Meta.Recent[4].TS != 305 {
+			t.Fatalf("expected last 5 meta messages, got %+v", fly.Meta.Recent)
+		}
+	})
+	t.Run("unread mentions", func(t *testing.T) {
+		if len(fly.UnreadMentions) != 1 || fly.UnreadMentions[0].ID != unread.ID {
+			t.Fatalf("expected only the unread mention from someone else, got %+v", fly.UnreadMentions)
+		}
+	})
+	t.Run("open questions", func(t *testing.T) {
+		if len(fly.OpenQuestions) != 1 || fly.OpenQuestions[0].Re != "which parser?" {
+			t.Fatalf("expected the question addressed to dev, got %+v", fly.OpenQuestions)
+		}
+	})
+	t.Run("nearby claims", func(t *testing.T) {
+		if len(fly.NearbyClaims) != 1 || fly.NearbyClaims[0].Pattern != "internal/parser/lexer.go" {
+			t.Fatalf("expected qa's parser claim only, got %+v", fly.NearbyClaims)
+		}
+	})
+	t.Run("focus thread", func(t *testing.T) {
+		if fly.FocusThread == nil || fly.FocusThread.GUID != design.GUID {
+			t.Fatalf("expected focus on design, got %+v", fly.FocusThread)
+		}
+	})
+}
+
+func TestGetFlyContextEmptyProject(t *testing.T) {
+	database := openTestDB(t)
+	requireSchema(t, database)
+
+	fly, err := GetFlyContext(database, filepath.Join(t.TempDir(), ".fray"), "dev")
+	if err != nil {
+		t.Fatalf("fly context: %v", err)
+	}
+	if fly.Handoff != nil || fly.Meta != nil || fly.FocusThread != nil {
+		t.Fatalf("expected empty sections, got %+v", fly)
+	}
+	if fly.UnreadMentions == nil || fly.OpenQuestions == nil || fly.NearbyClaims == nil {
+		t.Fatalf("expected empty lists rather than nil, got %+v", fly)
+	}
+}
+
+func TestAgentBaseKeepsDottedNames(t *testing.T) {
+	for id, want := range map[string]string{
+		"alice":          "alice",
+		"alice.1":        "alice",
+		"dev.frontend":   "dev.frontend",
+		"dev.frontend.3": "dev.frontend",
+	} {
+		if got := agentBase(id); got != want {
+			t.Errorf("agentBase(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
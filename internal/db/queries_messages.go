@@ -12,13 +12,28 @@ import (
 
 // messageColumns is the explicit column list for SELECT queries.
 // This prevents column order issues when migrations add columns via ALTER TABLE.
-const messageColumns = `guid, ts, channel_id, home, from_agent, body, mentions, type, "references", surface_message, reply_to, quote_message_guid, edited_at, archived_at, reactions`
+const messageColumns = `guid, ts, channel_id, home, from_agent, body, mentions, type, "references", surface_message, reply_to, quote_message_guid, edited_at, archived_at, reactions, important, standup, commits, thread_refs`
 
 // messageColumnsAliased is the same but with m. prefix for JOINs.
-const messageColumnsAliased = `m.guid, m.ts, m.channel_id, m.home, m.from_agent, m.body, m.mentions, m.type, m."references", m.surface_message, m.reply_to, m.quote_message_guid, m.edited_at, m.archived_at, m.reactions`
+const messageColumnsAliased = `m.guid, m.ts, m.channel_id, m.home, m.from_agent, m.body, m.mentions, m.type, m."references", m.surface_message, m.reply_to, m.quote_message_guid, m.edited_at, m.archived_at, m.reactions, m.important, m.standup, m.commits, m.thread_refs`
 
-// CreateMessage inserts a new message.
+// CreateMessage inserts a new message after checking its body against the
+// content policy. Every message path goes through here, so a blocked body
+// returns a *core.BlockedContentError whoever wrote it.
 func CreateMessage(db *sql.DB, message types.Message) (types.Message, error) {
+	if err := CheckMessageContent(db, message.Body); err != nil {
+		return types.Message{}, err
+	}
+	return createMessage(db, message)
+}
+
+// CreateMessageAllowingSecrets inserts a new message without the content
+// policy check, for posts made with --allow-secrets.
+func CreateMessageAllowingSecrets(db *sql.DB, message types.Message) (types.Message, error) {
+	return createMessage(db, message)
+}
+
+func createMessage(db *sql.DB, message types.Message) (types.Message, error) {
 	ts := message.TS
 	if ts == 0 {
 		ts = time.Now().Unix()
@@ -43,28 +58,46 @@ func CreateMessage(db *sql.DB, message types.Message) (types.Message, error) {
 	// New messages don't have reactions yet. Write empty JSON for legacy column.
 	reactionsJSON := []byte("{}")
 
-	msgType := message.Type
-	if msgType == "" {
-		msgType = types.MessageTypeAgent
+	commitsJSON, err := marshalCommits(message.Commits)
+	if err != nil {
+		return types.Message{}, err
 	}
 
-	guid, err := generateUniqueGUIDForTable(db, "fray_messages", "msg")
+	threadRefs := message.ThreadRefs
+	if threadRefs == nil {
+		threadRefs, err = ResolveThreadRefs(db, message.Body)
+		if err != nil {
+			return types.Message{}, err
+		}
+	}
+	threadRefsJSON, err := marshalThreadRefs(threadRefs)
 	if err != nil {
 		return types.Message{}, err
 	}
 
+	msgType := message.Type
+	if msgType == "" {
+		msgType = types.MessageTypeAgent
+	}
+
 	home := message.Home
 	if home == "" {
 		home = "room"
 	}
 
-	_, err = db.Exec(`
-		INSERT INTO fray_messages (guid, ts, channel_id, home, from_agent, body, mentions, type, "references", surface_message, reply_to, quote_message_guid, edited_at, archived_at, reactions)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULL, NULL, ?)
-	`, guid, ts, channelID, home, message.FromAgent, message.Body, string(mentionsJSON), msgType, message.References, message.SurfaceMessage, message.ReplyTo, message.QuoteMessageGUID, string(reactionsJSON))
+	guid, err := insertWithGUID(db, "fray_messages", "msg", "", func(guid string) error {
+		_, err := db.Exec(`
+			INSERT INTO fray_messages (guid, ts, channel_id, home, from_agent, body, mentions, type, "references", surface_message, reply_to, quote_message_guid, edited_at, archived_at, reactions, important, standup, commits, thread_refs)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULL, NULL, ?, ?, ?, ?, ?)
+		`, guid, ts, channelID, home, message.FromAgent, message.Body, string(mentionsJSON), msgType, message.References, message.SurfaceMessage, message.ReplyTo, message.QuoteMessageGUID, string(reactionsJSON), message.Important, message.Standup, commitsJSON, threadRefsJSON)
+		return err
+	})
 	if err != nil {
 		return types.Message{}, err
 	}
+	if err := refreshThreadReferenceCounts(db, threadRefs); err != nil {
+		return types.Message{}, err
+	}
 
 	// Return with empty reactions map (new messages don't have reactions)
 	reactions := make(map[string][]types.ReactionEntry)
@@ -85,9 +118,26 @@ func CreateMessage(db *sql.DB, message types.Message) (types.Message, error) {
 		QuoteMessageGUID: message.QuoteMessageGUID,
 		EditedAt:         nil,
 		ArchivedAt:       nil,
+		Important:        message.Important,
+		Standup:          message.Standup,
+		Commits:          message.Commits,
+		ThreadRefs:       threadRefs,
 	}, nil
 }
 
+// marshalCommits encodes commit metadata for the commits column; no commits
+// is NULL.
+func marshalCommits(commits []types.CommitRef) (any, error) {
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(commits)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
 // loadReactionsForMessages loads reactions from fray_reactions table into the messages.
 func loadReactionsForMessages(db *sql.DB, messages []types.Message) error {
 	if len(messages) == 0 {
@@ -146,11 +196,13 @@ func GetMessages(db *sql.DB, options *types.MessageQueryOptions) ([]types.Messag
 
 	limit := 0
 	includeArchived := false
+	importantOnly := false
 	filter := (*types.Filter)(nil)
 	home := "room"
 	if options != nil {
 		limit = options.Limit
 		includeArchived = options.IncludeArchived
+		importantOnly = options.ImportantOnly
 		filter = options.Filter
 		if options.Home != nil {
 			if *options.Home == "" {
@@ -169,6 +221,10 @@ func GetMessages(db *sql.DB, options *types.MessageQueryOptions) ([]types.Messag
 			conditions = append(conditions, "archived_at IS NULL")
 		}
 
+		if importantOnly {
+			conditions = append(conditions, "important = 1")
+		}
+
 		if home != "" {
 			conditions = append(conditions, "home = ?")
 			params = append(params, home)
@@ -179,6 +235,16 @@ func GetMessages(db *sql.DB, options *types.MessageQueryOptions) ([]types.Messag
 			params = append(params, args...)
 		}
 
+		if clause, args := buildAuthorConditions(options); clause != "" {
+			conditions = append(conditions, clause)
+			params = append(params, args...)
+		}
+
+		if clause, args := buildTimeBoundConditions("", options); clause != "" {
+			conditions = append(conditions, clause)
+			params = append(params, args...)
+		}
+
 		whereClause := ""
 		if len(conditions) > 0 {
 			whereClause = " WHERE " + strings.Join(conditions, " AND ")
@@ -210,6 +276,10 @@ func GetMessages(db *sql.DB, options *types.MessageQueryOptions) ([]types.Messag
 		conditions = append(conditions, "archived_at IS NULL")
 	}
 
+	if importantOnly {
+		conditions = append(conditions, "important = 1")
+	}
+
 	if home != "" {
 		conditions = append(conditions, "home = ?")
 		params = append(params, home)
@@ -232,6 +302,16 @@ func GetMessages(db *sql.DB, options *types.MessageQueryOptions) ([]types.Messag
 		params = append(params, args...)
 	}
 
+	if clause, args := buildAuthorConditions(options); clause != "" {
+		conditions = append(conditions, clause)
+		params = append(params, args...)
+	}
+
+	if clause, args := buildTimeBoundConditions("", options); clause != "" {
+		conditions = append(conditions, clause)
+		params = append(params, args...)
+	}
+
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
@@ -317,6 +397,10 @@ func GetMessagesWithMention(db *sql.DB, mentionPrefix string, options *types.Mes
 		commonConditions = append(commonConditions, clause)
 		commonParams = append(commonParams, args...)
 	}
+	if clause, args := buildTimeBoundConditions("m.", options); clause != "" {
+		commonConditions = append(commonConditions, clause)
+		commonParams = append(commonParams, args...)
+	}
 
 	conditionStr := ""
 	if len(commonConditions) > 0 {
@@ -434,6 +518,39 @@ func GetAgentLastPostTime(database *sql.DB, agentID string) (int64, error) {
 	return ts, nil
 }
 
+// GetLatestDoneMessage returns the agent's most recent done report at or after sinceTs.
+func GetLatestDoneMessage(db *sql.DB, agentID string, sinceTs int64) (*types.Message, error) {
+	row := db.QueryRow("SELECT "+messageColumns+` FROM fray_messages
+		WHERE from_agent = ? AND type = ? AND ts >= ? AND archived_at IS NULL
+		ORDER BY ts DESC, guid DESC
+		LIMIT 1`, agentID, string(types.MessageTypeDone), sinceTs)
+	message, err := scanMessage(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// GetFirstMessageSince returns the agent's earliest non-event message at or
+// after sinceTs, or nil when it hasn't posted since.
+func GetFirstMessageSince(db *sql.DB, agentID string, sinceTs int64) (*types.Message, error) {
+	row := db.QueryRow("SELECT "+messageColumns+` FROM fray_messages
+		WHERE from_agent = ? AND type != ? AND ts >= ? AND archived_at IS NULL
+		ORDER BY ts ASC, guid ASC
+		LIMIT 1`, agentID, string(types.MessageTypeEvent), sinceTs)
+	message, err := scanMessage(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
 // GetMessage returns a message by GUID.
 func GetMessage(db *sql.DB, messageID string) (*types.Message, error) {
 	row := db.QueryRow("SELECT "+messageColumns+" FROM fray_messages WHERE guid = ?", messageID)
@@ -453,6 +570,31 @@ func GetMessage(db *sql.DB, messageID string) (*types.Message, error) {
 	return &message, nil
 }
 
+// GetMessagesByIDs returns the given messages in chronological order using a
+// single query. Reactions are not loaded. Missing IDs are skipped.
+func GetMessagesByIDs(db *sql.DB, ids []string) ([]types.Message, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT %s FROM fray_messages
+		WHERE guid IN (%s)
+		ORDER BY ts ASC, guid ASC
+	`, messageColumns, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
 // GetMessageByPrefix returns a message by GUID prefix.
 func GetMessageByPrefix(db *sql.DB, prefix string) (*types.Message, error) {
 	normalized := prefix
@@ -539,7 +681,29 @@ func EditMessage(db *sql.DB, messageID, newBody, agentID string) error {
 	if _, err := db.Exec("UPDATE fray_messages SET body = ?, edited_at = ? WHERE guid = ?", newBody, editedAt, messageID); err != nil {
 		return err
 	}
-	return nil
+	return updateMessageThreadRefs(db, messageID, newBody)
+}
+
+// ReplaceMessageBody updates a message's body, mentions, and thread
+// references and stamps edited_at. It does not check authorship; callers
+// authorize the edit.
+func ReplaceMessageBody(db *sql.DB, messageID, newBody string, mentions []string) error {
+	if mentions == nil {
+		mentions = []string{}
+	}
+	mentionsJSON, err := json.Marshal(mentions)
+	if err != nil {
+		return err
+	}
+	editedAt := time.Now().Unix()
+	result, err := db.Exec("UPDATE fray_messages SET body = ?, mentions = ?, edited_at = ? WHERE guid = ?", newBody, string(mentionsJSON), editedAt, messageID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("message %s not found", messageID)
+	}
+	return updateMessageThreadRefs(db, messageID, newBody)
 }
 
 // DeleteMessage marks a message as deleted.
@@ -553,8 +717,10 @@ func DeleteMessage(db *sql.DB, messageID string) error {
 	}
 
 	deletedAt := time.Now().Unix()
-	_, err = db.Exec("UPDATE fray_messages SET body = ?, archived_at = ? WHERE guid = ?", "[deleted]", deletedAt, messageID)
-	return err
+	if _, err := db.Exec("UPDATE fray_messages SET body = ?, archived_at = ? WHERE guid = ?", "[deleted]", deletedAt, messageID); err != nil {
+		return err
+	}
+	return refreshThreadReferenceCounts(db, msg.ThreadRefs)
 }
 
 // ArchiveMessages archives messages before a cursor.
@@ -566,7 +732,7 @@ func ArchiveMessages(db *sql.DB, before *types.MessageCursor, beforeID string) (
 		if err != nil {
 			return 0, err
 		}
-		return result.RowsAffected()
+		return archivedRows(db, result)
 	}
 
 	cursor, err := resolveCursor(db, before, beforeID)
@@ -584,7 +750,17 @@ func ArchiveMessages(db *sql.DB, before *types.MessageCursor, beforeID string) (
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	return archivedRows(db, result)
+}
+
+// archivedRows returns how many messages an archive touched, refreshing
+// thread reference counts when any were.
+func archivedRows(db *sql.DB, result sql.Result) (int64, error) {
+	count, err := result.RowsAffected()
+	if err != nil || count == 0 {
+		return count, err
+	}
+	return count, refreshAllThreadReferenceCounts(db)
 }
 
 // GetReplyChain returns parent + replies.
@@ -643,6 +819,10 @@ type messageRow struct {
 	QuoteMessageGUID sql.NullString
 	EditedAt         sql.NullInt64
 	ArchivedAt       sql.NullInt64
+	Important        bool
+	Standup          bool
+	Commits          sql.NullString
+	ThreadRefs       sql.NullString
 }
 
 func (row messageRow) toMessage() (types.Message, error) {
@@ -670,6 +850,18 @@ func (row messageRow) toMessage() (types.Message, error) {
 	if row.Home.Valid && row.Home.String != "" {
 		home = row.Home.String
 	}
+	var commits []types.CommitRef
+	if row.Commits.Valid && row.Commits.String != "" {
+		if err := json.Unmarshal([]byte(row.Commits.String), &commits); err != nil {
+			return types.Message{}, err
+		}
+	}
+	var threadRefs []string
+	if row.ThreadRefs.Valid && row.ThreadRefs.String != "" {
+		if err := json.Unmarshal([]byte(row.ThreadRefs.String), &threadRefs); err != nil {
+			return types.Message{}, err
+		}
+	}
 
 	return types.Message{
 		ID:               row.GUID,
@@ -687,6 +879,10 @@ func (row messageRow) toMessage() (types.Message, error) {
 		QuoteMessageGUID: nullStringPtr(row.QuoteMessageGUID),
 		EditedAt:         nullIntPtr(row.EditedAt),
 		ArchivedAt:       nullIntPtr(row.ArchivedAt),
+		Important:        row.Important,
+		Standup:          row.Standup,
+		Commits:          commits,
+		ThreadRefs:       threadRefs,
 	}, nil
 }
 
@@ -715,6 +911,75 @@ func buildCursorCondition(prefix, op string, cursor *types.MessageCursor) (strin
 	return clause, []any{cursor.TS, cursor.TS, cursor.GUID}
 }
 
+// buildTimeBoundConditions applies SinceTS/UntilTS. Both bounds are inclusive.
+func buildTimeBoundConditions(prefix string, options *types.MessageQueryOptions) (string, []any) {
+	if options == nil {
+		return "", nil
+	}
+	var clauses []string
+	var args []any
+	if options.SinceTS != nil {
+		clauses = append(clauses, prefix+"ts >= ?")
+		args = append(args, *options.SinceTS)
+	}
+	if options.UntilTS != nil {
+		clauses = append(clauses, prefix+"ts <= ?")
+		args = append(args, *options.UntilTS)
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+// buildAuthorConditions applies FromAgents/ExcludeFromAgents. An agent ID
+// also matches its subagents (alice matches alice.1).
+func buildAuthorConditions(options *types.MessageQueryOptions) (string, []any) {
+	if options == nil {
+		return "", nil
+	}
+	var clauses []string
+	var args []any
+	if clause, clauseArgs := buildAuthorMatch(options.FromAgents); clause != "" {
+		clauses = append(clauses, clause)
+		args = append(args, clauseArgs...)
+	}
+	if clause, clauseArgs := buildAuthorMatch(options.ExcludeFromAgents); clause != "" {
+		clauses = append(clauses, "NOT "+clause)
+		args = append(args, clauseArgs...)
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+func buildAuthorMatch(agents []string) (string, []any) {
+	if len(agents) == 0 {
+		return "", nil
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	parts := make([]string, 0, len(agents))
+	args := make([]any, 0, len(agents)*2)
+	for _, agent := range agents {
+		parts = append(parts, `from_agent = ? OR from_agent LIKE ? ESCAPE '\'`)
+		args = append(args, agent, escaper.Replace(agent)+".%")
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+// MatchesAuthorFilter reports whether a message from fromAgent passes the
+// same include/exclude rules as MessageQueryOptions.FromAgents and
+// ExcludeFromAgents, for callers filtering messages already in memory.
+func MatchesAuthorFilter(fromAgent string, include, exclude []string) bool {
+	matches := func(agents []string) bool {
+		for _, agent := range agents {
+			if fromAgent == agent || strings.HasPrefix(fromAgent, agent+".") {
+				return true
+			}
+		}
+		return false
+	}
+	if matches(exclude) {
+		return false
+	}
+	return len(include) == 0 || matches(include)
+}
+
 func buildFilterCondition(filter *types.Filter) (string, []any) {
 	if filter == nil || filter.MentionsPattern == nil || *filter.MentionsPattern == "" {
 		return "", nil
@@ -739,7 +1004,7 @@ func scanMessages(rows *sql.Rows) ([]types.Message, error) {
 
 func scanMessage(scanner interface{ Scan(dest ...any) error }) (types.Message, error) {
 	var row messageRow
-	if err := scanner.Scan(&row.GUID, &row.TS, &row.ChannelID, &row.Home, &row.FromAgent, &row.Body, &row.Mentions, &row.MsgType, &row.References, &row.SurfaceMessage, &row.ReplyTo, &row.QuoteMessageGUID, &row.EditedAt, &row.ArchivedAt, &row.Reactions); err != nil {
+	if err := scanner.Scan(&row.GUID, &row.TS, &row.ChannelID, &row.Home, &row.FromAgent, &row.Body, &row.Mentions, &row.MsgType, &row.References, &row.SurfaceMessage, &row.ReplyTo, &row.QuoteMessageGUID, &row.EditedAt, &row.ArchivedAt, &row.Reactions, &row.Important, &row.Standup, &row.Commits, &row.ThreadRefs); err != nil {
 		return types.Message{}, err
 	}
 	return row.toMessage()
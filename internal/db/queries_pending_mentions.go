@@ -0,0 +1,48 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AddPendingMention records a mention the daemon queued for an agent.
+// Queuing the same message twice is a no-op.
+func AddPendingMention(db *sql.DB, agentID, messageGUID string) error {
+	_, err := db.Exec(`
+		INSERT OR IGNORE INTO fray_pending_mentions (agent_id, message_guid, queued_at)
+		VALUES (?, ?, ?)
+	`, agentID, messageGUID, time.Now().UnixMilli())
+	return err
+}
+
+// ClearPendingMentions removes every queued mention for an agent.
+func ClearPendingMentions(db *sql.DB, agentID string) error {
+	_, err := db.Exec(`DELETE FROM fray_pending_mentions WHERE agent_id = ?`, agentID)
+	return err
+}
+
+// GetPendingMentions returns the queued mentions per agent, in queue order.
+func GetPendingMentions(db *sql.DB) (map[string][]string, error) {
+	rows, err := db.Query(`
+		SELECT agent_id, message_guid
+		FROM fray_pending_mentions
+		ORDER BY queued_at, rowid
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pending := make(map[string][]string)
+	for rows.Next() {
+		var agentID, messageGUID string
+		if err := rows.Scan(&agentID, &messageGUID); err != nil {
+			return nil, err
+		}
+		pending[agentID] = append(pending[agentID], messageGUID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
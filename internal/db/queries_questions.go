@@ -19,17 +19,28 @@ type QuestionUpdates struct {
 	AnsweredIn types.OptionalString
 }
 
-// CreateQuestion inserts a new question.
+// CreateQuestion inserts a new question after checking its text and options
+// against the content policy, as CreateMessage does for message bodies.
 func CreateQuestion(db *sql.DB, question types.Question) (types.Question, error) {
-	guid := question.GUID
-	if guid == "" {
-		var err error
-		guid, err = generateUniqueGUIDForTable(db, "fray_questions", "qstn")
-		if err != nil {
-			return types.Question{}, err
-		}
+	texts := []string{question.Re}
+	for _, option := range question.Options {
+		texts = append(texts, option.Label)
+		texts = append(texts, option.Pros...)
+		texts = append(texts, option.Cons...)
+	}
+	if err := checkTextContent(db, texts...); err != nil {
+		return types.Question{}, err
 	}
+	return createQuestion(db, question)
+}
+
+// CreateQuestionAllowingSecrets inserts a new question without the content
+// policy check, for questions asked with --allow-secrets.
+func CreateQuestionAllowingSecrets(db *sql.DB, question types.Question) (types.Question, error) {
+	return createQuestion(db, question)
+}
 
+func createQuestion(db *sql.DB, question types.Question) (types.Question, error) {
 	status := question.Status
 	if status == "" {
 		status = types.QuestionStatusUnasked
@@ -48,10 +59,13 @@ func CreateQuestion(db *sql.DB, question types.Question) (types.Question, error)
 		optionsJSON = string(optBytes)
 	}
 
-	_, err := db.Exec(`
-		INSERT INTO fray_questions (guid, re, from_agent, to_agent, status, thread_guid, asked_in, answered_in, options, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, guid, question.Re, question.FromAgent, question.ToAgent, string(status), question.ThreadGUID, question.AskedIn, question.AnsweredIn, optionsJSON, createdAt)
+	guid, err := insertWithGUID(db, "fray_questions", "qstn", question.GUID, func(guid string) error {
+		_, err := db.Exec(`
+			INSERT INTO fray_questions (guid, re, from_agent, to_agent, status, thread_guid, asked_in, answered_in, options, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, guid, question.Re, question.FromAgent, question.ToAgent, string(status), question.ThreadGUID, question.AskedIn, question.AnsweredIn, optionsJSON, createdAt)
+		return err
+	})
 	if err != nil {
 		return types.Question{}, err
 	}
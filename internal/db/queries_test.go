@@ -3,6 +3,7 @@ package db
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/adamavenir/fray/internal/types"
 )
@@ -91,6 +92,118 @@ func TestGetMessagesWithLimitAndCursor(t *testing.T) {
 	}
 }
 
+func TestGetMessagesAuthorFilters(t *testing.T) {
+	db := openTestDB(t)
+	requireSchema(t, db)
+
+	for i, from := range []string{"alice", "alice.1", "alicia", "bob", "ci-bot"} {
+		if _, err := CreateMessage(db, types.Message{
+			TS:        int64(100 + i),
+			FromAgent: from,
+			Body:      "from " + from,
+			Mentions:  []string{},
+			Type:      types.MessageTypeAgent,
+		}); err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+
+	authors := func(options types.MessageQueryOptions) string {
+		t.Helper()
+		messages, err := GetMessages(db, &options)
+		if err != nil {
+			t.Fatalf("get messages: %v", err)
+		}
+		var from []string
+		for _, msg := range messages {
+			from = append(from, msg.FromAgent)
+		}
+		return strings.Join(from, ",")
+	}
+
+	cases := []struct {
+		name    string
+		options types.MessageQueryOptions
+		want    string
+	}{
+		{"subagents included", types.MessageQueryOptions{FromAgents: []string{"alice"}}, "alice,alice.1"},
+		{"or semantics", types.MessageQueryOptions{FromAgents: []string{"alice", "bob"}}, "alice,alice.1,bob"},
+		{"exclusion", types.MessageQueryOptions{ExcludeFromAgents: []string{"ci-bot"}}, "alice,alice.1,alicia,bob"},
+		{"exclusion wins", types.MessageQueryOptions{FromAgents: []string{"alice", "bob"}, ExcludeFromAgents: []string{"alice"}}, "bob"},
+		{"exclude subagent only", types.MessageQueryOptions{FromAgents: []string{"alice"}, ExcludeFromAgents: []string{"alice.1"}}, "alice"},
+		{"with limit", types.MessageQueryOptions{Limit: 1, FromAgents: []string{"alice"}}, "alice.1"},
+	}
+	for _, tc := range cases {
+		if got := authors(tc.options); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+		if tc.options.Limit == 0 {
+			var inMemory []string
+			for _, from := range []string{"alice", "alice.1", "alicia", "bob", "ci-bot"} {
+				if MatchesAuthorFilter(from, tc.options.FromAgents, tc.options.ExcludeFromAgents) {
+					inMemory = append(inMemory, from)
+				}
+			}
+			if got := strings.Join(inMemory, ","); got != tc.want {
+				t.Errorf("%s (in memory): got %q, want %q", tc.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestGetMessagesTimeBoundsInclusive(t *testing.T) {
+	db := openTestDB(t)
+	requireSchema(t, db)
+
+	for i, from := range []string{"a", "b", "c", "d"} {
+		if _, err := CreateMessage(db, types.Message{
+			TS:        int64(100 + i*10),
+			FromAgent: from,
+			Body:      "@z from " + from,
+			Mentions:  []string{"z"},
+			Type:      types.MessageTypeAgent,
+		}); err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+
+	join := func(messages []types.Message, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("query: %v", err)
+		}
+		var from []string
+		for _, msg := range messages {
+			from = append(from, msg.FromAgent)
+		}
+		return strings.Join(from, ",")
+	}
+
+	cases := []struct {
+		name    string
+		options types.MessageQueryOptions
+		want    string
+	}{
+		{"since is inclusive", types.MessageQueryOptions{SinceTS: intPtr(110)}, "b,c,d"},
+		{"until is inclusive", types.MessageQueryOptions{UntilTS: intPtr(120)}, "a,b,c"},
+		{"window", types.MessageQueryOptions{SinceTS: intPtr(110), UntilTS: intPtr(120)}, "b,c"},
+		{"single instant", types.MessageQueryOptions{SinceTS: intPtr(120), UntilTS: intPtr(120)}, "c"},
+		{"with limit", types.MessageQueryOptions{Limit: 1, UntilTS: intPtr(120)}, "c"},
+	}
+	for _, tc := range cases {
+		options := tc.options
+		if got := join(GetMessages(db, &options)); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	all := ""
+	mentions := types.MessageQueryOptions{Home: &all, SinceTS: intPtr(110), UntilTS: intPtr(120)}
+	if got := join(GetMessagesWithMention(db, "z", &mentions)); got != "b,c" {
+		t.Errorf("mentions window: got %q, want %q", got, "b,c")
+	}
+}
+
 func TestGetMessagesWithMentionUnread(t *testing.T) {
 	db := openTestDB(t)
 	requireSchema(t, db)
@@ -250,3 +363,220 @@ func TestThreadMessagesIncludeHomeAndMembership(t *testing.T) {
 		t.Fatalf("expected thread message in thread")
 	}
 }
+
+func TestGetThreadStats(t *testing.T) {
+	db := openTestDB(t)
+	requireSchema(t, db)
+
+	thread, err := CreateThread(db, types.Thread{
+		Name:      "design",
+		Status:    types.ThreadStatusOpen,
+		CreatedAt: 1700000000,
+		CreatedBy: strPtr("pm"),
+	})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	post := func(from, body string, msgType types.MessageType) types.Message {
+		t.Helper()
+		msg, err := CreateMessage(db, types.Message{FromAgent: from, Body: body, Type: msgType, Home: thread.GUID})
+		if err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+		return msg
+	}
+	first := post("dev", "schema draft", types.MessageTypeAgent)
+	post("dev.1", "split out the migration", types.MessageTypeAgent)
+	post("dev.2", "tests pass", types.MessageTypeAgent)
+	post("pm", "looks good", types.MessageTypeAgent)
+	archived := post("pm", "old note", types.MessageTypeAgent)
+	post("system", "dev joined", types.MessageTypeEvent)
+	if _, err := db.Exec("UPDATE fray_messages SET archived_at = 1700000100 WHERE guid = ?", archived.ID); err != nil {
+		t.Fatalf("archive message: %v", err)
+	}
+
+	roomMsg, err := CreateMessage(db, types.Message{FromAgent: "qa", Body: "room message"})
+	if err != nil {
+		t.Fatalf("create room message: %v", err)
+	}
+	if err := AddMessageToThread(db, thread.GUID, roomMsg.ID, "qa", 0); err != nil {
+		t.Fatalf("add message to thread: %v", err)
+	}
+	for _, agent := range []string{"dev", "pm"} {
+		if err := SubscribeThread(db, thread.GUID, agent, 0); err != nil {
+			t.Fatalf("subscribe: %v", err)
+		}
+	}
+	if err := PinMessage(db, first.ID, thread.GUID, "pm", 0); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+
+	stats, err := GetThreadStats(db, thread.GUID)
+	if err != nil {
+		t.Fatalf("get thread stats: %v", err)
+	}
+	want := []types.ThreadParticipant{{AgentID: "dev", Messages: 3}, {AgentID: "pm", Messages: 1}, {AgentID: "qa", Messages: 1}}
+	if len(stats.Participants) != len(want) {
+		t.Fatalf("expected participants %+v, got %+v", want, stats.Participants)
+	}
+	for i := range want {
+		if stats.Participants[i] != want[i] {
+			t.Fatalf("expected participants %+v, got %+v", want, stats.Participants)
+		}
+	}
+	if stats.Messages != 5 || stats.Subscribers != 2 || stats.Pins != 1 || stats.CreatedAt != 1700000000 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if stats.Owner == nil || *stats.Owner != "pm" {
+		t.Fatalf("expected creator as owner, got %v", stats.Owner)
+	}
+
+	missing, err := GetThreadStats(db, "thrd-missing")
+	if err != nil || missing != nil {
+		t.Fatalf("expected nil stats for an unknown thread, got %+v (%v)", missing, err)
+	}
+}
+
+func TestCreateThreadValidatesNames(t *testing.T) {
+	db := openTestDB(t)
+	requireSchema(t, db)
+
+	created, err := CreateThread(db, types.Thread{Name: " Design "})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	if created.Name != "design" {
+		t.Fatalf("expected normalized name design, got %q", created.Name)
+	}
+
+	for _, name := range []string{"room", "main", "all", "design thread", "design_v2"} {
+		if _, err := CreateThread(db, types.Thread{Name: name}); err == nil {
+			t.Fatalf("expected %q to be rejected", name)
+		}
+	}
+
+	_, err = CreateThread(db, types.Thread{Name: "DESIGN"})
+	taken, ok := err.(*ThreadNameTakenError)
+	if !ok || taken.Existing.GUID != created.GUID {
+		t.Fatalf("expected duplicate to point at %s, got %v", created.GUID, err)
+	}
+
+	// Same name under a different parent is fine.
+	if _, err := CreateThread(db, types.Thread{Name: "design", ParentThread: &created.GUID}); err != nil {
+		t.Fatalf("expected nested design thread to be allowed: %v", err)
+	}
+}
+
+func TestInsertWithGUIDRetriesOnCollision(t *testing.T) {
+	db := openTestDB(t)
+	requireSchema(t, db)
+
+	first, err := CreateMessage(db, types.Message{FromAgent: "alice.1", Body: "first", Type: types.MessageTypeAgent})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+
+	// Simulate a concurrent writer: the GUID is free when generated but taken
+	// by the time the insert runs.
+	attempts := 0
+	guid, err := insertWithGUID(db, "fray_messages", "msg", "", func(guid string) error {
+		attempts++
+		if attempts == 1 {
+			guid = first.ID
+		}
+		_, err := db.Exec(`
+			INSERT INTO fray_messages (guid, ts, home, from_agent, body, mentions, type)
+			VALUES (?, 1, 'room', 'bob.1', 'second', '[]', 'agent')
+		`, guid)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("insert with guid: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+	if guid == first.ID {
+		t.Fatalf("expected a fresh guid, got %s", guid)
+	}
+}
+
+func TestInsertWithGUIDGivesUpAfterAttempts(t *testing.T) {
+	db := openTestDB(t)
+	requireSchema(t, db)
+
+	existing, err := CreateMessage(db, types.Message{FromAgent: "alice.1", Body: "first", Type: types.MessageTypeAgent})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+
+	orig := newGUID
+	newGUID = func(prefix string, length int) (string, error) { return existing.ID, nil }
+	t.Cleanup(func() { newGUID = orig })
+
+	if _, err := CreateMessage(db, types.Message{FromAgent: "alice.1", Body: "second", Type: types.MessageTypeAgent}); err == nil {
+		t.Fatal("expected error when every guid collides")
+	}
+}
+
+func TestGUIDEntropyConfig(t *testing.T) {
+	db := openTestDB(t)
+	requireSchema(t, db)
+
+	if err := SetConfig(db, GUIDEntropyConfigKey, "16"); err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+	msg, err := CreateMessage(db, types.Message{FromAgent: "alice.1", Body: "hello", Type: types.MessageTypeAgent})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	if got := len(strings.TrimPrefix(msg.ID, "msg-")); got != 16 {
+		t.Fatalf("expected 16 guid characters, got %d (%s)", got, msg.ID)
+	}
+
+	found, err := GetMessageByPrefix(db, strings.TrimPrefix(msg.ID, "msg-")[:6])
+	if err != nil || found == nil || found.ID != msg.ID {
+		t.Fatalf("expected prefix lookup to resolve %s, got %v (%v)", msg.ID, found, err)
+	}
+
+	for _, value := range []string{"7", "33", "abc"} {
+		if _, err := ParseGUIDEntropy(value); err == nil {
+			t.Fatalf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestAgentWorkingExpiresAndKeepsStart(t *testing.T) {
+	db := openTestDB(t)
+	requireSchema(t, db)
+
+	ttl := 30 * time.Second
+	if err := SetAgentWorking(db, "dev", strPtr("msg-aaaaaaaa"), WorkingSourceAgent, 1_000_000); err != nil {
+		t.Fatalf("set working: %v", err)
+	}
+	// A refresh on the same target keeps the start; a new target restarts it.
+	if err := SetAgentWorking(db, "dev", strPtr("msg-aaaaaaaa"), WorkingSourceAgent, 1_020_000); err != nil {
+		t.Fatalf("refresh working: %v", err)
+	}
+	working, err := GetAgentWorking(db, "dev", ttl, 1_040_000)
+	if err != nil || working == nil || working.StartedAt != 1_000_000 || working.UpdatedAt != 1_020_000 {
+		t.Fatalf("expected a fresh ping started at the first set, got %+v (%v)", working, err)
+	}
+	if working, err := GetAgentWorking(db, "dev", ttl, 1_051_000); err != nil || working != nil {
+		t.Fatalf("expected the ping to expire after the ttl, got %+v (%v)", working, err)
+	}
+	if err := SetAgentWorking(db, "dev", strPtr("msg-bbbbbbbb"), WorkingSourceAgent, 1_060_000); err != nil {
+		t.Fatalf("switch working: %v", err)
+	}
+	all, err := GetWorkingAgents(db, ttl, 1_060_000)
+	if err != nil || len(all) != 1 || all[0].StartedAt != 1_060_000 || *all[0].MessageGUID != "msg-bbbbbbbb" {
+		t.Fatalf("expected the switched ping, got %+v (%v)", all, err)
+	}
+
+	if err := ClearAgentWorking(db, "dev"); err != nil {
+		t.Fatalf("clear working: %v", err)
+	}
+	if all, err := GetWorkingAgents(db, ttl, 1_060_000); err != nil || len(all) != 0 {
+		t.Fatalf("expected no pings after clear, got %+v (%v)", all, err)
+	}
+}
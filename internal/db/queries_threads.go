@@ -3,9 +3,11 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/adamavenir/fray/internal/core"
 	"github.com/adamavenir/fray/internal/types"
 )
 
@@ -20,15 +22,29 @@ type ThreadUpdates struct {
 	LastActivityAt    types.OptionalInt64
 }
 
-// CreateThread inserts a new thread.
+// ThreadNameTakenError is returned by CreateThread when the parent already
+// has a thread with the same (case-insensitive) name.
+type ThreadNameTakenError struct {
+	Existing types.Thread
+}
+
+func (e *ThreadNameTakenError) Error() string {
+	return fmt.Sprintf("thread %q already exists (%s)", e.Existing.Name, e.Existing.GUID)
+}
+
+// CreateThread inserts a new thread. The name is normalized and validated,
+// and must be unique under its parent.
 func CreateThread(db *sql.DB, thread types.Thread) (types.Thread, error) {
-	guid := thread.GUID
-	if guid == "" {
-		var err error
-		guid, err = generateUniqueGUIDForTable(db, "fray_threads", "thrd")
-		if err != nil {
-			return types.Thread{}, err
-		}
+	thread.Name = core.NormalizeThreadName(thread.Name)
+	if err := core.ValidateThreadName(thread.Name); err != nil {
+		return types.Thread{}, err
+	}
+	existing, err := GetThreadByNameFold(db, thread.Name, thread.ParentThread)
+	if err != nil {
+		return types.Thread{}, err
+	}
+	if existing != nil {
+		return types.Thread{}, &ThreadNameTakenError{Existing: *existing}
 	}
 
 	status := thread.Status
@@ -49,10 +65,13 @@ func CreateThread(db *sql.DB, thread types.Thread) (types.Thread, error) {
 		anchorHidden = 1
 	}
 
-	_, err := db.Exec(`
-		INSERT INTO fray_threads (guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, guid, thread.Name, thread.ParentThread, string(status), string(threadType), createdAt, thread.AnchorMessageGUID, anchorHidden, thread.LastActivityAt)
+	guid, err := insertWithGUID(db, "fray_threads", "thrd", thread.GUID, func(guid string) error {
+		_, err := db.Exec(`
+			INSERT INTO fray_threads (guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at, created_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, guid, thread.Name, thread.ParentThread, string(status), string(threadType), createdAt, thread.AnchorMessageGUID, anchorHidden, thread.LastActivityAt, thread.CreatedBy)
+		return err
+	})
 	if err != nil {
 		return types.Thread{}, err
 	}
@@ -117,7 +136,7 @@ func UpdateThread(db *sql.DB, guid string, updates ThreadUpdates) (*types.Thread
 // GetThread returns a thread by GUID.
 func GetThread(db *sql.DB, guid string) (*types.Thread, error) {
 	row := db.QueryRow(`
-		SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at
+		SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at, created_by, referenced_count
 		FROM fray_threads WHERE guid = ?
 	`, guid)
 
@@ -134,7 +153,7 @@ func GetThread(db *sql.DB, guid string) (*types.Thread, error) {
 // GetThreadByPrefix returns the first thread matching a GUID prefix.
 func GetThreadByPrefix(db *sql.DB, prefix string) (*types.Thread, error) {
 	rows, err := db.Query(`
-		SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at
+		SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at, created_by, referenced_count
 		FROM fray_threads
 		WHERE guid = ? OR guid LIKE ?
 		ORDER BY created_at ASC
@@ -159,12 +178,12 @@ func GetThreadByName(db *sql.DB, name string, parent *string) (*types.Thread, er
 	var row *sql.Row
 	if parent == nil {
 		row = db.QueryRow(`
-			SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at
+			SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at, created_by, referenced_count
 			FROM fray_threads WHERE name = ? AND parent_thread IS NULL
 		`, name)
 	} else {
 		row = db.QueryRow(`
-			SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at
+			SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at, created_by, referenced_count
 			FROM fray_threads WHERE name = ? AND parent_thread = ?
 		`, name, *parent)
 	}
@@ -179,10 +198,38 @@ func GetThreadByName(db *sql.DB, name string, parent *string) (*types.Thread, er
 	return &thread, nil
 }
 
+// GetThreadByNameFold is GetThreadByName ignoring case, so names created
+// before normalization still match.
+func GetThreadByNameFold(db *sql.DB, name string, parent *string) (*types.Thread, error) {
+	var row *sql.Row
+	if parent == nil {
+		row = db.QueryRow(`
+			SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at, created_by, referenced_count
+			FROM fray_threads WHERE lower(name) = lower(?) AND parent_thread IS NULL
+			ORDER BY created_at ASC LIMIT 1
+		`, name)
+	} else {
+		row = db.QueryRow(`
+			SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at, created_by, referenced_count
+			FROM fray_threads WHERE lower(name) = lower(?) AND parent_thread = ?
+			ORDER BY created_at ASC LIMIT 1
+		`, name, *parent)
+	}
+
+	thread, err := scanThread(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &thread, nil
+}
+
 // GetThreads returns threads filtered by options.
 func GetThreads(db *sql.DB, options *types.ThreadQueryOptions) ([]types.Thread, error) {
 	query := `
-		SELECT DISTINCT t.guid, t.name, t.parent_thread, t.status, t.type, t.created_at, t.anchor_message_guid, t.anchor_hidden, t.last_activity_at
+		SELECT DISTINCT t.guid, t.name, t.parent_thread, t.status, t.type, t.created_at, t.anchor_message_guid, t.anchor_hidden, t.last_activity_at, t.created_by, t.referenced_count
 		FROM fray_threads t
 	`
 	var conditions []string
@@ -206,6 +253,11 @@ func GetThreads(db *sql.DB, options *types.ThreadQueryOptions) ([]types.Thread,
 			conditions = append(conditions, "t.status = ?")
 			args = append(args, string(types.ThreadStatusOpen))
 		}
+		if options.Viewer != nil {
+			// dm threads are named "a+b"; the viewer must be one side.
+			conditions = append(conditions, "(t.type IS NULL OR t.type != ? OR instr('+' || t.name || '+', ?) > 0)")
+			args = append(args, string(types.ThreadTypeDM), "+"+*options.Viewer+"+")
+		}
 	} else {
 		conditions = append(conditions, "t.status = ?")
 		args = append(args, string(types.ThreadStatusOpen))
@@ -230,17 +282,82 @@ func GetThreads(db *sql.DB, options *types.ThreadQueryOptions) ([]types.Thread,
 }
 
 // SubscribeThread subscribes an agent to a thread.
+// An existing subscription keeps its level.
 func SubscribeThread(db *sql.DB, threadGUID, agentID string, subscribedAt int64) error {
+	return SubscribeThreadLevel(db, threadGUID, agentID, "", subscribedAt)
+}
+
+// SubscribeThreadLevel subscribes an agent to a thread at the given level.
+// An empty level keeps an existing subscription's level (new ones get all).
+func SubscribeThreadLevel(db *sql.DB, threadGUID, agentID string, level types.SubscriptionLevel, subscribedAt int64) error {
 	if subscribedAt == 0 {
 		subscribedAt = time.Now().Unix()
 	}
+	if level != "" && !IsValidSubscriptionLevel(level) {
+		return fmt.Errorf("invalid subscription level: %s (use all, mentions, or digest)", level)
+	}
 	_, err := db.Exec(`
-		INSERT OR REPLACE INTO fray_thread_subscriptions (thread_guid, agent_id, subscribed_at)
-		VALUES (?, ?, ?)
-	`, threadGUID, agentID, subscribedAt)
+		INSERT INTO fray_thread_subscriptions (thread_guid, agent_id, subscribed_at, level)
+		VALUES (?, ?, ?, COALESCE(NULLIF(?, ''), 'all'))
+		ON CONFLICT(thread_guid, agent_id) DO UPDATE SET
+			subscribed_at = excluded.subscribed_at,
+			level = COALESCE(NULLIF(?, ''), level)
+	`, threadGUID, agentID, subscribedAt, string(level), string(level))
 	return err
 }
 
+// IsValidSubscriptionLevel reports whether level is all, mentions, or digest.
+func IsValidSubscriptionLevel(level types.SubscriptionLevel) bool {
+	switch level {
+	case types.SubscriptionAll, types.SubscriptionMentions, types.SubscriptionDigest:
+		return true
+	}
+	return false
+}
+
+// GetThreadSubscription returns an agent's subscription to a thread, or nil.
+func GetThreadSubscription(db *sql.DB, threadGUID, agentID string) (*types.ThreadSubscription, error) {
+	var sub types.ThreadSubscription
+	var level string
+	err := db.QueryRow(`
+		SELECT thread_guid, agent_id, subscribed_at, level
+		FROM fray_thread_subscriptions WHERE thread_guid = ? AND agent_id = ?
+	`, threadGUID, agentID).Scan(&sub.ThreadGUID, &sub.AgentID, &sub.SubscribedAt, &level)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sub.Level = types.SubscriptionLevel(level)
+	return &sub, nil
+}
+
+// GetAgentSubscriptions returns an agent's thread subscriptions, oldest first.
+func GetAgentSubscriptions(db *sql.DB, agentID string) ([]types.ThreadSubscription, error) {
+	rows, err := db.Query(`
+		SELECT thread_guid, agent_id, subscribed_at, level
+		FROM fray_thread_subscriptions WHERE agent_id = ?
+		ORDER BY subscribed_at ASC, thread_guid ASC
+	`, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []types.ThreadSubscription
+	for rows.Next() {
+		var sub types.ThreadSubscription
+		var level string
+		if err := rows.Scan(&sub.ThreadGUID, &sub.AgentID, &sub.SubscribedAt, &level); err != nil {
+			return nil, err
+		}
+		sub.Level = types.SubscriptionLevel(level)
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
 // UnsubscribeThread unsubscribes an agent from a thread.
 func UnsubscribeThread(db *sql.DB, threadGUID, agentID string) error {
 	_, err := db.Exec(`
@@ -285,6 +402,69 @@ func GetThreadMessages(db *sql.DB, threadGUID string) ([]types.Message, error) {
 	return scanMessagesWithReactions(db, rows)
 }
 
+// GetThreadStats counts a thread's participants (by base name, busiest
+// first), visible messages, subscribers, and pins. Events and archived
+// messages are not counted.
+func GetThreadStats(db *sql.DB, threadGUID string) (*types.ThreadStats, error) {
+	thread, err := GetThread(db, threadGUID)
+	if err != nil || thread == nil {
+		return nil, err
+	}
+	stats := &types.ThreadStats{CreatedAt: thread.CreatedAt, Owner: thread.OwnerAgent}
+	if stats.Owner == nil {
+		stats.Owner = thread.CreatedBy
+	}
+
+	if err := db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM fray_thread_subscriptions WHERE thread_guid = ?),
+			(SELECT COUNT(*) FROM fray_message_pins WHERE thread_guid = ?)
+	`, threadGUID, threadGUID).Scan(&stats.Subscribers, &stats.Pins); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT m.from_agent, COUNT(DISTINCT m.guid) FROM fray_messages m
+		LEFT JOIN fray_thread_messages tm ON tm.message_guid = m.guid AND tm.thread_guid = ?
+		WHERE (m.home = ? OR tm.thread_guid = ?)
+			AND COALESCE(m.type, '') != 'event' AND m.archived_at IS NULL
+		GROUP BY m.from_agent
+	`, threadGUID, threadGUID, threadGUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var agentID string
+		var count int
+		if err := rows.Scan(&agentID, &count); err != nil {
+			return nil, err
+		}
+		base := agentID
+		if parsed, err := core.ParseAgentID(agentID); err == nil {
+			base = parsed.Base
+		}
+		counts[base] += count
+		stats.Messages += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for agentID, count := range counts {
+		stats.Participants = append(stats.Participants, types.ThreadParticipant{AgentID: agentID, Messages: count})
+	}
+	sort.Slice(stats.Participants, func(i, j int) bool {
+		if stats.Participants[i].Messages != stats.Participants[j].Messages {
+			return stats.Participants[i].Messages > stats.Participants[j].Messages
+		}
+		return stats.Participants[i].AgentID < stats.Participants[j].AgentID
+	})
+	return stats, nil
+}
+
 // IsMessageInThread reports whether a message is in a thread (home or membership).
 func IsMessageInThread(db *sql.DB, threadGUID, messageGUID string) (bool, error) {
 	row := db.QueryRow(`
@@ -305,7 +485,7 @@ func IsMessageInThread(db *sql.DB, threadGUID, messageGUID string) (bool, error)
 
 func scanThread(scanner interface{ Scan(dest ...any) error }) (types.Thread, error) {
 	var row threadRow
-	if err := scanner.Scan(&row.GUID, &row.Name, &row.ParentThread, &row.Status, &row.Type, &row.CreatedAt, &row.AnchorMessageGUID, &row.AnchorHidden, &row.LastActivityAt); err != nil {
+	if err := scanner.Scan(&row.GUID, &row.Name, &row.ParentThread, &row.Status, &row.Type, &row.CreatedAt, &row.AnchorMessageGUID, &row.AnchorHidden, &row.LastActivityAt, &row.CreatedBy, &row.ReferencedCount); err != nil {
 		return types.Thread{}, err
 	}
 	return row.toThread(), nil
@@ -336,6 +516,8 @@ type threadRow struct {
 	AnchorMessageGUID sql.NullString
 	AnchorHidden      sql.NullInt64
 	LastActivityAt    sql.NullInt64
+	CreatedBy         sql.NullString
+	ReferencedCount   int
 }
 
 func (row threadRow) toThread() types.Thread {
@@ -348,12 +530,14 @@ func (row threadRow) toThread() types.Thread {
 		threadType = types.ThreadType(row.Type.String)
 	}
 	thread := types.Thread{
-		GUID:         row.GUID,
-		Name:         row.Name,
-		ParentThread: nullStringPtr(row.ParentThread),
-		Status:       status,
-		Type:         threadType,
-		CreatedAt:    row.CreatedAt,
+		GUID:            row.GUID,
+		Name:            row.Name,
+		ParentThread:    nullStringPtr(row.ParentThread),
+		Status:          status,
+		Type:            threadType,
+		CreatedAt:       row.CreatedAt,
+		CreatedBy:       nullStringPtr(row.CreatedBy),
+		ReferencedCount: row.ReferencedCount,
 	}
 	if row.AnchorMessageGUID.Valid {
 		thread.AnchorMessageGUID = &row.AnchorMessageGUID.String
@@ -402,6 +586,43 @@ func IsMessagePinned(db *sql.DB, messageGUID, threadGUID string) (bool, error) {
 	return true, nil
 }
 
+// GetMessagePinThreads returns GUIDs of threads where a message is pinned.
+func GetMessagePinThreads(db *sql.DB, messageGUID string) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT thread_guid FROM fray_message_pins WHERE message_guid = ?
+		ORDER BY pinned_at ASC
+	`, messageGUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threadGUIDs []string
+	for rows.Next() {
+		var guid string
+		if err := rows.Scan(&guid); err != nil {
+			return nil, err
+		}
+		threadGUIDs = append(threadGUIDs, guid)
+	}
+	return threadGUIDs, rows.Err()
+}
+
+// GetThreadsByAnchor returns threads that use a message as their anchor.
+func GetThreadsByAnchor(db *sql.DB, messageGUID string) ([]types.Thread, error) {
+	rows, err := db.Query(`
+		SELECT guid, name, parent_thread, status, type, created_at, anchor_message_guid, anchor_hidden, last_activity_at, created_by, referenced_count
+		FROM fray_threads WHERE anchor_message_guid = ?
+		ORDER BY created_at ASC
+	`, messageGUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanThreads(rows)
+}
+
 // GetPinnedMessages returns messages pinned in a thread.
 func GetPinnedMessages(db *sql.DB, threadGUID string) ([]types.Message, error) {
 	rows, err := db.Query(fmt.Sprintf(`
@@ -484,7 +705,7 @@ func IsThreadPinned(db *sql.DB, threadGUID string) (bool, error) {
 // GetPinnedThreads returns all pinned threads.
 func GetPinnedThreads(db *sql.DB) ([]types.Thread, error) {
 	rows, err := db.Query(`
-		SELECT t.guid, t.name, t.parent_thread, t.status, t.type, t.created_at, t.anchor_message_guid, t.anchor_hidden, t.last_activity_at
+		SELECT t.guid, t.name, t.parent_thread, t.status, t.type, t.created_at, t.anchor_message_guid, t.anchor_hidden, t.last_activity_at, t.created_by, t.referenced_count
 		FROM fray_threads t
 		INNER JOIN fray_thread_pins p ON p.thread_guid = t.guid
 		ORDER BY p.pinned_at ASC
@@ -539,7 +760,7 @@ func IsThreadMuted(db *sql.DB, threadGUID, agentID string) (bool, error) {
 func GetMutedThreads(db *sql.DB, agentID string) ([]types.Thread, error) {
 	now := time.Now().Unix()
 	rows, err := db.Query(`
-		SELECT t.guid, t.name, t.parent_thread, t.status, t.type, t.created_at, t.anchor_message_guid, t.anchor_hidden, t.last_activity_at
+		SELECT t.guid, t.name, t.parent_thread, t.status, t.type, t.created_at, t.anchor_message_guid, t.anchor_hidden, t.last_activity_at, t.created_by, t.referenced_count
 		FROM fray_threads t
 		INNER JOIN fray_thread_mutes m ON m.thread_guid = t.guid
 		WHERE m.agent_id = ?
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// WorkingTTLConfigKey is how many seconds a working ping stays fresh without
+// a refresh.
+const WorkingTTLConfigKey = "working_ttl_seconds"
+
+// DefaultWorkingTTL applies when working_ttl_seconds is unset or invalid.
+const DefaultWorkingTTL = 2 * time.Minute
+
+// Working ping sources.
+const (
+	WorkingSourceAgent  = "agent"
+	WorkingSourceDaemon = "daemon"
+)
+
+// ParseWorkingTTL parses a working_ttl_seconds value.
+func ParseWorkingTTL(value string) (time.Duration, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number of seconds", WorkingTTLConfigKey)
+	}
+	return time.Duration(n) * time.Second, nil
+}
+
+// WorkingTTL returns how long a working ping stays fresh.
+func WorkingTTL(db *sql.DB) time.Duration {
+	value, err := GetConfig(db, WorkingTTLConfigKey)
+	if err != nil || value == "" {
+		return DefaultWorkingTTL
+	}
+	ttl, err := ParseWorkingTTL(value)
+	if err != nil {
+		return DefaultWorkingTTL
+	}
+	return ttl
+}
+
+// SetAgentWorking records or refreshes an agent's working ping at now (unix
+// millis). The start time is kept while the target stays the same.
+func SetAgentWorking(db *sql.DB, agentID string, messageGUID *string, source string, now int64) error {
+	_, err := db.Exec(`
+		INSERT INTO fray_agent_working (agent_id, message_guid, source, started_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(agent_id) DO UPDATE SET
+			started_at = CASE WHEN fray_agent_working.message_guid IS excluded.message_guid
+				THEN fray_agent_working.started_at ELSE excluded.started_at END,
+			message_guid = excluded.message_guid,
+			source = excluded.source,
+			updated_at = excluded.updated_at
+	`, agentID, messageGUID, source, now, now)
+	return err
+}
+
+// ClearAgentWorking removes an agent's working ping.
+func ClearAgentWorking(db *sql.DB, agentID string) error {
+	_, err := db.Exec(`DELETE FROM fray_agent_working WHERE agent_id = ?`, agentID)
+	return err
+}
+
+// GetAgentWorking returns an agent's working ping if it was refreshed within
+// ttl of now (unix millis), or nil.
+func GetAgentWorking(db *sql.DB, agentID string, ttl time.Duration, now int64) (*types.AgentWorking, error) {
+	row := db.QueryRow(`
+		SELECT agent_id, message_guid, source, started_at, updated_at
+		FROM fray_agent_working
+		WHERE agent_id = ? AND updated_at > ?
+	`, agentID, now-ttl.Milliseconds())
+	working, err := scanAgentWorking(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &working, nil
+}
+
+// GetWorkingAgents returns the fresh working pings, oldest start first.
+func GetWorkingAgents(db *sql.DB, ttl time.Duration, now int64) ([]types.AgentWorking, error) {
+	rows, err := db.Query(`
+		SELECT agent_id, message_guid, source, started_at, updated_at
+		FROM fray_agent_working
+		WHERE updated_at > ?
+		ORDER BY started_at, agent_id
+	`, now-ttl.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var working []types.AgentWorking
+	for rows.Next() {
+		entry, err := scanAgentWorking(rows)
+		if err != nil {
+			return nil, err
+		}
+		working = append(working, entry)
+	}
+	return working, rows.Err()
+}
+
+func scanAgentWorking(scanner interface{ Scan(dest ...any) error }) (types.AgentWorking, error) {
+	var working types.AgentWorking
+	var messageGUID sql.NullString
+	if err := scanner.Scan(&working.AgentID, &messageGUID, &working.Source, &working.StartedAt, &working.UpdatedAt); err != nil {
+		return types.AgentWorking{}, err
+	}
+	if messageGUID.Valid {
+		working.MessageGUID = &messageGUID.String
+	}
+	return working, nil
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -147,6 +148,61 @@ func GetMessagesWithReactionsFrom(db *sql.DB, agentID string, limit int) ([]Reac
 	return results, rows.Err()
 }
 
+// GetReactionsSince returns reactions made after sinceMillis, oldest first,
+// with the reacted-to message's author, body, and home.
+func GetReactionsSince(db *sql.DB, sinceMillis int64) ([]ReactionQueryResult, error) {
+	rows, err := db.Query(`
+		SELECT r.message_guid, r.emoji, r.reacted_at, m.from_agent, r.agent_id, m.body, m.home
+		FROM fray_reactions r
+		INNER JOIN fray_messages m ON m.guid = r.message_guid
+		WHERE r.reacted_at > ?
+		ORDER BY r.reacted_at ASC
+	`, sinceMillis)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []ReactionQueryResult
+	for rows.Next() {
+		var r ReactionQueryResult
+		if err := rows.Scan(&r.MessageGUID, &r.Emoji, &r.ReactedAt, &r.FromAgent, &r.ReactedBy, &r.Body, &r.Home); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// GetReactionsToAgentSince returns reactions left after sinceMillis on
+// messages by agentID or its sub-agents, oldest first. Reactions by the
+// agent's own family are skipped, like self-mentions.
+func GetReactionsToAgentSince(db *sql.DB, agentID string, sinceMillis int64) ([]ReactionQueryResult, error) {
+	rows, err := db.Query(`
+		SELECT r.message_guid, r.emoji, r.reacted_at, m.from_agent, r.agent_id, m.body, m.home
+		FROM fray_reactions r
+		INNER JOIN fray_messages m ON m.guid = r.message_guid
+		WHERE (m.from_agent = ? OR m.from_agent LIKE ?)
+		  AND r.agent_id != ? AND r.agent_id NOT LIKE ?
+		  AND r.reacted_at > ?
+		ORDER BY r.reacted_at ASC, r.message_guid ASC
+	`, agentID, agentID+".%", agentID, agentID+".%", sinceMillis)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []ReactionQueryResult
+	for rows.Next() {
+		var r ReactionQueryResult
+		if err := rows.Scan(&r.MessageGUID, &r.Emoji, &r.ReactedAt, &r.FromAgent, &r.ReactedBy, &r.Body, &r.Home); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
 // ReactionQueryResult holds info about a reaction for display.
 type ReactionQueryResult struct {
 	MessageGUID string
@@ -174,6 +230,32 @@ func InsertReactionNow(db *sql.DB, messageGUID, agentID, emoji string) (int64, e
 	return reactedAt, err
 }
 
+// RemoveLatestReaction deletes an agent's most recent reaction with the given emoji.
+// Returns the removed reaction's timestamp, or ok=false if there was none.
+func RemoveLatestReaction(db *sql.DB, messageGUID, agentID, emoji string) (int64, bool, error) {
+	var reactedAt int64
+	err := db.QueryRow(`
+		SELECT reacted_at FROM fray_reactions
+		WHERE message_guid = ? AND agent_id = ? AND emoji = ?
+		ORDER BY reacted_at DESC
+		LIMIT 1
+	`, messageGUID, agentID, emoji).Scan(&reactedAt)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	if _, err := db.Exec(`
+		DELETE FROM fray_reactions
+		WHERE message_guid = ? AND agent_id = ? AND emoji = ? AND reacted_at = ?
+	`, messageGUID, agentID, emoji, reactedAt); err != nil {
+		return 0, false, err
+	}
+	return reactedAt, true, nil
+}
+
 // Legacy helpers for backward compatibility with old JSON reactions format.
 // These will be removed once migration is complete.
 
@@ -213,6 +295,44 @@ func dedupeNonEmpty(values []string) []string {
 	return out
 }
 
+// ReactionSet is the JSONL form of a message's inline reactions, keyed by
+// emoji. It decodes both the current {agent_id, reacted_at} entries and the
+// legacy shape of bare agent IDs, which carry no timestamp (ReactedAt 0).
+type ReactionSet map[string][]types.ReactionEntry
+
+// UnmarshalJSON accepts emoji -> [entry | agent ID] in any mix.
+func (s *ReactionSet) UnmarshalJSON(data []byte) error {
+	var raw map[string][]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	out := make(ReactionSet, len(raw))
+	for emoji, items := range raw {
+		emoji = strings.TrimSpace(emoji)
+		if emoji == "" {
+			continue
+		}
+		seen := map[types.ReactionEntry]bool{}
+		for _, item := range items {
+			var entry types.ReactionEntry
+			var agentID string
+			if err := json.Unmarshal(item, &agentID); err == nil {
+				entry.AgentID = agentID
+			} else if err := json.Unmarshal(item, &entry); err != nil {
+				return err
+			}
+			entry.AgentID = strings.TrimSpace(entry.AgentID)
+			if entry.AgentID == "" || seen[entry] {
+				continue
+			}
+			seen[entry] = true
+			out[emoji] = append(out[emoji], entry)
+		}
+	}
+	*s = out
+	return nil
+}
+
 // ConvertLegacyReactions converts old map[string][]string format to new format.
 // Since legacy format has no timestamps, uses 0 as placeholder.
 func ConvertLegacyReactions(legacy map[string][]string) map[string][]types.ReactionEntry {
@@ -248,3 +368,39 @@ func ConvertToLegacyReactions(reactions map[string][]types.ReactionEntry) map[st
 	}
 	return result
 }
+
+// inlineReactionRecords turns reactions carried on message records into
+// reaction records, skipping any agent/emoji pair that already has an explicit
+// reaction record. Entries without a timestamp get the message's.
+func inlineReactionRecords(messages []MessageJSONLRecord, explicit []ReactionJSONLRecord) []ReactionJSONLRecord {
+	type reactionKey struct{ message, agent, emoji string }
+	covered := make(map[reactionKey]bool, len(explicit))
+	for _, r := range explicit {
+		covered[reactionKey{r.MessageGUID, r.AgentID, r.Emoji}] = true
+	}
+
+	var records []ReactionJSONLRecord
+	for _, message := range messages {
+		for emoji, entries := range message.Reactions {
+			for _, entry := range entries {
+				key := reactionKey{message.ID, entry.AgentID, emoji}
+				if covered[key] {
+					continue
+				}
+				covered[key] = true
+				reactedAt := entry.ReactedAt
+				if reactedAt == 0 {
+					reactedAt = message.TS
+				}
+				records = append(records, ReactionJSONLRecord{
+					Type:        "reaction",
+					MessageGUID: message.ID,
+					AgentID:     entry.AgentID,
+					Emoji:       emoji,
+					ReactedAt:   reactedAt,
+				})
+			}
+		}
+	}
+	return records
+}
@@ -0,0 +1,240 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+)
+
+const (
+	rebuildLockFile  = "rebuild.lock"
+	rebuildStatsFile = "rebuild.json"
+)
+
+// ErrRebuildInProgress is returned when another process is rebuilding the
+// cache and did not finish within RebuildWaitTimeout.
+var ErrRebuildInProgress = errors.New("rebuild in progress")
+
+// ErrRebuildInterrupted is returned when a rebuild lock was left behind by a
+// process that no longer exists; the cache may be half-built.
+var ErrRebuildInterrupted = errors.New("interrupted rebuild")
+
+// RebuildWaitTimeout is how long commands wait for another process's rebuild
+// before giving up.
+var RebuildWaitTimeout = 5 * time.Second
+
+// rebuildProgressEvery is how many rows a rebuild writes between progress lines.
+const rebuildProgressEvery = 5000
+
+// RebuildLock is the content of .fray/local/rebuild.lock.
+type RebuildLock struct {
+	PID       int   `json:"pid"`
+	StartedAt int64 `json:"started_at"`
+}
+
+// Active reports whether the process holding the lock is still running. A
+// lock without a pid (cut off mid-write) never is.
+func (l RebuildLock) Active() bool {
+	return core.ProcessAlive(l.PID)
+}
+
+// RebuildStats is the record of the last completed rebuild, kept in
+// .fray/local/rebuild.json.
+type RebuildStats struct {
+	FinishedAt int64          `json:"finished_at"`
+	DurationMs int64          `json:"duration_ms"`
+	Rows       int            `json:"rows"`
+	Tables     map[string]int `json:"tables"`
+}
+
+// RebuildOptions configures RebuildDatabase.
+type RebuildOptions struct {
+	// Progress receives a line every few thousand rows; nil stays quiet.
+	Progress io.Writer
+}
+
+func rebuildLockPath(projectPath string) string {
+	return filepath.Join(resolveFrayDir(projectPath), localDir, rebuildLockFile)
+}
+
+// ReadRebuildLock returns the current rebuild lock, or nil when there is none.
+func ReadRebuildLock(projectPath string) (*RebuildLock, error) {
+	data, err := os.ReadFile(rebuildLockPath(projectPath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var lock RebuildLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		// A lock cut off mid-write belongs to a process that died writing it.
+		return &RebuildLock{}, nil
+	}
+	return &lock, nil
+}
+
+// ClearRebuildLock removes the rebuild lock.
+func ClearRebuildLock(projectPath string) error {
+	if err := os.Remove(rebuildLockPath(projectPath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// WaitForRebuild blocks while another process rebuilds the cache. It returns
+// ErrRebuildInProgress if the rebuild outlasts RebuildWaitTimeout, and
+// ErrRebuildInterrupted if the lock was left by a dead process.
+func WaitForRebuild(projectPath string) error {
+	deadline := time.Now().Add(RebuildWaitTimeout)
+	for {
+		lock, err := ReadRebuildLock(projectPath)
+		if err != nil {
+			return err
+		}
+		if lock == nil {
+			return nil
+		}
+		if !lock.Active() {
+			return fmt.Errorf("%w: %s was left by pid %d; run 'fray doctor --fix' to clear it and rebuild",
+				ErrRebuildInterrupted, filepath.Join(localDir, rebuildLockFile), lock.PID)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w (pid %d, started %s ago); try again shortly",
+				ErrRebuildInProgress, lock.PID, time.Since(time.Unix(lock.StartedAt, 0)).Round(time.Second))
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// acquireRebuildLock takes the rebuild lock, waiting for a live holder.
+func acquireRebuildLock(projectPath string) (func(), error) {
+	path := rebuildLockPath(projectPath)
+	if err := ensureDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(RebuildLock{PID: os.Getpid(), StartedAt: time.Now().Unix()})
+	if err != nil {
+		return nil, err
+	}
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, writeErr := f.Write(data)
+			closeErr := f.Close()
+			if writeErr != nil || closeErr != nil {
+				_ = os.Remove(path)
+				return nil, errors.Join(writeErr, closeErr)
+			}
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if err := WaitForRebuild(projectPath); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ReadRebuildStats returns the stats of the last completed rebuild, or nil.
+func ReadRebuildStats(projectPath string) (*RebuildStats, error) {
+	data, err := os.ReadFile(filepath.Join(resolveFrayDir(projectPath), localDir, rebuildStatsFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var stats RebuildStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func writeRebuildStats(projectPath string, stats RebuildStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(resolveFrayDir(projectPath), localDir, rebuildStatsFile)
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+type txBeginner interface {
+	Begin() (*sql.Tx, error)
+}
+
+// rebuildWriter runs rebuild inserts in one transaction per table batch,
+// counting rows and reporting progress.
+type rebuildWriter struct {
+	db       DBTX
+	tx       *sql.Tx
+	table    string
+	rows     int
+	tables   map[string]int
+	progress io.Writer
+}
+
+func newRebuildWriter(db DBTX, progress io.Writer) *rebuildWriter {
+	return &rebuildWriter{db: db, tables: make(map[string]int), progress: progress}
+}
+
+// batch commits the previous table's rows and starts a batch for table.
+func (w *rebuildWriter) batch(table string) error {
+	if err := w.commit(); err != nil {
+		return err
+	}
+	w.table = table
+	if beginner, ok := w.db.(txBeginner); ok {
+		tx, err := beginner.Begin()
+		if err != nil {
+			return err
+		}
+		w.tx = tx
+	}
+	return nil
+}
+
+func (w *rebuildWriter) Exec(query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	var err error
+	if w.tx != nil {
+		result, err = w.tx.Exec(query, args...)
+	} else {
+		result, err = w.db.Exec(query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.rows++
+	w.tables[w.table]++
+	if w.progress != nil && w.rows%rebuildProgressEvery == 0 {
+		fmt.Fprintf(w.progress, "rebuild: %d rows written (%s)\n", w.rows, w.table)
+	}
+	return result, nil
+}
+
+func (w *rebuildWriter) commit() error {
+	if w.tx == nil {
+		return nil
+	}
+	tx := w.tx
+	w.tx = nil
+	return tx.Commit()
+}
+
+func (w *rebuildWriter) rollback() {
+	if w.tx != nil {
+		_ = w.tx.Rollback()
+		w.tx = nil
+	}
+}
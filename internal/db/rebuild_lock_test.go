@@ -0,0 +1,142 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/testutil"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// deadPID is above the kernel's pid limit, so no process can hold it.
+const deadPID = 1 << 30
+
+func rebuildTestProject(t *testing.T) core.Project {
+	t.Helper()
+	project := testutil.NewProject(t)
+	for i := 0; i < 3; i++ {
+		if err := AppendMessage(project.Root, types.Message{ID: "msg-" + strings.Repeat(string(rune('a'+i)), 8), TS: int64(i + 1), FromAgent: "alice", Body: "hi", Type: types.MessageTypeAgent}); err != nil {
+			t.Fatalf("append message: %v", err)
+		}
+	}
+	return project.Project
+}
+
+func writeRebuildLock(t *testing.T, project core.Project, pid int) {
+	t.Helper()
+	path := rebuildLockPath(project.DBPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	data, _ := json.Marshal(RebuildLock{PID: pid, StartedAt: time.Now().Unix()})
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+}
+
+func TestOpenDatabaseWaitsForRebuildLock(t *testing.T) {
+	project := rebuildTestProject(t)
+	prev := RebuildWaitTimeout
+	t.Cleanup(func() { RebuildWaitTimeout = prev })
+
+	// A live holder that never finishes makes the open give up.
+	writeRebuildLock(t, project, os.Getpid())
+	RebuildWaitTimeout = 200 * time.Millisecond
+	if conn, err := OpenDatabase(project); !errors.Is(err, ErrRebuildInProgress) {
+		if conn != nil {
+			conn.Close()
+		}
+		t.Fatalf("expected ErrRebuildInProgress, got %v", err)
+	}
+
+	// A holder that finishes within the timeout is waited out.
+	RebuildWaitTimeout = 5 * time.Second
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		_ = ClearRebuildLock(project.DBPath)
+	}()
+	conn, err := OpenDatabase(project)
+	if err != nil {
+		t.Fatalf("expected open to wait for the rebuild, got %v", err)
+	}
+	defer conn.Close()
+	messages, err := GetMessages(conn, &types.MessageQueryOptions{})
+	if err != nil || len(messages) != 3 {
+		t.Fatalf("expected rebuilt messages, got %d (%v)", len(messages), err)
+	}
+}
+
+func TestInterruptedRebuildLockBlocksOpen(t *testing.T) {
+	project := rebuildTestProject(t)
+	writeRebuildLock(t, project, deadPID)
+
+	_, err := OpenDatabase(project)
+	if !errors.Is(err, ErrRebuildInterrupted) || !strings.Contains(err.Error(), "fray doctor --fix") {
+		t.Fatalf("expected interrupted rebuild error pointing at doctor, got %v", err)
+	}
+	lock, err := ReadRebuildLock(project.DBPath)
+	if err != nil || lock == nil || lock.Active() {
+		t.Fatalf("expected a stale lock to remain, got %+v (%v)", lock, err)
+	}
+}
+
+func TestCorruptRebuildLockCountsAsInterrupted(t *testing.T) {
+	project := rebuildTestProject(t)
+	for _, content := range []string{"", `{"pid":`, `{"pid":0,"started_at":1}`} {
+		path := rebuildLockPath(project.DBPath)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write lock: %v", err)
+		}
+
+		start := time.Now()
+		if err := WaitForRebuild(project.DBPath); !errors.Is(err, ErrRebuildInterrupted) {
+			t.Fatalf("lock %q: expected an interrupted rebuild, got %v", content, err)
+		}
+		if waited := time.Since(start); waited > time.Second {
+			t.Fatalf("lock %q: expected no wait for a dead holder, waited %s", content, waited)
+		}
+	}
+}
+
+func TestRebuildReportsProgressAndStats(t *testing.T) {
+	project := rebuildTestProject(t)
+	conn, err := OpenDatabase(project)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer conn.Close()
+
+	var progress bytes.Buffer
+	for i := 0; i < rebuildProgressEvery; i++ {
+		if err := AppendReaction(project.DBPath, "msg-aaaaaaaa", "bob", "👍", int64(i)); err != nil {
+			t.Fatalf("append reaction: %v", err)
+		}
+	}
+	if err := RebuildDatabase(conn, project.DBPath, RebuildOptions{Progress: &progress}); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if !strings.Contains(progress.String(), "rows written (reactions)") {
+		t.Fatalf("expected a progress line, got %q", progress.String())
+	}
+	if lock, _ := ReadRebuildLock(project.DBPath); lock != nil {
+		t.Fatalf("expected the lock to be released, got %+v", lock)
+	}
+
+	stats, err := ReadRebuildStats(project.DBPath)
+	if err != nil || stats == nil {
+		t.Fatalf("expected rebuild stats, got %v", err)
+	}
+	if stats.Tables["messages"] != 3 || stats.Tables["reactions"] != rebuildProgressEvery || stats.Rows < 3+rebuildProgressEvery {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// MaxMoveRepliesConfigKey caps how many replies mv --with-replies will carry.
+const MaxMoveRepliesConfigKey = "mv_max_replies"
+
+// DefaultMaxMoveReplies applies when mv_max_replies is unset.
+const DefaultMaxMoveReplies = 1000
+
+// ParseMaxMoveReplies parses an mv_max_replies value.
+func ParseMaxMoveReplies(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", MaxMoveRepliesConfigKey)
+	}
+	return n, nil
+}
+
+// replyClosureCTE walks reply_to edges breadth-first from the root. UNION
+// (not UNION ALL) drops rows already produced, so reply cycles terminate.
+const replyClosureCTE = `
+	WITH RECURSIVE closure(guid) AS (
+		SELECT guid FROM fray_messages WHERE reply_to = ?
+		UNION
+		SELECT m.guid FROM fray_messages m JOIN closure c ON m.reply_to = c.guid
+	)`
+
+// CountReplyClosure returns how many messages reply to messageID directly or
+// transitively, not counting messageID itself.
+func CountReplyClosure(db *sql.DB, messageID string) (int, error) {
+	var count int
+	err := db.QueryRow(replyClosureCTE+`
+		SELECT COUNT(*) FROM closure WHERE guid != ?
+	`, messageID, messageID).Scan(&count)
+	return count, err
+}
+
+// GetReplyClosure returns every message that replies to messageID directly or
+// transitively, oldest first. messageID itself is excluded even when a reply
+// cycle leads back to it.
+func GetReplyClosure(db *sql.DB, messageID string) ([]types.Message, error) {
+	rows, err := db.Query(fmt.Sprintf(replyClosureCTE+`
+		SELECT %s FROM fray_messages
+		WHERE guid IN (SELECT guid FROM closure) AND guid != ?
+		ORDER BY ts ASC, guid ASC
+	`, messageColumns), messageID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMessagesWithReactions(db, rows)
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// ReplyTargetReason says why a message can't be replied to.
+type ReplyTargetReason string
+
+const (
+	ReplyTargetMissing   ReplyTargetReason = "missing"
+	ReplyTargetDeleted   ReplyTargetReason = "deleted"
+	ReplyTargetArchived  ReplyTargetReason = "archived"
+	ReplyTargetCrossHome ReplyTargetReason = "cross_home"
+)
+
+// ReplyTargetError reports an invalid reply target. Home is the target's
+// home for cross-home replies.
+type ReplyTargetError struct {
+	MessageGUID string
+	Reason      ReplyTargetReason
+	Home        string
+}
+
+func (e *ReplyTargetError) Error() string {
+	switch e.Reason {
+	case ReplyTargetMissing:
+		return fmt.Sprintf("reply target not found: %s", e.MessageGUID)
+	case ReplyTargetDeleted:
+		return fmt.Sprintf("cannot reply to %s: it was deleted", e.MessageGUID)
+	case ReplyTargetArchived:
+		return fmt.Sprintf("cannot reply to %s: it was archived", e.MessageGUID)
+	default:
+		return fmt.Sprintf("cannot reply to %s from another home (it lives in %s)", e.MessageGUID, e.Home)
+	}
+}
+
+// CheckReplyTarget validates a reply to replyTo posted into home ("" is the
+// room). The target must exist, must not be deleted or archived, and must
+// live in home or have been added to it, unless allowCrossHome is set.
+// Returns the target on success and a *ReplyTargetError otherwise.
+func CheckReplyTarget(db *sql.DB, replyTo, home string, allowCrossHome bool) (*types.Message, error) {
+	target, err := GetMessage(db, replyTo)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, &ReplyTargetError{MessageGUID: replyTo, Reason: ReplyTargetMissing}
+	}
+	if target.ArchivedAt != nil {
+		// fray rm archives the message and blanks its body; prune and
+		// retention archive it as-is.
+		reason := ReplyTargetArchived
+		if target.Body == "[deleted]" {
+			reason = ReplyTargetDeleted
+		}
+		return nil, &ReplyTargetError{MessageGUID: target.ID, Reason: reason}
+	}
+	if home == "" {
+		home = "room"
+	}
+	if allowCrossHome || target.Home == home {
+		return target, nil
+	}
+	if home != "room" {
+		inThread, err := IsMessageInThread(db, home, target.ID)
+		if err != nil {
+			return nil, err
+		}
+		if inThread {
+			return target, nil
+		}
+	}
+	return nil, &ReplyTargetError{MessageGUID: target.ID, Reason: ReplyTargetCrossHome, Home: target.Home}
+}
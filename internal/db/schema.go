@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"os"
 
 	"github.com/adamavenir/fray/internal/core"
 )
@@ -23,7 +24,12 @@ CREATE TABLE IF NOT EXISTS fray_agents (
   presence TEXT DEFAULT 'offline',     -- active, spawning, idle, error, offline
   mention_watermark TEXT,              -- last processed mention msg_id
   last_heartbeat INTEGER,              -- last silent checkin timestamp (ms)
-  last_session_id TEXT                 -- Claude Code session UUID for --resume
+  last_session_id TEXT,                -- Claude Code session UUID for --resume
+  parent_agent TEXT,                   -- parent of a sub-agent (alice for alice.1)
+  last_known_input INTEGER,            -- input tokens of the last session with reported usage
+  last_known_output INTEGER,           -- output tokens of the last session with reported usage
+  tokens_updated_at INTEGER,           -- when token usage was last recorded
+  reaction_watermark INTEGER           -- reacted_at (ms) of the last reaction acked in the inbox
 );
 
 -- Agent sessions (daemon-managed)
@@ -58,7 +64,11 @@ CREATE TABLE IF NOT EXISTS fray_messages (
   quote_message_guid TEXT,             -- quoted message guid for inline quotes
   edited_at INTEGER,                   -- unix timestamp of last edit
   archived_at INTEGER,                 -- unix timestamp of archival
-  reactions TEXT NOT NULL DEFAULT '{}' -- JSON object of reactions
+  reactions TEXT NOT NULL DEFAULT '{}', -- JSON object of reactions
+  important INTEGER NOT NULL DEFAULT 0, -- 1 when flagged as high-signal
+  standup INTEGER NOT NULL DEFAULT 0,   -- 1 when posted as a standup report
+  commits TEXT,                        -- JSON array of attached commit metadata
+  thread_refs TEXT                     -- JSON array of cited thread guids
 );
 
 CREATE INDEX IF NOT EXISTS idx_fray_messages_ts ON fray_messages(ts);
@@ -94,6 +104,8 @@ CREATE TABLE IF NOT EXISTS fray_threads (
   anchor_message_guid TEXT,
   anchor_hidden INTEGER NOT NULL DEFAULT 0,
   last_activity_at INTEGER,
+  created_by TEXT,
+  referenced_count INTEGER NOT NULL DEFAULT 0,
   FOREIGN KEY (parent_thread) REFERENCES fray_threads(guid)
 );
 
@@ -107,6 +119,8 @@ CREATE TABLE IF NOT EXISTS fray_thread_subscriptions (
   thread_guid TEXT NOT NULL,
   agent_id TEXT NOT NULL,
   subscribed_at INTEGER NOT NULL,
+  level TEXT NOT NULL DEFAULT 'all',  -- 'all' | 'mentions' | 'digest'
+
   PRIMARY KEY (thread_guid, agent_id),
   FOREIGN KEY (thread_guid) REFERENCES fray_threads(guid)
 );
@@ -211,9 +225,10 @@ CREATE INDEX IF NOT EXISTS idx_fray_ghost_cursors_agent ON fray_ghost_cursors(ag
 CREATE TABLE IF NOT EXISTS fray_claims (
   id INTEGER PRIMARY KEY AUTOINCREMENT,
   agent_id TEXT NOT NULL,
-  claim_type TEXT NOT NULL,        -- 'file', 'bd', 'issue'
-  pattern TEXT NOT NULL,           -- file path/glob, bd id, or issue number
-  reason TEXT,
+  claim_type TEXT NOT NULL,        -- 'file', 'bd', 'issue', 'branch'
+  pattern TEXT NOT NULL,           -- file path/glob, bd id, issue number, or branch
+  reason TEXT,                     -- intent note shown to other agents
+  message_guid TEXT,               -- optional message explaining the claim
   created_at INTEGER NOT NULL,
   expires_at INTEGER,              -- null = no expiry
   UNIQUE(claim_type, pattern)
@@ -264,6 +279,48 @@ CREATE TABLE IF NOT EXISTS fray_session_roles (
   PRIMARY KEY (agent_id, role_name)
 );
 CREATE INDEX IF NOT EXISTS idx_fray_session_roles_role ON fray_session_roles(role_name);
+
+-- Daemon mention queue (daemon-local; survives restarts, not in JSONL)
+CREATE TABLE IF NOT EXISTS fray_pending_mentions (
+  agent_id TEXT NOT NULL,
+  message_guid TEXT NOT NULL,
+  queued_at INTEGER NOT NULL,   -- unix millis, preserves queue order
+  PRIMARY KEY (agent_id, message_guid)
+);
+
+-- Sessions the daemon spawned and has not seen end (daemon-local, not in JSONL)
+CREATE TABLE IF NOT EXISTS fray_daemon_sessions (
+  agent_id TEXT PRIMARY KEY,
+  session_id TEXT,
+  started_at INTEGER NOT NULL   -- unix millis
+);
+
+-- Activity pings: who is mid-generation right now (transient, not in JSONL)
+CREATE TABLE IF NOT EXISTS fray_agent_working (
+  agent_id TEXT PRIMARY KEY,
+  message_guid TEXT,            -- what they're working on, if known
+  source TEXT NOT NULL,         -- "agent" (fray working) or "daemon"
+  started_at INTEGER NOT NULL,  -- unix millis
+  updated_at INTEGER NOT NULL   -- unix millis; stale after working_ttl_seconds
+);
+
+-- Room-wide announcements and who has acknowledged them
+CREATE TABLE IF NOT EXISTS fray_announcements (
+  message_guid TEXT PRIMARY KEY,
+  from_agent TEXT NOT NULL,
+  ack_required INTEGER NOT NULL DEFAULT 0,
+  audience TEXT NOT NULL DEFAULT '[]', -- JSON array of agent IDs expected to ack
+  created_at INTEGER NOT NULL,
+  reminded_at INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS fray_announcement_acks (
+  message_guid TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  source TEXT NOT NULL,         -- "command" (fray ack) or "reaction"
+  acked_at INTEGER NOT NULL,
+  PRIMARY KEY (message_guid, agent_id)
+);
 `
 
 const defaultConfigSQL = `
@@ -277,6 +334,10 @@ type DBTX interface {
 	QueryRow(query string, args ...any) *sql.Row
 }
 
+// SchemaVersion is the cache schema version InitSchema records in PRAGMA
+// user_version. Bump it when schemaSQL or migrateSchema changes.
+const SchemaVersion = 1
+
 // InitSchema initializes the fray schema.
 func InitSchema(db *sql.DB) error {
 	tx, err := db.Begin()
@@ -303,9 +364,31 @@ func initSchemaWith(db DBTX) error {
 	if _, err := db.Exec(defaultConfigSQL); err != nil {
 		return err
 	}
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)); err != nil {
+		return err
+	}
 	return nil
 }
 
+// ReadSchemaVersion returns the schema version recorded in the database at
+// dbPath, or 0 for a database from before versioning. It opens the file
+// read-only, so it never creates, migrates or rebuilds the cache.
+func ReadSchemaVersion(dbPath string) (int, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return 0, err
+	}
+	conn, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	var version int
+	if err := conn.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
 // SchemaExists reports whether fray schema is present.
 func SchemaExists(db *sql.DB) (bool, error) {
 	row := db.QueryRow(`
@@ -644,6 +727,26 @@ func migrateSchema(db DBTX) error {
 				return err
 			}
 		}
+		if !hasColumn(messageColumns, "important") {
+			if _, err := db.Exec("ALTER TABLE fray_messages ADD COLUMN important INTEGER NOT NULL DEFAULT 0"); err != nil {
+				return err
+			}
+		}
+		if !hasColumn(messageColumns, "standup") {
+			if _, err := db.Exec("ALTER TABLE fray_messages ADD COLUMN standup INTEGER NOT NULL DEFAULT 0"); err != nil {
+				return err
+			}
+		}
+		if !hasColumn(messageColumns, "commits") {
+			if _, err := db.Exec("ALTER TABLE fray_messages ADD COLUMN commits TEXT"); err != nil {
+				return err
+			}
+		}
+		if !hasColumn(messageColumns, "thread_refs") {
+			if _, err := db.Exec("ALTER TABLE fray_messages ADD COLUMN thread_refs TEXT"); err != nil {
+				return err
+			}
+		}
 	}
 
 	receiptColumns, err := getTableInfo(db, "fray_read_receipts")
@@ -751,6 +854,18 @@ func migrateSchema(db DBTX) error {
 				return err
 			}
 		}
+		if !hasColumn(agentColumns, "parent_agent") {
+			if _, err := db.Exec("ALTER TABLE fray_agents ADD COLUMN parent_agent TEXT"); err != nil {
+				return err
+			}
+		}
+		for _, column := range []string{"last_known_input", "last_known_output", "tokens_updated_at", "reaction_watermark"} {
+			if !hasColumn(agentColumns, column) {
+				if _, err := db.Exec("ALTER TABLE fray_agents ADD COLUMN " + column + " INTEGER"); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
 	// Add thread anchor and activity columns if missing
@@ -779,6 +894,36 @@ func migrateSchema(db DBTX) error {
 				return err
 			}
 		}
+		if !hasColumn(threadColumns, "created_by") {
+			if _, err := db.Exec("ALTER TABLE fray_threads ADD COLUMN created_by TEXT"); err != nil {
+				return err
+			}
+		}
+		if !hasColumn(threadColumns, "referenced_count") {
+			if _, err := db.Exec("ALTER TABLE fray_threads ADD COLUMN referenced_count INTEGER NOT NULL DEFAULT 0"); err != nil {
+				return err
+			}
+		}
+	}
+
+	claimColumns, err := getTableInfo(db, "fray_claims")
+	if err != nil {
+		return err
+	}
+	if len(claimColumns) > 0 && !hasColumn(claimColumns, "message_guid") {
+		if _, err := db.Exec("ALTER TABLE fray_claims ADD COLUMN message_guid TEXT"); err != nil {
+			return err
+		}
+	}
+
+	subscriptionColumns, err := getTableInfo(db, "fray_thread_subscriptions")
+	if err != nil {
+		return err
+	}
+	if len(subscriptionColumns) > 0 && !hasColumn(subscriptionColumns, "level") {
+		if _, err := db.Exec("ALTER TABLE fray_thread_subscriptions ADD COLUMN level TEXT NOT NULL DEFAULT 'all'"); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// ResolveThreadRefs returns the GUIDs of existing threads cited in body as
+// #name, #parent/child, or thrd-GUID, in order of first appearance.
+// References to threads that don't exist are dropped.
+func ResolveThreadRefs(db *sql.DB, body string) ([]string, error) {
+	var guids []string
+	seen := map[string]struct{}{}
+	for _, ref := range core.ExtractThreadRefs(body) {
+		thread, err := lookupThreadRef(db, ref)
+		if err != nil {
+			return nil, err
+		}
+		if thread == nil {
+			continue
+		}
+		if _, ok := seen[thread.GUID]; ok {
+			continue
+		}
+		seen[thread.GUID] = struct{}{}
+		guids = append(guids, thread.GUID)
+	}
+	return guids, nil
+}
+
+// lookupThreadRef finds the thread a single reference names. Names are
+// matched exactly, from the root, so prose only links real thread paths.
+func lookupThreadRef(db *sql.DB, ref string) (*types.Thread, error) {
+	if strings.HasPrefix(ref, "thrd-") {
+		return GetThread(db, ref)
+	}
+	var parent *types.Thread
+	for _, name := range strings.Split(ref, "/") {
+		var parentGUID *string
+		if parent != nil {
+			parentGUID = &parent.GUID
+		}
+		thread, err := GetThreadByName(db, name, parentGUID)
+		if err != nil || thread == nil {
+			return nil, err
+		}
+		parent = thread
+	}
+	return parent, nil
+}
+
+// marshalThreadRefs encodes thread references for the thread_refs column;
+// none is NULL.
+func marshalThreadRefs(refs []string) (any, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(refs)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// refreshThreadReferenceCounts recomputes referenced_count for the given
+// threads from the unarchived messages citing them.
+func refreshThreadReferenceCounts(db DBTX, threadGUIDs []string) error {
+	for _, guid := range threadGUIDs {
+		if _, err := db.Exec(`
+			UPDATE fray_threads SET referenced_count = (
+				SELECT COUNT(*) FROM fray_messages m, json_each(m.thread_refs) ref
+				WHERE m.thread_refs IS NOT NULL AND m.archived_at IS NULL
+				  AND ref.value = fray_threads.guid
+			) WHERE guid = ?
+		`, guid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshAllThreadReferenceCounts recomputes referenced_count for every thread.
+func refreshAllThreadReferenceCounts(db DBTX) error {
+	_, err := db.Exec(`
+		UPDATE fray_threads SET referenced_count = (
+			SELECT COUNT(*) FROM fray_messages m, json_each(m.thread_refs) ref
+			WHERE m.thread_refs IS NOT NULL AND m.archived_at IS NULL
+			  AND ref.value = fray_threads.guid
+		)
+	`)
+	return err
+}
+
+// GetThreadBacklinks returns the messages citing a thread, oldest first.
+// Archived messages are left out.
+func GetThreadBacklinks(db *sql.DB, threadGUID string) ([]types.Message, error) {
+	rows, err := db.Query(`
+		SELECT `+messageColumnsAliased+`
+		FROM fray_messages m
+		WHERE m.thread_refs IS NOT NULL AND m.archived_at IS NULL
+		  AND EXISTS (SELECT 1 FROM json_each(m.thread_refs) ref WHERE ref.value = ?)
+		ORDER BY m.ts ASC, m.guid ASC
+	`, threadGUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanMessagesWithReactions(db, rows)
+}
+
+// updateMessageThreadRefs re-resolves a message's thread references after
+// its body changed and refreshes the counts of threads it gained or lost.
+func updateMessageThreadRefs(db *sql.DB, messageID, body string) error {
+	var oldJSON sql.NullString
+	if err := db.QueryRow("SELECT thread_refs FROM fray_messages WHERE guid = ?", messageID).Scan(&oldJSON); err != nil {
+		return err
+	}
+	var old []string
+	if oldJSON.Valid && oldJSON.String != "" {
+		if err := json.Unmarshal([]byte(oldJSON.String), &old); err != nil {
+			return err
+		}
+	}
+	refs, err := ResolveThreadRefs(db, body)
+	if err != nil {
+		return err
+	}
+	refsJSON, err := marshalThreadRefs(refs)
+	if err != nil {
+		return err
+	}
+	if _, err := db.Exec("UPDATE fray_messages SET thread_refs = ? WHERE guid = ?", refsJSON, messageID); err != nil {
+		return err
+	}
+	return refreshThreadReferenceCounts(db, append(old, refs...))
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/adamavenir/fray/internal/core"
+)
+
+const (
+	localDir = "local"
+	undoFile = "undo.jsonl"
+)
+
+// Undo log record types.
+const (
+	UndoRecordEntry   = "undo_entry"
+	UndoRecordApplied = "undo_applied"
+	UndoRecordBarrier = "undo_barrier"
+)
+
+// UndoRecord is a line in the local undo log (.fray/local/undo.jsonl).
+// The log is per-machine state and is never synced or rebuilt from.
+type UndoRecord struct {
+	Type        string     `json:"type"`
+	ID          string     `json:"id"`
+	Command     string     `json:"command,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Inverse     [][]string `json:"inverse,omitempty"`
+	RecordedAt  int64      `json:"recorded_at"`
+}
+
+func undoLogPath(projectPath string) string {
+	return filepath.Join(resolveFrayDir(projectPath), localDir, undoFile)
+}
+
+// AppendUndoEntry records a reversible operation and the commands that invert it.
+func AppendUndoEntry(projectPath string, entry UndoRecord) (UndoRecord, error) {
+	entry.Type = UndoRecordEntry
+	if entry.ID == "" {
+		id, err := core.GenerateGUID("undo")
+		if err != nil {
+			return UndoRecord{}, err
+		}
+		entry.ID = id
+	}
+	if err := appendJSONLine(undoLogPath(projectPath), entry); err != nil {
+		return UndoRecord{}, err
+	}
+	return entry, nil
+}
+
+// AppendUndoApplied marks an undo entry as applied so it is never replayed twice.
+func AppendUndoApplied(projectPath, id string, appliedAt int64) error {
+	return appendJSONLine(undoLogPath(projectPath), UndoRecord{
+		Type:       UndoRecordApplied,
+		ID:         id,
+		RecordedAt: appliedAt,
+	})
+}
+
+// AppendUndoBarrier records a non-reversible operation. Entries before a
+// barrier can no longer be undone.
+func AppendUndoBarrier(projectPath, command string, recordedAt int64) error {
+	return appendJSONLine(undoLogPath(projectPath), UndoRecord{
+		Type:       UndoRecordBarrier,
+		Command:    command,
+		RecordedAt: recordedAt,
+	})
+}
+
+// ReadPendingUndo returns undo entries that are still applicable, newest first.
+// Applied entries and anything recorded before the latest barrier are dropped.
+func ReadPendingUndo(projectPath string) ([]UndoRecord, error) {
+	lines, err := readJSONLLines(undoLogPath(projectPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []UndoRecord
+	applied := make(map[string]struct{})
+	for _, line := range lines {
+		var record UndoRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			continue
+		}
+		switch record.Type {
+		case UndoRecordEntry:
+			entries = append(entries, record)
+		case UndoRecordApplied:
+			applied[record.ID] = struct{}{}
+		case UndoRecordBarrier:
+			entries = nil
+		}
+	}
+
+	pending := make([]UndoRecord, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		if _, ok := applied[entries[i].ID]; ok {
+			continue
+		}
+		pending = append(pending, entries[i])
+	}
+	return pending, nil
+}
@@ -0,0 +1,276 @@
+// Package devtool synthesizes large fray projects for performance work.
+// Everything is written through the db package's Append* functions, so the
+// fixtures carry the same record shapes a real project does, and a seed
+// fixes every ID, timestamp, and body.
+package devtool
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/types"
+)
+
+// Options sizes a generated project.
+type Options struct {
+	Dir      string
+	Messages int
+	Agents   int
+	Threads  int
+	Seed     int64
+}
+
+// Summary counts what Generate wrote.
+type Summary struct {
+	Dir       string        `json:"dir"`
+	Seed      int64         `json:"seed"`
+	Agents    int           `json:"agents"`
+	Threads   int           `json:"threads"`
+	Messages  int           `json:"messages"`
+	Replies   int           `json:"replies"`
+	Mentions  int           `json:"mentions"`
+	Reactions int           `json:"reactions"`
+	Pins      int           `json:"pins"`
+	Edits     int           `json:"edits"`
+	Elapsed   time.Duration `json:"elapsed_ns"`
+}
+
+// generatedEpoch is when generated projects start; messages follow it at a
+// few seconds to a few minutes apart.
+var generatedEpoch = time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC).Unix()
+
+const guidAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+var (
+	agentNames  = []string{"opus", "devrel", "pm", "designer", "reviewer", "architect", "qa", "scribe", "ops", "researcher", "planner", "builder"}
+	drivers     = []string{"claude", "codex", "opencode"}
+	threadWords = []string{"design", "perf", "release", "auth", "sync", "schema", "onboarding", "billing", "search", "infra", "docs", "triage"}
+	bodyWords   = []string{
+		"the", "migration", "looks", "good", "but", "we", "should", "check", "rebuild", "timing",
+		"on", "large", "projects", "before", "merging", "I", "pushed", "a", "fix", "for",
+		"flaky", "test", "can", "you", "review", "cache", "invalidation", "seems", "off", "after",
+		"prune", "runs", "let's", "split", "this", "into", "two", "threads", "agreed", "shipping",
+	}
+	emoji = []string{"👍", "🎉", "👀", "✅", "❤️", "🤔"}
+)
+
+// replyWindow is how many recent messages per home a reply may target.
+const replyWindow = 20
+
+// Generate creates a project in opts.Dir and fills it with agents, a thread
+// hierarchy, and messages with reply chains, mentions, reactions, pins, and
+// edits. The directory must not already hold a .fray project. The same
+// options always produce byte-identical JSONL.
+func Generate(opts Options) (Summary, error) {
+	started := time.Now()
+	if opts.Messages < 0 || opts.Threads < 0 {
+		return Summary{}, fmt.Errorf("messages and threads must not be negative")
+	}
+	if opts.Agents < 1 {
+		return Summary{}, fmt.Errorf("at least one agent is required")
+	}
+
+	project, err := core.InitProject(opts.Dir, false)
+	if err != nil {
+		return Summary{}, err
+	}
+	g := &generator{
+		rng:     rand.New(rand.NewSource(opts.Seed)),
+		path:    project.DBPath,
+		recent:  map[string][]string{},
+		summary: Summary{Dir: project.Root, Seed: opts.Seed},
+	}
+
+	if _, err := db.UpdateProjectConfig(g.path, db.ProjectConfig{
+		Version:     1,
+		ChannelID:   g.guid("ch"),
+		ChannelName: filepath.Base(project.Root),
+		CreatedAt:   time.Unix(generatedEpoch, 0).UTC().Format(time.RFC3339),
+	}); err != nil {
+		return Summary{}, err
+	}
+	if err := g.agents(opts.Agents); err != nil {
+		return Summary{}, err
+	}
+	if err := g.threads(opts.Threads); err != nil {
+		return Summary{}, err
+	}
+	if err := g.messages(opts.Messages); err != nil {
+		return Summary{}, err
+	}
+
+	g.summary.Elapsed = time.Since(started)
+	return g.summary, nil
+}
+
+type generator struct {
+	rng     *rand.Rand
+	path    string
+	agentID []string
+	thread  []types.Thread
+	recent  map[string][]string // home -> recent message IDs, oldest first
+	ts      int64
+	summary Summary
+}
+
+func (g *generator) guid(prefix string) string {
+	id := make([]byte, 8)
+	for i := range id {
+		id[i] = guidAlphabet[g.rng.Intn(len(guidAlphabet))]
+	}
+	return prefix + "-" + string(id)
+}
+
+func (g *generator) agents(count int) error {
+	for i := 0; i < count; i++ {
+		name := agentNames[i%len(agentNames)]
+		if i >= len(agentNames) {
+			name = fmt.Sprintf("%s%d", name, i/len(agentNames)+1)
+		}
+		purpose := fmt.Sprintf("generated %s agent", name)
+		agent := types.Agent{
+			GUID:         g.guid("usr"),
+			AgentID:      name,
+			Purpose:      &purpose,
+			RegisteredAt: generatedEpoch,
+			LastSeen:     generatedEpoch,
+			Presence:     types.PresenceOffline,
+		}
+		// Two in three agents are daemon-managed, spread across drivers.
+		if i%3 != 2 {
+			agent.Managed = true
+			agent.Invoke = &types.InvokeConfig{Driver: drivers[i%len(drivers)], PromptDelivery: types.PromptDeliveryStdin}
+		}
+		if err := db.AppendAgent(g.path, agent); err != nil {
+			return err
+		}
+		g.agentID = append(g.agentID, name)
+	}
+	g.summary.Agents = count
+	return nil
+}
+
+func (g *generator) threads(count int) error {
+	for i := 0; i < count; i++ {
+		thread := types.Thread{
+			GUID:      g.guid("thrd"),
+			Name:      fmt.Sprintf("%s-%d", threadWords[i%len(threadWords)], i+1),
+			Status:    types.ThreadStatusOpen,
+			Type:      types.ThreadTypeStandard,
+			CreatedAt: generatedEpoch + int64(i),
+		}
+		// Later threads nest under an earlier one a third of the time.
+		if i > 0 && g.rng.Intn(3) == 0 {
+			parent := g.thread[g.rng.Intn(len(g.thread))].GUID
+			thread.ParentThread = &parent
+		}
+		creator := g.pickAgent()
+		thread.CreatedBy = &creator
+		if err := db.AppendThread(g.path, thread, []string{creator}); err != nil {
+			return err
+		}
+		g.thread = append(g.thread, thread)
+	}
+	g.summary.Threads = count
+	return nil
+}
+
+func (g *generator) messages(count int) error {
+	g.ts = generatedEpoch + 3600
+	for i := 0; i < count; i++ {
+		g.ts += int64(5 + g.rng.Intn(180))
+		msg := types.Message{
+			ID:        g.guid("msg"),
+			TS:        g.ts,
+			FromAgent: g.pickAgent(),
+			Type:      types.MessageTypeAgent,
+			Home:      "room",
+		}
+		if len(g.thread) > 0 && g.rng.Intn(5) < 3 {
+			msg.Home = g.thread[g.rng.Intn(len(g.thread))].GUID
+		}
+		if recent := g.recent[msg.Home]; len(recent) > 0 && g.rng.Intn(10) < 3 {
+			parent := recent[g.rng.Intn(len(recent))]
+			msg.ReplyTo = &parent
+			g.summary.Replies++
+		}
+		msg.Body = g.sentence()
+		if g.rng.Intn(7) == 0 {
+			mention := g.pickAgent()
+			msg.Body = "@" + mention + " " + msg.Body
+			msg.Mentions = []string{mention}
+			g.summary.Mentions++
+		} else {
+			msg.Mentions = []string{}
+		}
+		if err := db.AppendMessage(g.path, msg); err != nil {
+			return err
+		}
+		g.remember(msg)
+
+		if err := g.decorate(msg); err != nil {
+			return err
+		}
+	}
+	g.summary.Messages = count
+	return nil
+}
+
+// decorate adds the follow-up records a message may attract: reactions,
+// an edit, and a pin when it lives in a thread.
+func (g *generator) decorate(msg types.Message) error {
+	if g.rng.Intn(10) == 0 {
+		for n := 1 + g.rng.Intn(3); n > 0; n-- {
+			reactedAt := (msg.TS+int64(1+g.rng.Intn(60)))*1000 + int64(g.rng.Intn(1000))
+			if err := db.AppendReaction(g.path, msg.ID, g.pickAgent(), emoji[g.rng.Intn(len(emoji))], reactedAt); err != nil {
+				return err
+			}
+			g.summary.Reactions++
+		}
+	}
+	if g.rng.Intn(33) == 0 {
+		body := msg.Body + " (edited: " + g.sentence() + ")"
+		editedAt := msg.TS + int64(30+g.rng.Intn(300))
+		if err := db.AppendMessageUpdate(g.path, db.MessageUpdateJSONLRecord{ID: msg.ID, Body: &body, EditedAt: &editedAt}); err != nil {
+			return err
+		}
+		g.summary.Edits++
+	}
+	if msg.Home != "room" && g.rng.Intn(100) == 0 {
+		if err := db.AppendMessagePin(g.path, db.MessagePinJSONLRecord{
+			MessageGUID: msg.ID,
+			ThreadGUID:  msg.Home,
+			PinnedBy:    g.pickAgent(),
+			PinnedAt:    msg.TS + 60,
+		}); err != nil {
+			return err
+		}
+		g.summary.Pins++
+	}
+	return nil
+}
+
+func (g *generator) remember(msg types.Message) {
+	recent := append(g.recent[msg.Home], msg.ID)
+	if len(recent) > replyWindow {
+		recent = recent[len(recent)-replyWindow:]
+	}
+	g.recent[msg.Home] = recent
+}
+
+func (g *generator) pickAgent() string {
+	return g.agentID[g.rng.Intn(len(g.agentID))]
+}
+
+func (g *generator) sentence() string {
+	words := make([]string, 4+g.rng.Intn(16))
+	for i := range words {
+		words[i] = bodyWords[g.rng.Intn(len(bodyWords))]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,95 @@
+package devtool
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/db"
+	"github.com/adamavenir/fray/internal/testutil"
+)
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	testutil.IsolateHome(t)
+	generate := func(seed int64) string {
+		t.Helper()
+		dir := filepath.Join(t.TempDir(), "bigproj")
+		if _, err := Generate(Options{Dir: dir, Messages: 500, Agents: 5, Threads: 8, Seed: seed}); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		return dir
+	}
+	read := func(dir, file string) []byte {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, ".fray", file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		return data
+	}
+
+	first, second, other := generate(42), generate(42), generate(7)
+	for _, file := range []string{"messages.jsonl", "agents.jsonl", "threads.jsonl", "fray-config.json"} {
+		if !bytes.Equal(read(first, file), read(second, file)) {
+			t.Fatalf("%s differs between runs with the same seed", file)
+		}
+	}
+	if bytes.Equal(read(first, "messages.jsonl"), read(other, "messages.jsonl")) {
+		t.Fatal("expected a different seed to produce different messages")
+	}
+
+	if _, err := Generate(Options{Dir: first, Messages: 1, Agents: 1}); err == nil {
+		t.Fatal("expected generating into an existing project to fail")
+	}
+}
+
+func TestGeneratedProjectRebuilds(t *testing.T) {
+	testutil.IsolateHome(t)
+	dir := filepath.Join(t.TempDir(), "bigproj")
+	summary, err := Generate(Options{Dir: dir, Messages: 2000, Agents: 12, Threads: 40, Seed: 42})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if summary.Replies == 0 || summary.Mentions == 0 || summary.Reactions == 0 || summary.Edits == 0 || summary.Pins == 0 {
+		t.Fatalf("expected every kind of record, got %+v", summary)
+	}
+
+	project := core.Project{Root: dir, DBPath: filepath.Join(dir, ".fray", "fray.db")}
+	conn, err := db.OpenDatabase(project)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.InitSchema(conn); err != nil {
+		t.Fatalf("init schema: %v", err)
+	}
+	if err := db.RebuildDatabaseFromJSONL(conn, project.DBPath); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+
+	counts := map[string]int{
+		"SELECT COUNT(*) FROM fray_messages":                                summary.Messages,
+		"SELECT COUNT(*) FROM fray_agents":                                  summary.Agents,
+		"SELECT COUNT(*) FROM fray_threads":                                 summary.Threads,
+		"SELECT COUNT(*) FROM fray_messages WHERE reply_to IS NOT NULL":     summary.Replies,
+		"SELECT COUNT(*) FROM fray_messages WHERE edited_at IS NOT NULL":    summary.Edits,
+		"SELECT COUNT(*) FROM fray_threads WHERE parent_thread IS NOT NULL": -1,
+	}
+	for query, want := range counts {
+		var got int
+		if err := conn.QueryRow(query).Scan(&got); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		if want < 0 {
+			if got == 0 {
+				t.Fatalf("%s: expected nested threads", query)
+			}
+			continue
+		}
+		if got != want {
+			t.Fatalf("%s = %d, want %d", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package testutil holds the scaffolding tests use to stand up throwaway fray
+// projects: a temp project, agent and message factories, JSONL fixtures, and a
+// command runner that keeps the working directory tidy. Helpers take
+// testing.TB, so benchmarks can use them too.
+//
+// It imports core and types but not db, so the db package's own tests can use
+// it too; helpers that need the database take the db functions as arguments.
+package testutil
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamavenir/fray/internal/core"
+	"github.com/adamavenir/fray/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// Project is a fray project in a temp directory.
+type Project struct {
+	Root    string
+	FrayDir string
+	Project core.Project
+	DB      *sql.DB
+}
+
+// NewProject initializes a project in a temp directory with a minimal config
+// and empty messages and agents JSONL files, so project discovery finds it.
+// HOME is pointed at another temp directory first so global config never
+// leaks in.
+func NewProject(t testing.TB) *Project {
+	t.Helper()
+	IsolateHome(t)
+
+	project, err := core.InitProject(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("init project: %v", err)
+	}
+	p := &Project{Root: project.Root, FrayDir: filepath.Dir(project.DBPath), Project: project}
+	config := []byte(`{"channel_id":"ch-test","channel_name":"test"}`)
+	if err := os.WriteFile(filepath.Join(p.FrayDir, "fray-config.json"), config, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	for _, name := range []string{"messages.jsonl", "agents.jsonl"} {
+		if err := os.WriteFile(filepath.Join(p.FrayDir, name), nil, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return p
+}
+
+// OpenDB opens the project's database with open, applies the schema with
+// initSchema, and closes it when the test ends. Callers pass db.OpenDatabase
+// and db.InitSchema.
+func (p *Project) OpenDB(t testing.TB, open func(core.Project) (*sql.DB, error), initSchema func(*sql.DB) error) *sql.DB {
+	t.Helper()
+	conn, err := open(p.Project)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := initSchema(conn); err != nil {
+		t.Fatalf("init schema: %v", err)
+	}
+	p.DB = conn
+	return conn
+}
+
+// WriteJSONL appends fixture records to a file under .fray. Strings are
+// written as raw lines, so malformed or future records can be loaded as-is;
+// anything else is marshaled to JSON.
+func (p *Project) WriteJSONL(t testing.TB, file string, records ...any) {
+	t.Helper()
+	path := filepath.Join(p.FrayDir, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	var buf bytes.Buffer
+	for _, record := range records {
+		line, ok := record.(string)
+		if !ok {
+			data, err := json.Marshal(record)
+			if err != nil {
+				t.Fatalf("marshal fixture: %v", err)
+			}
+			line = string(data)
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open %s: %v", file, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		t.Fatalf("write %s: %v", file, err)
+	}
+}
+
+// Run executes a command from the project root and restores the working
+// directory afterwards.
+func (p *Project) Run(cmd *cobra.Command, args ...string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chdir(p.Root); err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	return Execute(cmd, args...)
+}
+
+// Execute runs a command with args and returns its combined output.
+func Execute(cmd *cobra.Command, args ...string) (string, error) {
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+// IsolateHome points HOME at a temp directory for the rest of the test.
+func IsolateHome(t testing.TB) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	return home
+}
+
+// Chdir changes into dir for the rest of the test.
+func Chdir(t testing.TB, dir string) {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+}
+
+// NewAgent returns an offline, unmanaged agent registered and seen now.
+func NewAgent(agentID string) types.Agent {
+	now := time.Now().Unix()
+	return types.Agent{
+		AgentID:      agentID,
+		RegisteredAt: now,
+		LastSeen:     now,
+		Presence:     types.PresenceOffline,
+	}
+}
+
+// NewManagedAgent returns NewAgent with a claude stdin invoke config.
+func NewManagedAgent(agentID string) types.Agent {
+	agent := NewAgent(agentID)
+	agent.Managed = true
+	agent.Invoke = &types.InvokeConfig{
+		Driver:         "claude",
+		PromptDelivery: types.PromptDeliveryStdin,
+	}
+	return agent
+}
+
+// NewMessage returns a room message posted now. Mentions are not filled in.
+func NewMessage(fromAgent, body string, msgType types.MessageType) types.Message {
+	if msgType == "" {
+		msgType = types.MessageTypeAgent
+	}
+	return types.Message{
+		TS:        time.Now().Unix(),
+		FromAgent: fromAgent,
+		Body:      body,
+		Type:      msgType,
+		Home:      "room",
+	}
+}
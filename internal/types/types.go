@@ -8,6 +8,17 @@ const (
 	MessageTypeUser    MessageType = "user"
 	MessageTypeEvent   MessageType = "event"
 	MessageTypeSurface MessageType = "surface"
+	MessageTypeDone    MessageType = "done"    // structured end-of-session report (fray done)
+	MessageTypeStandup MessageType = "standup" // legacy: standups now keep their type and set Message.Standup
+)
+
+// DoneStatus is the outcome an agent reports with fray done.
+type DoneStatus string
+
+const (
+	DoneStatusSuccess     DoneStatus = "success"
+	DoneStatusBlocked     DoneStatus = "blocked"
+	DoneStatusNeedsReview DoneStatus = "needs-review"
 )
 
 // PresenceState represents the agent's daemon-managed presence.
@@ -19,6 +30,7 @@ const (
 	PresenceIdle     PresenceState = "idle"
 	PresenceError    PresenceState = "error"
 	PresenceOffline  PresenceState = "offline"
+	PresenceWarm     PresenceState = "warm" // daemon holds a pre-started session waiting for a prompt
 )
 
 // PromptDelivery specifies how prompts are passed to CLI.
@@ -41,22 +53,57 @@ type InvokeConfig struct {
 	MaxRuntimeMs   int64          `json:"max_runtime_ms,omitempty"`   // zombie safety net: forced termination (default: 7200000)
 }
 
+// Announcement is a room-wide announcement. When AckRequired is set, every
+// agent in Audience (the active agents when it was posted) is expected to ack.
+type Announcement struct {
+	MessageGUID string   `json:"message_guid"`
+	FromAgent   string   `json:"from_agent"`
+	AckRequired bool     `json:"ack_required"`
+	Audience    []string `json:"audience"`
+	CreatedAt   int64    `json:"created_at"`
+	RemindedAt  *int64   `json:"reminded_at,omitempty"`
+}
+
+// AnnouncementAck records an agent acknowledging an announcement, either with
+// fray ack or by reacting ✅ to it.
+type AnnouncementAck struct {
+	MessageGUID string `json:"message_guid"`
+	AgentID     string `json:"agent_id"`
+	Source      string `json:"source"`
+	AckedAt     int64  `json:"acked_at"`
+}
+
+// AgentWorking is a transient "working on" ping: an agent is mid-generation,
+// optionally on a specific message. It lives in SQLite only.
+type AgentWorking struct {
+	AgentID     string  `json:"agent_id"`
+	MessageGUID *string `json:"message_guid,omitempty"`
+	Source      string  `json:"source"`
+	StartedAt   int64   `json:"started_at"`
+	UpdatedAt   int64   `json:"updated_at"`
+}
+
 // Agent represents agent identity and presence.
 type Agent struct {
-	GUID             string         `json:"guid"`
-	AgentID          string         `json:"agent_id"`
-	Status           *string        `json:"status,omitempty"`
-	Purpose          *string        `json:"purpose,omitempty"`
-	Avatar           *string        `json:"avatar,omitempty"`            // single-char avatar for display
-	RegisteredAt     int64          `json:"registered_at"`
-	LastSeen         int64          `json:"last_seen"`
-	LeftAt           *int64         `json:"left_at,omitempty"`
-	Managed          bool           `json:"managed,omitempty"`           // whether daemon controls this agent
-	Invoke           *InvokeConfig  `json:"invoke,omitempty"`            // daemon invocation config
-	Presence         PresenceState  `json:"presence,omitempty"`          // daemon-tracked presence state
-	MentionWatermark *string        `json:"mention_watermark,omitempty"` // last processed mention msg_id
-	LastHeartbeat    *int64         `json:"last_heartbeat,omitempty"`    // last silent checkin timestamp (ms)
-	LastSessionID    *string        `json:"last_session_id,omitempty"`   // Claude Code session ID for --resume
+	GUID              string        `json:"guid"`
+	AgentID           string        `json:"agent_id"`
+	Status            *string       `json:"status,omitempty"`
+	Purpose           *string       `json:"purpose,omitempty"`
+	Avatar            *string       `json:"avatar,omitempty"` // single-char avatar for display
+	RegisteredAt      int64         `json:"registered_at"`
+	LastSeen          int64         `json:"last_seen"`
+	LeftAt            *int64        `json:"left_at,omitempty"`
+	Managed           bool          `json:"managed,omitempty"`            // whether daemon controls this agent
+	Invoke            *InvokeConfig `json:"invoke,omitempty"`             // daemon invocation config
+	Presence          PresenceState `json:"presence,omitempty"`           // daemon-tracked presence state
+	MentionWatermark  *string       `json:"mention_watermark,omitempty"`  // last processed mention msg_id
+	LastHeartbeat     *int64        `json:"last_heartbeat,omitempty"`     // last silent checkin timestamp (ms)
+	LastSessionID     *string       `json:"last_session_id,omitempty"`    // Claude Code session ID for --resume
+	ParentAgent       *string       `json:"parent_agent,omitempty"`       // parent of a sub-agent (alice for alice.1)
+	LastKnownInput    *int64        `json:"last_known_input,omitempty"`   // input tokens of the last session with reported usage
+	LastKnownOutput   *int64        `json:"last_known_output,omitempty"`  // output tokens of the last session with reported usage
+	TokensUpdatedAt   *int64        `json:"tokens_updated_at,omitempty"`  // when token usage was last recorded
+	ReactionWatermark *int64        `json:"reaction_watermark,omitempty"` // reacted_at (ms) of the last reaction acked in the inbox
 }
 
 // ReactionEntry represents a single reaction from an agent.
@@ -84,6 +131,23 @@ type Message struct {
 	Edited           bool                       `json:"edited,omitempty"`
 	EditCount        int                        `json:"edit_count,omitempty"`
 	ArchivedAt       *int64                     `json:"archived_at,omitempty"`
+	Important        bool                       `json:"important,omitempty"`
+	Standup          bool                       `json:"standup,omitempty"` // posted with fray post --standup
+	Commits          []CommitRef                `json:"commits,omitempty"`
+	ThreadRefs       []string                   `json:"thread_refs,omitempty"` // GUIDs of threads cited as #name or thrd-…
+}
+
+// CommitRef is git commit metadata attached to a message (fray post
+// --commit). Patch is only captured with --diff.
+type CommitRef struct {
+	SHA            string   `json:"sha"`
+	Author         string   `json:"author"`
+	Subject        string   `json:"subject"`
+	Files          []string `json:"files,omitempty"`
+	Insertions     int      `json:"insertions"`
+	Deletions      int      `json:"deletions"`
+	Patch          string   `json:"patch,omitempty"`
+	PatchTruncated bool     `json:"patch_truncated,omitempty"`
 }
 
 // MessageVersion represents a version of a message body.
@@ -121,6 +185,8 @@ type MessageRow struct {
 	QuoteMessageGUID *string
 	EditedAt         *int64
 	ArchivedAt       *int64
+	Important        bool
+	Standup          bool
 }
 
 // LinkedProject represents a cross-project link.
@@ -173,7 +239,12 @@ type MessageQueryOptions struct {
 	UnreadOnly            bool
 	AgentPrefix           string
 	IncludeArchived       bool
-	IncludeRepliesToAgent string // Include replies to messages from this agent prefix
+	IncludeRepliesToAgent string   // Include replies to messages from this agent prefix
+	FromAgents            []string // Only messages from these agents (or their subagents)
+	ExcludeFromAgents     []string // Drop messages from these agents; wins over FromAgents
+	ImportantOnly         bool     // Only messages flagged important
+	SinceTS               *int64   // Only messages at or after this unix time
+	UntilTS               *int64   // Only messages at or before this unix time
 }
 
 // QuestionQueryOptions controls question queries.
@@ -192,7 +263,8 @@ type ThreadQueryOptions struct {
 	ParentThread    *string
 	Status          *ThreadStatus
 	IncludeArchived bool
-	SortByActivity  bool // Sort by last_activity_at DESC instead of created_at ASC
+	SortByActivity  bool    // Sort by last_activity_at DESC instead of created_at ASC
+	Viewer          *string // Hide dm threads this agent is not part of
 }
 
 // MessageCursor represents a stable paging cursor.
@@ -228,6 +300,9 @@ const (
 	QuestionStatusOpen     QuestionStatus = "open"
 	QuestionStatusAnswered QuestionStatus = "answered"
 	QuestionStatusClosed   QuestionStatus = "closed"
+	// QuestionStatusDeclined means the recipient turned the question down
+	// (e.g. reacted 👎) rather than answering it.
+	QuestionStatusDeclined QuestionStatus = "declined"
 )
 
 // QuestionOption represents a proposed answer with pros/cons.
@@ -235,6 +310,7 @@ type QuestionOption struct {
 	Label string   `json:"label"`
 	Pros  []string `json:"pros,omitempty"`
 	Cons  []string `json:"cons,omitempty"`
+	Refs  []string `json:"refs,omitempty"` // message/thread GUIDs supporting the option
 }
 
 // Question represents a tracked question.
@@ -265,7 +341,12 @@ type ThreadType string
 const (
 	ThreadTypeStandard  ThreadType = "standard"  // normal user-created thread
 	ThreadTypeKnowledge ThreadType = "knowledge" // knowledge hierarchy (meta, role, agent)
-	ThreadTypeSystem    ThreadType = "system"    // system-managed (notes, keys, jrnl)
+	ThreadTypeSystem    ThreadType = "system"    // system-managed (legacy; superseded by the types below)
+	ThreadTypeMeta      ThreadType = "meta"      // project meta root
+	ThreadTypeNotes     ThreadType = "notes"     // agent notes (excluded from @all)
+	ThreadTypeJournal   ThreadType = "journal"   // agent journal (owner-only posting)
+	ThreadTypeKeys      ThreadType = "keys"      // role keys (no replies, reactions only)
+	ThreadTypeDM        ThreadType = "dm"        // direct messages (listed only for participants)
 )
 
 // Thread represents a container thread.
@@ -281,13 +362,41 @@ type Thread struct {
 	AnchorMessageGUID *string      `json:"anchor_message_guid,omitempty"`
 	AnchorHidden      bool         `json:"anchor_hidden,omitempty"`
 	LastActivityAt    *int64       `json:"last_activity_at,omitempty"`
+	ReferencedCount   int          `json:"referenced_count,omitempty"` // messages citing this thread
 }
 
+// ThreadParticipant is one author's message count in a thread. Sub-agents
+// and versioned IDs count toward their base name.
+type ThreadParticipant struct {
+	AgentID  string `json:"agent_id"`
+	Messages int    `json:"messages"`
+}
+
+// ThreadStats summarizes who takes part in a thread.
+type ThreadStats struct {
+	Participants []ThreadParticipant `json:"participants"`
+	Messages     int                 `json:"messages"`
+	CreatedAt    int64               `json:"created_at"`
+	Owner        *string             `json:"owner,omitempty"`
+	Subscribers  int                 `json:"subscribers"`
+	Pins         int                 `json:"pins"`
+}
+
+// SubscriptionLevel controls how much a thread subscription notifies.
+type SubscriptionLevel string
+
+const (
+	SubscriptionAll      SubscriptionLevel = "all"      // every new message counts as activity
+	SubscriptionMentions SubscriptionLevel = "mentions" // only @mentions count as activity
+	SubscriptionDigest   SubscriptionLevel = "digest"   // never wakes; shown in activity views only
+)
+
 // ThreadSubscription records a thread subscription.
 type ThreadSubscription struct {
-	ThreadGUID   string `json:"thread_guid"`
-	AgentID      string `json:"agent_id"`
-	SubscribedAt int64  `json:"subscribed_at"`
+	ThreadGUID   string            `json:"thread_guid"`
+	AgentID      string            `json:"agent_id"`
+	Level        SubscriptionLevel `json:"level"`
+	SubscribedAt int64             `json:"subscribed_at"`
 }
 
 // ThreadMessage records membership of a message in a thread.
@@ -302,29 +411,76 @@ type ThreadMessage struct {
 type ClaimType string
 
 const (
-	ClaimTypeFile  ClaimType = "file"
-	ClaimTypeBD    ClaimType = "bd"
-	ClaimTypeIssue ClaimType = "issue"
+	ClaimTypeFile   ClaimType = "file"
+	ClaimTypeBD     ClaimType = "bd"
+	ClaimTypeIssue  ClaimType = "issue"
+	ClaimTypeBranch ClaimType = "branch"
 )
 
 // Claim represents a resource claim.
 type Claim struct {
-	ID        int64     `json:"id"`
-	AgentID   string    `json:"agent_id"`
-	ClaimType ClaimType `json:"claim_type"`
-	Pattern   string    `json:"pattern"`
-	Reason    *string   `json:"reason,omitempty"`
-	CreatedAt int64     `json:"created_at"`
-	ExpiresAt *int64    `json:"expires_at,omitempty"`
+	ID          int64     `json:"id"`
+	AgentID     string    `json:"agent_id"`
+	ClaimType   ClaimType `json:"claim_type"`
+	Pattern     string    `json:"pattern"`
+	Reason      *string   `json:"reason,omitempty"`       // intent note (--note)
+	MessageGUID *string   `json:"message_guid,omitempty"` // linked context message (--msg)
+	CreatedAt   int64     `json:"created_at"`
+	ExpiresAt   *int64    `json:"expires_at,omitempty"`
+}
+
+// ClaimHistoryEntry is one claim's lifetime, reconstructed from JSONL so it
+// stays queryable after the claim is cleared or expires.
+type ClaimHistoryEntry struct {
+	AgentID     string    `json:"agent_id"`
+	ClaimType   ClaimType `json:"claim_type"`
+	Pattern     string    `json:"pattern"`
+	Reason      *string   `json:"reason,omitempty"`
+	MessageGUID *string   `json:"message_guid,omitempty"`
+	Status      *string   `json:"status,omitempty"`
+	CreatedAt   int64     `json:"created_at"`
+	ExpiresAt   *int64    `json:"expires_at,omitempty"`
+	ClearedAt   *int64    `json:"cleared_at,omitempty"`
+	// TransferredTo is set when the claim was released straight to another agent.
+	TransferredTo *string `json:"transferred_to,omitempty"`
+}
+
+// ClientPref is a per-thread hint for client UIs, such as priority or color.
+// Local prefs belong to one agent on one machine; shared prefs are synced and
+// apply to everyone, with AgentID empty.
+type ClientPref struct {
+	AgentID    string `json:"agent_id,omitempty"`
+	ThreadGUID string `json:"thread_guid"`
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	Shared     bool   `json:"shared"`
+	SetBy      string `json:"set_by"`
+	SetAt      int64  `json:"set_at"`
+}
+
+// ClaimRequest is an agent asking the holder of a claim to hand it over.
+// It resolves when the claim is next released, to the requester or not.
+type ClaimRequest struct {
+	GUID        string    `json:"guid"`
+	RequestedBy string    `json:"requested_by"`
+	Holder      string    `json:"holder"`
+	ClaimType   ClaimType `json:"claim_type"`
+	Pattern     string    `json:"pattern"`
+	Note        *string   `json:"note,omitempty"`
+	MessageGUID string    `json:"message_guid"` // the message mentioning the holder
+	RequestedAt int64     `json:"requested_at"`
+	ResolvedAt  *int64    `json:"resolved_at,omitempty"`
+	GrantedTo   *string   `json:"granted_to,omitempty"`
 }
 
 // ClaimInput represents new-claim data.
 type ClaimInput struct {
-	AgentID   string    `json:"agent_id"`
-	ClaimType ClaimType `json:"claim_type"`
-	Pattern   string    `json:"pattern"`
-	Reason    *string   `json:"reason,omitempty"`
-	ExpiresAt *int64    `json:"expires_at,omitempty"`
+	AgentID     string    `json:"agent_id"`
+	ClaimType   ClaimType `json:"claim_type"`
+	Pattern     string    `json:"pattern"`
+	Reason      *string   `json:"reason,omitempty"`
+	MessageGUID *string   `json:"message_guid,omitempty"`
+	ExpiresAt   *int64    `json:"expires_at,omitempty"`
 }
 
 // SessionStart records when a daemon spawns an agent session.
@@ -336,13 +492,75 @@ type SessionStart struct {
 	StartedAt   int64   `json:"started_at"`
 }
 
+// SessionResponse links a spawned session's first post back to the mention
+// that triggered the spawn. LatencyMs runs from the mention to the post.
+type SessionResponse struct {
+	AgentID      string `json:"agent_id"`
+	SessionID    string `json:"session_id"`
+	MessageGUID  string `json:"message_guid"`
+	RespondingTo string `json:"responding_to"`
+	LatencyMs    int64  `json:"latency_ms"`
+	RespondedAt  int64  `json:"responded_at"`
+}
+
 // SessionEnd records when an agent session completes.
 type SessionEnd struct {
-	AgentID    string `json:"agent_id"`
-	SessionID  string `json:"session_id"`
-	ExitCode   int    `json:"exit_code"`
-	DurationMs int64  `json:"duration_ms"`
-	EndedAt    int64  `json:"ended_at"`
+	AgentID    string     `json:"agent_id"`
+	SessionID  string     `json:"session_id"`
+	ExitCode   int        `json:"exit_code"`
+	DurationMs int64      `json:"duration_ms"`
+	EndedAt    int64      `json:"ended_at"`
+	Outcome    DoneStatus `json:"outcome,omitempty"` // set when the agent reported fray done
+	Summary    *string    `json:"summary,omitempty"`
+}
+
+// TokenUsage records the tokens one agent session used, as reported by its
+// driver at session end.
+type TokenUsage struct {
+	AgentID   string `json:"agent_id"`
+	SessionID string `json:"session_id,omitempty"`
+	Model     string `json:"model,omitempty"`
+	// InputTokens excludes prompt-cache reads and writes, which are priced
+	// separately.
+	InputTokens      int64 `json:"input_tokens"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
+	OutputTokens     int64 `json:"output_tokens"`
+	RecordedAt       int64 `json:"recorded_at"`
+}
+
+// TotalInput returns the session's input tokens including cache reads and
+// writes.
+func (u TokenUsage) TotalInput() int64 {
+	return u.InputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// SessionDone records an agent's explicit done report for a session.
+type SessionDone struct {
+	AgentID   string     `json:"agent_id"`
+	SessionID string     `json:"session_id,omitempty"`
+	Status    DoneStatus `json:"status"`
+	Summary   *string    `json:"summary,omitempty"`
+	MessageID string     `json:"message_id"`
+	At        int64      `json:"at"`
+}
+
+// AgentSession summarizes one agent session from the session event log.
+type AgentSession struct {
+	AgentID     string     `json:"agent_id"`
+	SessionID   string     `json:"session_id"`
+	TriggeredBy *string    `json:"triggered_by,omitempty"`
+	StartedAt   int64      `json:"started_at,omitempty"`
+	EndedAt     *int64     `json:"ended_at,omitempty"`
+	ExitCode    *int       `json:"exit_code,omitempty"`
+	DurationMs  int64      `json:"duration_ms,omitempty"`
+	Outcome     DoneStatus `json:"outcome,omitempty"`
+	Summary     *string    `json:"summary,omitempty"`
+
+	// ResponseGUID is the agent's first post after a daemon spawn, and
+	// ResponseLatencyMs the time from the triggering mention to it.
+	ResponseGUID      *string `json:"response_guid,omitempty"`
+	ResponseLatencyMs *int64  `json:"response_latency_ms,omitempty"`
 }
 
 // SessionHeartbeat records periodic session health updates.
@@ -372,9 +590,9 @@ type SessionHeartbeat struct {
 // Flow: new session → ghost cursor boundary → ack → read receipts for rest of session
 type GhostCursor struct {
 	AgentID      string `json:"agent_id"`
-	Home         string `json:"home"`                     // "room" or thread GUID
-	MessageGUID  string `json:"message_guid"`             // start reading from here
-	MustRead     bool   `json:"must_read"`                // inject full content vs hint only
+	Home         string `json:"home"`         // "room" or thread GUID
+	MessageGUID  string `json:"message_guid"` // start reading from here
+	MustRead     bool   `json:"must_read"`    // inject full content vs hint only
 	SetAt        int64  `json:"set_at"`
 	SessionAckAt *int64 `json:"session_ack_at,omitempty"` // when first viewed this session
 }
@@ -396,7 +614,7 @@ type SessionRole struct {
 
 // AgentRoles summarizes an agent's held and playing roles.
 type AgentRoles struct {
-	AgentID  string   `json:"agent_id"`
-	Held     []string `json:"held"`
-	Playing  []string `json:"playing"`
+	AgentID string   `json:"agent_id"`
+	Held    []string `json:"held"`
+	Playing []string `json:"playing"`
 }